@@ -231,6 +231,12 @@ func init() {
 	// 1: support for constraints
 	maxSupportedFormat[AccountKeyType.Name] = 1
 
+	// 1: support for migrations
+	maxSupportedFormat[ConfdbSchemaType.Name] = 1
+
+	// 1: support for subcluster rollouts
+	maxSupportedFormat[ClusterType.Name] = 1
+
 	for _, at := range typeRegistry {
 		at.validate()
 	}
@@ -263,6 +269,8 @@ func MockOptionalPrimaryKey(assertType *AssertionType, key, defaultValue string)
 
 var formatAnalyzer = map[*AssertionType]func(headers map[string]any, body []byte) (formatnum int, err error){
 	AccountKeyType:      accountKeyFormatAnalyze,
+	ClusterType:         clusterFormatAnalyze,
+	ConfdbSchemaType:    confdbSchemaFormatAnalyze,
 	SnapDeclarationType: snapDeclarationFormatAnalyze,
 	SystemUserType:      systemUserFormatAnalyze,
 }
@@ -88,12 +88,18 @@ func (as *assertsSuite) TestMaxSupportedFormats(c *C) {
 	accountKeyMaxFormat := asserts.AccountKeyType.MaxSupportedFormat()
 	snapDeclMaxFormat := asserts.SnapDeclarationType.MaxSupportedFormat()
 	systemUserMaxFormat := asserts.SystemUserType.MaxSupportedFormat()
+	confdbSchemaMaxFormat := asserts.ConfdbSchemaType.MaxSupportedFormat()
+	clusterMaxFormat := asserts.ClusterType.MaxSupportedFormat()
 	// validity
 	c.Check(accountKeyMaxFormat >= 1, Equals, true)
+	c.Check(clusterMaxFormat >= 1, Equals, true)
+	c.Check(confdbSchemaMaxFormat >= 1, Equals, true)
 	c.Check(snapDeclMaxFormat >= 6, Equals, true)
 	c.Check(systemUserMaxFormat >= 2, Equals, true)
 	c.Check(asserts.MaxSupportedFormats(1), DeepEquals, map[string]int{
 		"account-key":      accountKeyMaxFormat,
+		"cluster":          clusterMaxFormat,
+		"confdb-schema":    confdbSchemaMaxFormat,
 		"snap-declaration": snapDeclMaxFormat,
 		"system-user":      systemUserMaxFormat,
 		"test-only":        1,
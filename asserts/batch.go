@@ -127,6 +127,11 @@ type CommitOptions struct {
 	Precheck bool
 }
 
+// Backstore returns the memory backstore holding the assertions of this batch.
+func (b *Batch) Backstore() Backstore {
+	return b.bs
+}
+
 // CommitTo adds the batch of assertions to the given assertion database.
 // Nothing will be committed if there are missing prerequisites, for a full
 // consistency check beforehand there is the Precheck option.
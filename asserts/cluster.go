@@ -22,6 +22,7 @@ package asserts
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/snap/naming"
@@ -56,6 +57,40 @@ type Subcluster struct {
 	Devices []int
 	// Snaps contains the expected snap state for this subcluster.
 	Snaps []ClusterSnap
+	// Rollout describes how changes to this subcluster are rolled out to its
+	// devices. If nil, all the devices apply changes at the same time.
+	Rollout *ClusterRollout
+}
+
+// ClusterRolloutFailure describes what happens when a batch of a rollout
+// fails.
+type ClusterRolloutFailure string
+
+const (
+	// ClusterRolloutHalt stops the rollout, leaving the devices of the failed
+	// batch as they are.
+	ClusterRolloutHalt ClusterRolloutFailure = "halt"
+	// ClusterRolloutRevert stops the rollout and reverts the devices of the
+	// failed batch to the snaps they had before the rollout.
+	ClusterRolloutRevert ClusterRolloutFailure = "revert"
+)
+
+// ClusterRollout holds the details about how changes to a subcluster are
+// rolled out to its devices.
+type ClusterRollout struct {
+	// BatchSize is the number of devices, taken in the order in which they
+	// are listed in the subcluster, that apply changes at the same time. A
+	// batch only starts once all the devices of the previous batches have
+	// applied the changes and their snaps are healthy.
+	BatchSize int
+	// OnFailure describes what happens when a device of a batch fails to
+	// apply the changes or its snaps become unhealthy.
+	OnFailure ClusterRolloutFailure
+	// BatchTimeout is the time each batch has to apply the changes and
+	// become healthy. A device fails the rollout once it has been waiting
+	// for the devices of the previous batches for longer than they had. If
+	// unset, the device decides how long to wait.
+	BatchTimeout time.Duration
 }
 
 // ClusterSnapState describes the relationship of a snap to the cluster.
@@ -218,6 +253,60 @@ func checkClusterSnaps(snaps []any) ([]ClusterSnap, error) {
 	return result, nil
 }
 
+func checkClusterRollout(subcluster map[string]any) (*ClusterRollout, error) {
+	rollout, err := checkMap(subcluster, "rollout")
+	if err != nil {
+		return nil, err
+	}
+
+	if rollout == nil {
+		return nil, nil
+	}
+
+	size, err := checkInt(rollout, "batch-size")
+	if err != nil {
+		return nil, err
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf(`"batch-size" header must be >=1: %d`, size)
+	}
+
+	onFailure, err := checkOptionalString(rollout, "on-failure")
+	if err != nil {
+		return nil, err
+	}
+
+	switch ClusterRolloutFailure(onFailure) {
+	case "":
+		onFailure = string(ClusterRolloutHalt)
+	case ClusterRolloutHalt, ClusterRolloutRevert:
+	default:
+		return nil, fmt.Errorf(`"on-failure" header must be one of: %s`, strutil.Quoted([]string{
+			string(ClusterRolloutHalt), string(ClusterRolloutRevert),
+		}))
+	}
+
+	timeout, err := checkOptionalString(rollout, "batch-timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	var batchTimeout time.Duration
+	if timeout != "" {
+		batchTimeout, err = time.ParseDuration(timeout)
+		if err != nil || batchTimeout <= 0 {
+			return nil, fmt.Errorf(`"batch-timeout" header must be a positive duration: %q`, timeout)
+		}
+	}
+
+	return &ClusterRollout{
+		BatchSize:    size,
+		OnFailure:    ClusterRolloutFailure(onFailure),
+		BatchTimeout: batchTimeout,
+	}, nil
+}
+
 func checkClusterSubcluster(subcluster map[string]any) (Subcluster, error) {
 	name, err := checkNotEmptyString(subcluster, "name")
 	if err != nil {
@@ -251,10 +340,16 @@ func checkClusterSubcluster(subcluster map[string]any) (Subcluster, error) {
 		return Subcluster{}, err
 	}
 
+	rollout, err := checkClusterRollout(subcluster)
+	if err != nil {
+		return Subcluster{}, err
+	}
+
 	return Subcluster{
 		Name:    name,
 		Devices: ids,
 		Snaps:   snaps,
+		Rollout: rollout,
 	}, nil
 }
 
@@ -330,6 +425,14 @@ func assembleCluster(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	if assert.Format() < 1 {
+		for _, subcluster := range subclusters {
+			if subcluster.Rollout != nil {
+				return nil, fmt.Errorf(`the "rollout" header is only supported for format 1 or greater`)
+			}
+		}
+	}
+
 	return &Cluster{
 		assertionBase: assert,
 		seq:           seq,
@@ -337,3 +440,22 @@ func assembleCluster(assert assertionBase) (Assertion, error) {
 		subclusters:   subclusters,
 	}, nil
 }
+
+func clusterFormatAnalyze(headers map[string]any, body []byte) (formatnum int, err error) {
+	subclusters, err := checkList(headers, "subclusters")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range subclusters {
+		subcluster, ok := entry.(map[string]any)
+		if !ok {
+			return 0, errors.New(`"subclusters" field must be a list of maps`)
+		}
+		if _, ok := subcluster["rollout"]; ok {
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
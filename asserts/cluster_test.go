@@ -42,6 +42,7 @@ func (cs *clusterSuite) SetUpSuite(c *C) {
 
 const (
 	clusterExample = `type: cluster
+format: 1
 authority-id: authority-id
 cluster-id: bf3675f5-cffa-40f4-a119-7492ccc08e04
 sequence: 3
@@ -72,6 +73,10 @@ subclusters:
         state: evacuated
         instance: evacuated-snap
         channel: edge
+    rollout:
+      batch-size: 1
+      on-failure: revert
+      batch-timeout: 30m
   -
     name: additional-cluster
     devices:
@@ -127,6 +132,11 @@ func (cs *clusterSuite) TestDecodeOK(c *C) {
 	c.Check(subclusters[0].Snaps[1].State, Equals, asserts.ClusterSnapStateEvacuated)
 	c.Check(subclusters[0].Snaps[1].Instance, Equals, "evacuated-snap")
 	c.Check(subclusters[0].Snaps[1].Channel, Equals, "edge")
+	c.Check(subclusters[0].Rollout, DeepEquals, &asserts.ClusterRollout{
+		BatchSize:    1,
+		OnFailure:    asserts.ClusterRolloutRevert,
+		BatchTimeout: 30 * time.Minute,
+	})
 
 	c.Check(subclusters[1].Name, Equals, "additional-cluster")
 	c.Check(subclusters[1].Devices, DeepEquals, []int{2})
@@ -134,6 +144,54 @@ func (cs *clusterSuite) TestDecodeOK(c *C) {
 	c.Check(subclusters[1].Snaps[0].State, Equals, asserts.ClusterSnapStateRemoved)
 	c.Check(subclusters[1].Snaps[0].Instance, Equals, "removed-snap")
 	c.Check(subclusters[1].Snaps[0].Channel, Equals, "24/stable")
+	c.Check(subclusters[1].Rollout, IsNil)
+}
+
+func (cs *clusterSuite) TestDecodeRolloutDefaultOnFailure(c *C) {
+	encoded := strings.Replace(clusterExample, "TSLINE", cs.tsLine, 1)
+	encoded = strings.Replace(encoded, "      on-failure: revert\n", "", 1)
+	encoded = strings.Replace(encoded, "      batch-timeout: 30m\n", "", 1)
+
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+
+	subclusters := a.(*asserts.Cluster).Subclusters()
+	c.Check(subclusters[0].Rollout, DeepEquals, &asserts.ClusterRollout{
+		BatchSize: 1,
+		OnFailure: asserts.ClusterRolloutHalt,
+	})
+}
+
+func (cs *clusterSuite) TestRolloutRequiresFormat1(c *C) {
+	encoded := strings.Replace(clusterExample, "TSLINE", cs.tsLine, 1)
+	encoded = strings.Replace(encoded, "format: 1\n", "", 1)
+
+	_, err := asserts.Decode([]byte(encoded))
+	c.Check(err, ErrorMatches, `assertion cluster: the "rollout" header is only supported for format 1 or greater`)
+
+	// without rollouts, format 0 is enough
+	encoded = strings.Replace(encoded, "    rollout:\n      batch-size: 1\n      on-failure: revert\n      batch-timeout: 30m\n", "", 1)
+	_, err = asserts.Decode([]byte(encoded))
+	c.Check(err, IsNil)
+}
+
+func (cs *clusterSuite) TestSuggestedFormat(c *C) {
+	headers := map[string]any{
+		"subclusters": []any{
+			map[string]any{"name": "default"},
+		},
+	}
+	fmtnum, err := asserts.SuggestFormat(asserts.ClusterType, headers, nil)
+	c.Assert(err, IsNil)
+	c.Check(fmtnum, Equals, 0)
+
+	headers["subclusters"] = []any{
+		map[string]any{"name": "default"},
+		map[string]any{"name": "other", "rollout": map[string]any{"batch-size": "1"}},
+	}
+	fmtnum, err = asserts.SuggestFormat(asserts.ClusterType, headers, nil)
+	c.Assert(err, IsNil)
+	c.Check(fmtnum, Equals, 1)
 }
 
 func (cs *clusterSuite) TestDecodeInvalidTopLevel(c *C) {
@@ -144,7 +202,7 @@ func (cs *clusterSuite) TestDecodeInvalidTopLevel(c *C) {
 		{"cluster-id: bf3675f5-cffa-40f4-a119-7492ccc08e04\n", "cluster-id: \n", `"cluster-id" header should not be empty`},
 		{"sequence: 3\n", "sequence: 0\n", `"sequence" must be >=1: 0`},
 		{"devices:\n  -\n    id: 1\n    device: 9cc45ad6-d01b-4efd-9f76-db55b76c076b.ubuntu-core-24-amd64.canonical\n    addresses:\n      - 192.168.1.10\n      - 10.0.0.10\n  -\n    id: 2\n    device: bc3c0a19-cdad-4cfc-a6f0-85e917bc6280.ubuntu-core-24-amd64.canonical\n    addresses:\n      - 192.168.1.20\n", "devices: not-a-list\n", `"devices" header must be a list`},
-		{"subclusters:\n  -\n    name: default\n    devices:\n      - 1\n      - 2\n    snaps:\n      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n      -\n        state: evacuated\n        instance: evacuated-snap\n        channel: edge\n  -\n    name: additional-cluster\n    devices:\n      - 2\n    snaps:\n      -\n        state: removed\n        instance: removed-snap\n        channel: 24/stable\n", "subclusters: not-a-list\n", `"subclusters" header must be a list`},
+		{"subclusters:\n  -\n    name: default\n    devices:\n      - 1\n      - 2\n    snaps:\n      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n      -\n        state: evacuated\n        instance: evacuated-snap\n        channel: edge\n    rollout:\n      batch-size: 1\n      on-failure: revert\n      batch-timeout: 30m\n  -\n    name: additional-cluster\n    devices:\n      - 2\n    snaps:\n      -\n        state: removed\n        instance: removed-snap\n        channel: 24/stable\n", "subclusters: not-a-list\n", `"subclusters" header must be a list`},
 	}
 
 	for _, test := range invalidTests {
@@ -192,11 +250,18 @@ func (cs *clusterSuite) TestDecodeInvalidSubclusters(c *C) {
 		{"        channel: stable\n", "", `"channel" header is mandatory`},
 		{"        channel: stable\n", "        channel: invalid//channel\n", `invalid channel name "invalid//channel": invalid risk in channel name: invalid//channel`},
 		{"      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n", "      - snap-string\n", `"snaps" field must be a list of maps`},
-		{"  -\n    name: default\n    devices:\n      - 1\n      - 2\n    snaps:\n      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n      -\n        state: evacuated\n        instance: evacuated-snap\n        channel: edge\n", "  - subcluster-string\n", `"subclusters" field must be a list of maps`},
+		{"  -\n    name: default\n    devices:\n      - 1\n      - 2\n    snaps:\n      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n      -\n        state: evacuated\n        instance: evacuated-snap\n        channel: edge\n    rollout:\n      batch-size: 1\n      on-failure: revert\n      batch-timeout: 30m\n", "  - subcluster-string\n", `"subclusters" field must be a list of maps`},
 		{"    name: additional-cluster\n", "    name: default\n", `"subclusters" field contains duplicate subcluster name "default"`},
 		{"    devices:\n      - 1\n      - 2\n", "    devices: not-a-list\n", `"devices" header must be a list of strings`},
 		{"    snaps:\n      -\n        state: clustered\n        instance: clustered-snap\n        channel: stable\n      -\n        state: evacuated\n        instance: evacuated-snap\n        channel: edge\n", "    snaps: not-a-list\n", `"snaps" header must be a list`},
 		{"      - 2\n    snaps:", "      - 999\n    snaps:", `"subclusters" references unknown device id 999`},
+		{"    rollout:\n      batch-size: 1\n      on-failure: revert\n      batch-timeout: 30m\n", "    rollout: not-a-map\n", `"rollout" header must be a map`},
+		{"      batch-size: 1\n", "", `"batch-size" header is mandatory`},
+		{"      batch-size: 1\n", "      batch-size: none\n", `"batch-size" header is not an integer: none`},
+		{"      batch-size: 1\n", "      batch-size: 0\n", `"batch-size" header must be >=1: 0`},
+		{"      on-failure: revert\n", "      on-failure: retry\n", `"on-failure" header must be one of: "halt", "revert"`},
+		{"      batch-timeout: 30m\n", "      batch-timeout: soon\n", `"batch-timeout" header must be a positive duration: "soon"`},
+		{"      batch-timeout: 30m\n", "      batch-timeout: 0s\n", `"batch-timeout" header must be a positive duration: "0s"`},
 	}
 
 	for _, test := range invalidTests {
@@ -34,8 +34,9 @@ import (
 type ConfdbSchema struct {
 	assertionBase
 
-	schema    *confdb.Schema
-	timestamp time.Time
+	schema     *confdb.Schema
+	migrations []confdb.Migration
+	timestamp  time.Time
 }
 
 // AccountID returns the identifier of the account that signed this assertion.
@@ -54,6 +55,19 @@ func (ar *ConfdbSchema) Schema() *confdb.Schema {
 	return ar.schema
 }
 
+// MigrationsSince returns the migrations which convert data stored according
+// to the given revision of the confdb-schema into data stored according to
+// this revision, in the order they must be applied.
+func (ar *ConfdbSchema) MigrationsSince(revision int) []confdb.Migration {
+	var migrations []confdb.Migration
+	for _, m := range ar.migrations {
+		if m.Revision > revision {
+			migrations = append(migrations, m)
+		}
+	}
+	return migrations
+}
+
 func assembleConfdbSchema(assert assertionBase) (Assertion, error) {
 	authorityID := assert.AuthorityID()
 	accountID := assert.HeaderString("account-id")
@@ -98,6 +112,17 @@ func assembleConfdbSchema(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	var migrations []confdb.Migration
+	if migrationsRaw, ok := bodyMap["migrations"]; ok {
+		if assert.Format() < 1 {
+			return nil, fmt.Errorf(`"migrations" in the body are only supported for format 1 or greater`)
+		}
+		migrations, err = confdb.ParseMigrations(migrationsRaw, assert.Revision())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	timestamp, err := checkRFC3339Date(assert.headers, "timestamp")
 	if err != nil {
 		return nil, err
@@ -106,10 +131,25 @@ func assembleConfdbSchema(assert assertionBase) (Assertion, error) {
 	return &ConfdbSchema{
 		assertionBase: assert,
 		schema:        confdbSchema,
+		migrations:    migrations,
 		timestamp:     timestamp,
 	}, nil
 }
 
+func confdbSchemaFormatAnalyze(headers map[string]any, body []byte) (formatnum int, err error) {
+	formatnum = 0
+
+	var bodyMap map[string]json.RawMessage
+	if err := json.Unmarshal(body, &bodyMap); err != nil {
+		return 0, err
+	}
+	if _, ok := bodyMap["migrations"]; ok {
+		formatnum = 1
+	}
+
+	return formatnum, nil
+}
+
 // ConfdbControl holds a confdb-control assertion, which holds lists of
 // views delegated by the device to operators.
 type ConfdbControl struct {
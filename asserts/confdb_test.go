@@ -20,13 +20,16 @@
 package asserts_test
 
 import (
+	"encoding/json"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
 )
 
 type confdbSuite struct {
@@ -201,6 +204,153 @@ func (s *confdbSuite) TestAssembleAndSignChecksSchemaFormatFail(c *C) {
 	c.Assert(err, ErrorMatches, `assertion confdb-schema: JSON in body must be indented with 2 spaces and sort object entries by key`)
 }
 
+func (s *confdbSuite) assembleWithMigrations(c *C, revision int, migrations any) (asserts.Assertion, error) {
+	body, err := json.MarshalIndent(map[string]any{
+		"migrations": migrations,
+		"storage": map[string]any{
+			"schema": map[string]any{
+				"wifi": map[string]any{"type": "map", "values": "any"},
+			},
+		},
+	}, "", "  ")
+	c.Assert(err, IsNil)
+
+	headers := map[string]any{
+		"authority-id": "brand-id1",
+		"account-id":   "brand-id1",
+		"name":         "my-network",
+		"revision":     strconv.Itoa(revision),
+		"views": map[string]any{
+			"foo": map[string]any{
+				"rules": []any{
+					map[string]any{"request": "wifi", "storage": "wifi"},
+				},
+			},
+		},
+		"timestamp": s.ts.Format(time.RFC3339),
+		"format":    "1",
+	}
+	return asserts.AssembleAndSignInTest(asserts.ConfdbSchemaType, headers, body, testPrivKey0)
+}
+
+func (s *confdbSuite) TestMigrationsRequireFormat1(c *C) {
+	body, err := json.MarshalIndent(map[string]any{
+		"migrations": []any{
+			map[string]any{
+				"revision": 1,
+				"rules":    []any{map[string]any{"from": "wifi.psk", "to": "wifi.password"}},
+			},
+		},
+		"storage": map[string]any{
+			"schema": map[string]any{
+				"wifi": map[string]any{"type": "map", "values": "any"},
+			},
+		},
+	}, "", "  ")
+	c.Assert(err, IsNil)
+
+	encoded := strings.Replace(confdbExample, "TSLINE", s.tsLine, 1)
+	encoded = strings.Replace(encoded, "body-length: 115", "revision: 1\nbody-length: "+strconv.Itoa(len(body)), 1)
+	encoded = strings.Replace(encoded, schema, string(body), 1)
+
+	_, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, ErrorMatches, `assertion confdb-schema: "migrations" in the body are only supported for format 1 or greater`)
+
+	encoded = strings.Replace(encoded, "revision: 1\n", "revision: 1\nformat: 1\n", 1)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.Format(), Equals, 1)
+	c.Check(a.(*asserts.ConfdbSchema).MigrationsSince(0), HasLen, 1)
+}
+
+func (s *confdbSuite) TestSuggestedFormat(c *C) {
+	fmtnum, err := asserts.SuggestFormat(asserts.ConfdbSchemaType, nil, []byte(schema))
+	c.Assert(err, IsNil)
+	c.Check(fmtnum, Equals, 0)
+
+	body := []byte(`{"migrations": [], "storage": {}}`)
+	fmtnum, err = asserts.SuggestFormat(asserts.ConfdbSchemaType, nil, body)
+	c.Assert(err, IsNil)
+	c.Check(fmtnum, Equals, 1)
+}
+
+func (s *confdbSuite) TestMigrations(c *C) {
+	a, err := s.assembleWithMigrations(c, 3, []any{
+		map[string]any{
+			"revision": 1,
+			"rules":    []any{map[string]any{"from": "wifi.psk", "to": "wifi.password"}},
+		},
+		map[string]any{
+			"revision": 3,
+			"rules": []any{
+				map[string]any{"from": "wifi.channel", "to": "wifi.channel", "type": "int"},
+				map[string]any{"from": "wifi.legacy"},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	ar := a.(*asserts.ConfdbSchema)
+
+	migrations := ar.MigrationsSince(0)
+	c.Assert(migrations, HasLen, 2)
+	c.Check(migrations[0].Revision, Equals, 1)
+	c.Assert(migrations[0].Rules, HasLen, 1)
+	c.Check(confdb.JoinAccessors(migrations[0].Rules[0].From), Equals, "wifi.psk")
+	c.Check(confdb.JoinAccessors(migrations[0].Rules[0].To), Equals, "wifi.password")
+	c.Check(migrations[1].Revision, Equals, 3)
+	c.Assert(migrations[1].Rules, HasLen, 2)
+	c.Check(migrations[1].Rules[0].Type, Equals, "int")
+	c.Check(migrations[1].Rules[1].To, IsNil)
+
+	migrations = ar.MigrationsSince(1)
+	c.Assert(migrations, HasLen, 1)
+	c.Check(migrations[0].Revision, Equals, 3)
+
+	c.Check(ar.MigrationsSince(3), HasLen, 0)
+}
+
+func (s *confdbSuite) TestMigrationsInvalid(c *C) {
+	rules := []any{map[string]any{"from": "wifi.psk", "to": "wifi.password"}}
+
+	type test struct {
+		migrations any
+		err        string
+	}
+
+	for _, t := range []test{
+		{migrations: "foo", err: `cannot parse migrations: json: cannot unmarshal string .*`},
+		{migrations: []any{map[string]any{"revision": 0, "rules": rules}}, err: `cannot parse migration 0: invalid revision 0`},
+		{migrations: []any{map[string]any{"revision": 3, "rules": rules}}, err: `cannot parse migration 0: invalid revision 3`},
+		{
+			migrations: []any{
+				map[string]any{"revision": 2, "rules": rules},
+				map[string]any{"revision": 1, "rules": rules},
+			},
+			err: `cannot parse migration 1: revisions must be in ascending order`,
+		},
+		{migrations: []any{map[string]any{"revision": 1}}, err: `cannot parse migration 0: no rules`},
+		{
+			migrations: []any{map[string]any{"revision": 1, "rules": []any{map[string]any{"from": "wifi.{x}", "to": "a"}}}},
+			err:        `cannot parse migration 0: invalid "from" path "wifi.{x}": .*`,
+		},
+		{
+			migrations: []any{map[string]any{"revision": 1, "rules": []any{map[string]any{"from": "a", "to": "b.."}}}},
+			err:        `cannot parse migration 0: invalid "to" path "b..": .*`,
+		},
+		{
+			migrations: []any{map[string]any{"revision": 1, "rules": []any{map[string]any{"from": "a", "type": "int"}}}},
+			err:        `cannot parse migration 0: cannot convert removed path "a"`,
+		},
+		{
+			migrations: []any{map[string]any{"revision": 1, "rules": []any{map[string]any{"from": "a", "to": "b", "type": "list"}}}},
+			err:        `cannot parse migration 0: unknown type "list"`,
+		},
+	} {
+		_, err := s.assembleWithMigrations(c, 2, t.migrations)
+		c.Check(err, ErrorMatches, "cannot assemble assertion confdb-schema: "+t.err, Commentf("%v", t.migrations))
+	}
+}
+
 type confdbCtrlSuite struct {
 	db *asserts.Database
 }
@@ -196,6 +196,9 @@ func IsUnaccceptedUpdate(err error) bool {
 type RODatabase interface {
 	// IsTrustedAccount returns whether the account is part of the trusted set.
 	IsTrustedAccount(accountID string) bool
+	// IsLocalAuthority returns whether the account is a local authority,
+	// see Database.AddLocalAuthority.
+	IsLocalAuthority(accountID string) bool
 	// WithStackedBackstore returns a database view that layers the provided
 	// backstore on top of the current one while preserving read-only access to
 	// existing assertions.
@@ -260,6 +263,8 @@ type Database struct {
 
 	trusted    Backstore
 	predefined Backstore
+	// local authorities accounts and account-keys
+	local Backstore
 	// all backstores to consider for find
 	backstores []Backstore
 	// backstores of dbs this was built on by stacking
@@ -319,15 +324,18 @@ func OpenDatabase(cfg *DatabaseConfig) (*Database, error) {
 	dbCheckers := make([]Checker, len(checkers))
 	copy(dbCheckers, checkers)
 
+	localBackstore := NewMemoryBackstore()
+
 	return &Database{
 		bs:         bs,
 		keypairMgr: keypairMgr,
 		trusted:    trustedBackstore,
 		predefined: otherPredefinedBackstore,
+		local:      localBackstore,
 		// order here is relevant, Find* precedence and
 		// findAccountKey depend on it, trusted should win over the
 		// general backstore!
-		backstores: []Backstore{trustedBackstore, otherPredefinedBackstore, bs},
+		backstores: []Backstore{trustedBackstore, otherPredefinedBackstore, localBackstore, bs},
 		checkers:   dbCheckers,
 	}, nil
 }
@@ -341,8 +349,8 @@ func (db *Database) WithStackedBackstore(backstore Backstore) *Database {
 	// original bs goes in front of stacked-on ones
 	stackedOn := []Backstore{db.bs}
 	stackedOn = append(stackedOn, db.stackedOn...)
-	// find order: trusted, predefined, new backstore, stacked-on ones
-	backstores := []Backstore{db.trusted, db.predefined}
+	// find order: trusted, predefined, local, new backstore, stacked-on ones
+	backstores := []Backstore{db.trusted, db.predefined, db.local}
 	backstores = append(backstores, backstore)
 	backstores = append(backstores, stackedOn...)
 	return &Database{
@@ -350,6 +358,7 @@ func (db *Database) WithStackedBackstore(backstore Backstore) *Database {
 		keypairMgr: db.keypairMgr,
 		trusted:    db.trusted,
 		predefined: db.predefined,
+		local:      db.local,
 		backstores: backstores,
 		stackedOn:  stackedOn,
 		checkers:   db.checkers,
@@ -424,6 +433,64 @@ func (db *Database) IsTrustedAccount(accountID string) bool {
 	return err == nil
 }
 
+// IsLocalAuthority returns whether the account is a local authority, see
+// AddLocalAuthority.
+func (db *Database) IsLocalAuthority(accountID string) bool {
+	if accountID == "" {
+		return false
+	}
+	_, err := db.local.Get(AccountType, []string{accountID}, AccountType.MaxSupportedFormat())
+	return err == nil
+}
+
+// localAuthorityTypes are the assertion types that a local authority can sign.
+var localAuthorityTypes = map[*AssertionType]bool{
+	SnapDeclarationType: true,
+	SnapRevisionType:    true,
+}
+
+// AddLocalAuthority makes the account a local authority, whose given key is
+// trusted to sign only snap-declaration and snap-revision assertions. This
+// lets locally built snaps be installed as asserted snaps. Both the account
+// and the account-key must be self-signed with that key, and the account
+// cannot be one that is trusted or already known otherwise.
+// Local authorities are not persisted by the database.
+func (db *Database) AddLocalAuthority(acct *Account, acctKey *AccountKey) error {
+	accountID := acct.AccountID()
+	if acct.AuthorityID() != accountID || acctKey.AuthorityID() != accountID || acctKey.AccountID() != accountID {
+		return fmt.Errorf("cannot add local authority %q: account and account-key must be self-signed by the account", accountID)
+	}
+	if acct.SignKeyID() != acctKey.PublicKeyID() || acctKey.SignKeyID() != acctKey.PublicKeyID() {
+		return fmt.Errorf("cannot add local authority %q: account and account-key must be signed with the account-key", accountID)
+	}
+	if db.IsTrustedAccount(accountID) {
+		return fmt.Errorf("cannot add local authority %q: account is already trusted", accountID)
+	}
+	for _, bs := range []Backstore{db.predefined, db.bs} {
+		_, err := bs.Get(AccountType, []string{accountID}, AccountType.MaxSupportedFormat())
+		if err == nil {
+			return fmt.Errorf("cannot add local authority %q: account is already known", accountID)
+		}
+		if !errors.Is(err, &NotFoundError{}) {
+			return err
+		}
+	}
+	now := timeNow()
+	for _, a := range []Assertion{acctKey, acct} {
+		if err := CheckSignature(a, acctKey, db, now, now); err != nil {
+			return fmt.Errorf("cannot add local authority %q: %v", accountID, err)
+		}
+	}
+
+	if err := db.local.Put(AccountType, acct); err != nil && !IsUnaccceptedUpdate(err) {
+		return fmt.Errorf("cannot add local authority %q: %v", accountID, err)
+	}
+	if err := db.local.Put(AccountKeyType, acctKey); err != nil && !IsUnaccceptedUpdate(err) {
+		return fmt.Errorf("cannot add local authority %q: %v", accountID, err)
+	}
+	return nil
+}
+
 var timeNow = time.Now
 
 // SetEarliestTime affects how key expiration is checked.
@@ -460,6 +527,9 @@ func (db *Database) Check(assert Assertion) error {
 		if err != nil {
 			return fmt.Errorf("error finding matching public key for signature: %v", err)
 		}
+		if db.IsLocalAuthority(assert.AuthorityID()) && !localAuthorityTypes[typ] {
+			return fmt.Errorf("%q assertion cannot be signed by local authority %q", typ.Name, assert.AuthorityID())
+		}
 	} else {
 		if assert.AuthorityID() != "" {
 			return fmt.Errorf("internal error: %q assertion cannot have authority-id set", typ.Name)
@@ -1698,3 +1698,149 @@ func (s *isUnacceptedUpdateSuite) TestIsUnacceptedUpdate(c *C) {
 		c.Check(asserts.IsUnaccceptedUpdate(t.err), Equals, t.keptCurrent, Commentf("%v", t.err))
 	}
 }
+
+type localAuthoritySuite struct {
+	storeStack *assertstest.StoreStack
+	db         *asserts.Database
+
+	localSigning *assertstest.SigningDB
+	localPubKey  asserts.PublicKey
+	localAcct    *asserts.Account
+	localKey     *asserts.AccountKey
+}
+
+var _ = Suite(&localAuthoritySuite{})
+
+func (las *localAuthoritySuite) SetUpTest(c *C) {
+	las.storeStack, las.db = makeStoreAndCheckDB(c)
+
+	privKey, _ := assertstest.GenerateKey(752)
+	las.localSigning = assertstest.NewSigningDB("acme-local", privKey)
+	las.localPubKey = privKey.PublicKey()
+	las.localAcct = assertstest.NewAccount(las.localSigning, "acme-local", map[string]any{
+		"account-id": "acme-local",
+	}, "")
+	las.localKey = assertstest.NewAccountKey(las.localSigning, las.localAcct, nil, privKey.PublicKey(), "")
+}
+
+func (las *localAuthoritySuite) snapDecl(c *C, signing assertstest.SignerDB, snapID, publisherID string) asserts.Assertion {
+	a, err := signing.Sign(asserts.SnapDeclarationType, map[string]any{
+		"series":       "16",
+		"snap-id":      snapID,
+		"snap-name":    "foo",
+		"publisher-id": publisherID,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return a
+}
+
+func (las *localAuthoritySuite) snapRev(c *C, signing assertstest.SignerDB, snapID, developerID string) asserts.Assertion {
+	a, err := signing.Sign(asserts.SnapRevisionType, map[string]any{
+		"snap-sha3-384": blobSHA3_384,
+		"snap-id":       snapID,
+		"snap-size":     "1000",
+		"snap-revision": "1",
+		"developer-id":  developerID,
+		"timestamp":     time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return a
+}
+
+func (las *localAuthoritySuite) TestAddLocalAuthority(c *C) {
+	c.Check(las.db.IsLocalAuthority("acme-local"), Equals, false)
+
+	err := las.db.AddLocalAuthority(las.localAcct, las.localKey)
+	c.Assert(err, IsNil)
+	c.Check(las.db.IsLocalAuthority("acme-local"), Equals, true)
+	c.Check(las.db.IsTrustedAccount("acme-local"), Equals, false)
+	c.Check(las.db.IsLocalAuthority("canonical"), Equals, false)
+
+	// the account is found
+	a, err := las.db.Find(asserts.AccountType, map[string]string{"account-id": "acme-local"})
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.Account).AccountID(), Equals, "acme-local")
+
+	// adding it again is fine
+	err = las.db.AddLocalAuthority(las.localAcct, las.localKey)
+	c.Assert(err, IsNil)
+
+	// the local authority can sign snap-declarations and snap-revisions
+	// for its snaps
+	err = las.db.Add(las.snapDecl(c, las.localSigning, "local-snap-id", "acme-local"))
+	c.Assert(err, IsNil)
+	err = las.db.Add(las.snapRev(c, las.localSigning, "local-snap-id", "acme-local"))
+	c.Assert(err, IsNil)
+
+	// also in stacked databases
+	stacked := las.db.WithStackedBackstore(asserts.NewMemoryBackstore())
+	c.Check(stacked.IsLocalAuthority("acme-local"), Equals, true)
+	c.Check(stacked.Check(las.snapRev(c, las.localSigning, "local-snap-id", "acme-local")), IsNil)
+}
+
+func (las *localAuthoritySuite) TestLocalAuthorityScope(c *C) {
+	err := las.db.AddLocalAuthority(las.localAcct, las.localKey)
+	c.Assert(err, IsNil)
+
+	// other assertion types cannot be signed
+	otherAcct := assertstest.NewAccount(las.localSigning, "other", nil, "")
+	err = las.db.Add(otherAcct)
+	c.Check(err, ErrorMatches, `"account" assertion cannot be signed by local authority "acme-local"`)
+
+	// snaps declared by the store cannot be taken over
+	prereqDevAccount(c, las.storeStack, las.db)
+	err = las.db.Add(las.snapDecl(c, las.storeStack, "store-snap-id", "dev-id1"))
+	c.Assert(err, IsNil)
+	err = las.db.Add(las.snapDecl(c, las.localSigning, "store-snap-id", "acme-local"))
+	c.Check(err, ErrorMatches, `snap-declaration assertion for "foo" \(id "store-snap-id"\) by local authority "acme-local" cannot replace one by "canonical"`)
+	err = las.db.Add(las.snapRev(c, las.localSigning, "store-snap-id", "dev-id1"))
+	c.Check(err, ErrorMatches, `snap-revision assertion for snap id "store-snap-id" by local authority "acme-local" does not match the authority of the snap-declaration: canonical`)
+}
+
+func (las *localAuthoritySuite) TestLocalAuthorityCannotSpoofPublisher(c *C) {
+	err := las.db.AddLocalAuthority(las.localAcct, las.localKey)
+	c.Assert(err, IsNil)
+	prereqDevAccount(c, las.storeStack, las.db)
+
+	// neither a store publisher nor the store itself
+	for _, publisherID := range []string{"dev-id1", "canonical"} {
+		err = las.db.Add(las.snapDecl(c, las.localSigning, "local-snap-id", publisherID))
+		c.Check(err, ErrorMatches, fmt.Sprintf(`snap-declaration assertion for "foo" \(id "local-snap-id"\) by local authority "acme-local" cannot have publisher %q`, publisherID))
+	}
+}
+
+func (las *localAuthoritySuite) TestAddLocalAuthorityErrors(c *C) {
+	// account-key signed by the store
+	storeSignedKey := assertstest.NewAccountKey(las.storeStack, las.localAcct, nil, las.localPubKey, "")
+	err := las.db.AddLocalAuthority(las.localAcct, storeSignedKey)
+	c.Check(err, ErrorMatches, `cannot add local authority "acme-local": account and account-key must be self-signed by the account`)
+
+	// account-key signed with another key of the account
+	otherPrivKey, _ := assertstest.GenerateKey(752)
+	c.Assert(las.localSigning.ImportKey(otherPrivKey), IsNil)
+	otherKey := assertstest.NewAccountKey(las.localSigning, las.localAcct, nil, otherPrivKey.PublicKey(), "")
+	err = las.db.AddLocalAuthority(las.localAcct, otherKey)
+	c.Check(err, ErrorMatches, `cannot add local authority "acme-local": account and account-key must be signed with the account-key`)
+
+	for _, accountID := range []string{"canonical", "dev-id1"} {
+		privKey, _ := assertstest.GenerateKey(752)
+		signing := assertstest.NewSigningDB(accountID, privKey)
+		acct := assertstest.NewAccount(signing, accountID, map[string]any{
+			"account-id": accountID,
+		}, "")
+		key := assertstest.NewAccountKey(signing, acct, nil, privKey.PublicKey(), "")
+
+		if accountID == "canonical" {
+			err = las.db.AddLocalAuthority(acct, key)
+			c.Check(err, ErrorMatches, `cannot add local authority "canonical": account is already trusted`)
+			continue
+		}
+		// accounts known from the store cannot be taken over either
+		prereqDevAccount(c, las.storeStack, las.db)
+		err = las.db.AddLocalAuthority(acct, key)
+		c.Check(err, ErrorMatches, `cannot add local authority "dev-id1": account is already known`)
+	}
+	c.Check(las.db.IsLocalAuthority("canonical"), Equals, false)
+	c.Check(las.db.IsLocalAuthority("dev-id1"), Equals, false)
+}
@@ -119,7 +119,25 @@ func (snapdcl *SnapDeclaration) RevisionAuthority(provenance string) []*Revision
 
 // Implement further consistency checks.
 func (snapdcl *SnapDeclaration) checkConsistency(db RODatabase, acck *AccountKey) error {
-	if !db.IsTrustedAccount(snapdcl.AuthorityID()) {
+	if db.IsLocalAuthority(snapdcl.AuthorityID()) {
+		// a local authority can only declare its own snaps, it
+		// cannot pose as another publisher
+		if snapdcl.PublisherID() != snapdcl.AuthorityID() {
+			return fmt.Errorf("snap-declaration assertion for %q (id %q) by local authority %q cannot have publisher %q", snapdcl.SnapName(), snapdcl.SnapID(), snapdcl.AuthorityID(), snapdcl.PublisherID())
+		}
+		// a local authority cannot take over snaps declared by
+		// another authority
+		a, err := db.Find(SnapDeclarationType, map[string]string{
+			"series":  snapdcl.Series(),
+			"snap-id": snapdcl.SnapID(),
+		})
+		if err != nil && !errors.Is(err, &NotFoundError{}) {
+			return err
+		}
+		if err == nil && a.AuthorityID() != snapdcl.AuthorityID() {
+			return fmt.Errorf("snap-declaration assertion for %q (id %q) by local authority %q cannot replace one by %q", snapdcl.SnapName(), snapdcl.SnapID(), snapdcl.AuthorityID(), a.AuthorityID())
+		}
+	} else if !db.IsTrustedAccount(snapdcl.AuthorityID()) {
 		return fmt.Errorf("snap-declaration assertion for %q (id %q) is not signed by a directly trusted authority: %s", snapdcl.SnapName(), snapdcl.SnapID(), snapdcl.AuthorityID())
 	}
 	_, err := db.Find(AccountType, map[string]string{
@@ -654,7 +672,7 @@ func (snaprev *SnapRevision) SnapIntegrityData() []IntegrityData {
 // Implement further consistency checks.
 func (snaprev *SnapRevision) checkConsistency(db RODatabase, acck *AccountKey) error {
 	otherProvenance := snaprev.Provenance() != naming.DefaultProvenance
-	if !otherProvenance && !db.IsTrustedAccount(snaprev.AuthorityID()) {
+	if !otherProvenance && !db.IsTrustedAccount(snaprev.AuthorityID()) && !db.IsLocalAuthority(snaprev.AuthorityID()) {
 		// delegating global-upload revisions is not allowed
 		return fmt.Errorf("snap-revision assertion for snap id %q is not signed by a store: %s", snaprev.SnapID(), snaprev.AuthorityID())
 	}
@@ -678,6 +696,11 @@ func (snaprev *SnapRevision) checkConsistency(db RODatabase, acck *AccountKey) e
 	if err != nil {
 		return err
 	}
+	if !otherProvenance && db.IsLocalAuthority(snaprev.AuthorityID()) && a.AuthorityID() != snaprev.AuthorityID() {
+		// a local authority can only sign revisions of the snaps it
+		// declared itself
+		return fmt.Errorf("snap-revision assertion for snap id %q by local authority %q does not match the authority of the snap-declaration: %s", snaprev.SnapID(), snaprev.AuthorityID(), a.AuthorityID())
+	}
 	if otherProvenance {
 		decl := a.(*SnapDeclaration)
 		ras := decl.RevisionAuthority(snaprev.Provenance())
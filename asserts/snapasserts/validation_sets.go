@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -1210,3 +1211,49 @@ func (s *installedSnapSet) LookupComponent(snapRef naming.SnapRef, compRef namin
 	}
 	return nil
 }
+
+// ValidationSetSnapDiff describes how the constraints on a snap differ
+// between two validation sets.
+type ValidationSetSnapDiff struct {
+	Name string
+	// From and To are the constraints on the snap of the respective
+	// validation sets, nil if the snap is not listed in it.
+	From *asserts.ValidationSetSnap
+	To   *asserts.ValidationSetSnap
+}
+
+// DiffValidationSets returns the snaps whose constraints differ between
+// the validation sets from and to, usually two sequences of the same
+// set, sorted by snap name.
+func DiffValidationSets(from, to *asserts.ValidationSet) []ValidationSetSnapDiff {
+	diffs := make(map[string]*ValidationSetSnapDiff)
+	for _, sn := range from.Snaps() {
+		diffs[sn.SnapID] = &ValidationSetSnapDiff{Name: sn.Name, From: sn}
+	}
+	for _, sn := range to.Snaps() {
+		d := diffs[sn.SnapID]
+		if d == nil {
+			diffs[sn.SnapID] = &ValidationSetSnapDiff{Name: sn.Name, To: sn}
+			continue
+		}
+		// the snap could have been renamed
+		d.Name = sn.Name
+		d.To = sn
+		if sameSnapConstraints(d.From, d.To) {
+			delete(diffs, sn.SnapID)
+		}
+	}
+
+	res := make([]ValidationSetSnapDiff, 0, len(diffs))
+	for _, d := range diffs {
+		res = append(res, *d)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func sameSnapConstraints(sn1, sn2 *asserts.ValidationSetSnap) bool {
+	return sn1.Presence == sn2.Presence &&
+		sn1.Revision == sn2.Revision &&
+		reflect.DeepEqual(sn1.Components, sn2.Components)
+}
@@ -2125,3 +2125,89 @@ func (s *validationSetsSuite) TestSnapPresence(c *C) {
 		Presence: asserts.PresenceOptional,
 	})
 }
+
+func (s *validationSetsSuite) TestDiffValidationSets(c *C) {
+	from := assertstest.FakeAssertion(map[string]any{
+		"type":         "validation-set",
+		"authority-id": "account-id",
+		"series":       "16",
+		"account-id":   "account-id",
+		"name":         "my-snap-ctl",
+		"sequence":     "1",
+		"snaps": []any{
+			map[string]any{
+				"name":     "same-snap",
+				"id":       "samesnapidididididididididididid",
+				"revision": "3",
+			},
+			map[string]any{
+				"name":     "my-snap",
+				"id":       "mysnapididididididididididididid",
+				"presence": "required",
+				"revision": "7",
+			},
+			map[string]any{
+				"name":     "dropped-snap",
+				"id":       "droppedsnapididididididididididi",
+				"presence": "optional",
+			},
+			map[string]any{
+				"name": "comp-snap",
+				"id":   "compsnapidididididididididididid",
+				"components": map[string]any{
+					"comp": "optional",
+				},
+			},
+		},
+	}).(*asserts.ValidationSet)
+	to := assertstest.FakeAssertion(map[string]any{
+		"type":         "validation-set",
+		"authority-id": "account-id",
+		"series":       "16",
+		"account-id":   "account-id",
+		"name":         "my-snap-ctl",
+		"sequence":     "2",
+		"snaps": []any{
+			map[string]any{
+				"name":     "same-snap",
+				"id":       "samesnapidididididididididididid",
+				"presence": "required",
+				"revision": "3",
+			},
+			map[string]any{
+				"name":     "my-snap",
+				"id":       "mysnapididididididididididididid",
+				"revision": "8",
+			},
+			map[string]any{
+				"name":     "added-snap",
+				"id":       "addedsnapidididididididididididi",
+				"presence": "invalid",
+			},
+			map[string]any{
+				"name": "comp-snap",
+				"id":   "compsnapidididididididididididid",
+				"components": map[string]any{
+					"comp": "required",
+				},
+			},
+		},
+	}).(*asserts.ValidationSet)
+
+	diff := snapasserts.DiffValidationSets(from, to)
+	c.Assert(diff, HasLen, 4)
+	c.Check(diff[0].Name, Equals, "added-snap")
+	c.Check(diff[0].From, IsNil)
+	c.Check(diff[0].To.Presence, Equals, asserts.PresenceInvalid)
+	c.Check(diff[1].Name, Equals, "comp-snap")
+	c.Check(diff[1].From.Components["comp"].Presence, Equals, asserts.PresenceOptional)
+	c.Check(diff[1].To.Components["comp"].Presence, Equals, asserts.PresenceRequired)
+	c.Check(diff[2].Name, Equals, "dropped-snap")
+	c.Check(diff[2].From.Presence, Equals, asserts.PresenceOptional)
+	c.Check(diff[2].To, IsNil)
+	c.Check(diff[3].Name, Equals, "my-snap")
+	c.Check(diff[3].From.Revision, Equals, 7)
+	c.Check(diff[3].To.Revision, Equals, 8)
+
+	c.Check(snapasserts.DiffValidationSets(to, to), HasLen, 0)
+}
@@ -47,6 +47,20 @@ func (client *Client) Ack(b []byte) error {
 	return nil
 }
 
+// AckLocalAuthority registers a local authority from the given self-signed
+// account and account-key assertions. The local authority can then sign
+// snap-declaration and snap-revision assertions for locally built snaps.
+func (client *Client) AckLocalAuthority(b []byte) error {
+	q := url.Values{}
+	q.Set("local-authority", "true")
+	var rsp any
+	if _, err := client.doSync("POST", "/v2/assertions", q, nil, bytes.NewReader(b), &rsp); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // AssertionTypes returns a list of assertion type names.
 func (client *Client) AssertionTypes() ([]string, error) {
 	var types struct {
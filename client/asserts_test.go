@@ -48,6 +48,22 @@ func (cs *clientSuite) TestClientAssert(c *C) {
 	c.Check(cs.req.URL.Path, Equals, "/v2/assertions")
 }
 
+func (cs *clientSuite) TestClientAckLocalAuthority(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {}
+	}`
+	a := []byte("Assertions.")
+	err := cs.cli.AckLocalAuthority(a)
+	c.Assert(err, IsNil)
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	c.Check(body, DeepEquals, a)
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/assertions")
+	c.Check(cs.req.URL.Query().Get("local-authority"), Equals, "true")
+}
+
 func (cs *clientSuite) TestClientAssertsTypes(c *C) {
 	cs.rsp = `{
     "result": {
@@ -672,6 +672,17 @@ type RefreshInfo struct {
 	Last     string `json:"last,omitempty"`
 	Hold     string `json:"hold,omitempty"`
 	Next     string `json:"next,omitempty"`
+	// Snaps contains the refresh information of the snaps with their
+	// own refresh.snap-timer.<snap> setting, keyed by snap name.
+	Snaps map[string]SnapRefreshInfo `json:"snaps,omitempty"`
+}
+
+// SnapRefreshInfo contains information about the refreshes of a snap with
+// its own refresh timer.
+type SnapRefreshInfo struct {
+	Timer string `json:"timer"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
 }
 
 // SysInfo holds system information
@@ -0,0 +1,120 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2020-2023 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+// ClusterStatus holds the convergence status of all the devices in the
+// cluster, as seen from the device snapd is running on.
+type ClusterStatus struct {
+	ClusterID string                `json:"cluster-id"`
+	Sequence  int                   `json:"sequence"`
+	Devices   []ClusterDeviceStatus `json:"devices"`
+}
+
+// ClusterDeviceStatus holds what is known about a device of the cluster.
+type ClusterDeviceStatus struct {
+	ID          int      `json:"id"`
+	BrandID     string   `json:"brand-id"`
+	Model       string   `json:"model"`
+	Serial      string   `json:"serial"`
+	Addresses   []string `json:"addresses,omitempty"`
+	Subclusters []string `json:"subclusters,omitempty"`
+	// Local is true for the device snapd is running on.
+	Local bool `json:"local,omitempty"`
+	// Report is the latest convergence report received from the device, if
+	// any.
+	Report *ClusterReport `json:"report,omitempty"`
+}
+
+// ClusterReport is the convergence report of a device.
+type ClusterReport struct {
+	Sequence      int                   `json:"sequence"`
+	Snaps         []ClusterReportedSnap `json:"snaps,omitempty"`
+	FailedChanges []ClusterFailedChange `json:"failed-changes,omitempty"`
+	Converged     bool                  `json:"converged"`
+	LastConverged time.Time             `json:"last-converged"`
+	// Rollout maps the names of the subclusters that are rolled out in
+	// batches to the rollout status of the device.
+	Rollout map[string]string `json:"rollout,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+// ClusterReportedSnap describes a snap installed on a device because of the
+// cluster assertion.
+type ClusterReportedSnap struct {
+	Instance string        `json:"instance"`
+	Revision snap.Revision `json:"revision"`
+	Channel  string        `json:"channel,omitempty"`
+}
+
+// ClusterFailedChange describes a change that failed to apply the cluster
+// assertion on a device.
+type ClusterFailedChange struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// ClusterStatus returns the convergence status of the cluster.
+func (client *Client) ClusterStatus() (*ClusterStatus, error) {
+	var status ClusterStatus
+	if _, err := client.doSync("GET", "/v2/cluster/status", nil, nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ClusterAssembleOptions are the parameters of a cluster assembly session.
+type ClusterAssembleOptions struct {
+	// Secret is shared by all the devices taking part in the session.
+	Secret string `json:"secret"`
+	// Address is the address at which the device receives messages.
+	Address string `json:"address"`
+	// ExpectedSize is the number of devices taking part in the session.
+	ExpectedSize int `json:"expected-size"`
+	// Peers are the addresses of the other devices, discovered over mDNS
+	// if empty.
+	Peers []string `json:"peers,omitempty"`
+}
+
+// ClusterAssemble makes the device take part in a cluster assembly session.
+func (client *Client) ClusterAssemble(opts ClusterAssembleOptions) (changeID string, err error) {
+	data, err := json.Marshal(struct {
+		Action string `json:"action"`
+		ClusterAssembleOptions
+	}{
+		Action:                 "assemble",
+		ClusterAssembleOptions: opts,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return client.doAsync("POST", "/v2/cluster", nil, map[string]string{"Content-Type": "application/json"}, bytes.NewReader(data))
+}
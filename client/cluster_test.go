@@ -0,0 +1,147 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2020-2023 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (cs *clientSuite) TestClusterStatus(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"cluster-id": "cluster-id",
+			"sequence": 2,
+			"devices": [
+				{
+					"id": 1,
+					"brand-id": "my-brand",
+					"model": "my-model",
+					"serial": "serial-1",
+					"addresses": ["10.0.0.1:8001"],
+					"subclusters": ["default"],
+					"local": true,
+					"report": {
+						"serial": "serial-1",
+						"cluster-id": "cluster-id",
+						"sequence": 2,
+						"snaps": [{"instance": "some-snap", "revision": "3", "channel": "latest/stable"}],
+						"failed-changes": [{"id": "7", "kind": "apply-cluster-subcluster", "summary": "Apply subcluster", "error": "boom", "time": "2026-01-02T03:00:00Z"}],
+						"converged": false,
+						"last-converged": "2026-01-01T00:00:00Z",
+						"time": "2026-01-02T03:04:05Z"
+					}
+				},
+				{"id": 2, "brand-id": "my-brand", "model": "my-model", "serial": "serial-2"}
+			]
+		}
+	}`
+
+	status, err := cs.cli.ClusterStatus()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster/status")
+	c.Check(status, check.DeepEquals, &client.ClusterStatus{
+		ClusterID: "cluster-id",
+		Sequence:  2,
+		Devices: []client.ClusterDeviceStatus{{
+			ID:          1,
+			BrandID:     "my-brand",
+			Model:       "my-model",
+			Serial:      "serial-1",
+			Addresses:   []string{"10.0.0.1:8001"},
+			Subclusters: []string{"default"},
+			Local:       true,
+			Report: &client.ClusterReport{
+				Sequence: 2,
+				Snaps: []client.ClusterReportedSnap{
+					{Instance: "some-snap", Revision: snap.R(3), Channel: "latest/stable"},
+				},
+				FailedChanges: []client.ClusterFailedChange{{
+					ID:      "7",
+					Kind:    "apply-cluster-subcluster",
+					Summary: "Apply subcluster",
+					Error:   "boom",
+					Time:    time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+				}},
+				LastConverged: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Time:          time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		}, {
+			ID:      2,
+			BrandID: "my-brand",
+			Model:   "my-model",
+			Serial:  "serial-2",
+		}},
+	})
+}
+
+func (cs *clientSuite) TestClusterStatusError(c *check.C) {
+	cs.status = 404
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 404,
+		"result": {"message": "this device is not part of a cluster"}
+	}`
+
+	_, err := cs.cli.ClusterStatus()
+	c.Check(err, check.ErrorMatches, "this device is not part of a cluster")
+}
+
+func (cs *clientSuite) TestClusterAssemble(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": {},
+		"change": "42"
+	}`
+
+	id, err := cs.cli.ClusterAssemble(client.ClusterAssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:8001",
+		ExpectedSize: 2,
+		Peers:        []string{"10.0.0.2:8001"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "42")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/cluster")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var data map[string]any
+	c.Assert(json.Unmarshal(body, &data), check.IsNil)
+	c.Check(data, check.DeepEquals, map[string]any{
+		"action":        "assemble",
+		"secret":        "secret",
+		"address":       "10.0.0.1:8001",
+		"expected-size": float64(2),
+		"peers":         []any{"10.0.0.2:8001"},
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 func (c *Client) ConfdbGetViaView(viewID string, requests []string) (changeID string, err error) {
@@ -52,3 +53,81 @@ func (c *Client) ConfdbSetViaView(viewID string, requestValues map[string]any) (
 	endpoint := fmt.Sprintf("/v2/confdb/%s", viewID)
 	return c.doAsync("PUT", endpoint, nil, headers, bytes.NewReader(bodyRaw))
 }
+
+// ConfdbHistoryEntry holds a transaction committed to a confdb.
+type ConfdbHistoryEntry struct {
+	ID       int                  `json:"id"`
+	Time     time.Time            `json:"time"`
+	Author   string               `json:"author,omitempty"`
+	ChangeID string               `json:"change-id,omitempty"`
+	Diff     []ConfdbHistoryDelta `json:"diff"`
+}
+
+// ConfdbHistoryDelta holds the value of a storage path before and after a
+// transaction was committed.
+type ConfdbHistoryDelta struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// ConfdbHistory returns the transactions committed to the confdb which
+// altered data visible through the view, oldest first.
+func (c *Client) ConfdbHistory(viewID string) ([]*ConfdbHistoryEntry, error) {
+	query := url.Values{}
+	query.Set("history", "true")
+	endpoint := fmt.Sprintf("/v2/confdb/%s", viewID)
+
+	var history []*ConfdbHistoryEntry
+	if _, err := c.doSync("GET", endpoint, query, nil, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ConfdbRollback reverts the changes made to the confdb by the transaction
+// with the given ID and by all transactions committed after it.
+func (c *Client) ConfdbRollback(schemaID string, txID int) (changeID string, err error) {
+	body, err := json.Marshal(map[string]any{
+		"action":         "rollback",
+		"transaction-id": txID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	endpoint := fmt.Sprintf("/v2/confdb/%s", schemaID)
+	return c.doAsync("POST", endpoint, nil, headers, bytes.NewReader(body))
+}
+
+// ConfdbExport returns all the data stored in the confdb.
+func (c *Client) ConfdbExport(schemaID string) (map[string]any, error) {
+	endpoint := fmt.Sprintf("/v2/confdb/%s", schemaID)
+
+	var data map[string]any
+	if _, err := c.doSync("GET", endpoint, nil, nil, nil, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ConfdbImport replaces all the data stored in the confdb with the provided
+// data.
+func (c *Client) ConfdbImport(schemaID string, data map[string]any) (changeID string, err error) {
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"action": "import",
+		"data":   data,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	endpoint := fmt.Sprintf("/v2/confdb/%s", schemaID)
+	return c.doAsync("POST", endpoint, nil, headers, bytes.NewReader(body))
+}
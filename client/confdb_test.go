@@ -23,8 +23,11 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"time"
 
 	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
 )
 
 func (cs *clientSuite) TestConfdbGet(c *C) {
@@ -63,3 +66,79 @@ func (cs *clientSuite) TestConfdbSet(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(res, DeepEquals, map[string]any{"values": map[string]any{"foo": "bar", "baz": float64(1)}})
 }
+
+func (cs *clientSuite) TestConfdbHistory(c *C) {
+	cs.rsp = `{"type": "sync", "result": [
+		{"id": 1, "time": "2026-10-18T12:00:00Z", "author": "jane", "change-id": "12", "diff": [{"path": "wifi.ssid", "new": "foo"}]},
+		{"id": 2, "time": "2026-10-18T12:05:00Z", "change-id": "13", "diff": [{"path": "wifi.ssid", "old": "foo"}]}
+	]}`
+
+	history, err := cs.cli.ConfdbHistory("a/b/c")
+	c.Assert(err, IsNil)
+	c.Check(cs.reqs[0].Method, Equals, "GET")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/confdb/a/b/c")
+	c.Check(cs.reqs[0].URL.Query(), DeepEquals, url.Values{"history": []string{"true"}})
+	c.Check(history, DeepEquals, []*client.ConfdbHistoryEntry{
+		{
+			ID:       1,
+			Time:     time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+			Author:   "jane",
+			ChangeID: "12",
+			Diff:     []client.ConfdbHistoryDelta{{Path: "wifi.ssid", New: "foo"}},
+		},
+		{
+			ID:       2,
+			Time:     time.Date(2026, 10, 18, 12, 5, 0, 0, time.UTC),
+			ChangeID: "13",
+			Diff:     []client.ConfdbHistoryDelta{{Path: "wifi.ssid", Old: "foo"}},
+		},
+	})
+}
+
+func (cs *clientSuite) TestConfdbRollback(c *C) {
+	cs.status = 202
+	cs.rsp = `{"type": "async", "status-code": 202, "change": "123"}`
+
+	chgID, err := cs.cli.ConfdbRollback("a/b", 3)
+	c.Assert(err, IsNil)
+	c.Check(chgID, Equals, "123")
+	c.Assert(cs.reqs, HasLen, 1)
+	c.Check(cs.reqs[0].Method, Equals, "POST")
+	c.Check(cs.reqs[0].Header.Get("Content-Type"), Equals, "application/json")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/confdb/a/b")
+
+	var body map[string]any
+	c.Assert(json.NewDecoder(cs.reqs[0].Body).Decode(&body), IsNil)
+	c.Check(body, DeepEquals, map[string]any{"action": "rollback", "transaction-id": float64(3)})
+}
+
+func (cs *clientSuite) TestConfdbExport(c *C) {
+	cs.rsp = `{"type": "sync", "status-code": 200, "result": {"wifi": {"ssid": "foo"}}}`
+
+	data, err := cs.cli.ConfdbExport("a/b")
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, map[string]any{"wifi": map[string]any{"ssid": "foo"}})
+	c.Assert(cs.reqs, HasLen, 1)
+	c.Check(cs.reqs[0].Method, Equals, "GET")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/confdb/a/b")
+}
+
+func (cs *clientSuite) TestConfdbImport(c *C) {
+	cs.status = 202
+	cs.rsp = `{"type": "async", "status-code": 202, "change": "123"}`
+
+	chgID, err := cs.cli.ConfdbImport("a/b", map[string]any{"wifi": map[string]any{"ssid": "foo"}})
+	c.Assert(err, IsNil)
+	c.Check(chgID, Equals, "123")
+	c.Assert(cs.reqs, HasLen, 1)
+	c.Check(cs.reqs[0].Method, Equals, "POST")
+	c.Check(cs.reqs[0].Header.Get("Content-Type"), Equals, "application/json")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/confdb/a/b")
+
+	var body map[string]any
+	c.Assert(json.NewDecoder(cs.reqs[0].Body).Decode(&body), IsNil)
+	c.Check(body, DeepEquals, map[string]any{
+		"action": "import",
+		"data":   map[string]any{"wifi": map[string]any{"ssid": "foo"}},
+	})
+}
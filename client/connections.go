@@ -21,6 +21,7 @@ package client
 
 import (
 	"net/url"
+	"time"
 )
 
 // Connection describes a connection between a plug and a slot.
@@ -36,6 +37,9 @@ type Connection struct {
 	SlotAttrs map[string]any `json:"slot-attrs,omitempty"`
 	// PlugAttrs is the list of attributes of the plug side of the connection.
 	PlugAttrs map[string]any `json:"plug-attrs,omitempty"`
+	// Expiry is the time after which a time-limited connection is
+	// automatically removed.
+	Expiry time.Time `json:"expiry,omitzero"`
 }
 
 // Connections contains information about connections, as well as related plugs
@@ -79,3 +83,68 @@ func (client *Client) Connections(opts *ConnectionOptions) (Connections, error)
 	_, err := client.doSync("GET", "/v2/connections", query, nil, nil, &conns)
 	return conns, err
 }
+
+// PolicyExplanation explains the declaration based policy decisions
+// about connecting a plug to a slot.
+type PolicyExplanation struct {
+	Plug      PlugRef `json:"plug"`
+	Slot      SlotRef `json:"slot"`
+	Interface string  `json:"interface"`
+	// Connect is the decision about manual connections.
+	Connect *PolicyDecision `json:"connect,omitempty"`
+	// ConnectUnchecked is set when the plug or slot snap has no
+	// snap-declaration, manual connections are then not checked
+	// against the policy.
+	ConnectUnchecked bool `json:"connect-unchecked,omitempty"`
+	// AutoConnect is the decision about auto-connections.
+	AutoConnect *PolicyDecision `json:"auto-connect,omitempty"`
+	// AutoConnectCandidate is set if the slot is among the resulting
+	// auto-connection candidates of the plug.
+	AutoConnectCandidate bool     `json:"auto-connect-candidate,omitempty"`
+	Notes                []string `json:"notes,omitempty"`
+}
+
+// PolicyDecision describes how a connection or auto-connection policy
+// decision was reached.
+type PolicyDecision struct {
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message"`
+	// Origin is either "base-declaration" or "snap-declaration", it
+	// is empty if no rule applies to the interface.
+	Origin string `json:"origin,omitempty"`
+	// Snap is the name of the snap whose snap-declaration provided
+	// the rule.
+	Snap string `json:"snap,omitempty"`
+	// Side is either "plug" or "slot" depending on the rule used.
+	Side string `json:"side,omitempty"`
+	// Deny and Allow describe the evaluation of the alternative
+	// constraints of the deny and allow sections of the rule.
+	Deny            []PolicyConstraints `json:"deny,omitempty"`
+	Allow           []PolicyConstraints `json:"allow,omitempty"`
+	SlotsPerPlugAny bool                `json:"slots-per-plug-any,omitempty"`
+}
+
+// PolicyConstraints describes the evaluation of one alternative of
+// rule constraints.
+type PolicyConstraints struct {
+	Matched bool `json:"matched"`
+	// Mismatch describes the first constraint that did not match.
+	Mismatch string `json:"mismatch,omitempty"`
+}
+
+// ExplainConnections explains the policy decisions about connecting the
+// plugs and slots selected by the Snap and Interface options.
+func (client *Client) ExplainConnections(opts *ConnectionOptions) ([]PolicyExplanation, error) {
+	var expls []PolicyExplanation
+	params := map[string]string{}
+	if opts != nil && opts.Snap != "" {
+		params["snap"] = opts.Snap
+	}
+	if opts != nil && opts.Interface != "" {
+		params["interface"] = opts.Interface
+	}
+	if err := client.DebugGet("interface-policy", &expls, params); err != nil {
+		return nil, err
+	}
+	return expls, nil
+}
@@ -268,3 +268,47 @@ func (cs *clientSuite) TestClientConnectionsFilter(c *check.C) {
 		"snap":      []string{"foo"},
 	})
 }
+
+func (cs *clientSuite) TestClientExplainConnections(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{
+				"plug": {"snap": "consumer", "plug": "plug"},
+				"slot": {"snap": "producer", "slot": "slot"},
+				"interface": "test",
+				"connect": {"allowed": true, "message": "connection allowed, no rule applies"},
+				"auto-connect": {
+					"allowed": false,
+					"message": "auto-connection not allowed by slot rule of interface \"test\"",
+					"origin": "base-declaration",
+					"side": "slot",
+					"allow": [{"matched": false, "mismatch": "publisher id does not match"}]
+				},
+				"notes": ["a note"]
+			}
+		]
+	}`
+	expls, err := cs.cli.ExplainConnections(&client.ConnectionOptions{Snap: "consumer", Interface: "test"})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/debug")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"aspect":    []string{"interface-policy"},
+		"snap":      []string{"consumer"},
+		"interface": []string{"test"},
+	})
+	c.Check(expls, check.DeepEquals, []client.PolicyExplanation{{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      client.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Connect:   &client.PolicyDecision{Allowed: true, Message: "connection allowed, no rule applies"},
+		AutoConnect: &client.PolicyDecision{
+			Message: `auto-connection not allowed by slot rule of interface "test"`,
+			Origin:  "base-declaration",
+			Side:    "slot",
+			Allow:   []client.PolicyConstraints{{Mismatch: "publisher id does not match"}},
+		},
+		Notes: []string{"a note"},
+	}})
+}
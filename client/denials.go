@@ -0,0 +1,54 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+// Denial describes a group of identical AppArmor or seccomp denials of
+// a snap, together with the interfaces that would allow the access.
+type Denial struct {
+	Snap     string        `json:"snap"`
+	App      string        `json:"app,omitempty"`
+	Revision snap.Revision `json:"revision"`
+	// Kind is either "apparmor" or "seccomp".
+	Kind      string    `json:"kind"`
+	Access    string    `json:"access"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first-seen"`
+	LastSeen  time.Time `json:"last-seen"`
+	// Interfaces are the interfaces that would allow the access.
+	Interfaces []string `json:"interfaces,omitempty"`
+}
+
+// Denials returns the AppArmor and seccomp denials recorded for the
+// given snap.
+func (client *Client) Denials(snapName string) ([]Denial, error) {
+	var denials []Denial
+	query := url.Values{"snap": []string{snapName}}
+	if _, err := client.doSync("GET", "/v2/debug/denials", query, nil, nil, &denials); err != nil {
+		return nil, err
+	}
+	return denials, nil
+}
@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"net/url"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (cs *clientSuite) TestClientDenials(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{
+				"snap": "foo",
+				"app": "app",
+				"revision": "2",
+				"kind": "apparmor",
+				"access": "open /dev/video0 (r)",
+				"count": 3,
+				"first-seen": "2026-10-01T10:00:00Z",
+				"last-seen": "2026-10-01T10:05:00Z",
+				"interfaces": ["camera"]
+			}
+		]
+	}`
+	denials, err := cs.cli.Denials("foo")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/debug/denials")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"snap": []string{"foo"},
+	})
+	c.Check(denials, check.DeepEquals, []client.Denial{{
+		Snap:       "foo",
+		App:        "app",
+		Revision:   snap.R(2),
+		Kind:       "apparmor",
+		Access:     "open /dev/video0 (r)",
+		Count:      3,
+		FirstSeen:  time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		LastSeen:   time.Date(2026, 10, 1, 10, 5, 0, 0, time.UTC),
+		Interfaces: []string{"camera"},
+	}})
+}
+
+func (cs *clientSuite) TestClientDenialsError(c *check.C) {
+	cs.status = 404
+	cs.rsp = `{"type": "error", "result": {"message": "snap not installed", "kind": "snap-not-found"}}`
+	_, err := cs.cli.Denials("foo")
+	c.Check(err, check.ErrorMatches, "snap not installed")
+}
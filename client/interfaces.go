@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // Plug represents the potential of a given snap to connect to a slot.
@@ -73,6 +74,7 @@ type Interface struct {
 type InterfaceAction struct {
 	Action string `json:"action"`
 	Forget bool   `json:"forget,omitempty"`
+	Expiry string `json:"expiry,omitempty"`
 	Plugs  []Plug `json:"plugs,omitempty"`
 	Slots  []Slot `json:"slots,omitempty"`
 }
@@ -86,6 +88,13 @@ type InterfaceOptions struct {
 	Connected bool
 }
 
+// ConnectOptions represents extra options for connect op
+type ConnectOptions struct {
+	// Expiry, if set, is the time after which the connection is
+	// automatically removed.
+	Expiry time.Time
+}
+
 // DisconnectOptions represents extra options for disconnect op
 type DisconnectOptions struct {
 	Forget bool
@@ -130,12 +139,16 @@ func (client *Client) performInterfaceAction(sa *InterfaceAction) (changeID stri
 
 // Connect establishes a connection between a plug and a slot.
 // The plug and the slot must have the same interface.
-func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName string) (changeID string, err error) {
-	return client.performInterfaceAction(&InterfaceAction{
+func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName string, opts *ConnectOptions) (changeID string, err error) {
+	action := &InterfaceAction{
 		Action: "connect",
 		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
 		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
-	})
+	}
+	if opts != nil && !opts.Expiry.IsZero() {
+		action.Expiry = opts.Expiry.Format(time.RFC3339)
+	}
+	return client.performInterfaceAction(action)
 }
 
 // Disconnect breaks the connection between a plug and a slot.
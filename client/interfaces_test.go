@@ -21,6 +21,7 @@ package client_test
 
 import (
 	"encoding/json"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -157,7 +158,7 @@ func (cs *clientSuite) TestClientInterfacesMultiple(c *check.C) {
 }
 
 func (cs *clientSuite) TestClientConnectCallsEndpoint(c *check.C) {
-	cs.cli.Connect("producer", "plug", "consumer", "slot")
+	cs.cli.Connect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
 }
@@ -170,7 +171,7 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 		"result": { },
                 "change": "foo"
 	}`
-	id, err := cs.cli.Connect("producer", "plug", "consumer", "slot")
+	id, err := cs.cli.Connect("producer", "plug", "consumer", "slot", nil)
 	c.Assert(err, check.IsNil)
 	c.Check(id, check.Equals, "foo")
 	var body map[string]any
@@ -194,6 +195,41 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientConnectWithExpiry(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+                "status-code": 202,
+		"result": { },
+                "change": "foo"
+	}`
+	expiry := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	opts := &client.ConnectOptions{Expiry: expiry}
+	id, err := cs.cli.Connect("producer", "plug", "consumer", "slot", opts)
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	var body map[string]any
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]any{
+		"action": "connect",
+		"expiry": "2026-10-19T13:00:00Z",
+		"plugs": []any{
+			map[string]any{
+				"snap": "producer",
+				"plug": "plug",
+			},
+		},
+		"slots": []any{
+			map[string]any{
+				"snap": "consumer",
+				"slot": "slot",
+			},
+		},
+	})
+}
+
 func (cs *clientSuite) TestClientDisconnectCallsEndpoint(c *check.C) {
 	cs.cli.Disconnect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
@@ -17,6 +17,9 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type NotifyOptions struct {
@@ -61,4 +64,80 @@ type NoticeType string
 const (
 	// SnapRunInhibitNotice is recorded when "snap run" is inhibited due refresh.
 	SnapRunInhibitNotice NoticeType = "snap-run-inhibit"
+
+	// InterfacesRequestsPromptNotice is recorded when a prompt is added,
+	// modified or resolved. The key is the ID of the prompt.
+	InterfacesRequestsPromptNotice NoticeType = "interfaces-requests-prompt"
+
+	// ConfdbChangeNotice is recorded when a committed confdb transaction
+	// changes data visible through a view. The key is the ID of the view and
+	// the data holds the affected requests of the view.
+	ConfdbChangeNotice NoticeType = "confdb-change"
+
+	// InterfaceConnectionExpiredNotice is recorded when a time-limited
+	// interface connection expires. The key is the ID of the connection and
+	// the data holds the ID of the change removing it.
+	InterfaceConnectionExpiredNotice NoticeType = "interface-connection-expired"
 )
+
+// Notice holds details of an event that occurred and was recorded by snapd.
+type Notice struct {
+	ID            string            `json:"id"`
+	UserID        *uint32           `json:"user-id"`
+	Type          NoticeType        `json:"type"`
+	Key           string            `json:"key"`
+	FirstOccurred time.Time         `json:"first-occurred"`
+	LastOccurred  time.Time         `json:"last-occurred"`
+	LastRepeated  time.Time         `json:"last-repeated"`
+	Occurrences   int               `json:"occurrences"`
+	LastData      map[string]string `json:"last-data,omitempty"`
+}
+
+// NoticesOptions contains options for querying snapd for notices.
+type NoticesOptions struct {
+	// Types, if not empty, restricts the notices to those of the given types.
+	Types []NoticeType
+	// Keys, if not empty, restricts the notices to those with the given keys.
+	Keys []string
+	// After, if set, restricts the notices to those which last repeated
+	// after the given time.
+	After time.Time
+	// Timeout, if set, makes snapd wait up to the given duration for
+	// matching notices to occur, if there are none yet.
+	Timeout time.Duration
+}
+
+// Notices returns the notices matching the given options.
+func (client *Client) Notices(opts *NoticesOptions) ([]*Notice, error) {
+	q := make(url.Values)
+	var doOpts *doOptions
+	if opts != nil {
+		if len(opts.Types) > 0 {
+			types := make([]string, len(opts.Types))
+			for i, t := range opts.Types {
+				types[i] = string(t)
+			}
+			q.Set("types", strings.Join(types, ","))
+		}
+		if len(opts.Keys) > 0 {
+			q.Set("keys", strings.Join(opts.Keys, ","))
+		}
+		if !opts.After.IsZero() {
+			q.Set("after", opts.After.Format(time.RFC3339Nano))
+		}
+		if opts.Timeout > 0 {
+			q.Set("timeout", opts.Timeout.String())
+			// give snapd some leeway to respond after the timeout
+			doOpts = &doOptions{
+				Timeout: opts.Timeout + doTimeout,
+				Retry:   opts.Timeout + doTimeout,
+			}
+		}
+	}
+
+	var notices []*Notice
+	if _, err := client.doSyncWithOpts("GET", "/v2/notices", q, nil, nil, &notices, doOpts); err != nil {
+		return nil, err
+	}
+	return notices, nil
+}
@@ -22,6 +22,8 @@ package client_test
 import (
 	"encoding/json"
 	"io"
+	"net/url"
+	"time"
 
 	"github.com/snapcore/snapd/client"
 	. "gopkg.in/check.v1"
@@ -48,3 +50,52 @@ func (cs *clientSuite) TestNotify(c *C) {
 		"key":    "snap-name",
 	})
 }
+
+func (cs *clientSuite) TestNotices(c *C) {
+	cs.rsp = `{"type": "sync", "result": [{
+		"id": "3",
+		"user-id": 1000,
+		"type": "interfaces-requests-prompt",
+		"key": "0000000000000002",
+		"first-occurred": "2026-10-18T12:00:00Z",
+		"last-occurred": "2026-10-18T12:01:00Z",
+		"last-repeated": "2026-10-18T12:01:00Z",
+		"occurrences": 2,
+		"last-data": {"resolved": "replied"}
+	}]}`
+	notices, err := cs.cli.Notices(&client.NoticesOptions{
+		Types:   []client.NoticeType{client.InterfacesRequestsPromptNotice},
+		Keys:    []string{"0000000000000002", "0000000000000003"},
+		After:   time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC),
+		Timeout: time.Minute,
+	})
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/notices")
+	c.Check(cs.req.URL.Query(), DeepEquals, url.Values{
+		"types":   {"interfaces-requests-prompt"},
+		"keys":    {"0000000000000002,0000000000000003"},
+		"after":   {"2026-10-18T11:00:00Z"},
+		"timeout": {"1m0s"},
+	})
+	uid := uint32(1000)
+	c.Check(notices, DeepEquals, []*client.Notice{{
+		ID:            "3",
+		UserID:        &uid,
+		Type:          client.InterfacesRequestsPromptNotice,
+		Key:           "0000000000000002",
+		FirstOccurred: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		LastOccurred:  time.Date(2026, 10, 18, 12, 1, 0, 0, time.UTC),
+		LastRepeated:  time.Date(2026, 10, 18, 12, 1, 0, 0, time.UTC),
+		Occurrences:   2,
+		LastData:      map[string]string{"resolved": "replied"},
+	}})
+}
+
+func (cs *clientSuite) TestNoticesNoOptions(c *C) {
+	cs.rsp = `{"type": "sync", "result": []}`
+	notices, err := cs.cli.Notices(nil)
+	c.Assert(err, IsNil)
+	c.Check(notices, HasLen, 0)
+	c.Check(cs.req.URL.Query(), HasLen, 0)
+}
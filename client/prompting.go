@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Prompt holds the details of an outstanding request for access by a snap
+// which is waiting on a reply from the user.
+type Prompt struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Snap        string            `json:"snap"`
+	PID         int32             `json:"pid"`
+	Cgroup      string            `json:"cgroup"`
+	Interface   string            `json:"interface"`
+	Constraints PromptConstraints `json:"constraints"`
+}
+
+// PromptConstraints holds the interface-specific details of what a prompt is
+// requesting access to.
+type PromptConstraints struct {
+	// Path is only set for interfaces which mediate filesystem access,
+	// such as "home".
+	Path                 string   `json:"path,omitempty"`
+	RequestedPermissions []string `json:"requested-permissions"`
+	AvailablePermissions []string `json:"available-permissions"`
+}
+
+// PromptReply holds the reply to a prompt.
+type PromptReply struct {
+	// Action is either "allow" or "deny".
+	Action string `json:"action"`
+	// Lifespan is one of "single", "session", "timespan" or "forever".
+	Lifespan string `json:"lifespan"`
+	// Duration must only be set when Lifespan is "timespan".
+	Duration    string                 `json:"duration,omitempty"`
+	Constraints PromptReplyConstraints `json:"constraints"`
+}
+
+// PromptReplyConstraints holds the constraints to which a prompt reply, and
+// any rule created from it, applies.
+type PromptReplyConstraints struct {
+	PathPattern string   `json:"path-pattern,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// PromptsOptions contains options for querying snapd for prompts.
+type PromptsOptions struct {
+	// UserID, if set, requests the prompts of the given user rather than
+	// those of the calling user. Only root may do this.
+	UserID *uint32
+}
+
+func (opts *PromptsOptions) query() url.Values {
+	q := make(url.Values)
+	if opts != nil && opts.UserID != nil {
+		q.Set("user-id", fmt.Sprint(*opts.UserID))
+	}
+	return q
+}
+
+// Prompts returns the outstanding prompts.
+func (client *Client) Prompts(opts *PromptsOptions) ([]*Prompt, error) {
+	var prompts []*Prompt
+	_, err := client.doSync("GET", "/v2/interfaces/requests/prompts", opts.query(), nil, nil, &prompts)
+	if err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// Prompt returns the outstanding prompt with the given ID.
+func (client *Client) Prompt(id string, opts *PromptsOptions) (*Prompt, error) {
+	var prompt Prompt
+	_, err := client.doSync("GET", "/v2/interfaces/requests/prompts/"+url.PathEscape(id), opts.query(), nil, nil, &prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// ReplyToPrompt replies to the prompt with the given ID, returning the IDs of
+// all the prompts which were satisfied by the reply.
+func (client *Client) ReplyToPrompt(id string, reply *PromptReply, opts *PromptsOptions) ([]string, error) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(reply); err != nil {
+		return nil, err
+	}
+	var satisfied []string
+	_, err := client.doSync("POST", "/v2/interfaces/requests/prompts/"+url.PathEscape(id), opts.query(), nil, &body, &satisfied)
+	if err != nil {
+		return nil, err
+	}
+	return satisfied, nil
+}
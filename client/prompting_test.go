@@ -0,0 +1,130 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestPrompts(c *C) {
+	cs.rsp = `{"type": "sync", "result": [{
+		"id": "0000000000000002",
+		"timestamp": "2026-10-18T12:00:00Z",
+		"snap": "firefox",
+		"pid": 1234,
+		"cgroup": "0::/user.slice/snap.firefox.firefox.scope",
+		"interface": "home",
+		"constraints": {
+			"path": "/home/test/Downloads/foo.txt",
+			"requested-permissions": ["read", "write"],
+			"available-permissions": ["read", "write", "execute"]
+		}
+	}]}`
+	prompts, err := cs.cli.Prompts(nil)
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+	c.Check(cs.req.URL.Query(), HasLen, 0)
+	c.Check(prompts, DeepEquals, []*client.Prompt{{
+		ID:        "0000000000000002",
+		Timestamp: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Snap:      "firefox",
+		PID:       1234,
+		Cgroup:    "0::/user.slice/snap.firefox.firefox.scope",
+		Interface: "home",
+		Constraints: client.PromptConstraints{
+			Path:                 "/home/test/Downloads/foo.txt",
+			RequestedPermissions: []string{"read", "write"},
+			AvailablePermissions: []string{"read", "write", "execute"},
+		},
+	}})
+}
+
+func (cs *clientSuite) TestPromptsUserID(c *C) {
+	cs.rsp = `{"type": "sync", "result": []}`
+	uid := uint32(1000)
+	prompts, err := cs.cli.Prompts(&client.PromptsOptions{UserID: &uid})
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+	c.Check(cs.req.URL.Query().Get("user-id"), Equals, "1000")
+}
+
+func (cs *clientSuite) TestPrompt(c *C) {
+	cs.rsp = `{"type": "sync", "result": {
+		"id": "0000000000000002",
+		"snap": "cheese",
+		"interface": "camera",
+		"constraints": {
+			"requested-permissions": ["access"],
+			"available-permissions": ["access"]
+		}
+	}}`
+	prompt, err := cs.cli.Prompt("0000000000000002", nil)
+	c.Assert(err, IsNil)
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+	c.Check(prompt.Snap, Equals, "cheese")
+	c.Check(prompt.Constraints.Path, Equals, "")
+	c.Check(prompt.Constraints.RequestedPermissions, DeepEquals, []string{"access"})
+}
+
+func (cs *clientSuite) TestPromptNotFound(c *C) {
+	cs.status = 404
+	cs.rsp = `{"type": "error", "result": {"message": "cannot find prompt", "kind": "interfaces-requests-prompt-not-found"}}`
+	_, err := cs.cli.Prompt("0000000000000002", nil)
+	c.Assert(err, ErrorMatches, "cannot find prompt")
+	c.Check(err.(*client.Error).Kind, Equals, client.ErrorKindInterfacesRequestsPromptNotFound)
+}
+
+func (cs *clientSuite) TestReplyToPrompt(c *C) {
+	cs.rsp = `{"type": "sync", "result": ["0000000000000002", "0000000000000003"]}`
+	satisfied, err := cs.cli.ReplyToPrompt("0000000000000002", &client.PromptReply{
+		Action:   "allow",
+		Lifespan: "timespan",
+		Duration: "1h",
+		Constraints: client.PromptReplyConstraints{
+			PathPattern: "/home/test/Downloads/*",
+			Permissions: []string{"read"},
+		},
+	}, nil)
+	c.Assert(err, IsNil)
+	c.Check(satisfied, DeepEquals, []string{"0000000000000002", "0000000000000003"})
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	c.Assert(json.Unmarshal(body, &m), IsNil)
+	c.Check(m, DeepEquals, map[string]any{
+		"action":   "allow",
+		"lifespan": "timespan",
+		"duration": "1h",
+		"constraints": map[string]any{
+			"path-pattern": "/home/test/Downloads/*",
+			"permissions":  []any{"read"},
+		},
+	})
+}
@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// SBOM formats supported by the snapd API.
+const (
+	SBOMFormatSPDX      = "spdx"
+	SBOMFormatCycloneDX = "cyclonedx"
+)
+
+// SBOM returns the software bill of materials of the snaps and
+// components installed on the system, as a JSON document in the given
+// format. The default of snapd is used when format is empty.
+func (client *Client) SBOM(format string) (json.RawMessage, error) {
+	q := url.Values{}
+	if format != "" {
+		q.Set("format", format)
+	}
+
+	var doc json.RawMessage
+	if _, err := client.doSync("GET", "/v2/sbom", q, nil, nil, &doc); err != nil {
+		return nil, fmt.Errorf("cannot get SBOM: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,59 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestClientSBOM(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {"bomFormat": "CycloneDX"}
+	}`
+	doc, err := cs.cli.SBOM(client.SBOMFormatCycloneDX)
+	c.Assert(err, check.IsNil)
+	c.Check(string(doc), check.Equals, `{"bomFormat": "CycloneDX"}`)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/sbom")
+	c.Check(cs.req.URL.Query().Get("format"), check.Equals, "cyclonedx")
+}
+
+func (cs *clientSuite) TestClientSBOMDefaultFormat(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {"spdxVersion": "SPDX-2.3"}
+	}`
+	_, err := cs.cli.SBOM("")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.RawQuery, check.Equals, "")
+}
+
+func (cs *clientSuite) TestClientSBOMError(c *check.C) {
+	cs.status = 400
+	cs.rsp = `{
+		"type": "error",
+		"result": {"message": "invalid format"}
+	}`
+	_, err := cs.cli.SBOM("swid")
+	c.Check(err, check.ErrorMatches, "cannot get SBOM: invalid format")
+}
@@ -64,6 +64,10 @@ type SnapOptions struct {
 	Time             string          `json:"time,omitempty"`
 	HoldLevel        string          `json:"hold-level,omitempty"`
 	Users            []string        `json:"users,omitempty"`
+	DeltaFrom        string          `json:"delta-from,omitempty"`
+	// DeltaAssertions is an assertion stream sent along with a delta, to
+	// verify the snap rebuilt from it.
+	DeltaAssertions []byte `json:"-"`
 }
 
 func writeFieldBool(mw *multipart.Writer, key string, val bool) error {
@@ -325,6 +329,24 @@ func (client *Client) InstallPathMany(paths []string, options *SnapOptions) (cha
 	return client.sendLocalSnaps(paths, files, action)
 }
 
+// InstallBundle installs the snaps and components from the offline bundle
+// with the given path, after adding the assertions it carries, returning the
+// UUID of the background operation upon success.
+func (client *Client) InstallBundle(path string, options *SnapOptions) (changeID string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot open %q: %w", path, err)
+	}
+
+	action := actionData{
+		Action:      "install-bundle",
+		SnapPath:    path,
+		SnapOptions: options,
+	}
+
+	return client.sendLocalSnaps([]string{path}, []*os.File{f}, action)
+}
+
 func (client *Client) sendLocalSnaps(paths []string, files []*os.File, action actionData) (string, error) {
 	pr, pw := io.Pipe()
 	mw := multipart.NewWriter(pw)
@@ -382,7 +404,9 @@ func sendSnapFiles(paths []string, files []*os.File, pw *io.PipeWriter, mw *mult
 		fields = append(fields, []field{
 			{"name", action.Name},
 			{"snap-path", action.SnapPath},
-			{"channel", action.Channel}}...)
+			{"channel", action.Channel},
+			{"delta-from", action.DeltaFrom},
+			{"assertions", string(action.DeltaAssertions)}}...)
 	}
 
 	for _, s := range fields {
@@ -339,6 +339,82 @@ func (cs *clientSuite) TestClientOpInstallPath(c *check.C) {
 	c.Check(id, check.Equals, "66b3")
 }
 
+func (cs *clientSuite) TestClientOpInstallPathDeltaFrom(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+	bodyData := []byte("delta-data")
+
+	delta := filepath.Join(c.MkDir(), "foo_2.0_amd64.xdelta3")
+	err := os.WriteFile(delta, bodyData, 0644)
+	c.Assert(err, check.IsNil)
+
+	id, err := cs.cli.InstallPath(delta, "", &client.SnapOptions{DeltaFrom: "foo"})
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(string(body), testutil.Contains, "\r\ndelta-data\r\n")
+	c.Assert(string(body), testutil.Contains, "Content-Disposition: form-data; name=\"delta-from\"\r\n\r\nfoo\r\n")
+	c.Check(id, check.Equals, "66b3")
+}
+
+func (cs *clientSuite) TestClientOpInstallBundle(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+	bodyData := []byte("bundle-data")
+
+	bundle := filepath.Join(c.MkDir(), "update.bundle")
+	err := os.WriteFile(bundle, bodyData, 0644)
+	c.Assert(err, check.IsNil)
+
+	id, err := cs.cli.InstallBundle(bundle, nil)
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps")
+	c.Assert(string(body), testutil.Contains, "\r\nbundle-data\r\n")
+	c.Assert(string(body), testutil.Contains, "Content-Disposition: form-data; name=\"action\"\r\n\r\ninstall-bundle\r\n")
+	c.Assert(string(body), testutil.Contains, fmt.Sprintf("Content-Disposition: form-data; name=\"snap-path\"\r\n\r\n%s\r\n", bundle))
+	c.Check(id, check.Equals, "66b3")
+}
+
+func (cs *clientSuite) TestClientOpInstallPathDeltaAssertions(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+
+	delta := filepath.Join(c.MkDir(), "foo_2.0_amd64.xdelta3")
+	err := os.WriteFile(delta, []byte("delta-data"), 0644)
+	c.Assert(err, check.IsNil)
+
+	id, err := cs.cli.InstallPath(delta, "", &client.SnapOptions{
+		DeltaFrom:       "foo",
+		DeltaAssertions: []byte("type: snap-revision\n"),
+	})
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(string(body), testutil.Contains, "Content-Disposition: form-data; name=\"assertions\"\r\n\r\ntype: snap-revision\n\r\n")
+	c.Check(id, check.Equals, "66b3")
+}
+
 func (cs *clientSuite) TestClientOpInstallPathIgnoreRunning(c *check.C) {
 	cs.status = 202
 	cs.rsp = `{
@@ -133,3 +133,96 @@ func (client *Client) ValidationSet(accountID, name string, sequence int) (*Vali
 	}
 	return res, nil
 }
+
+// ValidationSetPreview describes what enforcing a validation set would
+// change on the system.
+type ValidationSetPreview struct {
+	AccountID string `json:"account-id"`
+	Name      string `json:"name"`
+	Sequence  int    `json:"sequence"`
+
+	Install []ValidationSetAction `json:"install,omitempty"`
+	Refresh []ValidationSetAction `json:"refresh,omitempty"`
+	Remove  []ValidationSetAction `json:"remove,omitempty"`
+	// Unresolvable is set when snapd cannot carry out the changes by
+	// itself, it says why.
+	Unresolvable string `json:"unresolvable,omitempty"`
+
+	// Diff holds the differences with another sequence of the validation
+	// set, if any.
+	Diff *ValidationSetDiff `json:"diff,omitempty"`
+}
+
+// ValidationSetAction is a change to a snap or component needed to meet
+// validation set constraints.
+type ValidationSetAction struct {
+	Snap      string `json:"snap"`
+	Component string `json:"component,omitempty"`
+	// Revision is the required revision, empty if any will do.
+	Revision string `json:"revision,omitempty"`
+	// Current is the installed revision, for refreshes.
+	Current        string   `json:"current,omitempty"`
+	ValidationSets []string `json:"validation-sets,omitempty"`
+}
+
+// ValidationSetDiff holds the differences in snap constraints between two
+// sequences of a validation set.
+type ValidationSetDiff struct {
+	From  int                     `json:"from"`
+	To    int                     `json:"to"`
+	Snaps []ValidationSetSnapDiff `json:"snaps"`
+}
+
+// ValidationSetSnapDiff describes how the constraints on a snap differ
+// between two sequences of a validation set, From or To are nil if the
+// snap is not listed in the respective sequence.
+type ValidationSetSnapDiff struct {
+	Name string                       `json:"name"`
+	From *ValidationSetSnapConstraint `json:"from,omitempty"`
+	To   *ValidationSetSnapConstraint `json:"to,omitempty"`
+}
+
+// ValidationSetSnapConstraint holds the constraints of a validation set on
+// a snap.
+type ValidationSetSnapConstraint struct {
+	Presence   string                                 `json:"presence"`
+	Revision   int                                    `json:"revision,omitempty"`
+	Components map[string]ValidationSetSnapConstraint `json:"components,omitempty"`
+}
+
+// ValidationSetPreviewOptions carries options for PreviewValidationSet.
+type ValidationSetPreviewOptions struct {
+	// Sequence is the sequence of the validation set to preview, the
+	// latest one if zero.
+	Sequence int
+	// DiffFrom is the sequence to diff against, the tracked one if zero.
+	DiffFrom int
+}
+
+// PreviewValidationSet returns what enforcing the given validation set
+// identified by account and name would change, without changing anything.
+func (client *Client) PreviewValidationSet(accountID, name string, opts *ValidationSetPreviewOptions) (*ValidationSetPreview, error) {
+	if accountID == "" || name == "" {
+		return nil, xerrors.Errorf("cannot preview validation set without account ID and name")
+	}
+	if opts == nil {
+		opts = &ValidationSetPreviewOptions{}
+	}
+
+	q := url.Values{}
+	q.Set("preview", "true")
+	if opts.Sequence != 0 {
+		q.Set("sequence", fmt.Sprintf("%d", opts.Sequence))
+	}
+	if opts.DiffFrom != 0 {
+		q.Set("diff-from", fmt.Sprintf("%d", opts.DiffFrom))
+	}
+
+	var res *ValidationSetPreview
+	path := fmt.Sprintf("/v2/validation-sets/%s/%s", accountID, name)
+	if _, err := client.doSync("GET", path, q, nil, nil, &res); err != nil {
+		fmt := "cannot preview validation set: %w"
+		return nil, xerrors.Errorf(fmt, err)
+	}
+	return res, nil
+}
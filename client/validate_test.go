@@ -238,3 +238,57 @@ func (cs *clientSuite) TestValidationSetWithSequence(c *check.C) {
 		AccountID: "abc", Name: "def", Mode: "monitor", Sequence: 9, Valid: false,
 	})
 }
+
+func (cs *clientSuite) TestPreviewValidationSet(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"account-id": "foo",
+			"name": "bar",
+			"sequence": 3,
+			"install": [{"snap": "snap-a", "revision": "2", "validation-sets": ["foo/bar"]}],
+			"remove": [{"snap": "snap-b", "component": "comp", "validation-sets": ["foo/bar"]}],
+			"unresolvable": "cannot auto-resolve",
+			"diff": {"from": 2, "to": 3, "snaps": [{"name": "snap-a", "to": {"presence": "required", "revision": 2}}]}
+		}
+	}`
+
+	res, err := cs.cli.PreviewValidationSet("foo", "bar", &client.ValidationSetPreviewOptions{Sequence: 3, DiffFrom: 2})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/validation-sets/foo/bar")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"preview":   []string{"true"},
+		"sequence":  []string{"3"},
+		"diff-from": []string{"2"},
+	})
+	c.Check(res, check.DeepEquals, &client.ValidationSetPreview{
+		AccountID:    "foo",
+		Name:         "bar",
+		Sequence:     3,
+		Install:      []client.ValidationSetAction{{Snap: "snap-a", Revision: "2", ValidationSets: []string{"foo/bar"}}},
+		Remove:       []client.ValidationSetAction{{Snap: "snap-b", Component: "comp", ValidationSets: []string{"foo/bar"}}},
+		Unresolvable: "cannot auto-resolve",
+		Diff: &client.ValidationSetDiff{
+			From: 2,
+			To:   3,
+			Snaps: []client.ValidationSetSnapDiff{{
+				Name: "snap-a",
+				To:   &client.ValidationSetSnapConstraint{Presence: "required", Revision: 2},
+			}},
+		},
+	})
+}
+
+func (cs *clientSuite) TestPreviewValidationSetError(c *check.C) {
+	cs.status = 500
+	cs.rsp = errorResponseJSON
+
+	_, err := cs.cli.PreviewValidationSet("foo", "bar", nil)
+	c.Assert(err, check.ErrorMatches, "cannot preview validation set: failed")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{"preview": []string{"true"}})
+
+	_, err = cs.cli.PreviewValidationSet("", "bar", nil)
+	c.Assert(err, check.ErrorMatches, "cannot preview validation set without account ID and name")
+}
@@ -152,6 +152,10 @@ type AssembleConfig struct {
 	// Signer is a function that signs the given data with the private key that
 	// matches the public key embedded in the serial assertion.
 	Signer func([]byte) ([]byte, error)
+	// Transport is used to exchange messages with peers. If nil, defaults to
+	// an [HTTPSTransport]. The listener given to [AssembleState.Run] must be
+	// compatible with the transport.
+	Transport Transport
 }
 
 const AssembleSessionLength = time.Hour
@@ -177,6 +181,10 @@ func NewAssembleState(
 		config.Clock = time.Now
 	}
 
+	if config.Transport == nil {
+		config.Transport = NewHTTPSTransport()
+	}
+
 	// validate the given session and parse it into more useful data structures
 	validated, err := validateSession(session, config.Clock)
 	if err != nil {
@@ -531,12 +539,12 @@ type RunOptions struct {
 }
 
 // Run starts the assembly process, managing both the server and periodic client
-// operations. It returns when the context is cancelled, returning the
-// discovered device identities and routes.
+// operations, using the [Transport] from the [AssembleConfig]. It returns when
+// the context is cancelled, returning the discovered device identities and
+// routes.
 func (as *AssembleState) Run(
 	ctx context.Context,
 	ln net.Listener,
-	transport Transport,
 	discoveries <-chan []string,
 	opts RunOptions,
 ) ([]Identity, Routes, error) {
@@ -549,6 +557,7 @@ func (as *AssembleState) Run(
 	}
 
 	addr := ln.Addr().String()
+	transport := as.config.Transport
 	client := transport.NewClient(as.cert)
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -770,6 +779,13 @@ func (as *AssembleState) shutdownAtExpectedSize() error {
 	return nil
 }
 
+// CommitReport implements [VerifiedPeer]. Convergence reports are only
+// exchanged once the cluster is assembled, so they are always rejected during
+// an assembly session.
+func (h *peerHandle) CommitReport(report Report) error {
+	return errors.New("cannot accept convergence reports during assembly")
+}
+
 // CommitDevices records the given device identities. All new device identities
 // are recorded. For any devices that we are already aware of, we check that our
 // view of the device's identity is consistent with the new data.
@@ -1977,13 +1977,14 @@ func (s *clusterSuite) TestRunTimeout(c *check.C) {
 	session := AssembleSession{
 		Initiated: started,
 	}
+	cfg.Transport = transport
 	as, err := NewAssembleState(cfg, session, func(DeviceToken, Identifier) (RouteSelector, error) {
 		return statelessSelector(), nil
 	}, commit, db)
 	c.Assert(err, check.IsNil)
 
 	// when Run is called, the clock will return a time past the 1-hour limit
-	_, _, err = as.Run(context.Background(), testListener("addr"), transport, discover, RunOptions{})
+	_, _, err = as.Run(context.Background(), testListener("addr"), discover, RunOptions{})
 	c.Assert(err, check.ErrorMatches, "cannot resume an assembly session that began more than an hour ago")
 }
 
@@ -2005,12 +2006,13 @@ func (s *clusterSuite) TestRunServerError(c *check.C) {
 	}
 
 	discover := make(chan []string)
+	cfg.Transport = transport
 	as, err := NewAssembleState(cfg, AssembleSession{}, func(DeviceToken, Identifier) (RouteSelector, error) {
 		return statelessSelector(), nil
 	}, commit, db)
 	c.Assert(err, check.IsNil)
 
-	_, _, err = as.Run(context.Background(), testListener("addr"), transport, discover, RunOptions{})
+	_, _, err = as.Run(context.Background(), testListener("addr"), discover, RunOptions{})
 	c.Assert(err, testutil.ErrorIs, serverError)
 }
 
@@ -2038,6 +2040,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnStartup(c *check.C) {
 	}
 
 	discover := make(chan []string)
+	cfg.Transport = transport
 	as, err := NewAssembleState(cfg, AssembleSession{}, func(DeviceToken, Identifier) (RouteSelector, error) {
 		return selector, nil
 	}, func(as AssembleSession) {}, db)
@@ -2046,7 +2049,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnStartup(c *check.C) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, _, err = as.Run(ctx, testListener("addr"), transport, discover, RunOptions{})
+	_, _, err = as.Run(ctx, testListener("addr"), discover, RunOptions{})
 	c.Assert(err, check.IsNil)
 }
 
@@ -2075,6 +2078,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnCommitDevices(c *check.C) {
 		},
 	}
 
+	cfg.Transport = transport
 	as, err := NewAssembleState(cfg, AssembleSession{}, func(DeviceToken, Identifier) (RouteSelector, error) {
 		return selector, nil
 	}, func(AssembleSession) {}, db)
@@ -2087,7 +2091,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnCommitDevices(c *check.C) {
 	defer cancel()
 
 	discover := make(chan []string)
-	_, _, err = as.Run(ctx, testListener("addr"), transport, discover, RunOptions{})
+	_, _, err = as.Run(ctx, testListener("addr"), discover, RunOptions{})
 	c.Assert(err, check.IsNil)
 }
 
@@ -2116,6 +2120,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnCommitRoutes(c *check.C) {
 		},
 	}
 
+	cfg.Transport = transport
 	as, err := NewAssembleState(cfg, AssembleSession{}, func(DeviceToken, Identifier) (RouteSelector, error) {
 		return selector, nil
 	}, func(AssembleSession) {}, db)
@@ -2128,7 +2133,7 @@ func (s *clusterSuite) TestMaxSizeCompletionOnCommitRoutes(c *check.C) {
 	defer cancel()
 
 	discover := make(chan []string)
-	_, _, err = as.Run(ctx, testListener("addr"), transport, discover, RunOptions{})
+	_, _, err = as.Run(ctx, testListener("addr"), discover, RunOptions{})
 	c.Assert(err, check.IsNil)
 }
 
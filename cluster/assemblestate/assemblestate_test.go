@@ -26,6 +26,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"net"
 	"strconv"
@@ -73,9 +74,78 @@ func createTestCertAndKey(c *check.C) (certPEM []byte, keyPEM []byte) {
 }
 
 func (s *assembleSuite) TestRun(c *check.C) {
-	db, signing := mockAssertDB(c)
+	const count = 16
+	testRun(c, count, func() assemblestate.Transport {
+		return assemblestate.NewHTTPSTransport()
+	}, func() net.Listener {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		c.Assert(err, check.IsNil)
+		return ln
+	})
+}
+
+func (s *assembleSuite) TestRunMemoryTransport(c *check.C) {
+	network := assemblestate.NewMemoryNetwork()
+
+	var n int
+	const count = 32
+	testRun(c, count, func() assemblestate.Transport {
+		return assemblestate.NewMemoryTransport(network)
+	}, func() net.Listener {
+		n++
+		ln, err := network.Listen(fmt.Sprintf("device-%d:8080", n))
+		c.Assert(err, check.IsNil)
+		return ln
+	})
+}
+
+func (s *assembleSuite) TestRunMemoryTransportLossy(c *check.C) {
+	network := assemblestate.NewMemoryNetwork()
+
+	// lose a third of the messages that are sent again during later rounds.
+	// auth messages are only sent again when the peer is discovered again.
+	var lock sync.Mutex
+	var sent int
+	network.SetDropFunc(func(from assemblestate.Fingerprint, to string, kind string) bool {
+		if kind == "auth" {
+			return false
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		sent++
+		return sent%3 == 0
+	})
 
+	var n int
 	const count = 16
+	testRun(c, count, func() assemblestate.Transport {
+		return assemblestate.NewMemoryTransport(network)
+	}, func() net.Listener {
+		n++
+		ln, err := network.Listen(fmt.Sprintf("device-%d:8080", n))
+		c.Assert(err, check.IsNil)
+		return ln
+	})
+}
+
+func (s *assembleSuite) TestRunDatagramTransport(c *check.C) {
+	const count = 16
+	testRun(c, count, func() assemblestate.Transport {
+		return assemblestate.NewDatagramTransport("secret")
+	}, func() net.Listener {
+		ln, err := assemblestate.ListenDatagram("127.0.0.1:0")
+		c.Assert(err, check.IsNil)
+		return ln
+	})
+}
+
+// testRun runs an assembly session between the given number of devices, each
+// using a new transport and listener, and checks that the devices discover
+// each other.
+func testRun(c *check.C, count int, newTransport func() assemblestate.Transport, listen func() net.Listener) {
+	db, signing := mockAssertDB(c)
+
 	rdts := make([]assemblestate.DeviceToken, 0, count)
 	addrs := make([]string, 0, count)
 	listeners := make(map[assemblestate.DeviceToken]net.Listener, count)
@@ -83,8 +153,7 @@ func (s *assembleSuite) TestRun(c *check.C) {
 		rdt := assemblestate.DeviceToken(strconv.Itoa(i))
 		rdts = append(rdts, rdt)
 
-		ln, err := net.Listen("tcp", "127.0.0.1:0")
-		c.Assert(err, check.IsNil)
+		ln := listen()
 		defer ln.Close()
 
 		addrs = append(addrs, ln.Addr().String())
@@ -101,12 +170,13 @@ func (s *assembleSuite) TestRun(c *check.C) {
 		cert, key := createTestCertAndKey(c)
 		serial, pk := createTestSerial(c, signing)
 		as, err := assemblestate.NewAssembleState(assemblestate.AssembleConfig{
-			Secret:  "secret",
-			RDT:     assemblestate.DeviceToken(rdt),
-			TLSCert: cert,
-			TLSKey:  key,
-			Serial:  serial,
-			Signer:  privateKeySigner(pk),
+			Secret:    "secret",
+			RDT:       assemblestate.DeviceToken(rdt),
+			TLSCert:   cert,
+			TLSKey:    key,
+			Serial:    serial,
+			Signer:    privateKeySigner(pk),
+			Transport: newTransport(),
 		}, assemblestate.AssembleSession{},
 			func(self assemblestate.DeviceToken, identified func(assemblestate.DeviceToken) bool) (assemblestate.RouteSelector, error) {
 				return assemblestate.NewPrioritySelector(self, nil, identified), nil
@@ -125,7 +195,6 @@ func (s *assembleSuite) TestRun(c *check.C) {
 			_, _, err := as.Run(
 				ctx,
 				listeners[rdt],
-				assemblestate.NewHTTPSTransport(),
 				disco,
 				assemblestate.RunOptions{Period: time.Millisecond * 100},
 			)
@@ -137,12 +206,13 @@ func (s *assembleSuite) TestRun(c *check.C) {
 	cert, key := createTestCertAndKey(c)
 	serial, pk := createTestSerial(c, signing)
 	as, err := assemblestate.NewAssembleState(assemblestate.AssembleConfig{
-		Secret:  "secret",
-		RDT:     assemblestate.DeviceToken(rdt),
-		TLSCert: cert,
-		TLSKey:  key,
-		Serial:  serial,
-		Signer:  privateKeySigner(pk),
+		Secret:    "secret",
+		RDT:       assemblestate.DeviceToken(rdt),
+		TLSCert:   cert,
+		TLSKey:    key,
+		Serial:    serial,
+		Signer:    privateKeySigner(pk),
+		Transport: newTransport(),
 
 		// this session has an expected size, so it will terminate on its own
 		ExpectedSize: count,
@@ -161,7 +231,6 @@ func (s *assembleSuite) TestRun(c *check.C) {
 	ids, routes, err := as.Run(
 		ctx,
 		listeners[rdt],
-		assemblestate.NewHTTPSTransport(),
 		disco,
 		assemblestate.RunOptions{Period: time.Millisecond * 100},
 	)
@@ -0,0 +1,858 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+const (
+	// datagramHeaderSize is the size of the header of each datagram: the
+	// message id (8 bytes), the index of the fragment (2 bytes) and the number
+	// of fragments of the message (2 bytes).
+	datagramHeaderSize = 12
+	// datagramFragmentSize is the maximum amount of message data carried by a
+	// single datagram, small enough to avoid IP fragmentation on common links.
+	datagramFragmentSize = 1200
+	// maxDatagramMessageSize bounds the size of the messages that are
+	// reassembled from fragments.
+	maxDatagramMessageSize = 8 << 20
+	// maxPartialDatagramMessages bounds the number of messages that are
+	// being reassembled at the same time.
+	maxPartialDatagramMessages = 1024
+	// maxDatagramResponses bounds the number of responses that are kept in
+	// case peers send their message again.
+	maxDatagramResponses = 1024
+	// maxDatagramHandlers bounds the number of messages that are handled at
+	// the same time.
+	maxDatagramHandlers = 64
+	// maxDatagramReplayCache bounds the number of messages that are
+	// remembered to detect replays.
+	maxDatagramReplayCache = 4096
+)
+
+var (
+	// datagramRetransmit is the initial time that a client waits for a
+	// response before sending a message again. It doubles with each attempt,
+	// up to datagramMaxRetransmit.
+	datagramRetransmit    = 250 * time.Millisecond
+	datagramMaxRetransmit = 4 * time.Second
+	// datagramTimeout bounds the time spent sending a single message.
+	datagramTimeout = time.Minute
+	// datagramReassemblyTimeout is the time after which incomplete messages
+	// and cached responses are forgotten.
+	datagramReassemblyTimeout = 30 * time.Second
+	// datagramReplayWindow is the maximum difference between the time at
+	// which a message was sent and the time at which it is received.
+	datagramReplayWindow = 5 * time.Minute
+)
+
+// DatagramListener is a [net.Listener] that holds the UDP socket used by a
+// [DatagramTransport]. It never accepts any connections.
+type DatagramListener struct {
+	conn   net.PacketConn
+	once   sync.Once
+	closed chan struct{}
+}
+
+// ListenDatagram creates a [DatagramListener] on the given UDP address.
+func ListenDatagram(addr string) (*DatagramListener, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatagramListener{
+		conn:   conn,
+		closed: make(chan struct{}),
+	}, nil
+}
+
+func (l *DatagramListener) Accept() (net.Conn, error) {
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *DatagramListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.closed)
+		err = l.conn.Close()
+	})
+	return err
+}
+
+func (l *DatagramListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// DatagramTransport implements the [Transport] interface over UDP, for
+// networks where long-lived TCP connections are unreliable. Each message is
+// split into fragments that fit in a single datagram, and is sent again until
+// the peer responds or the attempt times out. Peers discard the duplicates and
+// repeat their response.
+//
+// Since there is no TLS handshake, each message and response carries the
+// certificate of its sender and is signed with the certificate's private key.
+// Peers are then known by the fingerprint of their certificate, the same way
+// as with the [HTTPSTransport]. The signature also covers the fingerprint of
+// the recipient and the time at which the message was sent, and peers refuse
+// messages that were addressed to someone else, that are too old or that they
+// already received. Messages and responses are encrypted with a key derived
+// from the shared assembly secret.
+//
+// To avoid being used to amplify traffic towards a spoofed source address,
+// peers only respond to messages from peers that they could verify, and never
+// with a response that is larger than the message. Messages are padded to the
+// size of a full datagram for that purpose.
+type DatagramTransport struct {
+	aead  cipher.AEAD
+	stats TransportStats
+}
+
+// NewDatagramTransport creates a new [DatagramTransport] that encrypts its
+// messages with a key derived from the given shared assembly secret.
+func NewDatagramTransport(secret string) *DatagramTransport {
+	return &DatagramTransport{
+		aead: datagramCipher(secret),
+	}
+}
+
+// datagramCipher returns the cipher used to encrypt messages for peers that
+// know the given shared assembly secret.
+func datagramCipher(secret string) cipher.AEAD {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("snapd assemble datagram encryption"))
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		panic(fmt.Sprintf("internal error: cannot create datagram cipher: %v", err))
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(fmt.Sprintf("internal error: cannot create datagram cipher: %v", err))
+	}
+	return aead
+}
+
+// datagramEnvelope is the signed container for a message or a response.
+type datagramEnvelope struct {
+	// Kind is the kind of the message, or "response".
+	Kind string `json:"kind"`
+	// Status is the status code of a response, with the same meaning as with
+	// the [HTTPSTransport].
+	Status int `json:"status,omitempty"`
+	// Payload is the JSON encoded message.
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// To is the fingerprint of the certificate of the recipient. It is empty
+	// for "auth" messages, since the sender doesn't know the recipient yet.
+	To []byte `json:"to,omitempty"`
+	// Time is the time at which the message was sent, in nanoseconds since
+	// the Unix epoch.
+	Time int64 `json:"time"`
+	// Cert is the DER encoded certificate of the sender.
+	Cert []byte `json:"cert"`
+	// Sig is the signature of the message id, kind, status, recipient, time
+	// and payload, made with the private key of Cert.
+	Sig []byte `json:"sig"`
+}
+
+func datagramSignedData(id uint64, env *datagramEnvelope) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, id)
+	binary.Write(&buf, binary.BigEndian, uint32(len(env.Kind)))
+	buf.WriteString(env.Kind)
+	binary.Write(&buf, binary.BigEndian, int32(env.Status))
+	binary.Write(&buf, binary.BigEndian, uint32(len(env.To)))
+	buf.Write(env.To)
+	binary.Write(&buf, binary.BigEndian, env.Time)
+	buf.Write(env.Payload)
+	return buf.Bytes()
+}
+
+func datagramID(id uint64) []byte {
+	var raw [8]byte
+	binary.BigEndian.PutUint64(raw[:], id)
+	return raw[:]
+}
+
+// sealDatagram signs an envelope with the given certificate, then encodes and
+// encrypts it. The result is padded to at least the given size.
+func sealDatagram(aead cipher.AEAD, cert tls.Certificate, id uint64, env datagramEnvelope, size int) ([]byte, error) {
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("certificate private key cannot sign messages")
+	}
+
+	data := datagramSignedData(id, &env)
+
+	var err error
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		env.Sig, err = signer.Sign(rand.Reader, data, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(data)
+		env.Sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign message: %v", err)
+	}
+
+	env.Cert = cert.Certificate[0]
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// the plaintext is the length of the encoded envelope, the envelope and
+	// the padding
+	overhead := aead.NonceSize() + aead.Overhead()
+	plain := make([]byte, 4, 4+len(encoded))
+	binary.BigEndian.PutUint32(plain, uint32(len(encoded)))
+	plain = append(plain, encoded...)
+	if pad := size - overhead - len(plain); pad > 0 {
+		plain = append(plain, make([]byte, pad)...)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), overhead+len(plain))
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plain, datagramID(id)), nil
+}
+
+// openDatagram decrypts and decodes an envelope and verifies its signature,
+// returning the fingerprint of the certificate of its sender.
+func openDatagram(aead cipher.AEAD, id uint64, msg []byte) (datagramEnvelope, Fingerprint, error) {
+	if len(msg) < aead.NonceSize() {
+		return datagramEnvelope{}, Fingerprint{}, errors.New("message too short")
+	}
+
+	plain, err := aead.Open(nil, msg[:aead.NonceSize()], msg[aead.NonceSize():], datagramID(id))
+	if err != nil {
+		return datagramEnvelope{}, Fingerprint{}, fmt.Errorf("cannot decrypt message: %v", err)
+	}
+
+	if len(plain) < 4 || uint64(binary.BigEndian.Uint32(plain)) > uint64(len(plain)-4) {
+		return datagramEnvelope{}, Fingerprint{}, errors.New("invalid message length")
+	}
+	plain = plain[4 : 4+binary.BigEndian.Uint32(plain)]
+
+	var env datagramEnvelope
+	if err := json.Unmarshal(plain, &env); err != nil {
+		return datagramEnvelope{}, Fingerprint{}, err
+	}
+
+	cert, err := x509.ParseCertificate(env.Cert)
+	if err != nil {
+		return datagramEnvelope{}, Fingerprint{}, err
+	}
+
+	var algo x509.SignatureAlgorithm
+	switch cert.PublicKeyAlgorithm {
+	case x509.Ed25519:
+		algo = x509.PureEd25519
+	case x509.ECDSA:
+		algo = x509.ECDSAWithSHA256
+	case x509.RSA:
+		algo = x509.SHA256WithRSA
+	default:
+		return datagramEnvelope{}, Fingerprint{}, fmt.Errorf("unsupported public key algorithm %s", cert.PublicKeyAlgorithm)
+	}
+
+	if err := cert.CheckSignature(algo, datagramSignedData(id, &env), env.Sig); err != nil {
+		return datagramEnvelope{}, Fingerprint{}, fmt.Errorf("invalid message signature: %v", err)
+	}
+
+	return env, CalculateFP(env.Cert), nil
+}
+
+// fragmentDatagram splits the given message into datagrams.
+func fragmentDatagram(id uint64, msg []byte) ([][]byte, error) {
+	if len(msg) > maxDatagramMessageSize {
+		return nil, fmt.Errorf("message too large: %d bytes", len(msg))
+	}
+
+	total := (len(msg) + datagramFragmentSize - 1) / datagramFragmentSize
+	frags := make([][]byte, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * datagramFragmentSize
+		if end > len(msg) {
+			end = len(msg)
+		}
+
+		frag := make([]byte, datagramHeaderSize, datagramHeaderSize+end-i*datagramFragmentSize)
+		binary.BigEndian.PutUint64(frag[0:8], id)
+		binary.BigEndian.PutUint16(frag[8:10], uint16(i))
+		binary.BigEndian.PutUint16(frag[10:12], uint16(total))
+		frags = append(frags, append(frag, msg[i*datagramFragmentSize:end]...))
+	}
+
+	return frags, nil
+}
+
+type datagramKey struct {
+	from string
+	id   uint64
+}
+
+type partialDatagram struct {
+	frags   [][]byte
+	missing int
+	started time.Time
+}
+
+// reassembler puts messages back together from their fragments.
+type reassembler struct {
+	partial map[datagramKey]*partialDatagram
+	clock   func() time.Time
+}
+
+func newReassembler(clock func() time.Time) *reassembler {
+	return &reassembler{
+		partial: make(map[datagramKey]*partialDatagram),
+		clock:   clock,
+	}
+}
+
+// add records the given datagram, received from the given address. Once all
+// fragments of a message have been received, the message is returned.
+func (r *reassembler) add(from string, datagram []byte) (id uint64, msg []byte, ok bool) {
+	if len(datagram) < datagramHeaderSize {
+		return 0, nil, false
+	}
+
+	id = binary.BigEndian.Uint64(datagram[0:8])
+	index := int(binary.BigEndian.Uint16(datagram[8:10]))
+	total := int(binary.BigEndian.Uint16(datagram[10:12]))
+	data := datagram[datagramHeaderSize:]
+
+	if total == 0 || index >= total || total*datagramFragmentSize > maxDatagramMessageSize+datagramFragmentSize {
+		return 0, nil, false
+	}
+
+	if total == 1 {
+		return id, append([]byte(nil), data...), true
+	}
+
+	key := datagramKey{from: from, id: id}
+	p, ok := r.partial[key]
+	if !ok {
+		r.expire()
+		if len(r.partial) >= maxPartialDatagramMessages {
+			logger.Debugf("dropping datagram from %s, too many incomplete messages", from)
+			return 0, nil, false
+		}
+
+		p = &partialDatagram{
+			frags:   make([][]byte, total),
+			missing: total,
+			started: r.clock(),
+		}
+		r.partial[key] = p
+	}
+
+	if len(p.frags) != total || p.frags[index] != nil {
+		return 0, nil, false
+	}
+
+	p.frags[index] = append([]byte(nil), data...)
+	p.missing--
+	if p.missing > 0 {
+		return 0, nil, false
+	}
+
+	delete(r.partial, key)
+	return id, bytes.Join(p.frags, nil), true
+}
+
+func (r *reassembler) expire() {
+	now := r.clock()
+	for key, p := range r.partial {
+		if now.Sub(p.started) > datagramReassemblyTimeout {
+			delete(r.partial, key)
+		}
+	}
+}
+
+type replayKey struct {
+	fp Fingerprint
+	id uint64
+}
+
+// replayCache remembers the messages that were received recently, so that a
+// captured message cannot be handled a second time.
+type replayCache struct {
+	lock sync.Mutex
+	seen map[replayKey]time.Time
+	// horizon is the time at which the most recent of the evicted messages
+	// was sent. Messages sent before then are refused, since they might have
+	// been received already.
+	horizon time.Time
+	clock   func() time.Time
+}
+
+func newReplayCache(clock func() time.Time) *replayCache {
+	return &replayCache{
+		seen:  make(map[replayKey]time.Time),
+		clock: clock,
+	}
+}
+
+// add records the message with the given id, sent at the given time by the
+// peer with the given fingerprint. An error is returned if the message might
+// have been received before.
+func (c *replayCache) add(fp Fingerprint, id uint64, sent time.Time) error {
+	now := c.clock()
+	if sent.Before(now.Add(-datagramReplayWindow)) || sent.After(now.Add(datagramReplayWindow)) {
+		return fmt.Errorf("message sent at %s is outside of the accepted time window", sent.UTC().Format(time.RFC3339))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !sent.After(c.horizon) {
+		return errors.New("message is older than the recently received messages")
+	}
+
+	key := replayKey{fp: fp, id: id}
+	if _, ok := c.seen[key]; ok {
+		return errors.New("message was already received")
+	}
+
+	if len(c.seen) >= maxDatagramReplayCache {
+		c.evict(now)
+	}
+
+	c.seen[key] = sent
+	return nil
+}
+
+// evict forgets the messages that are outside of the accepted time window.
+// If the cache is still full, the oldest message is forgotten as well.
+func (c *replayCache) evict(now time.Time) {
+	var oldest replayKey
+	var oldestSent time.Time
+	for key, sent := range c.seen {
+		if sent.Before(now.Add(-datagramReplayWindow)) {
+			delete(c.seen, key)
+			continue
+		}
+		if oldestSent.IsZero() || sent.Before(oldestSent) {
+			oldest, oldestSent = key, sent
+		}
+	}
+
+	if len(c.seen) < maxDatagramReplayCache {
+		return
+	}
+
+	delete(c.seen, oldest)
+	if oldestSent.After(c.horizon) {
+		c.horizon = oldestSent
+	}
+}
+
+// datagramResponse is the response to a message, kept so that it can be sent
+// again if the peer didn't receive it.
+type datagramResponse struct {
+	// frags is empty if the message was dropped.
+	frags [][]byte
+	// done is false while the message is being handled.
+	done bool
+	time time.Time
+}
+
+// pruneResponses forgets the responses that are too old to be requested again.
+// If there are still too many responses, the oldest one is forgotten as well.
+// Responses to messages that are being handled are kept.
+func pruneResponses(responses map[datagramKey]*datagramResponse, now time.Time) {
+	var oldest *datagramKey
+	var oldestTime time.Time
+	for k, resp := range responses {
+		if !resp.done {
+			continue
+		}
+		if now.Sub(resp.time) > datagramReassemblyTimeout {
+			delete(responses, k)
+			continue
+		}
+		if oldest == nil || resp.time.Before(oldestTime) {
+			key := k
+			oldest, oldestTime = &key, resp.time
+		}
+	}
+
+	if len(responses) >= maxDatagramResponses && oldest != nil {
+		delete(responses, *oldest)
+	}
+}
+
+// Serve implements the [Transport] interface. It receives messages on the UDP
+// socket of the given listener, which must have been created with
+// [ListenDatagram], and routes them to the given [PeerAuthenticator]. The
+// server runs until the context is cancelled.
+func (t *DatagramTransport) Serve(ctx context.Context, ln net.Listener, cert tls.Certificate, pa PeerAuthenticator) error {
+	dl, ok := ln.(*DatagramListener)
+	if !ok {
+		return errors.New("datagram transport requires a listener created with ListenDatagram")
+	}
+	conn := dl.conn
+
+	// unblock the read below once the context is cancelled
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	self := CalculateFP(cert.Certificate[0])
+	replays := newReplayCache(time.Now)
+
+	var lock sync.Mutex
+	responses := make(map[datagramKey]*datagramResponse)
+	handlers := make(chan struct{}, maxDatagramHandlers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	r := newReassembler(time.Now)
+	buf := make([]byte, 64*1024)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			select {
+			case <-dl.closed:
+				return nil
+			default:
+			}
+			return err
+		}
+
+		id, msg, ok := r.add(from.String(), buf[:n])
+		if !ok {
+			continue
+		}
+
+		key := datagramKey{from: from.String(), id: id}
+
+		lock.Lock()
+		resp, seen := responses[key]
+		var frags [][]byte
+		if seen {
+			frags = resp.frags
+		}
+		lock.Unlock()
+
+		if seen {
+			// the peer didn't receive our response yet, send it again
+			for _, frag := range frags {
+				conn.WriteTo(frag, from)
+			}
+			continue
+		}
+
+		select {
+		case handlers <- struct{}{}:
+		default:
+			// the peer sends the message again if it doesn't get a response
+			logger.Debugf("dropping datagram message from %s, too many messages being handled", from)
+			continue
+		}
+
+		lock.Lock()
+		now := time.Now()
+		pruneResponses(responses, now)
+		responses[key] = &datagramResponse{time: now}
+		lock.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-handlers }()
+
+			frags := t.handle(cert, self, replays, pa, id, msg)
+
+			lock.Lock()
+			responses[key].frags = frags
+			responses[key].done = true
+			responses[key].time = time.Now()
+			lock.Unlock()
+
+			for _, frag := range frags {
+				conn.WriteTo(frag, from)
+			}
+		}()
+	}
+}
+
+// handle routes the given message to the [PeerAuthenticator] and returns the
+// fragments of the response. No response is returned if the message is
+// invalid, or if its sender couldn't be verified.
+func (t *DatagramTransport) handle(cert tls.Certificate, self Fingerprint, replays *replayCache, pa PeerAuthenticator, id uint64, msg []byte) [][]byte {
+	env, fp, err := openDatagram(t.aead, id, msg)
+	if err != nil {
+		logger.Debugf("dropping invalid datagram message: %v", err)
+		return nil
+	}
+
+	// only auth messages may be sent without knowing who receives them
+	if !bytes.Equal(env.To, self[:]) && (env.Kind != "auth" || len(env.To) != 0) {
+		logger.Debug("dropping datagram message addressed to another peer")
+		return nil
+	}
+
+	if err := replays.add(fp, id, time.Unix(0, env.Time)); err != nil {
+		logger.Debugf("dropping datagram message: %v", err)
+		return nil
+	}
+
+	t.stats.recv(int64(len(msg)))
+
+	status := dispatchMessage(pa, fp, env.Kind, env.Payload)
+	if status == 403 {
+		return nil
+	}
+
+	sealed, err := sealDatagram(t.aead, cert, id, datagramEnvelope{
+		Kind:   "response",
+		Status: status,
+		To:     fp[:],
+		Time:   time.Now().UnixNano(),
+	}, 0)
+	if err != nil {
+		logger.Noticef("cannot respond to datagram message: %v", err)
+		return nil
+	}
+
+	if len(sealed) > len(msg) {
+		logger.Noticef("cannot respond to datagram message: response is larger than the message")
+		return nil
+	}
+
+	frags, err := fragmentDatagram(id, sealed)
+	if err != nil {
+		logger.Noticef("cannot respond to datagram message: %v", err)
+		return nil
+	}
+	return frags
+}
+
+// NewClient creates a [Client] compatible with this [DatagramTransport]. The
+// client signs its messages with the given certificate.
+func (t *DatagramTransport) NewClient(cert tls.Certificate) Client {
+	return &DatagramClient{
+		aead:    t.aead,
+		cert:    cert,
+		stats:   &t.stats,
+		limiter: rate.NewLimiter(rate.Limit(1_000_000), 5_000_000),
+	}
+}
+
+// Stats returns the cumulative statistics for messages sent and received by
+// this [Transport].
+func (t *DatagramTransport) Stats() TransportStats {
+	return t.stats.clone()
+}
+
+// DatagramClient implements the [Client] interface for sending outbound
+// assembly protocol messages with a [DatagramTransport].
+type DatagramClient struct {
+	aead    cipher.AEAD
+	cert    tls.Certificate
+	stats   *TransportStats
+	limiter *rate.Limiter
+}
+
+// NewDatagramClient creates a new [DatagramClient] with custom rate limiting,
+// that encrypts its messages with a key derived from the given shared assembly
+// secret. Pass nil for the limiter to disable rate limiting entirely.
+func NewDatagramClient(secret string, cert tls.Certificate, stats *TransportStats, limiter *rate.Limiter) *DatagramClient {
+	return &DatagramClient{
+		aead:    datagramCipher(secret),
+		cert:    cert,
+		stats:   stats,
+		limiter: limiter,
+	}
+}
+
+// Trusted sends a message to a trusted peer, verifying that the peer signs its
+// response with the certificate with the given fingerprint.
+func (c *DatagramClient) Trusted(ctx context.Context, addr string, fp Fingerprint, kind string, data any) error {
+	status, peer, err := c.exchange(ctx, addr, fp[:], kind, data)
+	if err != nil {
+		return err
+	}
+
+	if peer != fp {
+		return errors.New("refusing to communicate with unexpected peer certificate")
+	}
+
+	if status != 200 {
+		return fmt.Errorf("response to '%s' message contains status code %d", kind, status)
+	}
+
+	return nil
+}
+
+// Untrusted sends a message to a peer that isn't trusted yet, and returns the
+// fingerprint of the certificate that the peer signed its response with.
+func (c *DatagramClient) Untrusted(ctx context.Context, addr string, kind string, data any) (Fingerprint, error) {
+	status, fp, err := c.exchange(ctx, addr, nil, kind, data)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	if status != 200 {
+		return Fingerprint{}, fmt.Errorf("got non-200 status code in response to auth message: %d", status)
+	}
+
+	return fp, nil
+}
+
+// exchange sends a message for the peer with the given fingerprint, if known,
+// to the given address until the peer responds. It returns the status code of
+// the response along with the fingerprint of the peer's certificate.
+func (c *DatagramClient) exchange(ctx context.Context, addr string, to []byte, kind string, data any) (int, Fingerprint, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return 0, Fingerprint{}, err
+	}
+
+	var raw [8]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return 0, Fingerprint{}, err
+	}
+	id := binary.BigEndian.Uint64(raw[:])
+
+	sealed, err := sealDatagram(c.aead, c.cert, id, datagramEnvelope{
+		Kind:    kind,
+		Payload: payload,
+		To:      to,
+		Time:    time.Now().UnixNano(),
+	}, datagramFragmentSize)
+	if err != nil {
+		return 0, Fingerprint{}, err
+	}
+
+	frags, err := fragmentDatagram(id, sealed)
+	if err != nil {
+		return 0, Fingerprint{}, err
+	}
+
+	// rate limit based on the number of bytes/second that we're sending
+	if c.limiter != nil {
+		if err := c.limiter.WaitN(ctx, len(payload)); err != nil {
+			return 0, Fingerprint{}, err
+		}
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return 0, Fingerprint{}, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, datagramTimeout)
+	defer cancel()
+
+	self := CalculateFP(c.cert.Certificate[0])
+	r := newReassembler(time.Now)
+	buf := make([]byte, 64*1024)
+	interval := datagramRetransmit
+	for {
+		for _, frag := range frags {
+			if _, err := conn.Write(frag); err != nil {
+				return 0, Fingerprint{}, err
+			}
+		}
+
+		deadline := time.Now().Add(interval)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		conn.SetReadDeadline(deadline)
+
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return 0, Fingerprint{}, err
+			}
+
+			respID, msg, ok := r.add("", buf[:n])
+			if !ok || respID != id {
+				continue
+			}
+
+			env, fp, err := openDatagram(c.aead, id, msg)
+			if err != nil || env.Kind != "response" || !bytes.Equal(env.To, self[:]) {
+				logger.Debugf("dropping invalid response from %s", addr)
+				continue
+			}
+
+			if env.Status == 200 && c.stats != nil {
+				c.stats.sent(int64(len(payload)))
+			}
+
+			return env.Status, fp, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return 0, Fingerprint{}, err
+		}
+
+		interval *= 2
+		if interval > datagramMaxRetransmit {
+			interval = datagramMaxRetransmit
+		}
+	}
+}
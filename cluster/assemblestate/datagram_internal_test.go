@@ -0,0 +1,100 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type datagramSuite struct{}
+
+var _ = check.Suite(&datagramSuite{})
+
+func (s *datagramSuite) TestReplayCache(c *check.C) {
+	now := time.Now()
+	cache := newReplayCache(func() time.Time { return now })
+
+	fp := Fingerprint{1}
+	c.Assert(cache.add(fp, 1, now), check.IsNil)
+	c.Check(cache.add(fp, 1, now), check.ErrorMatches, "message was already received")
+
+	// the same id from another peer is a different message
+	c.Check(cache.add(Fingerprint{2}, 1, now), check.IsNil)
+
+	c.Check(cache.add(fp, 2, now.Add(-datagramReplayWindow-time.Second)), check.ErrorMatches, "message sent at .* is outside of the accepted time window")
+	c.Check(cache.add(fp, 2, now.Add(datagramReplayWindow+time.Second)), check.ErrorMatches, "message sent at .* is outside of the accepted time window")
+}
+
+func (s *datagramSuite) TestReplayCacheEviction(c *check.C) {
+	now := time.Now()
+	cache := newReplayCache(func() time.Time { return now })
+
+	start := now.Add(-time.Minute)
+	for i := 0; i < maxDatagramReplayCache; i++ {
+		c.Assert(cache.add(Fingerprint{1}, uint64(i), start.Add(time.Duration(i)*time.Millisecond)), check.IsNil)
+	}
+
+	// the oldest message is forgotten to make room for the new one
+	c.Assert(cache.add(Fingerprint{1}, uint64(maxDatagramReplayCache), now), check.IsNil)
+	c.Check(cache.seen, check.HasLen, maxDatagramReplayCache)
+	c.Check(cache.seen[replayKey{fp: Fingerprint{1}, id: 0}].IsZero(), check.Equals, true)
+
+	// so it, and anything sent before it, cannot be received again
+	c.Check(cache.add(Fingerprint{1}, 0, start), check.ErrorMatches, "message is older than the recently received messages")
+	c.Check(cache.add(Fingerprint{2}, 0, start), check.ErrorMatches, "message is older than the recently received messages")
+	c.Check(cache.add(Fingerprint{2}, 0, start.Add(time.Millisecond/2)), check.IsNil)
+
+	// once they are out of the time window, messages are forgotten first
+	now = now.Add(datagramReplayWindow)
+	c.Assert(cache.add(Fingerprint{1}, uint64(maxDatagramReplayCache+1), now), check.IsNil)
+	c.Check(cache.seen, check.HasLen, 2)
+}
+
+func (s *datagramSuite) TestPruneResponses(c *check.C) {
+	now := time.Now()
+	responses := make(map[datagramKey]*datagramResponse)
+
+	// a message that is still being handled
+	responses[datagramKey{from: "handling", id: 0}] = &datagramResponse{time: now.Add(-time.Hour)}
+	responses[datagramKey{from: "expired", id: 0}] = &datagramResponse{
+		done: true,
+		time: now.Add(-datagramReassemblyTimeout - time.Second),
+	}
+	for i := 0; len(responses) < maxDatagramResponses; i++ {
+		responses[datagramKey{from: "peer", id: uint64(i)}] = &datagramResponse{
+			done: true,
+			time: now.Add(-time.Duration(i) * time.Millisecond),
+		}
+	}
+
+	pruneResponses(responses, now)
+	c.Check(responses, check.HasLen, maxDatagramResponses-1)
+	c.Check(responses[datagramKey{from: "handling", id: 0}], check.NotNil)
+	c.Check(responses[datagramKey{from: "expired", id: 0}], check.IsNil)
+
+	// once full, the oldest response is forgotten
+	responses[datagramKey{from: "other", id: 0}] = &datagramResponse{done: true, time: now}
+	pruneResponses(responses, now)
+	c.Check(responses, check.HasLen, maxDatagramResponses-1)
+	c.Check(responses[datagramKey{from: "peer", id: uint64(maxDatagramResponses - 3)}], check.IsNil)
+	c.Check(responses[datagramKey{from: "handling", id: 0}], check.NotNil)
+}
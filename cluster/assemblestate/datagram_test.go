@@ -0,0 +1,357 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+)
+
+// serveDatagram starts a [assemblestate.DatagramTransport] server with the
+// given authenticator, and returns its address along with a function that
+// stops it.
+func serveDatagram(c *check.C, pa assemblestate.PeerAuthenticator) (*assemblestate.DatagramTransport, string, func()) {
+	transport := assemblestate.NewDatagramTransport("secret")
+
+	ln, err := assemblestate.ListenDatagram("127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := transport.Serve(ctx, ln, testServerCert, pa)
+		c.Check(err, check.IsNil)
+	}()
+
+	return transport, ln.Addr().String(), func() {
+		cancel()
+		wg.Wait()
+		ln.Close()
+	}
+}
+
+// largeRoutes returns routes that don't fit in a single datagram.
+func largeRoutes() assemblestate.Routes {
+	routes := assemblestate.Routes{}
+	for i := 0; i < 500; i++ {
+		routes.Devices = append(routes.Devices, assemblestate.DeviceToken(fmt.Sprintf("device-%d", i)))
+		routes.Addresses = append(routes.Addresses, fmt.Sprintf("10.0.%d.%d:8080", i/256, i%256))
+		routes.Routes = append(routes.Routes, 0, i, i)
+	}
+	return routes
+}
+
+func (s *transportSuite) TestDatagramTransport(c *check.C) {
+	var lock sync.Mutex
+	var auths []assemblestate.Auth
+	var routes []assemblestate.Routes
+	var fps []assemblestate.Fingerprint
+	pa := &testPeerAuthenticator{
+		AuthenticateAndCommitFunc: func(auth assemblestate.Auth, fp assemblestate.Fingerprint) error {
+			lock.Lock()
+			defer lock.Unlock()
+			auths = append(auths, auth)
+			fps = append(fps, fp)
+			return nil
+		},
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			fps = append(fps, fp)
+			return &testVerifiedPeer{
+				CommitRoutesFunc: func(r assemblestate.Routes) error {
+					lock.Lock()
+					defer lock.Unlock()
+					routes = append(routes, r)
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	transport, addr, stop := serveDatagram(c, pa)
+	defer stop()
+
+	client := assemblestate.NewDatagramTransport("secret").NewClient(testClientCert)
+
+	auth := assemblestate.Auth{
+		HMAC: []byte("test-hmac-data"),
+		RDT:  assemblestate.DeviceToken("test-rdt"),
+	}
+	fp, err := client.Untrusted(context.Background(), addr, "auth", auth)
+	c.Assert(err, check.IsNil)
+	c.Check(fp, check.Equals, testServerCertFP)
+
+	// large messages are split into multiple datagrams
+	err = client.Trusted(context.Background(), addr, testServerCertFP, "routes", largeRoutes())
+	c.Assert(err, check.IsNil)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.Check(auths, check.DeepEquals, []assemblestate.Auth{auth})
+	c.Check(routes, check.DeepEquals, []assemblestate.Routes{largeRoutes()})
+	c.Check(fps, check.DeepEquals, []assemblestate.Fingerprint{testClientCertFP, testClientCertFP})
+
+	stats := transport.Stats()
+	c.Check(stats.Received, check.Equals, int64(2))
+	c.Check(stats.Rx > 1200, check.Equals, true)
+}
+
+func (s *transportSuite) TestDatagramTransportErrors(c *check.C) {
+	pa := &testPeerAuthenticator{
+		AuthenticateAndCommitFunc: func(auth assemblestate.Auth, fp assemblestate.Fingerprint) error {
+			return errors.New("invalid hmac")
+		},
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			if fp != testClientCertFP {
+				return nil, errors.New("untrusted")
+			}
+			return &testVerifiedPeer{
+				CommitDevicesFunc: func(d assemblestate.Devices) error {
+					return errors.New("inconsistent devices")
+				},
+			}, nil
+		},
+	}
+
+	_, addr, stop := serveDatagram(c, pa)
+	defer stop()
+
+	client := assemblestate.NewDatagramClient("secret", testClientCert, nil, nil)
+
+	err := client.Trusted(context.Background(), addr, testServerCertFP, "devices", assemblestate.Devices{})
+	c.Check(err, check.ErrorMatches, "response to 'devices' message contains status code 400")
+
+	err = client.Trusted(context.Background(), addr, testServerCertFP, "other", assemblestate.Devices{})
+	c.Check(err, check.ErrorMatches, "response to 'other' message contains status code 404")
+
+	// peers that cannot be verified don't get any response
+	expectNoResponse := func(send func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		c.Check(send(ctx), check.Equals, context.DeadlineExceeded)
+	}
+
+	expectNoResponse(func(ctx context.Context) error {
+		_, err := client.Untrusted(ctx, addr, "auth", assemblestate.Auth{})
+		return err
+	})
+
+	// the client isn't trusted
+	other, _ := generateTestCert()
+	expectNoResponse(func(ctx context.Context) error {
+		return assemblestate.NewDatagramClient("secret", other, nil, nil).Trusted(ctx, addr, testServerCertFP, "devices", assemblestate.Devices{})
+	})
+
+	// the client doesn't know the shared secret
+	expectNoResponse(func(ctx context.Context) error {
+		return assemblestate.NewDatagramClient("other-secret", testClientCert, nil, nil).Trusted(ctx, addr, testServerCertFP, "devices", assemblestate.Devices{})
+	})
+
+	// the message is addressed to another peer
+	expectNoResponse(func(ctx context.Context) error {
+		return client.Trusted(ctx, addr, testClientCertFP, "devices", assemblestate.Devices{})
+	})
+}
+
+func (s *transportSuite) TestDatagramTransportTimeout(c *check.C) {
+	// nothing responds on this socket
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	client := assemblestate.NewDatagramClient("secret", testClientCert, nil, nil)
+	err = client.Trusted(ctx, conn.LocalAddr().String(), testServerCertFP, "routes", assemblestate.Routes{})
+	c.Check(err, check.Equals, context.DeadlineExceeded)
+}
+
+func (s *transportSuite) TestDatagramTransportLossyLink(c *check.C) {
+	var lock sync.Mutex
+	var received int
+	pa := &testPeerAuthenticator{
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			return &testVerifiedPeer{
+				CommitRoutesFunc: func(r assemblestate.Routes) error {
+					lock.Lock()
+					defer lock.Unlock()
+					received++
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	_, addr, stop := serveDatagram(c, pa)
+	defer stop()
+
+	proxy := newLossyProxy(c, addr, 0.2)
+	defer proxy.Close()
+
+	client := assemblestate.NewDatagramClient("secret", testClientCert, nil, nil)
+	for i := 0; i < 3; i++ {
+		err := client.Trusted(context.Background(), proxy.LocalAddr().String(), testServerCertFP, "routes", largeRoutes())
+		c.Assert(err, check.IsNil)
+	}
+
+	// duplicates caused by lost responses are not handled again
+	lock.Lock()
+	defer lock.Unlock()
+	c.Check(received, check.Equals, 3)
+}
+
+func (s *transportSuite) TestDatagramTransportReplay(c *check.C) {
+	var lock sync.Mutex
+	var received int
+	pa := &testPeerAuthenticator{
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			return &testVerifiedPeer{
+				CommitRoutesFunc: func(r assemblestate.Routes) error {
+					lock.Lock()
+					defer lock.Unlock()
+					received++
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	_, addr, stop := serveDatagram(c, pa)
+	defer stop()
+
+	var sent, responded [][]byte
+	proxy := newProxy(c, addr, func(datagram []byte, upstream bool) bool {
+		lock.Lock()
+		defer lock.Unlock()
+		if upstream {
+			sent = append(sent, append([]byte(nil), datagram...))
+		} else {
+			responded = append(responded, append([]byte(nil), datagram...))
+		}
+		return true
+	})
+	defer proxy.Close()
+
+	client := assemblestate.NewDatagramClient("secret", testClientCert, nil, nil)
+	err := client.Trusted(context.Background(), proxy.LocalAddr().String(), testServerCertFP, "routes", assemblestate.Routes{})
+	c.Assert(err, check.IsNil)
+
+	lock.Lock()
+	c.Assert(sent, check.HasLen, 1)
+	c.Assert(responded, check.HasLen, 1)
+	// the response is never larger than the message
+	c.Check(len(responded[0]) <= len(sent[0]), check.Equals, true)
+	replayed := sent[0]
+	lock.Unlock()
+
+	// the captured message is replayed from another address
+	conn, err := net.Dial("udp", addr)
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+
+	_, err = conn.Write(replayed)
+	c.Assert(err, check.IsNil)
+
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 64*1024))
+	var netErr net.Error
+	c.Check(errors.As(err, &netErr) && netErr.Timeout(), check.Equals, true)
+
+	lock.Lock()
+	defer lock.Unlock()
+	c.Check(received, check.Equals, 1)
+}
+
+// newLossyProxy relays datagrams between its clients and the given address,
+// dropping the given ratio of them in both directions.
+func newLossyProxy(c *check.C, target string, ratio float64) net.PacketConn {
+	var lock sync.Mutex
+	rng := rand.New(rand.NewSource(1))
+	return newProxy(c, target, func(datagram []byte, upstream bool) bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return rng.Float64() >= ratio
+	})
+}
+
+// newProxy relays datagrams between its clients and the given address. Each
+// datagram is given to the relay function, along with its direction, and is
+// only relayed if the function returns true.
+func newProxy(c *check.C, target string, relay func(datagram []byte, upstream bool) bool) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+
+	upstreams := make(map[string]net.Conn)
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				for _, up := range upstreams {
+					up.Close()
+				}
+				return
+			}
+
+			up, ok := upstreams[from.String()]
+			if !ok {
+				up, err = net.Dial("udp", target)
+				if err != nil {
+					continue
+				}
+				upstreams[from.String()] = up
+
+				go func() {
+					buf := make([]byte, 64*1024)
+					for {
+						n, err := up.Read(buf)
+						if err != nil {
+							return
+						}
+						if relay(buf[:n], false) {
+							conn.WriteTo(buf[:n], from)
+						}
+					}
+				}()
+			}
+
+			if relay(buf[:n], true) {
+				up.Write(buf[:n])
+			}
+		}
+	}()
+
+	return conn
+}
@@ -0,0 +1,411 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/randutil"
+)
+
+// Discovery provides an abstraction for finding the addresses of peers that
+// are taking part in an assembly session.
+type Discovery interface {
+	// Discover makes this device, which is reachable at the given address,
+	// known to its peers and sends the addresses of the peers it finds on
+	// found. It returns once the context is cancelled.
+	Discover(ctx context.Context, self string, found chan<- []string) error
+}
+
+// StartDiscovery runs the given [Discovery] in the background until the
+// context is cancelled. The returned channel is meant to be passed to
+// [AssembleState.Run].
+func StartDiscovery(ctx context.Context, d Discovery, self string) <-chan []string {
+	found := make(chan []string)
+	go func() {
+		if err := d.Discover(ctx, self, found); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Noticef("cannot discover assemble peers: %v", err)
+		}
+	}()
+	return found
+}
+
+// StaticDiscovery is a [Discovery] that reports a fixed list of peer
+// addresses.
+type StaticDiscovery []string
+
+// Discover sends the static list of addresses on found and waits for the
+// context to be cancelled.
+func (sd StaticDiscovery) Discover(ctx context.Context, self string, found chan<- []string) error {
+	if len(sd) > 0 {
+		select {
+		case found <- []string(sd):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+const (
+	// MDNSService is the DNS-SD service type under which devices taking part
+	// in an assembly session advertise themselves.
+	MDNSService = "_snapd-assemble._tcp"
+
+	mdnsDomain         = "local."
+	mdnsDefaultTTL     = 120
+	mdnsMaxPacketSize  = 9000
+	mdnsDefaultRefresh = 5 * time.Second
+)
+
+var mdnsIPv4Group = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// MDNSDiscovery is a [Discovery] that advertises this device as a DNS-SD
+// service over multicast DNS and browses the local network for other
+// instances of the same service.
+type MDNSDiscovery struct {
+	// Interface is the network interface on which this device is advertised
+	// and peers are browsed. If nil, the system's default multicast interface
+	// is used.
+	Interface *net.Interface
+	// Instance is the name of the service instance advertised by this
+	// device, usually its RDT. It must be unique in the network and must not
+	// contain dots. If empty, a random name is used.
+	Instance string
+	// Interval is the time between queries for peers. If unset, defaults to
+	// 5 seconds.
+	Interval time.Duration
+
+	// listen is mocked in tests.
+	listen func(iface *net.Interface, group *net.UDPAddr) (net.PacketConn, error)
+}
+
+func listenMulticast(iface *net.Interface, group *net.UDPAddr) (net.PacketConn, error) {
+	return net.ListenMulticastUDP("udp4", iface, group)
+}
+
+// Discover advertises this device and browses for peers until the context is
+// cancelled. When the given address doesn't contain a specific IP address, all
+// the addresses of the network interface are advertised.
+func (md *MDNSDiscovery) Discover(ctx context.Context, self string, found chan<- []string) error {
+	host, portStr, err := net.SplitHostPort(self)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %v", self, err)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port in address %q", self)
+	}
+
+	ips, err := md.advertisedIPs(host)
+	if err != nil {
+		return err
+	}
+
+	instance := md.Instance
+	if instance == "" {
+		instance = randutil.RandomString(16)
+	}
+	if strings.Contains(instance, ".") || len(instance) > 63 {
+		return fmt.Errorf("invalid mDNS instance name %q", instance)
+	}
+
+	interval := md.Interval
+	if interval == 0 {
+		interval = mdnsDefaultRefresh
+	}
+
+	listen := md.listen
+	if listen == nil {
+		listen = listenMulticast
+	}
+
+	conn, err := listen(md.Interface, mdnsIPv4Group)
+	if err != nil {
+		return fmt.Errorf("cannot listen for mDNS messages: %v", err)
+	}
+	defer conn.Close()
+
+	svc := mdnsServiceInfo{
+		instance: instance,
+		port:     uint16(port),
+		ips:      ips,
+	}
+
+	packets := make(chan []byte)
+	go func() {
+		buf := make([]byte, mdnsMaxPacketSize)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				// the connection is closed when we're done
+				return
+			}
+
+			packet := make([]byte, n)
+			copy(packet, buf[:n])
+
+			select {
+			case packets <- packet:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	send := func(msg []byte) {
+		if _, err := conn.WriteTo(msg, mdnsIPv4Group); err != nil {
+			logger.Debugf("cannot send mDNS message: %v", err)
+		}
+	}
+
+	// announce ourselves and say goodbye once we're done so that peers can
+	// stop trying to reach us
+	send(svc.response(mdnsDefaultTTL))
+	defer send(svc.response(0))
+
+	browser := newMDNSBrowser(instance)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			send(mdnsQuery())
+			timer.Reset(interval)
+		case packet := <-packets:
+			msg, err := parseMDNSMessage(packet)
+			if err != nil {
+				logger.Debugf("cannot parse mDNS message: %v", err)
+				continue
+			}
+
+			if !msg.response {
+				if svc.answers(msg.questions) {
+					send(svc.response(mdnsDefaultTTL))
+				}
+				continue
+			}
+
+			addrs := browser.record(msg.records)
+			if len(addrs) == 0 {
+				continue
+			}
+
+			select {
+			case found <- addrs:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (md *MDNSDiscovery) advertisedIPs(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		return []net.IP{ip}, nil
+	}
+
+	var addrs []net.Addr
+	var err error
+	if md.Interface != nil {
+		addrs, err = md.Interface.Addrs()
+	} else {
+		addrs, err = net.InterfaceAddrs()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get addresses to advertise: %v", err)
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ips = append(ips, ipnet.IP)
+	}
+
+	if len(ips) == 0 {
+		return nil, errors.New("cannot find any address to advertise")
+	}
+	return ips, nil
+}
+
+func mdnsServiceName() string {
+	return MDNSService + "." + mdnsDomain
+}
+
+// mdnsServiceInfo describes the service instance advertised by this device.
+type mdnsServiceInfo struct {
+	instance string
+	port     uint16
+	ips      []net.IP
+}
+
+func (s mdnsServiceInfo) instanceName() string {
+	return s.instance + "." + mdnsServiceName()
+}
+
+func (s mdnsServiceInfo) hostName() string {
+	return s.instance + "." + mdnsDomain
+}
+
+// answers returns true if any of the questions is about this device's
+// service.
+func (s mdnsServiceInfo) answers(questions []mdnsQuestion) bool {
+	for _, q := range questions {
+		if q.qtype != dnsTypePTR && q.qtype != dnsTypeSRV && q.qtype != dnsTypeANY {
+			continue
+		}
+
+		if strings.EqualFold(q.name, mdnsServiceName()) || strings.EqualFold(q.name, s.instanceName()) {
+			return true
+		}
+	}
+	return false
+}
+
+// response builds a message advertising the service with the given TTL. A
+// TTL of zero withdraws the service.
+func (s mdnsServiceInfo) response(ttl uint32) []byte {
+	records := []mdnsRecord{
+		{name: mdnsServiceName(), rtype: dnsTypePTR, ttl: ttl, target: s.instanceName()},
+		{name: s.instanceName(), rtype: dnsTypeSRV, ttl: ttl, target: s.hostName(), port: s.port},
+		{name: s.instanceName(), rtype: dnsTypeTXT, ttl: ttl},
+	}
+	for _, ip := range s.ips {
+		rtype := dnsTypeAAAA
+		if ip.To4() != nil {
+			rtype = dnsTypeA
+		}
+		records = append(records, mdnsRecord{name: s.hostName(), rtype: rtype, ttl: ttl, ip: ip})
+	}
+
+	return mdnsMessage{response: true, records: records}.encode()
+}
+
+func mdnsQuery() []byte {
+	return mdnsMessage{
+		questions: []mdnsQuestion{{name: mdnsServiceName(), qtype: dnsTypePTR}},
+	}.encode()
+}
+
+type mdnsSRV struct {
+	target string
+	port   uint16
+}
+
+// mdnsBrowser accumulates the records that peers advertise and resolves them
+// into the addresses at which their assemble service can be reached.
+type mdnsBrowser struct {
+	self      string
+	instances map[string]bool
+	srvs      map[string]mdnsSRV
+	ips       map[string][]net.IP
+	reported  map[string]bool
+}
+
+func newMDNSBrowser(self string) *mdnsBrowser {
+	return &mdnsBrowser{
+		self:      strings.ToLower(self + "." + mdnsServiceName()),
+		instances: make(map[string]bool),
+		srvs:      make(map[string]mdnsSRV),
+		ips:       make(map[string][]net.IP),
+		reported:  make(map[string]bool),
+	}
+}
+
+// record processes the records of a response and returns the addresses that
+// can now be resolved and weren't reported before.
+func (b *mdnsBrowser) record(records []mdnsRecord) []string {
+	for _, r := range records {
+		name := strings.ToLower(r.name)
+		switch r.rtype {
+		case dnsTypePTR:
+			if name != strings.ToLower(mdnsServiceName()) {
+				continue
+			}
+
+			instance := strings.ToLower(r.target)
+			if instance == b.self {
+				continue
+			}
+
+			if r.ttl == 0 {
+				delete(b.instances, instance)
+			} else {
+				b.instances[instance] = true
+			}
+		case dnsTypeSRV:
+			b.srvs[name] = mdnsSRV{target: strings.ToLower(r.target), port: r.port}
+		case dnsTypeA, dnsTypeAAAA:
+			if r.ttl == 0 {
+				delete(b.ips, name)
+				continue
+			}
+			if !containsIP(b.ips[name], r.ip) {
+				b.ips[name] = append(b.ips[name], r.ip)
+			}
+		}
+	}
+
+	var addrs []string
+	for instance := range b.instances {
+		srv, ok := b.srvs[instance]
+		if !ok {
+			continue
+		}
+
+		for _, ip := range b.ips[srv.target] {
+			addr := net.JoinHostPort(ip.String(), strconv.Itoa(int(srv.port)))
+			if b.reported[addr] {
+				continue
+			}
+			b.reported[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+
+	sort.Strings(addrs)
+	return addrs
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type discoverySuite struct{}
+
+var _ = check.Suite(&discoverySuite{})
+
+// fakeMulticastBus delivers every packet written by one of its connections to
+// all of them, including the sender, like a multicast group with loopback
+// enabled.
+type fakeMulticastBus struct {
+	lock  sync.Mutex
+	conns []*fakeMulticastConn
+}
+
+func (b *fakeMulticastBus) listen(iface *net.Interface, group *net.UDPAddr) (net.PacketConn, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	conn := &fakeMulticastConn{
+		bus:    b,
+		in:     make(chan []byte, 64),
+		closed: make(chan struct{}),
+	}
+	b.conns = append(b.conns, conn)
+	return conn, nil
+}
+
+func (b *fakeMulticastBus) send(packet []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, conn := range b.conns {
+		select {
+		case conn.in <- append([]byte(nil), packet...):
+		case <-conn.closed:
+		default:
+			// like UDP, drop the packet if the receiver can't keep up
+		}
+	}
+}
+
+type fakeMulticastConn struct {
+	bus    *fakeMulticastBus
+	in     chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (c *fakeMulticastConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case packet := <-c.in:
+		return copy(b, packet), mdnsIPv4Group, nil
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *fakeMulticastConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.bus.send(b)
+	return len(b), nil
+}
+
+func (c *fakeMulticastConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeMulticastConn) LocalAddr() net.Addr                { return mdnsIPv4Group }
+func (c *fakeMulticastConn) SetDeadline(t time.Time) error      { return nil }
+func (c *fakeMulticastConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *fakeMulticastConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (s *discoverySuite) TestMessageRoundTrip(c *check.C) {
+	svc := mdnsServiceInfo{
+		instance: "device-1",
+		port:     8001,
+		ips:      []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1")},
+	}
+
+	msg, err := parseMDNSMessage(svc.response(120))
+	c.Assert(err, check.IsNil)
+	c.Check(msg.response, check.Equals, true)
+	c.Check(msg.questions, check.HasLen, 0)
+	c.Check(msg.records, check.DeepEquals, []mdnsRecord{
+		{name: "_snapd-assemble._tcp.local.", rtype: dnsTypePTR, ttl: 120, target: "device-1._snapd-assemble._tcp.local."},
+		{name: "device-1._snapd-assemble._tcp.local.", rtype: dnsTypeSRV, ttl: 120, target: "device-1.local.", port: 8001},
+		{name: "device-1.local.", rtype: dnsTypeA, ttl: 120, ip: net.ParseIP("10.0.0.1").To4()},
+		{name: "device-1.local.", rtype: dnsTypeAAAA, ttl: 120, ip: net.ParseIP("fd00::1")},
+	})
+
+	msg, err = parseMDNSMessage(mdnsQuery())
+	c.Assert(err, check.IsNil)
+	c.Check(msg.response, check.Equals, false)
+	c.Check(msg.questions, check.DeepEquals, []mdnsQuestion{
+		{name: "_snapd-assemble._tcp.local.", qtype: dnsTypePTR},
+	})
+	c.Check(svc.answers(msg.questions), check.Equals, true)
+	c.Check(svc.answers([]mdnsQuestion{{name: "_http._tcp.local.", qtype: dnsTypePTR}}), check.Equals, false)
+	c.Check(svc.answers([]mdnsQuestion{{name: "device-1._snapd-assemble._tcp.local.", qtype: dnsTypeSRV}}), check.Equals, true)
+}
+
+func (s *discoverySuite) TestParseCompressedNames(c *check.C) {
+	msg := []byte{
+		0, 0, 0x84, 0, // ID and flags
+		0, 0, 0, 2, 0, 0, 0, 0, // counts
+	}
+	// PTR _svc._tcp.local. -> dev.<pointer to _svc._tcp.local.>
+	msg = append(msg, 4, '_', 's', 'v', 'c', 4, '_', 't', 'c', 'p', 5, 'l', 'o', 'c', 'a', 'l', 0)
+	msg = append(msg, 0, 12, 0, 1, 0, 0, 0, 120, 0, 6)
+	msg = append(msg, 3, 'd', 'e', 'v', 0xc0, 12)
+	// SRV <pointer to dev._svc._tcp.local.> -> port 80, target dev.<pointer to local.>
+	msg = append(msg, 0xc0, 39)
+	msg = append(msg, 0, 33, 0x80, 1, 0, 0, 0, 120, 0, 12)
+	msg = append(msg, 0, 0, 0, 0, 0, 80, 3, 'd', 'e', 'v', 0xc0, 22)
+
+	parsed, err := parseMDNSMessage(msg)
+	c.Assert(err, check.IsNil)
+	c.Check(parsed.records, check.DeepEquals, []mdnsRecord{
+		{name: "_svc._tcp.local.", rtype: dnsTypePTR, ttl: 120, target: "dev._svc._tcp.local."},
+		{name: "dev._svc._tcp.local.", rtype: dnsTypeSRV, ttl: 120, target: "dev.local.", port: 80},
+	})
+}
+
+func (s *discoverySuite) TestParseInvalidMessages(c *check.C) {
+	valid := mdnsServiceInfo{instance: "device-1", port: 8001, ips: []net.IP{net.ParseIP("10.0.0.1")}}.response(120)
+
+	for i := 0; i < len(valid); i++ {
+		_, err := parseMDNSMessage(valid[:i])
+		c.Check(err, check.NotNil, check.Commentf("length %d", i))
+	}
+
+	// a compression pointer that points to itself
+	loop := []byte{0, 0, 0x84, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xc0, 12, 0, 12, 0, 1}
+	_, err := parseMDNSMessage(loop)
+	c.Check(err, check.ErrorMatches, "too many compression pointers")
+
+	// an A record with the wrong length
+	bad := mdnsMessage{response: true, records: []mdnsRecord{{name: "a.local.", rtype: dnsTypeA, ttl: 1, ip: net.ParseIP("fd00::1")}}}.encode()
+	_, err = parseMDNSMessage(bad)
+	c.Check(err, check.ErrorMatches, `invalid A record for "a.local."`)
+}
+
+func (s *discoverySuite) TestBrowser(c *check.C) {
+	b := newMDNSBrowser("self")
+
+	self := mdnsServiceInfo{instance: "self", port: 8001, ips: []net.IP{net.ParseIP("10.0.0.1")}}
+	msg, err := parseMDNSMessage(self.response(120))
+	c.Assert(err, check.IsNil)
+	c.Check(b.record(msg.records), check.HasLen, 0)
+
+	peer := mdnsServiceInfo{instance: "peer", port: 8002, ips: []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("fd00::2")}}
+	msg, err = parseMDNSMessage(peer.response(120))
+	c.Assert(err, check.IsNil)
+
+	// records can arrive in separate messages
+	c.Check(b.record(msg.records[:1]), check.HasLen, 0)
+	c.Check(b.record(msg.records[1:]), check.DeepEquals, []string{"10.0.0.2:8002", "[fd00::2]:8002"})
+
+	// addresses are only reported once
+	c.Check(b.record(msg.records), check.HasLen, 0)
+}
+
+func (s *discoverySuite) TestMDNSDiscovery(c *check.C) {
+	bus := &fakeMulticastBus{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type result struct {
+		name  string
+		addrs []string
+	}
+	results := make(chan result, 10)
+
+	var wg sync.WaitGroup
+	discover := func(instance, self string) {
+		d := &MDNSDiscovery{
+			Instance: instance,
+			Interval: 10 * time.Millisecond,
+			listen:   bus.listen,
+		}
+
+		found := make(chan []string)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := d.Discover(ctx, self, found)
+			c.Check(err, check.Equals, context.Canceled)
+		}()
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case addrs := <-found:
+					results <- result{name: instance, addrs: addrs}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	discover("one", "10.0.0.1:8001")
+	discover("two", "10.0.0.2:8002")
+
+	got := make(map[string][]string)
+	for len(got) < 2 {
+		select {
+		case r := <-results:
+			got[r.name] = append(got[r.name], r.addrs...)
+		case <-time.After(5 * time.Second):
+			c.Fatal("timed out waiting for peers to be discovered")
+		}
+	}
+
+	c.Check(got, check.DeepEquals, map[string][]string{
+		"one": {"10.0.0.2:8002"},
+		"two": {"10.0.0.1:8001"},
+	})
+
+	cancel()
+	wg.Wait()
+}
+
+func (s *discoverySuite) TestMDNSDiscoveryErrors(c *check.C) {
+	bus := &fakeMulticastBus{}
+	ctx := context.Background()
+
+	d := &MDNSDiscovery{Instance: "one", listen: bus.listen}
+	err := d.Discover(ctx, "10.0.0.1", nil)
+	c.Check(err, check.ErrorMatches, `invalid address "10.0.0.1": .*`)
+
+	err = d.Discover(ctx, "10.0.0.1:port", nil)
+	c.Check(err, check.ErrorMatches, `invalid port in address "10.0.0.1:port"`)
+
+	d = &MDNSDiscovery{Instance: "one.two", listen: bus.listen}
+	err = d.Discover(ctx, "10.0.0.1:8001", nil)
+	c.Check(err, check.ErrorMatches, `invalid mDNS instance name "one.two"`)
+}
+
+func (s *discoverySuite) TestStaticDiscovery(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	found := StartDiscovery(ctx, StaticDiscovery{"10.0.0.2:8002", "10.0.0.3:8003"}, "10.0.0.1:8001")
+
+	select {
+	case addrs := <-found:
+		c.Check(addrs, check.DeepEquals, []string{"10.0.0.2:8002", "10.0.0.3:8003"})
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for static addresses")
+	}
+}
@@ -0,0 +1,295 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// This file implements the subset of the DNS message format (RFC 1035) that is
+// needed to advertise and browse DNS-SD services (RFC 6763) over multicast DNS
+// (RFC 6762).
+
+const (
+	dnsTypeA    uint16 = 1
+	dnsTypePTR  uint16 = 12
+	dnsTypeTXT  uint16 = 16
+	dnsTypeAAAA uint16 = 28
+	dnsTypeSRV  uint16 = 33
+	dnsTypeANY  uint16 = 255
+
+	dnsClassIN uint16 = 1
+	// mdnsClassMask clears the cache-flush (in records) and unicast-response
+	// (in questions) bits of the class.
+	mdnsClassMask uint16 = 0x7fff
+	// mdnsCacheFlush marks records which are unique to the responder.
+	mdnsCacheFlush uint16 = 0x8000
+
+	dnsFlagResponse      uint16 = 0x8000
+	dnsFlagAuthoritative uint16 = 0x0400
+
+	dnsHeaderSize = 12
+	// dnsMaxPointers bounds the compression pointers followed while parsing a
+	// name, to protect against loops.
+	dnsMaxPointers = 16
+)
+
+type mdnsQuestion struct {
+	name  string
+	qtype uint16
+}
+
+// mdnsRecord is a resource record. Only the fields relevant to its type are
+// set.
+type mdnsRecord struct {
+	name  string
+	rtype uint16
+	ttl   uint32
+
+	// target is set for PTR and SRV records.
+	target string
+	// port is set for SRV records.
+	port uint16
+	// ip is set for A and AAAA records.
+	ip net.IP
+}
+
+type mdnsMessage struct {
+	response  bool
+	questions []mdnsQuestion
+	// records holds the records from the answer, authority and additional
+	// sections.
+	records []mdnsRecord
+}
+
+func (m mdnsMessage) encode() []byte {
+	var flags uint16
+	if m.response {
+		flags = dnsFlagResponse | dnsFlagAuthoritative
+	}
+
+	buf := make([]byte, dnsHeaderSize, 512)
+	// the ID is always zero in multicast DNS
+	binary.BigEndian.PutUint16(buf[2:], flags)
+	binary.BigEndian.PutUint16(buf[4:], uint16(len(m.questions)))
+	binary.BigEndian.PutUint16(buf[6:], uint16(len(m.records)))
+
+	for _, q := range m.questions {
+		buf = appendName(buf, q.name)
+		buf = appendUint16(buf, q.qtype)
+		buf = appendUint16(buf, dnsClassIN)
+	}
+
+	for _, r := range m.records {
+		class := dnsClassIN
+		if r.rtype != dnsTypePTR {
+			// only the PTR record is shared between all instances
+			class |= mdnsCacheFlush
+		}
+
+		var data []byte
+		switch r.rtype {
+		case dnsTypePTR:
+			data = appendName(nil, r.target)
+		case dnsTypeSRV:
+			// priority and weight are unused
+			data = make([]byte, 4, 4+2+len(r.target)+2)
+			data = appendUint16(data, r.port)
+			data = appendName(data, r.target)
+		case dnsTypeTXT:
+			// DNS-SD requires a TXT record, which may be a single empty string
+			data = []byte{0}
+		case dnsTypeA:
+			data = r.ip.To4()
+		case dnsTypeAAAA:
+			data = r.ip.To16()
+		}
+
+		buf = appendName(buf, r.name)
+		buf = appendUint16(buf, r.rtype)
+		buf = appendUint16(buf, class)
+		buf = appendUint32(buf, r.ttl)
+		buf = appendUint16(buf, uint16(len(data)))
+		buf = append(buf, data...)
+	}
+
+	return buf
+}
+
+func appendName(buf []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		buf = append(buf, byte(len(label)))
+		buf = append(buf, label...)
+	}
+	return append(buf, 0)
+}
+
+var errDNSTruncated = errors.New("message is truncated")
+
+func parseMDNSMessage(msg []byte) (mdnsMessage, error) {
+	if len(msg) < dnsHeaderSize {
+		return mdnsMessage{}, errDNSTruncated
+	}
+
+	flags := binary.BigEndian.Uint16(msg[2:])
+	qdcount := int(binary.BigEndian.Uint16(msg[4:]))
+	rrcount := int(binary.BigEndian.Uint16(msg[6:])) +
+		int(binary.BigEndian.Uint16(msg[8:])) +
+		int(binary.BigEndian.Uint16(msg[10:]))
+
+	m := mdnsMessage{response: flags&dnsFlagResponse != 0}
+
+	off := dnsHeaderSize
+	for i := 0; i < qdcount; i++ {
+		name, next, err := parseName(msg, off)
+		if err != nil {
+			return mdnsMessage{}, err
+		}
+		if next+4 > len(msg) {
+			return mdnsMessage{}, errDNSTruncated
+		}
+
+		m.questions = append(m.questions, mdnsQuestion{
+			name:  name,
+			qtype: binary.BigEndian.Uint16(msg[next:]),
+		})
+		off = next + 4
+	}
+
+	for i := 0; i < rrcount; i++ {
+		name, next, err := parseName(msg, off)
+		if err != nil {
+			return mdnsMessage{}, err
+		}
+		if next+10 > len(msg) {
+			return mdnsMessage{}, errDNSTruncated
+		}
+
+		r := mdnsRecord{
+			name:  name,
+			rtype: binary.BigEndian.Uint16(msg[next:]),
+			ttl:   binary.BigEndian.Uint32(msg[next+4:]),
+		}
+		class := binary.BigEndian.Uint16(msg[next+2:]) & mdnsClassMask
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+
+		start := next + 10
+		end := start + length
+		if end > len(msg) {
+			return mdnsMessage{}, errDNSTruncated
+		}
+		off = end
+
+		if class != dnsClassIN {
+			continue
+		}
+
+		data := msg[start:end]
+		switch r.rtype {
+		case dnsTypePTR:
+			if r.target, _, err = parseName(msg, start); err != nil {
+				return mdnsMessage{}, err
+			}
+		case dnsTypeSRV:
+			if length < 7 {
+				return mdnsMessage{}, fmt.Errorf("invalid SRV record for %q", name)
+			}
+			r.port = binary.BigEndian.Uint16(data[4:])
+			if r.target, _, err = parseName(msg, start+6); err != nil {
+				return mdnsMessage{}, err
+			}
+		case dnsTypeA:
+			if length != net.IPv4len {
+				return mdnsMessage{}, fmt.Errorf("invalid A record for %q", name)
+			}
+			r.ip = net.IP(append([]byte(nil), data...))
+		case dnsTypeAAAA:
+			if length != net.IPv6len {
+				return mdnsMessage{}, fmt.Errorf("invalid AAAA record for %q", name)
+			}
+			r.ip = net.IP(append([]byte(nil), data...))
+		default:
+			continue
+		}
+
+		m.records = append(m.records, r)
+	}
+
+	return m, nil
+}
+
+// parseName parses the, possibly compressed, name at the given offset and
+// returns it along with the offset right after it.
+func parseName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for pointers := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errDNSTruncated
+		}
+
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if next == -1 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(msg) {
+				return "", 0, errDNSTruncated
+			}
+
+			pointers++
+			if pointers > dnsMaxPointers {
+				return "", 0, errors.New("too many compression pointers")
+			}
+
+			if next == -1 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+		case length&0xc0 != 0:
+			return "", 0, fmt.Errorf("invalid label length %#x", length)
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errDNSTruncated
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// TODO:GOVERSION: use binary.BigEndian.AppendUint{16,32} when we're on go >= 1.19
+func appendUint16(buf []byte, v uint16) []byte {
+	return append(buf, byte(v>>8), byte(v))
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,235 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2025 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+// MembershipChange describes how the membership of an existing cluster should
+// change as the result of a re-assembly session.
+//
+// During a re-assembly session, the devices that are already members of the
+// cluster take part using the [DeviceToken] and TLS certificate that they
+// stored when the cluster was assembled. Since new devices can only join the
+// session by proving knowledge of the session secret to a trusted peer, a
+// route from a member to a new device means that the member vouches for it.
+type MembershipChange struct {
+	// Members maps the serials of the current members of the cluster to the
+	// fingerprints of the TLS certificates that they used when the cluster was
+	// assembled. Members that take part in the re-assembly session must use
+	// the same certificate.
+	Members map[string]Fingerprint
+	// Remove lists the IDs of the devices to remove from the cluster, for
+	// example because they are dead.
+	Remove []int
+}
+
+// ClusterUpdateHeaders builds the headers of the next cluster assertion in the
+// sequence of the given cluster assertion, from the data returned by a
+// re-assembly session and the given membership change. Once signed, the
+// resulting assertion can be installed with clusterstate.UpdateCluster.
+//
+// The IDs of the existing devices are kept stable. Members that took part in
+// the session have their addresses updated from the given routes, members that
+// did not are kept unchanged. New devices are assigned IDs after the highest
+// existing ID, in the same order as [AssertionDevices], and must be vouched for
+// by at least one member. They are not added to any subcluster. Removed
+// devices are dropped from the devices and from all subclusters.
+func ClusterUpdateHeaders(cluster *asserts.Cluster, ids []Identity, routes Routes, change MembershipChange) (map[string]any, error) {
+	addresses, err := addressesFromRoutes(routes)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]asserts.ClusterDevice, len(cluster.Devices()))
+	maxID := 0
+	for _, dev := range cluster.Devices() {
+		existing[dev.DeviceID.String()] = dev
+		if dev.ID > maxID {
+			maxID = dev.ID
+		}
+	}
+
+	removed := make(map[int]bool, len(change.Remove))
+	for _, id := range change.Remove {
+		found := false
+		for _, dev := range cluster.Devices() {
+			if dev.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("cannot remove unknown device id %d", id)
+		}
+		removed[id] = true
+	}
+
+	// members maps the RDTs of the members that took part in the session to
+	// their device in the current cluster assertion
+	members := make(map[DeviceToken]asserts.ClusterDevice)
+	serials := make(map[DeviceToken]*asserts.Serial, len(ids))
+	var newcomers []Identity
+	for _, identity := range ids {
+		serial, err := serialFromBundle(identity.SerialBundle)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse serial bundle for device %q: %w", identity.RDT, err)
+		}
+
+		if _, ok := serials[identity.RDT]; ok {
+			return nil, fmt.Errorf("duplicate device token found in identities: %q", identity.RDT)
+		}
+		serials[identity.RDT] = serial
+
+		dev, ok := existing[serial.DeviceID().String()]
+		if !ok {
+			newcomers = append(newcomers, identity)
+			continue
+		}
+
+		if removed[dev.ID] {
+			return nil, fmt.Errorf("cannot remove device id %d that took part in the re-assembly session", dev.ID)
+		}
+
+		fp, ok := change.Members[serial.Serial()]
+		if !ok || fp != identity.FP {
+			return nil, fmt.Errorf("cluster member %q did not use its stored certificate", serial.Serial())
+		}
+
+		members[identity.RDT] = dev
+	}
+
+	if len(newcomers) > 0 && len(members) == 0 {
+		return nil, errors.New("cannot add devices to a cluster without any of its members")
+	}
+
+	// a member vouches for a new device if it has a verified route to it
+	vouched := make(map[DeviceToken]bool)
+	for i := 0; i+2 < len(routes.Routes); i += 3 {
+		src := routes.Devices[routes.Routes[i]]
+		dest := routes.Devices[routes.Routes[i+1]]
+		if _, ok := members[src]; ok {
+			vouched[dest] = true
+		}
+	}
+
+	for _, identity := range newcomers {
+		if !vouched[identity.RDT] {
+			return nil, fmt.Errorf("new device %q is not vouched for by any cluster member", serials[identity.RDT].Serial())
+		}
+		if len(addresses[identity.RDT]) == 0 {
+			return nil, fmt.Errorf("no addresses available for device %q", identity.RDT)
+		}
+	}
+
+	// use the same order as AssertionDevices, so that the assignment of ids is
+	// consistent
+	sort.Slice(newcomers, func(i, j int) bool {
+		left := serials[newcomers[i].RDT]
+		right := serials[newcomers[j].RDT]
+
+		if left.BrandID() != right.BrandID() {
+			return left.BrandID() < right.BrandID()
+		}
+		if left.Model() != right.Model() {
+			return left.Model() < right.Model()
+		}
+
+		return left.Serial() < right.Serial()
+	})
+
+	updated := make(map[int][]any, len(members))
+	for rdt, dev := range members {
+		if addrs := addresses[rdt]; len(addrs) > 0 {
+			updated[dev.ID] = addrs
+		}
+	}
+
+	devices := make([]any, 0, len(cluster.Devices())+len(newcomers))
+	for _, dev := range cluster.Devices() {
+		if removed[dev.ID] {
+			continue
+		}
+
+		addrs, ok := updated[dev.ID]
+		if !ok {
+			addrs = make([]any, 0, len(dev.Addresses))
+			for _, addr := range dev.Addresses {
+				addrs = append(addrs, addr)
+			}
+		}
+
+		devices = append(devices, map[string]any{
+			"id":        strconv.Itoa(dev.ID),
+			"device":    dev.DeviceID.String(),
+			"addresses": addrs,
+		})
+	}
+
+	for i, identity := range newcomers {
+		devices = append(devices, map[string]any{
+			"id":        strconv.Itoa(maxID + i + 1),
+			"device":    serials[identity.RDT].DeviceID().String(),
+			"addresses": addresses[identity.RDT],
+		})
+	}
+
+	headers := cluster.Headers()
+
+	// these are set again when the new assertion is signed
+	delete(headers, "sign-key-sha3-384")
+	delete(headers, "timestamp")
+	delete(headers, "body-length")
+
+	headers["sequence"] = strconv.Itoa(cluster.Sequence() + 1)
+	headers["devices"] = devices
+
+	if subclusters, ok := headers["subclusters"].([]any); ok && len(removed) > 0 {
+		for _, entry := range subclusters {
+			subcluster, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			ids, ok := subcluster["devices"].([]any)
+			if !ok {
+				continue
+			}
+
+			kept := make([]any, 0, len(ids))
+			for _, raw := range ids {
+				id, err := strconv.Atoi(fmt.Sprint(raw))
+				if err == nil && removed[id] {
+					continue
+				}
+				kept = append(kept, raw)
+			}
+			subcluster["devices"] = kept
+		}
+	}
+
+	return headers, nil
+}
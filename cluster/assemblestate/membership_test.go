@@ -0,0 +1,248 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2025 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate_test
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+)
+
+type membershipFixture struct {
+	signing    *assertstest.SigningDB
+	cluster    *asserts.Cluster
+	identities []assemblestate.Identity
+	members    map[string]assemblestate.Fingerprint
+}
+
+// newMembershipFixture creates a cluster of devices serial-1, serial-2 and
+// serial-9, and the identities of serial-1, serial-2 and of a new device
+// serial-3 taking part in a re-assembly session.
+func newMembershipFixture(c *check.C) membershipFixture {
+	const secret = "secret"
+
+	key, _ := assertstest.GenerateKey(752)
+	signing := assertstest.NewSigningDB("authority-id", key)
+
+	f := membershipFixture{
+		signing: signing,
+		members: make(map[string]assemblestate.Fingerprint),
+	}
+
+	for i, serial := range []string{"serial-1", "serial-2", "serial-3"} {
+		_, bundle, key := makeBundleWithID(c, "brand", "model", serial)
+		rdt := assemblestate.DeviceToken(fmt.Sprintf("device-%d", i))
+		fp := assemblestate.CalculateFP([]byte(fmt.Sprintf("certificate-%d", i)))
+
+		proof, err := asserts.RawSignWithKey(assemblestate.CalculateHMAC(rdt, fp, secret), key)
+		c.Assert(err, check.IsNil)
+
+		f.identities = append(f.identities, assemblestate.Identity{
+			RDT:          rdt,
+			FP:           fp,
+			SerialBundle: bundle,
+			SerialProof:  proof,
+		})
+
+		if serial != "serial-3" {
+			f.members[serial] = fp
+		}
+	}
+
+	a, err := signing.Sign(asserts.ClusterType, map[string]any{
+		"type":       "cluster",
+		"cluster-id": "cluster-id",
+		"sequence":   "3",
+		"devices": []any{
+			map[string]any{"id": "1", "device": "serial-1.model.brand", "addresses": []any{"10.0.0.1:8080"}},
+			map[string]any{"id": "2", "device": "serial-2.model.brand", "addresses": []any{"10.0.0.2:8080"}},
+			map[string]any{"id": "5", "device": "serial-9.model.brand", "addresses": []any{"10.0.0.9:8080"}},
+		},
+		"subclusters": []any{
+			map[string]any{
+				"name":    "default",
+				"devices": []any{"1", "2", "5"},
+				"snaps": []any{
+					map[string]any{"state": "clustered", "instance": "app", "channel": "stable"},
+				},
+			},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, check.IsNil)
+	f.cluster = a.(*asserts.Cluster)
+
+	return f
+}
+
+func membershipRoutes() assemblestate.Routes {
+	return assemblestate.Routes{
+		Devices:   []assemblestate.DeviceToken{"device-0", "device-1", "device-2"},
+		Addresses: []string{"10.0.1.1:8080", "10.0.1.2:8080", "10.0.1.3:8080"},
+		Routes: []int{
+			0, 1, 1,
+			1, 0, 0,
+			0, 2, 2,
+			2, 0, 0,
+		},
+	}
+}
+
+func (s *assembleSuite) TestClusterUpdateHeaders(c *check.C) {
+	f := newMembershipFixture(c)
+
+	headers, err := assemblestate.ClusterUpdateHeaders(f.cluster, f.identities, membershipRoutes(), assemblestate.MembershipChange{
+		Members: f.members,
+		Remove:  []int{5},
+	})
+	c.Assert(err, check.IsNil)
+
+	headers["timestamp"] = time.Now().Format(time.RFC3339)
+	a, err := f.signing.Sign(asserts.ClusterType, headers, nil, "")
+	c.Assert(err, check.IsNil)
+
+	cluster := a.(*asserts.Cluster)
+	c.Check(cluster.ClusterID(), check.Equals, "cluster-id")
+	c.Check(cluster.Sequence(), check.Equals, 4)
+
+	// ids of existing devices are stable, the new device gets the next free
+	// id and the dead device is removed
+	c.Check(cluster.Devices(), check.DeepEquals, []asserts.ClusterDevice{{
+		ID:        1,
+		Addresses: []string{"10.0.1.1:8080"},
+		DeviceID:  asserts.DeviceID{BrandID: "brand", Model: "model", Serial: "serial-1"},
+	}, {
+		ID:        2,
+		Addresses: []string{"10.0.1.2:8080"},
+		DeviceID:  asserts.DeviceID{BrandID: "brand", Model: "model", Serial: "serial-2"},
+	}, {
+		ID:        6,
+		Addresses: []string{"10.0.1.3:8080"},
+		DeviceID:  asserts.DeviceID{BrandID: "brand", Model: "model", Serial: "serial-3"},
+	}})
+
+	c.Assert(cluster.Subclusters(), check.HasLen, 1)
+	c.Check(cluster.Subclusters()[0].Devices, check.DeepEquals, []int{1, 2})
+	c.Check(cluster.Subclusters()[0].Snaps, check.HasLen, 1)
+}
+
+func (s *assembleSuite) TestClusterUpdateHeadersAbsentMember(c *check.C) {
+	f := newMembershipFixture(c)
+
+	// serial-2 doesn't take part in the session, it keeps its addresses
+	routes := assemblestate.Routes{
+		Devices:   []assemblestate.DeviceToken{"device-0", "device-2"},
+		Addresses: []string{"10.0.1.1:8080", "10.0.1.3:8080"},
+		Routes:    []int{0, 1, 1, 1, 0, 0},
+	}
+	ids := []assemblestate.Identity{f.identities[0], f.identities[2]}
+
+	headers, err := assemblestate.ClusterUpdateHeaders(f.cluster, ids, routes, assemblestate.MembershipChange{
+		Members: f.members,
+	})
+	c.Assert(err, check.IsNil)
+
+	headers["timestamp"] = time.Now().Format(time.RFC3339)
+	a, err := f.signing.Sign(asserts.ClusterType, headers, nil, "")
+	c.Assert(err, check.IsNil)
+
+	cluster := a.(*asserts.Cluster)
+	c.Assert(cluster.Devices(), check.HasLen, 4)
+	c.Check(cluster.Devices()[1].Addresses, check.DeepEquals, []string{"10.0.0.2:8080"})
+	c.Check(cluster.Devices()[3].ID, check.Equals, 6)
+	c.Check(cluster.Subclusters()[0].Devices, check.DeepEquals, []int{1, 2, 5})
+}
+
+func (s *assembleSuite) TestClusterUpdateHeadersErrors(c *check.C) {
+	f := newMembershipFixture(c)
+
+	wrongFP := make(map[string]assemblestate.Fingerprint)
+	for serial, fp := range f.members {
+		wrongFP[serial] = fp
+	}
+	wrongFP["serial-2"] = assemblestate.CalculateFP([]byte("other"))
+
+	// only device-1 can reach the new device, but device-1 is not trusted as a
+	// member
+	unvouched := assemblestate.Routes{
+		Devices:   []assemblestate.DeviceToken{"device-0", "device-1", "device-2"},
+		Addresses: []string{"10.0.1.1:8080", "10.0.1.2:8080", "10.0.1.3:8080"},
+		Routes:    []int{1, 0, 0, 2, 0, 0, 1, 2, 2},
+	}
+
+	type test struct {
+		ids    []assemblestate.Identity
+		routes assemblestate.Routes
+		change assemblestate.MembershipChange
+		err    string
+	}
+
+	tests := []test{{
+		ids:    f.identities,
+		routes: membershipRoutes(),
+		change: assemblestate.MembershipChange{Members: f.members, Remove: []int{3}},
+		err:    "cannot remove unknown device id 3",
+	}, {
+		ids:    f.identities,
+		routes: membershipRoutes(),
+		change: assemblestate.MembershipChange{Members: f.members, Remove: []int{2}},
+		err:    "cannot remove device id 2 that took part in the re-assembly session",
+	}, {
+		ids:    f.identities,
+		routes: membershipRoutes(),
+		change: assemblestate.MembershipChange{Members: wrongFP},
+		err:    `cluster member "serial-2" did not use its stored certificate`,
+	}, {
+		ids:    f.identities[:1],
+		routes: membershipRoutes(),
+		change: assemblestate.MembershipChange{},
+		err:    `cluster member "serial-1" did not use its stored certificate`,
+	}, {
+		ids:    f.identities[2:],
+		routes: membershipRoutes(),
+		change: assemblestate.MembershipChange{Members: f.members},
+		err:    "cannot add devices to a cluster without any of its members",
+	}, {
+		ids:    f.identities[1:],
+		routes: unvouched,
+		change: assemblestate.MembershipChange{Members: map[string]assemblestate.Fingerprint{"serial-1": f.members["serial-1"]}},
+		err:    `cluster member "serial-2" did not use its stored certificate`,
+	}, {
+		ids:    []assemblestate.Identity{f.identities[0], f.identities[2]},
+		routes: unvouched,
+		change: assemblestate.MembershipChange{Members: f.members},
+		err:    `new device "serial-3" is not vouched for by any cluster member`,
+	}, {
+		ids:    f.identities,
+		routes: assemblestate.Routes{Routes: []int{0}},
+		change: assemblestate.MembershipChange{Members: f.members},
+		err:    "routes array length must be multiple of 3",
+	}}
+
+	for _, t := range tests {
+		_, err := assemblestate.ClusterUpdateHeaders(f.cluster, t.ids, t.routes, t.change)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
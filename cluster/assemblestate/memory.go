@@ -0,0 +1,285 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// MemoryNetwork connects devices that run an assembly session in the same
+// process, without using any sockets. It is mostly useful to simulate the
+// assembly of large clusters.
+//
+// Each device listens on an address of the network with
+// [MemoryNetwork.Listen] and uses its own [MemoryTransport].
+type MemoryNetwork struct {
+	lock      sync.Mutex
+	listeners map[string]*memoryListener
+
+	// drop, if set, decides which messages are lost on the way to their
+	// destination.
+	drop func(from Fingerprint, to string, kind string) bool
+}
+
+type memoryServer struct {
+	fp    Fingerprint
+	pa    PeerAuthenticator
+	stats *TransportStats
+}
+
+// NewMemoryNetwork creates an empty [MemoryNetwork].
+func NewMemoryNetwork() *MemoryNetwork {
+	return &MemoryNetwork{
+		listeners: make(map[string]*memoryListener),
+	}
+}
+
+// SetDropFunc sets a function that decides which messages are lost on the way
+// to their destination, to simulate lossy links. Lost messages result in an
+// error for their sender. Pass nil to deliver all messages.
+func (n *MemoryNetwork) SetDropFunc(drop func(from Fingerprint, to string, kind string) bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.drop = drop
+}
+
+// Listen reserves the given address on the network. The returned listener is
+// to be given to [AssembleState.Run], along with a [MemoryTransport] for the
+// same network. Closing the listener releases the address.
+func (n *MemoryNetwork) Listen(addr string) (net.Listener, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.listeners[addr]; ok {
+		return nil, fmt.Errorf("address %s already in use", addr)
+	}
+
+	l := &memoryListener{
+		network: n,
+		addr:    memoryAddr(addr),
+		served:  make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	n.listeners[addr] = l
+
+	return l, nil
+}
+
+type memoryAddr string
+
+func (a memoryAddr) Network() string {
+	return "memory"
+}
+
+func (a memoryAddr) String() string {
+	return string(a)
+}
+
+// memoryListener reserves an address on a [MemoryNetwork]. Messages are
+// delivered directly to the [PeerAuthenticator] of the server, so it never
+// accepts any connections. Like with a TCP listener, messages sent before the
+// server starts wait for it.
+type memoryListener struct {
+	network *MemoryNetwork
+	addr    memoryAddr
+	once    sync.Once
+	closed  chan struct{}
+
+	// server is set once, before served is closed.
+	server *memoryServer
+	served chan struct{}
+}
+
+func (l *memoryListener) Accept() (net.Conn, error) {
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *memoryListener) Close() error {
+	l.once.Do(func() {
+		l.network.lock.Lock()
+		defer l.network.lock.Unlock()
+		if l.network.listeners[string(l.addr)] == l {
+			delete(l.network.listeners, string(l.addr))
+		}
+		close(l.closed)
+	})
+	return nil
+}
+
+func (l *memoryListener) Addr() net.Addr {
+	return l.addr
+}
+
+// MemoryTransport implements the [Transport] interface on top of a
+// [MemoryNetwork]. Messages are still encoded to JSON, so that they are
+// subject to the same constraints as with the [HTTPSTransport], and the
+// fingerprint of the sender's certificate is passed along with each message.
+type MemoryTransport struct {
+	network *MemoryNetwork
+	stats   TransportStats
+}
+
+// NewMemoryTransport creates a new [MemoryTransport] for a device connected to
+// the given network.
+func NewMemoryTransport(network *MemoryNetwork) *MemoryTransport {
+	return &MemoryTransport{network: network}
+}
+
+// Serve implements the [Transport] interface. It routes the messages sent to
+// the address of the given listener, which must have been created with
+// [MemoryNetwork.Listen], to the given [PeerAuthenticator]. The server runs
+// until the context is cancelled.
+func (t *MemoryTransport) Serve(ctx context.Context, ln net.Listener, cert tls.Certificate, pa PeerAuthenticator) error {
+	ml, ok := ln.(*memoryListener)
+	if !ok || ml.network != t.network {
+		return errors.New("memory transport requires a listener from the same memory network")
+	}
+
+	t.network.lock.Lock()
+	if ml.server != nil {
+		t.network.lock.Unlock()
+		return fmt.Errorf("address %s already served", ml.addr)
+	}
+	ml.server = &memoryServer{
+		fp:    CalculateFP(cert.Certificate[0]),
+		pa:    pa,
+		stats: &t.stats,
+	}
+	close(ml.served)
+	t.network.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-ml.closed:
+	}
+
+	// the server is gone, the address is released
+	ml.Close()
+
+	return nil
+}
+
+// NewClient creates a [Client] compatible with this [MemoryTransport]. The
+// peers know the client by the fingerprint of the given certificate.
+func (t *MemoryTransport) NewClient(cert tls.Certificate) Client {
+	return &memoryClient{
+		network: t.network,
+		fp:      CalculateFP(cert.Certificate[0]),
+		stats:   &t.stats,
+	}
+}
+
+// Stats returns the cumulative statistics for messages sent and received by
+// this [Transport].
+func (t *MemoryTransport) Stats() TransportStats {
+	return t.stats.clone()
+}
+
+type memoryClient struct {
+	network *MemoryNetwork
+	fp      Fingerprint
+	stats   *TransportStats
+}
+
+// send delivers the given message to the server at the given address, and
+// returns the status code of the response along with the fingerprint of the
+// server's certificate. If expected is not nil, the message is only delivered
+// if the server uses the certificate with that fingerprint.
+func (c *memoryClient) send(ctx context.Context, addr string, expected *Fingerprint, kind string, data any) (int, Fingerprint, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, Fingerprint{}, err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return 0, Fingerprint{}, err
+	}
+
+	c.network.lock.Lock()
+	ln, ok := c.network.listeners[addr]
+	dropped := c.network.drop != nil && c.network.drop(c.fp, addr, kind)
+	c.network.lock.Unlock()
+
+	if !ok {
+		return 0, Fingerprint{}, fmt.Errorf("cannot reach %s: connection refused", addr)
+	}
+
+	select {
+	case <-ln.served:
+	case <-ln.closed:
+		return 0, Fingerprint{}, fmt.Errorf("cannot reach %s: connection refused", addr)
+	case <-ctx.Done():
+		return 0, Fingerprint{}, ctx.Err()
+	}
+	server := ln.server
+
+	if expected != nil && server.fp != *expected {
+		return 0, Fingerprint{}, errors.New("refusing to communicate with unexpected peer certificate")
+	}
+
+	if dropped {
+		return 0, Fingerprint{}, fmt.Errorf("cannot reach %s: message lost", addr)
+	}
+
+	status := dispatchMessage(server.pa, c.fp, kind, payload)
+	server.stats.recv(int64(len(payload)))
+	if status == 200 {
+		c.stats.sent(int64(len(payload)))
+	}
+
+	return status, server.fp, nil
+}
+
+// Trusted sends a message to a trusted peer, checking that the peer uses the
+// certificate with the given fingerprint.
+func (c *memoryClient) Trusted(ctx context.Context, addr string, fp Fingerprint, kind string, data any) error {
+	status, _, err := c.send(ctx, addr, &fp, kind, data)
+	if err != nil {
+		return err
+	}
+
+	if status != 200 {
+		return fmt.Errorf("response to '%s' message contains status code %d", kind, status)
+	}
+
+	return nil
+}
+
+// Untrusted sends a message to a peer that isn't trusted yet, and returns the
+// fingerprint of the peer's certificate.
+func (c *memoryClient) Untrusted(ctx context.Context, addr string, kind string, data any) (Fingerprint, error) {
+	status, fp, err := c.send(ctx, addr, nil, kind, data)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	if status != 200 {
+		return Fingerprint{}, fmt.Errorf("got non-200 status code in response to auth message: %d", status)
+	}
+
+	return fp, nil
+}
@@ -0,0 +1,138 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assemblestate_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+)
+
+func (s *transportSuite) TestMemoryTransport(c *check.C) {
+	var auths []assemblestate.Auth
+	var devices []assemblestate.Devices
+	var fps []assemblestate.Fingerprint
+	pa := &testPeerAuthenticator{
+		AuthenticateAndCommitFunc: func(auth assemblestate.Auth, fp assemblestate.Fingerprint) error {
+			auths = append(auths, auth)
+			fps = append(fps, fp)
+			return nil
+		},
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			if fp != testClientCertFP {
+				return nil, errors.New("untrusted")
+			}
+			return &testVerifiedPeer{
+				CommitDevicesFunc: func(d assemblestate.Devices) error {
+					if len(d.Devices) == 0 {
+						return errors.New("no devices")
+					}
+					devices = append(devices, d)
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	network := assemblestate.NewMemoryNetwork()
+	ln, err := network.Listen("server:8080")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+	c.Check(ln.Addr().String(), check.Equals, "server:8080")
+
+	_, err = network.Listen("server:8080")
+	c.Check(err, check.ErrorMatches, "address server:8080 already in use")
+
+	server := assemblestate.NewMemoryTransport(network)
+	client := assemblestate.NewMemoryTransport(network).NewClient(testClientCert)
+
+	// messages sent before the server starts wait for it
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond)
+		err := server.Serve(ctx, ln, testServerCert, pa)
+		c.Check(err, check.IsNil)
+	}()
+
+	auth := assemblestate.Auth{HMAC: []byte("hmac"), RDT: "rdt"}
+	fp, err := client.Untrusted(context.Background(), "server:8080", "auth", auth)
+	c.Assert(err, check.IsNil)
+	c.Check(fp, check.Equals, testServerCertFP)
+	c.Check(auths, check.DeepEquals, []assemblestate.Auth{auth})
+	c.Check(fps, check.DeepEquals, []assemblestate.Fingerprint{testClientCertFP})
+
+	ids := assemblestate.Devices{Devices: []assemblestate.Identity{{RDT: "rdt"}}}
+	err = client.Trusted(context.Background(), "server:8080", testServerCertFP, "devices", ids)
+	c.Assert(err, check.IsNil)
+	c.Check(devices, check.DeepEquals, []assemblestate.Devices{ids})
+
+	err = client.Trusted(context.Background(), "server:8080", testServerCertFP, "devices", assemblestate.Devices{})
+	c.Check(err, check.ErrorMatches, "response to 'devices' message contains status code 400")
+
+	err = client.Trusted(context.Background(), "server:8080", testClientCertFP, "devices", ids)
+	c.Check(err, check.ErrorMatches, "refusing to communicate with unexpected peer certificate")
+
+	other, _ := generateTestCert()
+	err = assemblestate.NewMemoryTransport(network).NewClient(other).Trusted(context.Background(), "server:8080", testServerCertFP, "devices", ids)
+	c.Check(err, check.ErrorMatches, "response to 'devices' message contains status code 403")
+
+	network.SetDropFunc(func(from assemblestate.Fingerprint, to string, kind string) bool {
+		return kind == "devices"
+	})
+	err = client.Trusted(context.Background(), "server:8080", testServerCertFP, "devices", ids)
+	c.Check(err, check.ErrorMatches, "cannot reach server:8080: message lost")
+	network.SetDropFunc(nil)
+
+	stats := server.Stats()
+	c.Check(stats.Received, check.Equals, int64(4))
+
+	_, err = client.Untrusted(context.Background(), "other:8080", "auth", auth)
+	c.Check(err, check.ErrorMatches, "cannot reach other:8080: connection refused")
+
+	// the address is released once the server stops
+	cancel()
+	wg.Wait()
+
+	_, err = client.Untrusted(context.Background(), "server:8080", "auth", auth)
+	c.Check(err, check.ErrorMatches, "cannot reach server:8080: connection refused")
+
+	ln, err = network.Listen("server:8080")
+	c.Assert(err, check.IsNil)
+	ln.Close()
+}
+
+func (s *transportSuite) TestMemoryTransportWrongListener(c *check.C) {
+	transport := assemblestate.NewMemoryTransport(assemblestate.NewMemoryNetwork())
+
+	ln, err := assemblestate.NewMemoryNetwork().Listen("addr")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+
+	err = transport.Serve(context.Background(), ln, testServerCert, &testPeerAuthenticator{})
+	c.Check(err, check.ErrorMatches, "memory transport requires a listener from the same memory network")
+}
@@ -19,6 +19,12 @@
 
 package assemblestate
 
+import (
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
 type (
 	// Fingerprint is the sha512 of a TLS certificate
 	Fingerprint [64]byte
@@ -110,3 +116,56 @@ type Routes struct {
 	//   - Route 2: Devices[2] can reach Devices[1] via Addresses[1]
 	Routes []int `json:"routes"`
 }
+
+// Report is a top-level message used once a cluster is assembled. Devices
+// periodically send it to their peers to describe how far they are in
+// converging to the state described by the cluster assertion.
+type Report struct {
+	// Serial is the serial of the device that sent this message.
+	Serial string `json:"serial"`
+
+	// ClusterID and Sequence identify the cluster assertion that the device is
+	// converging to.
+	ClusterID string `json:"cluster-id"`
+	Sequence  int    `json:"sequence"`
+
+	// Snaps lists the snaps of the device's subclusters that are installed on
+	// the device.
+	Snaps []ReportedSnap `json:"snaps,omitempty"`
+
+	// FailedChanges lists the changes that failed to apply the cluster
+	// assertion on the device.
+	FailedChanges []ReportedChange `json:"failed-changes,omitempty"`
+
+	// Converged is true if the device's state matched the cluster assertion
+	// when the report was made.
+	Converged bool `json:"converged"`
+
+	// LastConverged is the last time at which the device's state matched the
+	// cluster assertion. It is zero if the device never converged.
+	LastConverged time.Time `json:"last-converged"`
+
+	// Rollout maps the names of the device's subclusters that are rolled out
+	// in batches to how far the device is in applying the cluster assertion
+	// to them. Devices use it to decide when their batch can proceed.
+	Rollout map[string]string `json:"rollout,omitempty"`
+
+	// Time is the time at which the report was made.
+	Time time.Time `json:"time"`
+}
+
+// ReportedSnap describes a snap installed on a device in a [Report].
+type ReportedSnap struct {
+	Instance string        `json:"instance"`
+	Revision snap.Revision `json:"revision"`
+	Channel  string        `json:"channel,omitempty"`
+}
+
+// ReportedChange describes a failed change in a [Report].
+type ReportedChange struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
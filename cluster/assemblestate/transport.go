@@ -90,6 +90,9 @@ type VerifiedPeer interface {
 	CommitDevices(devices Devices) error
 	// CommitRoutes records the given routes.
 	CommitRoutes(routes Routes) error
+	// CommitReport records the given convergence report. Reports are only
+	// exchanged once the cluster is assembled.
+	CommitReport(report Report) error
 }
 
 // TransportStats carries the statistics for a [Transport].
@@ -147,6 +150,7 @@ func NewHTTPSTransport() *HTTPSTransport {
 //   - /assemble/routes: Route information from trusted peers
 //   - /assemble/unknown: Device queries from trusted peers
 //   - /assemble/devices: Device identity responses from trusted peers
+//   - /assemble/report: Convergence reports from trusted peers
 //
 // The server runs until the context is cancelled.
 func (t *HTTPSTransport) Serve(ctx context.Context, ln net.Listener, cert tls.Certificate, pa PeerAuthenticator) error {
@@ -157,6 +161,7 @@ func (t *HTTPSTransport) Serve(ctx context.Context, ln net.Listener, cert tls.Ce
 	mux.Handle("/assemble/routes", t.statsHandler(t.trustedHandler(t.handleRoutes, pa)))
 	mux.Handle("/assemble/unknown", t.statsHandler(t.trustedHandler(t.handleUnknown, pa)))
 	mux.Handle("/assemble/devices", t.statsHandler(t.trustedHandler(t.handleDevices, pa)))
+	mux.Handle("/assemble/report", t.statsHandler(t.trustedHandler(t.handleReport, pa)))
 
 	server := &http.Server{
 		Handler: mux,
@@ -232,7 +237,6 @@ func (t *HTTPSTransport) handleAuth(w http.ResponseWriter, r *http.Request, pa P
 	}
 
 	// set a max size so an untrusted peer can't send some massive JSON
-	const maxAuthSize = 1024 * 4
 	body := http.MaxBytesReader(w, r.Body, maxAuthSize)
 
 	var auth Auth
@@ -316,6 +320,20 @@ func (t *HTTPSTransport) handleDevices(w http.ResponseWriter, r *http.Request, p
 	}
 }
 
+func (t *HTTPSTransport) handleReport(w http.ResponseWriter, r *http.Request, peer VerifiedPeer) {
+	var report Report
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		w.WriteHeader(400)
+		return
+	}
+
+	if err := peer.CommitReport(report); err != nil {
+		w.WriteHeader(400)
+		logger.Debugf("cannot commit report: %v", err)
+		return
+	}
+}
+
 // NewClient creates a Client compatible with this [HTTPSTransport] for sending
 // outbound assembly protocol messages. The client will use the provided TLS
 // certificate for mutual authentication.
@@ -500,3 +518,80 @@ func sendWithResponse(
 
 	return res, int64(len(payload)), nil
 }
+
+// maxAuthSize bounds the size of the auth messages, so that an untrusted peer
+// can't send some massive JSON.
+const maxAuthSize = 1024 * 4
+
+// messageKinds contains the kinds of messages that peers can send to each
+// other.
+var messageKinds = map[string]bool{
+	"auth":    true,
+	"routes":  true,
+	"unknown": true,
+	"devices": true,
+	"report":  true,
+}
+
+// dispatchMessage routes a message of the given kind, received from the peer
+// that presented the certificate with the given fingerprint, to the
+// [PeerAuthenticator]. It is used by the transports that don't route messages
+// through an HTTP server, and returns the HTTP status code that the
+// [HTTPSTransport] would have responded with.
+func dispatchMessage(pa PeerAuthenticator, fp Fingerprint, kind string, payload []byte) int {
+	if !messageKinds[kind] {
+		return 404
+	}
+
+	if kind == "auth" {
+		if len(payload) > maxAuthSize {
+			return 400
+		}
+
+		var auth Auth
+		if err := json.Unmarshal(payload, &auth); err != nil {
+			return 400
+		}
+
+		if err := pa.AuthenticateAndCommit(auth, fp); err != nil {
+			logger.Debugf("cannot authenticate peer: %v", err)
+			return 403
+		}
+		return 200
+	}
+
+	peer, err := pa.VerifyPeer(fp)
+	if err != nil {
+		logger.Debug("dropping message from untrusted peer")
+		return 403
+	}
+
+	switch kind {
+	case "routes":
+		var routes Routes
+		if err = json.Unmarshal(payload, &routes); err == nil {
+			err = peer.CommitRoutes(routes)
+		}
+	case "unknown":
+		var unknown UnknownDevices
+		if err = json.Unmarshal(payload, &unknown); err == nil {
+			err = peer.CommitDeviceQueries(unknown)
+		}
+	case "devices":
+		var devices Devices
+		if err = json.Unmarshal(payload, &devices); err == nil {
+			err = peer.CommitDevices(devices)
+		}
+	case "report":
+		var report Report
+		if err = json.Unmarshal(payload, &report); err == nil {
+			err = peer.CommitReport(report)
+		}
+	}
+	if err != nil {
+		logger.Debugf("cannot commit %s message: %v", kind, err)
+		return 400
+	}
+
+	return 200
+}
@@ -42,6 +42,7 @@ import (
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/snap"
 )
 
 type transportSuite struct{}
@@ -427,6 +428,7 @@ type testVerifiedPeer struct {
 	CommitDevicesFunc       func(devices assemblestate.Devices) error
 	CommitDeviceQueriesFunc func(unknown assemblestate.UnknownDevices) error
 	CommitRoutesFunc        func(routes assemblestate.Routes) error
+	CommitReportFunc        func(report assemblestate.Report) error
 	RDTFunc                 func() assemblestate.DeviceToken
 }
 
@@ -451,6 +453,13 @@ func (m *testVerifiedPeer) CommitRoutes(routes assemblestate.Routes) error {
 	return m.CommitRoutesFunc(routes)
 }
 
+func (m *testVerifiedPeer) CommitReport(report assemblestate.Report) error {
+	if m.CommitReportFunc == nil {
+		panic("unexpected call to CommitReport")
+	}
+	return m.CommitReportFunc(report)
+}
+
 func (s *transportSuite) TestHTTPSTransportServeAuthRoute(c *check.C) {
 	var auths []assemblestate.Auth
 	var fps []assemblestate.Fingerprint
@@ -574,6 +583,62 @@ func (s *transportSuite) TestHTTPSTransportServeDevicesRoute(c *check.C) {
 	wg.Wait()
 }
 
+func (s *transportSuite) TestHTTPSTransportServeReportRoute(c *check.C) {
+	var reports []assemblestate.Report
+	pa := &testPeerAuthenticator{
+		VerifyPeerFunc: func(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+			c.Check(fp, check.Equals, testClientCertFP)
+			return &testVerifiedPeer{
+				CommitReportFunc: func(r assemblestate.Report) error {
+					reports = append(reports, r)
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	transport := assemblestate.NewHTTPSTransport()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = transport.Serve(ctx, ln, testServerCert, pa)
+	}()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := assemblestate.Report{
+		Serial:    "serial-1",
+		ClusterID: "cluster-id",
+		Sequence:  2,
+		Snaps: []assemblestate.ReportedSnap{
+			{Instance: "some-snap", Revision: snap.R(7), Channel: "latest/stable"},
+		},
+		FailedChanges: []assemblestate.ReportedChange{
+			{ID: "3", Kind: "apply-cluster-subcluster", Summary: "Apply subcluster", Error: "boom", Time: now},
+		},
+		LastConverged: now,
+		Time:          now,
+	}
+
+	client := transport.NewClient(testClientCert)
+	err = client.Trusted(ctx, ln.Addr().String(), testServerCertFP, "report", msg)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(reports, check.HasLen, 1)
+	c.Check(reports[0], check.DeepEquals, msg)
+
+	cancel()
+	wg.Wait()
+}
+
 func (s *transportSuite) TestHTTPSTransportServeRoutesRoute(c *check.C) {
 	var routes []assemblestate.Routes
 	var peerFPs []assemblestate.Fingerprint
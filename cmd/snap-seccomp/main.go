@@ -1113,6 +1113,12 @@ func main() {
 		err = showSeccompLibraryVersion()
 	case "version-info":
 		err = showVersionInfo()
+	case "syscall-name":
+		if len(os.Args) < 4 {
+			fmt.Println("syscall-name needs <arch> and <number>")
+			os.Exit(1)
+		}
+		err = showSyscallName(os.Args[2], os.Args[3])
 	case "dump":
 		if len(os.Args) < 4 {
 			fmt.Println("dump needs <file> and <prefix>")
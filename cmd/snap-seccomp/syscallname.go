@@ -0,0 +1,47 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/seccomp/libseccomp-golang"
+)
+
+// showSyscallName prints the name of the system call with the given number
+// on the given architecture, as named by libseccomp (eg. amd64, x86, arm64).
+func showSyscallName(archName, number string) error {
+	arch, err := seccomp.GetArchFromString(archName)
+	if err != nil {
+		return fmt.Errorf("cannot use architecture %q: %v", archName, err)
+	}
+	nr, err := strconv.Atoi(number)
+	if err != nil {
+		return fmt.Errorf("cannot parse system call number %q: %v", number, err)
+	}
+	name, err := seccomp.ScmpSyscall(nr).GetNameByArch(arch)
+	if err != nil {
+		return fmt.Errorf("cannot resolve system call %d on %s: %v", nr, archName, err)
+	}
+	fmt.Fprintln(os.Stdout, name)
+	return nil
+}
@@ -31,7 +31,8 @@ import (
 
 type cmdAck struct {
 	clientMixin
-	AckOptions struct {
+	LocalAuthority bool `long:"local-authority"`
+	AckOptions     struct {
 		AssertionFile flags.Filename
 	} `positional-args:"true" required:"true"`
 }
@@ -46,12 +47,21 @@ will replace.
 To succeed the assertion must be valid, its signature verified with a known
 public key and the assertion consistent with and its prerequisite in the
 database.
+
+With --local-authority, the file must instead hold an account and an
+account-key assertion, both signed with the key of the account-key, as made
+with 'snap sign'. The account becomes a local authority, which can only sign
+snap-declaration and snap-revision assertions. Locally built snaps can then be
+installed without --dangerous once such assertions for them are acknowledged.
 `)
 
 func init() {
 	addCommand("ack", shortAckHelp, longAckHelp, func() flags.Commander {
 		return &cmdAck{}
-	}, nil, []argDesc{{
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"local-authority": i18n.G("Register the account in the file as a local authority"),
+	}, []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<assertion file>"),
 		// TRANSLATORS: This should not start with a lowercase letter.
@@ -72,6 +82,16 @@ func (x *cmdAck) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
 	}
+	if x.LocalAuthority {
+		assertData, err := os.ReadFile(string(x.AckOptions.AssertionFile))
+		if err != nil {
+			return fmt.Errorf("cannot add local authority: %v", err)
+		}
+		if err := x.client.AckLocalAuthority(assertData); err != nil {
+			return fmt.Errorf("cannot add local authority: %v", err)
+		}
+		return nil
+	}
 	if err := ackFile(x.client, string(x.AckOptions.AssertionFile)); err != nil {
 		return fmt.Errorf("cannot assert: %v", err)
 	}
@@ -0,0 +1,225 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdCluster struct{}
+
+var shortClusterHelp = i18n.G("Manage the cluster this device is part of")
+var longClusterHelp = i18n.G(`
+The cluster command contains sub-commands to assemble this device into a
+cluster of devices and to inspect that cluster.
+`)
+
+type cmdClusterStatus struct {
+	clientMixin
+	timeMixin
+}
+
+var shortClusterStatusHelp = i18n.G("Show the convergence status of the cluster")
+var longClusterStatusHelp = i18n.G(`
+The status command shows, for every device of the cluster, whether it has
+applied the current cluster assertion, when it last did, the snaps it runs
+because of the assertion and the changes that failed to apply it.
+
+The status of the other devices is the one they last reported to this device.
+`)
+
+type cmdClusterAssemble struct {
+	waitMixin
+	Address      string   `long:"address" required:"true"`
+	ExpectedSize int      `long:"expected-size" required:"true"`
+	Peers        []string `long:"peer"`
+}
+
+var shortClusterAssembleHelp = i18n.G("Assemble this device into a cluster")
+var longClusterAssembleHelp = i18n.G(`
+The assemble command makes this device take part in an assembly session with
+the other devices of the cluster. The devices authenticate each other with a
+secret they share, which is read from standard input.
+
+The session ends once the expected number of devices found each other. The
+devices are then trusted as the peers of this device.
+
+The other devices are discovered over mDNS unless their addresses are given
+with --peer.
+`)
+
+func init() {
+	addClusterCommand("assemble", shortClusterAssembleHelp, longClusterAssembleHelp, func() flags.Commander {
+		return &cmdClusterAssemble{}
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"address": i18n.G("Address and port at which this device receives messages"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"expected-size": i18n.G("Number of devices taking part in the session"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"peer": i18n.G("Address and port of another device taking part in the session"),
+	}), nil)
+}
+
+func (x *cmdClusterAssemble) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	secret, err := io.ReadAll(Stdin)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot read the cluster secret: %v"), err)
+	}
+	if len(strings.TrimSpace(string(secret))) == 0 {
+		return errors.New(i18n.G("cannot assemble cluster without a secret on standard input"))
+	}
+
+	changeID, err := x.client.ClusterAssemble(client.ClusterAssembleOptions{
+		Secret:       strings.TrimSpace(string(secret)),
+		Address:      x.Address,
+		ExpectedSize: x.ExpectedSize,
+		Peers:        x.Peers,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintln(Stdout, i18n.G("Cluster assembled"))
+	return nil
+}
+
+func init() {
+	addClusterCommand("status", shortClusterStatusHelp, longClusterStatusHelp, func() flags.Commander {
+		return &cmdClusterStatus{}
+	}, timeDescs, nil)
+}
+
+func (x *cmdClusterStatus) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	status, err := x.client.ClusterStatus()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Stdout, i18n.G("Cluster %s at sequence %d\n\n"), status.ClusterID, status.Sequence)
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("ID\tSerial\tModel\tSubclusters\tConverged\tLast-converged\tSnaps\tNotes"))
+	var failed []string
+	for _, dev := range status.Devices {
+		converged, lastConverged, snaps := "-", "-", "-"
+		var notes []string
+		if dev.Local {
+			notes = append(notes, i18n.G("local"))
+		}
+
+		if rep := dev.Report; rep != nil {
+			converged = i18n.G("no")
+			if rep.Converged && rep.Sequence == status.Sequence {
+				converged = i18n.G("yes")
+			}
+			if !rep.LastConverged.IsZero() {
+				lastConverged = x.fmtTime(rep.LastConverged)
+			}
+			snaps = fmtReportedSnaps(rep.Snaps)
+			if rep.Sequence != status.Sequence {
+				notes = append(notes, fmt.Sprintf(i18n.G("sequence %d"), rep.Sequence))
+			}
+			notes = append(notes, fmtRollout(rep.Rollout)...)
+			if len(rep.FailedChanges) > 0 {
+				notes = append(notes, fmt.Sprintf(i18n.NG("%d failed change", "%d failed changes", len(rep.FailedChanges)), len(rep.FailedChanges)))
+			}
+			for _, chg := range rep.FailedChanges {
+				failed = append(failed, fmt.Sprintf("%s\t%s\t%s\t%s\t%s", dev.Serial, chg.ID, x.fmtTime(chg.Time), chg.Summary, chg.Error))
+			}
+		} else {
+			notes = append(notes, i18n.G("no report"))
+		}
+
+		subclusters := "-"
+		if len(dev.Subclusters) > 0 {
+			subclusters = strings.Join(dev.Subclusters, ",")
+		}
+		note := "-"
+		if len(notes) > 0 {
+			note = strings.Join(notes, ",")
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", dev.ID, dev.Serial, dev.Model, subclusters, converged, lastConverged, snaps, note)
+	}
+	w.Flush()
+
+	if len(failed) > 0 {
+		fmt.Fprintln(Stdout)
+		w = tabWriter()
+		fmt.Fprintln(w, i18n.G("Serial\tChange\tReady\tSummary\tError"))
+		for _, line := range failed {
+			fmt.Fprintln(w, line)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+func fmtReportedSnaps(snaps []client.ClusterReportedSnap) string {
+	if len(snaps) == 0 {
+		return "-"
+	}
+
+	formatted := make([]string, 0, len(snaps))
+	for _, sn := range snaps {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", sn.Instance, sn.Revision))
+	}
+	return strings.Join(formatted, ",")
+}
+
+func fmtRollout(rollout map[string]string) []string {
+	names := make([]string, 0, len(rollout))
+	for name := range rollout {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		formatted = append(formatted, fmt.Sprintf("%s:%s", name, rollout[name]))
+	}
+	return formatted
+}
@@ -0,0 +1,161 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const clusterStatusJSON = `{
+	"type": "sync",
+	"status-code": 200,
+	"result": {
+		"cluster-id": "my-cluster",
+		"sequence": 3,
+		"devices": [
+			{
+				"id": 1,
+				"brand-id": "my-brand",
+				"model": "my-model",
+				"serial": "serial-1",
+				"subclusters": ["default", "extra"],
+				"local": true,
+				"report": {
+					"sequence": 3,
+					"snaps": [{"instance": "some-snap", "revision": "3"}, {"instance": "other-snap", "revision": "7"}],
+					"converged": true,
+					"last-converged": "2026-01-02T03:04:05Z",
+					"time": "2026-01-02T03:04:05Z"
+				}
+			},
+			{
+				"id": 2,
+				"brand-id": "my-brand",
+				"model": "my-model",
+				"serial": "serial-2",
+				"subclusters": ["default"],
+				"report": {
+					"sequence": 2,
+					"failed-changes": [{"id": "12", "kind": "apply-cluster-subcluster", "summary": "Apply subcluster default", "error": "cannot install", "time": "2026-01-02T01:00:00Z"}],
+					"converged": false,
+					"rollout": {"default": "failed"},
+					"time": "2026-01-02T03:00:00Z"
+				}
+			},
+			{
+				"id": 3,
+				"brand-id": "my-brand",
+				"model": "my-model",
+				"serial": "serial-3"
+			}
+		]
+	}
+}`
+
+func (s *SnapSuite) TestClusterStatus(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/cluster/status")
+		fmt.Fprintln(w, clusterStatusJSON)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `Cluster my-cluster at sequence 3
+
+ID   Serial    Model     Subclusters    Converged  Last-converged        Snaps                     Notes
+1    serial-1  my-model  default,extra  yes        2026-01-02T03:04:05Z  some-snap=3,other-snap=7  local
+2    serial-2  my-model  default        no         -                     -                         sequence 2,default:failed,1 failed change
+3    serial-3  my-model  -              -          -                     -                         no report
+
+Serial    Change  Ready                 Summary                   Error
+serial-2  12      2026-01-02T01:00:00Z  Apply subcluster default  cannot install
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterStatusError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 404, "result": {"message": "this device is not part of a cluster"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status"})
+	c.Check(err, ErrorMatches, "this device is not part of a cluster")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "status", "extra"})
+	c.Check(err, Equals, snap.ErrExtraArgs)
+}
+
+func (s *SnapSuite) TestClusterAssemble(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/cluster")
+			var data map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&data), IsNil)
+			c.Check(data, DeepEquals, map[string]any{
+				"action":        "assemble",
+				"secret":        "my secret",
+				"address":       "10.0.0.1:8001",
+				"expected-size": float64(2),
+				"peers":         []any{"10.0.0.2:8001"},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		case 2:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected request %d", n)
+		}
+	})
+
+	s.stdin.WriteString("my secret\n")
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--address", "10.0.0.1:8001", "--expected-size", "2", "--peer", "10.0.0.2:8001"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(n, Equals, 2)
+	c.Check(s.Stdout(), Equals, "Cluster assembled\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestClusterAssembleNoSecret(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"cluster", "assemble", "--address", "10.0.0.1:8001", "--expected-size", "2"})
+	c.Check(err, ErrorMatches, "cannot assemble cluster without a secret on standard input")
+}
@@ -0,0 +1,243 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdConfdb struct{}
+
+var shortConfdbHelp = i18n.G("Manage confdbs")
+var longConfdbHelp = i18n.G(`
+The confdb command contains sub-commands to manage the data stored in confdbs,
+as opposed to reading and writing it through views with 'snap get' and
+'snap set'.
+`)
+
+type cmdConfdbRollback struct {
+	waitMixin
+	Positional struct {
+		Schema string `required:"yes"`
+		TxID   string `required:"yes"`
+	} `positional-args:"yes"`
+}
+
+var shortConfdbRollbackHelp = i18n.G("Revert changes made to a confdb")
+var longConfdbRollbackHelp = i18n.G(`
+The rollback command reverts the changes made to a confdb by the transaction
+with the given ID and by all the transactions committed after it. The IDs of
+the transactions can be found with 'snap get --history'.
+
+The reverted data is written through the affected views, so the snaps
+managing the confdb can validate and react to the changes like for any other
+write.
+`)
+
+type cmdConfdbExport struct {
+	clientMixin
+	Positional struct {
+		Schema string `required:"yes"`
+	} `positional-args:"yes"`
+}
+
+var shortConfdbExportHelp = i18n.G("Export the data stored in a confdb")
+var longConfdbExportHelp = i18n.G(`
+The export command prints all the data stored in a confdb as a JSON document,
+which can be loaded into a confdb with 'snap confdb import'. Secret values are
+encrypted with a key that never leaves the device, so they can only be imported
+back into the same device.
+`)
+
+type cmdConfdbImport struct {
+	waitMixin
+	Positional struct {
+		Schema string `required:"yes"`
+		File   string `required:"yes"`
+	} `positional-args:"yes"`
+}
+
+var shortConfdbImportHelp = i18n.G("Import data into a confdb")
+var longConfdbImportHelp = i18n.G(`
+The import command replaces all the data stored in a confdb with the JSON
+document in the given file, or read from the standard input if the file is
+"-". The data must conform to the confdb-schema.
+
+The data is written through the views affected by the changes, so the snaps
+managing the confdb can validate and react to them like for any other write.
+`)
+
+var confdbSchemaArgDesc = argDesc{
+	// TRANSLATORS: This needs to begin with < and end with >
+	name: i18n.G("<confdb-schema>"),
+	// TRANSLATORS: This should not start with a lowercase letter.
+	desc: i18n.G("Confdb schema in the format <account-id>/<confdb-schema>"),
+}
+
+func init() {
+	addConfdbCommand("export", shortConfdbExportHelp, longConfdbExportHelp, func() flags.Commander {
+		return &cmdConfdbExport{}
+	}, nil, []argDesc{confdbSchemaArgDesc})
+	addConfdbCommand("import", shortConfdbImportHelp, longConfdbImportHelp, func() flags.Commander {
+		return &cmdConfdbImport{}
+	}, waitDescs, []argDesc{
+		confdbSchemaArgDesc,
+		{
+			// TRANSLATORS: This needs to begin with < and end with >
+			name: i18n.G("<file>"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			desc: i18n.G("JSON file with the data to import, or - for the standard input"),
+		},
+	})
+	addConfdbCommand("rollback", shortConfdbRollbackHelp, longConfdbRollbackHelp, func() flags.Commander {
+		return &cmdConfdbRollback{}
+	}, waitDescs, []argDesc{
+		confdbSchemaArgDesc,
+		{
+			// TRANSLATORS: This needs to begin with < and end with >
+			name: i18n.G("<transaction-id>"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			desc: i18n.G("ID of the first transaction to revert"),
+		},
+	})
+}
+
+func validateConfdbSchemaID(id string) error {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.New(i18n.G("confdb-schema id must conform to format: <account-id>/<confdb-schema>"))
+	}
+	return nil
+}
+
+func (x *cmdConfdbRollback) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if err := validateConfdbFeatureFlag(); err != nil {
+		return err
+	}
+
+	if err := validateConfdbSchemaID(x.Positional.Schema); err != nil {
+		return err
+	}
+
+	txID, err := strconv.Atoi(x.Positional.TxID)
+	if err != nil || txID <= 0 {
+		return fmt.Errorf(i18n.G("invalid transaction ID %q"), x.Positional.TxID)
+	}
+
+	chgID, err := x.client.ConfdbRollback(x.Positional.Schema, txID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(chgID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintf(Stdout, i18n.G("Reverted confdb %s to before transaction %d\n"), x.Positional.Schema, txID)
+	return nil
+}
+
+func (x *cmdConfdbExport) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if err := validateConfdbFeatureFlag(); err != nil {
+		return err
+	}
+
+	if err := validateConfdbSchemaID(x.Positional.Schema); err != nil {
+		return err
+	}
+
+	data, err := x.client.ConfdbExport(x.Positional.Schema)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(Stdout, string(bytes))
+	return nil
+}
+
+func (x *cmdConfdbImport) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if err := validateConfdbFeatureFlag(); err != nil {
+		return err
+	}
+
+	if err := validateConfdbSchemaID(x.Positional.Schema); err != nil {
+		return err
+	}
+
+	var r io.Reader = Stdin
+	if x.Positional.File != "-" {
+		f, err := os.Open(x.Positional.File)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var data map[string]any
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf(i18n.G("cannot parse data to import: %v"), err)
+	}
+
+	chgID, err := x.client.ConfdbImport(x.Positional.Schema, data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(chgID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintf(Stdout, i18n.G("Imported data into confdb %s\n"), x.Positional.Schema)
+	return nil
+}
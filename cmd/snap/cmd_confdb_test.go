@@ -0,0 +1,207 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *confdbSuite) TestConfdbRollback(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	var reqs int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch reqs {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/confdb/foo/bar")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body, DeepEquals, map[string]any{"action": "rollback", "transaction-id": float64(3)})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected request %d", reqs)
+		}
+		reqs++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"confdb", "rollback", "foo/bar", "3"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(reqs, Equals, 2)
+	c.Check(s.Stdout(), Equals, "Reverted confdb foo/bar to before transaction 3\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *confdbSuite) TestConfdbRollbackNoWait(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/confdb/foo/bar")
+		w.WriteHeader(202)
+		fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"confdb", "rollback", "--no-wait", "foo/bar", "3"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "42\n")
+}
+
+func (s *confdbSuite) TestConfdbRollbackErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Error("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"confdb", "rollback", "foo/bar", "3"})
+	c.Check(err, ErrorMatches, `the "confdb" feature is disabled: set 'experimental.confdb' to true`)
+
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	for _, tc := range []struct {
+		args   []string
+		errMsg string
+	}{
+		{[]string{"confdb", "rollback", "foo/bar/baz", "3"}, "confdb-schema id must conform to format: <account-id>/<confdb-schema>"},
+		{[]string{"confdb", "rollback", "foo/", "3"}, "confdb-schema id must conform to format: <account-id>/<confdb-schema>"},
+		{[]string{"confdb", "rollback", "foo/bar", "three"}, `invalid transaction ID "three"`},
+		{[]string{"confdb", "rollback", "foo/bar", "0"}, `invalid transaction ID "0"`},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(tc.args)
+		c.Check(err, ErrorMatches, tc.errMsg, Commentf("%v", tc.args))
+	}
+}
+
+func (s *confdbSuite) TestConfdbExport(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/confdb/foo/bar")
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"wifi": {"ssid": "foo"}}}`)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"confdb", "export", "foo/bar"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `{
+	"wifi": {
+		"ssid": "foo"
+	}
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *confdbSuite) testConfdbImport(c *C, args []string) {
+	var reqs int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch reqs {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/confdb/foo/bar")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body, DeepEquals, map[string]any{
+				"action": "import",
+				"data":   map[string]any{"wifi": map[string]any{"ssid": "foo"}},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected request %d", reqs)
+		}
+		reqs++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs(args)
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(reqs, Equals, 2)
+	c.Check(s.Stdout(), Equals, "Imported data into confdb foo/bar\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *confdbSuite) TestConfdbImportFile(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	path := filepath.Join(c.MkDir(), "data.json")
+	c.Assert(os.WriteFile(path, []byte(`{"wifi": {"ssid": "foo"}}`), 0644), IsNil)
+
+	s.testConfdbImport(c, []string{"confdb", "import", "foo/bar", path})
+}
+
+func (s *confdbSuite) TestConfdbImportStdin(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	s.stdin.WriteString(`{"wifi": {"ssid": "foo"}}`)
+
+	s.testConfdbImport(c, []string{"confdb", "import", "foo/bar", "-"})
+}
+
+func (s *confdbSuite) TestConfdbImportExportErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Error("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"confdb", "export", "foo/bar"})
+	c.Check(err, ErrorMatches, `the "confdb" feature is disabled: set 'experimental.confdb' to true`)
+
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	badFile := filepath.Join(c.MkDir(), "data.json")
+	c.Assert(os.WriteFile(badFile, []byte(`["foo"]`), 0644), IsNil)
+
+	for _, tc := range []struct {
+		args   []string
+		errMsg string
+	}{
+		{[]string{"confdb", "export", "foo"}, "confdb-schema id must conform to format: <account-id>/<confdb-schema>"},
+		{[]string{"confdb", "import", "foo/bar/baz", "-"}, "confdb-schema id must conform to format: <account-id>/<confdb-schema>"},
+		{[]string{"confdb", "import", "foo/bar", badFile}, "cannot parse data to import: .*"},
+		{[]string{"confdb", "import", "foo/bar", "/does/not/exist"}, ".*no such file or directory"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(tc.args)
+		c.Check(err, ErrorMatches, tc.errMsg, Commentf("%v", tc.args))
+	}
+}
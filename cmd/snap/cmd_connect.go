@@ -20,13 +20,20 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
 type cmdConnect struct {
 	waitMixin
+	For         string `long:"for"`
+	Until       string `long:"until"`
 	Positionals struct {
 		PlugSpec connectPlugSpec `required:"yes"`
 		SlotSpec connectSlotSpec
@@ -52,12 +59,22 @@ $ snap connect <snap>:<plug>
 
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
+
+With --for or --until the connection is time-limited: once the given
+duration has passed, or the given time (in RFC3339 format) is reached, the
+connection is removed automatically. The removal survives reboots and is
+recorded as a change and an interface-connection-expired notice.
 `)
 
 func init() {
 	addCommand("connect", shortConnectHelp, longConnectHelp, func() flags.Commander {
 		return &cmdConnect{}
-	}, waitDescs, []argDesc{
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"for": i18n.G("Remove the connection automatically after the given duration (e.g. 1h)"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"until": i18n.G("Remove the connection automatically at the given time (RFC3339)"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -77,7 +94,13 @@ func (x *cmdConnect) Execute(args []string) error {
 		x.Positionals.PlugSpec.Snap = ""
 	}
 
-	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	expiry, err := x.expiry()
+	if err != nil {
+		return err
+	}
+	opts := &client.ConnectOptions{Expiry: expiry}
+
+	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name, opts)
 	if err != nil {
 		return err
 	}
@@ -91,3 +114,31 @@ func (x *cmdConnect) Execute(args []string) error {
 
 	return nil
 }
+
+// expiry returns the time at which the connection should be removed, as
+// requested with --for or --until, or the zero time if neither was given.
+func (x *cmdConnect) expiry() (time.Time, error) {
+	switch {
+	case x.For != "" && x.Until != "":
+		return time.Time{}, errors.New(i18n.G("cannot use --for and --until together"))
+	case x.For != "":
+		dur, err := time.ParseDuration(x.For)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(i18n.G("invalid duration for --for: %v"), err)
+		}
+		if dur < time.Second {
+			return time.Time{}, fmt.Errorf(i18n.G("cannot connect for less than a second: %s"), x.For)
+		}
+		return timeNow().Add(dur), nil
+	case x.Until != "":
+		until, err := time.Parse(time.RFC3339, x.Until)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(i18n.G("invalid time for --until: %v"), err)
+		}
+		if !until.After(timeNow()) {
+			return time.Time{}, fmt.Errorf(i18n.G("cannot connect until a time in the past: %s"), x.Until)
+		}
+		return until, nil
+	}
+	return time.Time{}, nil
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	. "gopkg.in/check.v1"
@@ -53,9 +54,18 @@ $ snap connect <snap>:<plug>
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
 
+With --for or --until the connection is time-limited: once the given
+duration has passed, or the given time (in RFC3339 format) is reached, the
+connection is removed automatically. The removal survives reboots and is
+recorded as a change and an interface-connection-expired notice.
+
 [connect command options]
       --no-wait          Do not wait for the operation to finish but just print
                          the change id.
+      --for=             Remove the connection automatically after the given
+                         duration (e.g. 1h)
+      --until=           Remove the connection automatically at the given time
+                         (RFC3339)
 `
 	s.testSubCommandHelp(c, "connect", msg)
 }
@@ -94,6 +104,80 @@ func (s *SnapSuite) TestConnectExplicitEverything(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) testConnectWithExpiry(c *C, args []string, expiry string) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]any{
+				"action": "connect",
+				"expiry": expiry,
+				"plugs": []any{
+					map[string]any{
+						"snap": "support-tool",
+						"plug": "log-observe",
+					},
+				},
+				"slots": []any{
+					map[string]any{
+						"snap": "",
+						"slot": "",
+					},
+				},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs(append([]string{"connect"}, args...))
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+}
+
+func (s *SnapSuite) TestConnectFor(c *C) {
+	restore := MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	s.testConnectWithExpiry(c, []string{"--for", "1h", "support-tool:log-observe"}, "2026-10-19T13:00:00Z")
+}
+
+func (s *SnapSuite) TestConnectUntil(c *C) {
+	restore := MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	s.testConnectWithExpiry(c, []string{"--until", "2026-10-19T14:30:00Z", "support-tool:log-observe"}, "2026-10-19T14:30:00Z")
+}
+
+func (s *SnapSuite) TestConnectExpiryErrors(c *C) {
+	restore := MockTimeNow(func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--for", "1h", "--until", "2026-10-19T14:30:00Z"}, "cannot use --for and --until together"},
+		{[]string{"--for", "soon"}, `invalid duration for --for: time: invalid duration "soon"`},
+		{[]string{"--for", "10ms"}, "cannot connect for less than a second: 10ms"},
+		{[]string{"--until", "tomorrow"}, `invalid time for --until: .*`},
+		{[]string{"--until", "2026-10-19T11:00:00Z"}, "cannot connect until a time in the past: 2026-10-19T11:00:00Z"},
+	} {
+		_, err := Parser(Client()).ParseArgs(append([]string{"connect"}, append(tc.args, "support-tool:log-observe")...))
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.args))
+	}
+}
+
 func (s *SnapSuite) TestConnectExplicitPlugImplicitSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -34,6 +35,7 @@ import (
 type cmdConnections struct {
 	clientMixin
 	All         bool `long:"all"`
+	Explain     bool `long:"explain"`
 	Positionals struct {
 		Snap installedSnapName
 	} `positional-args:"true"`
@@ -52,6 +54,13 @@ $ snap connections <snap>
 
 Lists connected and unconnected plugs and slots for the specified
 snap.
+
+$ snap connections --explain [<snap>]
+
+Explains which rules of the base-declaration or snap-declarations allow
+or deny connecting and auto-connecting each plug to each slot of the
+same interface, down to the constraint that did not match, and whether
+the slot is an auto-connection candidate for the plug.
 `)
 
 func init() {
@@ -59,6 +68,8 @@ func init() {
 		return &cmdConnections{}
 	}, map[string]string{
 		"all": i18n.G("Show connected and unconnected plugs and slots"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"explain": i18n.G("Explain the policy decisions about connecting plugs and slots"),
 	}, []argDesc{{
 		// TRANSLATORS: This needs to be wrapped in <>s.
 		name: "<snap>",
@@ -85,6 +96,7 @@ type connection struct {
 	interfaceDeterminant string
 	manual               bool
 	gadget               bool
+	expiry               time.Time
 }
 
 func (cn connection) String() string {
@@ -95,6 +107,9 @@ func (cn connection) String() string {
 	if cn.gadget {
 		opts = append(opts, "gadget")
 	}
+	if !cn.expiry.IsZero() {
+		opts = append(opts, "expires="+cn.expiry.Format(time.RFC3339))
+	}
 	if len(opts) == 0 {
 		return "-"
 	}
@@ -147,6 +162,13 @@ func (x *cmdConnections) Execute(args []string) error {
 		All: x.All,
 	}
 	wanted := string(x.Positionals.Snap)
+	if x.Explain {
+		if x.All {
+			return errors.New(i18n.G("cannot use --all with --explain"))
+		}
+		opts.Snap = wanted
+		return x.explain(&opts)
+	}
 	if wanted != "" {
 		if x.All {
 			// passing a snap name already implies --all, error out
@@ -176,6 +198,7 @@ func (x *cmdConnections) Execute(args []string) error {
 			slot:                 endpoint(conn.Slot.Snap, conn.Slot.Name),
 			manual:               conn.Manual,
 			gadget:               conn.Gadget,
+			expiry:               conn.Expiry,
 			interfaceName:        conn.Interface,
 			interfaceDeterminant: interfaceDeterminant(&conn),
 		})
@@ -219,3 +242,62 @@ func (x *cmdConnections) Execute(args []string) error {
 	}
 	return nil
 }
+
+func (x *cmdConnections) explain(opts *client.ConnectionOptions) error {
+	expls, err := x.client.ExplainConnections(opts)
+	if err != nil {
+		return err
+	}
+	if len(expls) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No plugs and slots to explain."))
+		return nil
+	}
+	printPolicyExplanations(expls)
+	return nil
+}
+
+func printPolicyExplanations(expls []client.PolicyExplanation) {
+	for i, expl := range expls {
+		if i > 0 {
+			fmt.Fprintln(Stdout)
+		}
+		fmt.Fprintf(Stdout, i18n.G("%s to %s (interface %q):\n"),
+			endpoint(expl.Plug.Snap, expl.Plug.Name), endpoint(expl.Slot.Snap, expl.Slot.Name), expl.Interface)
+		if expl.Connect != nil {
+			printPolicyDecision(expl.Connect, "connection")
+		}
+		if expl.ConnectUnchecked {
+			fmt.Fprintln(Stdout, i18n.G("  manual connections are not checked against the policy for snaps without snap-declaration"))
+		}
+		if expl.AutoConnect != nil {
+			printPolicyDecision(expl.AutoConnect, "auto-connection")
+		}
+		candidate := i18n.G("no")
+		if expl.AutoConnectCandidate {
+			candidate = i18n.G("yes")
+		}
+		fmt.Fprintf(Stdout, i18n.G("  auto-connection candidate: %s\n"), candidate)
+		for _, note := range expl.Notes {
+			fmt.Fprintf(Stdout, i18n.G("  note: %s\n"), note)
+		}
+	}
+}
+
+func printPolicyDecision(decision *client.PolicyDecision, kind string) {
+	fmt.Fprintf(Stdout, "  %s\n", decision.Message)
+	for _, alts := range []struct {
+		section string
+		alts    []client.PolicyConstraints
+	}{
+		{"deny-" + kind, decision.Deny},
+		{"allow-" + kind, decision.Allow},
+	} {
+		for i, alt := range alts.alts {
+			if alt.Matched {
+				fmt.Fprintf(Stdout, i18n.G("    %s alternative %d: matched\n"), alts.section, i+1)
+			} else {
+				fmt.Fprintf(Stdout, i18n.G("    %s alternative %d: not matched: %s\n"), alts.section, i+1, alt.Mismatch)
+			}
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -278,6 +279,47 @@ func (s *SnapSuite) TestConnectionsSomeConnected(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestConnectionsWithExpiry(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "support-tool", Name: "log-observe"},
+				Slot:      client.SlotRef{Snap: "core", Name: "log-observe"},
+				Interface: "log-observe",
+				Manual:    true,
+				Expiry:    time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:      "support-tool",
+				Name:      "log-observe",
+				Interface: "log-observe",
+				Connections: []client.SlotRef{{
+					Snap: "core",
+					Name: "log-observe",
+				}},
+			},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]any{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connections"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"Interface    Plug                      Slot          Notes\n" +
+		"log-observe  support-tool:log-observe  :log-observe  manual,expires=2026-10-19T13:00:00Z\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestConnectionsSomeDisconnected(c *C) {
 	result := client.Connections{
 		Established: []client.Connection{
@@ -861,3 +903,89 @@ func (s *SnapSuite) testConnectionsDefiningAttribute(c *C, attr string) {
 	c.Assert(s.Stdout(), Equals, expectedStdout)
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConnectionsExplain(c *C) {
+	result := []client.PolicyExplanation{{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      client.SlotRef{Snap: "core", Name: "test"},
+		Interface: "test",
+		Connect: &client.PolicyDecision{
+			Allowed: true,
+			Message: "connection allowed by slot rule of base-declaration",
+			Origin:  "base-declaration",
+			Side:    "slot",
+			Deny:    []client.PolicyConstraints{{Mismatch: "constraint is false"}},
+			Allow:   []client.PolicyConstraints{{Matched: true}},
+		},
+		AutoConnect: &client.PolicyDecision{
+			Message: `auto-connection not allowed by slot rule of interface "test" for "core" snap`,
+			Origin:  "snap-declaration",
+			Snap:    "core",
+			Side:    "slot",
+			Allow: []client.PolicyConstraints{
+				{Mismatch: `attribute "a" value "x" does not match ^(y)$`},
+				{Mismatch: "publisher id does not match"},
+			},
+		},
+	}, {
+		Plug:             client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:             client.SlotRef{Snap: "producer", Name: "slot"},
+		Interface:        "test",
+		Connect:          &client.PolicyDecision{Allowed: true, Message: "connection allowed, no rule applies"},
+		ConnectUnchecked: true,
+		AutoConnect: &client.PolicyDecision{
+			Allowed: true,
+			Message: "auto-connection allowed, no rule applies",
+		},
+		AutoConnectCandidate: true,
+		Notes:                []string{"a note"},
+	}}
+	query := url.Values{
+		"aspect": []string{"interface-policy"},
+		"snap":   []string{"consumer"},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.Query(), DeepEquals, query)
+		EncodeResponseBody(c, w, map[string]any{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connections", "--explain", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `consumer:plug to :test (interface "test"):
+  connection allowed by slot rule of base-declaration
+    deny-connection alternative 1: not matched: constraint is false
+    allow-connection alternative 1: matched
+  auto-connection not allowed by slot rule of interface "test" for "core" snap
+    allow-auto-connection alternative 1: not matched: attribute "a" value "x" does not match ^(y)$
+    allow-auto-connection alternative 2: not matched: publisher id does not match
+  auto-connection candidate: no
+
+consumer:plug to producer:slot (interface "test"):
+  connection allowed, no rule applies
+  manual connections are not checked against the policy for snaps without snap-declaration
+  auto-connection allowed, no rule applies
+  auto-connection candidate: yes
+  note: a note
+`)
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+	result = nil
+	query = url.Values{"aspect": []string{"interface-policy"}}
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--explain"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "No plugs and slots to explain.\n")
+}
+
+func (s *SnapSuite) TestConnectionsExplainWithAll(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--explain", "--all"})
+	c.Assert(err, ErrorMatches, "cannot use --all with --explain")
+}
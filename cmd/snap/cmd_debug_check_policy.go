@@ -0,0 +1,397 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
+)
+
+type cmdDebugCheckPolicy struct {
+	SnapYamls    []flags.Filename `long:"snap-yaml" value-name:"<snap.yaml>" required:"true"`
+	Declarations []flags.Filename `long:"declaration" value-name:"<assertion-file>"`
+	Model        flags.Filename   `long:"model" value-name:"<assertion-file>"`
+	Store        flags.Filename   `long:"store" value-name:"<assertion-file>"`
+}
+
+var shortDebugCheckPolicyHelp = i18n.G("Simulate the interface policy for hypothetical snaps")
+var longDebugCheckPolicyHelp = i18n.G(`
+The check-policy command evaluates the installation, connection and
+auto-connection policy of the base-declaration and the given
+snap-declarations against the snaps described by the given snap.yaml
+files, without installing anything.
+
+Snap-declarations are matched to the snaps by snap name. Snaps without
+a snap-declaration are checked as if installed with --dangerous. If none
+of the snaps is a core or snapd snap, the implicit slots of the system
+are provided by a "snapd" snap. The --model and --store assertions are
+used to evaluate device scope constraints.
+
+Assertion signatures are not verified.
+`)
+
+func init() {
+	addDebugCommand("check-policy", shortDebugCheckPolicyHelp, longDebugCheckPolicyHelp, func() flags.Commander {
+		return &cmdDebugCheckPolicy{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"snap-yaml": i18n.G("Path to the snap.yaml of a hypothetical snap, can be repeated"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"declaration": i18n.G("Path to snap-declaration assertions, can be repeated"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"model": i18n.G("Path to the model assertion of the device"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"store": i18n.G("Path to the store assertion of the device"),
+	}, nil)
+}
+
+func readAssertionsFile(path string) ([]asserts.Assertion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var as []asserts.Assertion
+	dec := asserts.NewDecoder(f)
+	for {
+		a, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode assertion from %q: %v", path, err)
+		}
+		as = append(as, a)
+	}
+	if len(as) == 0 {
+		return nil, fmt.Errorf("no assertions found in %q", path)
+	}
+	return as, nil
+}
+
+func readSingleAssertionFile(path string, assertType *asserts.AssertionType) (asserts.Assertion, error) {
+	as, err := readAssertionsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(as) != 1 || as[0].Type() != assertType {
+		return nil, fmt.Errorf("expected a single %s assertion in %q", assertType.Name, path)
+	}
+	return as[0], nil
+}
+
+// systemSnapWithImplicitSlots returns a snapd snap with the implicit slots
+// of a classic or core system, as set up by the interface manager.
+func systemSnapWithImplicitSlots(classic bool) (*snap.Info, error) {
+	info, err := snap.InfoFromSnapYaml([]byte("name: snapd\ntype: snapd\nversion: 1\n"))
+	if err != nil {
+		return nil, err
+	}
+	for _, iface := range builtin.Interfaces() {
+		si := interfaces.StaticInfoOf(iface)
+		if (classic && si.ImplicitOnClassic) || (!classic && si.ImplicitOnCore) {
+			ifaceName := iface.Name()
+			info.Slots[ifaceName] = &snap.SlotInfo{
+				Name:      ifaceName,
+				Snap:      info,
+				Interface: ifaceName,
+			}
+		}
+	}
+	return info, nil
+}
+
+type policySimulation struct {
+	decls    map[string]*asserts.SnapDeclaration
+	model    *asserts.Model
+	store    *asserts.Store
+	baseDecl *asserts.BaseDeclaration
+	repo     *interfaces.Repository
+	// simulated holds the names of the snaps given by the user
+	simulated map[string]bool
+	// implicitSystemSnap is set if the system snap was not given by
+	// the user
+	implicitSystemSnap *snap.Info
+}
+
+// asserted returns whether the snap has a snap-declaration, the implicit
+// system snap is considered asserted.
+func (sim *policySimulation) asserted(info *snap.Info) bool {
+	return sim.decls[info.SnapName()] != nil || info == sim.implicitSystemSnap
+}
+
+func (x *cmdDebugCheckPolicy) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	// plug/slot sanitization is disabled (no-op) by default at the package
+	// level for "snap" command, here however we want real validation.
+	snap.SanitizePlugsSlots = builtin.SanitizePlugsSlots
+
+	sim := &policySimulation{
+		decls:     make(map[string]*asserts.SnapDeclaration),
+		baseDecl:  asserts.BuiltinBaseDeclaration(),
+		repo:      interfaces.NewRepository(),
+		simulated: make(map[string]bool),
+	}
+	for _, path := range x.Declarations {
+		as, err := readAssertionsFile(string(path))
+		if err != nil {
+			return err
+		}
+		for _, a := range as {
+			decl, ok := a.(*asserts.SnapDeclaration)
+			if !ok {
+				return fmt.Errorf(i18n.G("unexpected %s assertion in %q, expected snap-declaration"), a.Type().Name, path)
+			}
+			sim.decls[decl.SnapName()] = decl
+		}
+	}
+	if x.Model != "" {
+		a, err := readSingleAssertionFile(string(x.Model), asserts.ModelType)
+		if err != nil {
+			return err
+		}
+		sim.model = a.(*asserts.Model)
+	}
+	if x.Store != "" {
+		a, err := readSingleAssertionFile(string(x.Store), asserts.StoreType)
+		if err != nil {
+			return err
+		}
+		sim.store = a.(*asserts.Store)
+	}
+
+	for _, iface := range builtin.Interfaces() {
+		if err := sim.repo.AddInterface(iface); err != nil {
+			return err
+		}
+	}
+
+	var infos []*snap.Info
+	hasSystemSnap := false
+	for _, path := range x.SnapYamls {
+		yamlData, err := os.ReadFile(string(path))
+		if err != nil {
+			return err
+		}
+		info, err := snap.InfoFromSnapYaml(yamlData)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot read snap.yaml from %q: %v"), path, err)
+		}
+		if sim.simulated[info.InstanceName()] {
+			return fmt.Errorf(i18n.G("snap %q given more than once"), info.InstanceName())
+		}
+		if decl := sim.decls[info.SnapName()]; decl != nil {
+			info.SnapID = decl.SnapID()
+		}
+		if typ := info.Type(); typ == snap.TypeOS || typ == snap.TypeSnapd {
+			hasSystemSnap = true
+		}
+		sim.simulated[info.InstanceName()] = true
+		infos = append(infos, info)
+	}
+
+	systemInfos := infos
+	if !hasSystemSnap {
+		// the implicit slots are those of the simulated device, if known
+		classic := release.OnClassic
+		if sim.model != nil {
+			classic = sim.model.Classic()
+		}
+		info, err := systemSnapWithImplicitSlots(classic)
+		if err != nil {
+			return err
+		}
+		sim.implicitSystemSnap = info
+		systemInfos = append(systemInfos, info)
+	}
+	for _, info := range systemInfos {
+		appSet, err := interfaces.NewSnapAppSet(info, nil)
+		if err != nil {
+			return err
+		}
+		if err := sim.repo.AddAppSet(appSet); err != nil {
+			return fmt.Errorf(i18n.G("cannot add snap %q: %v"), info.InstanceName(), err)
+		}
+	}
+
+	fmt.Fprintln(Stdout, i18n.G("Installation:"))
+	for _, info := range infos {
+		sim.printInstallCheck(info)
+	}
+
+	expls := sim.connections()
+	fmt.Fprintln(Stdout)
+	if len(expls) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No plugs and slots to connect."))
+		return nil
+	}
+	fmt.Fprintln(Stdout, i18n.G("Connections:"))
+	printPolicyExplanations(expls)
+	return nil
+}
+
+func (sim *policySimulation) printInstallCheck(info *snap.Info) {
+	var err error
+	if decl := sim.decls[info.SnapName()]; decl != nil {
+		ic := policy.InstallCandidate{
+			Snap:            info,
+			SnapDeclaration: decl,
+			BaseDeclaration: sim.baseDecl,
+			Model:           sim.model,
+			Store:           sim.store,
+		}
+		err = ic.Check()
+	} else {
+		ic := policy.InstallCandidateMinimalCheck{
+			Snap:            info,
+			BaseDeclaration: sim.baseDecl,
+			Model:           sim.model,
+			Store:           sim.store,
+		}
+		err = ic.Check()
+	}
+	switch {
+	case err != nil:
+		fmt.Fprintf(Stdout, i18n.G("  %s: denied: %v\n"), info.InstanceName(), err)
+	case sim.decls[info.SnapName()] == nil:
+		fmt.Fprintf(Stdout, i18n.G("  %s: allowed with --dangerous only (no snap-declaration)\n"), info.InstanceName())
+	default:
+		fmt.Fprintf(Stdout, i18n.G("  %s: allowed\n"), info.InstanceName())
+	}
+	if len(info.BadInterfaces) > 0 {
+		fmt.Fprintf(Stdout, "  %s\n", snap.BadInterfacesSummary(info))
+	}
+}
+
+func (sim *policySimulation) connectCandidate(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) *policy.ConnectCandidate {
+	return &policy.ConnectCandidate{
+		Plug:                plug,
+		PlugSnapDeclaration: sim.decls[plug.Snap().SnapName()],
+		Slot:                slot,
+		SlotSnapDeclaration: sim.decls[slot.Snap().SnapName()],
+		BaseDeclaration:     sim.baseDecl,
+		Model:               sim.model,
+		Store:               sim.store,
+		// the content compatibility labels feature is disabled by default
+		CompatEnabled: plug.Interface() != "content",
+	}
+}
+
+// connections explains connecting each plug to each slot of the same
+// interface where either side belongs to a simulated snap, marking the
+// resulting auto-connection candidates as the interface manager would.
+func (sim *policySimulation) connections() []client.PolicyExplanation {
+	var expls []client.PolicyExplanation
+	for _, plug := range sim.repo.AllPlugs("") {
+		plugSnap := plug.Snap.InstanceName()
+		var plugExpls []client.PolicyExplanation
+		check := func(cplug *interfaces.ConnectedPlug, cslot *interfaces.ConnectedSlot) (bool, interfaces.SideArity, error) {
+			ic := sim.connectCandidate(cplug, cslot)
+			arity, err := ic.CheckAutoConnect()
+			if sim.simulated[plugSnap] || sim.simulated[cslot.Snap().InstanceName()] {
+				plugExpls = append(plugExpls, client.PolicyExplanation{
+					Plug:             client.PlugRef{Snap: plugSnap, Name: cplug.Name()},
+					Slot:             client.SlotRef{Snap: cslot.Snap().InstanceName(), Name: cslot.Name()},
+					Interface:        cplug.Interface(),
+					Connect:          clientPolicyDecision(ic.ExplainConnect()),
+					ConnectUnchecked: !sim.asserted(cplug.Snap()) || !sim.asserted(cslot.Snap()),
+					AutoConnect:      clientPolicyDecision(ic.ExplainAutoConnect()),
+				})
+			}
+			return err == nil, arity, nil
+		}
+		candSlots, arities := sim.repo.AutoConnectCandidateSlots(plugSnap, plug.Name, check)
+		markSimulatedAutoConnectCandidates(plugExpls, candSlots, arities)
+
+		sort.Slice(plugExpls, func(i, j int) bool {
+			if plugExpls[i].Slot.Snap != plugExpls[j].Slot.Snap {
+				return plugExpls[i].Slot.Snap < plugExpls[j].Slot.Snap
+			}
+			return plugExpls[i].Slot.Name < plugExpls[j].Slot.Name
+		})
+		expls = append(expls, plugExpls...)
+	}
+	return expls
+}
+
+func markSimulatedAutoConnectCandidates(expls []client.PolicyExplanation, candSlots []*snap.SlotInfo, arities []interfaces.SideArity) {
+	candidates := make(map[client.SlotRef]bool, len(candSlots))
+	for _, slot := range candSlots {
+		candidates[client.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}] = true
+	}
+	applicable := true
+	for _, arity := range arities {
+		if !arity.SlotsPerPlugAny() {
+			applicable = len(candSlots) == 1
+			break
+		}
+	}
+	for i := range expls {
+		expl := &expls[i]
+		switch {
+		case candidates[expl.Slot] && applicable:
+			expl.AutoConnectCandidate = true
+		case candidates[expl.Slot]:
+			expl.Notes = append(expl.Notes, fmt.Sprintf("%d candidate slots but only one slot per plug allowed", len(candSlots)))
+		case expl.AutoConnect.Allowed:
+			expl.Notes = append(expl.Notes, fmt.Sprintf("auto-connection rejected by the %q interface", expl.Interface))
+		}
+	}
+}
+
+func clientPolicyDecision(expl *policy.Explanation) *client.PolicyDecision {
+	constraints := func(cexpls []policy.ConstraintsExplanation) []client.PolicyConstraints {
+		if len(cexpls) == 0 {
+			return nil
+		}
+		res := make([]client.PolicyConstraints, len(cexpls))
+		for i, cexpl := range cexpls {
+			res[i] = client.PolicyConstraints{Matched: cexpl.Matched, Mismatch: cexpl.Mismatch}
+		}
+		return res
+	}
+	return &client.PolicyDecision{
+		Allowed:         expl.Allowed(),
+		Message:         expl.String(),
+		Origin:          expl.Origin,
+		Snap:            expl.Snap,
+		Side:            expl.Side,
+		Deny:            constraints(expl.Deny),
+		Allow:           constraints(expl.Allow),
+		SlotsPerPlugAny: expl.SlotsPerPlugAny,
+	}
+}
@@ -0,0 +1,232 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/release"
+)
+
+func (s *SnapSuite) writeCheckPolicyFile(c *C, name, content string) string {
+	path := filepath.Join(c.MkDir(), name)
+	c.Assert(os.WriteFile(path, []byte(content), 0644), IsNil)
+	return path
+}
+
+func (s *SnapSuite) checkPolicySnapDecl(c *C, name, publisher string, extra map[string]any) asserts.Assertion {
+	headers := map[string]any{
+		"series":       "16",
+		"snap-name":    name,
+		"publisher-id": publisher,
+		"snap-id":      (name + strings.Repeat("id", 16))[:32],
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}
+	for k, v := range extra {
+		headers[k] = v
+	}
+	decl, err := assertstest.NewStoreStack("canonical", nil).Sign(asserts.SnapDeclarationType, headers, nil, "")
+	c.Assert(err, IsNil)
+	return decl
+}
+
+func (s *SnapSuite) writeCheckPolicyAssertions(c *C, name string, as ...asserts.Assertion) string {
+	var buf bytes.Buffer
+	enc := asserts.NewEncoder(&buf)
+	for _, a := range as {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+	return s.writeCheckPolicyFile(c, name, buf.String())
+}
+
+func (s *SnapSuite) TestDebugCheckPolicy(c *C) {
+	consumer := s.writeCheckPolicyFile(c, "consumer.yaml", `name: consumer
+version: 1
+plugs:
+  shared:
+    interface: content
+    content: foo
+    target: $SNAP/foo
+`)
+	producer := s.writeCheckPolicyFile(c, "producer.yaml", `name: producer
+version: 1
+slots:
+  shared:
+    interface: content
+    content: foo
+    read: [$SNAP/foo]
+  other:
+    interface: content
+    content: bar
+    read: [$SNAP/bar]
+`)
+	decls := s.writeCheckPolicyAssertions(c, "decls.assert",
+		s.checkPolicySnapDecl(c, "consumer", "one-publisher", nil),
+		s.checkPolicySnapDecl(c, "producer", "one-publisher", nil))
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-policy",
+		"--snap-yaml", consumer, "--snap-yaml", producer, "--declaration", decls})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(s.Stdout(), Equals, `Installation:
+  consumer: allowed
+  producer: allowed
+
+Connections:
+consumer:shared to producer:other (interface "content"):
+  connection not allowed by slot rule of interface "content"
+    deny-connection alternative 1: not matched: constraint is false
+    allow-connection alternative 1: not matched: no alternative matches: attribute "content" does not match $SLOT(content): foo != bar
+  auto-connection not allowed by slot rule of interface "content"
+    deny-auto-connection alternative 1: not matched: constraint is false
+    allow-auto-connection alternative 1: not matched: no alternative matches: attribute "content" does not match $SLOT(content): foo != bar
+  auto-connection candidate: no
+
+consumer:shared to producer:shared (interface "content"):
+  connection allowed by slot rule of base-declaration
+    deny-connection alternative 1: not matched: constraint is false
+    allow-connection alternative 1: matched
+  auto-connection allowed by slot rule of base-declaration
+    deny-auto-connection alternative 1: not matched: constraint is false
+    allow-auto-connection alternative 1: matched
+  auto-connection candidate: yes
+`)
+}
+
+func (s *SnapSuite) TestDebugCheckPolicyDangerousAndDenied(c *C) {
+	app := s.writeCheckPolicyFile(c, "app.yaml", `name: app
+version: 1
+plugs:
+  network:
+slots:
+  bad:
+    interface: unknown
+`)
+	denied := s.writeCheckPolicyFile(c, "denied.yaml", `name: denied
+version: 1
+plugs:
+  network:
+`)
+	decls := s.writeCheckPolicyAssertions(c, "decls.assert",
+		s.checkPolicySnapDecl(c, "denied", "one-publisher", map[string]any{
+			"format": "1",
+			"plugs": map[string]any{
+				"network": map[string]any{
+					"allow-installation":    "false",
+					"allow-auto-connection": "false",
+				},
+			},
+		}))
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-policy",
+		"--snap-yaml", app, "--snap-yaml", denied, "--declaration", decls})
+	c.Assert(err, IsNil)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(s.Stdout(), Equals, `Installation:
+  app: allowed with --dangerous only (no snap-declaration)
+  snap "app" has bad plugs or slots: bad (unknown interface "unknown")
+  denied: denied: installation not allowed by "network" plug rule of interface "network" for "denied" snap
+
+Connections:
+app:network to :network (interface "network"):
+  connection allowed by slot rule of base-declaration
+    deny-connection alternative 1: not matched: constraint is false
+    allow-connection alternative 1: matched
+  manual connections are not checked against the policy for snaps without snap-declaration
+  auto-connection allowed by slot rule of base-declaration
+    deny-auto-connection alternative 1: not matched: constraint is false
+    allow-auto-connection alternative 1: matched
+  auto-connection candidate: yes
+
+denied:network to :network (interface "network"):
+  connection allowed by plug rule of snap-declaration for "denied" snap
+    deny-connection alternative 1: not matched: constraint is false
+    allow-connection alternative 1: matched
+  auto-connection not allowed by plug rule of interface "network" for "denied" snap
+    deny-auto-connection alternative 1: not matched: constraint is false
+    allow-auto-connection alternative 1: not matched: constraint is false
+  auto-connection candidate: no
+`)
+}
+
+func (s *SnapSuite) TestDebugCheckPolicyErrors(c *C) {
+	app := s.writeCheckPolicyFile(c, "app.yaml", "name: app\nversion: 1\n")
+	decls := s.writeCheckPolicyAssertions(c, "decls.assert", s.checkPolicySnapDecl(c, "app", "one-publisher", nil))
+	garbage := s.writeCheckPolicyFile(c, "garbage", "garbage")
+
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--declaration", decls}, "the required flag `--snap-yaml' was not specified"},
+		{[]string{"--snap-yaml", app, "--snap-yaml", app}, `snap "app" given more than once`},
+		{[]string{"--snap-yaml", app, "--model", decls}, `expected a single model assertion in ".*/decls.assert"`},
+		{[]string{"--snap-yaml", app, "--declaration", garbage}, `cannot decode assertion from ".*/garbage": .*`},
+		{[]string{"--snap-yaml", garbage}, `(?s)cannot read snap.yaml from ".*/garbage": .*`},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"debug", "check-policy"}, t.args...))
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *SnapSuite) TestDebugCheckPolicyImplicitSlotsFollowModel(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	app := s.writeCheckPolicyFile(c, "app.yaml", `name: app
+version: 1
+plugs:
+  desktop:
+`)
+	model, err := assertstest.NewStoreStack("canonical", nil).Sign(asserts.ModelType, map[string]any{
+		"series":       "16",
+		"brand-id":     "canonical",
+		"model":        "my-model",
+		"architecture": "amd64",
+		"base":         "core22",
+		"gadget":       "pc",
+		"kernel":       "pc-kernel",
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	modelFile := s.writeCheckPolicyAssertions(c, "model.assert", model)
+
+	// the implicit slots of the classic host
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-policy", "--snap-yaml", app})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Matches, `(?s).*app:desktop to :desktop \(interface "desktop"\).*`)
+	s.ResetStdStreams()
+
+	// the implicit slots of the simulated core device
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-policy", "--snap-yaml", app, "--model", modelFile})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Matches, `(?s)Installation:\n  app: .*`)
+	c.Check(s.Stdout(), Not(Matches), `(?s).*to :desktop.*`)
+}
@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdDebugDenials struct {
+	clientMixin
+	timeMixin
+	Positional struct {
+		Snap installedSnapName `required:"yes"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+var shortDebugDenialsHelp = i18n.G("Show AppArmor and seccomp denials of a snap")
+var longDebugDenialsHelp = i18n.G(`
+The denials command shows the AppArmor and seccomp denials recorded for
+the given snap in the audit log or the system journal, grouped by app
+and access.
+
+For each access the interfaces whose AppArmor or seccomp rules would
+allow it are listed. These are only hints: connecting an interface may
+grant much more than the denied access, and an access may be denied on
+purpose.
+`)
+
+func init() {
+	addDebugCommand("denials", shortDebugDenialsHelp, longDebugDenialsHelp, func() flags.Commander {
+		return &cmdDebugDenials{}
+	}, timeDescs, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Snap name"),
+	}})
+}
+
+func (x *cmdDebugDenials) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := string(x.Positional.Snap)
+	denials, err := x.client.Denials(snapName)
+	if err != nil {
+		return err
+	}
+	if len(denials) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No denials found for snap %q.\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("App\tRev\tKind\tCount\tLast seen\tAccess\tInterfaces"))
+	for _, d := range denials {
+		app := d.App
+		if app == "" {
+			app = "-"
+		}
+		ifaces := "-"
+		if len(d.Interfaces) > 0 {
+			ifaces = strings.Join(d.Interfaces, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", app, d.Revision, d.Kind, d.Count, x.fmtTime(d.LastSeen), d.Access, ifaces)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestDebugDenials(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/debug/denials")
+			c.Check(r.URL.Query().Get("snap"), check.Equals, "foo")
+			fmt.Fprintln(w, `{"type": "sync", "result": [
+{"snap": "foo", "app": "app", "revision": "2", "kind": "apparmor", "access": "open /dev/video0 (r)", "count": 3, "first-seen": "2026-10-01T10:00:00Z", "last-seen": "2026-10-01T10:05:00Z", "interfaces": ["camera"]},
+{"snap": "foo", "app": "app", "revision": "2", "kind": "seccomp", "access": "syscall 999 (x86_64)", "count": 1, "first-seen": "2026-10-01T10:06:00Z", "last-seen": "2026-10-01T10:06:00Z"}
+]}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials", "--abs-time", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `App  Rev  Kind      Count  Last seen             Access                Interfaces
+app  2    apparmor  3      2026-10-01T10:05:00Z  open /dev/video0 (r)  camera
+app  2    seccomp   1      2026-10-01T10:06:00Z  syscall 999 (x86_64)  -
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugDenialsNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "No denials found for snap \"foo\".\n")
+}
+
+func (s *SnapSuite) TestDebugDenialsNeedsSnap(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials"})
+	c.Assert(err, check.ErrorMatches, "the required argument `<snap>` was not provided")
+}
@@ -32,8 +32,10 @@ import (
 	"github.com/snapcore/snapd/asserts/sysdb"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/image"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapbundle"
 	"github.com/snapcore/snapd/store/tooling"
 	"github.com/snapcore/snapd/strutil"
 )
@@ -44,10 +46,11 @@ type cmdDownload struct {
 	Basename       string `long:"basename"`
 	TargetDir      string `long:"target-directory"`
 	OnlyComponents bool   `long:"only-components"`
+	Bundle         string `long:"bundle"`
 
 	CohortKey  string `long:"cohort"`
 	Positional struct {
-		Snap remoteSnapName
+		Snaps []remoteSnapName `required:"1"`
 	} `positional-args:"true" required:"true"`
 }
 
@@ -56,6 +59,10 @@ var longDownloadHelp = i18n.G(`
 The download command downloads the given snap, components, and their supporting
 assertions to the current directory with .snap, .comp, and .assert file
 extensions, respectively.
+
+With --bundle, the given snaps, their components and their assertions are
+downloaded into a single bundle file instead, which can be installed on an
+offline device with 'snap install --bundle'.
 `)
 
 func init() {
@@ -72,6 +79,8 @@ func init() {
 		"target-directory": i18n.G("Download to this directory (defaults to the current directory)"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"only-components": i18n.G("Only download the given components, not the snap"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"bundle": i18n.G("Download the given snaps into this bundle file"),
 	}), []argDesc{{
 		name: "<snap[+component...]>",
 		// TRANSLATORS: This should not start with a lowercase letter.
@@ -103,6 +112,34 @@ func printInstallHint(assertPath string, containerPaths []string) {
 }
 
 func downloadDirect(snapName string, components []string, opts tooling.DownloadSnapOptions) error {
+	tsto, err := tooling.NewToolingStore()
+	if err != nil {
+		return err
+	}
+	tsto.Stdout = Stdout
+
+	dl, assertsPath, err := downloadWithAssertions(snapName, components, tsto, opts)
+	if err != nil {
+		return err
+	}
+
+	containerPaths := make([]string, 0, len(components)+1)
+	if !opts.OnlyComponents {
+		containerPaths = append(containerPaths, dl.Path)
+	}
+	for _, c := range dl.Components {
+		containerPaths = append(containerPaths, c.Path)
+	}
+
+	printInstallHint(assertsPath, containerPaths)
+
+	return nil
+}
+
+// downloadWithAssertions downloads the given snap and components, and then
+// their assertions. It returns the downloaded snap and the path of the file
+// with the assertions.
+func downloadWithAssertions(snapName string, components []string, tsto *tooling.ToolingStore, opts tooling.DownloadSnapOptions) (*tooling.DownloadedSnap, string, error) {
 	compRefs := make([]string, 0, len(components))
 	for _, comp := range components {
 		compRefs = append(compRefs, naming.NewComponentRef(snapName, comp).String())
@@ -125,15 +162,9 @@ func downloadDirect(snapName string, components []string, opts tooling.DownloadS
 		}
 	}
 
-	tsto, err := tooling.NewToolingStore()
-	if err != nil {
-		return err
-	}
-	tsto.Stdout = Stdout
-
 	dl, err := downloadContainers(snapName, components, tsto, opts)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	downloaded := make([]string, 0, len(compRefs)+1)
@@ -164,19 +195,68 @@ func downloadDirect(snapName string, components []string, opts tooling.DownloadS
 	}
 
 	assertsPath, err := downloadAssertions(dl.Info, snapPath, compInfos, tsto, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dl, assertsPath, nil
+}
+
+// downloadBundle downloads the given snaps, with their components and
+// assertions, into a bundle at bundlePath.
+func downloadBundle(bundlePath string, names []string, opts tooling.DownloadSnapOptions) error {
+	tsto, err := tooling.NewToolingStore()
 	if err != nil {
 		return err
 	}
+	tsto.Stdout = Stdout
 
-	containerPaths := make([]string, 0, len(components)+1)
-	if !opts.OnlyComponents {
-		containerPaths = append(containerPaths, dl.Path)
+	tmpDir, err := os.MkdirTemp("", "snap-bundle-")
+	if err != nil {
+		return err
 	}
-	for _, c := range dl.Components {
-		containerPaths = append(containerPaths, c.Path)
+	defer os.RemoveAll(tmpDir)
+	opts.TargetDir = tmpDir
+
+	manifest := &snapbundle.Manifest{}
+	for _, name := range names {
+		snapName, comps := snap.SplitSnapInstanceAndComponents(name)
+		dl, assertsPath, err := downloadWithAssertions(snapName, comps, tsto, opts)
+		if err != nil {
+			return err
+		}
+
+		bundled := snapbundle.Snap{
+			Name:       dl.Info.SnapName(),
+			Revision:   dl.Info.Revision,
+			File:       filepath.Base(dl.Path),
+			Assertions: filepath.Base(assertsPath),
+		}
+		for _, c := range dl.Components {
+			bundled.Components = append(bundled.Components, snapbundle.Component{
+				Name: c.Info.Component.ComponentName,
+				File: filepath.Base(c.Path),
+			})
+		}
+		manifest.Snaps = append(manifest.Snaps, bundled)
 	}
 
-	printInstallHint(assertsPath, containerPaths)
+	fmt.Fprintf(Stdout, i18n.G("Writing bundle %q\n"), bundlePath)
+	f, err := osutil.NewAtomicFile(bundlePath, 0644, 0, osutil.NoChown, osutil.NoChown)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot create bundle: %v"), err)
+	}
+	defer f.Cancel()
+	if err := snapbundle.Write(f, manifest, tmpDir); err != nil {
+		return err
+	}
+	if err := f.Commit(); err != nil {
+		return fmt.Errorf(i18n.G("cannot create bundle: %v"), err)
+	}
+
+	fmt.Fprintf(Stdout, i18n.G(`Install the snaps with:
+   snap install --bundle %s
+`), bundlePath)
 
 	return nil
 }
@@ -264,6 +344,23 @@ func (x *cmdDownload) downloadFromStore(snap string, comps []string, revision sn
 	})
 }
 
+func (x *cmdDownload) downloadBundle(names []string) error {
+	if x.Revision != "" {
+		return errors.New(i18n.G("cannot specify revision with --bundle"))
+	}
+	if x.Basename != "" || x.TargetDir != "" {
+		return errors.New(i18n.G("cannot specify basename or target directory with --bundle"))
+	}
+	if x.OnlyComponents {
+		return errors.New(i18n.G("cannot specify --only-components with --bundle"))
+	}
+
+	return downloadBundle(x.Bundle, names, tooling.DownloadSnapOptions{
+		Channel:   x.Channel,
+		CohortKey: x.CohortKey,
+	})
+}
+
 func (x *cmdDownload) Execute(args []string) error {
 	if strings.ContainsRune(x.Basename, filepath.Separator) {
 		return errors.New(i18n.G("cannot specify a path in basename (use --target-dir for that)"))
@@ -276,6 +373,14 @@ func (x *cmdDownload) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 
+	names := remoteSnapNames(x.Positional.Snaps)
+	if x.Bundle != "" {
+		return x.downloadBundle(names)
+	}
+	if len(names) > 1 {
+		return errors.New(i18n.G("cannot download more than one snap without --bundle"))
+	}
+
 	var revision snap.Revision
 	if x.Revision == "" {
 		revision = snap.R(0)
@@ -293,7 +398,7 @@ func (x *cmdDownload) Execute(args []string) error {
 		}
 	}
 
-	snap, comps := snap.SplitSnapInstanceAndComponents(string(x.Positional.Snap))
+	snap, comps := snap.SplitSnapInstanceAndComponents(names[0])
 	if x.OnlyComponents && len(comps) == 0 {
 		return errors.New(i18n.G("cannot specify --only-components without providing any components;"))
 	}
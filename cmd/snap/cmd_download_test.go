@@ -23,13 +23,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/check.v1"
 
 	snapCmd "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapbundle"
 	"github.com/snapcore/snapd/store/tooling"
+	"github.com/snapcore/snapd/testutil"
 )
 
 // these only cover errors that happen before hitting the network,
@@ -222,3 +225,82 @@ func (s *SnapSuite) TestDownloadDirectErrors(c *check.C) {
 	c.Assert(err, check.ErrorMatches, "some-error")
 	c.Check(n, check.Equals, 1)
 }
+
+func (s *SnapSuite) TestDownloadBundle(c *check.C) {
+	var downloaded []string
+	restore := snapCmd.MockDownloadContainers(func(snapName string, components []string, tsto *tooling.ToolingStore, opts tooling.DownloadSnapOptions) (*tooling.DownloadedSnap, error) {
+		c.Check(opts.Channel, check.Equals, "edge")
+		downloaded = append(downloaded, snapName)
+
+		dl := &tooling.DownloadedSnap{
+			Path: filepath.Join(opts.TargetDir, snapName+"_1.snap"),
+			Info: &snap.Info{SideInfo: snap.SideInfo{RealName: snapName, Revision: snap.R(1)}},
+		}
+		c.Assert(os.WriteFile(dl.Path, []byte(snapName), 0644), check.IsNil)
+		for _, comp := range components {
+			dc := &tooling.DownloadedComponent{
+				Path: filepath.Join(opts.TargetDir, snapName+"+"+comp+"_2.comp"),
+				Info: &snap.ComponentInfo{Component: naming.NewComponentRef(snapName, comp)},
+			}
+			c.Assert(os.WriteFile(dc.Path, []byte(comp), 0644), check.IsNil)
+			dl.Components = append(dl.Components, dc)
+		}
+		return dl, nil
+	})
+	defer restore()
+
+	restore = snapCmd.MockDownloadAssertions(func(info *snap.Info, snapPath string, components map[string]*snap.ComponentInfo, tsto *tooling.ToolingStore, opts tooling.DownloadSnapOptions) (string, error) {
+		assertPath := strings.TrimSuffix(snapPath, ".snap") + ".assert"
+		c.Assert(os.WriteFile(assertPath, []byte("assertions"), 0644), check.IsNil)
+		return assertPath, nil
+	})
+	defer restore()
+
+	bundlePath := filepath.Join(c.MkDir(), "update.bundle")
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{
+		"download", "--bundle", bundlePath, "--edge", "a-snap+comp-1", "other-snap"})
+	c.Assert(err, check.IsNil)
+	c.Check(downloaded, check.DeepEquals, []string{"a-snap", "other-snap"})
+	c.Check(s.Stdout(), testutil.Contains, fmt.Sprintf("Install the snaps with:\n   snap install --bundle %s\n", bundlePath))
+
+	f, err := os.Open(bundlePath)
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+	target := c.MkDir()
+	m, paths, err := snapbundle.Extract(f, func(name string) (*os.File, error) {
+		return os.Create(filepath.Join(target, name))
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(m, check.DeepEquals, &snapbundle.Manifest{
+		Snaps: []snapbundle.Snap{{
+			Name:       "a-snap",
+			Revision:   snap.R(1),
+			File:       "a-snap_1.snap",
+			Assertions: "a-snap_1.assert",
+			Components: []snapbundle.Component{{Name: "comp-1", File: "a-snap+comp-1_2.comp"}},
+		}, {
+			Name:       "other-snap",
+			Revision:   snap.R(1),
+			File:       "other-snap_1.snap",
+			Assertions: "other-snap_1.assert",
+		}},
+	})
+	c.Check(paths["a-snap+comp-1_2.comp"], testutil.FileEquals, "comp-1")
+	c.Check(paths["other-snap_1.snap"], testutil.FileEquals, "other-snap")
+}
+
+func (s *SnapSuite) TestDownloadBundleErrors(c *check.C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"a-snap", "other-snap"}, `cannot download more than one snap without --bundle`},
+		{[]string{"--bundle", "x.bundle", "--revision", "1", "a-snap"}, `cannot specify revision with --bundle`},
+		{[]string{"--bundle", "x.bundle", "--basename", "foo", "a-snap"}, `cannot specify basename or target directory with --bundle`},
+		{[]string{"--bundle", "x.bundle", "--target-directory", "foo", "a-snap"}, `cannot specify basename or target directory with --bundle`},
+		{[]string{"--bundle", "x.bundle", "--only-components", "a-snap+comp"}, `cannot specify --only-components with --bundle`},
+	} {
+		_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs(append([]string{"download"}, t.args...))
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -59,6 +60,8 @@ If the first argument passed into get is a confdb identifier matching the
 format <account-id>/<schema>/<view>, get will use the confdb API. In this
 case, the command returns the data retrieved from the requested view paths.
 Use --default to provide a default value to be used if no value is stored.
+Use --history to list the recent transactions which changed the data visible
+through the view.
 `)
 
 type cmdGet struct {
@@ -72,6 +75,7 @@ type cmdGet struct {
 	Document bool   `short:"d"`
 	List     bool   `short:"l"`
 	Default  string `long:"default" unquote:"false"`
+	History  bool   `long:"history"`
 }
 
 func init() {
@@ -93,6 +97,8 @@ func init() {
 			"t": i18n.G("Strict typing with nulls and quoted strings"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"default": i18n.G("A strictly typed default value to be used when none is found"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"history": i18n.G("List the recent changes to a confdb view"),
 		}, []argDesc{
 			{
 				name: "<snap>",
@@ -264,6 +270,16 @@ func (x *cmdGet) Execute(args []string) error {
 	snapName := string(x.Positional.Snap)
 	confKeys := x.Positional.Keys
 
+	if x.History {
+		if !isConfdbViewID(snapName) {
+			return fmt.Errorf("cannot use --history in non-confdb read")
+		}
+		if len(confKeys) > 0 || x.Default != "" || x.Document || x.List || x.Typed {
+			return fmt.Errorf("cannot use --history with keys or other options")
+		}
+		return x.showConfdbHistory(snapName)
+	}
+
 	var conf map[string]any
 	var err error
 	if isConfdbViewID(snapName) {
@@ -365,6 +381,57 @@ func (x *cmdGet) buildDefaultOutput(request string) (map[string]any, error) {
 	return map[string]any{request: defaultVal}, nil
 }
 
+func (x *cmdGet) showConfdbHistory(confdbViewID string) error {
+	if err := validateConfdbFeatureFlag(); err != nil {
+		return err
+	}
+
+	if err := validateConfdbViewID(confdbViewID); err != nil {
+		return err
+	}
+
+	history, err := x.client.ConfdbHistory(confdbViewID)
+	if err != nil {
+		return err
+	}
+
+	if len(history) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No changes to confdb view %s.\n"), confdbViewID)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("ID\tTime\tAuthor\tChange\tPath\tOld\tNew"))
+	for _, entry := range history {
+		author := entry.Author
+		if author == "" {
+			author = "-"
+		}
+		changeID := entry.ChangeID
+		if changeID == "" {
+			changeID = "-"
+		}
+		for _, delta := range entry.Diff {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.Time.Format(time.RFC3339), author,
+				changeID, delta.Path, fmtConfdbHistoryValue(delta.Old), fmtConfdbHistoryValue(delta.New))
+		}
+	}
+	return nil
+}
+
+func fmtConfdbHistoryValue(v any) string {
+	if v == nil {
+		return "-"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
 func validateConfdbFeatureFlag() error {
 	if !features.Confdb.IsEnabled() {
 		_, confName := features.Confdb.ConfigOption()
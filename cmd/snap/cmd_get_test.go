@@ -815,3 +815,68 @@ func (s *confdbSuite) TestForbidDefaultWithNonConfdbGet(c *check.C) {
 	c.Check(s.Stdout(), Equals, "")
 	c.Check(s.Stderr(), Equals, "")
 }
+
+func (s *confdbSuite) TestConfdbGetHistory(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	var reqs int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		reqs++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/confdb/foo/bar/baz")
+		c.Check(r.URL.Query().Get("history"), Equals, "true")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+			{"id": 1, "time": "2026-10-18T12:00:00Z", "author": "jane", "change-id": "12", "diff": [{"path": "wifi.ssid", "new": "foo"}, {"path": "wifi.psk", "new": {"a": 1}}]},
+			{"id": 2, "time": "2026-10-18T12:05:00Z", "diff": [{"path": "wifi.ssid", "old": "foo"}]}
+		]}`)
+	})
+
+	rest, err := snapset.Parser(snapset.Client()).ParseArgs([]string{"get", "--history", "foo/bar/baz"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(reqs, Equals, 1)
+	c.Check(s.Stdout(), Equals, `
+ID   Time                  Author  Change  Path       Old    New
+1    2026-10-18T12:00:00Z  jane    12      wifi.ssid  -      "foo"
+1    2026-10-18T12:00:00Z  jane    12      wifi.psk   -      {"a":1}
+2    2026-10-18T12:05:00Z  -       -       wifi.ssid  "foo"  -
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *confdbSuite) TestConfdbGetHistoryEmpty(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+
+	_, err := snapset.Parser(snapset.Client()).ParseArgs([]string{"get", "--history", "foo/bar/baz"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No changes to confdb view foo/bar/baz.\n")
+}
+
+func (s *confdbSuite) TestConfdbGetHistoryErrors(c *C) {
+	restore := s.mockConfdbFlag(c)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Error("unexpected request")
+	})
+
+	for _, tc := range []struct {
+		args   []string
+		errMsg string
+	}{
+		{[]string{"get", "--history", "some-snap"}, "cannot use --history in non-confdb read"},
+		{[]string{"get", "--history", "foo/bar/baz", "ssid"}, "cannot use --history with keys or other options"},
+		{[]string{"get", "--history", "-d", "foo/bar/baz"}, "cannot use --history with keys or other options"},
+		{[]string{"get", "--history", "foo//baz"}, "confdb-schema view id must conform to format: <account-id>/<confdb-schema>/<view>"},
+	} {
+		_, err := snapset.Parser(snapset.Client()).ParseArgs(tc.args)
+		c.Check(err, ErrorMatches, tc.errMsg, Commentf("%v", tc.args))
+	}
+}
@@ -220,11 +220,12 @@ var helpCategories = []helpCategory{
 	}, {
 		Label:       i18n.G("Permissions"),
 		Description: i18n.G("manage permissions"),
-		Commands:    []string{"connections", "interface", "connect", "disconnect"},
+		Commands:    []string{"connections", "interface", "connect", "disconnect", "prompts"},
 	}, {
-		Label:       i18n.G("Configuration"),
-		Description: i18n.G("system administration and configuration"),
-		Commands:    []string{"get", "set", "unset", "wait"},
+		Label:           i18n.G("Configuration"),
+		Description:     i18n.G("system administration and configuration"),
+		Commands:        []string{"get", "set", "unset", "wait"},
+		AllOnlyCommands: []string{"confdb"},
 	}, {
 		Label:       i18n.G("App Aliases"),
 		Description: i18n.G("manage aliases"),
@@ -243,9 +244,10 @@ var helpCategories = []helpCategory{
 		Description: i18n.G("report issues with a specific snap"),
 		Commands:    []string{"report-issue"},
 	}, {
-		Label:       i18n.G("Device"),
-		Description: i18n.G("manage device"),
-		Commands:    []string{"model", "remodel", "reboot", "recovery"},
+		Label:           i18n.G("Device"),
+		Description:     i18n.G("manage device"),
+		Commands:        []string{"model", "remodel", "reboot", "recovery"},
+		AllOnlyCommands: []string{"cluster", "sbom"},
 	}, {
 		Label:       i18n.G("Warnings"),
 		Other:       true,
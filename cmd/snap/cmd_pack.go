@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/xerrors"
@@ -31,14 +32,17 @@ import (
 
 	// for SanitizePlugsSlots
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/pack"
+	"github.com/snapcore/snapd/snap/snapdelta"
 )
 
 type packCmd struct {
 	CheckSkeleton bool   `long:"check-skeleton"`
 	Filename      string `long:"filename"`
 	Compression   string `long:"compression"`
+	DeltaFrom     string `long:"delta-from"`
 	Positional    struct {
 		SnapDir   string `positional-arg-name:"<snap-dir>"`
 		TargetDir string `positional-arg-name:"<target-dir>"`
@@ -61,7 +65,12 @@ When used with --check-skeleton, pack only checks whether snap-dir contains
 valid snap metadata and raises an error otherwise. Application commands listed
 in snap metadata file, but appearing with incorrect permission bits result in an
 error. Commands that are missing from snap-dir are listed in diagnostic
-messages.`,
+messages.
+
+When used with --delta-from, pack also writes next to the snap a delta from the
+given snap file to the new one. The delta can be installed with 'snap install
+--delta-from' on devices where the snap file it was generated from is
+installed, saving the transfer of the full snap.`,
 )
 
 func init() {
@@ -77,6 +86,8 @@ func init() {
 			"filename": i18n.G("Output to this filename"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"compression": i18n.G("Compression to use (e.g. xz or lzo)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"delta-from": i18n.G("Also write a delta from this snap file to the new one"),
 		}, nil)
 	cmd.extra = func(cmd *flags.Command) {
 		// TRANSLATORS: this describes the default filename for a snap, e.g. core_16-2.35.2_amd64.snap
@@ -108,6 +119,11 @@ func (x *packCmd) Execute([]string) error {
 		return err
 	}
 
+	if x.DeltaFrom != "" && !osutil.FileExists(x.DeltaFrom) {
+		// TRANSLATORS: %q is the path of the snap file given with --delta-from
+		return fmt.Errorf(i18n.G("cannot find snap file %q to generate a delta from"), x.DeltaFrom)
+	}
+
 	snapPath, err := pack.Pack(x.Positional.SnapDir, &pack.Options{
 		TargetDir:   x.Positional.TargetDir,
 		SnapName:    x.Filename,
@@ -121,5 +137,14 @@ func (x *packCmd) Execute([]string) error {
 	}
 	// TRANSLATORS: %s is the path to the built snap file
 	fmt.Fprintf(Stdout, i18n.G("built: %s\n"), snapPath)
+
+	if x.DeltaFrom != "" {
+		deltaPath := strings.TrimSuffix(snapPath, ".snap") + "." + snapdelta.Format
+		if err := snapdelta.Generate(x.DeltaFrom, snapPath, deltaPath); err != nil {
+			return err
+		}
+		// TRANSLATORS: %s is the path to the built delta file
+		fmt.Fprintf(Stdout, i18n.G("built delta: %s\n"), deltaPath)
+	}
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 
 	snaprun "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/testutil"
 )
 
 const packSnapYaml = `name: hello
@@ -130,6 +131,36 @@ printf "hello world"
 	c.Assert(matches, check.HasLen, 1)
 }
 
+func (s *SnapSuite) TestPackPacksASnapWithDelta(c *check.C) {
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 2.0")
+	oldSnap := filepath.Join(c.MkDir(), "hello_1.0_all.snap")
+	c.Assert(os.WriteFile(oldSnap, []byte("old"), 0644), check.IsNil)
+
+	mksquashfs := testutil.MockCommand(c, "mksquashfs", `touch "$2"`)
+	defer mksquashfs.Restore()
+	xdelta3 := testutil.MockCommand(c, "xdelta3", `echo delta > "${@: -1}"`)
+	defer xdelta3.Restore()
+
+	targetDir := c.MkDir()
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", oldSnap, snapDir, targetDir})
+	c.Assert(err, check.IsNil)
+
+	snapPath := filepath.Join(targetDir, "hello_2.0_all.snap")
+	deltaPath := filepath.Join(targetDir, "hello_2.0_all.xdelta3")
+	c.Check(s.Stdout(), check.Equals, fmt.Sprintf("built: %s\nbuilt delta: %s\n", snapPath, deltaPath))
+	c.Check(xdelta3.Calls(), check.DeepEquals, [][]string{
+		{"xdelta3", "-e", "-f", "-s", oldSnap, snapPath, deltaPath},
+	})
+	c.Check(deltaPath, testutil.FileEquals, "delta\n")
+}
+
+func (s *SnapSuite) TestPackDeltaFromMissing(c *check.C) {
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 2.0")
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", "/does/not/exist.snap", snapDir, snapDir})
+	c.Assert(err, check.ErrorMatches, `cannot find snap file "/does/not/exist.snap" to generate a delta from`)
+}
+
 func (s *SnapSuite) TestPackPacksASnapWithCompressionHappy(c *check.C) {
 	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 1.0")
 
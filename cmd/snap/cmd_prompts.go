@@ -0,0 +1,291 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/strutil"
+)
+
+type cmdPrompts struct {
+	clientMixin
+	timeMixin
+	Reply  bool `long:"reply"`
+	Follow bool `long:"follow"`
+}
+
+var shortPromptsHelp = i18n.G("List and reply to outstanding prompts")
+var longPromptsHelp = i18n.G(`
+The prompts command lists the outstanding requests for access made by snaps
+which are subject to prompting, and which are waiting on a reply from the user.
+
+With --reply, the user is asked in turn whether to allow or deny each
+outstanding prompt, for how long the reply should apply, and to which path
+pattern and permissions.
+
+With --follow, the command does not exit once the outstanding prompts have
+been handled, and instead waits for new prompts to appear.
+`)
+
+func init() {
+	addCommand("prompts", shortPromptsHelp, longPromptsHelp, func() flags.Commander { return &cmdPrompts{} }, timeDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"reply": i18n.G("Interactively reply to each prompt"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"follow": i18n.G("Wait for new prompts once the outstanding ones have been handled"),
+	}), nil)
+}
+
+// promptsNoticeTimeout is how long a single request waits for new prompt
+// notices when following.
+var promptsNoticeTimeout = 10 * time.Minute
+
+func (x *cmdPrompts) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	err := x.run()
+	if err == io.EOF {
+		// the user closed the input, nothing more can be replied to
+		fmt.Fprintln(Stdout)
+		return nil
+	}
+	return err
+}
+
+func (x *cmdPrompts) run() error {
+	// notices which occur from now on may refer to new prompts
+	after := time.Now()
+
+	prompts, err := x.client.Prompts(nil)
+	if err != nil {
+		return err
+	}
+
+	if !x.Reply {
+		if len(prompts) == 0 && !x.Follow {
+			fmt.Fprintln(Stdout, i18n.G("No outstanding prompts."))
+			return nil
+		}
+		x.showPrompts(prompts)
+	}
+
+	in := bufio.NewReader(Stdin)
+	handled := make(map[string]bool)
+	handle := func(p *client.Prompt) error {
+		handled[p.ID] = true
+		if !x.Reply {
+			return nil
+		}
+		return x.replyToPrompt(in, p)
+	}
+
+	for _, p := range prompts {
+		if err := handle(p); err != nil {
+			return err
+		}
+	}
+
+	if !x.Follow {
+		return nil
+	}
+
+	for {
+		notices, err := x.client.Notices(&client.NoticesOptions{
+			Types:   []client.NoticeType{client.InterfacesRequestsPromptNotice},
+			After:   after,
+			Timeout: promptsNoticeTimeout,
+		})
+		if err != nil {
+			return err
+		}
+		for _, n := range notices {
+			// notices are sorted by the time they last repeated
+			after = n.LastRepeated
+			if n.LastData["resolved"] != "" || handled[n.Key] {
+				continue
+			}
+			p, err := x.client.Prompt(n.Key, nil)
+			if err != nil {
+				if isPromptNotFound(err) {
+					// already replied to by another client
+					continue
+				}
+				return err
+			}
+			if !x.Reply {
+				x.showPrompts([]*client.Prompt{p})
+			}
+			if err := handle(p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func isPromptNotFound(err error) bool {
+	var e *client.Error
+	return errors.As(err, &e) && e.Kind == client.ErrorKindInterfacesRequestsPromptNotFound
+}
+
+func (x *cmdPrompts) showPrompts(prompts []*client.Prompt) {
+	if len(prompts) == 0 {
+		return
+	}
+	w := tabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, i18n.G("ID\tTimestamp\tSnap\tInterface\tPath\tPermissions"))
+	for _, p := range prompts {
+		path := p.Constraints.Path
+		if path == "" {
+			path = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", p.ID, x.fmtTime(p.Timestamp), p.Snap, p.Interface, path, strings.Join(p.Constraints.RequestedPermissions, ","))
+	}
+}
+
+// promptAnswer prints the given question and reads a line from the user,
+// returning def if the line is empty.
+func promptAnswer(in *bufio.Reader, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(Stdout, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(Stdout, "%s: ", question)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+var promptLifespans = []string{"single", "session", "timespan", "forever"}
+
+// askPromptReply asks the user how to reply to the given prompt. It returns
+// a nil reply if the user chose to skip the prompt.
+func askPromptReply(in *bufio.Reader, p *client.Prompt) (*client.PromptReply, error) {
+	var reply client.PromptReply
+
+	for reply.Action == "" {
+		answer, err := promptAnswer(in, i18n.G("Allow, deny or skip?"), "skip")
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(answer) {
+		case "a", "allow":
+			reply.Action = "allow"
+		case "d", "deny":
+			reply.Action = "deny"
+		case "s", "skip":
+			return nil, nil
+		default:
+			fmt.Fprintf(Stderr, i18n.G("invalid answer %q, expected allow, deny or skip\n"), answer)
+		}
+	}
+
+	for reply.Lifespan == "" {
+		answer, err := promptAnswer(in, fmt.Sprintf(i18n.G("Lifespan (%s)?"), strings.Join(promptLifespans, ", ")), "single")
+		if err != nil {
+			return nil, err
+		}
+		if !strutil.ListContains(promptLifespans, answer) {
+			fmt.Fprintf(Stderr, i18n.G("invalid lifespan %q\n"), answer)
+			continue
+		}
+		reply.Lifespan = answer
+	}
+
+	if reply.Lifespan == "timespan" {
+		for reply.Duration == "" {
+			answer, err := promptAnswer(in, i18n.G("Duration (for example 10m or 1h)?"), "")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := time.ParseDuration(answer); err != nil {
+				fmt.Fprintf(Stderr, i18n.G("invalid duration %q\n"), answer)
+				continue
+			}
+			reply.Duration = answer
+		}
+	}
+
+	if p.Constraints.Path != "" {
+		answer, err := promptAnswer(in, i18n.G("Path pattern?"), p.Constraints.Path)
+		if err != nil {
+			return nil, err
+		}
+		reply.Constraints.PathPattern = answer
+	}
+
+	answer, err := promptAnswer(in, i18n.G("Permissions?"), strings.Join(p.Constraints.RequestedPermissions, ","))
+	if err != nil {
+		return nil, err
+	}
+	reply.Constraints.Permissions = strutil.CommaSeparatedList(answer)
+
+	return &reply, nil
+}
+
+func (x *cmdPrompts) replyToPrompt(in *bufio.Reader, p *client.Prompt) error {
+	fmt.Fprintf(Stdout, i18n.G("Snap %q (pid %d) requests access through the %q interface\n"), p.Snap, p.PID, p.Interface)
+	if p.Constraints.Path != "" {
+		fmt.Fprintf(Stdout, i18n.G("  path:        %s\n"), p.Constraints.Path)
+	}
+	fmt.Fprintf(Stdout, i18n.G("  permissions: %s\n"), strings.Join(p.Constraints.RequestedPermissions, ","))
+
+	for {
+		reply, err := askPromptReply(in, p)
+		if err != nil {
+			return err
+		}
+		if reply == nil {
+			fmt.Fprintln(Stdout, i18n.G("Skipped."))
+			return nil
+		}
+		satisfied, err := x.client.ReplyToPrompt(p.ID, reply, nil)
+		if isPromptNotFound(err) {
+			fmt.Fprintln(Stdout, i18n.G("Prompt is no longer outstanding."))
+			return nil
+		}
+		if err != nil {
+			// let the user correct the reply, e.g. a path pattern which
+			// does not match the requested path
+			fmt.Fprintf(Stderr, i18n.G("error: %v\n"), err)
+			continue
+		}
+		fmt.Fprintf(Stdout, i18n.G("Replied to %d prompt(s).\n"), len(satisfied))
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const homePromptJSON = `{
+	"id": "0000000000000002",
+	"timestamp": "2026-10-18T12:00:00Z",
+	"snap": "firefox",
+	"pid": 1234,
+	"cgroup": "0::/user.slice/snap.firefox.firefox.scope",
+	"interface": "home",
+	"constraints": {
+		"path": "/home/test/Downloads/foo.txt",
+		"requested-permissions": ["read", "write"],
+		"available-permissions": ["read", "write", "execute"]
+	}
+}`
+
+const cameraPromptJSON = `{
+	"id": "0000000000000003",
+	"timestamp": "2026-10-18T12:05:00Z",
+	"snap": "cheese",
+	"pid": 4321,
+	"cgroup": "0::/user.slice/snap.cheese.cheese.scope",
+	"interface": "camera",
+	"constraints": {
+		"requested-permissions": ["access"],
+		"available-permissions": ["access"]
+	}
+}`
+
+func (s *SnapSuite) TestPromptsNone(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "No outstanding prompts.\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestPromptsList(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+		fmt.Fprintf(w, `{"type": "sync", "result": [%s, %s]}`, homePromptJSON, cameraPromptJSON)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+ID                Timestamp             Snap     Interface  Path                          Permissions
+0000000000000002  2026-10-18T12:00:00Z  firefox  home       /home/test/Downloads/foo.txt  read,write
+0000000000000003  2026-10-18T12:05:00Z  cheese   camera     -                             access
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestPromptsReply(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+			fmt.Fprintf(w, `{"type": "sync", "result": [%s, %s]}`, homePromptJSON, cameraPromptJSON)
+		case 2:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000002")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body, DeepEquals, map[string]any{
+				"action":   "allow",
+				"lifespan": "timespan",
+				"duration": "1h",
+				"constraints": map[string]any{
+					"path-pattern": "/home/test/Downloads/*",
+					"permissions":  []any{"read"},
+				},
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": ["0000000000000002"]}`)
+		default:
+			c.Fatalf("unexpected request %d", n)
+		}
+	})
+
+	// allow the first prompt, skip the second one
+	fmt.Fprint(s.stdin, "allow\nbogus\ntimespan\n1h\n/home/test/Downloads/*\nread\ns\n")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts", "--reply"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 2)
+	c.Check(s.Stdout(), Equals, `Snap "firefox" (pid 1234) requests access through the "home" interface
+  path:        /home/test/Downloads/foo.txt
+  permissions: read,write
+Allow, deny or skip? [skip]: Lifespan (single, session, timespan, forever)? [single]: Lifespan (single, session, timespan, forever)? [single]: Duration (for example 10m or 1h)?: Path pattern? [/home/test/Downloads/foo.txt]: Permissions? [read,write]: Replied to 1 prompt(s).
+Snap "cheese" (pid 4321) requests access through the "camera" interface
+  permissions: access
+Allow, deny or skip? [skip]: Skipped.
+`)
+	c.Check(s.Stderr(), Equals, "invalid lifespan \"bogus\"\n")
+}
+
+func (s *SnapSuite) TestPromptsReplyRetriesOnError(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			fmt.Fprintf(w, `{"type": "sync", "result": [%s]}`, homePromptJSON)
+		case 2:
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(400)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "path pattern in reply does not match originally requested path", "kind": "interfaces-requests-reply-not-match-request"}}`)
+		case 3:
+			c.Check(r.Method, Equals, "POST")
+			var body map[string]any
+			c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+			c.Check(body["action"], Equals, "deny")
+			c.Check(body["lifespan"], Equals, "single")
+			fmt.Fprintln(w, `{"type": "sync", "result": ["0000000000000002"]}`)
+		default:
+			c.Fatalf("unexpected request %d", n)
+		}
+	})
+
+	fmt.Fprint(s.stdin, "a\n\n/tmp/*\n\nd\n\n\n\n")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts", "--reply"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 3)
+	c.Check(s.Stderr(), Equals, "error: path pattern in reply does not match originally requested path\n")
+}
+
+func (s *SnapSuite) TestPromptsReplyEOF(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		fmt.Fprintf(w, `{"type": "sync", "result": [%s, %s]}`, homePromptJSON, cameraPromptJSON)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts", "--reply"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `Snap "firefox" (pid 1234) requests access through the "home" interface
+  path:        /home/test/Downloads/foo.txt
+  permissions: read,write
+Allow, deny or skip? [skip]: `+`
+`)
+}
+
+func (s *SnapSuite) TestPromptsFollow(c *C) {
+	defer snap.MockPromptsNoticeTimeout(time.Second)()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts")
+			fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+		case 2:
+			c.Check(r.URL.Path, Equals, "/v2/notices")
+			c.Check(r.URL.Query().Get("types"), Equals, "interfaces-requests-prompt")
+			c.Check(r.URL.Query().Get("timeout"), Equals, "1s")
+			c.Check(r.URL.Query().Get("after"), Not(Equals), "")
+			fmt.Fprintln(w, `{"type": "sync", "result": [
+				{"id": "1", "type": "interfaces-requests-prompt", "key": "0000000000000001", "last-repeated": "2026-10-18T12:00:00Z", "last-data": {"resolved": "expired"}},
+				{"id": "2", "type": "interfaces-requests-prompt", "key": "0000000000000003", "last-repeated": "2026-10-18T12:05:00Z"}
+			]}`)
+		case 3:
+			c.Check(r.URL.Path, Equals, "/v2/interfaces/requests/prompts/0000000000000003")
+			fmt.Fprintf(w, `{"type": "sync", "result": %s}`, cameraPromptJSON)
+		case 4:
+			c.Check(r.URL.Path, Equals, "/v2/notices")
+			c.Check(r.URL.Query().Get("after"), Equals, "2026-10-18T12:05:00Z")
+			w.WriteHeader(500)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "boom"}}`)
+		default:
+			c.Fatalf("unexpected request %d", n)
+		}
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"prompts", "--follow", "--abs-time"})
+	c.Assert(err, ErrorMatches, "boom")
+	c.Check(n, Equals, 4)
+	c.Check(s.Stdout(), Equals, `
+ID                Timestamp             Snap    Interface  Path  Permissions
+0000000000000003  2026-10-18T12:05:00Z  cheese  camera     -     access
+`[1:])
+}
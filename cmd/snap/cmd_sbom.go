@@ -0,0 +1,88 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
+)
+
+type cmdSBOM struct {
+	clientMixin
+	Format string `long:"format" default:"spdx" choice:"spdx" choice:"cyclonedx"`
+	Output string `long:"output"`
+}
+
+var shortSBOMHelp = i18n.G("Show the software bill of materials of the system")
+var longSBOMHelp = i18n.G(`
+The sbom command shows a software bill of materials of all the snaps and
+components installed on the system, with their revisions, publishers,
+licenses, digests and dependencies on bases and content providers.
+
+Bills of materials shipped by snaps in their meta/ directory are referenced.
+
+The document is in SPDX 2.3 JSON format by default, or in CycloneDX 1.5 JSON
+format with --format=cyclonedx.
+`)
+
+func init() {
+	addCommand("sbom", shortSBOMHelp, longSBOMHelp, func() flags.Commander {
+		return &cmdSBOM{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"format": i18n.G("Format of the document: spdx or cyclonedx"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"output": i18n.G("Write the document to the given file instead of standard output"),
+	}, nil)
+}
+
+func (x *cmdSBOM) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	doc, err := x.client.SBOM(x.Format)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, doc, "", "  "); err != nil {
+		return fmt.Errorf(i18n.G("cannot format SBOM: %v"), err)
+	}
+	buf.WriteByte('\n')
+
+	if x.Output == "" {
+		_, err := Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := osutil.AtomicWriteFile(x.Output, buf.Bytes(), 0644, 0); err != nil {
+		return fmt.Errorf(i18n.G("cannot write SBOM: %v"), err)
+	}
+	// TRANSLATORS: %s is a file name
+	fmt.Fprintf(Stdout, i18n.G("SBOM written to %s\n"), x.Output)
+	return nil
+}
@@ -0,0 +1,76 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *SnapSuite) mockSBOMServer(c *check.C, expectedFormat string) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/sbom")
+		c.Check(r.URL.Query().Get("format"), check.Equals, expectedFormat)
+		fmt.Fprintln(w, `{"type": "sync", "result": {"bomFormat": "CycloneDX", "version": 1}}`)
+	})
+	s.AddCleanup(func() { c.Check(n, check.Equals, 1) })
+}
+
+func (s *SnapSuite) TestSBOM(c *check.C) {
+	s.mockSBOMServer(c, "spdx")
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"sbom"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+	c.Check(s.Stdout(), check.Equals, `{
+  "bomFormat": "CycloneDX",
+  "version": 1
+}
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestSBOMCycloneDXToFile(c *check.C) {
+	s.mockSBOMServer(c, "cyclonedx")
+
+	output := filepath.Join(c.MkDir(), "sbom.json")
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"sbom", "--format=cyclonedx", "--output", output})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, fmt.Sprintf("SBOM written to %s\n", output))
+	c.Check(output, testutil.FileEquals, `{
+  "bomFormat": "CycloneDX",
+  "version": 1
+}
+`)
+}
+
+func (s *SnapSuite) TestSBOMInvalidFormat(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"sbom", "--format=swid"})
+	c.Assert(err, check.ErrorMatches, `Invalid value .swid. for option .--format.*`)
+}
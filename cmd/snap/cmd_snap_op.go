@@ -73,6 +73,15 @@ override, taking the snap back to the current revision of the channel it's
 tracking.
 
 Use --name to set the instance name when installing from snap file.
+
+Use --delta-from to install a delta file made with 'snap pack --delta-from'.
+The delta is applied to the installed revision of the given snap, and the
+resulting snap must match a snap-revision assertion, either acknowledged
+beforehand with 'snap ack' or given with --delta-assertions.
+
+Use --bundle to install a bundle file made with 'snap download --bundle'. The
+assertions in the bundle are acknowledged, and all of its snaps are installed
+or refreshed together or none of them are.
 `)
 
 var longRemoveHelp = i18n.G(`
@@ -108,6 +117,11 @@ requests from 'snap refresh target-snap'.
 When snaps are specified --hold is effective on both their auto-refreshes
 and general refresh requests from 'snap refresh'. However, specific snap
 requests from 'snap refresh target-snap' remain unblocked and will proceed.
+
+Schedule (--schedule) sets a refresh timer, in the format of refresh.timer, for
+the specified snaps. Those snaps are auto-refreshed together according to it
+instead of the system refresh timer, until --schedule=default is used. Use
+--time to see when each of them is refreshed next.
 `)
 
 var longTryHelp = i18n.G(`
@@ -735,6 +749,9 @@ type cmdInstall struct {
 	IgnoreRunning    bool                   `long:"ignore-running" hidden:"yes"`
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	QuotaGroupName   string                 `long:"quota-group"`
+	DeltaFrom        installedSnapName      `long:"delta-from"`
+	DeltaAssertions  flags.Filename         `long:"delta-assertions"`
+	Bundle           bool                   `long:"bundle"`
 	Positional       struct {
 		Snaps []remoteSnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -746,7 +763,7 @@ func (x *cmdInstall) installOne(nameOrPath, desiredName string, opts *client.Sna
 	var snapName string
 	var path string
 
-	if isLocalContainer(nameOrPath) {
+	if isLocalContainer(nameOrPath) || opts.DeltaFrom != "" {
 		// don't log the request's body because the encoded snap is large.
 		x.client.SetMayLogBody(false)
 		path = nameOrPath
@@ -875,6 +892,38 @@ func (x *cmdInstall) installMany(names []string, opts *client.SnapOptions) error
 	return nil
 }
 
+func (x *cmdInstall) installBundle(path string, opts *client.SnapOptions) error {
+	// don't log the request's body because the encoded bundle is large
+	x.client.SetMayLogBody(false)
+	changeID, err := x.client.InstallBundle(path, opts)
+	if err != nil {
+		msg, err := errorToCmdMessage(path, "install", err, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(Stderr, msg)
+		return nil
+	}
+
+	chg, err := x.wait(changeID)
+	if err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	changedSnaps, err := changedSnapsFromChange(chg)
+	if err != nil && err != client.ErrNoData {
+		return err
+	}
+	if changedSnaps == nil || !changedSnaps.hasChanges() {
+		return nil
+	}
+
+	return showDone(x.client, chg, changedSnaps, "install", opts, x.getEscapes())
+}
+
 // compareChangedToRequested compares the changed snaps to the requested set of
 // snap and component changes. A message is printed to stdout for unchanged
 // snaps and components.
@@ -938,6 +987,7 @@ func (x *cmdInstall) Execute([]string) error {
 		Transaction:      x.Transaction,
 		QuotaGroupName:   x.QuotaGroupName,
 		Prefer:           x.Prefer,
+		DeltaFrom:        string(x.DeltaFrom),
 	}
 	x.setModes(opts)
 
@@ -948,6 +998,48 @@ func (x *cmdInstall) Execute([]string) error {
 		}
 	}
 
+	if x.DeltaFrom != "" {
+		if len(names) != 1 {
+			return errors.New(i18n.G("a single delta file must be specified with --delta-from"))
+		}
+		if dangerous {
+			return errors.New(i18n.G("cannot use --delta-from with --dangerous, the snap rebuilt from the delta must be verified"))
+		}
+		if x.Name != "" {
+			return errors.New(i18n.G("cannot use instance name with --delta-from, the delta is installed as the snap it applies to"))
+		}
+	}
+
+	if x.DeltaAssertions != "" {
+		if x.DeltaFrom == "" {
+			return errors.New(i18n.G("cannot use --delta-assertions without --delta-from"))
+		}
+		assertions, err := os.ReadFile(string(x.DeltaAssertions))
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot read assertions for the delta: %v"), err)
+		}
+		opts.DeltaAssertions = assertions
+	}
+
+	if x.Bundle {
+		if len(names) != 1 {
+			return errors.New(i18n.G("a single bundle file must be specified with --bundle"))
+		}
+		if dangerous {
+			return errors.New(i18n.G("cannot use --bundle with --dangerous, the content of the bundle must be verified"))
+		}
+		if x.DeltaFrom != "" {
+			return errors.New(i18n.G("cannot use --bundle and --delta-from at the same time"))
+		}
+		if x.Name != "" {
+			return errors.New(i18n.G("cannot use instance name with --bundle"))
+		}
+		if x.asksForChannel() {
+			return errors.New(i18n.G("cannot use channel flags with --bundle"))
+		}
+		return x.installBundle(names[0], opts)
+	}
+
 	if len(names) == 1 {
 		return x.installOne(names[0], x.Name, opts)
 	}
@@ -987,6 +1079,7 @@ type cmdRefresh struct {
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	Hold             string                 `long:"hold" optional:"yes" optional-value:"forever"`
 	Unhold           bool                   `long:"unhold"`
+	Schedule         string                 `long:"schedule"`
 	Positional       struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -1107,6 +1200,35 @@ func (x *cmdRefresh) showRefreshTimes() error {
 	} else {
 		fmt.Fprintf(Stdout, "next: n/a\n")
 	}
+
+	if len(sysinfo.Refresh.Snaps) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(sysinfo.Refresh.Snaps))
+	for name := range sysinfo.Refresh.Snaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(Stdout, "snaps:\n")
+	for _, name := range names {
+		info := sysinfo.Refresh.Snaps[name]
+		fmt.Fprintf(Stdout, "  %s:\n", name)
+		fmt.Fprintf(Stdout, "    timer: %s\n", info.Timer)
+		if last := parseSysinfoTime(info.Last); !last.IsZero() {
+			fmt.Fprintf(Stdout, "    last: %s\n", x.fmtTime(last))
+		} else {
+			fmt.Fprintf(Stdout, "    last: n/a\n")
+		}
+		if next := parseSysinfoTime(info.Next); !next.IsZero() {
+			if next.Before(hold) || next.Equal(hold) {
+				fmt.Fprintf(Stdout, "    next: %s (but held)\n", x.fmtTime(next))
+			} else {
+				fmt.Fprintf(Stdout, "    next: %s\n", x.fmtTime(next))
+			}
+		} else {
+			fmt.Fprintf(Stdout, "    next: n/a\n")
+		}
+	}
 	return nil
 }
 
@@ -1170,6 +1292,11 @@ func (x *cmdRefresh) Execute([]string) error {
 		x.Transaction != client.TransactionPerSnap
 
 	switch {
+	case x.Schedule != "":
+		if x.Hold != "" || x.Unhold || x.Tracking || otherFlags || x.asksForMode() || x.asksForChannel() {
+			return errors.New(i18n.G("cannot use --schedule with other flags"))
+		}
+		return x.setRefreshSchedule()
 	case x.Tracking:
 		if x.Hold != "" || x.Unhold || otherFlags {
 			return errors.New(i18n.G("cannot use --tracking with other flags"))
@@ -1264,6 +1391,40 @@ func (x *cmdRefresh) trackRefreshes() (err error) {
 	})
 }
 
+func (x *cmdRefresh) setRefreshSchedule() error {
+	names := installedSnapNames(x.Positional.Snaps)
+	if len(names) == 0 {
+		return errors.New(i18n.G("--schedule requires at least one snap"))
+	}
+
+	var timer any
+	if x.Schedule != "default" {
+		timer = x.Schedule
+	}
+	patch := make(map[string]any, len(names))
+	for _, name := range names {
+		patch["refresh.snap-timer."+name] = timer
+	}
+	changeID, err := x.client.SetConf("system", patch)
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	if timer == nil {
+		fmt.Fprintf(Stdout, i18n.G("Auto-refresh of %s follows the system refresh timer\n"), strutil.Quoted(names))
+	} else {
+		fmt.Fprintf(Stdout, i18n.G("Auto-refresh of %s scheduled with timer %q\n"), strutil.Quoted(names), x.Schedule)
+	}
+	return nil
+}
+
 func (x *cmdRefresh) holdRefreshes() (err error) {
 	var opts client.SnapOptions
 
@@ -1648,6 +1809,12 @@ func init() {
 			"quota-group": i18n.G("Add the snap to a quota group on install"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"prefer": i18n.G("Enable all aliases of the given snap in preference to conflicting aliases of other snaps"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"delta-from": i18n.G("Install the given delta file on top of the installed revision of this snap"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"delta-assertions": i18n.G("Verify the snap rebuilt from the delta with the assertions in the given file"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"bundle": i18n.G("Install the snaps from the given bundle file, together with its assertions"),
 		}), nil)
 	addCommand("refresh", shortRefreshHelp, longRefreshHelp, func() flags.Commander { return &cmdRefresh{} },
 		colorDescs.also(waitDescs).also(channelDescs).also(modeDescs).also(timeDescs).also(map[string]string{
@@ -1675,6 +1842,8 @@ func init() {
 			"hold": i18n.G("Hold refreshes for a specified duration (or forever, if no value is specified)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"unhold": i18n.G("Remove refresh hold"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"schedule": i18n.G("Auto-refresh the snaps with the given timer, or with the system one if \"default\""),
 		}), nil)
 	addCommand("try", shortTryHelp, longTryHelp, func() flags.Commander { return &cmdTry{} }, waitDescs.also(modeDescs), nil)
 	addCommand("enable", shortEnableHelp, longEnableHelp, func() flags.Commander { return &cmdEnable{} }, waitDescs, nil)
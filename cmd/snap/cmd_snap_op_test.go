@@ -1346,6 +1346,137 @@ func (s *SnapOpSuite) TestInstallPath(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestInstallPathDeltaFrom(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+
+		form := testForm(r, c)
+		defer form.RemoveAll()
+
+		c.Check(form.Value["action"], check.DeepEquals, []string{"install"})
+		c.Check(form.Value["delta-from"], check.DeepEquals, []string{"foo"})
+		c.Check(form.Value["snap-path"], check.NotNil)
+		c.Check(form.Value["transaction"], check.NotNil)
+		c.Check(form.Value, check.HasLen, 4)
+
+		name, _, body := formFile(form, c)
+		c.Check(name, check.Equals, "snap")
+		c.Check(string(body), check.Equals, "delta-data")
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	// not recognizable as a local file from its name
+	deltaPath := filepath.Join(c.MkDir(), "foo_1.1_all.xdelta3")
+	err := os.WriteFile(deltaPath, []byte("delta-data"), 0644)
+	c.Assert(err, check.IsNil)
+	oldCwd, err := os.Getwd()
+	c.Assert(err, check.IsNil)
+	c.Assert(os.Chdir(filepath.Dir(deltaPath)), check.IsNil)
+	defer os.Chdir(oldCwd)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--delta-from", "foo", filepath.Base(deltaPath)})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
+func (s *SnapOpSuite) TestInstallPathDeltaAssertions(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+
+		form := testForm(r, c)
+		defer form.RemoveAll()
+
+		c.Check(form.Value["delta-from"], check.DeepEquals, []string{"foo"})
+		c.Check(form.Value["assertions"], check.DeepEquals, []string{"type: snap-revision\n"})
+
+		name, _, body := formFile(form, c)
+		c.Check(name, check.Equals, "snap")
+		c.Check(string(body), check.Equals, "delta-data")
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	dir := c.MkDir()
+	deltaPath := filepath.Join(dir, "foo_1.1_all.xdelta3")
+	c.Assert(os.WriteFile(deltaPath, []byte("delta-data"), 0644), check.IsNil)
+	assertsPath := filepath.Join(dir, "foo_1.1.assert")
+	c.Assert(os.WriteFile(assertsPath, []byte("type: snap-revision\n"), 0644), check.IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--delta-from", "foo", "--delta-assertions", assertsPath, deltaPath})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
+func (s *SnapOpSuite) TestInstallPathDeltaFromErrors(c *check.C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--delta-from", "foo", "a.xdelta3", "b.xdelta3"}, `a single delta file must be specified with --delta-from`},
+		{[]string{"--delta-from", "foo", "--dangerous", "a.xdelta3"}, `cannot use --delta-from with --dangerous, the snap rebuilt from the delta must be verified`},
+		{[]string{"--delta-from", "foo", "--name", "foo_bar", "a.xdelta3"}, `cannot use instance name with --delta-from, the delta is installed as the snap it applies to`},
+		{[]string{"--delta-assertions", "foo.assert", "a.snap"}, `cannot use --delta-assertions without --delta-from`},
+		{[]string{"--delta-from", "foo", "--delta-assertions", "/does/not/exist", "a.xdelta3"}, `cannot read assertions for the delta: open /does/not/exist: no such file or directory`},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"install"}, t.args...))
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
+
+func (s *SnapOpSuite) TestInstallBundle(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+
+		form := testForm(r, c)
+		defer form.RemoveAll()
+
+		c.Check(form.Value["action"], check.DeepEquals, []string{"install-bundle"})
+		c.Check(form.Value["snap-path"], check.NotNil)
+		c.Check(form.Value["transaction"], check.NotNil)
+		c.Check(form.Value, check.HasLen, 3)
+
+		name, _, body := formFile(form, c)
+		c.Check(name, check.Equals, "snap")
+		c.Check(string(body), check.Equals, "bundle-data")
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	bundlePath := filepath.Join(c.MkDir(), "update.bundle")
+	err := os.WriteFile(bundlePath, []byte("bundle-data"), 0644)
+	c.Assert(err, check.IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--bundle", bundlePath})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
+func (s *SnapOpSuite) TestInstallBundleErrors(c *check.C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--bundle", "a.bundle", "b.bundle"}, `a single bundle file must be specified with --bundle`},
+		{[]string{"--bundle", "--dangerous", "a.bundle"}, `cannot use --bundle with --dangerous, the content of the bundle must be verified`},
+		{[]string{"--bundle", "--delta-from", "foo", "a.bundle"}, `cannot use --bundle and --delta-from at the same time`},
+		{[]string{"--bundle", "--name", "foo_bar", "a.bundle"}, `cannot use instance name with --bundle`},
+		{[]string{"--bundle", "--edge", "a.bundle"}, `cannot use channel flags with --bundle`},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"install"}, t.args...))
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
+
 func (s *SnapOpSuite) TestComponentInstallPath(c *check.C) {
 	s.srv.checker = func(r *http.Request) {
 		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
@@ -2081,6 +2212,102 @@ next: 2017-04-26T00:58:00+02:00
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestRefreshTimeSnapTimers(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "snaps": {
+"foo": {"timer": "mon,10:00-12:00", "last": "2017-04-24T10:35:00+02:00", "next": "2017-05-01T10:12:00+02:00"},
+"bar": {"timer": "fri,8:00-9:00"}}}}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+snaps:
+  bar:
+    timer: fri,8:00-9:00
+    last: n/a
+    next: n/a
+  foo:
+    timer: mon,10:00-12:00
+    last: 2017-04-24T10:35:00+02:00
+    next: 2017-05-01T10:12:00+02:00
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshSchedule(c *check.C) {
+	for _, t := range []struct {
+		timer   string
+		value   any
+		message string
+	}{
+		{"mon,10:00-12:00", "mon,10:00-12:00", `Auto-refresh of "foo", "bar" scheduled with timer "mon,10:00-12:00"` + "\n"},
+		{"default", nil, `Auto-refresh of "foo", "bar" follows the system refresh timer` + "\n"},
+	} {
+		n := 0
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			switch n {
+			case 0:
+				c.Check(r.Method, check.Equals, "PUT")
+				c.Check(r.URL.Path, check.Equals, "/v2/snaps/system/conf")
+				c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]any{
+					"refresh.snap-timer.foo": t.value,
+					"refresh.snap-timer.bar": t.value,
+				})
+				w.WriteHeader(202)
+				fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+			case 1:
+				c.Check(r.Method, check.Equals, "GET")
+				c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+				fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done"}}`)
+			default:
+				c.Fatalf("expected to get 2 requests, now on %d", n+1)
+			}
+			n++
+		})
+
+		rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--schedule=" + t.timer, "foo", "bar"})
+		c.Assert(err, check.IsNil)
+		c.Assert(rest, check.DeepEquals, []string{})
+		c.Check(s.Stdout(), check.Equals, t.message)
+		c.Check(s.Stderr(), check.Equals, "")
+		c.Check(n, check.Equals, 2)
+		s.ResetStdStreams()
+	}
+}
+
+func (s *SnapSuite) TestRefreshScheduleErrors(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Errorf("unexpected request")
+	})
+
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"refresh", "--schedule=mon"}, "--schedule requires at least one snap"},
+		{[]string{"refresh", "--schedule=mon", "--hold", "foo"}, "cannot use --schedule with other flags"},
+		{[]string{"refresh", "--schedule=mon", "--amend", "foo"}, "cannot use --schedule with other flags"},
+		{[]string{"refresh", "--schedule=mon", "--channel=edge", "foo"}, "cannot use --schedule with other flags"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(t.args)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+}
+
 func (s *SnapSuite) TestRefreshTimeShowsHolds(c *check.C) {
 	type testcase struct {
 		in  string
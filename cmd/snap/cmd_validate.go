@@ -22,6 +22,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/jessevdk/go-flags"
@@ -37,6 +38,8 @@ type cmdValidate struct {
 	Enforce    bool `long:"enforce"`
 	Forget     bool `long:"forget"`
 	Refresh    bool `long:"refresh"`
+	Preview    bool `long:"preview"`
+	DiffFrom   int  `long:"diff-from"`
 	Positional struct {
 		ValidationSet string `positional-arg-name:"<validation-set>"`
 	} `positional-args:"yes"`
@@ -53,6 +56,12 @@ fixed revisions.
 A validation set can either be in monitoring mode, in which case its constraints
 aren't enforced, or in enforcing mode, in which case snapd will not allow
 operations which would result in snaps breaking the validation set's constraints.
+
+With --preview, the snaps that would need to be installed, refreshed or
+removed to enforce the given validation set, together with the other enforced
+ones, are listed without changing anything. The constraints of the validation
+set are also compared with the ones of the tracked sequence, or of the one
+given with --diff-from.
 `)
 
 func init() {
@@ -65,6 +74,10 @@ func init() {
 		"forget": i18n.G("Forget the given validation set"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"refresh": i18n.G("Refresh or install snaps to satisfy enforced validation sets"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"preview": i18n.G("Show what enforcing the given validation set would change"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"diff-from": i18n.G("Compare with the given sequence of the validation set (with --preview)"),
 	})), []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<validation-set>"),
@@ -97,6 +110,7 @@ func (cmd *cmdValidate) Execute(args []string) error {
 		{"monitor", cmd.Monitor},
 		{"enforce", cmd.Enforce},
 		{"forget", cmd.Forget},
+		{"preview", cmd.Preview},
 	} {
 		if a.set {
 			if action != "" {
@@ -109,6 +123,9 @@ func (cmd *cmdValidate) Execute(args []string) error {
 	if cmd.Refresh && !cmd.Enforce {
 		return fmt.Errorf("--refresh can only be used together with --enforce")
 	}
+	if cmd.DiffFrom != 0 && !cmd.Preview {
+		return fmt.Errorf("--diff-from can only be used together with --preview")
+	}
 
 	if cmd.Positional.ValidationSet == "" && action != "" {
 		return fmt.Errorf("missing validation set argument")
@@ -124,6 +141,10 @@ func (cmd *cmdValidate) Execute(args []string) error {
 		}
 	}
 
+	if cmd.Preview {
+		return cmd.preview(accountID, name, seq)
+	}
+
 	if action != "" {
 		if cmd.Refresh {
 			changeID, err := cmd.client.RefreshMany(nil, nil, &client.SnapOptions{
@@ -216,3 +237,93 @@ func (cmd *cmdValidate) Execute(args []string) error {
 
 	return nil
 }
+
+func (cmd *cmdValidate) preview(accountID, name string, seq int) error {
+	res, err := cmd.client.PreviewValidationSet(accountID, name, &client.ValidationSetPreviewOptions{
+		Sequence: seq,
+		DiffFrom: cmd.DiffFrom,
+	})
+	if err != nil {
+		return err
+	}
+
+	vset := fmt.Sprintf("%s/%s=%d", res.AccountID, res.Name, res.Sequence)
+	if len(res.Install)+len(res.Refresh)+len(res.Remove) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No changes needed to enforce validation set %q\n"), vset)
+	} else {
+		fmt.Fprintf(Stdout, i18n.G("Enforcing validation set %q requires:\n"), vset)
+		w := tabWriter()
+		fmt.Fprintln(w, i18n.G("Action\tSnap\tRevision\tCurrent"))
+		for _, a := range []struct {
+			name    string
+			actions []client.ValidationSetAction
+		}{
+			{"install", res.Install},
+			{"refresh", res.Refresh},
+			{"remove", res.Remove},
+		} {
+			for _, act := range a.actions {
+				snapName := act.Snap
+				if act.Component != "" {
+					snapName += "+" + act.Component
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.name, snapName, dashIfEmpty(act.Revision), dashIfEmpty(act.Current))
+			}
+		}
+		w.Flush()
+		if res.Unresolvable != "" {
+			fmt.Fprintf(Stdout, i18n.G("Note: %s\n"), res.Unresolvable)
+		}
+	}
+
+	if res.Diff == nil {
+		return nil
+	}
+	fmt.Fprintln(Stdout)
+	if len(res.Diff.Snaps) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No differences between sequences %d and %d\n"), res.Diff.From, res.Diff.To)
+		return nil
+	}
+	w := tabWriter()
+	fmt.Fprintf(w, i18n.G("Snap\tSequence %d\tSequence %d\n"), res.Diff.From, res.Diff.To)
+	for _, d := range res.Diff.Snaps {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", d.Name, fmtSnapConstraint(d.From), fmtSnapConstraint(d.To))
+	}
+	w.Flush()
+	return nil
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// fmtSnapConstraint formats the constraints of a validation set on a snap,
+// e.g. "required=3 (comp: optional)".
+func fmtSnapConstraint(c *client.ValidationSetSnapConstraint) string {
+	if c == nil {
+		return "-"
+	}
+	out := c.Presence
+	if c.Revision != 0 {
+		out += fmt.Sprintf("=%d", c.Revision)
+	}
+	if len(c.Components) == 0 {
+		return out
+	}
+	names := make([]string, 0, len(c.Components))
+	for name := range c.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	comps := make([]string, 0, len(names))
+	for _, name := range names {
+		comps = append(comps, fmt.Sprintf("%s: %s", name, fmtSnapConstraint(&client.ValidationSetSnapConstraint{
+			Presence: c.Components[name].Presence,
+			Revision: c.Components[name].Revision,
+		})))
+	}
+	return fmt.Sprintf("%s (%s)", out, strings.Join(comps, ", "))
+}
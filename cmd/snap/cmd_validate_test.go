@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"gopkg.in/check.v1"
 
@@ -107,6 +108,8 @@ func (s *validateSuite) TestValidateInvalidArgs(c *check.C) {
 		{[]string{"--monitor"}, `missing validation set argument`},
 		{[]string{"--forget"}, `missing validation set argument`},
 		{[]string{"--forget", "foo/-"}, `cannot parse validation set "foo/-": invalid validation set name "-"`},
+		{[]string{"--preview", "--enforce"}, `cannot use --enforce and --preview together`},
+		{[]string{"--diff-from=2", "foo/bar"}, `--diff-from can only be used together with --preview`},
 	} {
 		s.stdout.Reset()
 		s.stderr.Reset()
@@ -303,3 +306,60 @@ func (s *validateSuite) TestValidationSetsRefreshEnforceNoUnmetConstraints(c *ch
 	c.Check(s.Stderr(), check.Equals, "")
 	c.Check(s.Stdout(), check.Equals, "Enforced validation set \"foo/bar\"\n")
 }
+
+func (s *validateSuite) TestValidatePreview(c *check.C) {
+	var called bool
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(called, check.Equals, false)
+		called = true
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/validation-sets/foo/bar")
+		c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+			"preview":   []string{"true"},
+			"sequence":  []string{"3"},
+			"diff-from": []string{"2"},
+		})
+		w.WriteHeader(200)
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {
+			"account-id": "foo", "name": "bar", "sequence": 3,
+			"install": [{"snap": "snap-a", "revision": "2", "validation-sets": ["foo/bar"]}],
+			"refresh": [{"snap": "snap-b", "revision": "5", "current": "4", "validation-sets": ["foo/bar"]}],
+			"remove": [{"snap": "snap-c", "component": "comp", "current": "7", "validation-sets": ["foo/bar"]}],
+			"unresolvable": "cannot auto-resolve validation set constraints",
+			"diff": {"from": 2, "to": 3, "snaps": [
+				{"name": "snap-a", "to": {"presence": "required", "revision": 2}},
+				{"name": "snap-b", "from": {"presence": "required", "revision": 4}, "to": {"presence": "required", "revision": 5, "components": {"comp": {"presence": "optional"}}}}
+			]}
+		}}`)
+	})
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--preview", "--diff-from=2", "foo/bar=3"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(called, check.Equals, true)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Enforcing validation set "foo/bar=3" requires:
+Action   Snap         Revision  Current
+install  snap-a       2         -
+refresh  snap-b       5         4
+remove   snap-c+comp  -         7
+Note: cannot auto-resolve validation set constraints
+
+Snap    Sequence 2  Sequence 3
+snap-a  -           required=2
+snap-b  required=4  required=5 (comp: optional)
+`)
+}
+
+func (s *validateSuite) TestValidatePreviewNoChanges(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeValidationSetQueryHandler(c, `{"type": "sync", "status-code": 200, "result": {"account-id": "foo", "name": "bar", "sequence": 3, "diff": {"from": 2, "to": 3}}}`))
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--preview", "foo/bar"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `No changes needed to enforce validation set "foo/bar=3"
+
+No differences between sequences 2 and 3
+`)
+}
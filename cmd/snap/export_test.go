@@ -176,6 +176,14 @@ func MockMaxGoneTime(d time.Duration) (restore func()) {
 	}
 }
 
+func MockPromptsNoticeTimeout(d time.Duration) (restore func()) {
+	d0 := promptsNoticeTimeout
+	promptsNoticeTimeout = d
+	return func() {
+		promptsNoticeTimeout = d0
+	}
+}
+
 func MockSyscallExec(f func(string, []string, []string) error) (restore func()) {
 	syscallExecOrig := syscallExec
 	syscallExec = f
@@ -269,6 +269,23 @@ func (s *Schema) GetViewsAffectedByPath(path []Accessor) []*View {
 	return views
 }
 
+// RequestsAffectedByPath returns the requests, as they appear in the view's
+// rules, of the rules that have visibility into a storage path.
+func (v *View) RequestsAffectedByPath(path []Accessor) []string {
+	var requests []string
+	for _, rule := range v.rules {
+		if !pathChangeAffects(path, rule.storage) {
+			continue
+		}
+
+		if !strutil.ListContains(requests, rule.originalRequest) {
+			requests = append(requests, rule.originalRequest)
+		}
+	}
+
+	return requests
+}
+
 func pathChangeAffects(modified, affected []Accessor) bool {
 	for i, affectedKey := range affected {
 		if affectedKey.Type() == IndexPlaceholderType || affectedKey.Type() == KeyPlaceholderType {
@@ -335,6 +352,14 @@ func NewSchema(account string, dbSchemaName string, views map[string]any, schema
 			}
 		}
 
+		var secretsVisible bool
+		if secretsRaw, ok := viewMap["secrets"]; ok {
+			if secrets, ok := secretsRaw.(string); !ok || secrets != "visible" {
+				return nil, fmt.Errorf(`cannot define view %q: "secrets" must be "visible" if set`, name)
+			}
+			secretsVisible = true
+		}
+
 		rules, ok := viewMap["rules"].([]any)
 		if !ok || len(rules) == 0 {
 			return nil, fmt.Errorf("cannot define view %q: view rules must be non-empty list", name)
@@ -344,6 +369,7 @@ func NewSchema(account string, dbSchemaName string, views map[string]any, schema
 		if err != nil {
 			return nil, fmt.Errorf("cannot define view %q: %w", name, err)
 		}
+		view.secretsVisible = secretsVisible
 
 		dbSchema.views[name] = view
 	}
@@ -971,18 +997,38 @@ func (s *Schema) View(view string) *View {
 	return s.views[view]
 }
 
+// Views returns all the views of the confdb schema, sorted by name.
+func (s *Schema) Views() []*View {
+	views := make([]*View, 0, len(s.views))
+	for _, view := range s.views {
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
 // View carries access rules for a particular view in a confdb schema.
 type View struct {
 	Name   string
 	rules  []viewRule
 	schema *Schema
 	params map[string]paramPresence
+
+	// secretsVisible is true if the view can be used to read data with secret
+	// visibility.
+	secretsVisible bool
 }
 
 func (v *View) Schema() *Schema {
 	return v.schema
 }
 
+// SecretsVisible returns true if the view explicitly allows reading data with
+// secret visibility. Otherwise, secret values read through it are redacted.
+func (v *View) SecretsVisible() bool {
+	return v.secretsVisible
+}
+
 type expandedMatch struct {
 	// storagePath is a parsed storage path with all placeholders filled in.
 	storagePath []Accessor
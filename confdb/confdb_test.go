@@ -3055,6 +3055,61 @@ func (*viewSuite) TestGetAffectedViews(c *C) {
 	}
 }
 
+func (*viewSuite) TestSchemaViews(c *C) {
+	views := map[string]any{
+		"view-b": map[string]any{
+			"rules": []any{map[string]any{"request": "a", "storage": "a"}},
+		},
+		"view-a": map[string]any{
+			"rules": []any{map[string]any{"request": "b", "storage": "b"}},
+		},
+	}
+	schema, err := confdb.NewSchema("acc", "db", views, confdb.NewJSONSchema())
+	c.Assert(err, IsNil)
+
+	var names []string
+	for _, view := range schema.Views() {
+		names = append(names, view.Name)
+	}
+	c.Check(names, DeepEquals, []string{"view-a", "view-b"})
+}
+
+func (*viewSuite) TestRequestsAffectedByPath(c *C) {
+	views := map[string]any{
+		"view-1": map[string]any{
+			"rules": []any{
+				map[string]any{"request": "ssid", "storage": "wifi.ssid"},
+				map[string]any{"request": "ssid-alias", "storage": "wifi.ssid"},
+				map[string]any{"request": "wifi", "storage": "wifi"},
+				map[string]any{"request": "psk.{x}", "storage": "wifi.psks.{x}"},
+				map[string]any{"request": "dns", "storage": "dns"},
+			},
+		},
+	}
+	schema, err := confdb.NewSchema("acc", "db", views, confdb.NewJSONSchema())
+	c.Assert(err, IsNil)
+	view := schema.View("view-1")
+
+	type testcase struct {
+		modified string
+		requests []string
+	}
+
+	tcs := []testcase{
+		{modified: "wifi.ssid", requests: []string{"ssid", "ssid-alias", "wifi"}},
+		{modified: "wifi", requests: []string{"ssid", "ssid-alias", "wifi", "psk.{x}"}},
+		{modified: "wifi.psks.home", requests: []string{"wifi", "psk.{x}"}},
+		{modified: "dns.servers", requests: []string{"dns"}},
+		{modified: "proxy"},
+	}
+
+	for _, tc := range tcs {
+		cmt := Commentf("modified %q", tc.modified)
+		requests := view.RequestsAffectedByPath(parsePath(c, tc.modified))
+		c.Check(requests, DeepEquals, tc.requests, cmt)
+	}
+}
+
 func (*viewSuite) TestCheckReadEphemeralAccess(c *C) {
 	schemaStr := []byte(`{
 	"schema": {
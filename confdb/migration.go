@@ -0,0 +1,208 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/snapcore/snapd/strutil"
+)
+
+// Migration holds the transformations which convert data stored according to
+// the previous revision of a confdb-schema into data stored according to the
+// given revision.
+type Migration struct {
+	Revision int
+	Rules    []MigrationRule
+}
+
+// MigrationRule moves the value stored at one storage path to another,
+// optionally converting it into a different type. If To is empty, the value
+// is removed.
+type MigrationRule struct {
+	From []Accessor
+	To   []Accessor
+	// Type is the type the value is converted into. If empty, the value is
+	// moved as is.
+	Type string
+}
+
+var migrationTypes = []string{"string", "int", "number", "bool"}
+
+// ParseMigrations parses and validates a list of migrations in JSON format:
+//
+//	[{"revision": 2, "rules": [{"from": "a.b", "to": "c", "type": "int"}]}]
+//
+// Migrations must be listed in ascending order of revision and must not target
+// revisions above maxRevision.
+func ParseMigrations(raw []byte, maxRevision int) ([]Migration, error) {
+	var jsonMigrations []struct {
+		Revision int `json:"revision"`
+		Rules    []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+			Type string `json:"type"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal(raw, &jsonMigrations); err != nil {
+		return nil, fmt.Errorf("cannot parse migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(jsonMigrations))
+	for i, jm := range jsonMigrations {
+		if jm.Revision <= 0 || jm.Revision > maxRevision {
+			return nil, fmt.Errorf("cannot parse migration %d: invalid revision %d", i, jm.Revision)
+		}
+		if i > 0 && jm.Revision <= jsonMigrations[i-1].Revision {
+			return nil, fmt.Errorf("cannot parse migration %d: revisions must be in ascending order", i)
+		}
+		if len(jm.Rules) == 0 {
+			return nil, fmt.Errorf("cannot parse migration %d: no rules", i)
+		}
+
+		m := Migration{Revision: jm.Revision}
+		for _, jr := range jm.Rules {
+			from, err := ParsePathIntoAccessors(jr.From, ParseOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse migration %d: invalid \"from\" path %q: %w", i, jr.From, err)
+			}
+
+			var to []Accessor
+			if jr.To != "" {
+				to, err = ParsePathIntoAccessors(jr.To, ParseOptions{})
+				if err != nil {
+					return nil, fmt.Errorf("cannot parse migration %d: invalid \"to\" path %q: %w", i, jr.To, err)
+				}
+			}
+
+			if jr.Type != "" {
+				if to == nil {
+					return nil, fmt.Errorf("cannot parse migration %d: cannot convert removed path %q", i, jr.From)
+				}
+				if !strutil.ListContains(migrationTypes, jr.Type) {
+					return nil, fmt.Errorf("cannot parse migration %d: unknown type %q", i, jr.Type)
+				}
+			}
+
+			m.Rules = append(m.Rules, MigrationRule{From: from, To: to, Type: jr.Type})
+		}
+		migrations = append(migrations, m)
+	}
+
+	return migrations, nil
+}
+
+// ApplyMigrations applies the rules of the migrations, in order, to the
+// databag. Rules whose source path holds no data are skipped. The databag
+// may be left partially migrated if an error is returned, so callers should
+// pass in a copy.
+func ApplyMigrations(bag JSONDatabag, migrations []Migration) error {
+	for _, m := range migrations {
+		for _, rule := range m.Rules {
+			if err := applyMigrationRule(bag, rule); err != nil {
+				return fmt.Errorf("cannot apply migration to revision %d: %w", m.Revision, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyMigrationRule(bag JSONDatabag, rule MigrationRule) error {
+	value, err := bag.Get(rule.From, nil)
+	if err != nil {
+		if errors.Is(err, &NoDataError{}) {
+			return nil
+		}
+		return err
+	}
+
+	if rule.Type != "" {
+		value, err = convertValue(value, rule.Type)
+		if err != nil {
+			return fmt.Errorf("cannot convert %q: %w", JoinAccessors(rule.From), err)
+		}
+	}
+
+	if err := bag.Unset(rule.From); err != nil {
+		return err
+	}
+
+	if rule.To == nil {
+		return nil
+	}
+
+	return bag.Set(rule.To, value)
+}
+
+func convertValue(value any, typ string) (any, error) {
+	switch typ {
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case "int", "number":
+		var num float64
+		switch v := value.(type) {
+		case float64:
+			num = v
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value %q is not a number", v)
+			}
+			num = parsed
+		case bool:
+			if v {
+				num = 1
+			}
+		default:
+			return nil, fmt.Errorf("cannot convert %T to %s", value, typ)
+		}
+
+		if typ == "int" && num != math.Trunc(num) {
+			return nil, fmt.Errorf("value %v is not an integer", num)
+		}
+		return num, nil
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("value %q is not a boolean", v)
+			}
+			return b, nil
+		case float64:
+			return v != 0, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to %s", value, typ)
+}
@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdb_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/confdb"
+)
+
+type migrationSuite struct{}
+
+var _ = Suite(&migrationSuite{})
+
+func (*migrationSuite) TestParseMigrations(c *C) {
+	migrations, err := confdb.ParseMigrations([]byte(`[
+		{"revision": 1, "rules": [{"from": "a.b", "to": "c"}]},
+		{"revision": 2, "rules": [{"from": "c", "to": "c", "type": "string"}, {"from": "d"}]}
+	]`), 2)
+	c.Assert(err, IsNil)
+	c.Check(migrations, DeepEquals, []confdb.Migration{
+		{
+			Revision: 1,
+			Rules:    []confdb.MigrationRule{{From: parsePath(c, "a.b"), To: parsePath(c, "c")}},
+		},
+		{
+			Revision: 2,
+			Rules: []confdb.MigrationRule{
+				{From: parsePath(c, "c"), To: parsePath(c, "c"), Type: "string"},
+				{From: parsePath(c, "d")},
+			},
+		},
+	})
+}
+
+func (*migrationSuite) TestApplyMigrations(c *C) {
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.channel"), "11"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.legacy"), true), IsNil)
+
+	migrations, err := confdb.ParseMigrations([]byte(`[
+		{"revision": 1, "rules": [{"from": "wifi.psk", "to": "wifi.auth.password"}]},
+		{"revision": 2, "rules": [
+			{"from": "wifi.channel", "to": "wifi.channel", "type": "int"},
+			{"from": "wifi.legacy"},
+			{"from": "wifi.missing", "to": "wifi.other"}
+		]}
+	]`), 2)
+	c.Assert(err, IsNil)
+
+	err = confdb.ApplyMigrations(bag, migrations)
+	c.Assert(err, IsNil)
+
+	data, err := bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"wifi":{"auth":{"password":"secret"},"channel":11}}`)
+}
+
+func (*migrationSuite) TestApplyMigrationsConversions(c *C) {
+	type test struct {
+		value    any
+		typ      string
+		expected any
+		err      string
+	}
+
+	for _, t := range []test{
+		{value: 1.5, typ: "string", expected: "1.5"},
+		{value: true, typ: "string", expected: "true"},
+		{value: "2", typ: "int", expected: float64(2)},
+		{value: "2.5", typ: "number", expected: 2.5},
+		{value: true, typ: "int", expected: float64(1)},
+		{value: "yes", typ: "int", err: `.*value "yes" is not a number`},
+		{value: 2.5, typ: "int", err: `.*value 2.5 is not an integer`},
+		{value: "true", typ: "bool", expected: true},
+		{value: float64(0), typ: "bool", expected: false},
+		{value: "maybe", typ: "bool", err: `.*value "maybe" is not a boolean`},
+		{value: map[string]any{"a": "b"}, typ: "string", err: `.*cannot convert map\[string\]interface {} to string`},
+	} {
+		cmt := Commentf("%v to %s", t.value, t.typ)
+		bag := confdb.NewJSONDatabag()
+		c.Assert(bag.Set(parsePath(c, "a"), t.value), IsNil, cmt)
+
+		migrations := []confdb.Migration{{
+			Revision: 1,
+			Rules:    []confdb.MigrationRule{{From: parsePath(c, "a"), To: parsePath(c, "b"), Type: t.typ}},
+		}}
+		err := confdb.ApplyMigrations(bag, migrations)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, `cannot apply migration to revision 1: cannot convert "a": `+t.err, cmt)
+			continue
+		}
+		c.Assert(err, IsNil, cmt)
+
+		value, err := bag.Get(parsePath(c, "b"), nil)
+		c.Assert(err, IsNil, cmt)
+		c.Check(value, DeepEquals, t.expected, cmt)
+	}
+}
@@ -285,7 +285,12 @@ type StorageSchema struct {
 
 // Validate validates the provided JSON object.
 func (s *StorageSchema) Validate(raw []byte) error {
-	return s.topLevel.Validate(raw)
+	err := s.topLevel.Validate(raw)
+	if err != nil && s.NestedVisibility(SecretVisibility) {
+		// validation errors can include the offending value
+		return redactValidationError(s, err)
+	}
+	return err
 }
 
 // SchemaAt returns the types that may be stored at the specified path.
@@ -0,0 +1,198 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdb
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// RedactedValue replaces secret values in data returned to readers who aren't
+// allowed to see them.
+const RedactedValue = "<redacted>"
+
+// TransformSecrets calls transform on each value nested in value (the data
+// stored at the path) that the schema defines with secret visibility and
+// replaces it with the result. Values that aren't secret are returned as is.
+func TransformSecrets(schema DatabagSchema, path []Accessor, value any, transform func(any) (any, error)) (any, error) {
+	if !schema.NestedVisibility(SecretVisibility) {
+		return value, nil
+	}
+
+	schemas, err := schema.SchemaAt(path)
+	if err != nil {
+		return nil, err
+	}
+	return transformSecrets(schemas, value, transform)
+}
+
+func transformSecrets(schemas []DatabagSchema, value any, transform func(any) (any, error)) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var nested bool
+	for _, schema := range schemas {
+		if schema.Visibility() == SecretVisibility {
+			return transform(value)
+		}
+		nested = nested || schema.NestedVisibility(SecretVisibility)
+	}
+
+	if !nested {
+		return value, nil
+	}
+
+	nestedSchemas := func(acc Accessor) []DatabagSchema {
+		var nested []DatabagSchema
+		for _, schema := range schemas {
+			// the data was validated so it must match at least one of the
+			// schemas but not necessarily all of them
+			if s, err := schema.SchemaAt([]Accessor{acc}); err == nil {
+				nested = append(nested, s...)
+			}
+		}
+		return nested
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		transformed := make(map[string]any, len(v))
+		for key, val := range v {
+			val, err := transformSecrets(nestedSchemas(newKey(key, nil)), val, transform)
+			if err != nil {
+				return nil, err
+			}
+			transformed[key] = val
+		}
+		return transformed, nil
+	case []any:
+		transformed := make([]any, 0, len(v))
+		for i, val := range v {
+			val, err := transformSecrets(nestedSchemas(newIndex(strconv.Itoa(i), nil)), val, transform)
+			if err != nil {
+				return nil, err
+			}
+			transformed = append(transformed, val)
+		}
+		return transformed, nil
+	default:
+		return value, nil
+	}
+}
+
+// RedactSecrets replaces the secret values nested in value (the data stored at
+// the path) with RedactedValue.
+func RedactSecrets(schema DatabagSchema, path []Accessor, value any) (any, error) {
+	return TransformSecrets(schema, path, value, func(any) (any, error) {
+		return RedactedValue, nil
+	})
+}
+
+// redactingDatabag wraps a databag and redacts the secret values in the data
+// read from it.
+type redactingDatabag struct {
+	Databag
+	schema DatabagSchema
+}
+
+// NewRedactingDatabag returns a databag which reads from and writes to bag but
+// redacts any secret value, as defined by the schema, in the data read from it.
+func NewRedactingDatabag(bag Databag, schema DatabagSchema) Databag {
+	return &redactingDatabag{Databag: bag, schema: schema}
+}
+
+func (b *redactingDatabag) Get(path []Accessor, constraints map[string]string) (any, error) {
+	value, err := b.Databag.Get(path, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return RedactSecrets(b.schema, path, value)
+}
+
+func (b *redactingDatabag) Data() ([]byte, error) {
+	raw, err := b.Databag.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	data, err = RedactSecrets(b.schema, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// redactValidationError replaces the details of a validation error about a
+// secret value, since they may include the value itself.
+func redactValidationError(schema DatabagSchema, err error) error {
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		return err
+	}
+
+	path := make([]Accessor, 0, len(vErr.Path))
+	for _, part := range vErr.Path {
+		switch p := part.(type) {
+		case string:
+			path = append(path, newKey(p, nil))
+		case int:
+			path = append(path, newIndex(strconv.Itoa(p), nil))
+		default:
+			// can only happen due to a bug but err on the side of caution
+			return &ValidationError{Path: vErr.Path, Err: errors.New("invalid secret value")}
+		}
+	}
+
+	if !secretAt(schema, path) {
+		return err
+	}
+	return &ValidationError{Path: vErr.Path, Err: errors.New("invalid secret value")}
+}
+
+// secretAt returns true if the value at the path or any of its parents has
+// secret visibility. Since the details of alternatives can come from any of
+// them, those with nested secrets are also considered secret.
+func secretAt(schema DatabagSchema, path []Accessor) bool {
+	for i := 0; i <= len(path); i++ {
+		schemas, err := schema.SchemaAt(path[:i])
+		if err != nil {
+			// the path was reported by the schema so this shouldn't happen
+			return true
+		}
+
+		for _, s := range schemas {
+			if s.Visibility() == SecretVisibility {
+				return true
+			}
+
+			if _, ok := s.(*alternativesSchema); ok && i == len(path) && s.NestedVisibility(SecretVisibility) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdb_test
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/confdb"
+)
+
+type secretsSuite struct{}
+
+var _ = Suite(&secretsSuite{})
+
+var secretsSchema = []byte(`{
+	"schema": {
+		"wifi": {
+			"schema": {
+				"ssid": "string",
+				"psk": {
+					"type": "string",
+					"visibility": "secret"
+				},
+				"networks": {
+					"type": "array",
+					"values": {
+						"schema": {
+							"name": "string",
+							"key": {
+								"type": "string",
+								"visibility": "secret"
+							}
+						}
+					}
+				}
+			}
+		},
+		"tokens": {
+			"values": "string",
+			"visibility": "secret"
+		},
+		"other": "any"
+	}
+}`)
+
+func (*secretsSuite) TestRedactSecrets(c *C) {
+	schema, err := confdb.ParseStorageSchema(secretsSchema)
+	c.Assert(err, IsNil)
+
+	data := map[string]any{
+		"wifi": map[string]any{
+			"ssid": "foo",
+			"psk":  "secret",
+			"networks": []any{
+				map[string]any{"name": "a", "key": "key-a"},
+				map[string]any{"name": "b"},
+			},
+		},
+		"tokens": map[string]any{"a": "b"},
+		"other":  "value",
+	}
+
+	redacted, err := confdb.RedactSecrets(schema, nil, data)
+	c.Assert(err, IsNil)
+	c.Check(redacted, DeepEquals, map[string]any{
+		"wifi": map[string]any{
+			"ssid": "foo",
+			"psk":  confdb.RedactedValue,
+			"networks": []any{
+				map[string]any{"name": "a", "key": confdb.RedactedValue},
+				map[string]any{"name": "b"},
+			},
+		},
+		"tokens": confdb.RedactedValue,
+		"other":  "value",
+	})
+
+	// the original data is not modified
+	c.Check(data["tokens"], DeepEquals, map[string]any{"a": "b"})
+
+	redacted, err = confdb.RedactSecrets(schema, parsePath(c, "wifi.networks[0]"), map[string]any{"name": "a", "key": "key-a"})
+	c.Assert(err, IsNil)
+	c.Check(redacted, DeepEquals, map[string]any{"name": "a", "key": confdb.RedactedValue})
+
+	redacted, err = confdb.RedactSecrets(schema, parsePath(c, "wifi.ssid"), "foo")
+	c.Assert(err, IsNil)
+	c.Check(redacted, Equals, "foo")
+}
+
+func (*secretsSuite) TestTransformSecrets(c *C) {
+	schema, err := confdb.ParseStorageSchema(secretsSchema)
+	c.Assert(err, IsNil)
+
+	var seen []any
+	transformed, err := confdb.TransformSecrets(schema, parsePath(c, "wifi"), map[string]any{"ssid": "foo", "psk": "secret"}, func(v any) (any, error) {
+		seen = append(seen, v)
+		return "sealed:" + v.(string), nil
+	})
+	c.Assert(err, IsNil)
+	c.Check(transformed, DeepEquals, map[string]any{"ssid": "foo", "psk": "sealed:secret"})
+	c.Check(seen, DeepEquals, []any{"secret"})
+}
+
+func (*secretsSuite) TestRedactingDatabag(c *C) {
+	schema, err := confdb.ParseStorageSchema(secretsSchema)
+	c.Assert(err, IsNil)
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+
+	redacting := confdb.NewRedactingDatabag(bag, schema)
+	val, err := redacting.Get(parsePath(c, "wifi"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, DeepEquals, map[string]any{"ssid": "foo", "psk": confdb.RedactedValue})
+
+	val, err = redacting.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, confdb.RedactedValue)
+
+	raw, err := redacting.Data()
+	c.Assert(err, IsNil)
+	var data map[string]any
+	c.Assert(json.Unmarshal(raw, &data), IsNil)
+	c.Check(data, DeepEquals, map[string]any{"wifi": map[string]any{"ssid": "foo", "psk": confdb.RedactedValue}})
+
+	// writes go through to the wrapped databag
+	c.Assert(redacting.Set(parsePath(c, "wifi.psk"), "other"), IsNil)
+	val, err = bag.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "other")
+}
+
+func (*secretsSuite) TestValidateRedactsSecrets(c *C) {
+	schema, err := confdb.ParseStorageSchema([]byte(`{
+	"schema": {
+		"wifi": {
+			"schema": {
+				"ssid": {
+					"type": "string",
+					"choices": ["foo", "bar"]
+				},
+				"psk": {
+					"type": "string",
+					"pattern": "^[a-z]+$",
+					"visibility": "secret"
+				},
+				"networks": {
+					"type": "array",
+					"values": {
+						"type": "string",
+						"choices": ["a", "b"]
+					},
+					"visibility": "secret"
+				}
+			}
+		}
+	}
+}`))
+	c.Assert(err, IsNil)
+
+	err = schema.Validate([]byte(`{"wifi": {"psk": "Top-Secret"}}`))
+	c.Assert(err, ErrorMatches, `cannot accept element in "wifi.psk": invalid secret value`)
+	c.Check(err, Not(ErrorMatches), `.*Top-Secret.*`)
+
+	// values nested in secrets are redacted too
+	err = schema.Validate([]byte(`{"wifi": {"networks": ["a", "Top-Secret"]}}`))
+	c.Assert(err, ErrorMatches, `cannot accept element in "wifi.networks\[1\]": invalid secret value`)
+
+	// errors about other values are kept as is
+	err = schema.Validate([]byte(`{"wifi": {"ssid": "baz", "psk": "secret"}}`))
+	c.Assert(err, ErrorMatches, `cannot accept element in "wifi.ssid": string "baz" is not one of the allowed choices`)
+}
+
+func (*secretsSuite) TestViewSecretsVisible(c *C) {
+	views := map[string]any{
+		"default": map[string]any{
+			"rules": []any{map[string]any{"request": "a", "storage": "a"}},
+		},
+		"admin": map[string]any{
+			"rules":   []any{map[string]any{"request": "a", "storage": "a"}},
+			"secrets": "visible",
+		},
+	}
+	schema, err := confdb.NewSchema("acc", "db", views, confdb.NewJSONSchema())
+	c.Assert(err, IsNil)
+	c.Check(schema.View("default").SecretsVisible(), Equals, false)
+	c.Check(schema.View("admin").SecretsVisible(), Equals, true)
+
+	views["admin"].(map[string]any)["secrets"] = "hidden"
+	_, err = confdb.NewSchema("acc", "db", views, confdb.NewJSONSchema())
+	c.Assert(err, ErrorMatches, `cannot define view "admin": "secrets" must be "visible" if set`)
+}
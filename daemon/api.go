@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/clusterstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/configstate"
 	"github.com/snapcore/snapd/overlord/devicestate"
@@ -67,6 +68,7 @@ var api = []*Command{
 	warningsCmd,
 	debugPprofCmd,
 	debugCmd,
+	debugDenialsCmd,
 	snapshotCmd,
 	snapshotExportCmd,
 	connectionsCmd,
@@ -85,6 +87,7 @@ var api = []*Command{
 	quotaGroupInfoCmd,
 	confdbCmd,
 	confdbControlCmd,
+	confdbSchemaCmd,
 	noticesCmd,
 	noticeCmd,
 	requestsPromptsCmd,
@@ -93,6 +96,9 @@ var api = []*Command{
 	requestsRuleCmd,
 	systemSecurebootCmd,
 	systemVolumesCmd,
+	clusterCmd,
+	clusterStatusCmd,
+	sbomCmd,
 }
 
 type featureEndpoint struct {
@@ -189,6 +195,7 @@ var (
 	snapstatePathUpdateGoal                 = snapstate.PathUpdateGoal
 	snapstateInstallWithGoal                = snapstate.InstallWithGoal
 	snapstateInstallPath                    = snapstate.InstallPath
+	snapstateInstallPathWithDeviceContext   = snapstate.InstallPathWithDeviceContext
 	snapstateInstallPathMany                = snapstate.InstallPathMany
 	snapstateInstallComponentPath           = snapstate.InstallComponentPath
 	snapstateInstallComponents              = snapstate.InstallComponents
@@ -214,13 +221,22 @@ var (
 	assertstateRefreshSnapAssertions         = assertstate.RefreshSnapAssertions
 	assertstateRestoreValidationSetsTracking = assertstate.RestoreValidationSetsTracking
 	assertstateFetchAllValidationSets        = assertstate.FetchAllValidationSets
+	assertstateConfdbSchema                  = assertstate.ConfdbSchema
 
 	confdbstateGetView             = confdbstate.GetView
 	confdbstateGetTransactionToSet = confdbstate.GetTransactionToSet
 	confdbstateSetViaView          = confdbstate.SetViaView
 	confdbstateLoadConfdbAsync     = confdbstate.LoadConfdbAsync
+	confdbstateViewHistory         = confdbstate.ViewHistory
+	confdbstateRollback            = confdbstate.Rollback
+	confdbstateExport              = confdbstate.Export
+	confdbstateImport              = confdbstate.Import
+	confdbstateSnapViews           = confdbstate.SnapViews
 
 	devicestateSignConfdbControl = (*devicestate.DeviceManager).SignConfdbControl
+
+	clusterstateClusterStatus = clusterstate.ClusterStatus
+	clusterstateAssemble      = clusterstate.Assemble
 )
 
 func ensureStateSoonImpl(st *state.State) {
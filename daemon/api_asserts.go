@@ -22,6 +22,7 @@ package daemon
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -100,6 +101,14 @@ func getAssertTypeNames(c *Command, r *http.Request, user *auth.UserState) Respo
 }
 
 func doAssert(c *Command, r *http.Request, user *auth.UserState) Response {
+	switch r.URL.Query().Get("local-authority") {
+	case "":
+	case "true":
+		return doAddLocalAuthority(c, r)
+	default:
+		return BadRequest(`"local-authority" query parameter when used must be set to "true" or left unset`)
+	}
+
 	batch := asserts.NewBatch(nil)
 	_, err := batch.AddStream(r.Body)
 	if err != nil {
@@ -119,6 +128,47 @@ func doAssert(c *Command, r *http.Request, user *auth.UserState) Response {
 	return SyncResponse(nil)
 }
 
+func doAddLocalAuthority(c *Command, r *http.Request) Response {
+	var acct *asserts.Account
+	var acctKey *asserts.AccountKey
+	dec := asserts.NewDecoder(r.Body)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return BadRequest("cannot decode request body into assertions: %v", err)
+		}
+		switch x := a.(type) {
+		case *asserts.Account:
+			if acct != nil {
+				return BadRequest("local authority must be given as one account and one account-key assertion")
+			}
+			acct = x
+		case *asserts.AccountKey:
+			if acctKey != nil {
+				return BadRequest("local authority must be given as one account and one account-key assertion")
+			}
+			acctKey = x
+		default:
+			return BadRequest("local authority must be given as one account and one account-key assertion")
+		}
+	}
+	if acct == nil || acctKey == nil {
+		return BadRequest("local authority must be given as one account and one account-key assertion")
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := assertstate.AddLocalAuthority(st, acct, acctKey); err != nil {
+		return BadRequest("%v", err)
+	}
+	return SyncResponse(nil)
+}
+
 func assertsFindOneRemote(c *Command, at *asserts.AssertionType, headers map[string]string, user *auth.UserState) ([]asserts.Assertion, error) {
 	primaryKeys, err := asserts.PrimaryKeyFromHeaders(at, headers)
 	if err != nil {
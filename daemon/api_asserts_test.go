@@ -152,6 +152,59 @@ func (s *assertsSuite) TestAssertError(c *check.C) {
 	c.Check(rec.Body.String(), testutil.Contains, "assert failed")
 }
 
+func (s *assertsSuite) TestAssertLocalAuthority(c *check.C) {
+	st := s.d.Overlord().State()
+
+	privKey, _ := assertstest.GenerateKey(752)
+	localSigning := assertstest.NewSigningDB("acme-local", privKey)
+	localAcct := assertstest.NewAccount(localSigning, "acme-local", map[string]any{
+		"account-id": "acme-local",
+	}, "")
+	localKey := assertstest.NewAccountKey(localSigning, localAcct, nil, privKey.PublicKey(), "")
+
+	buf := &bytes.Buffer{}
+	enc := asserts.NewEncoder(buf)
+	c.Assert(enc.Encode(localAcct), check.IsNil)
+	c.Assert(enc.Encode(localKey), check.IsNil)
+
+	// Execute
+	req, err := http.NewRequest("POST", "/v2/assertions?local-authority=true", buf)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	// Verify (external)
+	c.Check(rsp.Status, check.Equals, 200)
+	// Verify (internal)
+	st.Lock()
+	defer st.Unlock()
+	c.Check(assertstate.DB(st).IsLocalAuthority("acme-local"), check.Equals, true)
+	ids, err := assertstate.LocalAuthorities(st)
+	c.Assert(err, check.IsNil)
+	c.Check(ids, check.DeepEquals, []string{"acme-local"})
+}
+
+func (s *assertsSuite) TestAssertLocalAuthorityErrors(c *check.C) {
+	acct := assertstest.NewAccount(s.StoreSigning, "developer1", nil, "")
+
+	for _, t := range []struct {
+		query string
+		body  []byte
+		err   string
+	}{
+		{"local-authority=maybe", asserts.Encode(acct), "query parameter when used must be set to"},
+		{"local-authority=true", asserts.Encode(acct), "local authority must be given as one account and one account-key assertion"},
+		{"local-authority=true", []byte("blargh"), "cannot decode request body into assertions"},
+	} {
+		req, err := http.NewRequest("POST", "/v2/assertions?"+t.query, bytes.NewBuffer(t.body))
+		c.Assert(err, check.IsNil)
+		s.asUserAuth(c, req)
+
+		rec := httptest.NewRecorder()
+		s.serveHTTP(c, rec, req)
+		c.Check(rec.Code, check.Equals, 400)
+		c.Check(rec.Body.String(), testutil.Contains, t.err)
+	}
+}
+
 func (s *assertsSuite) TestAssertsFindManyAll(c *check.C) {
 	acct := assertstest.NewAccount(s.StoreSigning, "developer1", map[string]any{
 		"account-id": "developer1-id",
@@ -0,0 +1,110 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var (
+	clusterCmd = &Command{
+		Path:        "/v2/cluster",
+		POST:        postCluster,
+		Actions:     []string{"assemble"},
+		WriteAccess: rootAccess{},
+	}
+	clusterStatusCmd = &Command{
+		Path:       "/v2/cluster/status",
+		GET:        getClusterStatus,
+		ReadAccess: openAccess{},
+	}
+)
+
+var assembleClusterChangeKind = swfeats.RegisterChangeKind("assemble-cluster")
+
+type postClusterData struct {
+	Action       string   `json:"action"`
+	Secret       string   `json:"secret"`
+	Address      string   `json:"address"`
+	ExpectedSize int      `json:"expected-size"`
+	Peers        []string `json:"peers"`
+}
+
+func postCluster(c *Command, r *http.Request, user *auth.UserState) Response {
+	var data postClusterData
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return BadRequest("cannot decode request body: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Clustering); err != nil {
+		return err
+	}
+
+	switch data.Action {
+	case "assemble":
+		ts, err := clusterstateAssemble(st, clusterstate.AssembleOptions{
+			Secret:       data.Secret,
+			Address:      data.Address,
+			ExpectedSize: data.ExpectedSize,
+			Peers:        data.Peers,
+		})
+		if err != nil {
+			return BadRequest(err.Error())
+		}
+
+		chg := newChange(st, assembleClusterChangeKind, "Assemble cluster", []*state.TaskSet{ts}, nil)
+		ensureStateSoon(st)
+		return AsyncResponse(nil, chg.ID())
+	default:
+		return BadRequest("unsupported cluster action %q", data.Action)
+	}
+}
+
+func getClusterStatus(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Clustering); err != nil {
+		return err
+	}
+
+	status, err := clusterstateClusterStatus(st)
+	if err != nil {
+		if errors.Is(err, clusterstate.ErrNoClusterAssertion) {
+			return NotFound("this device is not part of a cluster")
+		}
+		return InternalError("cannot get cluster status: %v", err)
+	}
+
+	return SyncResponse(status)
+}
@@ -0,0 +1,231 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+type clusterSuite struct {
+	apiBaseSuite
+}
+
+var _ = Suite(&clusterSuite{})
+
+func (s *clusterSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.expectReadAccess(daemon.OpenAccess{})
+	s.expectWriteAccess(daemon.RootAccess{})
+	s.daemon(c)
+}
+
+func (s *clusterSuite) setFeatureFlag(c *C) {
+	_, confOption := features.Clustering.ConfigOption()
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+
+	tr := config.NewTransaction(st)
+	err := tr.Set("core", confOption, true)
+	c.Assert(err, IsNil)
+	tr.Commit()
+}
+
+func (s *clusterSuite) TestClusterStatus(c *C) {
+	s.setFeatureFlag(c)
+
+	converged := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	status := &clusterstate.Status{
+		ClusterID: "cluster-id",
+		Sequence:  2,
+		Devices: []clusterstate.DeviceStatus{{
+			ID:          1,
+			Brand:       "my-brand",
+			Model:       "my-model",
+			Serial:      "serial-1",
+			Subclusters: []string{"default"},
+			Local:       true,
+			Report: &assemblestate.Report{
+				Serial:        "serial-1",
+				ClusterID:     "cluster-id",
+				Sequence:      2,
+				Converged:     true,
+				LastConverged: converged,
+				Snaps: []assemblestate.ReportedSnap{
+					{Instance: "some-snap", Revision: snap.R(3), Channel: "latest/stable"},
+				},
+			},
+		}, {
+			ID:     2,
+			Brand:  "my-brand",
+			Model:  "my-model",
+			Serial: "serial-2",
+		}},
+	}
+
+	restore := daemon.MockClusterstateClusterStatus(func(*state.State) (*clusterstate.Status, error) {
+		return status, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/cluster/status", nil)
+	c.Assert(err, IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, status)
+}
+
+func (s *clusterSuite) TestClusterStatusNoCluster(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockClusterstateClusterStatus(func(*state.State) (*clusterstate.Status, error) {
+		return nil, clusterstate.ErrNoClusterAssertion
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/cluster/status", nil)
+	c.Assert(err, IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 404)
+	c.Check(rspe.Message, Equals, "this device is not part of a cluster")
+}
+
+func (s *clusterSuite) TestClusterStatusError(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockClusterstateClusterStatus(func(*state.State) (*clusterstate.Status, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/cluster/status", nil)
+	c.Assert(err, IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 500)
+	c.Check(rspe.Message, Equals, "cannot get cluster status: boom")
+}
+
+func (s *clusterSuite) TestClusterStatusFeatureDisabled(c *C) {
+	req, err := http.NewRequest("GET", "/v2/cluster/status", nil)
+	c.Assert(err, IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "clustering" is disabled: set 'experimental.clustering' to true`)
+}
+
+func (s *clusterSuite) TestClusterAssemble(c *C) {
+	s.setFeatureFlag(c)
+
+	var got clusterstate.AssembleOptions
+	restore := daemon.MockClusterstateAssemble(func(st *state.State, opts clusterstate.AssembleOptions) (*state.TaskSet, error) {
+		got = opts
+		return state.NewTaskSet(st.NewTask("assemble-cluster", "...")), nil
+	})
+	defer restore()
+
+	soon := 0
+	_, restore = daemon.MockEnsureStateSoon(func(st *state.State) {
+		soon++
+	})
+	defer restore()
+
+	body := `{"action": "assemble", "secret": "secret", "address": "10.0.0.1:8001", "expected-size": 3, "peers": ["10.0.0.2:8001"]}`
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 202)
+	c.Check(got, DeepEquals, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      "10.0.0.1:8001",
+		ExpectedSize: 3,
+		Peers:        []string{"10.0.0.2:8001"},
+	})
+	c.Check(soon, Equals, 1)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "assemble-cluster")
+	c.Check(chg.Summary(), Equals, "Assemble cluster")
+	c.Check(chg.Tasks(), HasLen, 1)
+}
+
+func (s *clusterSuite) TestClusterAssembleError(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockClusterstateAssemble(func(*state.State, clusterstate.AssembleOptions) (*state.TaskSet, error) {
+		return nil, errors.New("cannot assemble cluster without a secret")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "assemble"}`))
+	c.Assert(err, IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, "cannot assemble cluster without a secret")
+}
+
+func (s *clusterSuite) TestClusterPostInvalid(c *C) {
+	s.setFeatureFlag(c)
+
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{}`))
+	c.Assert(err, IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `unsupported cluster action ""`)
+
+	req, err = http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`}`))
+	c.Assert(err, IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Matches, "cannot decode request body: .*")
+}
+
+func (s *clusterSuite) TestClusterAssembleFeatureDisabled(c *C) {
+	req, err := http.NewRequest("POST", "/v2/cluster", bytes.NewBufferString(`{"action": "assemble"}`))
+	c.Assert(err, IsNil)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "clustering" is disabled: set 'experimental.clustering' to true`)
+}
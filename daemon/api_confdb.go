@@ -52,6 +52,14 @@ var (
 		Actions:     []string{"delegate", "undelegate"},
 		WriteAccess: authenticatedAccess{Polkit: polkitActionManage},
 	}
+	confdbSchemaCmd = &Command{
+		Path:        "/v2/confdb/{account}/{confdb-schema}",
+		GET:         exportConfdb,
+		POST:        handleConfdbSchemaAction,
+		Actions:     []string{"rollback", "import"},
+		ReadAccess:  authenticatedAccess{Polkit: polkitActionManage},
+		WriteAccess: authenticatedAccess{Polkit: polkitActionManage},
+	}
 )
 
 func getView(c *Command, r *http.Request, _ *auth.UserState) Response {
@@ -66,6 +74,10 @@ func getView(c *Command, r *http.Request, _ *auth.UserState) Response {
 	vars := muxVars(r)
 	account, schemaName, viewName := vars["account"], vars["confdb-schema"], vars["view"]
 
+	if _, ok := r.URL.Query()["history"]; ok {
+		return getViewHistory(st, account, schemaName, viewName)
+	}
+
 	keysStr := r.URL.Query().Get("keys")
 	var keys []string
 	if keysStr != "" {
@@ -105,7 +117,38 @@ func getView(c *Command, r *http.Request, _ *auth.UserState) Response {
 	return AsyncResponse(nil, chgID)
 }
 
-func setView(c *Command, r *http.Request, _ *auth.UserState) Response {
+func getViewHistory(st *state.State, account, schemaName, viewName string) Response {
+	view, err := confdbstateGetView(st, account, schemaName, viewName)
+	if err != nil {
+		return toAPIError(err)
+	}
+
+	history, err := confdbstateViewHistory(st, view)
+	if err != nil {
+		return toAPIError(err)
+	}
+
+	if history == nil {
+		history = []*confdbstate.HistoryEntry{}
+	}
+	return SyncResponse(history)
+}
+
+// confdbAuthor returns a description of who made the request, to be recorded
+// in the confdb history.
+func confdbAuthor(r *http.Request, user *auth.UserState) string {
+	if user != nil && user.Username != "" {
+		return user.Username
+	}
+
+	ucred, err := ucrednetGet(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("uid:%d", ucred.Uid)
+}
+
+func setView(c *Command, r *http.Request, user *auth.UserState) Response {
 	st := c.d.state
 	st.Lock()
 	defer st.Unlock()
@@ -142,6 +185,9 @@ func setView(c *Command, r *http.Request, _ *auth.UserState) Response {
 	if err != nil {
 		return toAPIError(err)
 	}
+	if tx != nil {
+		tx.Author = confdbAuthor(r, user)
+	}
 
 	err = confdbstateSetViaView(tx, view, action.Values)
 	if err != nil {
@@ -183,6 +229,10 @@ func toAPIError(err error) *apiError {
 		}
 	case errors.Is(err, &confdb.BadRequestError{}):
 		return BadRequest(err.Error())
+	case errors.Is(err, &confdbstate.TransactionNotFoundError{}):
+		return NotFound(err.Error())
+	case errors.Is(err, &confdbstate.InvalidDataError{}):
+		return BadRequest(err.Error())
 	default:
 		return InternalError(err.Error())
 	}
@@ -269,3 +319,157 @@ func handleConfdbControlAction(c *Command, r *http.Request, user *auth.UserState
 
 	return SyncResponse(nil)
 }
+
+func exportConfdb(c *Command, r *http.Request, _ *auth.UserState) Response {
+	st := c.d.state
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Confdb); err != nil {
+		return err
+	}
+
+	vars := muxVars(r)
+	account, schemaName := vars["account"], vars["confdb-schema"]
+
+	query := r.URL.Query()
+	auths := strutil.CommaSeparatedList(query.Get("authentications"))
+	if err := checkConfdbAccess(c, r, account, schemaName, query.Get("operator-id"), auths); err != nil {
+		return err
+	}
+
+	data, err := confdbstateExport(st, account, schemaName)
+	if err != nil {
+		return toAPIError(err)
+	}
+
+	return SyncResponse(data)
+}
+
+// checkConfdbAccess checks that the client can access the confdb as a whole.
+// Only root can do so without identifying as an operator, since the device's
+// administrator isn't subject to delegations, while operators need all the
+// views of the confdb-schema to be delegated to them.
+// The state must be locked by the caller.
+func checkConfdbAccess(c *Command, r *http.Request, account, schemaName, operatorID string, auths []string) *apiError {
+	if operatorID != "" {
+		return checkConfdbDelegation(c, account, schemaName, operatorID, auths)
+	}
+
+	ucred, err := ucrednetGet(r.RemoteAddr)
+	if err != nil {
+		return Forbidden("cannot access confdb %s/%s: cannot determine UID of request", account, schemaName)
+	}
+	if ucred.Uid != 0 {
+		return Forbidden("cannot access confdb %s/%s: operator-id is required", account, schemaName)
+	}
+	return nil
+}
+
+// checkConfdbDelegation checks that all the views of the confdb-schema are
+// delegated to the operator with the given authentication methods, since
+// importing or exporting the whole confdb gives access to all of them.
+// The state must be locked by the caller.
+func checkConfdbDelegation(c *Command, account, schemaName, operatorID string, auths []string) *apiError {
+	st := c.d.state
+	if err := validateFeatureFlag(st, features.ConfdbControl); err != nil {
+		return err
+	}
+
+	if len(auths) == 0 {
+		return BadRequest("cannot check confdb delegation: no authentication methods provided")
+	}
+
+	confdbSchemaAs, err := assertstateConfdbSchema(st, account, schemaName)
+	if err != nil {
+		return toAPIError(err)
+	}
+
+	devMgr := c.d.overlord.DeviceManager()
+	cc, err := devMgr.ConfdbControl()
+	if err != nil &&
+		(!errors.Is(err, state.ErrNoState) ||
+			errors.Is(err, devicestate.ErrNoDeviceIdentityYet)) {
+		return InternalError(err.Error())
+	}
+
+	var ctrl confdb.Control
+	if cc != nil {
+		ctrl = cc.Control()
+	}
+
+	for _, view := range confdbSchemaAs.Schema().Views() {
+		delegated, err := ctrl.IsDelegated(operatorID, view.ID(), auths)
+		if err != nil {
+			return BadRequest(err.Error())
+		}
+
+		if !delegated {
+			return Forbidden("cannot access confdb %s/%s: view %q is not delegated to operator %q", account, schemaName, view.Name, operatorID)
+		}
+	}
+
+	return nil
+}
+
+type confdbSchemaAction struct {
+	Action        string `json:"action"`
+	TransactionID int    `json:"transaction-id"`
+
+	// for import
+	Data            map[string]any `json:"data"`
+	OperatorID      string         `json:"operator-id"`
+	Authentications []string       `json:"authentications"`
+}
+
+func handleConfdbSchemaAction(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.state
+	st.Lock()
+	defer st.Unlock()
+
+	if err := validateFeatureFlag(st, features.Confdb); err != nil {
+		return err
+	}
+
+	vars := muxVars(r)
+	account, schemaName := vars["account"], vars["confdb-schema"]
+
+	var a confdbSchemaAction
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		return BadRequest("cannot decode request body: %v", err)
+	}
+
+	switch a.Action {
+	case "rollback":
+		if a.TransactionID <= 0 {
+			return BadRequest("cannot rollback confdb: invalid transaction ID %d", a.TransactionID)
+		}
+
+		changeID, err := confdbstateRollback(st, account, schemaName, a.TransactionID, confdbAuthor(r, user))
+		if err != nil {
+			return toAPIError(err)
+		}
+
+		ensureStateSoon(st)
+		return AsyncResponse(nil, changeID)
+	case "import":
+		if a.Data == nil {
+			return BadRequest("cannot import confdb: request body contains no data")
+		}
+
+		if err := checkConfdbAccess(c, r, account, schemaName, a.OperatorID, a.Authentications); err != nil {
+			return err
+		}
+
+		changeID, err := confdbstateImport(st, account, schemaName, a.Data, confdbAuthor(r, user))
+		if err != nil {
+			return toAPIError(err)
+		}
+
+		ensureStateSoon(st)
+		return AsyncResponse(nil, changeID)
+	default:
+		return BadRequest("unknown action %q", a.Action)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
@@ -72,6 +74,10 @@ func (s *confdbSuite) SetUpTest(c *C) {
 		"system": {"network": confdb.NewJSONDatabag()},
 	}
 	s.st.Set("confdb-databags", databags)
+
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{Backstore: asserts.NewMemoryBackstore()})
+	c.Assert(err, IsNil)
+	assertstate.ReplaceDB(s.st, db)
 	s.st.Unlock()
 
 	views := map[string]any{
@@ -650,6 +656,345 @@ func (s *confdbSuite) TestGetBadConstraints(c *C) {
 	}
 }
 
+func (s *confdbSuite) TestGetViewHistory(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateGetView(func(_ *state.State, _, _, view string) (*confdb.View, error) {
+		return s.schema.View(view), nil
+	})
+	defer restore()
+
+	restore = daemon.MockConfdbstateLoadConfdbAsync(func(*state.State, *confdb.View, []string, map[string]string) (string, error) {
+		c.Error("unexpected call to LoadConfdbAsync")
+		return "", errors.New("unexpected call to LoadConfdbAsync")
+	})
+	defer restore()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	restore = daemon.MockConfdbstateViewHistory(func(_ *state.State, view *confdb.View) ([]*confdbstate.HistoryEntry, error) {
+		c.Check(view.Name, Equals, "wifi-setup")
+		return []*confdbstate.HistoryEntry{{
+			ID:       1,
+			Time:     now,
+			Author:   "jane",
+			ChangeID: "12",
+			Diff:     []confdbstate.HistoryDelta{{Path: "wifi.ssid", New: "foo"}},
+		}}, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/confdb/system/network/wifi-setup?history", nil)
+	c.Assert(err, IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, []*confdbstate.HistoryEntry{{
+		ID:       1,
+		Time:     now,
+		Author:   "jane",
+		ChangeID: "12",
+		Diff:     []confdbstate.HistoryDelta{{Path: "wifi.ssid", New: "foo"}},
+	}})
+}
+
+func (s *confdbSuite) TestGetViewHistoryEmpty(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateGetView(func(_ *state.State, _, _, view string) (*confdb.View, error) {
+		return s.schema.View(view), nil
+	})
+	defer restore()
+
+	restore = daemon.MockConfdbstateViewHistory(func(*state.State, *confdb.View) ([]*confdbstate.HistoryEntry, error) {
+		return nil, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/confdb/system/network/wifi-setup?history", nil)
+	c.Assert(err, IsNil)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, []*confdbstate.HistoryEntry{})
+}
+
+func (s *confdbSuite) TestSetViewRecordsAuthor(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateGetView(func(_ *state.State, _, _, view string) (*confdb.View, error) {
+		return s.schema.View(view), nil
+	})
+	defer restore()
+
+	s.st.Lock()
+	tx, err := confdbstate.NewTransaction(s.st, "system", "network")
+	s.st.Unlock()
+	c.Assert(err, IsNil)
+
+	restore = daemon.MockConfdbstateGetTransaction(func(*hookstate.Context, *state.State, *confdb.View) (*confdbstate.Transaction, confdbstate.CommitTxFunc, error) {
+		return tx, func() (string, <-chan struct{}, error) { return "123", nil, nil }, nil
+	})
+	defer restore()
+
+	restore = daemon.MockConfdbstateSetViaView(func(confdb.Databag, *confdb.View, map[string]any) error {
+		return nil
+	})
+	defer restore()
+
+	buf := bytes.NewBufferString(`{"values":{"ssid": "foo"}}`)
+	req, err := http.NewRequest("PUT", "/v2/confdb/system/network/wifi-setup", buf)
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rspe := s.asyncReq(c, req, &auth.UserState{Username: "jane"}, actionIsExpected)
+	c.Check(rspe.Status, Equals, 202)
+	c.Check(tx.Author, Equals, "jane")
+}
+
+func (s *confdbSuite) TestRollback(c *C) {
+	s.setFeatureFlag(c)
+
+	var called bool
+	restore := daemon.MockConfdbstateRollback(func(_ *state.State, account, schemaName string, txID int, author string) (string, error) {
+		called = true
+		c.Check(account, Equals, "system")
+		c.Check(schemaName, Equals, "network")
+		c.Check(txID, Equals, 3)
+		c.Check(author, Equals, "jane")
+		return "123", nil
+	})
+	defer restore()
+
+	buf := bytes.NewBufferString(`{"action": "rollback", "transaction-id": 3}`)
+	req, err := http.NewRequest("POST", "/v2/confdb/system/network", buf)
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rspe := s.asyncReq(c, req, &auth.UserState{Username: "jane"}, actionIsExpected)
+	c.Check(rspe.Status, Equals, 202)
+	c.Check(rspe.Change, Equals, "123")
+	c.Check(called, Equals, true)
+}
+
+func (s *confdbSuite) TestRollbackErrors(c *C) {
+	s.setFeatureFlag(c)
+
+	type test struct {
+		body   string
+		err    error
+		status int
+		errMsg string
+	}
+
+	for _, t := range []test{
+		{
+			body:   `{"action": "rollback", "transaction-id": 3}`,
+			err:    &confdbstate.TransactionNotFoundError{Account: "system", SchemaName: "network", ID: 3},
+			status: 404,
+			errMsg: "cannot find transaction 3 in the history of confdb system/network",
+		},
+		{
+			body:   `{"action": "rollback", "transaction-id": 3}`,
+			err:    errors.New("cannot rollback confdb system/network: ongoing transaction"),
+			status: 500,
+			errMsg: "cannot rollback confdb system/network: ongoing transaction",
+		},
+		{
+			body:   `{"action": "rollback"}`,
+			status: 400,
+			errMsg: "cannot rollback confdb: invalid transaction ID 0",
+		},
+		{
+			body:   `{"action": "foo"}`,
+			status: 400,
+			errMsg: `unknown action "foo"`,
+		},
+		{
+			body:   `{`,
+			status: 400,
+			errMsg: "cannot decode request body: unexpected EOF",
+		},
+	} {
+		cmt := Commentf("body %s", t.body)
+		restore := daemon.MockConfdbstateRollback(func(*state.State, string, string, int, string) (string, error) {
+			if t.err == nil {
+				c.Error("unexpected call to Rollback")
+			}
+			return "", t.err
+		})
+
+		req, err := http.NewRequest("POST", "/v2/confdb/system/network", bytes.NewBufferString(t.body))
+		c.Assert(err, IsNil, cmt)
+		req.Header.Set("Content-Type", "application/json")
+
+		rspe := s.errorReq(c, req, nil, actionExpectedBool(!strings.Contains(t.errMsg, "unknown action")))
+		c.Check(rspe.Status, Equals, t.status, cmt)
+		c.Check(rspe.Message, Equals, t.errMsg, cmt)
+		restore()
+	}
+}
+
+func (s *confdbSuite) TestRollbackFailUnsetFeatureFlag(c *C) {
+	restore := daemon.MockConfdbstateRollback(func(*state.State, string, string, int, string) (string, error) {
+		c.Error("unexpected call to Rollback")
+		return "", nil
+	})
+	defer restore()
+
+	buf := bytes.NewBufferString(`{"action": "rollback", "transaction-id": 3}`)
+	req, err := http.NewRequest("POST", "/v2/confdb/system/network", buf)
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, `feature flag "confdb" is disabled: set 'experimental.confdb' to true`)
+}
+
+func (s *confdbSuite) TestExport(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateExport(func(_ *state.State, account, schemaName string) (map[string]any, error) {
+		c.Check(account, Equals, "system")
+		c.Check(schemaName, Equals, "network")
+		return map[string]any{"wifi": map[string]any{"ssid": "foo"}}, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/confdb/system/network", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result, DeepEquals, map[string]any{"wifi": map[string]any{"ssid": "foo"}})
+}
+
+func (s *confdbSuite) TestImportExportRequireOperator(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateExport(func(*state.State, string, string) (map[string]any, error) {
+		c.Error("unexpected call to Export")
+		return nil, nil
+	})
+	defer restore()
+	restore = daemon.MockConfdbstateImport(func(*state.State, string, string, map[string]any, string) (string, error) {
+		c.Error("unexpected call to Import")
+		return "", nil
+	})
+	defer restore()
+
+	// users other than root must identify as an operator, even if they're
+	// authenticated
+	req, err := http.NewRequest("GET", "/v2/confdb/system/network", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;", dirs.SnapdSocket)
+	rspe := s.errorReq(c, req, &auth.UserState{Username: "jane"}, actionIsExpected)
+	c.Check(rspe.Status, Equals, 403)
+	c.Check(rspe.Message, Equals, "cannot access confdb system/network: operator-id is required")
+
+	buf := bytes.NewBufferString(`{"action": "import", "data": {"wifi": {"ssid": "foo"}}}`)
+	req, err = http.NewRequest("POST", "/v2/confdb/system/network", buf)
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;", dirs.SnapdSocket)
+	rspe = s.errorReq(c, req, &auth.UserState{Username: "jane"}, actionIsExpected)
+	c.Check(rspe.Status, Equals, 403)
+	c.Check(rspe.Message, Equals, "cannot access confdb system/network: operator-id is required")
+}
+
+func (s *confdbSuite) TestExportNoSchema(c *C) {
+	s.setFeatureFlag(c)
+
+	restore := daemon.MockConfdbstateExport(func(*state.State, string, string) (map[string]any, error) {
+		return nil, &asserts.NotFoundError{Type: asserts.ConfdbSchemaType}
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/confdb/system/network", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Kind, Equals, client.ErrorKindAssertionNotFound)
+}
+
+func (s *confdbSuite) TestImport(c *C) {
+	s.setFeatureFlag(c)
+
+	var called bool
+	restore := daemon.MockConfdbstateImport(func(_ *state.State, account, schemaName string, data map[string]any, author string) (string, error) {
+		called = true
+		c.Check(account, Equals, "system")
+		c.Check(schemaName, Equals, "network")
+		c.Check(data, DeepEquals, map[string]any{"wifi": map[string]any{"ssid": "foo"}})
+		c.Check(author, Equals, "jane")
+		return "123", nil
+	})
+	defer restore()
+
+	buf := bytes.NewBufferString(`{"action": "import", "data": {"wifi": {"ssid": "foo"}}}`)
+	req, err := http.NewRequest("POST", "/v2/confdb/system/network", buf)
+	c.Assert(err, IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+
+	rspe := s.asyncReq(c, req, &auth.UserState{Username: "jane"}, actionIsExpected)
+	c.Check(rspe.Status, Equals, 202)
+	c.Check(rspe.Change, Equals, "123")
+	c.Check(called, Equals, true)
+}
+
+func (s *confdbSuite) TestImportErrors(c *C) {
+	s.setFeatureFlag(c)
+
+	type test struct {
+		body   string
+		err    error
+		status int
+		errMsg string
+	}
+
+	for _, t := range []test{
+		{
+			body:   `{"action": "import"}`,
+			status: 400,
+			errMsg: "cannot import confdb: request body contains no data",
+		},
+		{
+			body:   `{"action": "import", "data": {"wifi": 1}}`,
+			err:    &confdbstate.InvalidDataError{Account: "system", SchemaName: "network", Err: errors.New("expected map")},
+			status: 400,
+			errMsg: "cannot import data into confdb system/network: expected map",
+		},
+		{
+			body:   `{"action": "import", "data": {}}`,
+			err:    errors.New("cannot import data into confdb system/network: ongoing transaction"),
+			status: 500,
+			errMsg: "cannot import data into confdb system/network: ongoing transaction",
+		},
+	} {
+		cmt := Commentf("body %s", t.body)
+		restore := daemon.MockConfdbstateImport(func(*state.State, string, string, map[string]any, string) (string, error) {
+			if t.err == nil {
+				c.Error("unexpected call to Import")
+			}
+			return "", t.err
+		})
+
+		req, err := http.NewRequest("POST", "/v2/confdb/system/network", bytes.NewBufferString(t.body))
+		c.Assert(err, IsNil, cmt)
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, Equals, t.status, cmt)
+		c.Check(rspe.Message, Equals, t.errMsg, cmt)
+		restore()
+	}
+}
+
 type confdbControlSuite struct {
 	apiBaseSuite
 
@@ -887,3 +1232,99 @@ func (s *confdbControlSuite) TestConfdbControlActionInvalidRequest(c *C) {
 		c.Check(rspe.Message, Equals, tc.errMsg)
 	}
 }
+
+func (s *confdbControlSuite) TestImportExportDelegation(c *C) {
+	s.prereqs(c)
+	s.expectReadAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage"})
+
+	schemaBody := []byte(`{
+  "storage": {
+    "schema": {
+      "dns": "any",
+      "wifi": "any"
+    }
+  }
+}`)
+	restore := daemon.MockAssertstateConfdbSchema(func(_ *state.State, account, schemaName string) (*asserts.ConfdbSchema, error) {
+		a := assertstest.FakeAssertionWithBody(schemaBody, map[string]any{
+			"type":         "confdb-schema",
+			"body-length":  strconv.Itoa(len(schemaBody)),
+			"authority-id": account,
+			"account-id":   account,
+			"name":         schemaName,
+			"views": map[string]any{
+				"setup-dns":  map[string]any{"rules": []any{map[string]any{"request": "dns", "storage": "dns"}}},
+				"setup-wifi": map[string]any{"rules": []any{map[string]any{"request": "wifi", "storage": "wifi"}}},
+			},
+		})
+		return a.(*asserts.ConfdbSchema), nil
+	})
+	defer restore()
+	restore = daemon.MockConfdbstateExport(func(*state.State, string, string) (map[string]any, error) {
+		return map[string]any{}, nil
+	})
+	defer restore()
+	restore = daemon.MockConfdbstateImport(func(*state.State, string, string, map[string]any, string) (string, error) {
+		return "123", nil
+	})
+	defer restore()
+
+	s.st.Lock()
+	a, err := asserts.SignWithoutAuthority(asserts.ConfdbControlType, map[string]any{
+		"brand-id": "can0nical",
+		"model":    "generic-classic",
+		"serial":   "serial-serial",
+		"groups": []any{
+			map[string]any{
+				"operators":       []any{"jane"},
+				"authentications": []any{"store"},
+				"views":           []any{"acc/network/setup-dns", "acc/network/setup-wifi"},
+			},
+			map[string]any{
+				"operators":       []any{"john"},
+				"authentications": []any{"store"},
+				"views":           []any{"acc/network/setup-wifi"},
+			},
+		},
+	}, nil, deviceKey)
+	c.Assert(err, IsNil)
+	c.Assert(assertstate.Add(s.st, a), IsNil)
+	s.st.Unlock()
+
+	// jane has access to all the views
+	req, err := http.NewRequest("GET", "/v2/confdb/acc/network?operator-id=jane&authentications=store", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+
+	body := `{"action": "import", "data": {}, "operator-id": "jane", "authentications": ["store"]}`
+	req, err = http.NewRequest("POST", "/v2/confdb/acc/network", bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	rspe := s.asyncReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 202)
+
+	// john doesn't
+	req, err = http.NewRequest("GET", "/v2/confdb/acc/network?operator-id=john&authentications=store", nil)
+	c.Assert(err, IsNil)
+	errRsp := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(errRsp.Status, Equals, 403)
+	c.Check(errRsp.Message, Equals, `cannot access confdb acc/network: view "setup-dns" is not delegated to operator "john"`)
+
+	body = `{"action": "import", "data": {}, "operator-id": "john", "authentications": ["store"]}`
+	req, err = http.NewRequest("POST", "/v2/confdb/acc/network", bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	errRsp = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(errRsp.Status, Equals, 403)
+
+	// jane didn't delegate with the operator's key
+	req, err = http.NewRequest("GET", "/v2/confdb/acc/network?operator-id=jane&authentications=operator-key", nil)
+	c.Assert(err, IsNil)
+	errRsp = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(errRsp.Status, Equals, 403)
+
+	req, err = http.NewRequest("GET", "/v2/confdb/acc/network?operator-id=jane", nil)
+	c.Assert(err, IsNil)
+	errRsp = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(errRsp.Status, Equals, 400)
+	c.Check(errRsp.Message, Equals, "cannot check confdb delegation: no authentication methods provided")
+}
@@ -780,6 +780,64 @@ func (s *interfacesSuite) TestConnectionsDefaultGadget(c *check.C) {
 	})
 }
 
+func (s *interfacesSuite) TestConnectionsWithExpiry(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.testConnectionsConnected(c, d, "/v2/connections", map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test",
+			"expiry":    "2099-01-01T00:00:00Z",
+		},
+	}, nil, map[string]any{
+		"result": map[string]any{
+			"plugs": []any{
+				map[string]any{
+					"snap":      "consumer",
+					"plug":      "plug",
+					"interface": "test",
+					"attrs":     map[string]any{"key": "value"},
+					"apps":      []any{"app"},
+					"label":     "label",
+					"connections": []any{
+						map[string]any{"snap": "producer", "slot": "slot"},
+					},
+				},
+			},
+			"slots": []any{
+				map[string]any{
+					"snap":      "producer",
+					"slot":      "slot",
+					"interface": "test",
+					"attrs":     map[string]any{"key": "value"},
+					"apps":      []any{"app"},
+					"label":     "label",
+					"connections": []any{
+						map[string]any{"snap": "consumer", "plug": "plug"},
+					},
+				},
+			},
+			"established": []any{
+				map[string]any{
+					"plug":      map[string]any{"snap": "consumer", "plug": "plug"},
+					"slot":      map[string]any{"snap": "producer", "slot": "slot"},
+					"manual":    true,
+					"interface": "test",
+					"expiry":    "2099-01-01T00:00:00Z",
+				},
+			},
+		},
+		"status":      "OK",
+		"status-code": 200.0,
+		"type":        "sync",
+	})
+}
+
 func (s *interfacesSuite) TestConnectionsAll(c *check.C) {
 	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
 	defer restore()
@@ -418,6 +418,8 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getRAAInfo(st)
 	case "features":
 		return getFeatures(c)
+	case "interface-policy":
+		return getInterfacePolicy(st, c.d.overlord.InterfaceManager(), query.Get("snap"), query.Get("interface"))
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
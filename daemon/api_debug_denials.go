@@ -0,0 +1,130 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sandbox/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+// debugDenialsCmd is separate from debugCmd as the denials are read from
+// the audit log and the journal, which are only readable by root.
+var debugDenialsCmd = &Command{
+	Path:       "/v2/debug/denials",
+	GET:        getDebugDenials,
+	ReadAccess: rootAccess{},
+}
+
+type denialJSON struct {
+	Snap      string        `json:"snap"`
+	App       string        `json:"app,omitempty"`
+	Revision  snap.Revision `json:"revision"`
+	Kind      string        `json:"kind"`
+	Access    string        `json:"access"`
+	Count     int           `json:"count"`
+	FirstSeen time.Time     `json:"first-seen"`
+	LastSeen  time.Time     `json:"last-seen"`
+	// Interfaces are the interfaces that would allow the access.
+	Interfaces []string `json:"interfaces,omitempty"`
+}
+
+var (
+	denialsCollect = denials.Collect
+
+	denialsSyscallResolver = func() (func(arch string, nr int) (string, error), error) {
+		compiler, err := seccomp.NewCompiler(snapdtool.InternalToolPath)
+		if err != nil {
+			return nil, err
+		}
+		return compiler.SyscallName, nil
+	}
+)
+
+// getDebugDenials returns the AppArmor and seccomp denials of the given
+// snap, grouped by app and access, together with the interfaces that would
+// allow each access. The state lock is not held while reading the logs, as
+// that can take a while.
+func getDebugDenials(c *Command, r *http.Request, user *auth.UserState) Response {
+	instanceName := r.URL.Query().Get("snap")
+	if instanceName == "" {
+		return BadRequest("cannot get denials without a snap name")
+	}
+	st := c.d.overlord.State()
+	st.Lock()
+	var snapst snapstate.SnapState
+	err := snapstate.Get(st, instanceName, &snapst)
+	st.Unlock()
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return SnapNotFound(instanceName, err)
+		}
+		return InternalError("cannot access snap state: %v", err)
+	}
+
+	ds, err := denialsCollect(instanceName)
+	if err != nil {
+		return InternalError("cannot collect denials: %v", err)
+	}
+	var hasSeccomp bool
+	for _, d := range ds {
+		hasSeccomp = hasSeccomp || d.Kind == denials.KindSeccomp
+	}
+	if hasSeccomp {
+		if resolve, err := denialsSyscallResolver(); err != nil {
+			logger.Noticef("cannot resolve names of denied system calls: %v", err)
+		} else {
+			denials.ResolveSyscalls(ds, resolve)
+		}
+	}
+	for _, d := range ds {
+		// AppArmor records do not tell the revision
+		if d.Revision.Unset() {
+			d.Revision = snapst.Current
+		}
+	}
+
+	suggester := denials.NewSuggester(c.d.overlord.InterfaceManager().Repository().AllInterfaces())
+	groups := denials.GroupDenials(ds)
+	res := make([]denialJSON, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, denialJSON{
+			Snap:       g.Snap,
+			App:        g.App,
+			Revision:   g.Revision,
+			Kind:       string(g.Kind),
+			Access:     g.Access,
+			Count:      g.Count,
+			FirstSeen:  g.FirstSeen,
+			LastSeen:   g.LastSeen,
+			Interfaces: suggester.Suggest(g.Denial),
+		})
+	}
+	return SyncResponse(res)
+}
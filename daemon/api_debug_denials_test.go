@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&debugDenialsSuite{})
+
+type debugDenialsSuite struct {
+	apiBaseSuite
+}
+
+func (s *debugDenialsSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.expectReadAccess(daemon.RootAccess{})
+}
+
+func (s *debugDenialsSuite) TestGetDenials(c *check.C) {
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(7), true, "")
+
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	restore := daemon.MockDenialsCollect(func(instanceName string) ([]*denials.Denial, error) {
+		c.Check(instanceName, check.Equals, "foo")
+		return []*denials.Denial{
+			{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Operation: "open", Class: "file", Name: "/dev/video0", Mask: "r", Time: t0},
+			{Kind: denials.KindSeccomp, Snap: "foo", App: "app", Revision: snap.R(6), Arch: "amd64", SyscallNumber: 1000, Time: t0.Add(time.Second)},
+			{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Operation: "open", Class: "file", Name: "/dev/video0", Mask: "r", Time: t0.Add(2 * time.Second)},
+		}, nil
+	})
+	defer restore()
+	restore = daemon.MockDenialsSyscallResolver(func() (func(arch string, nr int) (string, error), error) {
+		return func(arch string, nr int) (string, error) {
+			c.Check(arch, check.Equals, "amd64")
+			c.Check(nr, check.Equals, 1000)
+			return "frobnicate", nil
+		}, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug/denials?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	c.Check(rsp.Result, check.DeepEquals, []daemon.DenialJSON{{
+		Snap:       "foo",
+		App:        "app",
+		Revision:   snap.R(7),
+		Kind:       "apparmor",
+		Access:     "open /dev/video0 (r)",
+		Count:      2,
+		FirstSeen:  t0,
+		LastSeen:   t0.Add(2 * time.Second),
+		Interfaces: []string{"camera"},
+	}, {
+		Snap:      "foo",
+		App:       "app",
+		Revision:  snap.R(6),
+		Kind:      "seccomp",
+		Access:    "syscall frobnicate",
+		Count:     1,
+		FirstSeen: t0.Add(time.Second),
+		LastSeen:  t0.Add(time.Second),
+		// interfaces with unrestricted seccomp profiles
+		Interfaces: []string{"lxd-support", "steam-support"},
+	}})
+}
+
+func (s *debugDenialsSuite) TestGetDenialsUnresolvedSyscalls(c *check.C) {
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(7), true, "")
+
+	restore := daemon.MockDenialsCollect(func(instanceName string) ([]*denials.Denial, error) {
+		return []*denials.Denial{
+			{Kind: denials.KindSeccomp, Snap: "foo", App: "app", Arch: "amd64", SyscallNumber: 1000},
+		}, nil
+	})
+	defer restore()
+	restore = daemon.MockDenialsSyscallResolver(func() (func(arch string, nr int) (string, error), error) {
+		return nil, errors.New("no snap-seccomp")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug/denials?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsUnexpected)
+	res := rsp.Result.([]daemon.DenialJSON)
+	c.Assert(res, check.HasLen, 1)
+	c.Check(res[0].Access, check.Equals, "syscall 1000 (amd64)")
+	c.Check(res[0].Revision, check.Equals, snap.R(7))
+}
+
+func (s *debugDenialsSuite) TestGetDenialsErrors(c *check.C) {
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(7), true, "")
+
+	req, err := http.NewRequest("GET", "/v2/debug/denials", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot get denials without a snap name")
+
+	req, err = http.NewRequest("GET", "/v2/debug/denials?snap=unknown", nil)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNotFound)
+
+	restore := daemon.MockDenialsCollect(func(instanceName string) ([]*denials.Denial, error) {
+		return nil, errors.New("cannot read journal: boom")
+	})
+	defer restore()
+
+	req, err = http.NewRequest("GET", "/v2/debug/denials?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsUnexpected)
+	c.Check(rspe.Status, check.Equals, 500)
+	c.Check(rspe.Message, check.Equals, "cannot collect denials: cannot read journal: boom")
+}
@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type policyExplanationJSON struct {
+	Plug                 interfaces.PlugRef  `json:"plug"`
+	Slot                 interfaces.SlotRef  `json:"slot"`
+	Interface            string              `json:"interface"`
+	Connect              *policyDecisionJSON `json:"connect,omitempty"`
+	ConnectUnchecked     bool                `json:"connect-unchecked,omitempty"`
+	AutoConnect          *policyDecisionJSON `json:"auto-connect,omitempty"`
+	AutoConnectCandidate bool                `json:"auto-connect-candidate,omitempty"`
+	Notes                []string            `json:"notes,omitempty"`
+}
+
+type policyDecisionJSON struct {
+	Allowed         bool                   `json:"allowed"`
+	Message         string                 `json:"message"`
+	Origin          string                 `json:"origin,omitempty"`
+	Snap            string                 `json:"snap,omitempty"`
+	Side            string                 `json:"side,omitempty"`
+	Deny            []policyConstraintJSON `json:"deny,omitempty"`
+	Allow           []policyConstraintJSON `json:"allow,omitempty"`
+	SlotsPerPlugAny bool                   `json:"slots-per-plug-any,omitempty"`
+}
+
+type policyConstraintJSON struct {
+	Matched  bool   `json:"matched"`
+	Mismatch string `json:"mismatch,omitempty"`
+}
+
+func policyDecision(expl *policy.Explanation) *policyDecisionJSON {
+	if expl == nil {
+		return nil
+	}
+	constraints := func(cexpls []policy.ConstraintsExplanation) []policyConstraintJSON {
+		if len(cexpls) == 0 {
+			return nil
+		}
+		res := make([]policyConstraintJSON, len(cexpls))
+		for i, cexpl := range cexpls {
+			res[i] = policyConstraintJSON{Matched: cexpl.Matched, Mismatch: cexpl.Mismatch}
+		}
+		return res
+	}
+	return &policyDecisionJSON{
+		Allowed:         expl.Allowed(),
+		Message:         expl.String(),
+		Origin:          expl.Origin,
+		Snap:            expl.Snap,
+		Side:            expl.Side,
+		Deny:            constraints(expl.Deny),
+		Allow:           constraints(expl.Allow),
+		SlotsPerPlugAny: expl.SlotsPerPlugAny,
+	}
+}
+
+var ifacestateExplainPolicy = (*ifacestate.InterfaceManager).ExplainPolicy
+
+func getInterfacePolicy(st *state.State, ifaceMgr *ifacestate.InterfaceManager, snapName, ifaceName string) Response {
+	snapName = ifacestate.RemapSnapFromRequest(snapName)
+	if snapName != "" {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, snapName, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				return SnapNotFound(snapName, err)
+			}
+			return InternalError("cannot access snap state: %v", err)
+		}
+	}
+
+	expls, err := ifacestateExplainPolicy(ifaceMgr, snapName, ifaceName)
+	if err != nil {
+		return InternalError("cannot explain interface policy: %v", err)
+	}
+	res := make([]policyExplanationJSON, 0, len(expls))
+	for _, expl := range expls {
+		res = append(res, policyExplanationJSON{
+			Plug:                 expl.Plug,
+			Slot:                 expl.Slot,
+			Interface:            expl.Interface,
+			Connect:              policyDecision(expl.Connect),
+			ConnectUnchecked:     expl.ConnectUnchecked,
+			AutoConnect:          policyDecision(expl.AutoConnect),
+			AutoConnectCandidate: expl.AutoConnectCandidate,
+			Notes:                expl.Notes,
+		})
+	}
+	return SyncResponse(res)
+}
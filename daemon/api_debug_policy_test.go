@@ -0,0 +1,112 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (s *postDebugSuite) TestGetInterfacePolicy(c *check.C) {
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "consumer", "bar", "v1", snap.R(1), true, "")
+
+	restore := daemon.MockIfacestateExplainPolicy(func(m *ifacestate.InterfaceManager, snapName, ifaceName string) ([]*ifacestate.PolicyExplanation, error) {
+		c.Check(snapName, check.Equals, "consumer")
+		c.Check(ifaceName, check.Equals, "test")
+		return []*ifacestate.PolicyExplanation{{
+			Plug:      interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+			Slot:      interfaces.SlotRef{Snap: "producer", Name: "slot"},
+			Interface: "test",
+			Connect: &policy.Explanation{
+				Kind:   "connection",
+				Origin: policy.OriginBaseDeclaration,
+				Side:   "slot",
+				Deny:   []policy.ConstraintsExplanation{{Mismatch: "constraint is false"}},
+				Allow:  []policy.ConstraintsExplanation{{Matched: true}},
+			},
+			AutoConnect: &policy.Explanation{
+				Kind:   "auto-connection",
+				Origin: policy.OriginSnapDeclaration,
+				Snap:   "producer",
+				Side:   "slot",
+				Allow:  []policy.ConstraintsExplanation{{Mismatch: "publisher id does not match"}},
+				Err:    errors.New(`auto-connection not allowed by slot rule of interface "test" for "producer" snap`),
+			},
+			Notes: []string{"a note"},
+		}}, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=interface-policy&snap=consumer&interface=test", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Result, check.DeepEquals, []daemon.PolicyExplanationJSON{{
+		Plug:      interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      interfaces.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Connect: &daemon.PolicyDecisionJSON{
+			Allowed: true,
+			Message: "connection allowed by slot rule of base-declaration",
+			Origin:  "base-declaration",
+			Side:    "slot",
+			Deny:    []daemon.PolicyConstraintJSON{{Mismatch: "constraint is false"}},
+			Allow:   []daemon.PolicyConstraintJSON{{Matched: true}},
+		},
+		AutoConnect: &daemon.PolicyDecisionJSON{
+			Message: `auto-connection not allowed by slot rule of interface "test" for "producer" snap`,
+			Origin:  "snap-declaration",
+			Snap:    "producer",
+			Side:    "slot",
+			Allow:   []daemon.PolicyConstraintJSON{{Mismatch: "publisher id does not match"}},
+		},
+		Notes: []string{"a note"},
+	}})
+}
+
+func (s *postDebugSuite) TestGetInterfacePolicyErrors(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=interface-policy&snap=unknown", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNotFound)
+
+	restore := daemon.MockIfacestateExplainPolicy(func(m *ifacestate.InterfaceManager, snapName, ifaceName string) ([]*ifacestate.PolicyExplanation, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	req, err = http.NewRequest("GET", "/v2/debug?aspect=interface-policy", nil)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 500)
+	c.Check(rspe.Message, check.Equals, "cannot explain interface policy: boom")
+}
@@ -124,6 +124,10 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return InternalError("cannot get refresh schedule: %s", err)
 	}
+	snapRefreshTimers, err := snapMgr.SnapRefreshTimers()
+	if err != nil {
+		return InternalError("cannot get snap refresh timers: %s", err)
+	}
 	users, err := auth.Users(st)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
 		return InternalError("cannot get user auth data: %s", err)
@@ -149,6 +153,16 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	} else {
 		refreshInfo.Schedule = refreshScheduleStr
 	}
+	if len(snapRefreshTimers) > 0 {
+		refreshInfo.Snaps = make(map[string]client.SnapRefreshInfo, len(snapRefreshTimers))
+		for name, t := range snapRefreshTimers {
+			refreshInfo.Snaps[name] = client.SnapRefreshInfo{
+				Timer: t.Timer,
+				Last:  formatRefreshTime(t.Last),
+				Next:  formatRefreshTime(t.Next),
+			}
+		}
+	}
 
 	m := map[string]any{
 		"series":         release.Series,
@@ -34,6 +34,7 @@ import (
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/dirs/dirstest"
@@ -45,6 +46,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/systemd"
 )
 
@@ -308,6 +310,41 @@ func (s *generalSuite) TestSysInfoLegacyRefresh(c *check.C) {
 	c.Check(rsp.Result, check.DeepEquals, expected)
 }
 
+func (s *generalSuite) TestSysInfoSnapRefreshTimers(c *check.C) {
+	s.expectSystemInfoReadAccess()
+	req, err := http.NewRequest("GET", "/v2/system-info", nil)
+	c.Assert(err, check.IsNil)
+
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v1", snap.R(10), true, "")
+	s.mkInstalledInState(c, d, "baz", "bar", "v1", snap.R(10), true, "")
+
+	last := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "refresh.snap-timer.foo", "mon,10:00-12:00")
+	tr.Set("core", "refresh.snap-timer.baz", "fri,8:00-9:00")
+	// not installed
+	tr.Set("core", "refresh.snap-timer.other", "fri,8:00-9:00")
+	tr.Commit()
+	st.Set("last-snap-timer-refresh", map[string]time.Time{"foo": last})
+	st.Unlock()
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil, actionIsExpected).ServeHTTP(rec, nil)
+	c.Check(rec.Code, check.Equals, 200)
+
+	var rsp struct {
+		Result client.SysInfo `json:"result"`
+	}
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), check.IsNil)
+	c.Check(rsp.Result.Refresh.Snaps, check.DeepEquals, map[string]client.SnapRefreshInfo{
+		"foo": {Timer: "mon,10:00-12:00", Last: last.Format(time.RFC3339)},
+		"baz": {Timer: "fri,8:00-9:00"},
+	})
+}
+
 func (s *generalSuite) testSysInfoBinOrigin(c *check.C, exp string, expErr string) {
 	s.expectSystemInfoReadAccess()
 	req, err := http.NewRequest("GET", "/v2/system-info", nil)
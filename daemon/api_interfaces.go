@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -148,6 +149,20 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	if len(a.Plugs) == 0 || len(a.Slots) == 0 {
 		return BadRequest("at least one plug and slot is required")
 	}
+	var expiry time.Time
+	if a.Expiry != "" {
+		if a.Action != "connect" {
+			return BadRequest("expiry can only be used with the connect action")
+		}
+		var err error
+		expiry, err = time.Parse(time.RFC3339, a.Expiry)
+		if err != nil {
+			return BadRequest("invalid expiry: %v", err)
+		}
+		if !expiry.After(time.Now()) {
+			return BadRequest("invalid expiry: %s is in the past", a.Expiry)
+		}
+	}
 
 	var summary string
 	var err error
@@ -198,8 +213,11 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 			var ts *state.TaskSet
 			affected = snapNamesFromConns([]*interfaces.ConnRef{connRef})
 			summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
-			ts, err = ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+			ts, err = ifacestate.ConnectWithOptions(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name, ifacestate.ConnectOptions{Expiry: expiry})
 			if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
+				if !expiry.IsZero() {
+					return BadRequest("cannot set expiry of existing connection %q, disconnect it first", connRef.ID())
+				}
 				change := newChange(st, connectSnapChangeKind, summary, nil, affected)
 				change.SetStatus(state.DoneStatus)
 				return AsyncResponse(nil, change.ID())
@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -177,6 +178,80 @@ func (s *interfacesSuite) TestConnectPlugSuccess(c *check.C) {
 	}})
 }
 
+func (s *interfacesSuite) TestConnectPlugWithExpiry(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Expiry: expiry.Format(time.RFC3339),
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st := d.Overlord().State()
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	st.Unlock()
+	c.Assert(chg, check.NotNil)
+
+	<-chg.Ready()
+
+	st.Lock()
+	defer st.Unlock()
+	c.Assert(chg.Err(), check.IsNil)
+
+	conns, err := ifacestate.ConnectionStates(st)
+	c.Assert(err, check.IsNil)
+	c.Assert(conns, check.HasLen, 1)
+	c.Check(conns["consumer:plug producer:slot"].Expiry.Equal(expiry), check.Equals, true)
+}
+
+func (s *interfacesSuite) TestConnectExpiryErrors(c *check.C) {
+	s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	for _, tc := range []struct {
+		action string
+		expiry string
+		err    string
+	}{
+		{"connect", "in an hour", `invalid expiry: parsing time "in an hour" .*`},
+		{"connect", "2020-01-01T00:00:00Z", "invalid expiry: 2020-01-01T00:00:00Z is in the past"},
+		{"disconnect", "2099-01-01T00:00:00Z", "expiry can only be used with the connect action"},
+	} {
+		action := &client.InterfaceAction{
+			Action: tc.action,
+			Expiry: tc.expiry,
+			Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+			Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+		}
+		text, err := json.Marshal(action)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Matches, tc.err)
+	}
+}
+
 func (s *interfacesSuite) TestConnectPlugFailureInterfaceMismatch(c *check.C) {
 	d := s.daemon(c)
 
@@ -307,6 +382,44 @@ func (s *interfacesSuite) TestConnectAlreadyConnected(c *check.C) {
 	st.Unlock()
 }
 
+func (s *interfacesSuite) TestConnectAlreadyConnectedWithExpiry(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, producerYaml)
+	s.mockSnap(c, consumerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	_, err := repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"auto": false,
+		},
+	})
+	st.Unlock()
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Expiry: time.Now().Add(time.Hour).Format(time.RFC3339),
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot set expiry of existing connection "consumer:plug producer:slot", disconnect it first`)
+}
+
 func (s *interfacesSuite) TestConnectPlugFailureNoSuchSlot(c *check.C) {
 	d := s.daemon(c)
 
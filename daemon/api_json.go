@@ -20,6 +20,8 @@
 package daemon
 
 import (
+	"time"
+
 	"github.com/snapcore/snapd/interfaces"
 )
 
@@ -60,6 +62,7 @@ type interfaceJSON struct {
 type interfaceAction struct {
 	Action string     `json:"action"`
 	Forget bool       `json:"forget,omitempty"`
+	Expiry string     `json:"expiry,omitempty"`
 	Plugs  []plugJSON `json:"plugs,omitempty"`
 	Slots  []slotJSON `json:"slots,omitempty"`
 }
@@ -74,6 +77,7 @@ type connectionJSON struct {
 	Gadget    bool               `json:"gadget,omitempty"`
 	SlotAttrs map[string]any     `json:"slot-attrs,omitempty"`
 	PlugAttrs map[string]any     `json:"plug-attrs,omitempty"`
+	Expiry    *time.Time         `json:"expiry,omitempty"`
 }
 
 // legacyConnectionsJSON aids in marshaling legacy connections into JSON.
@@ -36,6 +36,7 @@ var noticeReadInterfaces = map[state.NoticeType][]string{
 	state.SnapRunInhibitNotice:               {"snap-refresh-observe"},
 	state.InterfacesRequestsPromptNotice:     {"snap-interfaces-requests-control"},
 	state.InterfacesRequestsRuleUpdateNotice: {"snap-interfaces-requests-control"},
+	state.ConfdbChangeNotice:                 {"confdb"},
 }
 
 var (
@@ -44,14 +45,14 @@ var (
 		GET:         getNotices,
 		POST:        postNotices,
 		Actions:     []string{"add"},
-		ReadAccess:  interfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control"}},
+		ReadAccess:  interfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control", "confdb"}},
 		WriteAccess: openAccess{},
 	}
 
 	noticeCmd = &Command{
 		Path:       "/v2/notices/{id}",
 		GET:        getNotice,
-		ReadAccess: interfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control"}},
+		ReadAccess: interfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control", "confdb"}},
 	}
 )
 
@@ -105,6 +106,14 @@ func getNotices(c *Command, r *http.Request, user *auth.UserState) Response {
 	if !noticeTypesViewableBySnap(types, r) {
 		return Forbidden("snap cannot access specified notice types")
 	}
+	confdbViews, allConfdbViews, err := readableConfdbViews(c.d.state, r, user)
+	if err != nil {
+		return InternalError("cannot check access to confdb views: %v", err)
+	}
+	askedForConfdb := strutil.ListContains(strutil.MultiCommaSeparatedList(query["types"]), string(state.ConfdbChangeNotice))
+	if !allConfdbViews && len(confdbViews) == 0 && askedForConfdb {
+		return Forbidden("cannot access confdb-change notices without access to confdb views")
+	}
 
 	keys := strutil.MultiCommaSeparatedList(query["keys"])
 
@@ -119,6 +128,13 @@ func getNotices(c *Command, r *http.Request, user *auth.UserState) Response {
 		Keys:   keys,
 		After:  after,
 	}
+	if !allConfdbViews {
+		// confdb-change notices are public but clients may only see those
+		// of the views they can read
+		filter.KeysByType = map[state.NoticeType][]string{
+			state.ConfdbChangeNotice: confdbViews,
+		}
+	}
 
 	timeout, err := parseOptionalDuration(query.Get("timeout"))
 	if err != nil {
@@ -159,6 +175,33 @@ func getNotices(c *Command, r *http.Request, user *auth.UserState) Response {
 	return SyncResponse(notices)
 }
 
+// readableConfdbViews returns the IDs of the confdb views the client can read,
+// or true if it can read all of them. Like through the confdb API, root and
+// authenticated users can read all views, while snaps can only read the views
+// of their connected confdb plugs.
+func readableConfdbViews(st *state.State, r *http.Request, user *auth.UserState) (views []string, all bool, err error) {
+	ucred, ifaces, err := ucrednetGetWithInterfaces(r.RemoteAddr)
+	if err != nil {
+		return nil, false, err
+	}
+	if ucred.Socket != dirs.SnapSocket {
+		return nil, ucred.Uid == 0 || user != nil, nil
+	}
+	if !strutil.ListContains(ifaces, "confdb") {
+		return nil, false, nil
+	}
+
+	snapName, err := cgroupSnapNameFromPid(int(ucred.Pid))
+	if err != nil {
+		return nil, false, err
+	}
+
+	st.Lock()
+	defer st.Unlock()
+	views, err = confdbstateSnapViews(st, snapName)
+	return views, false, err
+}
+
 // Get the UID of the request. If the UID is not known, return an error.
 func uidFromRequest(r *http.Request) (uint32, error) {
 	cred, err := ucrednetGet(r.RemoteAddr)
@@ -331,6 +374,15 @@ func getNotice(c *Command, r *http.Request, user *auth.UserState) Response {
 	if !noticeTypesViewableBySnap([]state.NoticeType{notice.Type()}, r) {
 		return Forbidden("not allowed to access notice with id %q", noticeID)
 	}
+	if notice.Type() == state.ConfdbChangeNotice {
+		views, all, err := readableConfdbViews(c.d.state, r, user)
+		if err != nil {
+			return InternalError("cannot check access to confdb views: %v", err)
+		}
+		if !all && !strutil.ListContains(views, notice.Key()) {
+			return Forbidden("not allowed to access notice with id %q", noticeID)
+		}
+	}
 	return SyncResponse(notice)
 }
 
@@ -30,6 +30,7 @@ import (
 
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
@@ -46,7 +47,7 @@ type noticesSuite struct {
 func (s *noticesSuite) SetUpTest(c *C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectReadAccess(daemon.InterfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control"}})
+	s.expectReadAccess(daemon.InterfaceOpenAccess{Interfaces: []string{"snap-refresh-observe", "snap-interfaces-requests-control", "confdb"}})
 	s.expectWriteAccess(daemon.OpenAccess{})
 }
 
@@ -625,6 +626,135 @@ func (s *noticesSuite) TestNoticesWait(c *C) {
 	c.Check(n["key"], Equals, "foo")
 }
 
+func (s *noticesSuite) TestNoticesConfdbChange(c *C) {
+	s.daemon(c)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	addNotice(c, st, nil, state.WarningNotice, "danger", nil)
+	addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/setup-wifi", &state.AddNoticeOptions{
+		Data: map[string]string{"requests": "ssid", "transaction-id": "1"},
+	})
+	st.Unlock()
+
+	// root can read confdb views and so confdb-change notices
+	req, err := http.NewRequest("GET", "/v2/notices?types=confdb-change", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=0;socket=%s;", dirs.SnapdSocket)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	notices, ok := rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["type"], Equals, "confdb-change")
+	c.Check(n["key"], Equals, "acc/network/setup-wifi")
+	c.Check(n["last-data"], DeepEquals, map[string]any{"requests": "ssid", "transaction-id": "1"})
+
+	// as can authenticated users
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;", dirs.SnapdSocket)
+	rsp = s.syncReq(c, req, &auth.UserState{ID: 1}, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	notices, ok = rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	c.Check(notices, HasLen, 1)
+
+	// other users cannot request them
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 403)
+	c.Check(rspe.Message, Equals, "cannot access confdb-change notices without access to confdb views")
+
+	// and don't see them when not filtering by type
+	req, err = http.NewRequest("GET", "/v2/notices", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;", dirs.SnapdSocket)
+	rsp = s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	notices, ok = rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["type"], Equals, "warning")
+}
+
+func (s *noticesSuite) TestNoticesConfdbChangeSnapViews(c *C) {
+	s.daemon(c)
+
+	restore := daemon.MockCgroupSnapNameFromPid(func(pid int) (string, error) {
+		c.Check(pid, Equals, 100)
+		return "test-snap", nil
+	})
+	defer restore()
+	restore = daemon.MockConfdbstateSnapViews(func(_ *state.State, snapName string) ([]string, error) {
+		c.Check(snapName, Equals, "test-snap")
+		return []string{"acc/network/observe-wifi"}, nil
+	})
+	defer restore()
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	setupID, err := st.AddNotice(nil, state.ConfdbChangeNotice, "acc/network/setup-wifi", nil)
+	c.Assert(err, IsNil)
+	observeID, err := st.AddNotice(nil, state.ConfdbChangeNotice, "acc/network/observe-wifi", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	// snaps only see the notices of the views of their connected plugs
+	req, err := http.NewRequest("GET", "/v2/notices?types=confdb-change", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;iface=confdb;", dirs.SnapSocket)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+	notices, ok := rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["key"], Equals, "acc/network/observe-wifi")
+
+	// including when fetching a single notice
+	req, err = http.NewRequest("GET", "/v2/notices/"+setupID, nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;iface=confdb;", dirs.SnapSocket)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, Equals, 403)
+
+	req, err = http.NewRequest("GET", "/v2/notices/"+observeID, nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;iface=confdb;", dirs.SnapSocket)
+	rsp = s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+}
+
+func (s *noticesSuite) TestNoticesConfdbChangeWait(c *C) {
+	s.daemon(c)
+
+	st := s.d.Overlord().State()
+	go func() {
+		time.Sleep(testutil.HostScaledTimeout(50 * time.Millisecond))
+		st.Lock()
+		addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/setup-wifi", nil)
+		st.Unlock()
+
+		time.Sleep(testutil.HostScaledTimeout(50 * time.Millisecond))
+		st.Lock()
+		addNotice(c, st, nil, state.WarningNotice, "foo", nil)
+		st.Unlock()
+	}()
+
+	// clients that can't read confdb views keep waiting for other notices
+	timeout := testutil.HostScaledTimeout(5 * time.Second).String()
+	req, err := http.NewRequest("GET", "/v2/notices?timeout="+timeout, nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=1000;socket=%s;", dirs.SnapdSocket)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Check(rsp.Status, Equals, 200)
+
+	notices, ok := rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["type"], Equals, "warning")
+	c.Check(n["key"], Equals, "foo")
+}
+
 func (s *noticesSuite) TestNoticesTimeout(c *C) {
 	s.daemon(c)
 
@@ -0,0 +1,326 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sbom"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var sbomCmd = &Command{
+	Path:       "/v2/sbom",
+	GET:        getSBOM,
+	ReadAccess: openAccess{},
+}
+
+var sbomNow = time.Now
+
+// embeddedSBOMSuffixes are the suffixes of the files in the meta/
+// directory of a snap that are picked up as embedded bills of materials,
+// all the files in meta/sbom/ are picked up as well.
+var embeddedSBOMSuffixes = []string{".spdx", ".spdx.json", ".cdx.json", ".cdx.xml"}
+
+func getSBOM(c *Command, r *http.Request, user *auth.UserState) Response {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = sbom.FormatSPDX
+	case sbom.FormatSPDX, sbom.FormatCycloneDX:
+	default:
+		return BadRequest("invalid format %q, must be %q or %q", format, sbom.FormatSPDX, sbom.FormatCycloneDX)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	doc, mountDirs, err := sbomDocument(st)
+	st.Unlock()
+	if err != nil {
+		return InternalError("cannot build SBOM: %v", err)
+	}
+	doc.ToolVersion = c.d.Version
+
+	// looking for the embedded bills of materials can read files of any
+	// size, do it without holding the state lock
+	for i, pkg := range doc.Packages {
+		pkg.EmbeddedSBOMs = embeddedSBOMs(mountDirs[i])
+	}
+
+	res, err := sbom.Generate(doc, format)
+	if err != nil {
+		return InternalError("cannot build SBOM: %v", err)
+	}
+	return SyncResponse(res)
+}
+
+// sbomDocument gathers the installed snaps and components for the bill
+// of materials of the device, together with the mount directories of each
+// of the packages of the document.
+func sbomDocument(st *state.State) (doc *sbom.Document, mountDirs []string, err error) {
+	doc = &sbom.Document{
+		Name:    "snapd",
+		Created: sbomNow(),
+	}
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err == nil {
+		doc.Name = deviceCtx.Model().Model()
+	}
+
+	all, err := snapstate.All(st)
+	if err != nil {
+		return nil, nil, err
+	}
+	contentDeps, err := contentProviders(st)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(all))
+	for name, snapst := range all {
+		if snapst.IsInstalled() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	db := assertstate.DB(st)
+	for _, name := range names {
+		snapst := all[name]
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		pkg := &sbom.Package{
+			Name:      info.InstanceName(),
+			Type:      string(info.Type()),
+			Version:   info.Version,
+			Revision:  info.Revision.String(),
+			SnapID:    info.SnapID,
+			License:   info.License,
+			DependsOn: contentDeps[info.InstanceName()],
+		}
+		if base := snapBase(info); base != "" {
+			pkg.DependsOn = append([]string{base}, pkg.DependsOn...)
+		}
+		if info.SnapID != "" {
+			if pub, err := assertstate.PublisherStoreAccount(st, info.SnapID); err == nil {
+				pkg.Publisher = pub.DisplayName
+				if pkg.Publisher == "" {
+					pkg.Publisher = pub.Username
+				}
+			}
+			pkg.SHA3_384 = snapRevisionDigest(db, info)
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		mountDirs = append(mountDirs, info.MountDir())
+
+		compInfos, err := snapst.CurrentComponentInfos()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, ci := range compInfos {
+			cpkg := &sbom.Package{
+				Name:      ci.FullName(),
+				Snap:      info.InstanceName(),
+				Type:      string(ci.Type),
+				Version:   ci.Version(info.Version),
+				Revision:  ci.Revision.String(),
+				SnapID:    info.SnapID,
+				Publisher: pkg.Publisher,
+			}
+			if info.SnapID != "" {
+				cpkg.SHA3_384 = resourceRevisionDigest(db, info, ci)
+			}
+			doc.Packages = append(doc.Packages, cpkg)
+			mountDirs = append(mountDirs, snap.ComponentMountDir(ci.Component.ComponentName, ci.Revision, info.InstanceName()))
+		}
+	}
+	return doc, mountDirs, nil
+}
+
+// snapBase returns the name of the base of the snap, app snaps without a
+// declared base use core.
+func snapBase(info *snap.Info) string {
+	if info.Base != "" && info.Base != "none" {
+		return info.Base
+	}
+	if info.Base == "" && info.Type() == snap.TypeApp {
+		return "core"
+	}
+	return ""
+}
+
+// contentProviders maps snaps to the snaps providing content to them
+// through active connections of the content interface.
+func contentProviders(st *state.State) (map[string][]string, error) {
+	conns, err := ifacestate.ConnectionStates(st)
+	if err != nil {
+		return nil, err
+	}
+	providers := make(map[string][]string)
+	for id, conn := range conns {
+		if conn.Interface != "content" || !conn.Active() {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		plugSnap, slotSnap := connRef.PlugRef.Snap, connRef.SlotRef.Snap
+		if plugSnap == slotSnap || strutil.ListContains(providers[plugSnap], slotSnap) {
+			continue
+		}
+		providers[plugSnap] = append(providers[plugSnap], slotSnap)
+	}
+	for _, p := range providers {
+		sort.Strings(p)
+	}
+	return providers, nil
+}
+
+func snapRevisionDigest(db asserts.RODatabase, info *snap.Info) string {
+	as, err := db.FindMany(asserts.SnapRevisionType, map[string]string{
+		"snap-id":       info.SnapID,
+		"snap-revision": info.Revision.String(),
+		"provenance":    info.Provenance(),
+	})
+	if err != nil || len(as) != 1 {
+		return ""
+	}
+	return hexDigest(as[0].(*asserts.SnapRevision).SnapSHA3_384())
+}
+
+func resourceRevisionDigest(db asserts.RODatabase, info *snap.Info, ci *snap.ComponentInfo) string {
+	as, err := db.FindMany(asserts.SnapResourceRevisionType, map[string]string{
+		"snap-id":           info.SnapID,
+		"resource-name":     ci.Component.ComponentName,
+		"resource-revision": ci.Revision.String(),
+		"provenance":        ci.Provenance(),
+	})
+	if err != nil || len(as) != 1 {
+		return ""
+	}
+	return hexDigest(as[0].(*asserts.SnapResourceRevision).ResourceSHA3_384())
+}
+
+// hexDigest converts a digest as found in assertions to hex.
+func hexDigest(digest string) string {
+	b, err := base64.RawURLEncoding.DecodeString(digest)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// embeddedSBOMs returns the bills of materials shipped in the meta/
+// directory of the snap or component mounted at mountDir. Only regular files
+// are picked up, symlinks are ignored so that the digests of files outside of
+// the snap, possibly only readable by root, cannot be obtained.
+func embeddedSBOMs(mountDir string) []sbom.File {
+	metaDir := filepath.Join(mountDir, "meta")
+	var paths []string
+	if entries, err := readRealDir(metaDir); err == nil {
+		for _, e := range entries {
+			for _, suffix := range embeddedSBOMSuffixes {
+				if strings.HasSuffix(e.Name(), suffix) {
+					paths = append(paths, filepath.Join(metaDir, e.Name()))
+					break
+				}
+			}
+		}
+	}
+	if entries, err := readRealDir(filepath.Join(metaDir, "sbom")); err == nil {
+		for _, e := range entries {
+			paths = append(paths, filepath.Join(metaDir, "sbom", e.Name()))
+		}
+	}
+
+	var files []sbom.File
+	for _, p := range paths {
+		if !strings.HasPrefix(p, filepath.Clean(mountDir)+"/") {
+			continue
+		}
+		if fi, err := os.Lstat(p); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		digest, err := regularFileDigest(p)
+		if err != nil {
+			logger.Noticef("cannot compute digest of embedded SBOM %q: %v", p, err)
+			continue
+		}
+		files = append(files, sbom.File{Path: p, SHA256: hex.EncodeToString(digest)})
+	}
+	return files
+}
+
+// readRealDir reads the given directory, which must not be a symlink.
+func readRealDir(dir string) ([]os.DirEntry, error) {
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+	return os.ReadDir(dir)
+}
+
+// regularFileDigest returns the SHA256 digest of the given file, without
+// following symlinks.
+func regularFileDigest(path string) ([]byte, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("not a regular file")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
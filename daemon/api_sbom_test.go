@@ -0,0 +1,182 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/sbom"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&sbomSuite{})
+
+type sbomSuite struct {
+	apiBaseSuite
+}
+
+func (s *sbomSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.AddCleanup(daemon.MockSBOMNow(func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	}))
+}
+
+func (s *sbomSuite) mockSnaps(c *check.C) (fooSBOM, fooDigest string) {
+	d := s.daemon(c)
+
+	s.mkInstalledInState(c, d, "core22", "canonical", "20260901", snap.R(2000), true, "type: base\n")
+	s.mkInstalledInState(c, d, "themes", "", "1", snap.R(-1), true, `
+slots:
+  gtk-3-themes:
+    interface: content
+    content: gtk-3-themes
+    read: [$SNAP/share/themes]
+`)
+	foo := s.mkInstalledInState(c, d, "foo", "bar", "1.0", snap.R(10), true, `
+base: core22
+license: GPL-3.0 OR MIT
+plugs:
+  gtk-3-themes:
+    interface: content
+    target: $SNAP/data-dir/themes
+`)
+	fooSBOM = filepath.Join(foo.MountDir(), "meta", "sbom", "foo.cdx.json")
+	c.Assert(os.MkdirAll(filepath.Dir(fooSBOM), 0755), check.IsNil)
+	c.Assert(os.WriteFile(fooSBOM, []byte("{}"), 0644), check.IsNil)
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]any{
+		"foo:gtk-3-themes themes:gtk-3-themes": map[string]any{"interface": "content"},
+	})
+	st.Unlock()
+
+	content, err := os.ReadFile(foo.MountFile())
+	c.Assert(err, check.IsNil)
+	h := sha3.Sum384(content)
+	return fooSBOM, hex.EncodeToString(h[:])
+}
+
+func (s *sbomSuite) TestSBOMSPDX(c *check.C) {
+	fooSBOM, fooDigest := s.mockSnaps(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+
+	doc, ok := rsp.Result.(*sbom.SPDXDocument)
+	c.Assert(ok, check.Equals, true)
+	c.Check(doc.CreationInfo.Created, check.Equals, "2026-10-19T12:00:00Z")
+	c.Assert(doc.Packages, check.HasLen, 3)
+
+	foo := doc.Packages[1]
+	c.Check(foo.Name, check.Equals, "foo")
+	c.Check(foo.VersionInfo, check.Equals, "1.0")
+	c.Check(foo.Supplier, check.Equals, "Organization: Bar")
+	c.Check(foo.LicenseDeclared, check.Equals, "GPL-3.0 OR MIT")
+	c.Check(foo.Checksums, check.DeepEquals, []sbom.SPDXChecksum{{Algorithm: "SHA3-384", ChecksumValue: fooDigest}})
+	c.Check(foo.ExternalRefs, check.DeepEquals, []sbom.SPDXExternalRef{
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "snap-id", ReferenceLocator: "foo-id"},
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "snap-revision", ReferenceLocator: "10"},
+		{ReferenceCategory: "OTHER", ReferenceType: "sbom", ReferenceLocator: fooSBOM},
+	})
+
+	// the local snap has no digest
+	c.Check(doc.Packages[2].Name, check.Equals, "themes")
+	c.Check(doc.Packages[2].Checksums, check.HasLen, 0)
+
+	// the dependency of themes on core is left out as core is not installed
+	c.Check(doc.Relationships, check.DeepEquals, []sbom.SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Snap-core22"},
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Snap-foo"},
+		{SPDXElementID: "SPDXRef-Snap-foo", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Snap-core22"},
+		{SPDXElementID: "SPDXRef-Snap-foo", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Snap-themes"},
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Snap-themes"},
+	})
+}
+
+func (s *sbomSuite) TestSBOMCycloneDX(c *check.C) {
+	fooSBOM, _ := s.mockSnaps(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=cyclonedx", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+
+	doc, ok := rsp.Result.(*sbom.CycloneDXDocument)
+	c.Assert(ok, check.Equals, true)
+	c.Check(doc.Metadata.Timestamp, check.Equals, "2026-10-19T12:00:00Z")
+	c.Assert(doc.Components, check.HasLen, 3)
+	c.Check(doc.Components[1].Name, check.Equals, "foo")
+	c.Check(doc.Components[1].Publisher, check.Equals, "Bar")
+	c.Check(doc.Components[1].ExternalReferences, check.DeepEquals, []sbom.CycloneDXExternalReference{{
+		Type: "bom",
+		URL:  "file://" + fooSBOM,
+		// sha256 of "{}"
+		Hashes: []sbom.CycloneDXHash{{Alg: "SHA-256", Content: "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"}},
+	}})
+	c.Check(doc.Dependencies[1], check.DeepEquals, sbom.CycloneDXDependency{
+		Ref:       "snap:foo",
+		DependsOn: []string{"snap:core22", "snap:themes"},
+	})
+}
+
+func (s *sbomSuite) TestSBOMInvalidFormat(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=swid", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid format "swid", must be "spdx" or "cyclonedx"`)
+}
+
+func (s *sbomSuite) TestSBOMSymlinksIgnored(c *check.C) {
+	fooSBOM, _ := s.mockSnaps(c)
+
+	secret := filepath.Join(c.MkDir(), "secret")
+	c.Assert(os.WriteFile(secret, []byte("secret"), 0600), check.IsNil)
+	metaDir := filepath.Dir(filepath.Dir(fooSBOM))
+	c.Assert(os.Symlink(secret, filepath.Join(metaDir, "sbom", "secret.cdx.json")), check.IsNil)
+	c.Assert(os.Symlink(secret, filepath.Join(metaDir, "secret.spdx")), check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/sbom?format=cyclonedx", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+
+	// only the regular file is referenced
+	doc, ok := rsp.Result.(*sbom.CycloneDXDocument)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(doc.Components, check.HasLen, 3)
+	c.Assert(doc.Components[1].ExternalReferences, check.HasLen, 1)
+	c.Check(doc.Components[1].ExternalReferences[0].URL, check.Equals, "file://"+fooSBOM)
+}
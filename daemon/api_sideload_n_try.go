@@ -44,6 +44,8 @@ import (
 	"github.com/snapcore/snapd/overlord/swfeats"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapbundle"
+	"github.com/snapcore/snapd/snap/snapdelta"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/strutil"
 )
@@ -144,6 +146,17 @@ func (f *Form) GetSnapFiles() ([]*uploadedContainer, *apiError) {
 type sideloadFlags struct {
 	snapstate.Flags
 	dangerousOK bool
+	// assertDB, if set, is the database the uploads are verified against
+	// instead of the system assertion database.
+	assertDB asserts.RODatabase
+}
+
+// db returns the database that the uploads are verified against.
+func (f *sideloadFlags) db(st *state.State) asserts.RODatabase {
+	if f.assertDB != nil {
+		return f.assertDB
+	}
+	return assertstate.DB(st)
 }
 
 func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boundary string, user *auth.UserState) Response {
@@ -209,14 +222,70 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 		return errRsp
 	}
 
+	isBundle := len(form.Values["action"]) > 0 && form.Values["action"][0] == "install-bundle"
+	if isBundle && len(snapFiles) != 1 {
+		return BadRequest("cannot install more than one bundle at a time")
+	}
+
+	var deltaFrom string
+	if len(form.Values["delta-from"]) > 0 {
+		if len(snapFiles) != 1 {
+			return BadRequest("cannot install more than one delta at a time")
+		}
+		deltaFrom = form.Values["delta-from"][0]
+	}
+
 	st := c.d.overlord.State()
+
+	var rebuilt *rebuiltDelta
+	if deltaFrom != "" {
+		rebuilt, errRsp = rebuildDelta(st, snapFiles[0], deltaFrom, form.Values["assertions"], sideloadFlags)
+		if errRsp != nil {
+			return errRsp
+		}
+		defer func() {
+			if !strutil.ListContains(pathsToNotRemove, rebuilt.path) {
+				os.Remove(rebuilt.path)
+			}
+		}()
+	}
+
+	var bundle *extractedBundle
+	if isBundle {
+		bundle, errRsp = extractBundle(snapFiles[0], sideloadFlags)
+		if errRsp != nil {
+			return errRsp
+		}
+		defer func() {
+			if bundle != nil {
+				bundle.remove()
+			}
+		}()
+	}
+
 	st.Lock()
 	defer st.Unlock()
 
 	var chg *state.Change
-	if len(snapFiles) > 1 {
+	switch {
+	case rebuilt != nil:
+		chg, errRsp = sideloadDelta(st, snapFiles[0], deltaFrom, rebuilt, sideloadFlags, user)
+		if errRsp == nil {
+			// the change is in charge of the rebuilt snap, the delta
+			// is removed with the form
+			snapFiles[0].tmpPath = rebuilt.path
+		}
+	case bundle != nil:
+		chg, errRsp = sideloadBundle(ctx, st, snapFiles[0], bundle, sideloadFlags, user)
+		if errRsp == nil {
+			// the change is in charge of the files extracted from the
+			// bundle, the bundle itself is removed with the form
+			bundle = nil
+			snapFiles = nil
+		}
+	case len(snapFiles) > 1:
 		chg, errRsp = sideloadManySnaps(ctx, st, snapFiles, sideloadFlags, user)
-	} else {
+	default:
 		chg, errRsp = sideloadSnap(ctx, st, snapFiles[0], sideloadFlags)
 	}
 	if errRsp != nil {
@@ -423,7 +492,19 @@ func sideloadManySnaps(ctx context.Context, st *state.State, uploads []*uploaded
 		return nil, err
 	}
 
-	snapNames := make([]string, 0, len(slInfo.snaps))
+	msg := multiPathInstallMessage(slInfo)
+	snapNames, apiData := sideloadAPIData(slInfo)
+
+	chg := newChange(st, installSnapChangeKind, msg, tss, snapNames)
+	chg.Set("api-data", apiData)
+
+	return chg, nil
+}
+
+// sideloadAPIData returns the names of the sideloaded snaps and the api-data
+// for the change sideloading them.
+func sideloadAPIData(slInfo *sideloadedInfo) (snapNames []string, apiData map[string]any) {
+	snapNames = make([]string, 0, len(slInfo.snaps))
 	snapToComps := make(map[string][]string, len(slInfo.components))
 	for _, sn := range slInfo.snaps {
 		snapName := sn.info.RealName
@@ -443,10 +524,7 @@ func sideloadManySnaps(ctx context.Context, st *state.State, uploads []*uploaded
 		snapToComps[ci.sideInfo.Component.SnapName] = append(snapToComps[ci.sideInfo.Component.SnapName], ci.sideInfo.Component.ComponentName)
 	}
 
-	msg := multiPathInstallMessage(slInfo)
-
-	chg := newChange(st, installSnapChangeKind, msg, tss, snapNames)
-	apiData := make(map[string]any, 0)
+	apiData = make(map[string]any, 0)
 
 	if len(snapNames) > 0 {
 		apiData["snap-names"] = snapNames
@@ -455,9 +533,8 @@ func sideloadManySnaps(ctx context.Context, st *state.State, uploads []*uploaded
 	if len(snapToComps) > 0 {
 		apiData["components"] = snapToComps
 	}
-	chg.Set("api-data", apiData)
 
-	return chg, nil
+	return snapNames, apiData
 }
 
 func multiPathInstallMessage(sli *sideloadedInfo) string {
@@ -615,6 +692,272 @@ func sideloadSnap(_ context.Context, st *state.State, upload *uploadedContainer,
 	return chg, nil
 }
 
+var snapdeltaApply = snapdelta.Apply
+
+// rebuiltDelta is a snap rebuilt from an uploaded delta.
+type rebuiltDelta struct {
+	path   string
+	digest string
+	size   uint64
+	// assertions holds the assertions uploaded along with the delta.
+	assertions *asserts.Batch
+}
+
+// rebuildDelta rebuilds a snap from the uploaded delta and the current
+// revision of the installed snap it applies to, and hashes it. This can take
+// a long time for large snaps, so it's done without holding the state lock,
+// which must not be held by the caller.
+func rebuildDelta(st *state.State, upload *uploadedContainer, deltaFrom string, assertions []string, flags sideloadFlags) (_ *rebuiltDelta, apiErr *apiError) {
+	if err := snap.ValidateInstanceName(deltaFrom); err != nil {
+		return nil, BadRequest(err.Error())
+	}
+	if flags.dangerousOK {
+		return nil, BadRequest("cannot install a delta in dangerous mode, the rebuilt snap must be verified")
+	}
+
+	batch := asserts.NewBatch(nil)
+	for _, stream := range assertions {
+		if _, err := batch.AddStream(strings.NewReader(stream)); err != nil {
+			return nil, BadRequest("cannot decode assertions uploaded with delta %q: %v", upload.filename, err)
+		}
+	}
+
+	st.Lock()
+	current, err := installedSnapInfo(st, deltaFrom)
+	st.Unlock()
+	if err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, SnapNotInstalled(deltaFrom, fmt.Errorf("snap %q is not installed", deltaFrom))
+		}
+		return nil, InternalError("cannot retrieve information for %q: %v", deltaFrom, err)
+	}
+
+	rebuilt, err := os.CreateTemp(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*")
+	if err != nil {
+		return nil, InternalError("cannot create temp file for the rebuilt snap: %v", err)
+	}
+	rebuilt.Close()
+	defer func() {
+		if apiErr != nil {
+			os.Remove(rebuilt.Name())
+		}
+	}()
+
+	if err := snapdeltaApply(current.MountFile(), upload.tmpPath, rebuilt.Name()); err != nil {
+		return nil, BadRequest("cannot rebuild snap %q from delta %q: %v", deltaFrom, upload.filename, err)
+	}
+
+	digest, size, err := asserts.SnapFileSHA3_384(rebuilt.Name())
+	if err != nil {
+		return nil, InternalError("cannot hash snap %q rebuilt from delta %q: %v", deltaFrom, upload.filename, err)
+	}
+
+	return &rebuiltDelta{
+		path:       rebuilt.Name(),
+		digest:     digest,
+		size:       size,
+		assertions: batch,
+	}, nil
+}
+
+// sideloadDelta installs a snap rebuilt with rebuildDelta. The rebuilt snap
+// must match a snap-revision assertion, even in devmode, either already known
+// or uploaded along with the delta. The uploaded assertions are only added
+// once the change is created.
+func sideloadDelta(st *state.State, upload *uploadedContainer, deltaFrom string, rebuilt *rebuiltDelta, flags sideloadFlags, user *auth.UserState) (*state.Change, *apiError) {
+	deviceCtx, err := snapstate.DevicePastSeeding(st, nil)
+	if err != nil {
+		return nil, InternalError(err.Error())
+	}
+
+	db, err := stageAssertions(st, rebuilt.assertions)
+	if err != nil {
+		return nil, BadRequest("cannot add assertions uploaded with delta %q: %v", upload.filename, err)
+	}
+
+	si, err := snapasserts.DeriveSideInfoFromDigestAndSize(rebuilt.path, rebuilt.digest, rebuilt.size, deviceCtx.Model(), db)
+	if err != nil {
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, BadRequest("cannot find signatures with metadata for snap %q rebuilt from delta %q", deltaFrom, upload.filename)
+		}
+		return nil, BadRequest(err.Error())
+	}
+	if si.RealName != snap.InstanceSnap(deltaFrom) {
+		return nil, BadRequest("snap %q rebuilt from delta %q is for snap %q", deltaFrom, upload.filename, si.RealName)
+	}
+
+	var userID int
+	if user != nil {
+		userID = user.ID
+	}
+	tset, err := snapstateInstallPathWithDeviceContext(st, si, rebuilt.path, deltaFrom, nil, userID, flags.Flags, nil, deviceCtx, "")
+	if err != nil {
+		return nil, errToResponse(err, []string{deltaFrom}, InternalError, "cannot install snap rebuilt from delta: %v")
+	}
+
+	if err := assertstate.AddBatch(st, rebuilt.assertions, &asserts.CommitOptions{Precheck: true}); err != nil {
+		return nil, BadRequest("cannot add assertions uploaded with delta %q: %v", upload.filename, err)
+	}
+
+	msg := fmt.Sprintf(i18n.G("Install %q snap from delta %q"), deltaFrom, upload.filename)
+	chg := newChange(st, installSnapChangeKind, msg, []*state.TaskSet{tset}, []string{deltaFrom})
+	chg.Set("api-data", map[string]any{
+		"snap-name":  deltaFrom,
+		"snap-names": []string{deltaFrom},
+	})
+
+	return chg, nil
+}
+
+// stageAssertions returns a database holding the assertions of the given
+// batch on top of the system assertion database, so that uploads can be
+// verified against them before they are added to the system.
+func stageAssertions(st *state.State, batch *asserts.Batch) (asserts.RODatabase, error) {
+	db := assertstate.TemporaryDB(st)
+	if err := batch.CommitTo(db, &asserts.CommitOptions{Precheck: true}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+var snapbundleExtract = snapbundle.Extract
+
+// extractedBundle holds the content of an uploaded bundle.
+type extractedBundle struct {
+	manifest *snapbundle.Manifest
+	// uploads holds the snaps and components extracted from the bundle.
+	uploads []*uploadedContainer
+	// assertions holds the assertions carried by the bundle.
+	assertions *asserts.Batch
+}
+
+// remove removes the snaps and components extracted from the bundle.
+func (b *extractedBundle) remove() {
+	for _, upload := range b.uploads {
+		os.Remove(upload.tmpPath)
+	}
+}
+
+// extractBundle extracts the snaps, components and assertions from the
+// uploaded bundle. Bundles can be very large, so this is done without holding
+// the state lock.
+func extractBundle(upload *uploadedContainer, flags sideloadFlags) (_ *extractedBundle, apiErr *apiError) {
+	if flags.dangerousOK {
+		return nil, BadRequest("cannot install a bundle in dangerous mode, its content must be verified")
+	}
+
+	f, err := os.Open(upload.tmpPath)
+	if err != nil {
+		return nil, InternalError("cannot open bundle: %v", err)
+	}
+	defer f.Close()
+
+	manifest, paths, err := snapbundleExtract(f, func(name string) (*os.File, error) {
+		return os.CreateTemp(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*")
+	})
+	if err != nil {
+		return nil, BadRequest("cannot extract bundle %q: %v", upload.filename, err)
+	}
+	defer func() {
+		// the assertions are not needed once they are read
+		for _, sn := range manifest.Snaps {
+			os.Remove(paths[sn.Assertions])
+		}
+		if apiErr != nil {
+			for _, path := range paths {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	bundle := &extractedBundle{
+		manifest:   manifest,
+		assertions: asserts.NewBatch(nil),
+	}
+	for _, sn := range manifest.Snaps {
+		if err := addAssertionsFromFile(bundle.assertions, paths[sn.Assertions]); err != nil {
+			return nil, BadRequest("cannot read assertions for snap %q from bundle %q: %v", sn.Name, upload.filename, err)
+		}
+		bundle.uploads = append(bundle.uploads, &uploadedContainer{
+			filename: sn.File,
+			tmpPath:  paths[sn.File],
+		})
+		for _, comp := range sn.Components {
+			bundle.uploads = append(bundle.uploads, &uploadedContainer{
+				filename: comp.File,
+				tmpPath:  paths[comp.File],
+			})
+		}
+	}
+
+	return bundle, nil
+}
+
+// sideloadBundle installs the snaps and components extracted from the
+// uploaded bundle with extractBundle. They are verified against the
+// assertions carried by the bundle, which are only added once the change is
+// created. All the snaps are installed in a single transaction.
+func sideloadBundle(ctx context.Context, st *state.State, upload *uploadedContainer, bundle *extractedBundle, flags sideloadFlags, user *auth.UserState) (*state.Change, *apiError) {
+	db, err := stageAssertions(st, bundle.assertions)
+	if err != nil {
+		return nil, BadRequest("cannot add assertions from bundle %q: %v", upload.filename, err)
+	}
+	flags.assertDB = db
+
+	slInfo, apiErr := sideloadSnapsInfo(st, bundle.uploads, flags)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	for _, sn := range bundle.manifest.Snaps {
+		var found bool
+		for _, sideloaded := range slInfo.snaps {
+			if sideloaded.origPath != sn.File {
+				continue
+			}
+			if sideloaded.info.SnapName() != sn.Name || sideloaded.info.Revision != sn.Revision {
+				return nil, BadRequest("snap file %q in bundle %q is revision %s of snap %q, expected revision %s of snap %q",
+					sn.File, upload.filename, sideloaded.info.Revision, sideloaded.info.SnapName(), sn.Revision, sn.Name)
+			}
+			found = true
+		}
+		if !found {
+			return nil, BadRequest("file %q in bundle %q is not a snap", sn.File, upload.filename)
+		}
+	}
+
+	var userID int
+	if user != nil {
+		userID = user.ID
+	}
+	flags.Transaction = client.TransactionAllSnaps
+	tss, apiErr := sideloadTaskSets(ctx, st, slInfo, userID, flags.Flags)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if err := assertstate.AddBatch(st, bundle.assertions, &asserts.CommitOptions{Precheck: true}); err != nil {
+		return nil, BadRequest("cannot add assertions from bundle %q: %v", upload.filename, err)
+	}
+
+	snapNames, apiData := sideloadAPIData(slInfo)
+	msg := fmt.Sprintf(i18n.G("Install snaps %s from bundle %q"), strutil.Quoted(snapNames), upload.filename)
+	chg := newChange(st, installSnapChangeKind, msg, tss, snapNames)
+	chg.Set("api-data", apiData)
+
+	return chg, nil
+}
+
+func addAssertionsFromFile(batch *asserts.Batch, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = batch.AddStream(f)
+	return err
+}
+
 func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string, flags sideloadFlags, model *asserts.Model) (*snap.Info, *apiError) {
 	if flags.dangerousOK {
 		info, err := unsafeReadSnapInfo(tempPath)
@@ -625,7 +968,7 @@ func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string
 		return info, nil
 	}
 
-	si, err := snapasserts.DeriveSideInfo(tempPath, model, assertstate.DB(st))
+	si, err := snapasserts.DeriveSideInfo(tempPath, model, flags.db(st))
 	if err != nil {
 		if !errors.Is(err, &asserts.NotFoundError{}) {
 			return nil, BadRequest(err.Error())
@@ -722,7 +1065,7 @@ func readComponentInfoAndDeriveSideInfo(
 		return nil, nil, apiErr
 	}
 
-	db := assertstate.DB(st)
+	db := flags.db(st)
 
 	csi, err := snapasserts.DeriveComponentSideInfo(cref.ComponentName, upload.tmpPath, info, model, db)
 	if err != nil {
@@ -749,7 +1092,7 @@ func readComponentInfoAndDeriveSideInfo(
 	// revision. installing via snapstate checks this too, but we might as well
 	// fail early.
 	if !flags.DevMode {
-		if _, err := assertstate.SnapResourcePair(st, csi, info); err != nil {
+		if err := findSnapResourcePair(db, csi, info); err != nil {
 			return nil, nil, MissingSnapResourcePair(csi, info.Revision)
 		}
 	}
@@ -768,6 +1111,19 @@ func readComponentInfoAndDeriveSideInfo(
 	return compInfo, info, nil
 }
 
+// findSnapResourcePair checks that the given database holds the
+// snap-resource-pair assertion for the given component and snap revision.
+func findSnapResourcePair(db asserts.RODatabase, csi *snap.ComponentSideInfo, info *snap.Info) error {
+	_, err := db.Find(asserts.SnapResourcePairType, map[string]string{
+		"snap-id":           info.SnapID,
+		"resource-name":     csi.Component.ComponentName,
+		"resource-revision": csi.Revision.String(),
+		"snap-revision":     info.Revision.String(),
+		"provenance":        info.Provenance(),
+	})
+	return err
+}
+
 func readComponentInfoDangerous(
 	upload *uploadedContainer,
 	matchingSnap func(instanceName string, cref naming.ComponentRef) (*snap.Info, *apiError),
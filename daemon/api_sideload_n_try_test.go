@@ -44,6 +44,7 @@ import (
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
@@ -52,6 +53,7 @@ import (
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapbundle"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/strutil"
@@ -2462,3 +2464,505 @@ func (s *sideloadSuite) TestSideloadSnapInvalidTransaction(c *check.C) {
 	rspe := s.errorReq(c, req, nil, actionIsExpected)
 	c.Assert(rspe.Message, check.Matches, `transaction must be either "per-snap" or "all-snaps"`)
 }
+
+func (s *sideloadSuite) sideloadDeltaBody(deltaFrom string, extra string) string {
+	return "----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap\"; filename=\"foo_2.xdelta3\"\r\n" +
+		"\r\n" +
+		"delta\r\n" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap-path\"\r\n" +
+		"\r\n" +
+		"a/b/foo_2.xdelta3\r\n" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"delta-from\"\r\n" +
+		"\r\n" +
+		deltaFrom + "\r\n" +
+		"----hello--\r\n" + extra
+}
+
+func (s *sideloadSuite) mockInstalledForDelta(c *check.C, st *state.State) *snap.Info {
+	st.Lock()
+	defer st.Unlock()
+	si := &snap.SideInfo{
+		RealName: "foo",
+		Revision: snap.R(1),
+		SnapID:   snaptest.AssertedSnapID("foo"),
+	}
+	info := snaptest.MockSnap(c, "name: foo\nversion: 1", si)
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromRevisionSideInfos(
+			[]*sequence.RevisionSideState{sequence.NewRevisionSideState(si, nil)},
+		),
+		Current: snap.R(1),
+	})
+	return info
+}
+
+func (s *sideloadSuite) TestSideloadDelta(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+	installed := s.mockInstalledForDelta(c, st)
+
+	// the snap rebuilt from the delta is asserted
+	rebuiltRef := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 2\n", nil)
+	rebuiltContent, err := os.ReadFile(rebuiltRef)
+	c.Assert(err, check.IsNil)
+	snapDecl, snapRev := s.makeSnapAssertions(c, "foo", rebuiltRef)
+	st.Lock()
+	assertstatetest.AddMany(st, s.StoreSigning.StoreAccountKey(""), snapDecl, snapRev)
+	st.Unlock()
+
+	var deltaPath string
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		c.Check(sourcePath, check.Equals, installed.MountFile())
+		c.Check(delta, testutil.FileEquals, "delta")
+		deltaPath = delta
+		return os.WriteFile(targetPath, rebuiltContent, 0600)
+	})()
+
+	var rebuiltPath string
+	defer daemon.MockSnapstateInstallPathWithDeviceContext(func(st *state.State, si *snap.SideInfo, path, name string, opts *snapstate.RevisionOptions, userID int, flags snapstate.Flags, prqt snapstate.PrereqTracker, deviceCtx snapstate.DeviceContext, fromChange string) (*state.TaskSet, error) {
+		c.Check(si, check.DeepEquals, &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   snaptest.AssertedSnapID("foo"),
+			Revision: snap.R(1),
+		})
+		c.Check(name, check.Equals, "foo")
+		c.Check(flags, check.Equals, snapstate.Flags{RemoveSnapPath: true, Transaction: client.TransactionPerSnap})
+		c.Check(deviceCtx, check.NotNil)
+		c.Check(path, testutil.FileEquals, rebuiltContent)
+		rebuiltPath = path
+		return state.NewTaskSet(), nil
+	})()
+
+	req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(s.sideloadDeltaBody("foo", "")))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "install-snap")
+	c.Check(chg.Summary(), check.Equals, `Install "foo" snap from delta "a/b/foo_2.xdelta3"`)
+	var apiData map[string]any
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData, check.DeepEquals, map[string]any{
+		"snap-name":  "foo",
+		"snap-names": []any{"foo"},
+	})
+
+	// the rebuilt snap is handed to the change, the delta is removed
+	c.Check(rebuiltPath, testutil.FilePresent)
+	c.Check(deltaPath, testutil.FileAbsent)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaNoAssertions(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	s.mockInstalledForDelta(c, d.Overlord().State())
+
+	var rebuiltPath string
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		rebuiltPath = targetPath
+		return os.WriteFile(targetPath, []byte("rebuilt snap"), 0600)
+	})()
+
+	// devmode does not allow missing assertions for deltas
+	body := s.sideloadDeltaBody("foo", "Content-Disposition: form-data; name=\"devmode\"\r\n\r\ntrue\r\n----hello--\r\n")
+	req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot find signatures with metadata for snap "foo" rebuilt from delta "a/b/foo_2.xdelta3"`)
+	c.Check(rebuiltPath, testutil.FileAbsent)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaApplyFails(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	s.mockInstalledForDelta(c, d.Overlord().State())
+
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		return errors.New("invalid delta")
+	})()
+
+	req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(s.sideloadDeltaBody("foo", "")))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Message, check.Equals, `cannot rebuild snap "foo" from delta "a/b/foo_2.xdelta3": invalid delta`)
+
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+	c.Assert(err, check.IsNil)
+	c.Check(matches, check.HasLen, 0)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaErrors(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		c.Fatalf("unexpected delta application")
+		return nil
+	})()
+
+	for _, t := range []struct {
+		deltaFrom string
+		extra     string
+		status    int
+		err       string
+	}{
+		{"foo", "", 400, `snap "foo" is not installed`},
+		{"foo_", "", 400, `invalid instance key: ""`},
+		{"foo", "Content-Disposition: form-data; name=\"dangerous\"\r\n\r\ntrue\r\n----hello--\r\n", 400, `cannot install a delta in dangerous mode, the rebuilt snap must be verified`},
+	} {
+		req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(s.sideloadDeltaBody(t.deltaFrom, t.extra)))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, t.status)
+		c.Check(rspe.Message, check.Equals, t.err)
+	}
+}
+
+// mockDeltaAssertions returns the content of a snap rebuilt from a delta,
+// along with its assertions as uploaded with the delta.
+func (s *sideloadSuite) mockDeltaAssertions(c *check.C) (content []byte, form string, snapRev asserts.Assertion) {
+	rebuiltRef := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 2\n", nil)
+	content, err := os.ReadFile(rebuiltRef)
+	c.Assert(err, check.IsNil)
+	snapDecl, snapRev := s.makeSnapAssertions(c, "foo", rebuiltRef)
+
+	var stream bytes.Buffer
+	enc := asserts.NewEncoder(&stream)
+	for _, a := range []asserts.Assertion{s.StoreSigning.StoreAccountKey(""), snapDecl, snapRev} {
+		c.Assert(enc.Encode(a), check.IsNil)
+	}
+
+	form = "Content-Disposition: form-data; name=\"assertions\"\r\n" +
+		"\r\n" +
+		stream.String() + "\r\n" +
+		"----hello--\r\n"
+	return content, form, snapRev
+}
+
+func (s *sideloadSuite) TestSideloadDeltaWithAssertions(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+	s.mockInstalledForDelta(c, st)
+
+	rebuiltContent, form, snapRev := s.mockDeltaAssertions(c)
+
+	checkNotAdded := func() {
+		_, err := snapRev.Ref().Resolve(assertstate.DB(st).Find)
+		c.Check(errors.Is(err, &asserts.NotFoundError{}), check.Equals, true)
+	}
+
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		// the snap is rebuilt without holding the state lock
+		unlocked := make(chan struct{})
+		go func() {
+			st.Lock()
+			defer st.Unlock()
+			checkNotAdded()
+			close(unlocked)
+		}()
+		select {
+		case <-unlocked:
+		case <-time.After(testutil.HostScaledTimeout(5 * time.Second)):
+			c.Errorf("state is locked while rebuilding the snap")
+		}
+		return os.WriteFile(targetPath, rebuiltContent, 0600)
+	})()
+
+	defer daemon.MockSnapstateInstallPathWithDeviceContext(func(st *state.State, si *snap.SideInfo, path, name string, opts *snapstate.RevisionOptions, userID int, flags snapstate.Flags, prqt snapstate.PrereqTracker, deviceCtx snapstate.DeviceContext, fromChange string) (*state.TaskSet, error) {
+		c.Check(si, check.DeepEquals, &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   snaptest.AssertedSnapID("foo"),
+			Revision: snap.R(1),
+		})
+		// the assertions are only added along with the change
+		checkNotAdded()
+		return state.NewTaskSet(), nil
+	})()
+
+	req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(s.sideloadDeltaBody("foo", form)))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Change(rsp.Change), check.NotNil)
+	_, err = snapRev.Ref().Resolve(assertstate.DB(st).Find)
+	c.Check(err, check.IsNil)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaAssertionsNotAddedOnError(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+	s.mockInstalledForDelta(c, st)
+
+	content, form, snapRev := s.mockDeltaAssertions(c)
+
+	var rebuiltContent []byte
+	defer daemon.MockSnapdeltaApply(func(sourcePath, delta, targetPath string) error {
+		return os.WriteFile(targetPath, rebuiltContent, 0600)
+	})()
+
+	installErr := errors.New("cannot install")
+	defer daemon.MockSnapstateInstallPathWithDeviceContext(func(st *state.State, si *snap.SideInfo, path, name string, opts *snapstate.RevisionOptions, userID int, flags snapstate.Flags, prqt snapstate.PrereqTracker, deviceCtx snapstate.DeviceContext, fromChange string) (*state.TaskSet, error) {
+		return nil, installErr
+	})()
+
+	for _, t := range []struct {
+		content []byte
+		form    string
+		status  int
+		err     string
+	}{
+		// the rebuilt snap doesn't match the uploaded assertions
+		{[]byte("other snap"), form, 400, `cannot find signatures with metadata for snap "foo" rebuilt from delta "a/b/foo_2.xdelta3"`},
+		{content, form, 500, `cannot install snap rebuilt from delta: cannot install`},
+		{content, "Content-Disposition: form-data; name=\"assertions\"\r\n\r\ngarbage\r\n----hello--\r\n", 400, `cannot decode assertions uploaded with delta "a/b/foo_2.xdelta3": .*`},
+	} {
+		rebuiltContent = t.content
+
+		req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(s.sideloadDeltaBody("foo", t.form)))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, t.status)
+		c.Check(rspe.Message, check.Matches, t.err)
+
+		st.Lock()
+		_, err = snapRev.Ref().Resolve(assertstate.DB(st).Find)
+		st.Unlock()
+		c.Check(errors.Is(err, &asserts.NotFoundError{}), check.Equals, true)
+
+		// the rebuilt snap is removed
+		matches, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+		c.Assert(err, check.IsNil)
+		c.Check(matches, check.HasLen, 0)
+	}
+}
+
+func (s *sideloadSuite) sideloadBundleReq(c *check.C, bundle []byte, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	c.Assert(mw.WriteField("action", "install-bundle"), check.IsNil)
+	c.Assert(mw.WriteField("snap-path", "a/b/update.bundle"), check.IsNil)
+	for k, v := range fields {
+		c.Assert(mw.WriteField(k, v), check.IsNil)
+	}
+	fw, err := mw.CreateFormFile("snap", "update.bundle")
+	c.Assert(err, check.IsNil)
+	_, err = fw.Write(bundle)
+	c.Assert(err, check.IsNil)
+	c.Assert(mw.Close(), check.IsNil)
+
+	req, err := http.NewRequest("POST", "/v2/snaps", &body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// makeBundle returns a bundle with the foo snap and its assertions, the
+// manifest lists it with the given revision.
+func (s *sideloadSuite) makeBundle(c *check.C, rev snap.Revision) []byte {
+	dir := c.MkDir()
+	snapPath := filepath.Join(dir, "foo_1.snap")
+	c.Assert(os.WriteFile(snapPath, []byte("foo snap"), 0644), check.IsNil)
+	return s.makeBundleFromDir(c, dir, rev)
+}
+
+// makeBundleFromDir returns a bundle with the foo_1.snap file from the given
+// directory and its assertions, the manifest lists it with the given revision.
+func (s *sideloadSuite) makeBundleFromDir(c *check.C, dir string, rev snap.Revision) []byte {
+	snapDecl, snapRev := s.makeSnapAssertions(c, "foo", filepath.Join(dir, "foo_1.snap"))
+
+	var asserted bytes.Buffer
+	enc := asserts.NewEncoder(&asserted)
+	for _, a := range []asserts.Assertion{s.StoreSigning.StoreAccountKey(""), snapDecl, snapRev} {
+		c.Assert(enc.Encode(a), check.IsNil)
+	}
+	c.Assert(os.WriteFile(filepath.Join(dir, "foo_1.assert"), asserted.Bytes(), 0644), check.IsNil)
+
+	var bundle bytes.Buffer
+	c.Assert(snapbundle.Write(&bundle, &snapbundle.Manifest{
+		Snaps: []snapbundle.Snap{{
+			Name:       "foo",
+			Revision:   rev,
+			File:       "foo_1.snap",
+			Assertions: "foo_1.assert",
+		}},
+	}, dir), check.IsNil)
+	return bundle.Bytes()
+}
+
+func (s *sideloadSuite) TestSideloadBundle(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+
+	defer daemon.MockSideloadSnapsInfo([]*snap.Info{{SideInfo: snap.SideInfo{
+		RealName: "foo",
+		SnapID:   snaptest.AssertedSnapID("foo"),
+		Revision: snap.R(1),
+	}}})()
+
+	defer daemon.MockSnapbundleExtract(func(r io.Reader, create func(name string) (*os.File, error)) (*snapbundle.Manifest, map[string]string, error) {
+		// the bundle is extracted without holding the state lock
+		unlocked := make(chan struct{})
+		go func() {
+			st.Lock()
+			defer st.Unlock()
+			close(unlocked)
+		}()
+		select {
+		case <-unlocked:
+		case <-time.After(testutil.HostScaledTimeout(5 * time.Second)):
+			c.Errorf("state is locked while extracting the bundle")
+		}
+		return snapbundle.Extract(r, create)
+	})()
+
+	var snapPath string
+	defer daemon.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, g snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		goal := g.(*pathUpdateGoalRecorder)
+		c.Check(opts.Flags, check.DeepEquals, snapstate.Flags{RemoveSnapPath: true, Transaction: client.TransactionAllSnaps, Lane: 1})
+		// the assertions from the bundle are only added along with the change
+		_, err := assertstate.DB(st).Find(asserts.SnapDeclarationType, map[string]string{
+			"series":  "16",
+			"snap-id": snaptest.AssertedSnapID("foo"),
+		})
+		c.Check(errors.Is(err, &asserts.NotFoundError{}), check.Equals, true)
+		c.Assert(goal.snaps, check.HasLen, 1)
+		c.Check(goal.snaps[0].SideInfo.RealName, check.Equals, "foo")
+		c.Check(goal.snaps[0].Path, testutil.FileEquals, "foo snap")
+		snapPath = goal.snaps[0].Path
+
+		ts := state.NewTaskSet(st.NewTask("fake-install-snap", "Doing a fake install"))
+		return []string{"foo"}, &snapstate.UpdateTaskSets{Refresh: []*state.TaskSet{ts}}, nil
+	})()
+
+	req := s.sideloadBundleReq(c, s.makeBundle(c, snap.R(1)), nil)
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "install-snap")
+	c.Check(chg.Summary(), check.Equals, `Install snaps "foo" from bundle "a/b/update.bundle"`)
+	var apiData map[string]any
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData, check.DeepEquals, map[string]any{
+		"snap-names": []any{"foo"},
+	})
+
+	// the assertions from the bundle were added
+	_, err := assertstate.DB(st).Find(asserts.SnapDeclarationType, map[string]string{
+		"series":  "16",
+		"snap-id": snaptest.AssertedSnapID("foo"),
+	})
+	c.Check(err, check.IsNil)
+
+	// only the snap handed to the change is left
+	left, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+	c.Assert(err, check.IsNil)
+	c.Check(left, check.DeepEquals, []string{snapPath})
+}
+
+func (s *sideloadSuite) TestSideloadBundleErrors(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+
+	defer daemon.MockSideloadSnapsInfo([]*snap.Info{{SideInfo: snap.SideInfo{
+		RealName: "foo",
+		SnapID:   snaptest.AssertedSnapID("foo"),
+		Revision: snap.R(1),
+	}}})()
+	defer daemon.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, g snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil, nil
+	})()
+
+	for _, t := range []struct {
+		bundle []byte
+		fields map[string]string
+		err    string
+	}{{
+		bundle: s.makeBundle(c, snap.R(1)),
+		fields: map[string]string{"dangerous": "true"},
+		err:    `cannot install a bundle in dangerous mode, its content must be verified`,
+	}, {
+		bundle: []byte("garbage"),
+		err:    `cannot extract bundle "a/b/update.bundle": cannot read bundle: unexpected EOF`,
+	}, {
+		bundle: s.makeBundle(c, snap.R(2)),
+		err:    `snap file "foo_1.snap" in bundle "a/b/update.bundle" is revision 1 of snap "foo", expected revision 2 of snap "foo"`,
+	}} {
+		req := s.sideloadBundleReq(c, t.bundle, t.fields)
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, t.err)
+
+		// nothing is left behind
+		left, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+		c.Assert(err, check.IsNil)
+		c.Check(left, check.HasLen, 0)
+
+		st.Lock()
+		_, err = assertstate.DB(st).Find(asserts.SnapDeclarationType, map[string]string{
+			"series":  "16",
+			"snap-id": snaptest.AssertedSnapID("foo"),
+		})
+		st.Unlock()
+		c.Check(errors.Is(err, &asserts.NotFoundError{}), check.Equals, true)
+	}
+}
+
+func (s *sideloadSuite) TestSideloadBundleVerifiedWithItsAssertions(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+
+	snapPath := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1\n", nil)
+	dir := c.MkDir()
+	c.Assert(os.Rename(snapPath, filepath.Join(dir, "foo_1.snap")), check.IsNil)
+
+	defer daemon.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, g snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		goal := g.(*pathUpdateGoalRecorder)
+		c.Assert(goal.snaps, check.HasLen, 1)
+		// the snap was verified with the assertions from the bundle
+		c.Check(goal.snaps[0].SideInfo, check.DeepEquals, &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   snaptest.AssertedSnapID("foo"),
+			Revision: snap.R(1),
+		})
+		ts := state.NewTaskSet(st.NewTask("fake-install-snap", "Doing a fake install"))
+		return []string{"foo"}, &snapstate.UpdateTaskSets{Refresh: []*state.TaskSet{ts}}, nil
+	})()
+
+	req := s.sideloadBundleReq(c, s.makeBundleFromDir(c, dir, snap.R(1)), nil)
+	rsp := s.asyncReq(c, req, nil, actionIsExpected)
+
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Change(rsp.Change), check.NotNil)
+	_, err := assertstate.DB(st).Find(asserts.SnapDeclarationType, map[string]string{
+		"series":  "16",
+		"snap-id": snaptest.AssertedSnapID("foo"),
+	})
+	c.Check(err, check.IsNil)
+}
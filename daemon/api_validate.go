@@ -195,10 +195,36 @@ func getValidationSet(c *Command, r *http.Request, user *auth.UserState) Respons
 		}
 	}
 
+	var preview bool
+	switch query.Get("preview") {
+	case "":
+	case "true":
+		preview = true
+	default:
+		return BadRequest("invalid preview argument")
+	}
+
+	// diff-from is optional and only valid with preview
+	var diffFrom int
+	if diffFromStr := query.Get("diff-from"); diffFromStr != "" {
+		if !preview {
+			return BadRequest("diff-from argument requires preview")
+		}
+		var err error
+		diffFrom, err = strconv.Atoi(diffFromStr)
+		if err != nil || diffFrom < 0 {
+			return BadRequest("invalid diff-from argument")
+		}
+	}
+
 	st := c.d.overlord.State()
 	st.Lock()
 	defer st.Unlock()
 
+	if preview {
+		return previewValidationSet(st, accountID, name, sequence, diffFrom, user)
+	}
+
 	var tr assertstate.ValidationSetTracking
 	err := assertstate.GetValidationSet(st, accountID, name, &tr)
 	if errors.Is(err, state.ErrNoState) || (err == nil && sequence != 0 && sequence != tr.PinnedAt) {
@@ -416,3 +442,153 @@ func enforceValidationSet(st *state.State, accountID, name string, sequence, use
 	}
 	return SyncResponse(*res)
 }
+
+var snapstatePlanValidationSetsEnforcement = snapstate.PlanValidationSetsEnforcement
+
+// previewValidationSet handles snap validate --preview accountId/name[=sequence],
+// it reports what enforcing the validation set, together with the other
+// enforced ones, would change without changing anything.
+func previewValidationSet(st *state.State, accountID, name string, sequence, diffFrom int, user *auth.UserState) Response {
+	vs, err := validationSetForPreview(st, accountID, name, sequence, user)
+	if errors.Is(err, &asserts.NotFoundError{}) {
+		return validationSetNotFound(accountID, name, sequence)
+	}
+	if err != nil {
+		return InternalError(err.Error())
+	}
+
+	sets, err := assertstate.TrackedEnforcedValidationSets(st, vs)
+	if err != nil {
+		return InternalError("cannot get enforced validation sets: %v", err)
+	}
+	if err := sets.Conflict(); err != nil {
+		return BadRequest("cannot enforce validation set: %v", err)
+	}
+	snaps, ignoreValidation, err := snapstate.InstalledSnaps(st)
+	if err != nil {
+		return InternalError(err.Error())
+	}
+
+	res := &client.ValidationSetPreview{
+		AccountID: vs.AccountID(),
+		Name:      vs.Name(),
+		Sequence:  vs.Sequence(),
+	}
+
+	var valErr *snapasserts.ValidationSetsValidationError
+	err = checkInstalledSnaps(sets, snaps, ignoreValidation)
+	if errors.As(err, &valErr) {
+		plan, err := snapstatePlanValidationSetsEnforcement(st, valErr)
+		if err != nil {
+			return InternalError(err.Error())
+		}
+		res.Install = validationSetActions(plan.Install)
+		res.Refresh = validationSetActions(plan.Refresh)
+		res.Remove = validationSetActions(plan.Remove)
+		if plan.Unresolvable != nil {
+			res.Unresolvable = plan.Unresolvable.Error()
+		}
+	} else if err != nil {
+		return InternalError(err.Error())
+	}
+
+	if diffFrom == 0 {
+		// diff against the tracked sequence, if any
+		var tr assertstate.ValidationSetTracking
+		err := assertstate.GetValidationSet(st, accountID, name, &tr)
+		if err != nil && !errors.Is(err, state.ErrNoState) {
+			return InternalError("accessing validation sets failed: %v", err)
+		}
+		diffFrom = tr.Sequence()
+	}
+	if diffFrom != 0 && diffFrom != vs.Sequence() {
+		from, err := validationSetForPreview(st, accountID, name, diffFrom, user)
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			return validationSetNotFound(accountID, name, diffFrom)
+		}
+		if err != nil {
+			return InternalError(err.Error())
+		}
+		res.Diff = &client.ValidationSetDiff{
+			From:  from.Sequence(),
+			To:    vs.Sequence(),
+			Snaps: validationSetSnapDiffs(snapasserts.DiffValidationSets(from, vs)),
+		}
+	}
+
+	return SyncResponse(res)
+}
+
+// validationSetForPreview returns the validation set assertion at the
+// given sequence, or the latest one if sequence is zero, preferring the
+// one in the database for a given sequence.
+func validationSetForPreview(st *state.State, accountID, name string, sequence int, user *auth.UserState) (*asserts.ValidationSet, error) {
+	if sequence != 0 {
+		vs, err := validationSetAssertFromDb(st, accountID, name, sequence)
+		if err == nil {
+			return vs, nil
+		}
+		if !errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, err
+		}
+	}
+	as, err := getSingleSeqFormingAssertion(st, accountID, name, sequence, user)
+	if err != nil {
+		return nil, err
+	}
+	return as.(*asserts.ValidationSet), nil
+}
+
+func validationSetActions(actions []snapstate.ValidationSetsEnforcementAction) []client.ValidationSetAction {
+	if len(actions) == 0 {
+		return nil
+	}
+	res := make([]client.ValidationSetAction, 0, len(actions))
+	for _, a := range actions {
+		ca := client.ValidationSetAction{
+			Snap:           a.InstanceName,
+			Component:      a.Component,
+			ValidationSets: a.ValidationSets,
+		}
+		if !a.Revision.Unset() {
+			ca.Revision = a.Revision.String()
+		}
+		if !a.Current.Unset() {
+			ca.Current = a.Current.String()
+		}
+		res = append(res, ca)
+	}
+	return res
+}
+
+func validationSetSnapDiffs(diffs []snapasserts.ValidationSetSnapDiff) []client.ValidationSetSnapDiff {
+	res := make([]client.ValidationSetSnapDiff, 0, len(diffs))
+	for _, d := range diffs {
+		res = append(res, client.ValidationSetSnapDiff{
+			Name: d.Name,
+			From: validationSetSnapConstraint(d.From),
+			To:   validationSetSnapConstraint(d.To),
+		})
+	}
+	return res
+}
+
+func validationSetSnapConstraint(sn *asserts.ValidationSetSnap) *client.ValidationSetSnapConstraint {
+	if sn == nil {
+		return nil
+	}
+	c := &client.ValidationSetSnapConstraint{
+		Presence: string(sn.Presence),
+		Revision: sn.Revision,
+	}
+	if len(sn.Components) != 0 {
+		c.Components = make(map[string]client.ValidationSetSnapConstraint, len(sn.Components))
+		for name, comp := range sn.Components {
+			c.Components[name] = client.ValidationSetSnapConstraint{
+				Presence: string(comp.Presence),
+				Revision: comp.Revision,
+			}
+		}
+	}
+	return c
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
@@ -962,3 +963,183 @@ func (s *apiValidationSetsSuite) TestApplyValidationSetEnforceModeError(c *check
 	c.Assert(rspe.Status, check.Equals, 400)
 	c.Check(string(rspe.Message), check.Equals, "cannot enforce validation set: boom")
 }
+
+func (s *apiValidationSetsSuite) signValidationSet(c *check.C, name, sequence string, snaps []any) *asserts.ValidationSet {
+	headers := map[string]any{
+		"authority-id": s.dev1acct.AccountID(),
+		"account-id":   s.dev1acct.AccountID(),
+		"name":         name,
+		"series":       "16",
+		"sequence":     sequence,
+		"revision":     "1",
+		"timestamp":    "2030-11-06T09:16:26Z",
+		"snaps":        snaps,
+	}
+	vs, err := s.dev1Signing.Sign(asserts.ValidationSetType, headers, nil, "")
+	c.Assert(err, check.IsNil)
+	return vs.(*asserts.ValidationSet)
+}
+
+func (s *apiValidationSetsSuite) TestPreviewValidationSet(c *check.C) {
+	acct := s.dev1acct.AccountID()
+	vs2 := s.signValidationSet(c, "foo", "2", []any{
+		map[string]any{"id": "snapbidididididididididididididi", "name": "snap-b", "presence": "optional"},
+		map[string]any{"id": "snapcidididididididididididididi", "name": "snap-c", "presence": "optional"},
+	})
+	vs3 := s.signValidationSet(c, "foo", "3", []any{
+		map[string]any{"id": "snapbidididididididididididididi", "name": "snap-b", "revision": "1"},
+		map[string]any{"id": "snapcidididididididididididididi", "name": "snap-c", "presence": "invalid"},
+		map[string]any{"id": "snapdidididididididididididididi", "name": "snap-d"},
+	})
+	s.mockSeqFormingAssertionFn = func(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
+		c.Check(sequenceKey, check.DeepEquals, []string{"16", acct, "foo"})
+		c.Check(sequence, check.Equals, 0)
+		return vs3, nil
+	}
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	assertstatetest.AddMany(st, s.dev1acct, s.acct1Key, vs2)
+	st.Set("validation-sets", map[string]any{
+		acct + "/foo": map[string]any{
+			"account-id": acct,
+			"name":       "foo",
+			"mode":       assertstate.Monitor,
+			"current":    2,
+		},
+	})
+	for _, sn := range []struct {
+		name string
+		id   string
+		rev  snap.Revision
+	}{
+		{"snap-b", "snapbidididididididididididididi", snap.R(2)},
+		{"snap-c", "snapcidididididididididididididi", snap.R(5)},
+	} {
+		snapstate.Set(st, sn.name, &snapstate.SnapState{
+			Active:   true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: sn.name, Revision: sn.rev, SnapID: sn.id}}),
+			Current:  sn.rev,
+		})
+	}
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/validation-sets/"+acct+"/foo?preview=true", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+
+	key := acct + "/foo"
+	c.Check(rsp.Result, check.DeepEquals, &client.ValidationSetPreview{
+		AccountID: acct,
+		Name:      "foo",
+		Sequence:  3,
+		Install: []client.ValidationSetAction{
+			{Snap: "snap-d", ValidationSets: []string{key}},
+		},
+		Refresh: []client.ValidationSetAction{
+			{Snap: "snap-b", Revision: "1", Current: "2", ValidationSets: []string{key}},
+		},
+		Remove: []client.ValidationSetAction{
+			{Snap: "snap-c", ValidationSets: []string{key}},
+		},
+		Unresolvable: `cannot auto-resolve validation set constraints that require removing snaps: "snap-c"`,
+		Diff: &client.ValidationSetDiff{
+			From: 2,
+			To:   3,
+			Snaps: []client.ValidationSetSnapDiff{{
+				Name: "snap-b",
+				From: &client.ValidationSetSnapConstraint{Presence: "optional"},
+				To:   &client.ValidationSetSnapConstraint{Presence: "required", Revision: 1},
+			}, {
+				Name: "snap-c",
+				From: &client.ValidationSetSnapConstraint{Presence: "optional"},
+				To:   &client.ValidationSetSnapConstraint{Presence: "invalid"},
+			}, {
+				Name: "snap-d",
+				To:   &client.ValidationSetSnapConstraint{Presence: "required"},
+			}},
+		},
+	})
+
+	// nothing is changed
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+	var tr assertstate.ValidationSetTracking
+	c.Assert(assertstate.GetValidationSet(st, acct, "foo", &tr), check.IsNil)
+	c.Check(tr.Mode, check.Equals, assertstate.Monitor)
+	c.Check(tr.Current, check.Equals, 2)
+}
+
+func (s *apiValidationSetsSuite) TestPreviewValidationSetDiffFromValid(c *check.C) {
+	acct := s.dev1acct.AccountID()
+	vs1 := s.signValidationSet(c, "foo", "1", []any{
+		map[string]any{"id": "snapbidididididididididididididi", "name": "snap-b", "revision": "1"},
+	})
+	vs4 := s.signValidationSet(c, "foo", "4", []any{
+		map[string]any{"id": "snapbidididididididididididididi", "name": "snap-b", "presence": "optional"},
+	})
+	s.mockSeqFormingAssertionFn = func(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
+		switch sequence {
+		case 1:
+			return vs1, nil
+		case 4:
+			return vs4, nil
+		}
+		return nil, &asserts.NotFoundError{Type: assertType}
+	}
+
+	req, err := http.NewRequest("GET", "/v2/validation-sets/"+acct+"/foo?preview=true&sequence=4&diff-from=1", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil, actionIsExpected)
+	c.Assert(rsp.Status, check.Equals, 200)
+	c.Check(rsp.Result, check.DeepEquals, &client.ValidationSetPreview{
+		AccountID: acct,
+		Name:      "foo",
+		Sequence:  4,
+		Diff: &client.ValidationSetDiff{
+			From: 1,
+			To:   4,
+			Snaps: []client.ValidationSetSnapDiff{{
+				Name: "snap-b",
+				From: &client.ValidationSetSnapConstraint{Presence: "required", Revision: 1},
+				To:   &client.ValidationSetSnapConstraint{Presence: "optional"},
+			}},
+		},
+	})
+
+	// diffing from an unknown sequence
+	req, err = http.NewRequest("GET", "/v2/validation-sets/"+acct+"/foo?preview=true&sequence=4&diff-from=2", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil, actionIsExpected)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Value, check.DeepEquals, map[string]any{
+		"account-id": acct,
+		"name":       "foo",
+		"sequence":   2,
+	})
+}
+
+func (s *apiValidationSetsSuite) TestPreviewValidationSetErrors(c *check.C) {
+	s.mockSeqFormingAssertionFn = func(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
+		return nil, &asserts.NotFoundError{Type: assertType}
+	}
+
+	for _, tc := range []struct {
+		query   string
+		status  int
+		message string
+	}{
+		{"preview=maybe", 400, "invalid preview argument"},
+		{"diff-from=2", 400, "diff-from argument requires preview"},
+		{"preview=true&diff-from=x", 400, "invalid diff-from argument"},
+		{"preview=true", 404, "validation set not found"},
+	} {
+		req, err := http.NewRequest("GET", "/v2/validation-sets/foo/bar?"+tc.query, nil)
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil, actionIsExpected)
+		c.Check(rspe.Status, check.Equals, tc.status, check.Commentf("%s", tc.query))
+		c.Check(rspe.Message, check.Equals, tc.message, check.Commentf("%s", tc.query))
+	}
+}
@@ -21,7 +21,9 @@ package daemon
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -32,16 +34,20 @@ import (
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/interfaces/denials"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/clusterstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/restart"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapbundle"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -212,6 +218,18 @@ func MockSnapstateInstallPath(mock func(*state.State, *snap.SideInfo, string, st
 	}
 }
 
+func MockSnapstateInstallPathWithDeviceContext(mock func(*state.State, *snap.SideInfo, string, string, *snapstate.RevisionOptions, int, snapstate.Flags, snapstate.PrereqTracker, snapstate.DeviceContext, string) (*state.TaskSet, error)) (restore func()) {
+	return testutil.Mock(&snapstateInstallPathWithDeviceContext, mock)
+}
+
+func MockSnapdeltaApply(mock func(sourcePath, deltaPath, targetPath string) error) (restore func()) {
+	return testutil.Mock(&snapdeltaApply, mock)
+}
+
+func MockSnapbundleExtract(mock func(r io.Reader, create func(name string) (*os.File, error)) (*snapbundle.Manifest, map[string]string, error)) (restore func()) {
+	return testutil.Mock(&snapbundleExtract, mock)
+}
+
 func MockSnapstateTryPath(mock func(*state.State, string, string, snapstate.Flags) (*state.TaskSet, error)) (restore func()) {
 	oldSnapstateTryPath := snapstateTryPath
 	snapstateTryPath = mock
@@ -447,6 +465,30 @@ func MockConfdbstateLoadConfdbAsync(f func(*state.State, *confdb.View, []string,
 	return testutil.Mock(&confdbstateLoadConfdbAsync, f)
 }
 
+func MockConfdbstateViewHistory(f func(*state.State, *confdb.View) ([]*confdbstate.HistoryEntry, error)) (restore func()) {
+	return testutil.Mock(&confdbstateViewHistory, f)
+}
+
+func MockConfdbstateRollback(f func(*state.State, string, string, int, string) (string, error)) (restore func()) {
+	return testutil.Mock(&confdbstateRollback, f)
+}
+
+func MockAssertstateConfdbSchema(f func(*state.State, string, string) (*asserts.ConfdbSchema, error)) (restore func()) {
+	return testutil.Mock(&assertstateConfdbSchema, f)
+}
+
+func MockConfdbstateExport(f func(*state.State, string, string) (map[string]any, error)) (restore func()) {
+	return testutil.Mock(&confdbstateExport, f)
+}
+
+func MockConfdbstateSnapViews(f func(*state.State, string) ([]string, error)) (restore func()) {
+	return testutil.Mock(&confdbstateSnapViews, f)
+}
+
+func MockConfdbstateImport(f func(*state.State, string, string, map[string]any, string) (string, error)) (restore func()) {
+	return testutil.Mock(&confdbstateImport, f)
+}
+
 func ValidateFeatureFlag(st *state.State, feature features.SnapdFeature) *apiError {
 	return validateFeatureFlag(st, feature)
 }
@@ -454,3 +496,35 @@ func ValidateFeatureFlag(st *state.State, feature features.SnapdFeature) *apiErr
 func MockDeviceStateSignConfdbControl(f func(m *devicestate.DeviceManager, groups []any, revision int) (*asserts.ConfdbControl, error)) (restore func()) {
 	return testutil.Mock(&devicestateSignConfdbControl, f)
 }
+
+func MockClusterstateClusterStatus(f func(*state.State) (*clusterstate.Status, error)) (restore func()) {
+	return testutil.Mock(&clusterstateClusterStatus, f)
+}
+
+func MockClusterstateAssemble(f func(*state.State, clusterstate.AssembleOptions) (*state.TaskSet, error)) (restore func()) {
+	return testutil.Mock(&clusterstateAssemble, f)
+}
+
+func MockSBOMNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&sbomNow, f)
+}
+
+func MockIfacestateExplainPolicy(f func(m *ifacestate.InterfaceManager, snapName, ifaceName string) ([]*ifacestate.PolicyExplanation, error)) (restore func()) {
+	return testutil.Mock(&ifacestateExplainPolicy, f)
+}
+
+type (
+	PolicyExplanationJSON = policyExplanationJSON
+	PolicyDecisionJSON    = policyDecisionJSON
+	PolicyConstraintJSON  = policyConstraintJSON
+)
+
+func MockDenialsCollect(f func(instanceName string) ([]*denials.Denial, error)) (restore func()) {
+	return testutil.Mock(&denialsCollect, f)
+}
+
+func MockDenialsSyscallResolver(f func() (func(arch string, nr int) (string, error), error)) (restore func()) {
+	return testutil.Mock(&denialsSyscallResolver, f)
+}
+
+type DenialJSON = denialJSON
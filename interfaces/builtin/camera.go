@@ -20,7 +20,15 @@
 package builtin
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
 )
 
 const cameraSummary = `allows access to all cameras`
@@ -39,7 +47,16 @@ const cameraConnectedPlugAppArmor = `
 
 # VideoCore cameras (shared device with VideoCore/EGL)
 ###PROMPT### /dev/vchiq rw,
+` + cameraDetectionAppArmor
+
+// cameraConnectedPlugAppArmorDevice is used for slots of a specific camera,
+// created when the camera was hotplugged.
+const cameraConnectedPlugAppArmorDevice = `
+# Description: allow access to a specific camera
+###PROMPT### %s rw,
+` + cameraDetectionAppArmor
 
+const cameraDetectionAppArmor = `
 # Allow detection of cameras. Leaks plugged in USB device info
 /sys/bus/usb/devices/ r,
 /sys/devices/pci**/usb*/**/busnum r,
@@ -73,8 +90,62 @@ var cameraConnectedPlugUDev = []string{
 	`KERNEL=="vchiq"`,
 }
 
+// Pattern to match the device nodes of hotplugged cameras
+var cameraDeviceNodePattern = regexp.MustCompile("^/dev/video[0-9]{1,3}$")
+
+// cameraInterface is the type for the camera interface. Besides the implicit
+// slot granting access to all cameras, it provides slots for specific cameras
+// that are created when they are hotplugged.
+type cameraInterface struct {
+	commonInterface
+}
+
+func (iface *cameraInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if _, ok := slot.Lookup("path"); !ok {
+		return iface.commonInterface.AppArmorConnectedPlug(spec, plug, slot)
+	}
+	cleanedPath, err := verifySlotPathAttribute(slot.Ref(), slot, cameraDeviceNodePattern, invalidDeviceNodeSlotPathErrFmt)
+	if err != nil {
+		return err
+	}
+	spec.AddSnippet(fmt.Sprintf(cameraConnectedPlugAppArmorDevice, cleanedPath))
+	return nil
+}
+
+func (iface *cameraInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if _, ok := slot.Lookup("path"); !ok {
+		return iface.commonInterface.UDevConnectedPlug(spec, plug, slot)
+	}
+	cleanedPath, err := verifySlotPathAttribute(slot.Ref(), slot, cameraDeviceNodePattern, invalidDeviceNodeSlotPathErrFmt)
+	if err != nil {
+		return err
+	}
+	spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="video4linux", KERNEL=="%s"`, strings.TrimPrefix(cleanedPath, "/dev/")))
+	return nil
+}
+
+func (iface *cameraInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	// only video capture devices are cameras, other video4linux devices
+	// (eg. metadata nodes of the same camera) are ignored
+	caps, _ := di.Attribute("ID_V4L_CAPABILITIES")
+	if di.Subsystem() != "video4linux" || !strings.Contains(caps, ":capture:") || !cameraDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	slot := hotplug.ProposedSlot{
+		Attrs: hotplugSlotAttrs(di),
+	}
+	if product, ok := di.Attribute("ID_V4L_PRODUCT"); ok {
+		slot.Label = product
+	}
+	return &slot, nil
+}
+
+func (iface *cameraInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di, "ID_USB_INTERFACE_NUM"), nil
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&cameraInterface{commonInterface{
 		name:                  "camera",
 		summary:               cameraSummary,
 		implicitOnCore:        true,
@@ -82,5 +153,5 @@ func init() {
 		baseDeclarationSlots:  cameraBaseDeclarationSlots,
 		connectedPlugAppArmor: cameraConnectedPlugAppArmor,
 		connectedPlugUDev:     cameraConnectedPlugUDev,
-	})
+	}})
 }
@@ -28,17 +28,20 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
 
 type CameraInterfaceSuite struct {
-	iface    interfaces.Interface
-	slot     *interfaces.ConnectedSlot
-	slotInfo *snap.SlotInfo
-	plug     *interfaces.ConnectedPlug
-	plugInfo *snap.PlugInfo
+	iface          interfaces.Interface
+	slot           *interfaces.ConnectedSlot
+	slotInfo       *snap.SlotInfo
+	hotplugSlot    *interfaces.ConnectedSlot
+	badHotplugSlot *interfaces.ConnectedSlot
+	plug           *interfaces.ConnectedPlug
+	plugInfo       *snap.PlugInfo
 }
 
 var _ = Suite(&CameraInterfaceSuite{
@@ -59,9 +62,23 @@ slots:
   camera:
 `
 
+const cameraHotplugCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  webcam:
+    interface: camera
+    path: /dev/video2
+  bad-webcam:
+    interface: camera
+    path: /dev/sda1
+`
+
 func (s *CameraInterfaceSuite) SetUpTest(c *C) {
 	s.plug, s.plugInfo = MockConnectedPlug(c, cameraConsumerYaml, nil, "camera")
 	s.slot, s.slotInfo = MockConnectedSlot(c, cameraCoreYaml, nil, "camera")
+	s.hotplugSlot, _ = MockConnectedSlot(c, cameraHotplugCoreYaml, nil, "webcam")
+	s.badHotplugSlot, _ = MockConnectedSlot(c, cameraHotplugCoreYaml, nil, "bad-webcam")
 }
 
 func (s *CameraInterfaceSuite) TestName(c *C) {
@@ -133,3 +150,103 @@ func (s *CameraInterfaceSuite) TestDetectCameraFromPath(c *C) {
 		c.Check(builtin.DetectCameraFromPath(path), Equals, false, Commentf("%q should not be detected as camera path"))
 	}
 }
+
+func (s *CameraInterfaceSuite) TestAppArmorSpecHotplugSlot(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.hotplugSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "###PROMPT### /dev/video2 rw,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/video[0-9]*")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/run/udev/data/c81:[0-9]* r,")
+
+	spec = apparmor.NewSpecification(appSet)
+	err = spec.AddConnectedPlug(s.iface, s.plug, s.badHotplugSlot)
+	c.Assert(err, ErrorMatches, `slot "core:bad-webcam" path attribute must be a valid device node`)
+}
+
+func (s *CameraInterfaceSuite) TestUDevSpecHotplugSlot(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := udev.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.hotplugSlot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Assert(spec.Snippets(), testutil.Contains, `# camera
+SUBSYSTEM=="video4linux", KERNEL=="video2", TAG+="snap_consumer_app"`)
+
+	spec = udev.NewSpecification(appSet)
+	err = spec.AddConnectedPlug(s.iface, s.plug, s.badHotplugSlot)
+	c.Assert(err, ErrorMatches, `slot "core:bad-webcam" path attribute must be a valid device node`)
+}
+
+func (s *CameraInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/video0", "ID_VENDOR_ID": "046d", "ID_MODEL_ID": "0825", "ID_V4L_CAPABILITIES": ":capture:", "ID_V4L_PRODUCT": "UVC Camera (046d:0825)", "ACTION": "add", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Label: "UVC Camera (046d:0825)", Attrs: map[string]any{"path": "/dev/video0", "usb-vendor": "046d", "usb-product": "0825"}})
+
+	// cameras on other buses don't get usb attributes
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/video1", "ID_V4L_CAPABILITIES": ":capture:", "ACTION": "add", "SUBSYSTEM": "video4linux"})
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]any{"path": "/dev/video1"}})
+}
+
+func (s *CameraInterfaceSuite) TestHotplugDeviceDetectedNotCamera(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	for _, env := range []map[string]string{
+		// metadata node of a camera
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/video1", "ID_V4L_CAPABILITIES": ":", "ACTION": "add", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"},
+		// not a video device node
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/radio0", "ID_V4L_CAPABILITIES": ":capture:", "ACTION": "add", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"},
+		// other subsystem
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/video0", "ID_V4L_CAPABILITIES": ":capture:", "ACTION": "add", "SUBSYSTEM": "tty", "ID_BUS": "usb"},
+	} {
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+		c.Assert(err, IsNil)
+		c.Check(proposedSlot, IsNil, Commentf("%v", env))
+	}
+}
+
+func (s *CameraInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	env := map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/video0", "ID_VENDOR_ID": "046d", "ID_MODEL_ID": "0825", "ID_SERIAL_SHORT": "A1B2C3", "ID_PATH": "pci-0000:00:14.0-usb-0:2:1.0", "ID_USB_INTERFACE_NUM": "00", "ACTION": "add", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key, Equals, snap.HotplugKey("00b845a5a4efeab31ee2943ca39d12abf529d26d50ff033675142cf4c099fbf58"))
+
+	// the same camera gets the same key under a different device node
+	// and plugged into another port
+	env["DEVNAME"] = "/dev/video4"
+	env["ID_PATH"] = "pci-0000:00:14.0-usb-0:3:1.0"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Equals, key)
+
+	// without a serial number the port tells the cameras apart
+	env["ID_SERIAL_SHORT"] = "noserial"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err = keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Not(Equals), key)
+	c.Check(otherKey, Not(Equals), snap.HotplugKey(""))
+
+	// not a usb camera, the default key is used
+	delete(env, "ID_BUS")
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err = keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Equals, snap.HotplugKey(""))
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 )
@@ -193,6 +194,39 @@ func (iface *hidrawInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) bool {
 	return true
 }
 
+func (iface *hidrawInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	if di.Subsystem() != "hidraw" || !hidrawDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	// the proposed usb-vendor and usb-product attributes are informational
+	// only, the slot is restricted to the device node given by path
+	return &hotplug.ProposedSlot{
+		Attrs: hotplugSlotAttrs(di),
+	}, nil
+}
+
+func (iface *hidrawInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di, "ID_USB_INTERFACE_NUM"), nil
+}
+
+func (iface *hidrawInterface) HandledByGadget(di *hotplug.HotplugDeviceInfo, slot *snap.SlotInfo) bool {
+	// slots with vendor and product set match all such devices, the
+	// path attribute is then the location of the udev symlink
+	var usbVendor, usbProduct int64
+	if err := slot.Attr("usb-vendor", &usbVendor); err == nil {
+		if err := slot.Attr("usb-product", &usbProduct); err != nil {
+			return false
+		}
+		return slotDeviceAttrEqual(di, "ID_VENDOR_ID", usbVendor) && slotDeviceAttrEqual(di, "ID_MODEL_ID", usbProduct)
+	}
+
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return false
+	}
+	return di.DeviceName() == path
+}
+
 func (iface *hidrawInterface) hasUsbAttrs(attrs interfaces.Attrer) bool {
 	var v int64
 	if err := attrs.Attr("usb-vendor", &v); err == nil {
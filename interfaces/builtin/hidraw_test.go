@@ -28,6 +28,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
@@ -377,3 +378,77 @@ SUBSYSTEM=="hidraw", SUBSYSTEMS=="usb", ATTRS{idVendor}=="ffff", ATTRS{idProduct
 func (s *HidrawInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
+
+func (s *HidrawInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/hidraw3", "ID_VENDOR_ID": "1234", "ID_MODEL_ID": "5678", "ACTION": "add", "SUBSYSTEM": "hidraw", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]any{"path": "/dev/hidraw3", "usb-vendor": "1234", "usb-product": "5678"}})
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugDeviceDetectedNotHidraw(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/hidraw-canbus", "ACTION": "add", "SUBSYSTEM": "hidraw", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, IsNil)
+
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/hidraw0", "ACTION": "add", "SUBSYSTEM": "tty", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, IsNil)
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugSlotUsesPathPolicy(c *C) {
+	// slots created on hotplug carry usb-vendor and usb-product as
+	// strings, access is granted to the device node only
+	slotAppSet, err := interfaces.NewSnapAppSet(s.osSnapInfo, nil)
+	c.Assert(err, IsNil)
+	hotplugSlot := interfaces.NewConnectedSlot(s.testSlot1Info, slotAppSet, nil, map[string]any{"path": "/dev/hidraw0", "usb-vendor": "1234", "usb-product": "5678"})
+	appSet, err := interfaces.NewSnapAppSet(s.testPlugPort1.Snap(), nil)
+	c.Assert(err, IsNil)
+	apparmorSpec := apparmor.NewSpecification(appSet)
+	c.Assert(apparmorSpec.AddConnectedPlug(s.iface, s.testPlugPort1, hotplugSlot), IsNil)
+	c.Check(apparmorSpec.SnippetForTag("snap.client-snap.app-accessing-2-devices"), Equals, "/dev/hidraw0 rw,")
+
+	udevSpec := udev.NewSpecification(appSet)
+	c.Assert(udevSpec.AddConnectedPlug(s.iface, s.testPlugPort1, hotplugSlot), IsNil)
+	c.Check(udevSpec.Snippets()[0], Equals, `# hidraw
+SUBSYSTEM=="hidraw", KERNEL=="hidraw0", TAG+="snap_client-snap_app-accessing-2-devices"`)
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	env := map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/hidraw3", "ID_VENDOR_ID": "1234", "ID_MODEL_ID": "5678", "ID_SERIAL_SHORT": "0001", "ID_USB_INTERFACE_NUM": "00", "ACTION": "add", "SUBSYSTEM": "hidraw", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key, Not(Equals), snap.HotplugKey(""))
+
+	// another interface of the same device
+	env["DEVNAME"] = "/dev/hidraw4"
+	env["ID_USB_INTERFACE_NUM"] = "01"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Not(Equals), key)
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugHandledByGadget(c *C) {
+	byGadgetPred := s.iface.(hotplug.HandledByGadgetPredicate)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/hidraw0", "ID_VENDOR_ID": "0001", "ID_MODEL_ID": "0001", "ACTION": "add", "SUBSYSTEM": "hidraw", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+
+	// matching path /dev/hidraw0
+	c.Check(byGadgetPred.HandledByGadget(di, s.testSlot1Info), Equals, true)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testSlot2Info), Equals, false)
+	// matching vendor and product
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev1Info), Equals, true)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev2Info), Equals, false)
+}
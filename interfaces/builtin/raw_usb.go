@@ -19,6 +19,18 @@
 
 package builtin
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
 const rawusbSummary = `allows raw access to all USB devices`
 
 const rawusbBaseDeclarationSlots = `
@@ -40,7 +52,16 @@ const rawusbConnectedPlugAppArmor = `
 
 # Allow raw access to USB printers (i.e. for receipt printers in POS systems).
 /dev/usb/lp[0-9]* rwk,
+` + rawusbDetectionAppArmor
 
+// rawusbConnectedPlugAppArmorDevice is used for slots of a specific USB
+// device, created when the device was hotplugged.
+const rawusbConnectedPlugAppArmorDevice = `
+# Description: Allow raw access to a specific USB device.
+%s rw,
+` + rawusbDetectionAppArmor
+
+const rawusbDetectionAppArmor = `
 # Allow detection of usb devices. Leaks plugged in USB device info
 /sys/bus/usb/devices/ r,
 /sys/devices/pci**/usb[0-9]** r,
@@ -69,8 +90,61 @@ var rawusbConnectedPlugUDev = []string{
 	`SUBSYSTEM=="tty", ENV{ID_BUS}=="usb"`,
 }
 
+// Pattern to match the device nodes of hotplugged USB devices
+var rawusbDeviceNodePattern = regexp.MustCompile("^/dev/bus/usb/[0-9]{3}/[0-9]{3}$")
+
+// rawusbInterface is the type for the raw-usb interface. Besides the implicit
+// slot granting access to all USB devices, it provides slots for specific
+// devices that are created when they are hotplugged.
+type rawusbInterface struct {
+	commonInterface
+}
+
+func (iface *rawusbInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if _, ok := slot.Lookup("path"); !ok {
+		return iface.commonInterface.AppArmorConnectedPlug(spec, plug, slot)
+	}
+	cleanedPath, err := verifySlotPathAttribute(slot.Ref(), slot, rawusbDeviceNodePattern, invalidDeviceNodeSlotPathErrFmt)
+	if err != nil {
+		return err
+	}
+	spec.AddSnippet(fmt.Sprintf(rawusbConnectedPlugAppArmorDevice, cleanedPath))
+	return nil
+}
+
+func (iface *rawusbInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if _, ok := slot.Lookup("path"); !ok {
+		return iface.commonInterface.UDevConnectedPlug(spec, plug, slot)
+	}
+	cleanedPath, err := verifySlotPathAttribute(slot.Ref(), slot, rawusbDeviceNodePattern, invalidDeviceNodeSlotPathErrFmt)
+	if err != nil {
+		return err
+	}
+	spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="usb", ENV{DEVNAME}=="%s"`, cleanedPath))
+	return nil
+}
+
+func (iface *rawusbInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	devtype, _ := di.Attribute("DEVTYPE")
+	if di.Subsystem() != "usb" || devtype != "usb_device" || !rawusbDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	// hubs are not interesting on their own, the devices attached to
+	// them are reported separately
+	if devClass, _ := di.Attribute("TYPE"); strings.HasPrefix(devClass, "9/") {
+		return nil, nil
+	}
+	return &hotplug.ProposedSlot{
+		Attrs: hotplugSlotAttrs(di),
+	}, nil
+}
+
+func (iface *rawusbInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di), nil
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&rawusbInterface{commonInterface{
 		name:                  "raw-usb",
 		summary:               rawusbSummary,
 		implicitOnCore:        true,
@@ -79,5 +153,5 @@ func init() {
 		connectedPlugAppArmor: rawusbConnectedPlugAppArmor,
 		connectedPlugSecComp:  rawusbConnectedPlugSecComp,
 		connectedPlugUDev:     rawusbConnectedPlugUDev,
-	})
+	}})
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
@@ -40,6 +41,9 @@ type RawUsbInterfaceSuite struct {
 	slot     *interfaces.ConnectedSlot
 	plugInfo *snap.PlugInfo
 	plug     *interfaces.ConnectedPlug
+
+	hotplugSlot    *interfaces.ConnectedSlot
+	badHotplugSlot *interfaces.ConnectedSlot
 }
 
 var _ = Suite(&RawUsbInterfaceSuite{
@@ -60,9 +64,23 @@ slots:
   raw-usb:
 `
 
+const rawusbHotplugCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  usb-device:
+    interface: raw-usb
+    path: /dev/bus/usb/001/004
+  bad-usb-device:
+    interface: raw-usb
+    path: /dev/bus/usb/1/4
+`
+
 func (s *RawUsbInterfaceSuite) SetUpTest(c *C) {
 	s.plug, s.plugInfo = MockConnectedPlug(c, rawusbConsumerYaml, nil, "raw-usb")
 	s.slot, s.slotInfo = MockConnectedSlot(c, rawusbCoreYaml, nil, "raw-usb")
+	s.hotplugSlot, _ = MockConnectedSlot(c, rawusbHotplugCoreYaml, nil, "usb-device")
+	s.badHotplugSlot, _ = MockConnectedSlot(c, rawusbHotplugCoreYaml, nil, "bad-usb-device")
 }
 
 func (s *RawUsbInterfaceSuite) TestName(c *C) {
@@ -125,3 +143,80 @@ func (s *RawUsbInterfaceSuite) TestAutoConnect(c *C) {
 func (s *RawUsbInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
+
+func (s *RawUsbInterfaceSuite) TestAppArmorSpecHotplugSlot(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.hotplugSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/dev/bus/usb/001/004 rw,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/bus/usb/[0-9][0-9][0-9]/[0-9][0-9][0-9] rw,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/tty{USB,ACM}[0-9]*")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, `/sys/bus/usb/devices/`)
+
+	spec = apparmor.NewSpecification(appSet)
+	err = spec.AddConnectedPlug(s.iface, s.plug, s.badHotplugSlot)
+	c.Assert(err, ErrorMatches, `slot "core:bad-usb-device" path attribute must be a valid device node`)
+}
+
+func (s *RawUsbInterfaceSuite) TestUDevSpecHotplugSlot(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := udev.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.hotplugSlot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Assert(spec.Snippets(), testutil.Contains, `# raw-usb
+SUBSYSTEM=="usb", ENV{DEVNAME}=="/dev/bus/usb/001/004", TAG+="snap_consumer_app"`)
+}
+
+func (s *RawUsbInterfaceSuite) TestSecCompSpecHotplugSlot(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := seccomp.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.hotplugSlot), IsNil)
+	c.Assert(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, `socket AF_NETLINK - NETLINK_KOBJECT_UEVENT`)
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/bus/usb/001/004", "DEVTYPE": "usb_device", "TYPE": "239/2/1", "ID_VENDOR_ID": "1234", "ID_MODEL_ID": "5678", "ACTION": "add", "SUBSYSTEM": "usb", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]any{"path": "/dev/bus/usb/001/004", "usb-vendor": "1234", "usb-product": "5678"}})
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugDeviceDetectedIgnored(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	for _, env := range []map[string]string{
+		// hub
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/bus/usb/001/001", "DEVTYPE": "usb_device", "TYPE": "9/0/1", "ACTION": "add", "SUBSYSTEM": "usb", "ID_BUS": "usb"},
+		// usb interface
+		{"DEVPATH": "/sys/foo/bar", "DEVTYPE": "usb_interface", "ACTION": "add", "SUBSYSTEM": "usb"},
+		// other subsystem
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/ttyUSB0", "ACTION": "add", "SUBSYSTEM": "tty", "ID_BUS": "usb"},
+	} {
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+		c.Assert(err, IsNil)
+		c.Check(proposedSlot, IsNil, Commentf("%v", env))
+	}
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/bus/usb/001/004", "DEVTYPE": "usb_device", "ID_VENDOR_ID": "1234", "ID_MODEL_ID": "5678", "ID_SERIAL_SHORT": "0001", "ACTION": "add", "SUBSYSTEM": "usb", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	key, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+
+	// device numbers change when the device is plugged in again
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/baz", "DEVNAME": "/dev/bus/usb/001/009", "DEVTYPE": "usb_device", "ID_VENDOR_ID": "1234", "ID_MODEL_ID": "5678", "ID_SERIAL_SHORT": "0001", "ACTION": "add", "SUBSYSTEM": "usb", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	otherKey, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Equals, key)
+	c.Check(otherKey, Not(Equals), snap.HotplugKey(""))
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 )
@@ -149,6 +150,31 @@ func (iface *rawVolumeInterface) UDevConnectedPlug(spec *udev.Specification, plu
 	return nil
 }
 
+// HotplugDeviceDetected creates slots for the partitions of USB mass storage
+// devices.
+func (iface *rawVolumeInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	devtype, _ := di.Attribute("DEVTYPE")
+	bus, _ := di.Attribute("ID_BUS")
+	if di.Subsystem() != "block" || devtype != "partition" || bus != "usb" || !rawVolumePartitionPattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	return &hotplug.ProposedSlot{
+		Attrs: hotplugSlotAttrs(di),
+	}, nil
+}
+
+func (iface *rawVolumeInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di, "ID_PART_ENTRY_NUMBER"), nil
+}
+
+func (iface *rawVolumeInterface) HandledByGadget(di *hotplug.HotplugDeviceInfo, slot *snap.SlotInfo) bool {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return false
+	}
+	return di.DeviceName() == path
+}
+
 func (iface *rawVolumeInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) bool {
 	// Allow what is allowed in the declarations
 	return true
@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
@@ -354,3 +355,65 @@ func (s *rawVolumeInterfaceSuite) TestAutoConnect(c *C) {
 func (s *rawVolumeInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
+
+func (s *rawVolumeInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/sdb1", "DEVTYPE": "partition", "ID_VENDOR_ID": "0781", "ID_MODEL_ID": "5581", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]any{"path": "/dev/sdb1", "usb-vendor": "0781", "usb-product": "5581"}})
+}
+
+func (s *rawVolumeInterfaceSuite) TestHotplugDeviceDetectedIgnored(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	for _, env := range []map[string]string{
+		// whole disk
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/sdb", "DEVTYPE": "disk", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "usb"},
+		// partition of an internal disk
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/sda1", "DEVTYPE": "partition", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "ata"},
+		// not a partition device node
+		{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/loop0p1", "DEVTYPE": "partition", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "usb"},
+	} {
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+		c.Assert(err, IsNil)
+		c.Check(proposedSlot, IsNil, Commentf("%v", env))
+	}
+}
+
+func (s *rawVolumeInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	env := map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/sdb1", "DEVTYPE": "partition", "ID_VENDOR_ID": "0781", "ID_MODEL_ID": "5581", "ID_SERIAL_SHORT": "4C530001", "ID_PART_ENTRY_NUMBER": "1", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key, Not(Equals), snap.HotplugKey(""))
+
+	// the same partition shows up under another device node
+	env["DEVNAME"] = "/dev/sdc1"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Equals, key)
+
+	// another partition of the same device
+	env["DEVNAME"] = "/dev/sdc2"
+	env["ID_PART_ENTRY_NUMBER"] = "2"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	otherKey, err = keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(otherKey, Not(Equals), key)
+}
+
+func (s *rawVolumeInterfaceSuite) TestHotplugHandledByGadget(c *C) {
+	byGadgetPred := s.iface.(hotplug.HandledByGadgetPredicate)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/mmcblk0p1", "DEVTYPE": "partition", "ACTION": "add", "SUBSYSTEM": "block", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev1Info), Equals, false)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev2Info), Equals, true)
+}
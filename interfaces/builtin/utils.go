@@ -20,6 +20,7 @@
 package builtin
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
@@ -194,3 +196,67 @@ func stringListAttribute(attrer interfaces.Attrer, key string) ([]string, error)
 
 	return stringList, nil
 }
+
+// usbHotplugKeyVersion is the version of the keys computed by usbHotplugKey.
+// Any future changes to the attributes used for the key require a new
+// version.
+const usbHotplugKeyVersion = 0
+
+// usbHotplugKey computes a hotplug key for a USB device that stays the same
+// when the device is plugged in again, also across reboots, even if it shows
+// up under a different device node. The device is identified by its vendor
+// and product IDs and serial number; for devices without a serial number the
+// physical port (ID_PATH) is used instead, so that identical devices plugged
+// into different ports get distinct keys. The values of extraAttrs, when
+// present, tell apart the device nodes of a single device (eg. USB interface
+// or partition numbers). An empty key is returned for devices that cannot be
+// identified this way, in which case the default hotplug key is used.
+func usbHotplugKey(di *hotplug.HotplugDeviceInfo, extraAttrs ...string) snap.HotplugKey {
+	if bus, _ := di.Attribute("ID_BUS"); bus != "usb" {
+		return ""
+	}
+	attrs := []string{"ID_VENDOR_ID", "ID_MODEL_ID"}
+	if serial, _ := di.Attribute("ID_SERIAL_SHORT"); serial != "" && serial != "noserial" {
+		attrs = append(attrs, "ID_SERIAL_SHORT")
+	} else {
+		attrs = append(attrs, "ID_PATH")
+	}
+	key := sha256.New()
+	for _, attr := range attrs {
+		val, _ := di.Attribute(attr)
+		if val == "" {
+			return ""
+		}
+		key.Write([]byte(attr))
+		key.Write([]byte{0})
+		key.Write([]byte(val))
+		key.Write([]byte{0})
+	}
+	for _, attr := range extraAttrs {
+		if val, ok := di.Attribute(attr); ok && val != "" {
+			key.Write([]byte(attr))
+			key.Write([]byte{0})
+			key.Write([]byte(val))
+			key.Write([]byte{0})
+		}
+	}
+	return snap.HotplugKey(fmt.Sprintf("%x%x", usbHotplugKeyVersion, key.Sum(nil)))
+}
+
+// hotplugSlotAttrs returns the attributes of a slot proposed for a hotplugged
+// device node: the path of the node and, for USB devices, the vendor and
+// product IDs of the device.
+func hotplugSlotAttrs(di *hotplug.HotplugDeviceInfo) map[string]any {
+	attrs := map[string]any{
+		"path": di.DeviceName(),
+	}
+	if bus, _ := di.Attribute("ID_BUS"); bus == "usb" {
+		if vendor, ok := di.Attribute("ID_VENDOR_ID"); ok {
+			attrs["usb-vendor"] = vendor
+		}
+		if product, ok := di.Attribute("ID_MODEL_ID"); ok {
+			attrs["usb-product"] = product
+		}
+	}
+	return attrs
+}
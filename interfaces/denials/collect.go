@@ -0,0 +1,222 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// maxJournalEntries is the number of most recent audit and kernel entries
+// of the journal that are looked at for denials.
+const maxJournalEntries = 10000
+
+var osutilStreamCommand = osutil.StreamCommand
+
+func auditLogPath() string {
+	return filepath.Join(dirs.GlobalRootDir, "/var/log/audit/audit.log")
+}
+
+// Collect returns the denials of the processes of the given snap instance,
+// or of all snaps if instanceName is empty, in the order they happened. The
+// denials are read from the log of auditd if it is in use, otherwise from
+// the journal.
+func Collect(instanceName string) ([]*Denial, error) {
+	var denials []*Denial
+	seen := make(map[string]bool)
+	add := func(d *Denial) {
+		if d == nil || (instanceName != "" && d.Snap != instanceName) {
+			return
+		}
+		// with both the kernel and the audit transport of journald, the
+		// same record can be logged twice
+		if d.auditID != "" {
+			if seen[d.auditID] {
+				return
+			}
+			seen[d.auditID] = true
+		}
+		denials = append(denials, d)
+	}
+
+	f, err := os.Open(auditLogPath())
+	switch {
+	case err == nil:
+		defer f.Close()
+		if err := readAuditLog(f, add); err != nil {
+			return nil, fmt.Errorf("cannot read audit log: %v", err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if err := readJournal(add); err != nil {
+			return nil, fmt.Errorf("cannot read journal: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("cannot read audit log: %v", err)
+	}
+
+	sort.SliceStable(denials, func(i, j int) bool {
+		return denials[i].Time.Before(denials[j].Time)
+	})
+	return denials, nil
+}
+
+func readAuditLog(r io.Reader, add func(*Denial)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		add(Parse(scanner.Text(), time.Time{}))
+	}
+	return scanner.Err()
+}
+
+func readJournal(add func(*Denial)) error {
+	// matches of the same field are alternatives
+	stream, err := osutilStreamCommand("journalctl", "-o", "json", "--no-pager", "-n", strconv.Itoa(maxJournalEntries), "_TRANSPORT=audit", "_TRANSPORT=kernel")
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	decoder := json.NewDecoder(stream)
+	for {
+		var entry systemd.Log
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		t, _ := entry.Time()
+		d := Parse(entry.Message(), t)
+		if d != nil && d.auditID == "" {
+			// the records of the audit transport do not carry the
+			// audit stamp in the message
+			us, err := strconv.ParseInt(journalField(entry, "_SOURCE_REALTIME_TIMESTAMP"), 10, 64)
+			serial := journalField(entry, "_AUDIT_ID")
+			if err == nil && serial != "" {
+				d.Time = time.UnixMicro(us).UTC()
+				d.auditID = fmt.Sprintf("audit(%d.%03d:%s)", us/1000000, us%1000000/1000, serial)
+			}
+		}
+		add(d)
+	}
+}
+
+func journalField(entry systemd.Log, key string) string {
+	var value string
+	if raw := entry[key]; raw != nil {
+		json.Unmarshal(*raw, &value)
+	}
+	return value
+}
+
+// ResolveSyscalls sets the names of the system calls of seccomp denials,
+// using resolve to map the number of a system call on an architecture to its
+// name. Denials of system calls that cannot be resolved are left unchanged.
+func ResolveSyscalls(denials []*Denial, resolve func(arch string, nr int) (string, error)) {
+	type syscall struct {
+		arch string
+		nr   int
+	}
+	names := make(map[syscall]string)
+	for _, d := range denials {
+		if d.Kind != KindSeccomp || d.Syscall != "" {
+			continue
+		}
+		key := syscall{d.Arch, d.SyscallNumber}
+		name, ok := names[key]
+		if !ok {
+			// failures are remembered too, to not try again
+			name, _ = resolve(d.Arch, d.SyscallNumber)
+			names[key] = name
+		}
+		d.Syscall = name
+	}
+}
+
+// Group is a set of denials of the same access by the same app.
+type Group struct {
+	Snap     string
+	App      string
+	Revision snap.Revision
+	Kind     Kind
+	Access   string
+	// Denial is the most recent denial of the group.
+	Denial    *Denial
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// GroupDenials groups the given denials, ordered by time, by snap, app,
+// revision and access.
+func GroupDenials(denials []*Denial) []*Group {
+	type groupKey struct {
+		snap, app string
+		rev       snap.Revision
+		kind      Kind
+		access    string
+	}
+	var groups []*Group
+	byKey := make(map[groupKey]*Group)
+	for _, d := range denials {
+		key := groupKey{d.Snap, d.App, d.Revision, d.Kind, d.Access()}
+		g := byKey[key]
+		if g == nil {
+			g = &Group{
+				Snap:      d.Snap,
+				App:       d.App,
+				Revision:  d.Revision,
+				Kind:      d.Kind,
+				Access:    key.access,
+				FirstSeen: d.Time,
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Denial = d
+		g.Count++
+		g.LastSeen = d.Time
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		gi, gj := groups[i], groups[j]
+		if gi.Snap != gj.Snap {
+			return gi.Snap < gj.Snap
+		}
+		if gi.App != gj.App {
+			return gi.App < gj.App
+		}
+		return gi.FirstSeen.Before(gj.FirstSeen)
+	})
+	return groups
+}
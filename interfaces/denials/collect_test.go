@@ -0,0 +1,159 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type collectSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&collectSuite{})
+
+func (s *collectSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+	s.AddCleanup(denials.MockOsutilStreamCommand(func(name string, args ...string) (io.ReadCloser, error) {
+		c.Fatalf("unexpected call to %s", name)
+		return nil, nil
+	}))
+}
+
+const auditLog = `type=AVC msg=audit(1790000002.000:12): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+type=SERVICE_START msg=audit(1790000002.100:13): pid=1 uid=0 auid=4294967295 ses=4294967295 subj=unconfined msg='unit=snapd comm="systemd" exe="/usr/lib/systemd/systemd" res=success'
+type=AVC msg=audit(1790000001.000:11): apparmor="DENIED" operation="open" class="file" profile="snap.bar.app" name="/dev/video0" pid=1234 comm="bar" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0
+type=SECCOMP msg=audit(1790000003.000:14): auid=1000 uid=1000 gid=1000 ses=2 subj=snap.foo.app pid=1234 comm="foo" exe="/snap/foo/x1/bin/foo" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f0000001234 code=0x50000
+`
+
+func (s *collectSuite) TestCollectAuditLog(c *C) {
+	logPath := filepath.Join(dirs.GlobalRootDir, "/var/log/audit/audit.log")
+	c.Assert(os.MkdirAll(filepath.Dir(logPath), 0755), IsNil)
+	c.Assert(os.WriteFile(logPath, []byte(auditLog), 0600), IsNil)
+
+	all, err := denials.Collect("")
+	c.Assert(err, IsNil)
+	c.Assert(all, HasLen, 3)
+	c.Check(all[0].Snap, Equals, "bar")
+	c.Check(all[1].Snap, Equals, "foo")
+	c.Check(all[1].Access(), Equals, "open /dev/video0 (r)")
+	c.Check(all[2].Snap, Equals, "foo")
+	c.Check(all[2].Kind, Equals, denials.KindSeccomp)
+
+	foo, err := denials.Collect("foo")
+	c.Assert(err, IsNil)
+	c.Check(foo, DeepEquals, all[1:])
+}
+
+func (s *collectSuite) TestCollectJournal(c *C) {
+	var calls [][]string
+	restore := denials.MockOsutilStreamCommand(func(name string, args ...string) (io.ReadCloser, error) {
+		calls = append(calls, append([]string{name}, args...))
+		var buf strings.Builder
+		for _, entry := range []string{
+			`{"__REALTIME_TIMESTAMP":"1790000001000200","_TRANSPORT":"kernel","MESSAGE":"audit: type=1400 audit(1790000001.000:11): apparmor=\"DENIED\" operation=\"open\" class=\"file\" profile=\"snap.foo.app\" name=\"/dev/video0\" pid=1234 comm=\"foo\" requested_mask=\"r\" denied_mask=\"r\" fsuid=1000 ouid=0"}`,
+			// the same record through the audit transport
+			`{"__REALTIME_TIMESTAMP":"1790000001000300","_SOURCE_REALTIME_TIMESTAMP":"1790000001000000","_AUDIT_ID":"11","_TRANSPORT":"audit","MESSAGE":"AVC apparmor=\"DENIED\" operation=\"open\" class=\"file\" profile=\"snap.foo.app\" name=\"/dev/video0\" pid=1234 comm=\"foo\" requested_mask=\"r\" denied_mask=\"r\" fsuid=1000 ouid=0"}`,
+			`{"__REALTIME_TIMESTAMP":"1790000002000000","_TRANSPORT":"kernel","MESSAGE":"usb 1-2: new high-speed USB device number 4 using xhci_hcd"}`,
+			`{"__REALTIME_TIMESTAMP":"1790000003000200","_SOURCE_REALTIME_TIMESTAMP":"1790000003000000","_AUDIT_ID":"12","_TRANSPORT":"audit","MESSAGE":"AVC apparmor=\"DENIED\" operation=\"capable\" class=\"cap\" profile=\"snap.foo.app\" pid=1234 comm=\"foo\" capability=21 capname=\"sys_admin\""}`,
+		} {
+			buf.WriteString(entry + "\n")
+		}
+		return io.NopCloser(strings.NewReader(buf.String())), nil
+	})
+	defer restore()
+
+	foo, err := denials.Collect("foo")
+	c.Assert(err, IsNil)
+	c.Check(calls, DeepEquals, [][]string{
+		{"journalctl", "-o", "json", "--no-pager", "-n", "10000", "_TRANSPORT=audit", "_TRANSPORT=kernel"},
+	})
+	c.Assert(foo, HasLen, 2)
+	c.Check(foo[0].Access(), Equals, "open /dev/video0 (r)")
+	c.Check(foo[0].Time, Equals, time.Unix(1790000001, 0).UTC())
+	c.Check(foo[1].Access(), Equals, "capability sys_admin")
+	c.Check(foo[1].Time, Equals, time.Unix(1790000003, 0).UTC())
+}
+
+func (s *collectSuite) TestCollectJournalError(c *C) {
+	restore := denials.MockOsutilStreamCommand(func(name string, args ...string) (io.ReadCloser, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	_, err := denials.Collect("foo")
+	c.Assert(err, ErrorMatches, "cannot read journal: boom")
+}
+
+func (s *collectSuite) TestResolveSyscalls(c *C) {
+	ds := []*denials.Denial{
+		{Kind: denials.KindSeccomp, Arch: "amd64", SyscallNumber: 165},
+		{Kind: denials.KindAppArmor, Name: "/dev/video0"},
+		{Kind: denials.KindSeccomp, Arch: "amd64", SyscallNumber: 165},
+		{Kind: denials.KindSeccomp, Arch: "amd64", SyscallNumber: 9999},
+		{Kind: denials.KindSeccomp, Arch: "amd64", SyscallNumber: 9999},
+	}
+	var calls []string
+	denials.ResolveSyscalls(ds, func(arch string, nr int) (string, error) {
+		calls = append(calls, fmt.Sprintf("%s/%d", arch, nr))
+		if nr == 165 {
+			return "mount", nil
+		}
+		return "", errors.New("unknown")
+	})
+	c.Check(calls, DeepEquals, []string{"amd64/165", "amd64/9999"})
+	c.Check(ds[0].Syscall, Equals, "mount")
+	c.Check(ds[2].Syscall, Equals, "mount")
+	c.Check(ds[3].Syscall, Equals, "")
+	c.Check(ds[4].Access(), Equals, "syscall 9999 (amd64)")
+}
+
+func (s *collectSuite) TestGroupDenials(c *C) {
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	ds := []*denials.Denial{
+		{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Revision: snap.R(1), Operation: "open", Name: "/dev/video0", Mask: "r", Time: t0},
+		{Kind: denials.KindAppArmor, Snap: "bar", App: "app", Revision: snap.R(3), Operation: "open", Name: "/dev/video0", Mask: "r", Time: t0.Add(time.Second)},
+		{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Revision: snap.R(1), Capability: "sys_admin", Time: t0.Add(2 * time.Second)},
+		{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Revision: snap.R(1), Operation: "open", Name: "/dev/video0", Mask: "r", Time: t0.Add(3 * time.Second)},
+		// different revision
+		{Kind: denials.KindAppArmor, Snap: "foo", App: "app", Revision: snap.R(2), Operation: "open", Name: "/dev/video0", Mask: "r", Time: t0.Add(4 * time.Second)},
+	}
+	groups := denials.GroupDenials(ds)
+	c.Assert(groups, HasLen, 4)
+	c.Check(groups[0], DeepEquals, &denials.Group{Snap: "bar", App: "app", Revision: snap.R(3), Kind: denials.KindAppArmor, Access: "open /dev/video0 (r)", Denial: ds[1], Count: 1, FirstSeen: t0.Add(time.Second), LastSeen: t0.Add(time.Second)})
+	c.Check(groups[1], DeepEquals, &denials.Group{Snap: "foo", App: "app", Revision: snap.R(1), Kind: denials.KindAppArmor, Access: "open /dev/video0 (r)", Denial: ds[3], Count: 2, FirstSeen: t0, LastSeen: t0.Add(3 * time.Second)})
+	c.Check(groups[2].Access, Equals, "capability sys_admin")
+	c.Check(groups[3].Revision, Equals, snap.R(2))
+}
@@ -0,0 +1,338 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package denials collects the AppArmor and seccomp denials of snap processes
+// from the audit log and suggests interfaces that would allow the denied
+// accesses.
+package denials
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+// Kind is the kind of confinement that denied an access.
+type Kind string
+
+const (
+	KindAppArmor Kind = "apparmor"
+	KindSeccomp  Kind = "seccomp"
+)
+
+// Denial is an access of a snap process denied by AppArmor or seccomp. Snaps
+// in devmode are not denied any accesses, their accesses that would be
+// denied are reported as denials nevertheless.
+type Denial struct {
+	Kind Kind
+	Time time.Time
+
+	// Snap is the instance name of the snap of the process.
+	Snap string
+	// App is the name of the app of the process or, prefixed with
+	// "hook.", the name of the hook. It is empty if it cannot be
+	// determined from the record.
+	App string
+	// Revision is the revision of the snap, if it can be determined from
+	// the record.
+	Revision snap.Revision
+
+	PID  int
+	Comm string
+	Exe  string
+
+	// Operation is the AppArmor operation, eg. open or capable.
+	Operation string
+	// Class is the AppArmor mediation class, eg. file or net.
+	Class string
+	// Name is the object of the operation, usually a path.
+	Name string
+	// Mask is the denied AppArmor permissions, eg. "r" or "wc".
+	Mask string
+	// Capability is the denied capability, eg. sys_admin.
+	Capability string
+	// Family and SockType describe the denied socket.
+	Family   string
+	SockType string
+	// Bus, Path, Interface and Member describe the denied D-Bus message.
+	Bus       string
+	Path      string
+	Interface string
+	Member    string
+
+	// Arch is the libseccomp name of the architecture of the denied
+	// system call.
+	Arch string
+	// SyscallNumber is the number of the denied system call.
+	SyscallNumber int
+	// Syscall is the name of the denied system call, if resolved.
+	Syscall string
+
+	// auditID is the unique identifier of the audit record.
+	auditID string
+}
+
+// Access describes the denied access.
+func (d *Denial) Access() string {
+	switch {
+	case d.Kind == KindSeccomp && d.Syscall != "":
+		return fmt.Sprintf("syscall %s", d.Syscall)
+	case d.Kind == KindSeccomp:
+		return fmt.Sprintf("syscall %d (%s)", d.SyscallNumber, d.Arch)
+	case d.Capability != "":
+		return fmt.Sprintf("capability %s", d.Capability)
+	case d.Family != "":
+		return strings.TrimSpace(fmt.Sprintf("network %s %s", d.Family, d.SockType))
+	case d.Class == "dbus" || strings.HasPrefix(d.Operation, "dbus_"):
+		return fmt.Sprintf("dbus %s bus=%s path=%s interface=%s member=%s", strings.TrimPrefix(d.Operation, "dbus_"), d.Bus, d.Path, d.Interface, d.Member)
+	case d.Mask != "":
+		return fmt.Sprintf("%s %s (%s)", d.Operation, d.Name, d.Mask)
+	default:
+		return strings.TrimSpace(fmt.Sprintf("%s %s", d.Operation, d.Name))
+	}
+}
+
+var auditStampPattern = regexp.MustCompile(`audit\(([0-9]+)\.([0-9]{3}):([0-9]+)\)`)
+
+// Parse parses an AppArmor or seccomp audit record, as found in the audit log
+// or in the journal. If the record does not carry its own timestamp, t is
+// used instead. Parse returns nil for records that are not denials of snap
+// processes.
+func Parse(record string, t time.Time) *Denial {
+	fields := auditFields(record)
+
+	var d *Denial
+	switch {
+	case fields["apparmor"] == "DENIED" || fields["apparmor"] == "ALLOWED":
+		d = parseAppArmor(fields)
+	case fields["syscall"] != "" && fields["code"] != "":
+		d = parseSeccomp(fields)
+	}
+	if d == nil {
+		return nil
+	}
+
+	d.Time = t
+	if m := auditStampPattern.FindStringSubmatch(record); m != nil {
+		sec, _ := strconv.ParseInt(m[1], 10, 64)
+		msec, _ := strconv.ParseInt(m[2], 10, 64)
+		d.Time = time.Unix(sec, msec*int64(time.Millisecond)).UTC()
+		d.auditID = m[0]
+	}
+	d.PID, _ = strconv.Atoi(fields["pid"])
+	d.Comm = fields["comm"]
+	d.Exe = fields["exe"]
+	if d.Revision.Unset() {
+		_, d.Revision = snapFromExe(d.Exe)
+	}
+	return d
+}
+
+func parseAppArmor(fields map[string]string) *Denial {
+	label := fields["profile"]
+	if label == "" {
+		label = fields["label"]
+	}
+	instanceName, app, ok := snapFromLabel(label)
+	if !ok {
+		return nil
+	}
+	mask := fields["denied_mask"]
+	if mask == "" {
+		mask = fields["requested_mask"]
+	}
+	return &Denial{
+		Kind:       KindAppArmor,
+		Snap:       instanceName,
+		App:        app,
+		Operation:  fields["operation"],
+		Class:      fields["class"],
+		Name:       fields["name"],
+		Mask:       mask,
+		Capability: fields["capname"],
+		Family:     fields["family"],
+		SockType:   fields["sock_type"],
+		Bus:        fields["bus"],
+		Path:       fields["path"],
+		Interface:  fields["interface"],
+		Member:     fields["member"],
+	}
+}
+
+// seccompActionAllow is the SECCOMP_RET_ALLOW action, audited system calls
+// with any other action are either denied or would be denied if the snap was
+// not in devmode.
+const seccompActionAllow = "0x7fff0000"
+
+func parseSeccomp(fields map[string]string) *Denial {
+	if fields["code"] == seccompActionAllow {
+		return nil
+	}
+	d := &Denial{Kind: KindSeccomp}
+	var ok bool
+	if d.Snap, d.App, ok = snapFromLabel(fields["subj"]); !ok {
+		// without AppArmor, only the snap of the executable is known
+		if d.Snap, d.Revision = snapFromExe(fields["exe"]); d.Snap == "" {
+			return nil
+		}
+	}
+	nr, err := strconv.Atoi(fields["syscall"])
+	if err != nil {
+		return nil
+	}
+	d.SyscallNumber = nr
+	d.Arch = auditArches[strings.ToLower(fields["arch"])]
+	if d.Arch == "" {
+		d.Arch = fields["arch"]
+	}
+	return d
+}
+
+// auditArches maps the AUDIT_ARCH_* values found in audit records to the
+// names of the architectures in libseccomp.
+var auditArches = map[string]string{
+	"40000003": "x86",
+	"c000003e": "amd64",
+	"40000028": "arm",
+	"c00000b7": "arm64",
+	"80000014": "ppc",
+	"80000015": "ppc64",
+	"c0000015": "ppc64le",
+	"80000016": "s390x",
+	"c00000f3": "riscv64",
+}
+
+// snapFromLabel returns the snap instance name and the app or hook of the
+// process confined by the given AppArmor label.
+func snapFromLabel(label string) (instanceName, app string, ok bool) {
+	// stacked labels and the mode of the profile are not part of the
+	// security tag
+	if i := strings.IndexAny(label, " /"); i >= 0 {
+		label = label[:i]
+	}
+	tag, err := naming.ParseSecurityTag(label)
+	if err != nil {
+		return "", "", false
+	}
+	switch t := tag.(type) {
+	case naming.AppSecurityTag:
+		app = t.AppName()
+	case naming.HookSecurityTag:
+		app = "hook." + t.HookName()
+	}
+	return tag.InstanceName(), app, true
+}
+
+// snapFromExe returns the snap instance name and revision of an executable
+// found in a mounted snap, eg. /snap/foo/x1/bin/foo.
+func snapFromExe(exe string) (instanceName string, rev snap.Revision) {
+	for _, prefix := range []string{"/snap/", "/var/lib/snapd/snap/"} {
+		if !strings.HasPrefix(exe, prefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(exe, prefix), "/", 3)
+		if len(parts) < 3 || naming.ValidateInstance(parts[0]) != nil {
+			return "", snap.Revision{}
+		}
+		rev, err := snap.ParseRevision(parts[1])
+		if err != nil {
+			return "", snap.Revision{}
+		}
+		return parts[0], rev
+	}
+	return "", snap.Revision{}
+}
+
+// hexFields are the fields that the kernel hex encodes instead of quoting
+// when their values contain spaces or special characters.
+var hexFields = map[string]bool{
+	"name":    true,
+	"comm":    true,
+	"exe":     true,
+	"profile": true,
+	"label":   true,
+	"subj":    true,
+	"path":    true,
+}
+
+var hexValuePattern = regexp.MustCompile(`^(?:[0-9A-F]{2})+$`)
+
+// auditFields returns the key=value fields of an audit record.
+func auditFields(record string) map[string]string {
+	fields := make(map[string]string)
+	for len(record) > 0 {
+		record = strings.TrimLeft(record, " ")
+		end := strings.IndexAny(record, "= ")
+		if end < 0 {
+			break
+		}
+		if record[end] == ' ' {
+			// not a field, eg. "audit(...):"
+			record = record[end:]
+			continue
+		}
+		key := record[:end]
+		record = record[end+1:]
+		var value string
+		if strings.HasPrefix(record, "'") {
+			// user space records, eg. of dbus-daemon, carry the fields
+			// of the denial in a single quoted msg field, these take
+			// precedence over the fields of the sender
+			inner := record[1:]
+			record = ""
+			if end = strings.IndexByte(inner, '\''); end >= 0 {
+				inner, record = inner[:end], inner[end+1:]
+			}
+			for k, v := range auditFields(inner) {
+				fields[k] = v
+			}
+			continue
+		}
+		if strings.HasPrefix(record, `"`) {
+			if end = strings.IndexByte(record[1:], '"'); end < 0 {
+				break
+			}
+			value = record[1 : end+1]
+			record = record[end+2:]
+		} else {
+			if end = strings.IndexByte(record, ' '); end < 0 {
+				end = len(record)
+			}
+			value = record[:end]
+			record = record[end:]
+			if hexFields[key] && hexValuePattern.MatchString(value) {
+				if decoded, err := hex.DecodeString(value); err == nil {
+					value = string(decoded)
+				}
+			}
+		}
+		// the first occurrence wins, later ones are eg. the fields of the
+		// peer
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/snap"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type denialsSuite struct{}
+
+var _ = Suite(&denialsSuite{})
+
+var fallbackTime = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func (s *denialsSuite) TestParseAppArmorFile(c *C) {
+	for _, record := range []string{
+		// kernel transport of the journal
+		`audit: type=1400 audit(1790000000.123:456): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="wr" denied_mask="wr" fsuid=1000 ouid=0`,
+		// audit transport of the journal
+		`AVC apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="wr" denied_mask="wr" fsuid=1000 ouid=0`,
+		// auditd log
+		`type=AVC msg=audit(1790000000.123:456): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="wr" denied_mask="wr" fsuid=1000 ouid=0`,
+	} {
+		d := denials.Parse(record, fallbackTime)
+		c.Assert(d, NotNil, Commentf(record))
+		c.Check(d.Kind, Equals, denials.KindAppArmor)
+		c.Check(d.Snap, Equals, "foo")
+		c.Check(d.App, Equals, "app")
+		c.Check(d.Revision, Equals, snap.Revision{})
+		c.Check(d.PID, Equals, 1234)
+		c.Check(d.Comm, Equals, "foo")
+		c.Check(d.Operation, Equals, "open")
+		c.Check(d.Class, Equals, "file")
+		c.Check(d.Name, Equals, "/dev/video0")
+		c.Check(d.Mask, Equals, "wr")
+		c.Check(d.Access(), Equals, "open /dev/video0 (wr)")
+	}
+
+	c.Check(denials.Parse(`audit: type=1400 audit(1790000000.123:456): apparmor="DENIED" operation="open" profile="snap.foo.app" name="/dev/video0" requested_mask="r" denied_mask="r"`, fallbackTime).Time, Equals, time.Date(2026, 9, 21, 14, 13, 20, 123000000, time.UTC))
+	c.Check(denials.Parse(`AVC apparmor="DENIED" operation="open" profile="snap.foo.app" name="/dev/video0" requested_mask="r" denied_mask="r"`, fallbackTime).Time, Equals, fallbackTime)
+}
+
+func (s *denialsSuite) TestParseAppArmorOther(c *C) {
+	for _, tc := range []struct {
+		record string
+		app    string
+		access string
+	}{{
+		record: `audit: type=1400 audit(1790000000.123:457): apparmor="DENIED" operation="capable" class="cap" profile="snap.foo.hook.configure" pid=1 comm="foo" capability=21 capname="sys_admin"`,
+		app:    "hook.configure",
+		access: "capability sys_admin",
+	}, {
+		record: `audit: type=1400 audit(1790000000.123:458): apparmor="DENIED" operation="create" class="net" profile="snap.foo.app" pid=1 comm="foo" family="bluetooth" sock_type="raw" protocol=1 requested_mask="create" denied_mask="create"`,
+		app:    "app",
+		access: "network bluetooth raw",
+	}, {
+		record: `audit: type=1107 audit(1790000000.123:459): pid=1 uid=103 auid=4294967295 ses=4294967295 subj=unconfined msg='apparmor="DENIED" operation="dbus_method_call" bus="system" path="/org/freedesktop/hostname1" interface="org.freedesktop.hostname1" member="SetHostname" mask="send" name="org.freedesktop.hostname1" pid=1234 label="snap.foo.app" peer_pid=5 peer_label="unconfined"`,
+		app:    "app",
+		access: "dbus method_call bus=system path=/org/freedesktop/hostname1 interface=org.freedesktop.hostname1 member=SetHostname",
+	}, {
+		// hex encoded values, stacked labels
+		record: `audit: type=1400 audit(1790000000.123:460): apparmor="ALLOWED" operation="open" profile="snap.foo_bar.app//&:lxd:unconfined" name=2F746D702F6120622E747874 pid=1 comm="foo" requested_mask="r" denied_mask="r"`,
+		app:    "app",
+		access: "open /tmp/a b.txt (r)",
+	}} {
+		d := denials.Parse(tc.record, fallbackTime)
+		c.Assert(d, NotNil, Commentf(tc.record))
+		c.Check(d.Kind, Equals, denials.KindAppArmor)
+		c.Check(d.App, Equals, tc.app)
+		c.Check(d.Access(), Equals, tc.access)
+	}
+	c.Check(denials.Parse(`audit: type=1400 audit(1790000000.123:460): apparmor="ALLOWED" operation="open" profile="snap.foo_bar.app//&:lxd:unconfined" name="/tmp/a" requested_mask="r" denied_mask="r"`, fallbackTime).Snap, Equals, "foo_bar")
+}
+
+func (s *denialsSuite) TestParseSeccomp(c *C) {
+	d := denials.Parse(`audit: type=1326 audit(1790000000.123:461): auid=1000 uid=1000 gid=1000 ses=2 subj=snap.foo.app pid=1234 comm="foo" exe="/snap/foo/x1/bin/foo" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f0000001234 code=0x50000`, fallbackTime)
+	c.Assert(d, NotNil)
+	c.Check(d.Kind, Equals, denials.KindSeccomp)
+	c.Check(d.Snap, Equals, "foo")
+	c.Check(d.App, Equals, "app")
+	c.Check(d.Revision, Equals, snap.R(-1))
+	c.Check(d.Exe, Equals, "/snap/foo/x1/bin/foo")
+	c.Check(d.Arch, Equals, "amd64")
+	c.Check(d.SyscallNumber, Equals, 165)
+	c.Check(d.Access(), Equals, "syscall 165 (amd64)")
+	d.Syscall = "mount"
+	c.Check(d.Access(), Equals, "syscall mount")
+
+	// without AppArmor the snap is known from the executable only
+	d = denials.Parse(`type=SECCOMP msg=audit(1790000000.123:462): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 comm="foo" exe="/var/lib/snapd/snap/foo/12/bin/foo" sig=0 arch=c00000b7 syscall=40 compat=0 ip=0x7f0000001234 code=0x7ffc0000`, fallbackTime)
+	c.Assert(d, NotNil)
+	c.Check(d.Snap, Equals, "foo")
+	c.Check(d.App, Equals, "")
+	c.Check(d.Revision, Equals, snap.R(12))
+	c.Check(d.Arch, Equals, "arm64")
+}
+
+func (s *denialsSuite) TestParseIgnored(c *C) {
+	for _, record := range []string{
+		// not a snap
+		`audit: type=1400 audit(1790000000.123:456): apparmor="DENIED" operation="open" profile="/usr/bin/man" name="/etc/foo" requested_mask="r" denied_mask="r"`,
+		`audit: type=1400 audit(1790000000.123:456): apparmor="DENIED" operation="open" profile="snap-update-ns.foo" name="/etc/foo" requested_mask="r" denied_mask="r"`,
+		`audit: type=1326 audit(1790000000.123:461): auid=1000 uid=1000 gid=1000 ses=2 subj=unconfined pid=1234 comm="foo" exe="/usr/bin/foo" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f0000001234 code=0x50000`,
+		// not a denial
+		`audit: type=1400 audit(1790000000.123:456): apparmor="STATUS" operation="profile_load" profile="unconfined" name="snap.foo.app" pid=1 comm="apparmor_parser"`,
+		`audit: type=1326 audit(1790000000.123:461): auid=1000 uid=1000 gid=1000 ses=2 subj=snap.foo.app pid=1234 comm="foo" exe="/snap/foo/x1/bin/foo" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f0000001234 code=0x7fff0000`,
+		`Linux version 6.8.0-45-generic`,
+		``,
+	} {
+		c.Check(denials.Parse(record, fallbackTime), IsNil, Commentf(record))
+	}
+}
@@ -0,0 +1,377 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Suggester finds the interfaces whose AppArmor or seccomp snippets would
+// allow denied accesses. Files, capabilities, sockets and system calls are
+// matched, other kinds of accesses are not. Constraints of the snippets that
+// are not matched, eg. the owner qualifier of AppArmor file rules or the
+// arguments of system calls, are ignored, so the suggested interfaces may
+// allow the access only partially.
+type Suggester struct {
+	rules []*interfaceRules
+	// patterns caches the regular expressions of the AppArmor file
+	// patterns of each snap
+	patterns map[string]*regexp.Regexp
+}
+
+type interfaceRules struct {
+	name     string
+	files    []fileRule
+	caps     map[string]bool
+	network  []networkRule
+	syscalls map[string]bool
+}
+
+type fileRule struct {
+	pattern string
+	perms   string
+}
+
+type networkRule struct {
+	family   string
+	sockType string
+}
+
+const (
+	probeSnapYaml = `name: denials-probe
+version: 0
+apps:
+  app:
+`
+	probeSlotSnapYaml = `name: denials-probe-slot
+version: 0
+type: os
+`
+	probeSecurityTag = "snap.denials-probe.app"
+)
+
+// NewSuggester returns a Suggester for the given interfaces. The snippets of
+// the interfaces are those of a plug connected to an implicit system slot
+// without attributes.
+func NewSuggester(ifaces []interfaces.Interface) *Suggester {
+	s := &Suggester{patterns: make(map[string]*regexp.Regexp)}
+	for _, iface := range ifaces {
+		aaSnippet, seccompSnippet, err := probeSnippets(iface)
+		if err != nil {
+			// interfaces that require attributes cannot be probed
+			continue
+		}
+		rules := parseAppArmorRules(aaSnippet)
+		rules.syscalls = parseSeccompRules(seccompSnippet)
+		rules.name = iface.Name()
+		s.rules = append(s.rules, rules)
+	}
+	return s
+}
+
+func probeSnippets(iface interfaces.Interface) (aaSnippet, seccompSnippet string, err error) {
+	name := iface.Name()
+	// the plug and slot are added after parsing, as parsing drops those
+	// of interfaces unknown to the builtin repository
+	plugSnap, err := snap.InfoFromSnapYaml([]byte(probeSnapYaml))
+	if err != nil {
+		return "", "", err
+	}
+	app := plugSnap.Apps["app"]
+	plugInfo := &snap.PlugInfo{
+		Snap:      plugSnap,
+		Name:      name,
+		Interface: name,
+		Apps:      map[string]*snap.AppInfo{app.Name: app},
+	}
+	plugSnap.Plugs[name] = plugInfo
+	app.Plugs = map[string]*snap.PlugInfo{name: plugInfo}
+	slotSnap, err := snap.InfoFromSnapYaml([]byte(probeSlotSnapYaml))
+	if err != nil {
+		return "", "", err
+	}
+	slotInfo := &snap.SlotInfo{
+		Snap:      slotSnap,
+		Name:      name,
+		Interface: name,
+	}
+	slotSnap.Slots[name] = slotInfo
+	// sanitizing sets the default attributes of some interfaces
+	if err := interfaces.BeforePreparePlug(iface, plugInfo); err != nil {
+		return "", "", err
+	}
+	if err := interfaces.BeforePrepareSlot(iface, slotInfo); err != nil {
+		return "", "", err
+	}
+	plugAppSet, err := interfaces.NewSnapAppSet(plugSnap, nil)
+	if err != nil {
+		return "", "", err
+	}
+	slotAppSet, err := interfaces.NewSnapAppSet(slotSnap, nil)
+	if err != nil {
+		return "", "", err
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, plugAppSet, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotInfo, slotAppSet, nil, nil)
+
+	aaSpec := apparmor.NewSpecification(plugAppSet)
+	if err := aaSpec.AddPermanentPlug(iface, plugInfo); err != nil {
+		return "", "", err
+	}
+	if err := aaSpec.AddConnectedPlug(iface, plug, slot); err != nil {
+		return "", "", err
+	}
+	seccompSpec := seccomp.NewSpecification(plugAppSet)
+	if err := seccompSpec.AddPermanentPlug(iface, plugInfo); err != nil {
+		return "", "", err
+	}
+	if err := seccompSpec.AddConnectedPlug(iface, plug, slot); err != nil {
+		return "", "", err
+	}
+	return aaSpec.SnippetForTag(probeSecurityTag), seccompSpec.SnippetForTag(probeSecurityTag), nil
+}
+
+var filePermsPattern = regexp.MustCompile(`^[rwaklmixpuPUCcb]+$`)
+
+func parseAppArmorRules(snippet string) *interfaceRules {
+	rules := &interfaceRules{caps: make(map[string]bool)}
+	for _, line := range strings.Split(snippet, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "###PROMPT###")
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+		fields := strings.Fields(line)
+		// qualifiers
+		for len(fields) > 0 && (fields[0] == "owner" || fields[0] == "audit" || fields[0] == "allow" || fields[0] == "file") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "capability":
+			if len(fields) == 1 {
+				rules.caps["*"] = true
+			}
+			for _, capability := range fields[1:] {
+				rules.caps[capability] = true
+			}
+		case fields[0] == "network":
+			var rule networkRule
+			if len(fields) > 1 {
+				rule.family = fields[1]
+			}
+			if len(fields) > 2 {
+				rule.sockType = fields[2]
+			}
+			rules.network = append(rules.network, rule)
+		case strings.HasPrefix(fields[0], `"`):
+			// quoted paths can contain spaces
+			rest := strings.Join(fields, " ")
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				continue
+			}
+			perms := strings.Fields(rest[end+2:])
+			if len(perms) == 0 || !filePermsPattern.MatchString(perms[0]) {
+				continue
+			}
+			rules.files = append(rules.files, fileRule{pattern: rest[1 : end+1], perms: perms[0]})
+		case strings.HasPrefix(fields[0], "/") || strings.HasPrefix(fields[0], "@{"):
+			if len(fields) < 2 || !filePermsPattern.MatchString(fields[1]) {
+				continue
+			}
+			rules.files = append(rules.files, fileRule{pattern: fields[0], perms: fields[1]})
+		}
+	}
+	return rules
+}
+
+func parseSeccompRules(snippet string) map[string]bool {
+	syscalls := make(map[string]bool)
+	for _, line := range strings.Split(snippet, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "@unrestricted":
+			syscalls["*"] = true
+		case strings.HasPrefix(fields[0], "@") || strings.HasPrefix(fields[0], "~"):
+			// other directives and denied system calls
+		default:
+			syscalls[fields[0]] = true
+		}
+	}
+	return syscalls
+}
+
+// Suggest returns the names of the interfaces that would allow the denied
+// access, in alphabetical order.
+func (s *Suggester) Suggest(d *Denial) []string {
+	var names []string
+	for _, rules := range s.rules {
+		if s.allows(rules, d) {
+			names = append(names, rules.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Suggester) allows(rules *interfaceRules, d *Denial) bool {
+	switch {
+	case d.Kind == KindSeccomp:
+		return d.Syscall != "" && (rules.syscalls[d.Syscall] || rules.syscalls["*"])
+	case d.Capability != "":
+		return rules.caps[d.Capability] || rules.caps["*"]
+	case d.Family != "":
+		for _, rule := range rules.network {
+			if (rule.family == "" || rule.family == d.Family) && (rule.sockType == "" || rule.sockType == d.SockType) {
+				return true
+			}
+		}
+		return false
+	case (d.Class == "" || d.Class == "file") && strings.HasPrefix(d.Name, "/") && d.Mask != "":
+		for _, rule := range rules.files {
+			if !permsAllow(rule.perms, d.Mask) {
+				continue
+			}
+			if re := s.pattern(d, rule.pattern); re != nil && re.MatchString(d.Name) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// permsAllow returns whether the permissions of an AppArmor file rule include
+// all the requested permissions.
+func permsAllow(perms, requested string) bool {
+	for _, p := range requested {
+		var ok bool
+		switch p {
+		case 'w', 'c', 'd':
+			// creating and deleting files is part of writing
+			ok = strings.ContainsRune(perms, 'w')
+		case 'a':
+			ok = strings.ContainsAny(perms, "aw")
+		case 'r', 'k', 'l', 'm', 'x':
+			ok = strings.ContainsRune(perms, p)
+		default:
+			// separators and unknown permissions
+			ok = true
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Suggester) pattern(d *Denial, pattern string) *regexp.Regexp {
+	key := d.Snap + "\x00" + d.Revision.String() + "\x00" + pattern
+	if re, ok := s.patterns[key]; ok {
+		return re
+	}
+	snapName, _ := snap.SplitInstanceName(d.Snap)
+	vars := map[string]string{
+		"SNAP_NAME":          regexp.QuoteMeta(snapName),
+		"SNAP_INSTANCE_NAME": regexp.QuoteMeta(d.Snap),
+		"PROC":               "/proc",
+		"HOME":               "(?:/home/[^/]+|/root)",
+		"INSTALL_DIR":        "(?:/snap|/var/lib/snapd/snap)",
+		"pid":                "[0-9]+",
+		"pids":               "[0-9]+",
+		"tid":                "[0-9]+",
+	}
+	if !d.Revision.Unset() {
+		vars["SNAP_REVISION"] = regexp.QuoteMeta(d.Revision.String())
+	}
+	// patterns that cannot be converted are remembered as nil
+	re, _ := regexp.Compile("^" + globToRegexp(pattern, vars) + "$")
+	s.patterns[key] = re
+	return re
+}
+
+// globToRegexp converts an AppArmor path pattern to a regular expression.
+// Variables not in vars match anything.
+func globToRegexp(pattern string, vars map[string]string) string {
+	var buf strings.Builder
+	braces := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '@' && strings.HasPrefix(pattern[i:], "@{"):
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta(pattern[i:]))
+				return buf.String()
+			}
+			value, ok := vars[pattern[i+2:i+end]]
+			if !ok {
+				value = ".*"
+			}
+			buf.WriteString(value)
+			i += end
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '{':
+			braces++
+			buf.WriteString("(?:")
+		case c == '}' && braces > 0:
+			braces--
+			buf.WriteString(")")
+		case c == ',' && braces > 0:
+			buf.WriteString("|")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta(pattern[i:]))
+				return buf.String()
+			}
+			buf.WriteString(pattern[i : i+end+1])
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return buf.String()
+}
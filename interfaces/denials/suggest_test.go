@@ -0,0 +1,194 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"errors"
+	"regexp"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+type suggestSuite struct{}
+
+var _ = Suite(&suggestSuite{})
+
+func (s *suggestSuite) suggester() *denials.Suggester {
+	return denials.NewSuggester([]interfaces.Interface{
+		&ifacetest.TestInterface{
+			InterfaceName: "devices",
+			AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet(`
+# Description: some devices
+###PROMPT### /dev/video[0-9]* rw,
+/dev/bus/usb/[0-9][0-9][0-9]/[0-9][0-9][0-9] r,
+/run/udev/data/c{81,189}:[0-9]* r, # udev data
+deny /dev/mem rw,
+capability sys_rawio,
+network bluetooth,
+`)
+				return nil
+			},
+		},
+		&ifacetest.TestInterface{
+			InterfaceName: "files",
+			AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet(`
+owner @{HOME}/Documents/** rwk,
+@{PROC}/@{pid}/mountinfo r,
+/var/snap/@{SNAP_INSTANCE_NAME}/common/{,**} rw,
+/etc/foo\{bar\} r,
+"/etc/with space" r,
+network inet stream,
+capability sys_admin net_admin,
+`)
+				return nil
+			},
+			SecCompConnectedPlugCallback: func(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet(`
+# Description: mounting
+mount
+umount2 - MNT_DETACH
+~reboot
+@deny delete_module
+`)
+				return nil
+			},
+		},
+		&ifacetest.TestInterface{
+			InterfaceName: "unrestricted",
+			SecCompConnectedPlugCallback: func(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet("@unrestricted")
+				return nil
+			},
+		},
+		&ifacetest.TestInterface{
+			InterfaceName: "broken",
+			AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet("/** rwklmix,")
+				return errors.New("slot needs attributes")
+			},
+		},
+	})
+}
+
+func (s *suggestSuite) TestSuggestFiles(c *C) {
+	sug := s.suggester()
+	for _, tc := range []struct {
+		path, mask string
+		snap       string
+		ifaces     []string
+	}{
+		{"/dev/video0", "rw", "foo", []string{"devices"}},
+		{"/dev/video12", "wc", "foo", []string{"devices"}},
+		{"/dev/bus/usb/001/004", "r", "foo", []string{"devices"}},
+		{"/dev/bus/usb/001/004", "w", "foo", nil},
+		{"/run/udev/data/c189:3", "r", "foo", []string{"devices"}},
+		{"/run/udev/data/c180:3", "r", "foo", nil},
+		{"/dev/mem", "r", "foo", nil},
+		{"/home/user/Documents/a/b.txt", "rk", "foo", []string{"files"}},
+		{"/root/Documents/a.txt", "a", "foo", []string{"files"}},
+		{"/home/user/Music/a.mp3", "r", "foo", nil},
+		{"/home/user/Documents/a.txt", "x", "foo", nil},
+		{"/proc/123/mountinfo", "r", "foo", []string{"files"}},
+		{"/proc/self/mountinfo", "r", "foo", nil},
+		{"/var/snap/foo/common/", "w", "foo", []string{"files"}},
+		{"/var/snap/foo/common/a/b", "r", "foo", []string{"files"}},
+		{"/var/snap/bar/common/a", "r", "foo", nil},
+		{"/var/snap/foo_x/common/a", "r", "foo_x", []string{"files"}},
+		{"/etc/foo{bar}", "r", "foo", []string{"files"}},
+		{"/etc/with space", "r", "foo", []string{"files"}},
+	} {
+		d := &denials.Denial{Kind: denials.KindAppArmor, Snap: tc.snap, Operation: "open", Class: "file", Name: tc.path, Mask: tc.mask}
+		c.Check(sug.Suggest(d), DeepEquals, tc.ifaces, Commentf("%s (%s)", tc.path, tc.mask))
+	}
+}
+
+func (s *suggestSuite) TestSuggestOther(c *C) {
+	sug := s.suggester()
+	for _, tc := range []struct {
+		denial *denials.Denial
+		ifaces []string
+	}{
+		{&denials.Denial{Kind: denials.KindAppArmor, Capability: "sys_rawio"}, []string{"devices"}},
+		{&denials.Denial{Kind: denials.KindAppArmor, Capability: "net_admin"}, []string{"files"}},
+		{&denials.Denial{Kind: denials.KindAppArmor, Capability: "sys_module"}, nil},
+		{&denials.Denial{Kind: denials.KindAppArmor, Family: "bluetooth", SockType: "raw"}, []string{"devices"}},
+		{&denials.Denial{Kind: denials.KindAppArmor, Family: "inet", SockType: "stream"}, []string{"files"}},
+		{&denials.Denial{Kind: denials.KindAppArmor, Family: "inet", SockType: "dgram"}, nil},
+		{&denials.Denial{Kind: denials.KindAppArmor, Operation: "dbus_method_call", Class: "dbus", Bus: "system"}, nil},
+		{&denials.Denial{Kind: denials.KindSeccomp, Syscall: "mount"}, []string{"files", "unrestricted"}},
+		{&denials.Denial{Kind: denials.KindSeccomp, Syscall: "reboot"}, []string{"unrestricted"}},
+		// unresolved system call
+		{&denials.Denial{Kind: denials.KindSeccomp, SyscallNumber: 165}, nil},
+	} {
+		c.Check(sug.Suggest(tc.denial), DeepEquals, tc.ifaces, Commentf(tc.denial.Access()))
+	}
+}
+
+func (s *suggestSuite) TestSuggestBuiltin(c *C) {
+	var ifaces []interfaces.Interface
+	for _, iface := range builtin.Interfaces() {
+		if iface.Name() == "camera" || iface.Name() == "network" {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	c.Assert(ifaces, HasLen, 2)
+	sug := denials.NewSuggester(ifaces)
+	d := &denials.Denial{Kind: denials.KindAppArmor, Snap: "foo", Revision: snap.R(1), Operation: "open", Class: "file", Name: "/dev/video0", Mask: "rw"}
+	c.Check(sug.Suggest(d), DeepEquals, []string{"camera"})
+	d = &denials.Denial{Kind: denials.KindAppArmor, Snap: "foo", Family: "netlink", SockType: "dgram"}
+	c.Check(sug.Suggest(d), DeepEquals, []string{"network"})
+}
+
+func (s *suggestSuite) TestGlobToRegexp(c *C) {
+	vars := map[string]string{"HOME": "/home/[^/]+"}
+	for _, tc := range []struct {
+		glob, path string
+		match      bool
+	}{
+		{"/a/*", "/a/b", true},
+		{"/a/*", "/a/b/c", false},
+		{"/a/**", "/a/b/c", true},
+		{"/a/?", "/a/b", true},
+		{"/a/?", "/a/bc", false},
+		{"/a/{b,c{d,e}}", "/a/ce", true},
+		{"/a/{b,c{d,e}}", "/a/c", false},
+		{"/a/[0-9]", "/a/7", true},
+		{"/a/[^0-9]", "/a/7", false},
+		{"@{HOME}/x", "/home/u/x", true},
+		{"@{UNKNOWN}/x", "/foo/x", true},
+		{"@{UNKNOWN}/x", "/foo/bar/x", true},
+		{"@{UNKNOWN}/x", "/foo/y", false},
+		{"/a.b", "/axb", false},
+		{"/a/[0-9", "/a/[0-9", true},
+	} {
+		re := regexp.MustCompile("^" + denials.GlobToRegexp(tc.glob, vars) + "$")
+		c.Check(re.MatchString(tc.path), Equals, tc.match, Commentf("%s %s", tc.glob, tc.path))
+	}
+}
@@ -0,0 +1,182 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package policy
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+// Declaration origins of the rule that decided about a connection,
+// see Explanation.
+const (
+	OriginBaseDeclaration = "base-declaration"
+	OriginSnapDeclaration = "snap-declaration"
+)
+
+// ConstraintsExplanation describes the evaluation of one of the
+// alternative constraints of an allow or deny section of a rule.
+type ConstraintsExplanation struct {
+	// Matched is true if all the constraints of the alternative
+	// matched.
+	Matched bool
+	// Mismatch describes the first constraint that did not match,
+	// e.g. a failed attribute constraint.
+	Mismatch string
+}
+
+// Explanation describes how a connection or auto-connection policy
+// decision was reached for a ConnectCandidate.
+type Explanation struct {
+	// Kind is either "connection" or "auto-connection".
+	Kind string
+	// Origin is OriginBaseDeclaration or OriginSnapDeclaration,
+	// it is empty if no rule applies to the interface.
+	Origin string
+	// Snap is the name of the snap whose snap-declaration
+	// provided the rule.
+	Snap string
+	// Side is either "plug" or "slot" depending on the kind of
+	// rule that was used.
+	Side string
+	// Deny and Allow describe the evaluation of the alternatives
+	// of the deny and allow sections of the rule. Any matching
+	// deny alternative denies the connection, otherwise at least
+	// one allow alternative must match.
+	Deny  []ConstraintsExplanation
+	Allow []ConstraintsExplanation
+	// SlotsPerPlugAny is set for allowed auto-connections whose
+	// matching allow alternative accepts any number of slots per
+	// plug.
+	SlotsPerPlugAny bool
+	// Err is the resulting error of the check, nil if it is
+	// allowed.
+	Err error
+}
+
+// Allowed returns whether the explained check allows the connection.
+func (e *Explanation) Allowed() bool {
+	return e.Err == nil
+}
+
+// ExplainConnect traces the evaluation of Check.
+func (connc *ConnectCandidate) ExplainConnect() *Explanation {
+	return connc.explain("connection")
+}
+
+// ExplainAutoConnect traces the evaluation of CheckAutoConnect.
+func (connc *ConnectCandidate) ExplainAutoConnect() *Explanation {
+	return connc.explain("auto-connection")
+}
+
+func (connc *ConnectCandidate) explain(kind string) *Explanation {
+	expl := &Explanation{Kind: kind}
+	arity, err := connc.check(kind)
+	expl.Err = err
+	if err == nil && arity != nil {
+		expl.SlotsPerPlugAny = arity.SlotsPerPlugAny()
+	}
+	if connc.BaseDeclaration == nil || connc.Slot.Interface() != connc.Plug.Interface() {
+		return expl
+	}
+
+	plugRule, slotRule, snapRule := connc.rule(connc.Plug.Interface())
+	if plugRule == nil && slotRule == nil {
+		return expl
+	}
+	expl.Origin = OriginBaseDeclaration
+	switch {
+	case plugRule != nil:
+		expl.Side = "plug"
+		if snapRule {
+			expl.Origin = OriginSnapDeclaration
+			expl.Snap = connc.PlugSnapDeclaration.SnapName()
+		}
+		deny, allow := plugRule.DenyConnection, plugRule.AllowConnection
+		if kind == "auto-connection" {
+			deny, allow = plugRule.DenyAutoConnection, plugRule.AllowAutoConnection
+		}
+		expl.Deny = explainPlugConnectionAltConstraints(connc, deny)
+		expl.Allow = explainPlugConnectionAltConstraints(connc, allow)
+	case slotRule != nil:
+		expl.Side = "slot"
+		if snapRule {
+			expl.Origin = OriginSnapDeclaration
+			expl.Snap = connc.SlotSnapDeclaration.SnapName()
+		}
+		deny, allow := slotRule.DenyConnection, slotRule.AllowConnection
+		if kind == "auto-connection" {
+			deny, allow = slotRule.DenyAutoConnection, slotRule.AllowAutoConnection
+		}
+		expl.Deny = explainSlotConnectionAltConstraints(connc, deny)
+		expl.Allow = explainSlotConnectionAltConstraints(connc, allow)
+	}
+	return expl
+}
+
+func explainConstraints1(err error) ConstraintsExplanation {
+	if err == nil {
+		return ConstraintsExplanation{Matched: true}
+	}
+	return ConstraintsExplanation{Mismatch: err.Error()}
+}
+
+func isNeverMatch(plugAttrs, slotAttrs *asserts.AttributeConstraints) bool {
+	return plugAttrs == asserts.NeverMatchAttributes && slotAttrs == asserts.NeverMatchAttributes
+}
+
+func explainPlugConnectionAltConstraints(connc *ConnectCandidate, altConstraints []*asserts.PlugConnectionConstraints) []ConstraintsExplanation {
+	expls := make([]ConstraintsExplanation, 0, len(altConstraints))
+	for _, constraints := range altConstraints {
+		if isNeverMatch(constraints.PlugAttributes, constraints.SlotAttributes) {
+			expls = append(expls, ConstraintsExplanation{Mismatch: "constraint is false"})
+			continue
+		}
+		expls = append(expls, explainConstraints1(checkPlugConnectionConstraints1(connc, constraints)))
+	}
+	return expls
+}
+
+func explainSlotConnectionAltConstraints(connc *ConnectCandidate, altConstraints []*asserts.SlotConnectionConstraints) []ConstraintsExplanation {
+	expls := make([]ConstraintsExplanation, 0, len(altConstraints))
+	for _, constraints := range altConstraints {
+		if isNeverMatch(constraints.PlugAttributes, constraints.SlotAttributes) {
+			expls = append(expls, ConstraintsExplanation{Mismatch: "constraint is false"})
+			continue
+		}
+		expls = append(expls, explainConstraints1(checkSlotConnectionConstraints1(connc, constraints)))
+	}
+	return expls
+}
+
+// String returns a short summary of the explained decision.
+func (e *Explanation) String() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Origin == "" {
+		return fmt.Sprintf("%s allowed, no rule applies", e.Kind)
+	}
+	if e.Origin == OriginSnapDeclaration {
+		return fmt.Sprintf("%s allowed by %s rule of %s for %q snap", e.Kind, e.Side, e.Origin, e.Snap)
+	}
+	return fmt.Sprintf("%s allowed by %s rule of %s", e.Kind, e.Side, e.Origin)
+}
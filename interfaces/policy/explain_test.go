@@ -0,0 +1,114 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package policy_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+)
+
+func (s *policySuite) connectCandidate(iface string) *policy.ConnectCandidate {
+	return &policy.ConnectCandidate{
+		Plug:                interfaces.NewConnectedPlug(s.plugSnap.Plugs[iface], s.plugAppSet, nil, nil),
+		Slot:                interfaces.NewConnectedSlot(s.slotSnap.Slots[iface], s.slotAppSet, nil, nil),
+		PlugSnapDeclaration: s.plugDecl,
+		SlotSnapDeclaration: s.slotDecl,
+		BaseDeclaration:     s.baseDecl,
+	}
+}
+
+func (s *policySuite) TestExplainConnectBaseDeclAlternatives(c *C) {
+	expl := s.connectCandidate("plug-or-p1-s2").ExplainConnect()
+	c.Check(expl.Allowed(), Equals, false)
+	c.Check(expl.Err, ErrorMatches, `connection not allowed by plug rule of interface "plug-or"`)
+	c.Check(expl.Kind, Equals, "connection")
+	c.Check(expl.Origin, Equals, policy.OriginBaseDeclaration)
+	c.Check(expl.Snap, Equals, "")
+	c.Check(expl.Side, Equals, "plug")
+	c.Check(expl.Deny, DeepEquals, []policy.ConstraintsExplanation{
+		{Mismatch: "constraint is false"},
+	})
+	c.Check(expl.Allow, DeepEquals, []policy.ConstraintsExplanation{
+		{Mismatch: `attribute "s" value "S2" does not match ^(S1)$`},
+		{Mismatch: `attribute "p" value "P1" does not match ^(P2)$`},
+	})
+	c.Check(expl.String(), Equals, `connection not allowed by plug rule of interface "plug-or"`)
+
+	expl = s.connectCandidate("plug-or-p2-s2").ExplainConnect()
+	c.Check(expl.Allowed(), Equals, true)
+	c.Check(expl.Allow, DeepEquals, []policy.ConstraintsExplanation{
+		{Mismatch: `attribute "p" value "P2" does not match ^(P1)$`},
+		{Matched: true},
+	})
+	c.Check(expl.String(), Equals, "connection allowed by plug rule of base-declaration")
+}
+
+func (s *policySuite) TestExplainConnectSnapDecl(c *C) {
+	expl := s.connectCandidate("snap-slot-deny").ExplainConnect()
+	c.Check(expl.Allowed(), Equals, false)
+	c.Check(expl.Origin, Equals, policy.OriginSnapDeclaration)
+	c.Check(expl.Snap, Equals, "slot-snap")
+	c.Check(expl.Side, Equals, "slot")
+	c.Check(expl.Deny, DeepEquals, []policy.ConstraintsExplanation{{Matched: true}})
+	c.Check(expl.String(), Equals, `connection denied by slot rule of interface "snap-slot-deny" for "slot-snap" snap`)
+
+	// the snap-declaration of the plug side takes precedence
+	expl = s.connectCandidate("snap-slot-deny-snap-plug-allow").ExplainConnect()
+	c.Check(expl.Allowed(), Equals, true)
+	c.Check(expl.Origin, Equals, policy.OriginSnapDeclaration)
+	c.Check(expl.Snap, Equals, "plug-snap")
+	c.Check(expl.Side, Equals, "plug")
+	c.Check(expl.String(), Equals, `connection allowed by plug rule of snap-declaration for "plug-snap" snap`)
+}
+
+func (s *policySuite) TestExplainAutoConnect(c *C) {
+	expl := s.connectCandidate("auto-base-plug-not-allow").ExplainAutoConnect()
+	c.Check(expl.Allowed(), Equals, false)
+	c.Check(expl.Kind, Equals, "auto-connection")
+	c.Check(expl.Origin, Equals, policy.OriginBaseDeclaration)
+	c.Check(expl.Side, Equals, "plug")
+	c.Check(expl.Allow, DeepEquals, []policy.ConstraintsExplanation{
+		{Mismatch: "constraint is false"},
+	})
+
+	expl = s.connectCandidate("auto-base-plug-allow").ExplainAutoConnect()
+	c.Check(expl.Allowed(), Equals, true)
+	c.Check(expl.SlotsPerPlugAny, Equals, false)
+	c.Check(expl.Allow, DeepEquals, []policy.ConstraintsExplanation{{Matched: true}})
+}
+
+func (s *policySuite) TestExplainNoRule(c *C) {
+	expl := s.connectCandidate("random").ExplainConnect()
+	c.Check(expl.Allowed(), Equals, true)
+	c.Check(expl.Origin, Equals, "")
+	c.Check(expl.Deny, HasLen, 0)
+	c.Check(expl.Allow, HasLen, 0)
+	c.Check(expl.String(), Equals, "connection allowed, no rule applies")
+}
+
+func (s *policySuite) TestExplainMismatchedInterfaces(c *C) {
+	cand := s.connectCandidate("random")
+	cand.Plug = interfaces.NewConnectedPlug(s.plugSnap.Plugs["mismatchy"], s.plugAppSet, nil, nil)
+	expl := cand.ExplainConnect()
+	c.Check(expl.Err, ErrorMatches, `cannot connect mismatched plug interface "bar" to slot interface "random"`)
+	c.Check(expl.Origin, Equals, "")
+}
@@ -235,8 +235,7 @@ func (connc *ConnectCandidate) checkSlotRule(kind string, rule *asserts.SlotRule
 }
 
 func (connc *ConnectCandidate) check(kind string) (interfaces.SideArity, error) {
-	baseDecl := connc.BaseDeclaration
-	if baseDecl == nil {
+	if connc.BaseDeclaration == nil {
 		return nil, fmt.Errorf("internal error: improperly initialized ConnectCandidate")
 	}
 
@@ -246,23 +245,39 @@ func (connc *ConnectCandidate) check(kind string) (interfaces.SideArity, error)
 		return nil, fmt.Errorf("cannot connect mismatched plug interface %q to slot interface %q", iface, connc.Slot.Interface())
 	}
 
+	plugRule, slotRule, snapRule := connc.rule(iface)
+	switch {
+	case plugRule != nil:
+		return connc.checkPlugRule(kind, plugRule, snapRule)
+	case slotRule != nil:
+		return connc.checkSlotRule(kind, slotRule, snapRule)
+	}
+	return nil, nil
+}
+
+// rule returns the plug or slot rule that decides about connecting
+// the candidate for the given interface, in order of precedence: the
+// plug snap-declaration, the slot snap-declaration, then the plug and
+// slot rules of the base-declaration. snapRule is true if the rule
+// comes from a snap-declaration.
+func (connc *ConnectCandidate) rule(iface string) (plugRule *asserts.PlugRule, slotRule *asserts.SlotRule, snapRule bool) {
 	if plugDecl := connc.PlugSnapDeclaration; plugDecl != nil {
 		if rule := plugDecl.PlugRule(iface); rule != nil {
-			return connc.checkPlugRule(kind, rule, true)
+			return rule, nil, true
 		}
 	}
 	if slotDecl := connc.SlotSnapDeclaration; slotDecl != nil {
 		if rule := slotDecl.SlotRule(iface); rule != nil {
-			return connc.checkSlotRule(kind, rule, true)
+			return nil, rule, true
 		}
 	}
-	if rule := baseDecl.PlugRule(iface); rule != nil {
-		return connc.checkPlugRule(kind, rule, false)
+	if rule := connc.BaseDeclaration.PlugRule(iface); rule != nil {
+		return rule, nil, false
 	}
-	if rule := baseDecl.SlotRule(iface); rule != nil {
-		return connc.checkSlotRule(kind, rule, false)
+	if rule := connc.BaseDeclaration.SlotRule(iface); rule != nil {
+		return nil, rule, false
 	}
-	return nil, nil
+	return nil, nil, false
 }
 
 // Check checks whether the connection is allowed.
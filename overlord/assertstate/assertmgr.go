@@ -51,8 +51,11 @@ func Manager(s *state.State, runner *state.TaskRunner) (*AssertManager, error) {
 	}
 
 	s.Lock()
+	defer s.Unlock()
 	ReplaceDB(s, db)
-	s.Unlock()
+	if err := restoreLocalAuthorities(s, db); err != nil {
+		return nil, err
+	}
 
 	return &AssertManager{}, nil
 }
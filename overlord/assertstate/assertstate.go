@@ -41,12 +41,118 @@ import (
 // Add the given assertion to the system assertion database.
 func Add(s *state.State, a asserts.Assertion) error {
 	// TODO: deal together with asserts itself with (cascading) side effects of possible assertion updates
-	return cachedDB(s).Add(a)
+	db := cachedDB(s)
+	var pending []*asserts.ConfdbSchema
+	if schema, ok := a.(*asserts.ConfdbSchema); ok {
+		pending = append(pending, schema)
+	}
+	return commitWithConfdbSchemaUpdates(s, db, pending, func() error {
+		return db.Add(a)
+	})
 }
 
 // AddBatch adds the given assertion batch to the system assertion database.
 func AddBatch(s *state.State, batch *asserts.Batch, opts *asserts.CommitOptions) error {
-	return batch.CommitTo(cachedDB(s), opts)
+	db := cachedDB(s)
+	pending, err := pendingConfdbSchemas(batch.Backstore())
+	if err != nil {
+		return err
+	}
+	return commitWithConfdbSchemaUpdates(s, db, pending, func() error {
+		return batch.CommitTo(db, opts)
+	})
+}
+
+// ConfdbSchemaMigration migrates the data stored in a confdb to a new
+// revision of its confdb-schema. The data is migrated along with the
+// assertion: either both are added or neither is.
+type ConfdbSchemaMigration interface {
+	// Apply migrates the data before the new revision is added.
+	Apply() error
+	// Revert restores the data if the new revision couldn't be added.
+	Revert()
+	// Done is called once the new revision has been added.
+	Done()
+}
+
+// PrepareConfdbSchemaUpdate is called before a new revision of a
+// confdb-schema assertion replaces the current one. It returns an error if
+// the data stored in the confdb cannot be migrated to the new revision, in
+// which case the assertion is refused. Otherwise, it may return a migration
+// of the data to apply along with the new revision.
+var PrepareConfdbSchemaUpdate = func(st *state.State, current, update *asserts.ConfdbSchema) (ConfdbSchemaMigration, error) {
+	return nil, nil
+}
+
+// pendingConfdbSchemas returns the confdb-schema assertions in the backstore.
+func pendingConfdbSchemas(bs asserts.Backstore) ([]*asserts.ConfdbSchema, error) {
+	var schemas []*asserts.ConfdbSchema
+	err := bs.Search(asserts.ConfdbSchemaType, nil, func(a asserts.Assertion) {
+		schemas = append(schemas, a.(*asserts.ConfdbSchema))
+	}, asserts.ConfdbSchemaType.MaxSupportedFormat())
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// commitWithConfdbSchemaUpdates prepares the migration of the data stored in
+// the confdbs whose confdb-schemas are updated by the pending assertions,
+// migrates it and commits the assertions. The data of the confdbs whose
+// updated confdb-schemas couldn't be added is restored, so it's always stored
+// according to the confdb-schema in the database.
+func commitWithConfdbSchemaUpdates(st *state.State, db *asserts.Database, pending []*asserts.ConfdbSchema, commit func() error) error {
+	type update struct {
+		schema    *asserts.ConfdbSchema
+		migration ConfdbSchemaMigration
+	}
+
+	var updates []update
+	for _, schema := range pending {
+		current, err := ConfdbSchema(st, schema.AccountID(), schema.Name())
+		if err != nil {
+			if errors.Is(err, &asserts.NotFoundError{}) {
+				// no data can be stored without a confdb-schema
+				continue
+			}
+			return err
+		}
+
+		if current.Revision() >= schema.Revision() {
+			continue
+		}
+
+		migration, err := PrepareConfdbSchemaUpdate(st, current, schema)
+		if err != nil {
+			return fmt.Errorf("cannot update confdb-schema %s/%s to revision %d: %w", schema.AccountID(), schema.Name(), schema.Revision(), err)
+		}
+		if migration != nil {
+			updates = append(updates, update{schema: schema, migration: migration})
+		}
+	}
+
+	for i, u := range updates {
+		if err := u.migration.Apply(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				updates[j].migration.Revert()
+			}
+			return fmt.Errorf("cannot migrate confdb %s/%s to revision %d: %w", u.schema.AccountID(), u.schema.Name(), u.schema.Revision(), err)
+		}
+	}
+
+	commitErr := commit()
+
+	for i := len(updates) - 1; i >= 0; i-- {
+		u := updates[i]
+		current, err := ConfdbSchema(st, u.schema.AccountID(), u.schema.Name())
+		if err != nil || current.Revision() != u.schema.Revision() {
+			u.migration.Revert()
+			continue
+		}
+		u.migration.Done()
+	}
+
+	return commitErr
 }
 
 func findError(format string, ref *asserts.Ref, err error) error {
@@ -94,11 +200,12 @@ func RefreshSnapDeclarations(s *state.State, userID int, opts *RefreshAssertions
 	logger.Noticef("bulk refresh of snap-declarations failed, falling back to one-by-one assertion fetching: %v", err)
 
 	modelAs := deviceCtx.Model()
+	db := cachedDB(s)
 
 	fetching := func(f asserts.Fetcher) error {
 		for instanceName, snapst := range snapStates {
 			sideInfo := snapst.CurrentSideInfo()
-			if sideInfo.SnapID == "" {
+			if sideInfo.SnapID == "" || isLocallyDeclared(db, sideInfo.SnapID) {
 				continue
 			}
 			if err := snapasserts.FetchSnapDeclaration(f, sideInfo.SnapID); err != nil {
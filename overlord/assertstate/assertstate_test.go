@@ -1380,6 +1380,69 @@ func (s *assertMgrSuite) TestRefreshSnapDeclarationsNop(c *C) {
 	c.Check(s.fakeStore.(*fakeStore).opts.Scheduled, Equals, true)
 }
 
+func (s *assertMgrSuite) TestLocalAuthority(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setModel(sysdb.GenericClassicModel())
+
+	privKey, _ := assertstest.GenerateKey(752)
+	localSigning := assertstest.NewSigningDB("acme-local", privKey)
+	localAcct := assertstest.NewAccount(localSigning, "acme-local", map[string]any{
+		"account-id": "acme-local",
+	}, "")
+	localKey := assertstest.NewAccountKey(localSigning, localAcct, nil, privKey.PublicKey(), "")
+
+	err := assertstate.AddLocalAuthority(s.state, localAcct, localKey)
+	c.Assert(err, IsNil)
+	ids, err := assertstate.LocalAuthorities(s.state)
+	c.Assert(err, IsNil)
+	c.Check(ids, DeepEquals, []string{"acme-local"})
+	c.Check(assertstate.DB(s.state).IsLocalAuthority("acme-local"), Equals, true)
+
+	// snaps declared by the local authority can be added
+	localDecl, err := localSigning.Sign(asserts.SnapDeclarationType, map[string]any{
+		"series":       "16",
+		"snap-id":      "local-foo-id",
+		"snap-name":    "local-foo",
+		"publisher-id": "acme-local",
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, localDecl)
+	c.Assert(err, IsNil)
+	s.stateFromDecl(c, localDecl.(*asserts.SnapDeclaration), "", snap.R(1))
+
+	// the store is not asked about them
+	err = assertstate.RefreshSnapDeclarations(s.state, 0, nil)
+	c.Assert(err, IsNil)
+
+	// the local authority is restored on restart
+	s.state.Unlock()
+	_, err = assertstate.Manager(s.state, s.o.TaskRunner())
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	db := assertstate.DB(s.state)
+	c.Check(db.IsLocalAuthority("acme-local"), Equals, true)
+	_, err = db.Find(asserts.SnapDeclarationType, map[string]string{
+		"series":  "16",
+		"snap-id": "local-foo-id",
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *assertMgrSuite) TestAddLocalAuthorityError(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// not self-signed
+	err := assertstate.AddLocalAuthority(s.state, s.dev1Acct, s.dev1AcctKey)
+	c.Check(err, ErrorMatches, `cannot add local authority ".*": account and account-key must be self-signed by the account`)
+	ids, err := assertstate.LocalAuthorities(s.state)
+	c.Assert(err, IsNil)
+	c.Check(ids, HasLen, 0)
+}
+
 func (s *assertMgrSuite) TestRefreshSnapDeclarationsNoStore(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -5405,6 +5468,242 @@ func (s *assertMgrSuite) TestConfdb(c *C) {
 	c.Check(schema.DatabagSchema, NotNil)
 }
 
+func (s *assertMgrSuite) TestConfdbSchemaUpdate(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := assertstate.Add(s.state, s.storeSigning.StoreAccountKey(""))
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1Acct)
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1AcctKey)
+	c.Assert(err, IsNil)
+
+	views := map[string]any{
+		"a-view": map[string]any{
+			"rules": []any{
+				map[string]any{"request": "a", "storage": "a"},
+			},
+		},
+	}
+	confdbRev0 := s.confdb(c, "foo", map[string]any{"views": views}, `{
+  "storage": {
+    "schema": {
+      "a": "string"
+    }
+  }
+}`)
+	confdbRev1 := s.confdb(c, "foo", map[string]any{"views": views, "revision": "1", "format": "1"}, `{
+  "migrations": [
+    {
+      "revision": 1,
+      "rules": [
+        {
+          "from": "a",
+          "to": "a",
+          "type": "int"
+        }
+      ]
+    }
+  ],
+  "storage": {
+    "schema": {
+      "a": "int"
+    }
+  }
+}`)
+
+	var calls []string
+	restore := testutil.Backup(&assertstate.PrepareConfdbSchemaUpdate)
+	defer restore()
+	assertstate.PrepareConfdbSchemaUpdate = func(st *state.State, current, update *asserts.ConfdbSchema) (assertstate.ConfdbSchemaMigration, error) {
+		calls = append(calls, "prepare")
+		c.Check(current.Revision(), Equals, 0)
+		c.Check(update.Revision(), Equals, 1)
+		c.Check(update.MigrationsSince(current.Revision()), HasLen, 1)
+
+		checkRevision := func(rev int) {
+			as, err := assertstate.ConfdbSchema(st, update.AccountID(), update.Name())
+			c.Assert(err, IsNil)
+			c.Check(as.Revision(), Equals, rev)
+		}
+
+		return &fakeConfdbMigration{
+			apply: func() error {
+				// the data is migrated before the new revision is added
+				checkRevision(0)
+				calls = append(calls, "apply")
+				return nil
+			},
+			done: func() {
+				checkRevision(1)
+				calls = append(calls, "done")
+			},
+			revert: func() {
+				calls = append(calls, "revert")
+			},
+		}, nil
+	}
+
+	// nothing to migrate when the confdb-schema is first added
+	err = assertstate.Add(s.state, confdbRev0)
+	c.Assert(err, IsNil)
+	c.Check(calls, HasLen, 0)
+
+	err = assertstate.Add(s.state, confdbRev1)
+	c.Assert(err, IsNil)
+	c.Check(calls, DeepEquals, []string{"prepare", "apply", "done"})
+}
+
+type fakeConfdbMigration struct {
+	apply  func() error
+	revert func()
+	done   func()
+}
+
+func (m *fakeConfdbMigration) Apply() error { return m.apply() }
+func (m *fakeConfdbMigration) Revert()      { m.revert() }
+func (m *fakeConfdbMigration) Done()        { m.done() }
+
+// failingPutBackstore fails to store the given revision of confdb-schemas.
+type failingPutBackstore struct {
+	asserts.Backstore
+	revision int
+}
+
+func (b *failingPutBackstore) Put(assertType *asserts.AssertionType, as asserts.Assertion) error {
+	if assertType == asserts.ConfdbSchemaType && as.Revision() == b.revision {
+		return errors.New("cannot store assertion")
+	}
+	return b.Backstore.Put(assertType, as)
+}
+
+func (s *assertMgrSuite) testConfdbSchemaUpdateNotAdded(c *C, failPut bool, applyErr error) (calls []string, err error) {
+	if failPut {
+		db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+			Backstore: &failingPutBackstore{Backstore: asserts.NewMemoryBackstore(), revision: 1},
+			Trusted:   s.storeSigning.Trusted,
+		})
+		c.Assert(err, IsNil)
+		assertstate.ReplaceDB(s.state, db)
+	}
+
+	err = assertstate.Add(s.state, s.storeSigning.StoreAccountKey(""))
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1Acct)
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1AcctKey)
+	c.Assert(err, IsNil)
+
+	views := map[string]any{
+		"a-view": map[string]any{
+			"rules": []any{
+				map[string]any{"request": "a", "storage": "a"},
+			},
+		},
+	}
+	body := `{
+  "storage": {
+    "schema": {
+      "a": "string"
+    }
+  }
+}`
+	err = assertstate.Add(s.state, s.confdb(c, "foo", map[string]any{"views": views}, body))
+	c.Assert(err, IsNil)
+
+	restore := testutil.Backup(&assertstate.PrepareConfdbSchemaUpdate)
+	defer restore()
+	assertstate.PrepareConfdbSchemaUpdate = func(*state.State, *asserts.ConfdbSchema, *asserts.ConfdbSchema) (assertstate.ConfdbSchemaMigration, error) {
+		return &fakeConfdbMigration{
+			apply: func() error {
+				calls = append(calls, "apply")
+				return applyErr
+			},
+			done:   func() { calls = append(calls, "done") },
+			revert: func() { calls = append(calls, "revert") },
+		}, nil
+	}
+
+	batch := asserts.NewBatch(nil)
+	c.Assert(batch.Add(s.confdb(c, "foo", map[string]any{"views": views, "revision": "1"}, body)), IsNil)
+	err = assertstate.AddBatch(s.state, batch, nil)
+
+	// the current revision is kept
+	as, asErr := assertstate.ConfdbSchema(s.state, s.dev1Acct.AccountID(), "foo")
+	c.Assert(asErr, IsNil)
+	c.Check(as.Revision(), Equals, 0)
+	return calls, err
+}
+
+func (s *assertMgrSuite) TestConfdbSchemaUpdateApplyError(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	const failPut = false
+	calls, err := s.testConfdbSchemaUpdateNotAdded(c, failPut, errors.New("boom"))
+	c.Assert(err, ErrorMatches, fmt.Sprintf("cannot migrate confdb %s/foo to revision 1: boom", s.dev1Acct.AccountID()))
+	c.Check(calls, DeepEquals, []string{"apply"})
+}
+
+func (s *assertMgrSuite) TestConfdbSchemaUpdateRevertedIfNotAdded(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// the assertion can't be stored once the data has already been migrated
+	const failPut = true
+	calls, err := s.testConfdbSchemaUpdateNotAdded(c, failPut, nil)
+	c.Assert(err, ErrorMatches, `(?s).*cannot store assertion.*`)
+	c.Check(calls, DeepEquals, []string{"apply", "revert"})
+}
+
+func (s *assertMgrSuite) TestConfdbSchemaUpdateRefused(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := assertstate.Add(s.state, s.storeSigning.StoreAccountKey(""))
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1Acct)
+	c.Assert(err, IsNil)
+	err = assertstate.Add(s.state, s.dev1AcctKey)
+	c.Assert(err, IsNil)
+
+	views := map[string]any{
+		"a-view": map[string]any{
+			"rules": []any{
+				map[string]any{"request": "a", "storage": "a"},
+			},
+		},
+	}
+	body := `{
+  "storage": {
+    "schema": {
+      "a": "string"
+    }
+  }
+}`
+	err = assertstate.Add(s.state, s.confdb(c, "foo", map[string]any{"views": views}, body))
+	c.Assert(err, IsNil)
+
+	restore := testutil.Backup(&assertstate.PrepareConfdbSchemaUpdate)
+	defer restore()
+	assertstate.PrepareConfdbSchemaUpdate = func(*state.State, *asserts.ConfdbSchema, *asserts.ConfdbSchema) (assertstate.ConfdbSchemaMigration, error) {
+		return nil, errors.New("boom")
+	}
+
+	batch := asserts.NewBatch(nil)
+	err = batch.Add(s.confdb(c, "foo", map[string]any{"views": views, "revision": "1"}, body))
+	c.Assert(err, IsNil)
+
+	err = assertstate.AddBatch(s.state, batch, nil)
+	c.Assert(err, ErrorMatches, fmt.Sprintf("cannot update confdb-schema %s/foo to revision 1: boom", s.dev1Acct.AccountID()))
+
+	// the current revision is kept
+	as, err := assertstate.ConfdbSchema(s.state, s.dev1Acct.AccountID(), "foo")
+	c.Assert(err, IsNil)
+	c.Check(as.Revision(), Equals, 0)
+}
+
 func (s *assertMgrSuite) TestValidateComponent(c *C) {
 	s.testValidateComponent(c, testValidateComponentOpts{})
 }
@@ -68,7 +68,7 @@ func bulkRefreshSnapDeclarations(s *state.State, snapStates map[string]*snapstat
 	c := 0
 	for instanceName, snapst := range snapStates {
 		sideInfo := snapst.CurrentSideInfo()
-		if sideInfo.SnapID == "" {
+		if sideInfo.SnapID == "" || isLocallyDeclared(db, sideInfo.SnapID) {
 			continue
 		}
 
@@ -328,7 +328,17 @@ func resolvePool(s *state.State, pool *asserts.Pool, checkBeforeCommit func(*ass
 			return err
 		}
 	}
-	pool.CommitTo(db)
+	pending, err := pendingConfdbSchemas(pool.Backstore())
+	if err != nil {
+		return err
+	}
+	err = commitWithConfdbSchemaUpdates(s, db, pending, func() error {
+		pool.CommitTo(db)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
 	errors := pool.Errors()
 	if len(errors) != 0 {
@@ -84,7 +84,13 @@ func doFetch(s *state.State, userID int, deviceCtx snapstate.DeviceContext, batc
 	// TODO: trigger w. caller a global validity check if a is revoked
 	// (but try to save as much possible still), or err is a check error
 	if commitBatch {
-		return batch.CommitTo(db, nil)
+		pending, err := pendingConfdbSchemas(batch.Backstore())
+		if err != nil {
+			return err
+		}
+		return commitWithConfdbSchemaUpdates(s, db, pending, func() error {
+			return batch.CommitTo(db, nil)
+		})
 	}
 
 	return nil
@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assertstate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/release"
+)
+
+// localAuthoritiesKey is the state key under which the local authorities are
+// kept, as a map from account id to their encoded account and account-key
+// assertions.
+const localAuthoritiesKey = "local-authorities"
+
+func localAuthorities(st *state.State) (map[string]string, error) {
+	var encoded map[string]string
+	if err := st.Get(localAuthoritiesKey, &encoded); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// AddLocalAuthority registers the account as a local authority of the system
+// assertion database, whose self-signed account-key is trusted to sign
+// snap-declaration and snap-revision assertions for locally built snaps.
+// The local authority is remembered across restarts.
+func AddLocalAuthority(st *state.State, acct *asserts.Account, acctKey *asserts.AccountKey) error {
+	db := cachedDB(st)
+	if err := db.AddLocalAuthority(acct, acctKey); err != nil {
+		return err
+	}
+
+	encoded, err := localAuthorities(st)
+	if err != nil {
+		return err
+	}
+	if encoded == nil {
+		encoded = make(map[string]string)
+	}
+	var buf bytes.Buffer
+	enc := asserts.NewEncoder(&buf)
+	for _, a := range []asserts.Assertion{acct, acctKey} {
+		if err := enc.Encode(a); err != nil {
+			return fmt.Errorf("cannot encode local authority %q: %v", acct.AccountID(), err)
+		}
+	}
+	encoded[acct.AccountID()] = buf.String()
+	st.Set(localAuthoritiesKey, encoded)
+	return nil
+}
+
+// LocalAuthorities returns the account ids of the local authorities.
+func LocalAuthorities(st *state.State) ([]string, error) {
+	encoded, err := localAuthorities(st)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(encoded))
+	for id := range encoded {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// restoreLocalAuthorities adds the remembered local authorities to the
+// database. The ones that cannot be added anymore are logged and skipped.
+func restoreLocalAuthorities(st *state.State, db *asserts.Database) error {
+	encoded, err := localAuthorities(st)
+	if err != nil {
+		return err
+	}
+	for id, enc := range encoded {
+		if err := addLocalAuthorityFromEncoded(db, enc); err != nil {
+			logger.Noticef("cannot restore local authority %q: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func addLocalAuthorityFromEncoded(db *asserts.Database, enc string) error {
+	var acct *asserts.Account
+	var acctKey *asserts.AccountKey
+	dec := asserts.NewDecoder(bytes.NewBufferString(enc))
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch x := a.(type) {
+		case *asserts.Account:
+			acct = x
+		case *asserts.AccountKey:
+			acctKey = x
+		}
+	}
+	if acct == nil || acctKey == nil {
+		return errors.New("missing account or account-key assertion")
+	}
+	return db.AddLocalAuthority(acct, acctKey)
+}
+
+// isLocallyDeclared returns whether the snap with the given id was declared
+// by a local authority, the store knows nothing about such snaps.
+func isLocallyDeclared(db asserts.RODatabase, snapID string) bool {
+	a, err := db.Find(asserts.SnapDeclarationType, map[string]string{
+		"series":  release.Series,
+		"snap-id": snapID,
+	})
+	if err != nil {
+		return false
+	}
+	return db.IsLocalAuthority(a.AuthorityID())
+}
@@ -0,0 +1,235 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/randutil"
+)
+
+var (
+	devicestateSignSerialProof = devicestate.SignSerialProof
+
+	// assemblePeriod is the time between two publications of routes during
+	// an assembly session.
+	assemblePeriod = 5 * time.Second
+)
+
+// AssembleOptions are the parameters of an assembly session.
+type AssembleOptions struct {
+	// Secret is shared by all the devices taking part in the session.
+	Secret string `json:"secret"`
+	// Address is the address at which this device receives messages, during
+	// the session and once the cluster is assembled.
+	Address string `json:"address"`
+	// ExpectedSize is the number of devices taking part in the session, the
+	// session ends once they were all found.
+	ExpectedSize int `json:"expected-size"`
+	// Peers are the addresses of the devices taking part in the session. If
+	// empty, they're discovered over mDNS.
+	Peers []string `json:"peers,omitempty"`
+}
+
+// Assemble returns the task set that makes this device take part in a cluster
+// assembly session, and records the devices it assembled with as the peers it
+// trusts once the session is over. Members of an existing cluster take part in
+// the session with the TLS certificate they were assembled with.
+func Assemble(st *state.State, opts AssembleOptions) (*state.TaskSet, error) {
+	if opts.Secret == "" {
+		return nil, errors.New("cannot assemble cluster without a secret")
+	}
+	if _, _, err := net.SplitHostPort(opts.Address); err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", opts.Address, err)
+	}
+	if opts.ExpectedSize < 1 {
+		return nil, errors.New("cannot assemble cluster without its expected size")
+	}
+
+	for _, chg := range st.Changes() {
+		if chg.Status().Ready() {
+			continue
+		}
+		for _, t := range chg.Tasks() {
+			if t.Kind() == "assemble-cluster" {
+				return nil, fmt.Errorf("cluster assembly already in progress in change %s", chg.ID())
+			}
+		}
+	}
+
+	t := st.NewTask("assemble-cluster", "Assemble cluster")
+	t.Set("assemble-options", opts)
+	return state.NewTaskSet(t), nil
+}
+
+func (m *ClusterManager) doAssembleCluster(t *state.Task, tomb *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	var opts AssembleOptions
+	err := t.Get("assemble-options", &opts)
+	var config assemblestate.AssembleConfig
+	if err == nil {
+		config, err = assembleConfig(st, opts)
+	}
+	db := assertstate.TemporaryDB(st)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	as, err := assemblestate.NewAssembleState(config, assemblestate.AssembleSession{},
+		func(self assemblestate.DeviceToken, identified func(assemblestate.DeviceToken) bool) (assemblestate.RouteSelector, error) {
+			return assemblestate.NewPrioritySelector(self, nil, identified), nil
+		},
+		// the session isn't resumed if snapd restarts
+		func(assemblestate.AssembleSession) {},
+		db,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", opts.Address)
+	if err != nil {
+		return fmt.Errorf("cannot listen for assembly messages: %v", err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(tomb.Context(nil), assemblestate.AssembleSessionLength)
+	defer cancel()
+
+	var discovery assemblestate.Discovery = assemblestate.StaticDiscovery(opts.Peers)
+	if len(opts.Peers) == 0 {
+		discovery = &assemblestate.MDNSDiscovery{Instance: string(config.RDT)}
+	}
+
+	ids, _, err := as.Run(ctx, ln, assemblestate.StartDiscovery(ctx, discovery, opts.Address), assemblestate.RunOptions{
+		Period: assemblePeriod,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot assemble cluster: %v", err)
+	}
+	if len(ids) < opts.ExpectedSize {
+		return fmt.Errorf("cannot assemble cluster: found %d of %d devices", len(ids), opts.ExpectedSize)
+	}
+
+	st.Lock()
+	defer st.Unlock()
+
+	if err := RecordAssembly(st, opts.Address, config.TLSCert, config.TLSKey, ids); err != nil {
+		return err
+	}
+
+	// the secret is of no use once the session is over
+	opts.Secret = ""
+	t.Set("assemble-options", opts)
+	return nil
+}
+
+// assembleConfig returns the configuration with which this device takes part
+// in an assembly session.
+func assembleConfig(st *state.State, opts AssembleOptions) (assemblestate.AssembleConfig, error) {
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return assemblestate.AssembleConfig{}, fmt.Errorf("cannot assemble cluster without a serial: %v", err)
+	}
+
+	cert, key, err := Credentials(st)
+	if errors.Is(err, ErrNoPeers) {
+		cert, key, err = generateTLSCredentials()
+	}
+	if err != nil {
+		return assemblestate.AssembleConfig{}, err
+	}
+
+	rdt, err := randutil.CryptoToken(16)
+	if err != nil {
+		return assemblestate.AssembleConfig{}, err
+	}
+
+	return assemblestate.AssembleConfig{
+		Secret:       opts.Secret,
+		RDT:          assemblestate.DeviceToken(rdt),
+		TLSCert:      cert,
+		TLSKey:       key,
+		ExpectedSize: opts.ExpectedSize,
+		Serial:       serial,
+		Signer: func(data []byte) ([]byte, error) {
+			st.Lock()
+			defer st.Unlock()
+			return devicestateSignSerialProof(st, data)
+		},
+	}, nil
+}
+
+// generateTLSCredentials returns a new self-signed TLS certificate and its
+// key, PEM-encoded. Peers know the device by the fingerprint of the
+// certificate rather than by its subject.
+func generateTLSCredentials() (cert, key []byte, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate cluster TLS key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate cluster TLS certificate: %v", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "snapd cluster"},
+		NotBefore:    now,
+		NotAfter:     now.AddDate(100, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, pub, priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate cluster TLS certificate: %v", err)
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate cluster TLS key: %v", err)
+	}
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	return cert, key, nil
+}
@@ -0,0 +1,194 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type assembleSuite struct {
+	testutil.BaseTest
+
+	st     *state.State
+	runner *state.TaskRunner
+	mgr    *clusterstate.ClusterManager
+}
+
+var _ = check.Suite(&assembleSuite{})
+
+func (s *assembleSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	st, stack := newStateWithStoreStack(c)
+	s.st = st
+
+	serial, key := makeSerialAssertionWithKey(c, stack, "serial-1")
+	st.Lock()
+	addSerialToState(c, st, serial)
+	st.Unlock()
+
+	s.AddCleanup(clusterstate.MockDevicestateSignSerialProof(func(st *state.State, data []byte) ([]byte, error) {
+		return asserts.RawSignWithKey(data, key)
+	}))
+	s.AddCleanup(clusterstate.MockAssemblePeriod(10 * time.Millisecond))
+
+	s.runner = state.NewTaskRunner(st)
+	s.mgr = clusterstate.Manager(st, s.runner)
+}
+
+func (s *assembleSuite) TearDownTest(c *check.C) {
+	s.runner.Stop()
+	s.mgr.Stop()
+	s.BaseTest.TearDownTest(c)
+}
+
+func (s *assembleSuite) run(c *check.C, chg *state.Change) {
+	for i := 0; i < 50 && !chg.Status().Ready(); i++ {
+		s.st.Unlock()
+		s.runner.Ensure()
+		s.runner.Wait()
+		s.st.Lock()
+	}
+	c.Assert(chg.Status().Ready(), check.Equals, true)
+}
+
+func (s *assembleSuite) address(c *check.C) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func (s *assembleSuite) TestAssemble(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	addr := s.address(c)
+	ts, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      addr,
+		ExpectedSize: 1,
+		Peers:        []string{"127.0.0.1:1"},
+	})
+	c.Assert(err, check.IsNil)
+	chg := s.st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	s.run(c, chg)
+	c.Assert(chg.Err(), check.IsNil)
+
+	// the credentials the device assembled with are recorded, so that its
+	// peers can recognize it
+	cert, key, err := clusterstate.Credentials(s.st)
+	c.Assert(err, check.IsNil)
+	_, err = tls.X509KeyPair(cert, key)
+	c.Check(err, check.IsNil)
+
+	// the secret isn't kept
+	var opts clusterstate.AssembleOptions
+	c.Assert(ts.Tasks()[0].Get("assemble-options", &opts), check.IsNil)
+	c.Check(opts, check.DeepEquals, clusterstate.AssembleOptions{
+		Address:      addr,
+		ExpectedSize: 1,
+		Peers:        []string{"127.0.0.1:1"},
+	})
+
+	// members of the cluster re-assemble with the same credentials
+	ts, err = clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:       "other-secret",
+		Address:      addr,
+		ExpectedSize: 1,
+		Peers:        []string{"127.0.0.1:1"},
+	})
+	c.Assert(err, check.IsNil)
+	chg = s.st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	s.run(c, chg)
+	c.Assert(chg.Err(), check.IsNil)
+
+	reCert, reKey, err := clusterstate.Credentials(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(reCert, check.DeepEquals, cert)
+	c.Check(reKey, check.DeepEquals, key)
+}
+
+func (s *assembleSuite) TestAssembleListenError(c *check.C) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	ts, err := clusterstate.Assemble(s.st, clusterstate.AssembleOptions{
+		Secret:       "secret",
+		Address:      ln.Addr().String(),
+		ExpectedSize: 1,
+	})
+	c.Assert(err, check.IsNil)
+	chg := s.st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	s.run(c, chg)
+	c.Check(chg.Err(), check.ErrorMatches, `(?s).*cannot listen for assembly messages: .*`)
+
+	_, _, err = clusterstate.Credentials(s.st)
+	c.Check(err, check.Equals, clusterstate.ErrNoPeers)
+}
+
+func (s *assembleSuite) TestAssembleInvalid(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	for _, tc := range []struct {
+		opts clusterstate.AssembleOptions
+		err  string
+	}{
+		{clusterstate.AssembleOptions{Address: "127.0.0.1:8001", ExpectedSize: 2}, "cannot assemble cluster without a secret"},
+		{clusterstate.AssembleOptions{Secret: "secret", Address: "127.0.0.1", ExpectedSize: 2}, `invalid address "127.0.0.1": .*`},
+		{clusterstate.AssembleOptions{Secret: "secret", Address: "127.0.0.1:8001"}, "cannot assemble cluster without its expected size"},
+	} {
+		_, err := clusterstate.Assemble(s.st, tc.opts)
+		c.Check(err, check.ErrorMatches, tc.err)
+	}
+
+	opts := clusterstate.AssembleOptions{Secret: "secret", Address: "127.0.0.1:8001", ExpectedSize: 2}
+	ts, err := clusterstate.Assemble(s.st, opts)
+	c.Assert(err, check.IsNil)
+	chg := s.st.NewChange("assemble-cluster", "...")
+	chg.AddAll(ts)
+
+	_, err = clusterstate.Assemble(s.st, opts)
+	c.Check(err, check.ErrorMatches, "cluster assembly already in progress in change "+chg.ID())
+}
@@ -22,6 +22,7 @@ package clusterstate
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/overlord/configstate/config"
@@ -33,13 +34,25 @@ var applyClusterSubclusterChangeKind = swfeats.RegisterChangeKind("apply-cluster
 
 type ClusterManager struct {
 	state *state.State
+
+	// reporter exchanges convergence reports with the peers once the
+	// cluster is assembled.
+	reporter *reporter
+	// reporterRetry is when to try again to start the reporter after it
+	// failed to start, with reporterRetryDelay the current back-off.
+	reporterRetry      time.Time
+	reporterRetryDelay time.Duration
 }
 
 // Manager returns a new ClusterManager.
-func Manager(st *state.State) *ClusterManager {
-	return &ClusterManager{
+func Manager(st *state.State, runner *state.TaskRunner) *ClusterManager {
+	m := &ClusterManager{
 		state: st,
 	}
+
+	runner.AddHandler("assemble-cluster", m.doAssembleCluster, nil)
+
+	return m
 }
 
 // Ensure ensures that the device state matches the expectations defined by the
@@ -51,6 +64,7 @@ func (m *ClusterManager) Ensure() error {
 	}
 
 	if !enabled {
+		m.stopReporting()
 		return nil
 	}
 
@@ -70,11 +84,17 @@ func (m *ClusterManager) Ensure() error {
 		return err
 	}
 
-	if len(tasksets) == 0 {
-		return nil
+	clusterChanges := inProgressClusterChanges(m.state)
+
+	rollouts, rolledOut, err := rollOutClusterState(m.state, cluster, clusterChanges)
+	if err != nil {
+		return err
 	}
 
-	clusterChanges := inProgressClusterChanges(m.state)
+	converged := len(tasksets) == 0 && len(clusterChanges) == 0 && rolledOut
+	if err := updateConvergence(m.state, converged); err != nil {
+		return err
+	}
 
 	for name, tasks := range tasksets {
 		ref := clusterChangeRef{ClusterID: cluster.ClusterID(), Subcluster: name}
@@ -85,12 +105,59 @@ func (m *ClusterManager) Ensure() error {
 			continue
 		}
 
-		chg := m.state.NewChange(applyClusterSubclusterChangeKind, fmt.Sprintf("Apply subcluster %q state", name))
-		chg.Set("cluster-change-ref", ref)
+		rollouts = append(rollouts, pendingChange{
+			kind:    applyClusterSubclusterChangeKind,
+			summary: fmt.Sprintf("Apply subcluster %q state", name),
+			ref:     ref,
+			tasks:   tasks,
+		})
+	}
+
+	for _, pending := range rollouts {
+		chg := m.state.NewChange(pending.kind, pending.summary)
+		chg.Set("cluster-change-ref", pending.ref)
+		chg.Set("cluster-sequence", cluster.Sequence())
+		chg.AddAll(pending.tasks)
+	}
+
+	if err := updatePeers(m.state, cluster); err != nil {
+		return err
+	}
+
+	// exchange reports last so that they include the changes made above
+	return m.exchangeReports()
+}
+
+// Stop implements StateStopper. It stops exchanging convergence reports with
+// the peers.
+func (m *ClusterManager) Stop() {
+	m.stopReporting()
+}
+
+func (m *ClusterManager) stopReporting() {
+	if m.reporter != nil {
+		m.reporter.stop()
+		m.reporter = nil
+	}
+}
+
+// updateConvergence records whether the state of the device matches the
+// current cluster assertion and when it last came to match it.
+func updateConvergence(st *state.State, converged bool) error {
+	var cs clusterState
+	if err := st.Get("cluster", &cs); err != nil {
+		return err
+	}
 
-		chg.AddAll(tasks)
+	if converged == cs.Converged {
+		return nil
 	}
 
+	if converged {
+		cs.LastConverged = timeNow()
+	}
+	cs.Converged = converged
+	st.Set("cluster", cs)
 	return nil
 }
 
@@ -102,7 +169,7 @@ type clusterChangeRef struct {
 func inProgressClusterChanges(st *state.State) map[clusterChangeRef]bool {
 	changes := make(map[clusterChangeRef]bool)
 	for _, chg := range st.Changes() {
-		if chg.Kind() != applyClusterSubclusterChangeKind || chg.Status().Ready() {
+		if !isClusterChange(chg) || chg.Status().Ready() {
 			continue
 		}
 
@@ -117,6 +184,14 @@ func inProgressClusterChanges(st *state.State) map[clusterChangeRef]bool {
 	return changes
 }
 
+func isClusterChange(chg *state.Change) bool {
+	switch chg.Kind() {
+	case applyClusterSubclusterChangeKind, revertClusterSubclusterChangeKind:
+		return true
+	}
+	return false
+}
+
 func clusteringEnabled(st *state.State) (bool, error) {
 	st.Lock()
 	defer st.Unlock()
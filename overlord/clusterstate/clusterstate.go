@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/overlord/assertstate"
@@ -53,6 +54,12 @@ type clusterState struct {
 	// assertion. Maybe we should consider some sort of sequence container, like
 	// we use in snapstate?
 	Current clusterAssertionState `json:"current"`
+	// Converged is true if the state of the device matched the current
+	// cluster assertion during the last ensure pass.
+	Converged bool `json:"converged,omitempty"`
+	// LastConverged is the time at which the state of the device last came to
+	// match a cluster assertion.
+	LastConverged time.Time `json:"last-converged,omitempty"`
 }
 
 // clusterAssertionState contains the information needed to find a specific
@@ -154,13 +161,14 @@ func UpdateCluster(st *state.State, bundle io.Reader) error {
 		return fmt.Errorf("cannot add cluster assertion bundle: %w", err)
 	}
 
-	st.Set("cluster", clusterState{
-		Current: clusterAssertionState{
-			ClusterID:   cluster.ClusterID(),
-			Sequence:    cluster.Sequence(),
-			AuthorityID: cluster.AuthorityID(),
-		},
-	})
+	cs.Current = clusterAssertionState{
+		ClusterID:   cluster.ClusterID(),
+		Sequence:    cluster.Sequence(),
+		AuthorityID: cluster.AuthorityID(),
+	}
+	// the device needs to converge to the new assertion
+	cs.Converged = false
+	st.Set("cluster", cs)
 
 	// trigger an ensure pass so that the new assertion is picked up and applied
 	st.EnsureBefore(0)
@@ -233,20 +241,17 @@ func decodeClusterBundle(bundle io.Reader) (*asserts.Batch, *asserts.Cluster, er
 // applyClusterState creates the tasks needed to apply the state described by
 // the cluster assertion on this device.
 func applyClusterState(st *state.State, cluster *asserts.Cluster) (map[string]*state.TaskSet, error) {
-	serial, err := devicestate.Serial(st)
+	deviceID, err := localDeviceID(st, cluster)
 	if err != nil {
 		return nil, err
 	}
 
-	deviceID, ok := clusterDeviceIDBySerial(cluster, serial.Serial())
-	if !ok {
-		return nil, fmt.Errorf("device with serial %q not found in cluster assertion", serial.Serial())
-	}
-
 	// mapping of subcluster name to tasks to match desired subcluster state
 	tasksets := make(map[string]*state.TaskSet)
 	for _, subcluster := range cluster.Subclusters() {
-		if !deviceInSubcluster(subcluster, deviceID) {
+		// subclusters that are rolled out in batches are handled by
+		// rollOutClusterState
+		if subcluster.Rollout != nil || !deviceInSubcluster(subcluster, deviceID) {
 			continue
 		}
 
@@ -265,6 +270,20 @@ func applyClusterState(st *state.State, cluster *asserts.Cluster) (map[string]*s
 	return tasksets, nil
 }
 
+// localDeviceID returns the ID of this device in the cluster assertion.
+func localDeviceID(st *state.State, cluster *asserts.Cluster) (int, error) {
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return 0, err
+	}
+
+	deviceID, ok := clusterDeviceIDBySerial(cluster, serial.Serial())
+	if !ok {
+		return 0, fmt.Errorf("device with serial %q not found in cluster assertion", serial.Serial())
+	}
+	return deviceID, nil
+}
+
 func applySubcluster(st *state.State, subcluster asserts.Subcluster) (*state.TaskSet, error) {
 	installs, removals, updates, err := snapsForSubcluster(st, subcluster)
 	if err != nil {
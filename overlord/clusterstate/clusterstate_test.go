@@ -378,7 +378,7 @@ func (s *managerSuite) TestEnsureIdempotent(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -406,7 +406,7 @@ func (s *managerSuite) TestEnsureClusteringDisabled(c *check.C) {
 	st.Unlock()
 	defer st.Lock()
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	err = mgr.Ensure()
 	c.Assert(err, check.IsNil)
@@ -457,7 +457,7 @@ func (s *managerSuite) TestApplyClusterStateNoActions(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -524,7 +524,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceNotInAnySubcluster(c *check.C)
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -651,7 +651,7 @@ func (s *managerSuite) TestApplyClusterStateInstallRemoveAndUpdate(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -761,7 +761,7 @@ func (s *managerSuite) TestApplyClusterStateMultipleSubclusters(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -816,7 +816,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceMissing(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -831,7 +831,7 @@ func (s *managerSuite) TestApplyClusterStateDeviceMissing(c *check.C) {
 func (s *managerSuite) TestApplyClusterStateNoClusterData(c *check.C) {
 	st, _ := newStateWithStoreStack(c)
 
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	c.Assert(mgr.Ensure(), check.IsNil)
 
@@ -927,7 +927,7 @@ func (s *managerSuite) TestApplyClusterStateSkipsExistingChange(c *check.C) {
 
 	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
 	c.Assert(err, check.IsNil)
-	mgr := clusterstate.Manager(st)
+	mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
 
 	st.Unlock()
 	defer st.Lock()
@@ -989,6 +989,10 @@ func makeClusterBundleWithSigning(
 		"timestamp":   time.Now().Format(time.RFC3339),
 	}
 
+	fmtnum, err := asserts.SuggestFormat(asserts.ClusterType, headers, nil)
+	c.Assert(err, check.IsNil)
+	headers["format"] = strconv.Itoa(fmtnum)
+
 	clusterAssertion, err := sa.Signing(accountID).Sign(asserts.ClusterType, headers, nil, "")
 	c.Assert(err, check.IsNil)
 	cluster := clusterAssertion.(*asserts.Cluster)
@@ -1004,6 +1008,13 @@ func makeClusterBundleWithSigning(
 }
 
 func makeSerialAssertion(c *check.C, stack *assertstest.StoreStack, serial string) *asserts.Serial {
+	a, _ := makeSerialAssertionWithKey(c, stack, serial)
+	return a
+}
+
+// makeSerialAssertionWithKey returns a serial assertion along with its device
+// key.
+func makeSerialAssertionWithKey(c *check.C, stack *assertstest.StoreStack, serial string) (*asserts.Serial, asserts.PrivateKey) {
 	deviceKey, _ := assertstest.GenerateKey(752)
 	encodedKey, err := asserts.EncodePublicKey(deviceKey.PublicKey())
 	c.Assert(err, check.IsNil)
@@ -1021,7 +1032,7 @@ func makeSerialAssertion(c *check.C, stack *assertstest.StoreStack, serial strin
 	a, err := stack.Sign(asserts.SerialType, headers, nil, "")
 	c.Assert(err, check.IsNil)
 
-	return a.(*asserts.Serial)
+	return a.(*asserts.Serial), deviceKey
 }
 
 func addSerialToState(c *check.C, st *state.State, serial *asserts.Serial) {
@@ -1064,6 +1075,10 @@ func makeClusterBundle(c *check.C, stack *assertstest.StoreStack, devices []map[
 		"timestamp":   time.Now().Format(time.RFC3339),
 	}
 
+	fmtnum, err := asserts.SuggestFormat(asserts.ClusterType, headers, nil)
+	c.Assert(err, check.IsNil)
+	headers["format"] = strconv.Itoa(fmtnum)
+
 	a, err := brandSigning.Sign(asserts.ClusterType, headers, nil, "")
 	c.Assert(err, check.IsNil)
 	cluster := a.(*asserts.Cluster)
@@ -1154,7 +1169,12 @@ func makeBundleWithUntrustedBrand(c *check.C) []byte {
 
 func newStateWithStoreStack(c *check.C) (*state.State, *assertstest.StoreStack) {
 	signing := assertstest.NewStoreStack("canonical", nil)
+	return newStateTrusting(c, signing), signing
+}
 
+// newStateTrusting returns a new state with clustering enabled, whose
+// assertion database trusts the given store stack.
+func newStateTrusting(c *check.C, signing *assertstest.StoreStack) *state.State {
 	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
 		Backstore: asserts.NewMemoryBackstore(),
 		Trusted:   signing.Trusted,
@@ -1176,5 +1196,5 @@ func newStateWithStoreStack(c *check.C) (*state.State, *assertstest.StoreStack)
 	c.Assert(err, check.IsNil)
 	tr.Commit()
 
-	return st, signing
+	return st
 }
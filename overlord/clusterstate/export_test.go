@@ -21,6 +21,7 @@ package clusterstate
 
 import (
 	"context"
+	"time"
 
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -57,3 +58,30 @@ func MockStoreInstallGoal(f func(...snapstate.StoreSnap) snapstate.InstallGoal)
 	storeInstallGoal = f
 	return restore
 }
+
+var RecordReport = recordReport
+
+func MockTimeNow(f func() time.Time) func() {
+	return testutil.Mock(&timeNow, f)
+}
+
+// ReportAddress returns the address at which the manager receives the
+// convergence reports of its peers.
+func (m *ClusterManager) ReportAddress() string {
+	if m.reporter == nil {
+		return ""
+	}
+	return m.reporter.addr
+}
+
+func MockRevertToRevision(f func(*state.State, string, snap.Revision, snapstate.Flags, string) (*state.TaskSet, error)) func() {
+	return testutil.Mock(&revertToRevision, f)
+}
+
+func MockDevicestateSignSerialProof(f func(*state.State, []byte) ([]byte, error)) func() {
+	return testutil.Mock(&devicestateSignSerialProof, f)
+}
+
+func MockAssemblePeriod(d time.Duration) func() {
+	return testutil.Mock(&assemblePeriod, d)
+}
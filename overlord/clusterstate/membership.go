@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// ErrNoPeers indicates that this device didn't record the peers it trusts
+// once the cluster is assembled.
+var ErrNoPeers = errors.New("clusterstate: no cluster peers")
+
+// Credentials returns the PEM-encoded TLS certificate and key that this device
+// used when the cluster was assembled. When re-assembling an existing cluster,
+// the members take part in the session with these credentials so that their
+// peers can recognize them. Callers must hold the state lock.
+func Credentials(st *state.State) (cert, key []byte, err error) {
+	cp, err := getPeers(st)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cp == nil {
+		return nil, nil, ErrNoPeers
+	}
+
+	key, err = readTLSKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cp.TLSCert, key, nil
+}
+
+// MembershipChange returns the [assemblestate.MembershipChange] that removes
+// the devices with the given IDs from the current cluster. The members of the
+// cluster are known by the certificates that were recorded with
+// RecordAssembly, for this device and the devices it assembled with. Callers must hold the state lock.
+//
+// Together with the result of a re-assembly session, the returned value can be
+// given to [assemblestate.ClusterUpdateHeaders] to build the next cluster
+// assertion, which is then installed with UpdateCluster.
+func MembershipChange(st *state.State, remove []int) (assemblestate.MembershipChange, error) {
+	cluster, err := CurrentCluster(st)
+	if err != nil {
+		return assemblestate.MembershipChange{}, err
+	}
+
+	cp, err := getPeers(st)
+	if err != nil {
+		return assemblestate.MembershipChange{}, err
+	}
+	if cp == nil {
+		return assemblestate.MembershipChange{}, ErrNoPeers
+	}
+
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return assemblestate.MembershipChange{}, err
+	}
+
+	key, err := readTLSKey()
+	if err != nil {
+		return assemblestate.MembershipChange{}, err
+	}
+
+	cert, err := tls.X509KeyPair(cp.TLSCert, key)
+	if err != nil {
+		return assemblestate.MembershipChange{}, err
+	}
+
+	members := make(map[string]assemblestate.Fingerprint, len(cp.Members)+1)
+	for s, encoded := range cp.Members {
+		fp, err := parseFingerprint(encoded)
+		if err != nil {
+			return assemblestate.MembershipChange{}, err
+		}
+		members[s] = fp
+	}
+	members[serial.Serial()] = assemblestate.CalculateFP(cert.Certificate[0])
+
+	// only keep the members that are still part of the cluster, peers that
+	// were removed by an earlier membership change can't vouch for anyone
+	current := make(map[string]bool, len(cluster.Devices()))
+	for _, dev := range cluster.Devices() {
+		current[dev.Serial] = true
+	}
+	for s := range members {
+		if !current[s] {
+			delete(members, s)
+		}
+	}
+
+	return assemblestate.MembershipChange{
+		Members: members,
+		Remove:  remove,
+	}, nil
+}
@@ -0,0 +1,140 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+)
+
+func (s *reportSuite) TestMembershipChange(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	_, err := clusterstate.MembershipChange(s.st, nil)
+	c.Check(err, check.Equals, clusterstate.ErrNoPeers)
+	_, _, err = clusterstate.Credentials(s.st)
+	c.Check(err, check.Equals, clusterstate.ErrNoPeers)
+
+	localCert, localCertPEM, localKeyPEM := generatePEMCert(c)
+	peerCert, _, _ := generatePEMCert(c)
+	goneCert, _, _ := generatePEMCert(c)
+	peerFP := assemblestate.CalculateFP(peerCert.Certificate[0])
+
+	err = clusterstate.RecordAssembly(s.st, "127.0.0.1:0", localCertPEM, localKeyPEM, []assemblestate.Identity{
+		identity(c, s.stack, "serial-1", localCert),
+		identity(c, s.stack, "serial-2", peerCert),
+		// no longer part of the cluster
+		identity(c, s.stack, "serial-3", goneCert),
+	})
+	c.Assert(err, check.IsNil)
+
+	cert, key, err := clusterstate.Credentials(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(cert, check.DeepEquals, localCertPEM)
+	c.Check(key, check.DeepEquals, localKeyPEM)
+
+	change, err := clusterstate.MembershipChange(s.st, []int{2})
+	c.Assert(err, check.IsNil)
+	c.Check(change, check.DeepEquals, assemblestate.MembershipChange{
+		Members: map[string]assemblestate.Fingerprint{
+			"serial-1": assemblestate.CalculateFP(localCert.Certificate[0]),
+			"serial-2": peerFP,
+		},
+		Remove: []int{2},
+	})
+}
+
+func (s *reportSuite) TestMembershipChangeDropsRemovedPeers(c *check.C) {
+	localCert, localCertPEM, localKeyPEM := generatePEMCert(c)
+	peerCert, _, _ := generatePEMCert(c)
+	localFP := assemblestate.CalculateFP(localCert.Certificate[0])
+
+	collector := &reportCollector{reports: make(chan assemblestate.Report, 10)}
+	transport := assemblestate.NewHTTPSTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		transport.Serve(ctx, s.peerLn, peerCert, collector)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	err := clusterstate.RecordAssembly(s.st, "127.0.0.1:0", localCertPEM, localKeyPEM, []assemblestate.Identity{
+		identity(c, s.stack, "serial-1", localCert),
+		identity(c, s.stack, "serial-2", peerCert),
+	})
+	c.Assert(err, check.IsNil)
+	s.ensure(c)
+
+	addr := s.mgr.ReportAddress()
+	c.Assert(addr, check.Not(check.Equals), "")
+
+	client := transport.NewClient(peerCert)
+	peerReport := assemblestate.Report{Serial: "serial-2", ClusterID: "cluster-id", Sequence: 1, Time: s.now}
+	s.st.Unlock()
+	err = client.Trusted(context.Background(), addr, localFP, "report", peerReport)
+	s.st.Lock()
+	c.Assert(err, check.IsNil)
+
+	// serial-2 is removed from the cluster
+	change, err := clusterstate.MembershipChange(s.st, []int{2})
+	c.Assert(err, check.IsNil)
+	c.Check(change.Members, check.HasLen, 2)
+
+	bundle, _ := makeClusterBundleWithSigning(c, s.sa, "cluster-brand", "cluster-id", 2, []map[string]any{
+		{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.10"},
+		},
+	}, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1"},
+		"snaps":   []any{},
+	}})
+	c.Assert(clusterstate.UpdateCluster(s.st, bytes.NewReader(bundle)), check.IsNil)
+	s.ensure(c)
+
+	// and is no longer trusted
+	s.st.Unlock()
+	err = client.Trusted(context.Background(), addr, localFP, "report", peerReport)
+	s.st.Lock()
+	c.Check(err, check.ErrorMatches, ".* status code 403")
+
+	change, err = clusterstate.MembershipChange(s.st, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(change.Members, check.DeepEquals, map[string]assemblestate.Fingerprint{
+		"serial-1": localFP,
+	})
+}
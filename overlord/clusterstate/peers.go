@@ -0,0 +1,519 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var (
+	// reportInterval is the time between two convergence reports sent to
+	// the peers.
+	reportInterval = 5 * time.Minute
+	// reportTimeout bounds the time spent sending a report to a peer.
+	reportTimeout = 30 * time.Second
+	// reporterRetryDelay is the initial time to wait before trying again to
+	// receive the reports of the peers, it doubles on every failure up to
+	// reportInterval.
+	reporterRetryDelay = 10 * time.Second
+)
+
+// Peer identifies a device that this device trusts once the cluster is
+// assembled.
+type Peer struct {
+	// Serial is the serial of the peer device.
+	Serial string
+	// Address is the address at which the peer receives messages.
+	Address string
+	// FP is the fingerprint of the TLS certificate used by the peer.
+	FP assemblestate.Fingerprint
+}
+
+// clusterPeers contains what this device needs to exchange messages with its
+// peers once the cluster is assembled.
+type clusterPeers struct {
+	// Address is the address at which this device receives messages.
+	Address string `json:"address"`
+	// TLSCert is the PEM-encoded certificate that this device used during
+	// assembly. Peers know this device by the fingerprint of that
+	// certificate. The matching key is kept in a file, see tlsKeyPath.
+	TLSCert []byte `json:"tls-cert"`
+	// Members maps the serials of the devices that took part in the
+	// assembly sessions of the cluster to the fingerprints of their
+	// certificates.
+	Members map[string]string `json:"members"`
+	// Peers are the members which are part of the current cluster
+	// assertion, with the addresses they are listed at.
+	Peers []clusterPeer `json:"peers,omitempty"`
+}
+
+type clusterPeer struct {
+	Serial  string `json:"serial"`
+	Address string `json:"address"`
+	FP      string `json:"fp"`
+}
+
+func tlsKeyPath() string {
+	return filepath.Join(dirs.SnapDeviceDir, "cluster-tls.key")
+}
+
+func readTLSKey() ([]byte, error) {
+	key, err := os.ReadFile(tlsKeyPath())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cluster TLS key: %v", err)
+	}
+	return key, nil
+}
+
+// RecordAssembly records the result of an assembly session that this device
+// took part in: the address at which it receives messages, the TLS
+// credentials it used and the identities of the devices it discovered, as
+// returned by [assemblestate.AssembleState.Run] at the end of the
+// assemble-cluster task, see Assemble. The manager then trusts the
+// devices that are part of the cluster assertion as its peers and exchanges
+// convergence reports with them. The key is kept on disk, next to the other
+// device keys, rather than in the state. Callers must hold the state lock.
+func RecordAssembly(st *state.State, address string, cert, key []byte, ids []assemblestate.Identity) error {
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return fmt.Errorf("invalid TLS credentials: %v", err)
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid address %q: %v", address, err)
+	}
+
+	cp, err := getPeers(st)
+	if err != nil {
+		return err
+	}
+
+	// members of an existing cluster take part in re-assembly sessions with
+	// the same certificate, so the devices that didn't take part in this one
+	// are still known by their earlier certificate
+	if cp == nil || !bytes.Equal(cp.TLSCert, cert) {
+		cp = &clusterPeers{}
+	}
+	if cp.Members == nil {
+		cp.Members = make(map[string]string, len(ids))
+	}
+	cp.Address = address
+	cp.TLSCert = cert
+
+	for _, id := range ids {
+		serial, err := identitySerial(id)
+		if err != nil {
+			return err
+		}
+		cp.Members[serial] = base64.StdEncoding.EncodeToString(id.FP[:])
+	}
+
+	path := tlsKeyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot save cluster TLS key: %v", err)
+	}
+	if err := osutil.AtomicWriteFile(path, key, 0600, 0); err != nil {
+		return fmt.Errorf("cannot save cluster TLS key: %v", err)
+	}
+
+	st.Set("cluster-peers", cp)
+	st.EnsureBefore(0)
+	return nil
+}
+
+// identitySerial returns the serial of the device with the given identity.
+func identitySerial(id assemblestate.Identity) (string, error) {
+	dec := asserts.NewDecoder(strings.NewReader(id.SerialBundle))
+	for {
+		as, err := dec.Decode()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", fmt.Errorf("cannot decode serial bundle of device %q: %v", id.RDT, err)
+		}
+
+		if serial, ok := as.(*asserts.Serial); ok {
+			return serial.Serial(), nil
+		}
+	}
+	return "", fmt.Errorf("serial assertion not found in bundle of device %q", id.RDT)
+}
+
+// updatePeers sets the peers of this device to the members of the cluster
+// that are part of the current cluster assertion, at the addresses they are
+// listed at. Devices removed from the cluster are no longer trusted and the
+// peers follow their new addresses as the cluster assertion is updated.
+// Callers must hold the state lock.
+func updatePeers(st *state.State, cluster *asserts.Cluster) error {
+	cp, err := getPeers(st)
+	if err != nil || cp == nil {
+		return err
+	}
+
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return err
+	}
+
+	var peers []Peer
+	for _, dev := range cluster.Devices() {
+		if dev.Serial == serial.Serial() {
+			continue
+		}
+
+		encoded, ok := cp.Members[dev.Serial]
+		if !ok {
+			// the device didn't take part in any assembly session with
+			// this device, so there's no way to authenticate it
+			continue
+		}
+
+		fp, err := parseFingerprint(encoded)
+		if err != nil {
+			return err
+		}
+
+		address := peerAddress(dev.Addresses)
+		if address == "" {
+			logger.Noticef("cannot exchange cluster reports with %q: no address with a port", dev.Serial)
+			continue
+		}
+
+		peers = append(peers, Peer{Serial: dev.Serial, Address: address, FP: fp})
+	}
+
+	return setPeers(st, cp, peers)
+}
+
+// peerAddress returns the first of the given addresses that includes a port.
+func peerAddress(addresses []string) string {
+	for _, addr := range addresses {
+		if _, _, err := net.SplitHostPort(addr); err == nil {
+			return addr
+		}
+	}
+	return ""
+}
+
+// setPeers records the peers that this device trusts and exchanges
+// convergence reports with.
+func setPeers(st *state.State, cp *clusterPeers, peers []Peer) error {
+	updated := make([]clusterPeer, 0, len(peers))
+	for _, p := range peers {
+		updated = append(updated, clusterPeer{
+			Serial:  p.Serial,
+			Address: p.Address,
+			FP:      base64.StdEncoding.EncodeToString(p.FP[:]),
+		})
+	}
+
+	if reflect.DeepEqual(cp.Peers, updated) || (len(cp.Peers) == 0 && len(updated) == 0) {
+		return nil
+	}
+
+	cp.Peers = updated
+	st.Set("cluster-peers", cp)
+	return nil
+}
+
+func getPeers(st *state.State) (*clusterPeers, error) {
+	var cp clusterPeers
+	if err := st.Get("cluster-peers", &cp); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// reporter receives the convergence reports of the peers and periodically
+// sends them the report of this device.
+type reporter struct {
+	config   *clusterPeers
+	addr     string
+	client   assemblestate.Client
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	lastSent time.Time
+	// lastReport is the last report sent to the peers.
+	lastReport assemblestate.Report
+}
+
+func (r *reporter) stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// sameReport returns true if the two reports only differ by the time at which
+// they were made.
+func sameReport(a, b assemblestate.Report) bool {
+	a.Time, b.Time = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+func sameConfig(a, b *clusterPeers) bool {
+	return a.Address == b.Address && bytes.Equal(a.TLSCert, b.TLSCert)
+}
+
+// exchangeReports starts or stops exchanging convergence reports with the
+// peers, depending on whether any are known, and sends this device's report
+// when it's due. Callers must hold the state lock.
+func (m *ClusterManager) exchangeReports() error {
+	cp, err := getPeers(m.state)
+	if err != nil {
+		return err
+	}
+
+	if m.reporter != nil && (cp == nil || !sameConfig(m.reporter.config, cp)) {
+		r := m.reporter
+		m.reporter = nil
+		// the server and the reports being sent need the state lock
+		m.state.Unlock()
+		r.stop()
+		m.state.Lock()
+
+		if cp, err = getPeers(m.state); err != nil {
+			return err
+		}
+	}
+
+	if cp == nil {
+		return nil
+	}
+
+	if m.reporter == nil {
+		now := timeNow()
+		if now.Before(m.reporterRetry) {
+			m.state.EnsureBefore(m.reporterRetry.Sub(now))
+			return nil
+		}
+
+		r, err := m.startReporter(cp)
+		if err != nil {
+			// the address can be in use for a while, try again later
+			// rather than failing every Ensure
+			m.reporterRetryDelay = nextRetryDelay(m.reporterRetryDelay)
+			m.reporterRetry = now.Add(m.reporterRetryDelay)
+			logger.Noticef("cannot exchange cluster reports, retrying in %v: %v", m.reporterRetryDelay, err)
+			m.state.EnsureBefore(m.reporterRetryDelay)
+			return nil
+		}
+		m.reporter = r
+		m.reporterRetry = time.Time{}
+		m.reporterRetryDelay = 0
+	}
+	// the list of peers can change without restarting the server
+	m.reporter.config = cp
+
+	report, err := LocalReport(m.state)
+	if err != nil {
+		return err
+	}
+
+	// reports are sent periodically, and as soon as they change so that the
+	// peers waiting on this device during a rollout don't have to wait
+	now := timeNow()
+	if next := m.reporter.lastSent.Add(reportInterval); now.Before(next) && sameReport(m.reporter.lastReport, report) {
+		m.state.EnsureBefore(next.Sub(now))
+		return nil
+	}
+
+	m.reporter.lastSent = now
+	m.reporter.lastReport = report
+	m.state.EnsureBefore(reportInterval)
+
+	r := m.reporter
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.sendReport(cp.Peers, report)
+	}()
+
+	return nil
+}
+
+func nextRetryDelay(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return reporterRetryDelay
+	}
+	delay *= 2
+	if delay > reportInterval {
+		delay = reportInterval
+	}
+	return delay
+}
+
+func (m *ClusterManager) startReporter(cp *clusterPeers) (*reporter, error) {
+	key, err := readTLSKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.X509KeyPair(cp.TLSCert, key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS credentials: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", cp.Address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for cluster reports: %v", err)
+	}
+
+	transport := assemblestate.NewHTTPSTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &reporter{
+		config: cp,
+		addr:   ln.Addr().String(),
+		client: transport.NewClient(cert),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer ln.Close()
+		if err := transport.Serve(ctx, ln, cert, &reportAuthenticator{st: m.state}); err != nil {
+			logger.Noticef("cannot serve cluster reports: %v", err)
+		}
+	}()
+
+	return r, nil
+}
+
+func (r *reporter) sendReport(peers []clusterPeer, report assemblestate.Report) {
+	for _, p := range peers {
+		// the reporter is being stopped
+		if r.ctx.Err() != nil {
+			return
+		}
+
+		fp, err := parseFingerprint(p.FP)
+		if err != nil {
+			logger.Noticef("cannot send cluster report to %q: %v", p.Serial, err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(r.ctx, reportTimeout)
+		if err := r.client.Trusted(ctx, p.Address, fp, "report", report); err != nil {
+			logger.Debugf("cannot send cluster report to %q: %v", p.Serial, err)
+		}
+		cancel()
+	}
+}
+
+func parseFingerprint(s string) (assemblestate.Fingerprint, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return assemblestate.Fingerprint{}, fmt.Errorf("invalid fingerprint: %v", err)
+	}
+
+	var fp assemblestate.Fingerprint
+	if len(raw) != len(fp) {
+		return assemblestate.Fingerprint{}, fmt.Errorf("invalid fingerprint length: %d", len(raw))
+	}
+	copy(fp[:], raw)
+	return fp, nil
+}
+
+// reportAuthenticator is an [assemblestate.PeerAuthenticator] which only
+// trusts the peers that were recorded when the cluster was assembled.
+type reportAuthenticator struct {
+	st *state.State
+}
+
+func (a *reportAuthenticator) AuthenticateAndCommit(auth assemblestate.Auth, fp assemblestate.Fingerprint) error {
+	return errors.New("cannot authenticate new peers once the cluster is assembled")
+}
+
+func (a *reportAuthenticator) VerifyPeer(fp assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+	a.st.Lock()
+	defer a.st.Unlock()
+
+	cp, err := getPeers(a.st)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp != nil {
+		encoded := base64.StdEncoding.EncodeToString(fp[:])
+		for _, p := range cp.Peers {
+			if p.FP == encoded {
+				return &reportingPeer{st: a.st, serial: p.Serial}, nil
+			}
+		}
+	}
+
+	return nil, errors.New("unknown peer")
+}
+
+var errClusterAssembled = errors.New("cannot accept assembly messages once the cluster is assembled")
+
+// reportingPeer is an [assemblestate.VerifiedPeer] which only accepts the
+// convergence reports of the device it was verified as.
+type reportingPeer struct {
+	st     *state.State
+	serial string
+}
+
+func (p *reportingPeer) CommitDeviceQueries(unknown assemblestate.UnknownDevices) error {
+	return errClusterAssembled
+}
+
+func (p *reportingPeer) CommitDevices(devices assemblestate.Devices) error {
+	return errClusterAssembled
+}
+
+func (p *reportingPeer) CommitRoutes(routes assemblestate.Routes) error {
+	return errClusterAssembled
+}
+
+func (p *reportingPeer) CommitReport(report assemblestate.Report) error {
+	if report.Serial != p.serial {
+		return fmt.Errorf("peer %q cannot report for device %q", p.serial, report.Serial)
+	}
+
+	p.st.Lock()
+	defer p.st.Unlock()
+	return recordReport(p.st, report)
+}
@@ -0,0 +1,261 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var timeNow = time.Now
+
+// LocalReport returns the convergence report of this device for the current
+// cluster assertion. Callers must hold the state lock.
+func LocalReport(st *state.State) (assemblestate.Report, error) {
+	cluster, err := CurrentCluster(st)
+	if err != nil {
+		return assemblestate.Report{}, err
+	}
+
+	serial, err := devicestate.Serial(st)
+	if err != nil {
+		return assemblestate.Report{}, err
+	}
+
+	deviceID, ok := clusterDeviceIDBySerial(cluster, serial.Serial())
+	if !ok {
+		return assemblestate.Report{}, fmt.Errorf("device with serial %q not found in cluster assertion", serial.Serial())
+	}
+
+	var cs clusterState
+	if err := st.Get("cluster", &cs); err != nil {
+		return assemblestate.Report{}, err
+	}
+
+	rollout, err := rolloutStatuses(st, cluster)
+	if err != nil {
+		return assemblestate.Report{}, err
+	}
+
+	report := assemblestate.Report{
+		Serial:        serial.Serial(),
+		ClusterID:     cluster.ClusterID(),
+		Sequence:      cluster.Sequence(),
+		Converged:     cs.Converged,
+		LastConverged: cs.LastConverged,
+		Rollout:       rollout,
+		Time:          timeNow(),
+	}
+
+	seen := make(map[string]bool)
+	for _, subcluster := range cluster.Subclusters() {
+		if !deviceInSubcluster(subcluster, deviceID) {
+			continue
+		}
+
+		for _, sn := range subcluster.Snaps {
+			if seen[sn.Instance] {
+				continue
+			}
+			seen[sn.Instance] = true
+
+			var snapst snapstate.SnapState
+			if err := snapstate.Get(st, sn.Instance, &snapst); err != nil {
+				if errors.Is(err, state.ErrNoState) {
+					continue
+				}
+				return assemblestate.Report{}, err
+			}
+
+			if !snapst.IsInstalled() {
+				continue
+			}
+
+			report.Snaps = append(report.Snaps, assemblestate.ReportedSnap{
+				Instance: sn.Instance,
+				Revision: snapst.Current,
+				Channel:  snapst.TrackingChannel,
+			})
+		}
+	}
+	sort.Slice(report.Snaps, func(i, j int) bool {
+		return report.Snaps[i].Instance < report.Snaps[j].Instance
+	})
+
+	for _, chg := range st.Changes() {
+		if !isClusterChange(chg) || chg.Status() != state.ErrorStatus {
+			continue
+		}
+
+		var ref clusterChangeRef
+		if err := chg.Get("cluster-change-ref", &ref); err != nil || ref.ClusterID != cluster.ClusterID() {
+			continue
+		}
+
+		failed := assemblestate.ReportedChange{
+			ID:      chg.ID(),
+			Kind:    chg.Kind(),
+			Summary: chg.Summary(),
+			Time:    chg.ReadyTime(),
+		}
+		if err := chg.Err(); err != nil {
+			failed.Error = err.Error()
+		}
+		report.FailedChanges = append(report.FailedChanges, failed)
+	}
+	sort.Slice(report.FailedChanges, func(i, j int) bool {
+		return report.FailedChanges[i].Time.Before(report.FailedChanges[j].Time)
+	})
+
+	return report, nil
+}
+
+// recordReport saves a convergence report received from a peer, replacing
+// any previous report from the same device. Callers must hold the state lock.
+func recordReport(st *state.State, report assemblestate.Report) error {
+	cluster, err := CurrentCluster(st)
+	if err != nil {
+		return err
+	}
+
+	if report.ClusterID != cluster.ClusterID() {
+		return fmt.Errorf("cannot record report for cluster %q: current cluster is %q", report.ClusterID, cluster.ClusterID())
+	}
+
+	if _, ok := clusterDeviceIDBySerial(cluster, report.Serial); !ok {
+		return fmt.Errorf("cannot record report from device with serial %q: device not found in cluster assertion", report.Serial)
+	}
+
+	reports, err := peerReports(st)
+	if err != nil {
+		return err
+	}
+
+	// a report can be delayed, don't replace a newer one
+	if prev, ok := reports[report.Serial]; ok && prev.Time.After(report.Time) {
+		return nil
+	}
+
+	reports[report.Serial] = report
+	st.Set("cluster-reports", reports)
+
+	// devices waiting for the previous batches of a rollout might be able to
+	// proceed
+	st.EnsureBefore(0)
+	return nil
+}
+
+func peerReports(st *state.State) (map[string]assemblestate.Report, error) {
+	var reports map[string]assemblestate.Report
+	if err := st.Get("cluster-reports", &reports); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return nil, err
+		}
+		reports = make(map[string]assemblestate.Report)
+	}
+	return reports, nil
+}
+
+// DeviceStatus holds what is known about a device of the cluster.
+type DeviceStatus struct {
+	ID          int      `json:"id"`
+	Brand       string   `json:"brand-id"`
+	Model       string   `json:"model"`
+	Serial      string   `json:"serial"`
+	Addresses   []string `json:"addresses,omitempty"`
+	Subclusters []string `json:"subclusters,omitempty"`
+	// Local is true for the device that produced the status.
+	Local bool `json:"local,omitempty"`
+	// Report is the latest convergence report of the device, if any.
+	Report *assemblestate.Report `json:"report,omitempty"`
+}
+
+// Status holds the convergence status of the whole cluster, as seen from this
+// device.
+type Status struct {
+	ClusterID string         `json:"cluster-id"`
+	Sequence  int            `json:"sequence"`
+	Devices   []DeviceStatus `json:"devices"`
+}
+
+// ClusterStatus aggregates the convergence reports of all the devices in the
+// current cluster assertion, including this device's own. Callers must hold
+// the state lock.
+func ClusterStatus(st *state.State) (*Status, error) {
+	cluster, err := CurrentCluster(st)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := peerReports(st)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := LocalReport(st)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		ClusterID: cluster.ClusterID(),
+		Sequence:  cluster.Sequence(),
+	}
+
+	for _, dev := range cluster.Devices() {
+		ds := DeviceStatus{
+			ID:          dev.ID,
+			Brand:       dev.BrandID,
+			Model:       dev.Model,
+			Serial:      dev.Serial,
+			Addresses:   dev.Addresses,
+			Subclusters: subclustersOf(cluster, dev.ID),
+		}
+
+		if dev.Serial == local.Serial {
+			ds.Local = true
+			ds.Report = &local
+		} else if report, ok := reports[dev.Serial]; ok && report.ClusterID == cluster.ClusterID() {
+			ds.Report = &report
+		}
+
+		status.Devices = append(status.Devices, ds)
+	}
+
+	return status, nil
+}
+
+func subclustersOf(cluster *asserts.Cluster, deviceID int) []string {
+	var names []string
+	for _, subcluster := range cluster.Subclusters() {
+		if deviceInSubcluster(subcluster, deviceID) {
+			names = append(names, subcluster.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,536 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/sequence"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type reportSuite struct {
+	testutil.BaseTest
+
+	st    *state.State
+	stack *assertstest.StoreStack
+	// sa signs the cluster assertions
+	sa  *assertstest.SigningAccounts
+	mgr *clusterstate.ClusterManager
+	now time.Time
+	// peerLn is where the peer device, serial-2, receives messages
+	peerLn net.Listener
+}
+
+var _ = check.Suite(&reportSuite{})
+
+func (s *reportSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	st, stack := newStateWithStoreStack(c)
+	s.st = st
+	s.stack = stack
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	s.peerLn = ln
+	s.AddCleanup(func() { ln.Close() })
+
+	s.now = time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	restore := clusterstate.MockTimeNow(func() time.Time { return s.now })
+	s.AddCleanup(restore)
+
+	s.sa = registerAccount(stack, "cluster-brand")
+	bundle, _ := makeClusterBundleWithSigning(c, s.sa, "cluster-brand", "cluster-id", 1, []map[string]any{
+		{
+			"id":        "1",
+			"device":    "serial-1.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.10"},
+		},
+		{
+			"id":        "2",
+			"device":    "serial-2.ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.11", s.peerLn.Addr().String()},
+		},
+	}, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1", "2"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "clustered",
+				"instance": "some-snap",
+				"channel":  "latest/stable",
+			},
+		},
+	}})
+
+	st.Lock()
+	defer st.Unlock()
+
+	addSerialToState(c, st, makeSerialAssertion(c, stack, "serial-1"))
+
+	snapstate.Set(st, "some-snap", &snapstate.SnapState{
+		Current:         snap.R(3),
+		TrackingChannel: "latest/stable",
+		Sequence: sequence.SnapSequence{
+			Revisions: []*sequence.RevisionSideState{
+				sequence.NewRevisionSideState(&snap.SideInfo{Revision: snap.R(3)}, nil),
+			},
+		},
+	})
+
+	err = clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
+	c.Assert(err, check.IsNil)
+
+	s.mgr = clusterstate.Manager(st, state.NewTaskRunner(st))
+}
+
+func (s *reportSuite) TearDownTest(c *check.C) {
+	s.mgr.Stop()
+	s.BaseTest.TearDownTest(c)
+}
+
+func (s *reportSuite) ensure(c *check.C) {
+	s.st.Unlock()
+	defer s.st.Lock()
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+}
+
+func (s *reportSuite) addFailedChange(c *check.C, clusterID string, readyTime time.Time) *state.Change {
+	restore := state.MockTime(readyTime)
+	defer restore()
+
+	chg := s.st.NewChange("apply-cluster-subcluster", `Apply subcluster "default" state`)
+	chg.Set("cluster-change-ref", map[string]string{"cluster-id": clusterID, "subcluster": "default"})
+	t := s.st.NewTask("install-snap", "Install some-snap")
+	chg.AddTask(t)
+	t.Errorf("cannot install")
+	t.SetStatus(state.ErrorStatus)
+	c.Assert(chg.Status(), check.Equals, state.ErrorStatus)
+	return chg
+}
+
+func (s *reportSuite) TestLocalReport(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+
+	failedAt := s.now.Add(-time.Hour)
+	chg := s.addFailedChange(c, "cluster-id", failedAt)
+	// changes for other clusters are ignored
+	s.addFailedChange(c, "other-cluster", failedAt)
+
+	report, err := clusterstate.LocalReport(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(report, check.DeepEquals, assemblestate.Report{
+		Serial:    "serial-1",
+		ClusterID: "cluster-id",
+		Sequence:  1,
+		Snaps: []assemblestate.ReportedSnap{
+			{Instance: "some-snap", Revision: snap.R(3), Channel: "latest/stable"},
+		},
+		FailedChanges: []assemblestate.ReportedChange{{
+			ID:      chg.ID(),
+			Kind:    "apply-cluster-subcluster",
+			Summary: `Apply subcluster "default" state`,
+			Error:   "cannot perform the following tasks:\n- Install some-snap (cannot install)",
+			Time:    failedAt,
+		}},
+		Converged:     true,
+		LastConverged: s.now,
+		Time:          s.now,
+	})
+}
+
+func (s *reportSuite) TestEnsureTracksConvergence(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	convergedAt := s.now
+	s.ensure(c)
+
+	// staying converged doesn't change the time at which the device converged
+	s.now = s.now.Add(time.Hour)
+	s.ensure(c)
+
+	report, err := clusterstate.LocalReport(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(report.Converged, check.Equals, true)
+	c.Check(report.LastConverged.Equal(convergedAt), check.Equals, true)
+
+	// an ongoing change means the device isn't converged
+	chg := s.st.NewChange("apply-cluster-subcluster", "...")
+	chg.Set("cluster-change-ref", map[string]string{"cluster-id": "cluster-id", "subcluster": "default"})
+	chg.AddTask(s.st.NewTask("install-snap", "..."))
+	s.ensure(c)
+
+	report, err = clusterstate.LocalReport(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(report.Converged, check.Equals, false)
+	c.Check(report.LastConverged.Equal(convergedAt), check.Equals, true)
+
+	chg.Tasks()[0].SetStatus(state.DoneStatus)
+	s.now = s.now.Add(time.Hour)
+	s.ensure(c)
+
+	report, err = clusterstate.LocalReport(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(report.Converged, check.Equals, true)
+	c.Check(report.LastConverged.Equal(s.now), check.Equals, true)
+}
+
+func (s *reportSuite) TestRecordReportAndStatus(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+
+	peerReport := assemblestate.Report{
+		Serial:    "serial-2",
+		ClusterID: "cluster-id",
+		Sequence:  1,
+		Snaps: []assemblestate.ReportedSnap{
+			{Instance: "some-snap", Revision: snap.R(2), Channel: "latest/stable"},
+		},
+		Time: s.now.Add(-time.Minute),
+	}
+	c.Assert(clusterstate.RecordReport(s.st, peerReport), check.IsNil)
+
+	// older reports don't replace newer ones
+	older := peerReport
+	older.Time = s.now.Add(-time.Hour)
+	older.Sequence = 0
+	c.Assert(clusterstate.RecordReport(s.st, older), check.IsNil)
+
+	err := clusterstate.RecordReport(s.st, assemblestate.Report{Serial: "serial-3", ClusterID: "cluster-id"})
+	c.Check(err, check.ErrorMatches, `cannot record report from device with serial "serial-3": device not found in cluster assertion`)
+
+	err = clusterstate.RecordReport(s.st, assemblestate.Report{Serial: "serial-2", ClusterID: "other"})
+	c.Check(err, check.ErrorMatches, `cannot record report for cluster "other": current cluster is "cluster-id"`)
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(status.ClusterID, check.Equals, "cluster-id")
+	c.Check(status.Sequence, check.Equals, 1)
+	c.Assert(status.Devices, check.HasLen, 2)
+
+	local := status.Devices[0]
+	c.Check(local.ID, check.Equals, 1)
+	c.Check(local.Serial, check.Equals, "serial-1")
+	c.Check(local.Brand, check.Equals, "canonical")
+	c.Check(local.Model, check.Equals, "ubuntu-core-24-amd64")
+	c.Check(local.Addresses, check.DeepEquals, []string{"192.168.0.10"})
+	c.Check(local.Subclusters, check.DeepEquals, []string{"default"})
+	c.Check(local.Local, check.Equals, true)
+	c.Assert(local.Report, check.NotNil)
+	c.Check(local.Report.Snaps, check.DeepEquals, []assemblestate.ReportedSnap{
+		{Instance: "some-snap", Revision: snap.R(3), Channel: "latest/stable"},
+	})
+
+	peer := status.Devices[1]
+	c.Check(peer.Serial, check.Equals, "serial-2")
+	c.Check(peer.Local, check.Equals, false)
+	c.Assert(peer.Report, check.NotNil)
+	c.Check(peer.Report.Sequence, check.Equals, 1)
+	c.Check(peer.Report.Snaps, check.DeepEquals, peerReport.Snaps)
+}
+
+func generatePEMCert(c *check.C) (tls.Certificate, []byte, []byte) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, check.IsNil)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, pub, priv)
+	c.Assert(err, check.IsNil)
+
+	rawKey, err := x509.MarshalPKCS8PrivateKey(priv)
+	c.Assert(err, check.IsNil)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawKey})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	c.Assert(err, check.IsNil)
+	return cert, certPEM, keyPEM
+}
+
+// identity returns the identity of the device with the given serial, as
+// returned by an assembly session.
+func identity(c *check.C, stack *assertstest.StoreStack, serial string, cert tls.Certificate) assemblestate.Identity {
+	var buf bytes.Buffer
+	c.Assert(asserts.NewEncoder(&buf).Encode(makeSerialAssertion(c, stack, serial)), check.IsNil)
+	return assemblestate.Identity{
+		RDT:          assemblestate.DeviceToken("rdt-" + serial),
+		FP:           assemblestate.CalculateFP(cert.Certificate[0]),
+		SerialBundle: buf.String(),
+	}
+}
+
+type reportCollector struct {
+	reports chan assemblestate.Report
+}
+
+func (r *reportCollector) AuthenticateAndCommit(assemblestate.Auth, assemblestate.Fingerprint) error {
+	return errors.New("unexpected")
+}
+
+func (r *reportCollector) VerifyPeer(assemblestate.Fingerprint) (assemblestate.VerifiedPeer, error) {
+	return r, nil
+}
+
+func (r *reportCollector) CommitDeviceQueries(assemblestate.UnknownDevices) error {
+	return errors.New("unexpected")
+}
+
+func (r *reportCollector) CommitDevices(assemblestate.Devices) error { return errors.New("unexpected") }
+
+func (r *reportCollector) CommitRoutes(assemblestate.Routes) error { return errors.New("unexpected") }
+
+func (r *reportCollector) CommitReport(report assemblestate.Report) error {
+	r.reports <- report
+	return nil
+}
+
+func (s *reportSuite) TestExchangeReports(c *check.C) {
+	localCert, localCertPEM, localKeyPEM := generatePEMCert(c)
+	peerCert, _, _ := generatePEMCert(c)
+	localFP := assemblestate.CalculateFP(localCert.Certificate[0])
+
+	// the peer receives reports over the assemble transport
+	collector := &reportCollector{reports: make(chan assemblestate.Report, 1)}
+	transport := assemblestate.NewHTTPSTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		transport.Serve(ctx, s.peerLn, peerCert, collector)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// the peer is known from the assembly session and found at the address
+	// it's listed at in the cluster assertion
+	err := clusterstate.RecordAssembly(s.st, "127.0.0.1:0", localCertPEM, localKeyPEM, []assemblestate.Identity{
+		identity(c, s.stack, "serial-1", localCert),
+		identity(c, s.stack, "serial-2", peerCert),
+	})
+	c.Assert(err, check.IsNil)
+
+	// the key is kept out of the state
+	keyPath := filepath.Join(dirs.SnapDeviceDir, "cluster-tls.key")
+	c.Check(keyPath, testutil.FileEquals, localKeyPEM)
+	fi, err := os.Stat(keyPath)
+	c.Assert(err, check.IsNil)
+	c.Check(fi.Mode().Perm(), check.Equals, os.FileMode(0600))
+	data, err := json.Marshal(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(bytes.Contains(data, []byte("PRIVATE KEY")), check.Equals, false)
+	c.Check(bytes.Contains(data, []byte(base64.StdEncoding.EncodeToString(localKeyPEM))), check.Equals, false)
+
+	s.ensure(c)
+
+	select {
+	case report := <-collector.reports:
+		c.Check(report.Serial, check.Equals, "serial-1")
+		c.Check(report.ClusterID, check.Equals, "cluster-id")
+	case <-time.After(10 * time.Second):
+		c.Fatal("timed out waiting for the report to be sent")
+	}
+
+	// reports are only sent periodically
+	s.ensure(c)
+	select {
+	case <-collector.reports:
+		c.Fatal("unexpected report")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// unless the report changes
+	chg := s.st.NewChange("apply-cluster-subcluster", "...")
+	chg.Set("cluster-change-ref", map[string]string{"cluster-id": "cluster-id", "subcluster": "default"})
+	chg.AddTask(s.st.NewTask("install-snap", "..."))
+	s.ensure(c)
+
+	select {
+	case report := <-collector.reports:
+		c.Check(report.Converged, check.Equals, false)
+	case <-time.After(10 * time.Second):
+		c.Fatal("timed out waiting for the changed report to be sent")
+	}
+
+	addr := s.mgr.ReportAddress()
+	c.Assert(addr, check.Not(check.Equals), "")
+
+	// the peer sends its report to this device
+	client := transport.NewClient(peerCert)
+	peerReport := assemblestate.Report{Serial: "serial-2", ClusterID: "cluster-id", Sequence: 1, Time: s.now}
+	s.st.Unlock()
+	err = client.Trusted(context.Background(), addr, localFP, "report", peerReport)
+	s.st.Lock()
+	c.Assert(err, check.IsNil)
+
+	status, err := clusterstate.ClusterStatus(s.st)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Devices[1].Report, check.NotNil)
+	c.Check(status.Devices[1].Report.Serial, check.Equals, "serial-2")
+
+	// the peer can't pretend to be another device
+	s.st.Unlock()
+	err = client.Trusted(context.Background(), addr, localFP, "report", assemblestate.Report{Serial: "serial-1", ClusterID: "cluster-id"})
+	c.Check(err, check.ErrorMatches, ".* status code 400")
+
+	// unknown devices are rejected
+	otherCert, _, _ := generatePEMCert(c)
+	err = transport.NewClient(otherCert).Trusted(context.Background(), addr, localFP, "report", peerReport)
+	c.Check(err, check.ErrorMatches, ".* status code 403")
+	s.st.Lock()
+}
+
+func (s *reportSuite) TestExchangeReportsRetriesListen(c *check.C) {
+	localCert, localCertPEM, localKeyPEM := generatePEMCert(c)
+	peerCert, _, _ := generatePEMCert(c)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer busy.Close()
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	err = clusterstate.RecordAssembly(s.st, busy.Addr().String(), localCertPEM, localKeyPEM, []assemblestate.Identity{
+		identity(c, s.stack, "serial-1", localCert),
+		identity(c, s.stack, "serial-2", peerCert),
+	})
+	c.Assert(err, check.IsNil)
+
+	// the address is in use, which doesn't fail the manager
+	s.ensure(c)
+	c.Check(s.mgr.ReportAddress(), check.Equals, "")
+
+	// nothing is tried until the back-off expires
+	c.Assert(busy.Close(), check.IsNil)
+	s.now = s.now.Add(5 * time.Second)
+	s.ensure(c)
+	c.Check(s.mgr.ReportAddress(), check.Equals, "")
+
+	s.now = s.now.Add(10 * time.Second)
+	s.ensure(c)
+	c.Check(s.mgr.ReportAddress(), check.Equals, busy.Addr().String())
+}
+
+func (s *reportSuite) TestExchangeReportsStopDoesNotWaitForPeers(c *check.C) {
+	localCert, localCertPEM, localKeyPEM := generatePEMCert(c)
+	peerCert, _, _ := generatePEMCert(c)
+	ids := []assemblestate.Identity{
+		identity(c, s.stack, "serial-1", localCert),
+		identity(c, s.stack, "serial-2", peerCert),
+	}
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	// nothing answers on the listener of the peer, sending it the report
+	// stalls
+	err := clusterstate.RecordAssembly(s.st, "127.0.0.1:0", localCertPEM, localKeyPEM, ids)
+	c.Assert(err, check.IsNil)
+	s.ensure(c)
+	c.Assert(s.mgr.ReportAddress(), check.Not(check.Equals), "")
+
+	// new credentials restart the reporter, without waiting for the report
+	// to time out
+	otherCert, otherCertPEM, otherKeyPEM := generatePEMCert(c)
+	ids[0] = identity(c, s.stack, "serial-1", otherCert)
+	err = clusterstate.RecordAssembly(s.st, "127.0.0.1:0", otherCertPEM, otherKeyPEM, ids)
+	c.Assert(err, check.IsNil)
+
+	done := make(chan error, 1)
+	s.st.Unlock()
+	go func() { done <- s.mgr.Ensure() }()
+	select {
+	case err := <-done:
+		c.Check(err, check.IsNil)
+	case <-time.After(10 * time.Second):
+		c.Fatal("stopping the reporter waited for the peers")
+	}
+	s.st.Lock()
+	c.Check(s.mgr.ReportAddress(), check.Not(check.Equals), "")
+}
+
+func (s *reportSuite) TestRecordAssemblyInvalid(c *check.C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	_, certPEM, keyPEM := generatePEMCert(c)
+
+	err := clusterstate.RecordAssembly(s.st, "127.0.0.1:0", certPEM, []byte("bad"), nil)
+	c.Check(err, check.ErrorMatches, "invalid TLS credentials: .*")
+
+	err = clusterstate.RecordAssembly(s.st, "127.0.0.1", certPEM, keyPEM, nil)
+	c.Check(err, check.ErrorMatches, `invalid address "127.0.0.1": .*`)
+
+	err = clusterstate.RecordAssembly(s.st, "127.0.0.1:0", certPEM, keyPEM, []assemblestate.Identity{
+		{RDT: "rdt-1", SerialBundle: "garbage"},
+	})
+	c.Check(err, check.ErrorMatches, `cannot decode serial bundle of device "rdt-1": .*`)
+}
@@ -0,0 +1,577 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+	"github.com/snapcore/snapd/snap"
+)
+
+var revertClusterSubclusterChangeKind = swfeats.RegisterChangeKind("revert-cluster-subcluster")
+
+var revertToRevision = snapstate.RevertToRevision
+
+// defaultBatchTimeout is the time each batch of a rollout has to apply the
+// cluster assertion when the rollout doesn't specify it.
+var defaultBatchTimeout = 12 * time.Hour
+
+// RolloutStatus describes how far this device is in applying the current
+// cluster assertion to a subcluster that is rolled out in batches.
+type RolloutStatus string
+
+const (
+	// RolloutWaiting means that the device waits for the devices of the
+	// previous batches to apply the cluster assertion.
+	RolloutWaiting RolloutStatus = "waiting"
+	// RolloutApplying means that the device is applying the cluster
+	// assertion.
+	RolloutApplying RolloutStatus = "applying"
+	// RolloutApplied means that the device applied the cluster assertion but
+	// some of the snaps of the subcluster are not healthy yet.
+	RolloutApplied RolloutStatus = "applied"
+	// RolloutHealthy means that the device applied the cluster assertion and
+	// the snaps of the subcluster are healthy.
+	RolloutHealthy RolloutStatus = "healthy"
+	// RolloutFailed means that the device, or another device of its batch,
+	// failed to apply the cluster assertion or that the snaps of the
+	// subcluster became unhealthy.
+	RolloutFailed RolloutStatus = "failed"
+	// RolloutReverting means that the device is reverting the snaps of the
+	// subcluster after its batch failed.
+	RolloutReverting RolloutStatus = "reverting"
+	// RolloutReverted means that the device reverted the snaps of the
+	// subcluster after its batch failed.
+	RolloutReverted RolloutStatus = "reverted"
+	// RolloutHalted means that the device won't apply the cluster assertion
+	// because a previous batch failed.
+	RolloutHalted RolloutStatus = "halted"
+	// RolloutTimedOut means that the device won't apply the cluster
+	// assertion because the devices of the previous batches didn't report
+	// that they applied it in time.
+	RolloutTimedOut RolloutStatus = "timed-out"
+)
+
+// stopsRollout returns true if a device with this status prevents the devices
+// of the following batches from applying the cluster assertion.
+func (s RolloutStatus) stopsRollout() bool {
+	switch s {
+	case RolloutFailed, RolloutReverting, RolloutReverted, RolloutHalted, RolloutTimedOut:
+		return true
+	}
+	return false
+}
+
+// rolloutState tracks the rollout of a cluster assertion to the subclusters
+// of this device that are rolled out in batches. It's reset whenever the
+// cluster assertion changes.
+type rolloutState struct {
+	ClusterID   string                        `json:"cluster-id"`
+	Sequence    int                           `json:"sequence"`
+	Subclusters map[string]*subclusterRollout `json:"subclusters,omitempty"`
+}
+
+type subclusterRollout struct {
+	Status RolloutStatus `json:"status"`
+	// Started is true once this device started applying the cluster
+	// assertion to the subcluster.
+	Started bool `json:"started,omitempty"`
+	// Failed is true once this device's batch failed. A failed rollout isn't
+	// retried until the cluster assertion changes.
+	Failed bool `json:"failed,omitempty"`
+	// Reverted is true once this device started reverting the snaps of the
+	// subcluster.
+	Reverted bool `json:"reverted,omitempty"`
+	// WaitingSince is when this device started waiting for the devices of
+	// the previous batches.
+	WaitingSince time.Time `json:"waiting-since,omitempty"`
+	// TimedOut is true once this device gave up waiting for the devices of
+	// the previous batches. Like a failure, it isn't retried until the
+	// cluster assertion changes.
+	TimedOut bool `json:"timed-out,omitempty"`
+	// Previous holds the revisions of the snaps of the subcluster before the
+	// device started applying the cluster assertion. Snaps that were not
+	// installed have an unset revision.
+	Previous map[string]snap.Revision `json:"previous,omitempty"`
+}
+
+func getRolloutState(st *state.State, cluster *asserts.Cluster) (*rolloutState, error) {
+	var rs rolloutState
+	if err := st.Get("cluster-rollout", &rs); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	if rs.ClusterID != cluster.ClusterID() || rs.Sequence != cluster.Sequence() {
+		rs = rolloutState{
+			ClusterID: cluster.ClusterID(),
+			Sequence:  cluster.Sequence(),
+		}
+	}
+	if rs.Subclusters == nil {
+		rs.Subclusters = make(map[string]*subclusterRollout)
+	}
+
+	return &rs, nil
+}
+
+// rolloutStatuses returns the rollout status of the subclusters of this device
+// that are rolled out in batches, for the current cluster assertion.
+func rolloutStatuses(st *state.State, cluster *asserts.Cluster) (map[string]string, error) {
+	rs, err := getRolloutState(st, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rs.Subclusters) == 0 {
+		return nil, nil
+	}
+
+	statuses := make(map[string]string, len(rs.Subclusters))
+	for name, sr := range rs.Subclusters {
+		statuses[name] = string(sr.Status)
+	}
+	return statuses, nil
+}
+
+// pendingChange holds the tasks of a change that must be created to apply the
+// cluster assertion to a subcluster.
+type pendingChange struct {
+	kind    string
+	summary string
+	ref     clusterChangeRef
+	tasks   *state.TaskSet
+}
+
+// rollOutClusterState applies the cluster assertion to the subclusters of this
+// device that are rolled out in batches. Each device waits for the devices of
+// the previous batches to report, through the convergence reports exchanged
+// with the peers, that they applied the cluster assertion and that their
+// snaps are healthy. It returns the changes that must be created and whether
+// the state of those subclusters already matches the cluster assertion.
+func rollOutClusterState(st *state.State, cluster *asserts.Cluster, inProgress map[clusterChangeRef]bool) ([]pendingChange, bool, error) {
+	deviceID, err := localDeviceID(st, cluster)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rs, err := getRolloutState(st, cluster)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reports, err := peerReports(st)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var changes []pendingChange
+	converged := true
+	for _, subcluster := range cluster.Subclusters() {
+		if subcluster.Rollout == nil || !deviceInSubcluster(subcluster, deviceID) {
+			continue
+		}
+
+		sr := rs.Subclusters[subcluster.Name]
+		if sr == nil {
+			sr = &subclusterRollout{}
+			rs.Subclusters[subcluster.Name] = sr
+		}
+
+		r := &subclusterRolloutContext{
+			st:       st,
+			cluster:  cluster,
+			sub:      subcluster,
+			deviceID: deviceID,
+			reports:  reports,
+			sr:       sr,
+		}
+
+		chg, subConverged, err := r.rollOut(inProgress)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if chg != nil {
+			changes = append(changes, *chg)
+		}
+		converged = converged && subConverged
+	}
+
+	if len(rs.Subclusters) > 0 {
+		st.Set("cluster-rollout", rs)
+	}
+
+	return changes, converged, nil
+}
+
+type subclusterRolloutContext struct {
+	st       *state.State
+	cluster  *asserts.Cluster
+	sub      asserts.Subcluster
+	deviceID int
+	reports  map[string]assemblestate.Report
+	sr       *subclusterRollout
+}
+
+func (r *subclusterRolloutContext) ref() clusterChangeRef {
+	return clusterChangeRef{ClusterID: r.cluster.ClusterID(), Subcluster: r.sub.Name}
+}
+
+func (r *subclusterRolloutContext) rollOut(inProgress map[clusterChangeRef]bool) (*pendingChange, bool, error) {
+	sr := r.sr
+	if inProgress[r.ref()] {
+		if sr.Reverted {
+			sr.Status = RolloutReverting
+		} else {
+			sr.Status = RolloutApplying
+		}
+		return nil, false, nil
+	}
+
+	if sr.Reverted {
+		sr.Status = RolloutReverted
+		return nil, false, nil
+	}
+
+	health, err := r.health()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if sr.Started && !sr.Failed {
+		sr.Failed = health == RolloutFailed || r.changeFailed() || r.batchFailed()
+	}
+
+	if sr.Failed {
+		if r.sub.Rollout.OnFailure != asserts.ClusterRolloutRevert {
+			sr.Status = RolloutFailed
+			return nil, false, nil
+		}
+
+		ts, err := r.revert()
+		if err != nil {
+			return nil, false, err
+		}
+
+		sr.Reverted = true
+		if len(ts.Tasks()) == 0 {
+			sr.Status = RolloutReverted
+			return nil, false, nil
+		}
+
+		sr.Status = RolloutReverting
+		return &pendingChange{
+			kind:    revertClusterSubclusterChangeKind,
+			summary: fmt.Sprintf("Revert subcluster %q state", r.sub.Name),
+			ref:     r.ref(),
+			tasks:   ts,
+		}, false, nil
+	}
+
+	installs, removals, updates, err := snapsForSubcluster(r.st, r.sub)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(installs) == 0 && len(removals) == 0 && len(updates) == 0 {
+		sr.Status = health
+		return nil, true, nil
+	}
+
+	if !sr.Started {
+		if !sr.TimedOut {
+			status := r.batchGate()
+			if status == RolloutWaiting && r.waitExpired() {
+				sr.TimedOut = true
+			} else if status != "" {
+				sr.Status = status
+				return nil, false, nil
+			}
+		}
+
+		if sr.TimedOut {
+			sr.Status = RolloutTimedOut
+			return nil, false, nil
+		}
+
+		previous, err := r.revisions()
+		if err != nil {
+			return nil, false, err
+		}
+		sr.Started = true
+		sr.Previous = previous
+	}
+
+	ts, err := applySubcluster(r.st, r.sub)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sr.Status = RolloutApplying
+	return &pendingChange{
+		kind:    applyClusterSubclusterChangeKind,
+		summary: fmt.Sprintf("Apply subcluster %q state", r.sub.Name),
+		ref:     r.ref(),
+		tasks:   ts,
+	}, false, nil
+}
+
+// health returns RolloutHealthy if all the snaps of the subcluster that are
+// installed on this device are healthy, RolloutApplied if some of them are
+// still waiting to become healthy and RolloutFailed if any of them is
+// blocked or in error. Snaps that never reported their health for their
+// current revision are considered healthy.
+func (r *subclusterRolloutContext) health() (RolloutStatus, error) {
+	all, err := healthstate.All(r.st)
+	if err != nil {
+		return "", err
+	}
+
+	status := RolloutHealthy
+	for _, sn := range r.sub.Snaps {
+		health := all[sn.Instance]
+		if health == nil {
+			continue
+		}
+
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(r.st, sn.Instance, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				continue
+			}
+			return "", err
+		}
+
+		if !snapst.IsInstalled() || health.Revision != snapst.Current {
+			continue
+		}
+
+		switch health.Status {
+		case healthstate.BlockedStatus, healthstate.ErrorStatus:
+			return RolloutFailed, nil
+		case healthstate.WaitingStatus:
+			status = RolloutApplied
+		}
+	}
+
+	return status, nil
+}
+
+// changeFailed returns true if a change that applied the current cluster
+// assertion to the subcluster failed.
+func (r *subclusterRolloutContext) changeFailed() bool {
+	for _, chg := range r.st.Changes() {
+		if chg.Kind() != applyClusterSubclusterChangeKind || chg.Status() != state.ErrorStatus {
+			continue
+		}
+
+		var ref clusterChangeRef
+		if err := chg.Get("cluster-change-ref", &ref); err != nil || ref != r.ref() {
+			continue
+		}
+
+		var seq int
+		if err := chg.Get("cluster-sequence", &seq); err != nil || seq != r.cluster.Sequence() {
+			continue
+		}
+
+		return true
+	}
+	return false
+}
+
+// batch returns the index of the batch of the device with the given ID.
+func (r *subclusterRolloutContext) batch(deviceID int) int {
+	for i, id := range r.sub.Devices {
+		if id == deviceID {
+			return i / r.sub.Rollout.BatchSize
+		}
+	}
+	return -1
+}
+
+// peerStatus returns the rollout status of the current cluster assertion
+// reported by the device with the given ID, or an empty status if the device
+// didn't report it yet.
+func (r *subclusterRolloutContext) peerStatus(deviceID int) RolloutStatus {
+	for _, dev := range r.cluster.Devices() {
+		if dev.ID != deviceID {
+			continue
+		}
+
+		report, ok := r.reports[dev.Serial]
+		if !ok || report.ClusterID != r.cluster.ClusterID() || report.Sequence != r.cluster.Sequence() {
+			return ""
+		}
+		return RolloutStatus(report.Rollout[r.sub.Name])
+	}
+	return ""
+}
+
+// batchFailed returns true if another device of this device's batch failed to
+// apply the cluster assertion.
+func (r *subclusterRolloutContext) batchFailed() bool {
+	batch := r.batch(r.deviceID)
+	for _, id := range r.sub.Devices {
+		if id == r.deviceID || r.batch(id) != batch {
+			continue
+		}
+
+		switch r.peerStatus(id) {
+		case RolloutFailed, RolloutReverting, RolloutReverted:
+			return true
+		}
+	}
+	return false
+}
+
+// batchGate returns an empty status if this device can start applying the
+// cluster assertion, RolloutHalted if a device of a previous batch stops the
+// rollout and RolloutWaiting otherwise.
+func (r *subclusterRolloutContext) batchGate() RolloutStatus {
+	batch := r.batch(r.deviceID)
+	status := RolloutStatus("")
+	for _, id := range r.sub.Devices {
+		if r.batch(id) >= batch {
+			continue
+		}
+
+		peer := r.peerStatus(id)
+		if peer.stopsRollout() {
+			return RolloutHalted
+		}
+		if peer != RolloutHealthy {
+			status = RolloutWaiting
+		}
+	}
+	return status
+}
+
+// waitExpired returns true once this device has been waiting for the devices
+// of the previous batches for longer than those batches had to apply the
+// cluster assertion. Until then, it makes sure that the manager checks again
+// when the wait expires, even if nothing else happens.
+func (r *subclusterRolloutContext) waitExpired() bool {
+	now := timeNow()
+	if r.sr.WaitingSince.IsZero() {
+		r.sr.WaitingSince = now
+	}
+
+	timeout := r.sub.Rollout.BatchTimeout
+	if timeout == 0 {
+		timeout = defaultBatchTimeout
+	}
+
+	deadline := r.sr.WaitingSince.Add(time.Duration(r.batch(r.deviceID)) * timeout)
+	if !now.Before(deadline) {
+		return true
+	}
+
+	r.st.EnsureBefore(deadline.Sub(now))
+	return false
+}
+
+// revisions returns the revisions of the snaps of the subcluster, with an
+// unset revision for the snaps that are not installed.
+func (r *subclusterRolloutContext) revisions() (map[string]snap.Revision, error) {
+	revisions := make(map[string]snap.Revision, len(r.sub.Snaps))
+	for _, sn := range r.sub.Snaps {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(r.st, sn.Instance, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+			return nil, err
+		}
+
+		var rev snap.Revision
+		if snapst.IsInstalled() {
+			rev = snapst.Current
+		}
+		revisions[sn.Instance] = rev
+	}
+	return revisions, nil
+}
+
+// revert creates the tasks that bring the snaps of the subcluster back to the
+// revisions they had before this device started applying the cluster
+// assertion. Snaps that were installed by the rollout are removed.
+func (r *subclusterRolloutContext) revert() (*state.TaskSet, error) {
+	current, err := r.revisions()
+	if err != nil {
+		return nil, err
+	}
+
+	combined := state.NewTaskSet()
+	var removals []string
+	var installs []snapstate.StoreSnap
+	for _, sn := range r.sub.Snaps {
+		prev, ok := r.sr.Previous[sn.Instance]
+		cur := current[sn.Instance]
+		if !ok || prev == cur {
+			continue
+		}
+
+		switch {
+		case prev.Unset():
+			removals = append(removals, sn.Instance)
+		case cur.Unset():
+			installs = append(installs, snapstate.StoreSnap{
+				InstanceName: sn.Instance,
+				RevOpts:      snapstate.RevisionOptions{Revision: prev},
+			})
+		default:
+			ts, err := revertToRevision(r.st, sn.Instance, prev, snapstate.Flags{}, "")
+			if err != nil {
+				return nil, fmt.Errorf("cannot revert snap %q: %w", sn.Instance, err)
+			}
+			combined.AddAll(ts)
+		}
+	}
+
+	if len(removals) > 0 {
+		_, removeTS, err := removeMany(r.st, removals, &snapstate.RemoveFlags{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create snap removal tasks: %w", err)
+		}
+		for _, ts := range removeTS {
+			combined.AddAll(ts)
+		}
+	}
+
+	if len(installs) > 0 {
+		goal := storeInstallGoal(installs...)
+		_, installTS, err := installWithGoal(context.Background(), r.st, goal, snapstate.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create snap installation tasks: %w", err)
+		}
+		for _, ts := range installTS {
+			combined.AddAll(ts)
+		}
+	}
+
+	return combined, nil
+}
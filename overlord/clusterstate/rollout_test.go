@@ -0,0 +1,633 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package clusterstate_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/cluster/assemblestate"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/clusterstate"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/sequence"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type rolloutSuite struct {
+	testutil.BaseTest
+
+	st  *state.State
+	mgr *clusterstate.ClusterManager
+	now time.Time
+
+	updates []string
+	reverts []snap.Revision
+
+	// batchTimeout is the batch-timeout of the rollout created by setup, if
+	// set
+	batchTimeout string
+}
+
+var _ = check.Suite(&rolloutSuite{})
+
+func (s *rolloutSuite) SetUpTest(c *check.C) {
+	s.BaseTest.SetUpTest(c)
+
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.batchTimeout = ""
+	s.now = time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	s.AddCleanup(clusterstate.MockTimeNow(func() time.Time { return s.now }))
+
+	s.updates = nil
+	s.AddCleanup(clusterstate.MockSnapstateUpdateWithGoal(func(ctx context.Context, st *state.State, goal snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) ([]string, *snapstate.UpdateTaskSets, error) {
+		s.updates = append(s.updates, "some-snap")
+		task := st.NewTask("update", "update channel")
+		return []string{"some-snap"}, &snapstate.UpdateTaskSets{
+			Refresh: []*state.TaskSet{state.NewTaskSet(task)},
+		}, nil
+	}))
+
+	s.reverts = nil
+	s.AddCleanup(clusterstate.MockRevertToRevision(func(st *state.State, name string, rev snap.Revision, flags snapstate.Flags, fromChange string) (*state.TaskSet, error) {
+		c.Check(name, check.Equals, "some-snap")
+		s.reverts = append(s.reverts, rev)
+		return state.NewTaskSet(st.NewTask("revert", "revert snap")), nil
+	}))
+}
+
+func (s *rolloutSuite) TearDownTest(c *check.C) {
+	if s.mgr != nil {
+		s.mgr.Stop()
+	}
+	s.BaseTest.TearDownTest(c)
+}
+
+// setup creates a cluster of three devices whose "default" subcluster is
+// rolled out with the given batch size and failure policy. The device has
+// some-snap at revision 3 tracking a channel that differs from the one in the
+// cluster assertion.
+func (s *rolloutSuite) setup(c *check.C, serial string, batchSize string, onFailure string) {
+	st, stack := newStateWithStoreStack(c)
+	s.st = st
+
+	var devices []map[string]any
+	for _, id := range []string{"1", "2", "3"} {
+		devices = append(devices, map[string]any{
+			"id":        id,
+			"device":    "serial-" + id + ".ubuntu-core-24-amd64.canonical",
+			"addresses": []any{"192.168.0.1" + id},
+		})
+	}
+
+	rollout := map[string]any{
+		"batch-size": batchSize,
+		"on-failure": onFailure,
+	}
+	if s.batchTimeout != "" {
+		rollout["batch-timeout"] = s.batchTimeout
+	}
+
+	bundle, _ := makeClusterBundle(c, stack, devices, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1", "2", "3"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "clustered",
+				"instance": "some-snap",
+				"channel":  "latest/stable",
+			},
+		},
+		"rollout": rollout,
+	}})
+
+	st.Lock()
+	defer st.Unlock()
+
+	addSerialToState(c, st, makeSerialAssertion(c, stack, serial))
+	s.setSnap(snap.R(3), "latest/edge")
+
+	err := clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle))
+	c.Assert(err, check.IsNil)
+
+	s.mgr = clusterstate.Manager(st, state.NewTaskRunner(st))
+}
+
+func (s *rolloutSuite) setSnap(rev snap.Revision, channel string) {
+	setSomeSnap(s.st, rev, channel)
+}
+
+func setSomeSnap(st *state.State, rev snap.Revision, channel string) {
+	var revisions []*sequence.RevisionSideState
+	for i := 3; i <= rev.N; i++ {
+		revisions = append(revisions, sequence.NewRevisionSideState(&snap.SideInfo{Revision: snap.R(i)}, nil))
+	}
+	snapstate.Set(st, "some-snap", &snapstate.SnapState{
+		Active:          true,
+		Current:         rev,
+		TrackingChannel: channel,
+		Sequence:        sequence.SnapSequence{Revisions: revisions},
+	})
+}
+
+func (s *rolloutSuite) setHealth(rev snap.Revision, status healthstate.HealthStatus) {
+	s.st.Set("health", map[string]*healthstate.HealthState{
+		"some-snap": {Revision: rev, Status: status, Timestamp: s.now},
+	})
+}
+
+func (s *rolloutSuite) ensure(c *check.C) {
+	s.st.Unlock()
+	defer s.st.Lock()
+	c.Assert(s.mgr.Ensure(), check.IsNil)
+}
+
+func (s *rolloutSuite) peerReport(c *check.C, serial string, status clusterstate.RolloutStatus) {
+	s.now = s.now.Add(time.Second)
+	err := clusterstate.RecordReport(s.st, assemblestate.Report{
+		Serial:    serial,
+		ClusterID: "cluster-id",
+		Sequence:  1,
+		Rollout:   map[string]string{"default": string(status)},
+		Time:      s.now,
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *rolloutSuite) checkStatus(c *check.C, expected clusterstate.RolloutStatus, converged bool) {
+	report, err := clusterstate.LocalReport(s.st)
+	c.Assert(err, check.IsNil)
+	c.Check(report.Rollout, check.DeepEquals, map[string]string{"default": string(expected)})
+	c.Check(report.Converged, check.Equals, converged)
+}
+
+func (s *rolloutSuite) clusterChanges() []*state.Change {
+	return clusterChanges(s.st)
+}
+
+func clusterChanges(st *state.State) []*state.Change {
+	var changes []*state.Change
+	for _, chg := range st.Changes() {
+		switch chg.Kind() {
+		case "apply-cluster-subcluster", "revert-cluster-subcluster":
+			changes = append(changes, chg)
+		}
+	}
+	// in the order they were created
+	sort.Slice(changes, func(i, j int) bool {
+		left, _ := strconv.Atoi(changes[i].ID())
+		right, _ := strconv.Atoi(changes[j].ID())
+		return left < right
+	})
+	return changes
+}
+
+// finishApply marks the change applying the cluster assertion as done and
+// updates the snap like the change would have.
+func (s *rolloutSuite) finishApply(c *check.C, chg *state.Change, status state.Status) {
+	for _, t := range chg.Tasks() {
+		if status == state.ErrorStatus {
+			t.Errorf("cannot refresh")
+		}
+		t.SetStatus(status)
+	}
+	c.Assert(chg.Status(), check.Equals, status)
+
+	if status == state.DoneStatus {
+		s.setSnap(snap.R(4), "latest/stable")
+	}
+}
+
+func (s *rolloutSuite) TestFirstBatchAppliesImmediately(c *check.C) {
+	s.setup(c, "serial-1", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+	c.Check(changes[0].Kind(), check.Equals, "apply-cluster-subcluster")
+	c.Check(changes[0].Summary(), check.Equals, `Apply subcluster "default" state`)
+
+	var seq int
+	c.Assert(changes[0].Get("cluster-sequence", &seq), check.IsNil)
+	c.Check(seq, check.Equals, 1)
+	c.Check(s.updates, check.DeepEquals, []string{"some-snap"})
+	s.checkStatus(c, clusterstate.RolloutApplying, false)
+
+	// the change isn't created twice
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 1)
+
+	s.finishApply(c, changes[0], state.DoneStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutHealthy, true)
+}
+
+func (s *rolloutSuite) TestLaterBatchWaitsForPreviousBatches(c *check.C) {
+	s.setup(c, "serial-3", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	c.Check(s.updates, check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	// the first batch is done but its snaps aren't healthy yet
+	s.peerReport(c, "serial-1", clusterstate.RolloutApplied)
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	// all the devices of the previous batches must be healthy
+	s.peerReport(c, "serial-1", clusterstate.RolloutHealthy)
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	s.peerReport(c, "serial-2", clusterstate.RolloutHealthy)
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 1)
+	c.Check(s.updates, check.DeepEquals, []string{"some-snap"})
+	s.checkStatus(c, clusterstate.RolloutApplying, false)
+}
+
+func (s *rolloutSuite) TestWaitTimesOut(c *check.C) {
+	s.batchTimeout = "1h"
+	s.setup(c, "serial-3", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	// the device of the third batch waits for two batches
+	s.peerReport(c, "serial-1", clusterstate.RolloutHealthy)
+	s.now = s.now.Add(2*time.Hour - 2*time.Second)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	s.now = s.now.Add(time.Second)
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutTimedOut, false)
+
+	// late reports don't resume the rollout
+	s.peerReport(c, "serial-2", clusterstate.RolloutHealthy)
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	c.Check(s.updates, check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutTimedOut, false)
+}
+
+func (s *rolloutSuite) TestWaitDefaultTimeout(c *check.C) {
+	s.setup(c, "serial-2", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	s.now = s.now.Add(12*time.Hour - time.Second)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+
+	s.now = s.now.Add(time.Second)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutTimedOut, false)
+}
+
+func (s *rolloutSuite) TestHaltedByTimedOutBatch(c *check.C) {
+	s.setup(c, "serial-3", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.peerReport(c, "serial-1", clusterstate.RolloutHealthy)
+	s.peerReport(c, "serial-2", clusterstate.RolloutTimedOut)
+	s.ensure(c)
+
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutHalted, false)
+}
+
+func (s *rolloutSuite) TestReportsForOtherSequencesIgnored(c *check.C) {
+	s.setup(c, "serial-2", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	err := clusterstate.RecordReport(s.st, assemblestate.Report{
+		Serial:    "serial-1",
+		ClusterID: "cluster-id",
+		Sequence:  0,
+		Rollout:   map[string]string{"default": "healthy"},
+		Time:      s.now,
+	})
+	c.Assert(err, check.IsNil)
+
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutWaiting, false)
+}
+
+func (s *rolloutSuite) TestHaltedByFailedBatch(c *check.C) {
+	s.setup(c, "serial-3", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.peerReport(c, "serial-1", clusterstate.RolloutHealthy)
+	s.peerReport(c, "serial-2", clusterstate.RolloutFailed)
+	s.ensure(c)
+
+	c.Check(s.clusterChanges(), check.HasLen, 0)
+	c.Check(s.updates, check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutHalted, false)
+
+	// halted devices halt the following batches too
+	s.peerReport(c, "serial-2", clusterstate.RolloutHalted)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutHalted, false)
+}
+
+func (s *rolloutSuite) TestWaitsForHealth(c *check.C) {
+	s.setup(c, "serial-1", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+
+	s.finishApply(c, changes[0], state.DoneStatus)
+	s.setHealth(snap.R(4), healthstate.WaitingStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutApplied, true)
+
+	// health reported for a previous revision is ignored
+	s.setHealth(snap.R(3), healthstate.ErrorStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutHealthy, true)
+
+	s.setHealth(snap.R(4), healthstate.OkayStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutHealthy, true)
+	c.Check(s.clusterChanges(), check.HasLen, 1)
+}
+
+func (s *rolloutSuite) TestFailureHalts(c *check.C) {
+	s.setup(c, "serial-1", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+
+	s.finishApply(c, changes[0], state.ErrorStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutFailed, false)
+
+	// a failed rollout isn't retried
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 1)
+	c.Check(s.updates, check.HasLen, 1)
+	c.Check(s.reverts, check.HasLen, 0)
+	s.checkStatus(c, clusterstate.RolloutFailed, false)
+}
+
+func (s *rolloutSuite) TestUnhealthyReverts(c *check.C) {
+	s.setup(c, "serial-1", "1", "revert")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+
+	s.finishApply(c, changes[0], state.DoneStatus)
+	s.setHealth(snap.R(4), healthstate.ErrorStatus)
+	s.ensure(c)
+
+	c.Check(s.reverts, check.DeepEquals, []snap.Revision{snap.R(3)})
+	changes = s.clusterChanges()
+	c.Assert(changes, check.HasLen, 2)
+	revert := changes[1]
+	c.Check(revert.Kind(), check.Equals, "revert-cluster-subcluster")
+	c.Check(revert.Summary(), check.Equals, `Revert subcluster "default" state`)
+	s.checkStatus(c, clusterstate.RolloutReverting, false)
+
+	for _, t := range revert.Tasks() {
+		t.SetStatus(state.DoneStatus)
+	}
+	s.setSnap(snap.R(3), "latest/stable")
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutReverted, false)
+
+	// nothing else happens until the cluster assertion changes
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 2)
+	c.Check(s.updates, check.HasLen, 1)
+}
+
+func (s *rolloutSuite) TestFailedBatchPeerReverts(c *check.C) {
+	s.setup(c, "serial-1", "2", "revert")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+	s.finishApply(c, changes[0], state.DoneStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutHealthy, true)
+
+	// serial-2 is in the same batch, when it fails the whole batch reverts
+	s.peerReport(c, "serial-2", clusterstate.RolloutFailed)
+	s.ensure(c)
+
+	c.Check(s.reverts, check.DeepEquals, []snap.Revision{snap.R(3)})
+	c.Check(s.clusterChanges(), check.HasLen, 2)
+	s.checkStatus(c, clusterstate.RolloutReverting, false)
+}
+
+func (s *rolloutSuite) TestRolloutResetsWithNewSequence(c *check.C) {
+	s.setup(c, "serial-1", "1", "halt")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	s.ensure(c)
+	changes := s.clusterChanges()
+	c.Assert(changes, check.HasLen, 1)
+	s.finishApply(c, changes[0], state.ErrorStatus)
+	s.ensure(c)
+	s.checkStatus(c, clusterstate.RolloutFailed, false)
+
+	// a new sequence of the cluster assertion starts a new rollout
+	var rs map[string]any
+	c.Assert(s.st.Get("cluster-rollout", &rs), check.IsNil)
+	rs["sequence"] = 0
+	s.st.Set("cluster-rollout", rs)
+
+	s.ensure(c)
+	c.Check(s.clusterChanges(), check.HasLen, 2)
+	s.checkStatus(c, clusterstate.RolloutApplying, false)
+}
+
+// freeAddress returns a local address that is free to listen on.
+func freeAddress(c *check.C) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func (s *rolloutSuite) TestMultiBatchRolloutThroughReports(c *check.C) {
+	stack := assertstest.NewStoreStack("canonical", nil)
+	const accountID = "cluster-brand"
+	sa := registerAccount(stack, accountID)
+
+	// two devices in two batches, which only learn about each other's
+	// progress through the reports they exchange
+	addresses := []string{freeAddress(c), freeAddress(c)}
+	var devices []map[string]any
+	for i, addr := range addresses {
+		id := strconv.Itoa(i + 1)
+		devices = append(devices, map[string]any{
+			"id":        id,
+			"device":    "serial-" + id + ".ubuntu-core-24-amd64.canonical",
+			"addresses": []any{addr},
+		})
+	}
+	bundle, _ := makeClusterBundleWithSigning(c, sa, accountID, "cluster-id", 1, devices, []map[string]any{{
+		"name":    "default",
+		"devices": []any{"1", "2"},
+		"snaps": []any{
+			map[string]any{
+				"state":    "clustered",
+				"instance": "some-snap",
+				"channel":  "latest/stable",
+			},
+		},
+		"rollout": map[string]any{"batch-size": "1"},
+	}})
+
+	var ids []assemblestate.Identity
+	var certs, keys [][]byte
+	for _, serial := range []string{"serial-1", "serial-2"} {
+		cert, certPEM, keyPEM := generatePEMCert(c)
+		ids = append(ids, identity(c, stack, serial, cert))
+		certs = append(certs, certPEM)
+		keys = append(keys, keyPEM)
+	}
+
+	var states []*state.State
+	var mgrs []*clusterstate.ClusterManager
+	for i, serial := range []string{"serial-1", "serial-2"} {
+		// each device keeps its TLS key in its own directory
+		dirs.SetRootDir(c.MkDir())
+
+		st := newStateTrusting(c, stack)
+		st.Lock()
+		addSerialToState(c, st, makeSerialAssertion(c, stack, serial))
+		setSomeSnap(st, snap.R(3), "latest/edge")
+		c.Assert(clusterstate.InitializeNewCluster(st, bytes.NewReader(bundle)), check.IsNil)
+		c.Assert(clusterstate.RecordAssembly(st, addresses[i], certs[i], keys[i], ids), check.IsNil)
+		st.Unlock()
+
+		mgr := clusterstate.Manager(st, state.NewTaskRunner(st))
+		// the manager reads its key when it starts exchanging reports
+		c.Assert(mgr.Ensure(), check.IsNil)
+		defer mgr.Stop()
+
+		states = append(states, st)
+		mgrs = append(mgrs, mgr)
+	}
+
+	rolloutStatus := func(i int) string {
+		states[i].Lock()
+		defer states[i].Unlock()
+		report, err := clusterstate.LocalReport(states[i])
+		c.Assert(err, check.IsNil)
+		return report.Rollout["default"]
+	}
+
+	// waitFor runs the ensure loop of the device until its rollout has the
+	// given status
+	waitFor := func(i int, status clusterstate.RolloutStatus) {
+		for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+			c.Assert(mgrs[i].Ensure(), check.IsNil)
+			if rolloutStatus(i) == string(status) {
+				return
+			}
+		}
+		c.Fatalf("timed out waiting for device %d to be %q, it is %q", i+1, status, rolloutStatus(i))
+	}
+
+	// the first batch applies the cluster assertion right away, while the
+	// second waits
+	waitFor(0, clusterstate.RolloutApplying)
+	waitFor(1, clusterstate.RolloutWaiting)
+
+	st := states[0]
+	st.Lock()
+	changes := clusterChanges(st)
+	c.Assert(changes, check.HasLen, 1)
+	for _, t := range changes[0].Tasks() {
+		t.SetStatus(state.DoneStatus)
+	}
+	setSomeSnap(st, snap.R(4), "latest/stable")
+	st.Unlock()
+	// reports are ordered by the time they were made
+	s.now = s.now.Add(time.Minute)
+
+	// once the first device reports that it's healthy, the second batch
+	// starts
+	waitFor(0, clusterstate.RolloutHealthy)
+	waitFor(1, clusterstate.RolloutApplying)
+
+	states[1].Lock()
+	c.Check(clusterChanges(states[1]), check.HasLen, 1)
+	states[1].Unlock()
+}
@@ -22,15 +22,19 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
 	"gopkg.in/tomb.v2"
 )
 
@@ -51,6 +55,7 @@ type ConfdbManager struct{}
 func Manager(st *state.State, hookMgr *hookstate.HookManager, runner *state.TaskRunner) *ConfdbManager {
 	snapstate.IsConfdbHookname = IsConfdbHookname
 	hookstate.IsConfdbHookname = IsConfdbHookname
+	assertstate.PrepareConfdbSchemaUpdate = prepareSchemaUpdate
 
 	m := &ConfdbManager{}
 
@@ -77,6 +82,9 @@ func Manager(st *state.State, hookMgr *hookstate.HookManager, runner *state.Task
 	hookMgr.Register(regexp.MustCompile("^load-view-.+$"), func(context *hookstate.Context) hookstate.Handler {
 		return &hookstate.SnapHookHandler{}
 	})
+	hookMgr.Register(regexp.MustCompile("^migrate-view-.+$"), func(context *hookstate.Context) hookstate.Handler {
+		return &hookstate.SnapHookHandler{}
+	})
 
 	return m
 }
@@ -97,9 +105,97 @@ func (m *ConfdbManager) doCommitTransaction(t *state.Task, _ *tomb.Tomb) (err er
 	if err != nil {
 		return err
 	}
-	schema := confdbAssert.Schema().DatabagSchema
+	dbSchema := confdbAssert.Schema()
+	schema := dbSchema.DatabagSchema
+
+	paths := tx.AlteredPaths()
+	before, err := readDatabag(st, tx.ConfdbAccount, tx.ConfdbName)
+	if err != nil {
+		return err
+	}
+	// the commit must not modify the databag we compare against
+	before = before.Copy()
+
+	if err := tx.Commit(st, schema); err != nil {
+		return err
+	}
+
+	after, err := readDatabag(st, tx.ConfdbAccount, tx.ConfdbName)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffDatabags(before, after, paths)
+	if err != nil {
+		return fmt.Errorf("cannot record confdb history: %v", err)
+	}
+
+	if len(diff) == 0 {
+		// nothing was changed, nothing to record
+		return nil
+	}
+
+	var changeID string
+	if chg := t.Change(); chg != nil {
+		changeID = chg.ID()
+	}
+
+	// secrets are encrypted in the history, like in the databag
+	if schema.NestedVisibility(confdb.SecretVisibility) {
+		diff, err = transformSecretsInHistory(st, schema, diff, sealValue)
+		if err != nil {
+			return fmt.Errorf("cannot record confdb history: %v", err)
+		}
+	}
 
-	return tx.Commit(st, schema)
+	txID, err := recordHistory(st, tx, changeID, diff)
+	if err != nil {
+		return err
+	}
+
+	return addChangeNotices(st, dbSchema, txID, diff)
+}
+
+// addChangeNotices records a confdb-change notice for each view with
+// visibility into the data changed by a transaction, so clients outside of
+// snaps can observe the changes like observe-view hooks do.
+func addChangeNotices(st *state.State, dbSchema *confdb.Schema, txID int, diff []HistoryDelta) error {
+	affected := make(map[string][]string)
+	for _, delta := range diff {
+		path, err := confdb.ParsePathIntoAccessors(delta.Path, confdb.ParseOptions{})
+		if err != nil {
+			return fmt.Errorf("internal error: cannot parse path %q: %v", delta.Path, err)
+		}
+
+		for _, view := range dbSchema.GetViewsAffectedByPath(path) {
+			for _, request := range view.RequestsAffectedByPath(path) {
+				if !strutil.ListContains(affected[view.Name], request) {
+					affected[view.Name] = append(affected[view.Name], request)
+				}
+			}
+		}
+	}
+
+	viewNames := make([]string, 0, len(affected))
+	for name := range affected {
+		viewNames = append(viewNames, name)
+	}
+	sort.Strings(viewNames)
+
+	for _, name := range viewNames {
+		requests := affected[name]
+		sort.Strings(requests)
+
+		key := dbSchema.View(name).ID()
+		data := map[string]string{
+			"requests":       strings.Join(requests, ","),
+			"transaction-id": strconv.Itoa(txID),
+		}
+		if _, err := st.AddNotice(nil, state.ConfdbChangeNotice, key, &state.AddNoticeOptions{Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *ConfdbManager) clearOngoingTransaction(t *state.Task, _ *tomb.Tomb) error {
@@ -168,7 +264,12 @@ func readViewIntoChange(chg *state.Change, tx *Transaction, view *confdb.View, r
 		apiData = make(map[string]any)
 	}
 
-	result, err := GetViaView(tx, view, requests, constraints)
+	bag, err := RedactSecretsFor(chg.State(), tx, view, "")
+	if err != nil {
+		return err
+	}
+
+	result, err := GetViaView(bag, view, requests, constraints)
 	if err != nil {
 		if !errors.Is(err, &confdb.NoDataError{}) {
 			// other errors (no match/view) would be detected before the change is created
@@ -19,15 +19,18 @@
 package confdbstate_test
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
@@ -52,11 +55,17 @@ func (s *hookHandlerSuite) SetUpTest(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
 
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+	})
+	c.Assert(err, IsNil)
+	assertstate.ReplaceDB(s.state, db)
+
 	s.repo = interfaces.NewRepository()
 	ifacerepo.Replace(s.state, s.repo)
 
 	confdbIface := &ifacetest.TestInterface{InterfaceName: "confdb"}
-	err := s.repo.AddInterface(confdbIface)
+	err = s.repo.AddInterface(confdbIface)
 	c.Assert(err, IsNil)
 
 	const coreYaml = `name: core
@@ -463,6 +472,47 @@ func (s *confdbTestSuite) TestCommitTransaction(c *C) {
 	c.Assert(val, Equals, "foo")
 }
 
+func (s *confdbTestSuite) TestCommitTransactionAddsChangeNotices(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo", "wifi.psk": "secret"})
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.ConfdbChangeNotice}})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["user-id"], IsNil)
+	c.Check(n["key"], Equals, s.devAccID+"/network/setup-wifi")
+	c.Check(n["occurrences"], Equals, 1.0)
+	c.Check(n["last-data"], DeepEquals, map[string]any{
+		"requests":       "password,ssid",
+		"transaction-id": "1",
+	})
+
+	// a transaction which doesn't change anything doesn't add notices
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+
+	// changes through the same view repeat the notice
+	s.commitTx(c, "test-snap", map[string]any{"private.a": "b"})
+
+	notices = s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.ConfdbChangeNotice}})
+	c.Assert(notices, HasLen, 1)
+	n = noticeToMap(c, notices[0])
+	c.Check(n["occurrences"], Equals, 2.0)
+	c.Check(n["last-data"], DeepEquals, map[string]any{
+		"requests":       "private.{placeholder}",
+		"transaction-id": "2",
+	})
+}
+
+func noticeToMap(c *C, notice *state.Notice) map[string]any {
+	buf, err := json.Marshal(notice)
+	c.Assert(err, IsNil)
+	var n map[string]any
+	c.Assert(json.Unmarshal(buf, &n), IsNil)
+	return n
+}
+
 func (s *confdbTestSuite) TestClearOngoingTransaction(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -158,33 +158,76 @@ func GetViaView(bag confdb.Databag, view *confdb.View, requests []string, constr
 }
 
 var readDatabag = func(st *state.State, account, dbSchemaName string) (confdb.JSONDatabag, error) {
+	bag, err := loadDatabag(st, account, dbSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	if bag == nil {
+		return confdb.NewJSONDatabag(), nil
+	}
+
+	schema, err := secretsSchema(st, account, dbSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		return transformSecretsInDatabag(st, schema, bag, unsealValue)
+	}
+
+	return bag, nil
+}
+
+var writeDatabag = func(st *state.State, databag confdb.JSONDatabag, account, dbSchemaName string) error {
+	schema, err := secretsSchema(st, account, dbSchemaName)
+	if err != nil {
+		return err
+	}
+	if schema != nil {
+		databag, err = transformSecretsInDatabag(st, schema, databag, sealValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	return saveDatabag(st, databag, account, dbSchemaName)
+}
+
+// loadDatabag returns the databag of the confdb as saved in the state, with
+// any secrets still encrypted, or nil if there is none.
+func loadDatabag(st *state.State, account, dbSchemaName string) (confdb.JSONDatabag, error) {
 	var databags map[string]map[string]confdb.JSONDatabag
 	if err := st.Get("confdb-databags", &databags); err != nil {
 		if errors.Is(err, &state.NoStateError{}) {
-			return confdb.NewJSONDatabag(), nil
+			return nil, nil
 		}
 		return nil, err
 	}
 
-	if databags[account] == nil || databags[account][dbSchemaName] == nil {
-		return confdb.NewJSONDatabag(), nil
-	}
-
 	return databags[account][dbSchemaName], nil
 }
 
-var writeDatabag = func(st *state.State, databag confdb.JSONDatabag, account, dbSchemaName string) error {
+// saveDatabag saves the databag of the confdb in the state as is, so any
+// secrets must already be encrypted. A nil databag removes it.
+func saveDatabag(st *state.State, databag confdb.JSONDatabag, account, dbSchemaName string) error {
 	var databags map[string]map[string]confdb.JSONDatabag
 	err := st.Get("confdb-databags", &databags)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
 		return err
-	} else if errors.Is(err, &state.NoStateError{}) || databags[account] == nil || databags[account][dbSchemaName] == nil {
-		databags = map[string]map[string]confdb.JSONDatabag{
-			account: {dbSchemaName: confdb.NewJSONDatabag()},
-		}
 	}
 
-	databags[account][dbSchemaName] = databag
+	// keep the databags of other confdbs
+	if databags == nil {
+		databags = make(map[string]map[string]confdb.JSONDatabag, 1)
+	}
+	if databags[account] == nil {
+		databags[account] = make(map[string]confdb.JSONDatabag, 1)
+	}
+
+	if databag == nil {
+		delete(databags[account], dbSchemaName)
+	} else {
+		databags[account][dbSchemaName] = databag
+	}
 	st.Set("confdb-databags", databags)
 	return nil
 }
@@ -256,6 +299,9 @@ func GetTransactionToSet(ctx *hookstate.Context, st *state.State, view *confdb.V
 		if ctx != nil {
 			callingSnap = ctx.InstanceName()
 		}
+		if tx.Author == "" {
+			tx.Author = callingSnap
+		}
 
 		ts, err := createChangeConfdbTasks(st, tx, view, callingSnap)
 		if err != nil {
@@ -308,19 +354,29 @@ const (
 )
 
 func createChangeConfdbTasks(st *state.State, tx *Transaction, view *confdb.View, callingSnap string) (*state.TaskSet, error) {
-	custodians, custodianPlugs, err := getCustodianPlugsForView(st, view)
+	return createCommitConfdbTasks(st, tx, []*confdb.View{view}, callingSnap)
+}
+
+// createCommitConfdbTasks returns a taskset that runs the custodians' hooks
+// for the given views and then commits the transaction.
+func createCommitConfdbTasks(st *state.State, tx *Transaction, views []*confdb.View, callingSnap string) (*state.TaskSet, error) {
+	custodians, custodianPlugs, err := getCustodianPlugsForViews(st, views)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(custodianPlugs) == 0 {
-		return nil, fmt.Errorf("cannot commit changes to confdb made through view %s: no custodian snap installed", view.ID())
+		return nil, fmt.Errorf("cannot commit changes to confdb made through view %s: no custodian snap installed", viewsID(views))
 	}
 
 	paths := tx.AlteredPaths()
-	mightAffectEph, err := view.WriteAffectsEphemeral(paths)
-	if err != nil {
-		return nil, err
+	var mightAffectEph bool
+	for _, view := range views {
+		affects, err := view.WriteAffectsEphemeral(paths)
+		if err != nil {
+			return nil, err
+		}
+		mightAffectEph = mightAffectEph || affects
 	}
 
 	ts := state.NewTaskSet()
@@ -342,26 +398,27 @@ func createChangeConfdbTasks(st *state.State, tx *Transaction, view *confdb.View
 	for _, hookPrefix := range hookPrefixes {
 		var saveViewHookPresent bool
 		for _, name := range custodians {
-			plug := custodianPlugs[name]
-			custodian := plug.Snap
-			if _, ok := custodian.Hooks[hookPrefix+plug.Name]; !ok {
-				continue
+			for _, plug := range custodianPlugs[name] {
+				custodian := plug.Snap
+				if _, ok := custodian.Hooks[hookPrefix+plug.Name]; !ok {
+					continue
+				}
+
+				saveViewHookPresent = true
+				const ignoreError = false
+				chgViewTask := setupConfdbHook(st, name, hookPrefix+plug.Name, ignoreError)
+				linkTask(chgViewTask)
 			}
-
-			saveViewHookPresent = true
-			const ignoreError = false
-			chgViewTask := setupConfdbHook(st, name, hookPrefix+plug.Name, ignoreError)
-			linkTask(chgViewTask)
 		}
 
 		if hookPrefix == "save-view-" && mightAffectEph && !saveViewHookPresent {
-			return nil, fmt.Errorf("cannot access %s: write might change ephemeral data but no custodians has a save-view hook", view.ID())
+			return nil, fmt.Errorf("cannot access %s: write might change ephemeral data but no custodians has a save-view hook", viewsID(views))
 		}
 	}
 
 	// run observe-view hooks for any plug that references a view that could have
 	// changed with this data modification
-	affectedPlugs, err := getPlugsAffectedByPaths(st, view.Schema(), paths)
+	affectedPlugs, err := getPlugsAffectedByPaths(st, views[0].Schema(), paths)
 	if err != nil {
 		return nil, err
 	}
@@ -386,7 +443,7 @@ func createChangeConfdbTasks(st *state.State, tx *Transaction, view *confdb.View
 	}
 
 	// commit after custodians save ephemeral data
-	commitTask := st.NewTask("commit-confdb-tx", fmt.Sprintf("Commit changes to confdb (%s)", view.ID()))
+	commitTask := st.NewTask("commit-confdb-tx", fmt.Sprintf("Commit changes to confdb (%s)", viewsID(views)))
 	commitTask.Set("confdb-transaction", tx)
 	// link all previous tasks to the commit task that carries the transaction
 	for _, t := range ts.Tasks() {
@@ -404,43 +461,74 @@ func createChangeConfdbTasks(st *state.State, tx *Transaction, view *confdb.View
 	return ts, nil
 }
 
+// viewsID returns the IDs of the views, for use in messages.
+func viewsID(views []*confdb.View) string {
+	ids := make([]string, 0, len(views))
+	for _, view := range views {
+		ids = append(ids, view.ID())
+	}
+	return strings.Join(ids, ", ")
+}
+
 // getCustodianPlugsForView returns a list of snaps that have connected plugs
 // declaring them as custodians of a confdb view. The list of custodians is
 // sorted. It also returns a map of the snap names to plugs.
 func getCustodianPlugsForView(st *state.State, view *confdb.View) ([]string, map[string]*snap.PlugInfo, error) {
+	custodians, plugs, err := getCustodianPlugsForViews(st, []*confdb.View{view})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	custodianPlugs := make(map[string]*snap.PlugInfo, len(plugs))
+	for name, snapPlugs := range plugs {
+		// TODO: if a snap has more than one plug providing access to a view, then
+		// which plug we're getting here becomes unpredictable. We should check
+		// for this at some point (interface connection?)
+		custodianPlugs[name] = snapPlugs[0]
+	}
+	return custodians, custodianPlugs, nil
+}
+
+// getCustodianPlugsForViews returns a list of snaps that have connected plugs
+// declaring them as custodians of any of the confdb views. The list of
+// custodians is sorted. It also returns a map of the snap names to their
+// custodian plugs, in the order of the views.
+func getCustodianPlugsForViews(st *state.State, views []*confdb.View) ([]string, map[string][]*snap.PlugInfo, error) {
 	repo := ifacerepo.Get(st)
 	plugs := repo.AllPlugs("confdb")
 
 	var custodians []string
-	custodianPlugs := make(map[string]*snap.PlugInfo)
-	for _, plug := range plugs {
-		conns, err := repo.Connected(plug.Snap.InstanceName(), plug.Name)
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(conns) == 0 {
-			continue
-		}
+	custodianPlugs := make(map[string][]*snap.PlugInfo)
+	for _, view := range views {
+		for _, plug := range plugs {
+			conns, err := repo.Connected(plug.Snap.InstanceName(), plug.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(conns) == 0 {
+				continue
+			}
 
-		if role, ok := plug.Attrs["role"]; !ok || role != "custodian" {
-			continue
-		}
+			if role, ok := plug.Attrs["role"]; !ok || role != "custodian" {
+				continue
+			}
 
-		account, dbSchemaName, viewName, err := snap.ConfdbPlugAttrs(plug)
-		if err != nil {
-			return nil, nil, err
-		}
+			account, dbSchemaName, viewName, err := snap.ConfdbPlugAttrs(plug)
+			if err != nil {
+				return nil, nil, err
+			}
 
-		if view.Schema().Account != account || view.Schema().Name != dbSchemaName ||
-			view.Name != viewName {
-			continue
-		}
+			if view.Schema().Account != account || view.Schema().Name != dbSchemaName ||
+				view.Name != viewName {
+				continue
+			}
 
-		// TODO: if a snap has more than one plug providing access to a view, then
-		// which plug we're getting here becomes unpredictable. We should check
-		// for this at some point (interface connection?)
-		custodianPlugs[plug.Snap.InstanceName()] = plug
-		custodians = append(custodians, plug.Snap.InstanceName())
+			name := plug.Snap.InstanceName()
+			if _, ok := custodianPlugs[name]; !ok {
+				custodians = append(custodians, name)
+			}
+			custodianPlugs[name] = append(custodianPlugs[name], plug)
+		}
 	}
 
 	// we want to process these in a deterministic order (useful for testing
@@ -489,6 +577,39 @@ func getPlugsAffectedByPaths(st *state.State, dbSchema *confdb.Schema, storagePa
 	return affectedPlugs, nil
 }
 
+// SnapViews returns the IDs of the views that the snap can access through its
+// connected confdb plugs. The state must be locked by the caller.
+func SnapViews(st *state.State, snapName string) ([]string, error) {
+	repo := ifacerepo.Get(st)
+
+	var viewIDs []string
+	for _, plug := range repo.Plugs(snapName) {
+		if plug.Interface != "confdb" {
+			continue
+		}
+
+		conns, err := repo.Connected(snapName, plug.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(conns) == 0 {
+			continue
+		}
+
+		account, dbSchemaName, viewName, err := snap.ConfdbPlugAttrs(plug)
+		if err != nil {
+			return nil, err
+		}
+
+		viewID := fmt.Sprintf("%s/%s/%s", account, dbSchemaName, viewName)
+		if !strutil.ListContains(viewIDs, viewID) {
+			viewIDs = append(viewIDs, viewID)
+		}
+	}
+
+	return viewIDs, nil
+}
+
 // GetStoredTransaction returns the transaction associated with the task
 // (even if indirectly) and a callback to persist changes made to it.
 func GetStoredTransaction(t *state.Task) (tx *Transaction, txTask *state.Task, saveTxChanges func(), err error) {
@@ -535,16 +656,18 @@ func IsConfdbHookname(name string) bool {
 		strings.HasPrefix(name, "save-view-") ||
 		strings.HasPrefix(name, "load-view-") ||
 		strings.HasPrefix(name, "query-view-") ||
-		strings.HasPrefix(name, "observe-view-")
+		strings.HasPrefix(name, "observe-view-") ||
+		strings.HasPrefix(name, "migrate-view-")
 }
 
 // CanHookSetConfdb returns whether the hook context belongs to a confdb hook
-// that supports snapctl set (either a write hook or load-view).
+// that supports snapctl set (either a write hook, load-view or migrate-view).
 func CanHookSetConfdb(ctx *hookstate.Context) bool {
 	return ctx != nil && !ctx.IsEphemeral() &&
 		(strings.HasPrefix(ctx.HookName(), "change-view-") ||
 			strings.HasPrefix(ctx.HookName(), "query-view-") ||
-			strings.HasPrefix(ctx.HookName(), "load-view-"))
+			strings.HasPrefix(ctx.HookName(), "load-view-") ||
+			strings.HasPrefix(ctx.HookName(), "migrate-view-"))
 }
 
 // GetTransactionForSnapctlGet gets a transaction to read the view's confdb. It
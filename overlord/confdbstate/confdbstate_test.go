@@ -58,8 +58,9 @@ type confdbTestSuite struct {
 	state *state.State
 	o     *overlord.Overlord
 
-	dbSchema *confdb.Schema
-	devAccID string
+	dbSchema   *confdb.Schema
+	devAccID   string
+	devSigning *assertstest.SigningDB
 
 	repo *interfaces.Repository
 }
@@ -96,6 +97,9 @@ func (s *confdbTestSuite) SetUpTest(c *C) {
 	c.Assert(db.Add(storeSigning.StoreAccountKey("")), IsNil)
 	assertstate.ReplaceDB(s.state, db)
 
+	s.repo = interfaces.NewRepository()
+	ifacerepo.Replace(s.state, s.repo)
+
 	// add developer1's account and account-key assertions
 	devAcc := assertstest.NewAccount(storeSigning, "developer1", nil, "")
 	c.Assert(storeSigning.Add(devAcc), IsNil)
@@ -157,6 +161,7 @@ func (s *confdbTestSuite) SetUpTest(c *C) {
 	c.Assert(assertstate.Add(s.state, as), IsNil)
 
 	s.devAccID = devAccKey.AccountID()
+	s.devSigning = signingDB
 	s.dbSchema = as.(*asserts.ConfdbSchema).Schema()
 
 	tr := config.NewTransaction(s.state)
@@ -857,6 +862,28 @@ func findTask(chg *state.Change, kind string) *state.Task {
 	return nil
 }
 
+func (s *confdbTestSuite) TestSnapViews(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupConfdbScenario(c, nil, []string{"test-snap"})
+
+	views, err := confdbstate.SnapViews(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(views, DeepEquals, []string{s.devAccID + "/network/setup-wifi"})
+
+	// views of disconnected plugs are not accessible
+	c.Assert(s.repo.Disconnect("test-snap", "setup", "core", "confdb-slot"), IsNil)
+
+	views, err = confdbstate.SnapViews(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(views, HasLen, 0)
+
+	views, err = confdbstate.SnapViews(s.state, "other-snap")
+	c.Assert(err, IsNil)
+	c.Check(views, HasLen, 0)
+}
+
 func (s *confdbTestSuite) TestGetStoredTransaction(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -0,0 +1,227 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var importConfdbChangeKind = swfeats.RegisterChangeKind("import-confdb")
+
+// InvalidDataError is returned when data imported into a confdb doesn't
+// conform to its confdb-schema.
+type InvalidDataError struct {
+	Account    string
+	SchemaName string
+	Err        error
+}
+
+func (e *InvalidDataError) Is(err error) bool {
+	_, ok := err.(*InvalidDataError)
+	return ok
+}
+
+func (e *InvalidDataError) Error() string {
+	return fmt.Sprintf("cannot import data into confdb %s/%s: %v", e.Account, e.SchemaName, e.Err)
+}
+
+func (e *InvalidDataError) Unwrap() error {
+	return e.Err
+}
+
+// Export returns all the data stored in the confdb. Secret values are
+// returned encrypted with the device's key, so they can only be imported back
+// into the same device.
+func Export(st *state.State, account, schemaName string) (map[string]any, error) {
+	data, err := exportData(st, account, schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := secretsSchema(st, account, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		return transformSecretsInData(st, schema, data, sealValue)
+	}
+	return data, nil
+}
+
+// exportData returns all the data stored in the confdb, including the values
+// of secrets.
+func exportData(st *state.State, account, schemaName string) (map[string]any, error) {
+	// check that the confdb-schema exists
+	if _, err := assertstateConfdbSchema(st, account, schemaName); err != nil {
+		return nil, err
+	}
+
+	bag, err := readDatabag(st, account, schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := bag.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Import schedules a change which replaces all the data stored in the confdb
+// with the provided data. The change goes through the same pipeline as a
+// write through the views affected by the changed data, so the custodians'
+// change-view and save-view hooks are run and the result is validated before
+// being committed. Secret values can be provided encrypted, as returned by
+// Export. If the data is unchanged, the returned change is already done.
+func Import(st *state.State, account, schemaName string, data map[string]any, author string) (changeID string, err error) {
+	confdbSchemaAs, err := assertstateConfdbSchema(st, account, schemaName)
+	if err != nil {
+		return "", err
+	}
+	dbSchema := confdbSchemaAs.Schema()
+
+	if dbSchema.DatabagSchema.NestedVisibility(confdb.SecretVisibility) {
+		data, err = transformSecretsInData(st, dbSchema.DatabagSchema, data, unsealValue)
+		if err != nil {
+			return "", &InvalidDataError{Account: account, SchemaName: schemaName, Err: err}
+		}
+	}
+
+	// fail early instead of waiting for the commit to validate the data
+	if len(data) != 0 {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return "", &InvalidDataError{Account: account, SchemaName: schemaName, Err: err}
+		}
+
+		if err := dbSchema.DatabagSchema.Validate(raw); err != nil {
+			return "", &InvalidDataError{Account: account, SchemaName: schemaName, Err: err}
+		}
+	}
+
+	txs, _, err := getOngoingTxs(st, account, schemaName)
+	if err != nil {
+		return "", fmt.Errorf("cannot import data into confdb %s/%s: cannot check ongoing transactions: %v", account, schemaName, err)
+	}
+
+	if txs != nil && !txs.CanStartWriteTx() {
+		return "", fmt.Errorf("cannot import data into confdb %s/%s: ongoing transaction", account, schemaName)
+	}
+
+	current, err := exportData(st, account, schemaName)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := NewTransaction(st, account, schemaName)
+	if err != nil {
+		return "", fmt.Errorf("cannot import data into confdb %s/%s: cannot create transaction: %v", account, schemaName, err)
+	}
+	tx.Author = author
+
+	keys := make([]string, 0, len(current)+len(data))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range data {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changed bool
+	affected := make(map[string]bool)
+	for _, key := range keys {
+		oldValue, newValue := current[key], data[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changed = true
+
+		path, err := confdb.ParsePathIntoAccessors(key, confdb.ParseOptions{})
+		if err != nil {
+			return "", &InvalidDataError{Account: account, SchemaName: schemaName, Err: err}
+		}
+
+		if newValue == nil {
+			err = tx.Unset(path)
+		} else {
+			err = tx.Set(path, newValue)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		for _, view := range dbSchema.GetViewsAffectedByPath(path) {
+			affected[view.Name] = true
+		}
+	}
+
+	summary := fmt.Sprintf("Import data into confdb %s/%s", account, schemaName)
+	if !changed {
+		// nothing to do, importing the same data again is fine
+		chg := st.NewChange(importConfdbChangeKind, summary)
+		chg.SetStatus(state.DoneStatus)
+		return chg.ID(), nil
+	}
+
+	if len(affected) == 0 {
+		return "", fmt.Errorf("cannot import data into confdb %s/%s: no view is affected by the changes", account, schemaName)
+	}
+
+	var views []*confdb.View
+	for _, view := range dbSchema.Views() {
+		if affected[view.Name] {
+			views = append(views, view)
+		}
+	}
+
+	ts, err := createCommitConfdbTasks(st, tx, views, "")
+	if err != nil {
+		return "", err
+	}
+
+	commitTask, err := ts.Edge(commitEdge)
+	if err != nil {
+		return "", err
+	}
+
+	if err := setWriteTransaction(st, account, schemaName, commitTask.ID()); err != nil {
+		return "", err
+	}
+
+	chg := st.NewChange(importConfdbChangeKind, summary)
+	chg.AddAll(ts)
+	return chg.ID(), nil
+}
@@ -0,0 +1,189 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/overlord/confdbstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *confdbTestSuite) TestExport(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	data, err := confdbstate.Export(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, map[string]any{})
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "private.count"), 2), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	data, err = confdbstate.Export(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, map[string]any{
+		"wifi":    map[string]any{"ssid": "foo"},
+		"private": map[string]any{"count": float64(2)},
+	})
+}
+
+func (s *confdbTestSuite) TestExportNoSchema(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := confdbstate.Export(s.state, s.devAccID, "other")
+	c.Assert(err, testutil.ErrorIs, &asserts.NotFoundError{})
+}
+
+func (s *confdbTestSuite) TestImport(c *C) {
+	hooks, restore := s.mockConfdbHooks()
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	custodians := map[string]confdbHooks{"custodian-snap": allHooks}
+	s.setupConfdbScenario(c, custodians, nil)
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo", "private.a": "b"})
+
+	chgID, err := confdbstate.Import(s.state, s.devAccID, "network", map[string]any{
+		"wifi": map[string]any{"ssid": "bar", "psk": "secret"},
+	}, "uid:0")
+	c.Assert(err, IsNil)
+	s.checkOngoingWriteConfdbTx(c, s.devAccID, "network")
+
+	chg := s.state.Change(chgID)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "import-confdb")
+	c.Check(chg.Summary(), Equals, "Import data into confdb "+s.devAccID+"/network")
+
+	s.state.Unlock()
+	err = s.o.Settle(testutil.HostScaledTimeout(5 * time.Second))
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(chg.Status(), Equals, state.DoneStatus)
+
+	// the custodian got to see and save the changes
+	c.Check(*hooks, DeepEquals, []string{"change-view-setup", "save-view-setup", "observe-view-setup"})
+
+	data, err := confdbstate.Export(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, map[string]any{
+		"wifi": map[string]any{"ssid": "bar", "psk": "secret"},
+	})
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Check(history[1].Author, Equals, "uid:0")
+	c.Check(history[1].ChangeID, Equals, chgID)
+}
+
+func (s *confdbTestSuite) TestImportInvalidData(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := confdbstate.Import(s.state, s.devAccID, "network", map[string]any{
+		"wifi": map[string]any{"ssid": 1},
+	}, "")
+	c.Assert(err, testutil.ErrorIs, &confdbstate.InvalidDataError{})
+	c.Assert(err, ErrorMatches, `cannot import data into confdb .*/network: .*`)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *confdbTestSuite) TestImportUnchanged(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+
+	chgID, err := confdbstate.Import(s.state, s.devAccID, "network", map[string]any{
+		"wifi": map[string]any{"ssid": "foo"},
+	}, "")
+	c.Assert(err, IsNil)
+
+	// there's nothing to do
+	chg := s.state.Change(chgID)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "import-confdb")
+	c.Check(chg.Tasks(), HasLen, 0)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 1)
+}
+
+func (s *confdbTestSuite) TestExportImportSecrets(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	// secrets are exported encrypted
+	data, err := confdbstate.Export(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	wifi := data["wifi"].(map[string]any)
+	c.Check(wifi["ssid"], Equals, "foo")
+	psk, ok := wifi["psk"].(string)
+	c.Assert(ok, Equals, true)
+	c.Check(strings.HasPrefix(psk, "sealed:v1:"), Equals, true)
+	c.Check(psk, Not(testutil.Contains), "secret")
+
+	// and can be imported back as they are
+	chgID, err := confdbstate.Import(s.state, s.devAccID, "network", data, "")
+	c.Assert(err, IsNil)
+	c.Check(s.state.Change(chgID).Status(), Equals, state.DoneStatus)
+
+	// but not if they were encrypted with another key
+	wifi["psk"] = "sealed:v1:" + base64.StdEncoding.EncodeToString(make([]byte, 64))
+	_, err = confdbstate.Import(s.state, s.devAccID, "network", data, "")
+	c.Assert(err, testutil.ErrorIs, &confdbstate.InvalidDataError{})
+	c.Assert(err, ErrorMatches, `cannot import data into confdb .*/network: cannot decrypt confdb secret: .*`)
+}
+
+func (s *confdbTestSuite) TestImportOngoingTransaction(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := confdbstate.SetWriteTransaction(s.state, s.devAccID, "network", "123")
+	c.Assert(err, IsNil)
+
+	_, err = confdbstate.Import(s.state, s.devAccID, "network", map[string]any{
+		"wifi": map[string]any{"ssid": "foo"},
+	}, "")
+	c.Assert(err, ErrorMatches, `cannot import data into confdb .*/network: ongoing transaction`)
+}
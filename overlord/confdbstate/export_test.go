@@ -35,8 +35,11 @@ var (
 	SetWriteTransaction     = setWriteTransaction
 	AddReadTransaction      = addReadTransaction
 	UnsetOngoingTransaction = unsetOngoingTransaction
+	PrepareSchemaUpdate     = prepareSchemaUpdate
 )
 
+type SecretsKeyKey = secretsKeyKey
+
 type (
 	ConfdbTransactions = confdbTransactions
 )
@@ -85,3 +88,19 @@ func MockTransactionTimeout(dur time.Duration) func() {
 		transactionTimeout = old
 	}
 }
+
+func MockMaxHistoryEntries(n int) func() {
+	old := maxHistoryEntries
+	maxHistoryEntries = n
+	return func() {
+		maxHistoryEntries = old
+	}
+}
+
+func MockTimeNow(f func() time.Time) func() {
+	old := timeNow
+	timeNow = f
+	return func() {
+		timeNow = old
+	}
+}
@@ -0,0 +1,354 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var rollbackConfdbChangeKind = swfeats.RegisterChangeKind("rollback-confdb")
+
+// maxHistoryEntries is the number of committed transactions kept in the
+// history of each confdb.
+var maxHistoryEntries = 32
+
+// HistoryEntry records a transaction committed to a confdb.
+type HistoryEntry struct {
+	// ID identifies the transaction within the history of the confdb. IDs
+	// are monotonically increasing.
+	ID int `json:"id"`
+	// Time is when the transaction was committed.
+	Time time.Time `json:"time"`
+	// Author is the snap or user who made the changes, if known.
+	Author string `json:"author,omitempty"`
+	// ChangeID is the ID of the change which committed the transaction.
+	ChangeID string `json:"change-id,omitempty"`
+	// Migration is the revision of the confdb-schema the data was migrated
+	// to, if the entry records a migration rather than a transaction.
+	Migration int `json:"migration,omitempty"`
+	// Diff holds the storage paths altered by the transaction, with the
+	// values they held before and after it was committed.
+	Diff []HistoryDelta `json:"diff"`
+}
+
+// HistoryDelta holds the value of a storage path before and after a
+// transaction was committed. A nil value means the path had no data.
+type HistoryDelta struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// TransactionNotFoundError is returned when a transaction isn't (or is no
+// longer) in the history of a confdb.
+type TransactionNotFoundError struct {
+	Account    string
+	SchemaName string
+	ID         int
+}
+
+func (e *TransactionNotFoundError) Is(err error) bool {
+	_, ok := err.(*TransactionNotFoundError)
+	return ok
+}
+
+func (e *TransactionNotFoundError) Error() string {
+	return fmt.Sprintf("cannot find transaction %d in the history of confdb %s/%s", e.ID, e.Account, e.SchemaName)
+}
+
+// readHistory returns the history of committed transactions for the confdb,
+// oldest first, and a function to save a modified history in the state.
+func readHistory(st *state.State, account, schemaName string) ([]*HistoryEntry, func([]*HistoryEntry), error) {
+	var histories map[string][]*HistoryEntry
+	if err := st.Get("confdb-history", &histories); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return nil, nil, err
+		}
+		histories = make(map[string][]*HistoryEntry, 1)
+	}
+
+	ref := account + "/" + schemaName
+	save := func(history []*HistoryEntry) {
+		if len(history) == 0 {
+			delete(histories, ref)
+		} else {
+			histories[ref] = history
+		}
+		st.Set("confdb-history", histories)
+	}
+	return histories[ref], save, nil
+}
+
+// History returns the history of transactions committed to the confdb,
+// oldest first. Only the last transactions are kept.
+func History(st *state.State, account, schemaName string) ([]*HistoryEntry, error) {
+	history, _, err := readHistory(st, account, schemaName)
+	return history, err
+}
+
+// ViewHistory returns the history of transactions committed to the view's
+// confdb, oldest first, restricted to the changes to storage paths which are
+// visible through the view.
+func ViewHistory(st *state.State, view *confdb.View) ([]*HistoryEntry, error) {
+	history, err := History(st, view.Schema().Account, view.Schema().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*HistoryEntry
+	for _, entry := range history {
+		var diff []HistoryDelta
+		for _, delta := range entry.Diff {
+			path, err := confdb.ParsePathIntoAccessors(delta.Path, confdb.ParseOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("internal error: cannot parse path %q: %v", delta.Path, err)
+			}
+
+			for _, affected := range view.Schema().GetViewsAffectedByPath(path) {
+				if affected.Name != view.Name {
+					continue
+				}
+
+				// secrets are never shown in the history
+				schema := view.Schema().DatabagSchema
+				if delta.Old, err = redactHistoryValue(schema, path, delta.Old); err != nil {
+					return nil, err
+				}
+				if delta.New, err = redactHistoryValue(schema, path, delta.New); err != nil {
+					return nil, err
+				}
+
+				diff = append(diff, delta)
+				break
+			}
+		}
+
+		if len(diff) == 0 {
+			continue
+		}
+
+		viewEntry := *entry
+		viewEntry.Diff = diff
+		filtered = append(filtered, &viewEntry)
+	}
+	return filtered, nil
+}
+
+// redactHistoryValue redacts the secrets in a value recorded in the history.
+// Since the history is kept across migrations, the path may no longer be in
+// the schema in which case, if the schema has secrets, the whole value is
+// redacted to be safe.
+func redactHistoryValue(schema confdb.DatabagSchema, path []confdb.Accessor, value any) (any, error) {
+	if value == nil || !schema.NestedVisibility(confdb.SecretVisibility) {
+		return value, nil
+	}
+
+	if _, err := schema.SchemaAt(path); err != nil {
+		return confdb.RedactedValue, nil
+	}
+	return confdb.RedactSecrets(schema, path, value)
+}
+
+// diffDatabags returns the values of the paths in the databags before and
+// after a transaction was committed.
+func diffDatabags(before, after confdb.JSONDatabag, paths [][]confdb.Accessor) ([]HistoryDelta, error) {
+	seen := make(map[string]bool, len(paths))
+	diff := make([]HistoryDelta, 0, len(paths))
+	for _, path := range paths {
+		pathStr := confdb.JoinAccessors(path)
+		if seen[pathStr] {
+			continue
+		}
+		seen[pathStr] = true
+
+		oldValue, err := before.Get(path, nil)
+		if err != nil && !errors.Is(err, &confdb.NoDataError{}) {
+			return nil, err
+		}
+
+		newValue, err := after.Get(path, nil)
+		if err != nil && !errors.Is(err, &confdb.NoDataError{}) {
+			return nil, err
+		}
+
+		if reflect.DeepEqual(oldValue, newValue) {
+			// the path was written but its value didn't change
+			continue
+		}
+
+		diff = append(diff, HistoryDelta{Path: pathStr, Old: oldValue, New: newValue})
+	}
+	return diff, nil
+}
+
+// recordHistory adds an entry for a committed transaction to the history of
+// the confdb, dropping the oldest entries if the history is full. It returns
+// the ID of the new entry.
+func recordHistory(st *state.State, tx *Transaction, changeID string, diff []HistoryDelta) (int, error) {
+	history, save, err := readHistory(st, tx.ConfdbAccount, tx.ConfdbName)
+	if err != nil {
+		return 0, err
+	}
+
+	entry := &HistoryEntry{
+		Time:     timeNow(),
+		Author:   tx.Author,
+		ChangeID: changeID,
+		Diff:     diff,
+	}
+	save(appendHistoryEntry(history, entry))
+	return entry.ID, nil
+}
+
+// appendHistoryEntry assigns the next ID to the entry and adds it to the
+// history, dropping the oldest entries if the history is full.
+func appendHistoryEntry(history []*HistoryEntry, entry *HistoryEntry) []*HistoryEntry {
+	entry.ID = 1
+	if len(history) > 0 {
+		entry.ID = history[len(history)-1].ID + 1
+	}
+
+	history = append(history, entry)
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+	return history
+}
+
+var timeNow = time.Now
+
+// Rollback schedules a change which reverts the changes made to the confdb by
+// the transaction with the given ID and by all transactions committed after
+// it. The change goes through the same pipeline as a write through the views
+// affected by the reverted changes, so the custodians' change-view and
+// save-view hooks are run and the result is validated before being committed.
+func Rollback(st *state.State, account, schemaName string, txID int, author string) (changeID string, err error) {
+	history, err := History(st, account, schemaName)
+	if err != nil {
+		return "", err
+	}
+
+	idx := sort.Search(len(history), func(i int) bool { return history[i].ID >= txID })
+	if idx == len(history) || history[idx].ID != txID {
+		return "", &TransactionNotFoundError{Account: account, SchemaName: schemaName, ID: txID}
+	}
+
+	// the data was reshaped for a newer confdb-schema since, so it can't be
+	// restored from the recorded values
+	for _, entry := range history[idx:] {
+		if entry.Migration != 0 {
+			return "", fmt.Errorf("cannot rollback confdb %s/%s: data was migrated to revision %d of the confdb-schema by transaction %d", account, schemaName, entry.Migration, entry.ID)
+		}
+	}
+
+	confdbSchemaAs, err := assertstateConfdbSchema(st, account, schemaName)
+	if err != nil {
+		return "", err
+	}
+	dbSchema := confdbSchemaAs.Schema()
+
+	txs, _, err := getOngoingTxs(st, account, schemaName)
+	if err != nil {
+		return "", fmt.Errorf("cannot rollback confdb %s/%s: cannot check ongoing transactions: %v", account, schemaName, err)
+	}
+
+	if txs != nil && !txs.CanStartWriteTx() {
+		return "", fmt.Errorf("cannot rollback confdb %s/%s: ongoing transaction", account, schemaName)
+	}
+
+	tx, err := NewTransaction(st, account, schemaName)
+	if err != nil {
+		return "", fmt.Errorf("cannot rollback confdb %s/%s: cannot create transaction: %v", account, schemaName, err)
+	}
+	tx.Author = author
+
+	// undo the transactions from the most recent one, so each path ends up
+	// with the value it had before the rolled back transaction
+	affected := make(map[string]*confdb.View)
+	for i := len(history) - 1; i >= idx; i-- {
+		diff := history[i].Diff
+		if dbSchema.DatabagSchema.NestedVisibility(confdb.SecretVisibility) {
+			diff, err = transformSecretsInHistory(st, dbSchema.DatabagSchema, diff, unsealValue)
+			if err != nil {
+				return "", fmt.Errorf("cannot rollback confdb %s/%s: %v", account, schemaName, err)
+			}
+		}
+
+		for _, delta := range diff {
+			path, err := confdb.ParsePathIntoAccessors(delta.Path, confdb.ParseOptions{})
+			if err != nil {
+				return "", fmt.Errorf("internal error: cannot parse path %q: %v", delta.Path, err)
+			}
+
+			if delta.Old == nil {
+				err = tx.Unset(path)
+			} else {
+				err = tx.Set(path, delta.Old)
+			}
+			if err != nil {
+				return "", err
+			}
+
+			for _, view := range dbSchema.GetViewsAffectedByPath(path) {
+				affected[view.Name] = view
+			}
+		}
+	}
+
+	if len(affected) == 0 {
+		return "", fmt.Errorf("cannot rollback confdb %s/%s: no view is affected by transaction %d", account, schemaName, txID)
+	}
+
+	viewNames := make([]string, 0, len(affected))
+	for name := range affected {
+		viewNames = append(viewNames, name)
+	}
+	sort.Strings(viewNames)
+	views := make([]*confdb.View, 0, len(viewNames))
+	for _, name := range viewNames {
+		views = append(views, affected[name])
+	}
+
+	ts, err := createCommitConfdbTasks(st, tx, views, "")
+	if err != nil {
+		return "", err
+	}
+
+	commitTask, err := ts.Edge(commitEdge)
+	if err != nil {
+		return "", err
+	}
+
+	if err := setWriteTransaction(st, account, schemaName, commitTask.ID()); err != nil {
+		return "", err
+	}
+
+	chg := st.NewChange(rollbackConfdbChangeKind, fmt.Sprintf("Rollback confdb %s/%s to before transaction %d", account, schemaName, txID))
+	chg.AddAll(ts)
+
+	return chg.ID(), nil
+}
@@ -0,0 +1,252 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate_test
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/overlord/confdbstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+// commitTx commits a transaction with the given changes through a
+// commit-confdb-tx task and returns the task's change. The state must be
+// locked.
+func (s *confdbTestSuite) commitTx(c *C, author string, values map[string]any) *state.Change {
+	chg := s.state.NewChange("test", "")
+	t := s.state.NewTask("commit-confdb-tx", "")
+	chg.AddTask(t)
+
+	tx, err := confdbstate.NewTransaction(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	tx.Author = author
+
+	// write in a consistent order so the recorded diffs are predictable
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		value := values[path]
+		if value == nil {
+			err = tx.Unset(parsePath(c, path))
+		} else {
+			err = tx.Set(parsePath(c, path), value)
+		}
+		c.Assert(err, IsNil)
+	}
+	setTransaction(t, tx)
+
+	s.state.Unlock()
+	err = s.o.Settle(testutil.HostScaledTimeout(5 * time.Second))
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(t.Status(), Equals, state.DoneStatus, Commentf(strings.Join(t.Log(), "\n")))
+	return chg
+}
+
+func (s *confdbTestSuite) TestCommitRecordsHistory(c *C) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	defer confdbstate.MockTimeNow(func() time.Time { return now })()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chg1 := s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo", "wifi.psk": "secret"})
+	chg2 := s.commitTx(c, "uid:1000", map[string]any{"wifi.ssid": "bar", "wifi.psk": nil})
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+
+	c.Check(history[0].ID, Equals, 1)
+	c.Check(history[0].Time.Equal(now), Equals, true)
+	c.Check(history[0].Author, Equals, "test-snap")
+	c.Check(history[0].ChangeID, Equals, chg1.ID())
+	c.Check(history[0].Diff, testutil.DeepUnsortedMatches, []confdbstate.HistoryDelta{
+		{Path: "wifi.ssid", New: "foo"},
+		{Path: "wifi.psk", New: "secret"},
+	})
+
+	c.Check(history[1].ID, Equals, 2)
+	c.Check(history[1].Author, Equals, "uid:1000")
+	c.Check(history[1].ChangeID, Equals, chg2.ID())
+	c.Check(history[1].Diff, testutil.DeepUnsortedMatches, []confdbstate.HistoryDelta{
+		{Path: "wifi.ssid", Old: "foo", New: "bar"},
+		{Path: "wifi.psk", Old: "secret"},
+	})
+}
+
+func (s *confdbTestSuite) TestCommitNoChangesNotRecorded(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.commitTx(c, "test-snap", nil)
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 0)
+}
+
+func (s *confdbTestSuite) TestHistoryIsBounded(c *C) {
+	defer confdbstate.MockMaxHistoryEntries(2)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, ssid := range []string{"one", "two", "three"} {
+		s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": ssid})
+	}
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Check(history[0].ID, Equals, 2)
+	c.Check(history[0].Diff, DeepEquals, []confdbstate.HistoryDelta{{Path: "wifi.ssid", Old: "one", New: "two"}})
+	c.Check(history[1].ID, Equals, 3)
+	c.Check(history[1].Diff, DeepEquals, []confdbstate.HistoryDelta{{Path: "wifi.ssid", Old: "two", New: "three"}})
+}
+
+func (s *confdbTestSuite) TestViewHistory(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("confdb-history", map[string][]*confdbstate.HistoryEntry{
+		s.devAccID + "/network": {
+			{ID: 1, Author: "test-snap", Diff: []confdbstate.HistoryDelta{
+				{Path: "wifi.ssid", New: "foo"},
+				{Path: "other.thing", New: "bar"},
+			}},
+			{ID: 2, Author: "test-snap", Diff: []confdbstate.HistoryDelta{
+				{Path: "other.thing", Old: "bar"},
+			}},
+		},
+	})
+
+	view := s.dbSchema.View("setup-wifi")
+	history, err := confdbstate.ViewHistory(s.state, view)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Check(history[0].ID, Equals, 1)
+	c.Check(history[0].Diff, DeepEquals, []confdbstate.HistoryDelta{{Path: "wifi.ssid", New: "foo"}})
+
+	// the stored history is not modified
+	full, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(full[0].Diff, HasLen, 2)
+}
+
+func (s *confdbTestSuite) TestRollback(c *C) {
+	hooks, restore := s.mockConfdbHooks()
+	defer restore()
+	defer confdbstate.MockEnsureNow(func(*state.State) {})()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	custodians := map[string]confdbHooks{"custodian-snap": allHooks}
+	s.setupConfdbScenario(c, custodians, nil)
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "bar", "wifi.psk": "secret"})
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "baz"})
+
+	// undo the last two transactions
+	chgID, err := confdbstate.Rollback(s.state, s.devAccID, "network", 2, "uid:0")
+	c.Assert(err, IsNil)
+	s.checkOngoingWriteConfdbTx(c, s.devAccID, "network")
+
+	chg := s.state.Change(chgID)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "rollback-confdb")
+	c.Check(chg.Summary(), Equals, "Rollback confdb "+s.devAccID+"/network to before transaction 2")
+
+	s.state.Unlock()
+	err = s.o.Settle(testutil.HostScaledTimeout(5 * time.Second))
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(chg.Status(), Equals, state.DoneStatus)
+
+	// the custodian got to see and save the changes
+	c.Check(*hooks, DeepEquals, []string{"change-view-setup", "save-view-setup", "observe-view-setup"})
+
+	bag, err := confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	val, err := bag.Get(parsePath(c, "wifi.ssid"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "foo")
+	_, err = bag.Get(parsePath(c, "wifi.psk"), nil)
+	c.Check(err, testutil.ErrorIs, &confdb.NoDataError{})
+
+	// the rollback is recorded in the history like any other transaction
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 4)
+	c.Check(history[3].ID, Equals, 4)
+	c.Check(history[3].Author, Equals, "uid:0")
+	c.Check(history[3].ChangeID, Equals, chgID)
+	c.Check(history[3].Diff, testutil.DeepUnsortedMatches, []confdbstate.HistoryDelta{
+		{Path: "wifi.ssid", Old: "baz", New: "foo"},
+		{Path: "wifi.psk", Old: "secret"},
+	})
+}
+
+func (s *confdbTestSuite) TestRollbackNotFound(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+
+	_, err := confdbstate.Rollback(s.state, s.devAccID, "network", 2, "")
+	c.Assert(err, testutil.ErrorIs, &confdbstate.TransactionNotFoundError{})
+	c.Assert(err, ErrorMatches, `cannot find transaction 2 in the history of confdb .*/network`)
+}
+
+func (s *confdbTestSuite) TestRollbackOngoingTransaction(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+
+	err := confdbstate.SetWriteTransaction(s.state, s.devAccID, "network", "123")
+	c.Assert(err, IsNil)
+
+	_, err = confdbstate.Rollback(s.state, s.devAccID, "network", 1, "")
+	c.Assert(err, ErrorMatches, `cannot rollback confdb .*/network: ongoing transaction`)
+}
+
+func (s *confdbTestSuite) TestRollbackNoCustodian(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupConfdbScenario(c, nil, nil)
+	s.commitTx(c, "test-snap", map[string]any{"wifi.ssid": "foo"})
+
+	_, err := confdbstate.Rollback(s.state, s.devAccID, "network", 1, "")
+	c.Assert(err, ErrorMatches, `cannot commit changes to confdb made through view .*/network/setup-wifi: no custodian snap installed`)
+	c.Check(s.state.Changes(), HasLen, 1)
+}
@@ -0,0 +1,378 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/overlord/swfeats"
+)
+
+var migrateConfdbChangeKind = swfeats.RegisterChangeKind("migrate-confdb")
+
+// schemaMigration migrates the data of a confdb to a new revision of its
+// confdb-schema. The migrations declared in the assertion are applied along
+// with it, then the custodians' migrate-view hooks can make further changes
+// in a transaction which is validated and committed like any other.
+type schemaMigration struct {
+	st         *state.State
+	account    string
+	schemaName string
+	revision   int
+
+	// bag is the migrated databag, in plain text
+	bag confdb.JSONDatabag
+	// migrated is the migrated databag, with secrets encrypted according to
+	// the new revision, or nil if there are no migrations to apply
+	migrated confdb.JSONDatabag
+	// diff records the migration in the history of the confdb
+	diff []HistoryDelta
+	// hooks are the custodians' migrate-view hooks, by snap
+	hooks []migrationHook
+	// secrets encrypts the transaction of the hooks, if needed
+	secrets cipher.AEAD
+
+	// saved when applied so it can be reverted
+	applied     bool
+	prevBag     confdb.JSONDatabag
+	prevHistory []*HistoryEntry
+	hooksTs     *state.TaskSet
+	hooksTxID   string
+}
+
+type migrationHook struct {
+	snap string
+	hook string
+}
+
+// prepareSchemaUpdate checks that the data stored in the confdb can be
+// migrated to the updated confdb-schema and still be valid. It returns the
+// migration to apply along with the new revision, if there is anything to
+// migrate. Everything that can fail is done here or when the migration is
+// applied, before the new revision is added.
+func prepareSchemaUpdate(st *state.State, current, update *asserts.ConfdbSchema) (assertstate.ConfdbSchemaMigration, error) {
+	account, schemaName := update.AccountID(), update.Name()
+
+	txs, _, err := getOngoingTxs(st, account, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	if txs != nil && (txs.WriteTxID != "" || len(txs.ReadTxIDs) != 0) {
+		return nil, fmt.Errorf("cannot migrate confdb while it is being accessed")
+	}
+
+	original, err := readDatabag(st, account, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	bag := original.Copy()
+
+	migrations := update.MigrationsSince(current.Revision())
+	if err := confdb.ApplyMigrations(bag, migrations); err != nil {
+		return nil, err
+	}
+
+	data, err := bag.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	updateSchema := update.Schema().DatabagSchema
+	// an empty confdb is always valid, regardless of the schema
+	if len(bag) != 0 {
+		if err := updateSchema.Validate(data); err != nil {
+			return nil, fmt.Errorf("migrated data is invalid: %w", err)
+		}
+	}
+
+	hooks, err := migrationHooks(st, update.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(migrations) == 0 && len(hooks) == 0 {
+		return nil, nil
+	}
+
+	m := &schemaMigration{
+		st:         st,
+		account:    account,
+		schemaName: schemaName,
+		revision:   update.Revision(),
+		bag:        bag,
+		hooks:      hooks,
+	}
+
+	if len(migrations) != 0 {
+		m.diff, err = diffDatabags(original, bag, migratedPaths(migrations))
+		if err != nil {
+			return nil, fmt.Errorf("cannot record confdb history: %v", err)
+		}
+
+		m.migrated = bag
+		if updateSchema.NestedVisibility(confdb.SecretVisibility) {
+			m.migrated, err = transformSecretsInDatabag(st, updateSchema, bag, sealValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		m.diff, err = sealMigrationDiff(st, current.Schema().DatabagSchema, updateSchema, m.diff)
+		if err != nil {
+			return nil, fmt.Errorf("cannot record confdb history: %v", err)
+		}
+	}
+
+	if len(hooks) != 0 && updateSchema.NestedVisibility(confdb.SecretVisibility) {
+		m.secrets, err = secretsCipher(st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// migrationHooks returns the migrate-view hooks of the custodians of the
+// confdb's views.
+func migrationHooks(st *state.State, dbSchema *confdb.Schema) ([]migrationHook, error) {
+	custodians, custodianPlugs, err := getCustodianPlugsForViews(st, dbSchema.Views())
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []migrationHook
+	for _, name := range custodians {
+		seen := make(map[string]bool)
+		for _, plug := range custodianPlugs[name] {
+			hookName := "migrate-view-" + plug.Name
+			if _, ok := plug.Snap.Hooks[hookName]; !ok || seen[hookName] {
+				continue
+			}
+			seen[hookName] = true
+			hooks = append(hooks, migrationHook{snap: name, hook: hookName})
+		}
+	}
+	return hooks, nil
+}
+
+// migratedPaths returns the storage paths read or written by the migrations.
+func migratedPaths(migrations []confdb.Migration) [][]confdb.Accessor {
+	var paths [][]confdb.Accessor
+	for _, migration := range migrations {
+		for _, rule := range migration.Rules {
+			paths = append(paths, rule.From)
+			if rule.To != nil {
+				paths = append(paths, rule.To)
+			}
+		}
+	}
+	return paths
+}
+
+// sealMigrationDiff encrypts the secrets in the history of a migration. The
+// old values are secret according to the current revision and the new ones
+// according to the updated revision. Values at paths which aren't in a schema
+// with secrets are encrypted as a whole, to be safe.
+func sealMigrationDiff(st *state.State, current, update confdb.DatabagSchema, diff []HistoryDelta) ([]HistoryDelta, error) {
+	if !current.NestedVisibility(confdb.SecretVisibility) && !update.NestedVisibility(confdb.SecretVisibility) {
+		return diff, nil
+	}
+
+	aead, err := secretsCipher(st)
+	if err != nil {
+		return nil, err
+	}
+
+	seal := func(schema confdb.DatabagSchema, path []confdb.Accessor, value any) (any, error) {
+		if value == nil || !schema.NestedVisibility(confdb.SecretVisibility) {
+			return value, nil
+		}
+		if _, err := schema.SchemaAt(path); err != nil {
+			return sealValue(aead, value)
+		}
+		return confdb.TransformSecrets(schema, path, value, func(v any) (any, error) {
+			return sealValue(aead, v)
+		})
+	}
+
+	sealed := make([]HistoryDelta, 0, len(diff))
+	for _, delta := range diff {
+		path, err := confdb.ParsePathIntoAccessors(delta.Path, confdb.ParseOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("internal error: cannot parse path %q: %v", delta.Path, err)
+		}
+
+		if delta.Old, err = seal(current, path, delta.Old); err != nil {
+			return nil, err
+		}
+		if delta.New, err = seal(update, path, delta.New); err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, delta)
+	}
+	return sealed, nil
+}
+
+// Apply writes the migrated data and records the migration in the history,
+// keeping the previous data so it can be reverted. It also prepares the
+// custodians' migrate-view hooks, which run once the new revision is added.
+func (m *schemaMigration) Apply() (err error) {
+	st := m.st
+	m.applied = true
+	defer func() {
+		if err != nil {
+			m.Revert()
+		}
+	}()
+
+	if m.migrated != nil {
+		m.prevBag, err = loadDatabag(st, m.account, m.schemaName)
+		if err != nil {
+			return err
+		}
+
+		history, saveHistory, err := readHistory(st, m.account, m.schemaName)
+		if err != nil {
+			return err
+		}
+		m.prevHistory = history
+
+		if err := saveDatabag(st, m.migrated, m.account, m.schemaName); err != nil {
+			return err
+		}
+
+		if len(m.diff) != 0 {
+			saveHistory(appendHistoryEntry(history, &HistoryEntry{
+				Time:      timeNow(),
+				Migration: m.revision,
+				Diff:      m.diff,
+			}))
+		}
+	}
+
+	if len(m.hooks) != 0 {
+		m.hooksTs, err = m.createHookTasks()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createHookTasks returns a taskset that runs the custodians' migrate-view
+// hooks in a transaction and then commits it. The transaction is reserved
+// so that nothing else can access the confdb until it's done.
+func (m *schemaMigration) createHookTasks() (*state.TaskSet, error) {
+	st := m.st
+	bag := m.bag.Copy()
+
+	tx := &Transaction{
+		pristine:      bag,
+		previous:      bag,
+		ConfdbAccount: m.account,
+		ConfdbName:    m.schemaName,
+		secrets:       m.secrets,
+	}
+
+	ts := state.NewTaskSet()
+	linkTask := func(t *state.Task) {
+		tasks := ts.Tasks()
+		if len(tasks) > 0 {
+			t.WaitFor(tasks[len(tasks)-1])
+		}
+		ts.AddTask(t)
+	}
+
+	clearTxOnErrTask := st.NewTask("clear-confdb-tx-on-error", "Clears the ongoing confdb transaction from state (on error)")
+	linkTask(clearTxOnErrTask)
+
+	for _, hook := range m.hooks {
+		const ignoreError = false
+		linkTask(setupConfdbHook(st, hook.snap, hook.hook, ignoreError))
+	}
+
+	commitTask := st.NewTask("commit-confdb-tx", fmt.Sprintf("Commit migration of confdb %s/%s", m.account, m.schemaName))
+	commitTask.Set("confdb-transaction", tx)
+	for _, t := range ts.Tasks() {
+		t.Set("tx-task", commitTask.ID())
+	}
+	linkTask(commitTask)
+
+	clearTxTask := st.NewTask("clear-confdb-tx", "Clears the ongoing confdb transaction from state")
+	clearTxTask.Set("tx-task", commitTask.ID())
+	linkTask(clearTxTask)
+
+	if err := setWriteTransaction(st, m.account, m.schemaName, commitTask.ID()); err != nil {
+		return nil, err
+	}
+	m.hooksTxID = commitTask.ID()
+	return ts, nil
+}
+
+// Revert restores the data and history saved before the migration was
+// applied and releases the hooks' transaction. Their tasks aren't part of
+// any change so they never run and are eventually pruned.
+func (m *schemaMigration) Revert() {
+	if !m.applied {
+		return
+	}
+	m.applied = false
+
+	st := m.st
+	if m.migrated != nil {
+		if err := saveDatabag(st, m.prevBag, m.account, m.schemaName); err != nil {
+			logger.Noticef("internal error: cannot restore data of confdb %s/%s: %v", m.account, m.schemaName, err)
+		}
+
+		_, saveHistory, err := readHistory(st, m.account, m.schemaName)
+		if err != nil {
+			logger.Noticef("internal error: cannot restore history of confdb %s/%s: %v", m.account, m.schemaName, err)
+		} else {
+			saveHistory(m.prevHistory)
+		}
+	}
+
+	if m.hooksTxID != "" {
+		if err := unsetOngoingTransaction(st, m.account, m.schemaName, m.hooksTxID); err != nil {
+			logger.Noticef("internal error: cannot release transaction of confdb %s/%s: %v", m.account, m.schemaName, err)
+		}
+		m.hooksTs = nil
+		m.hooksTxID = ""
+	}
+}
+
+// Done schedules the custodians' migrate-view hooks, if there are any.
+func (m *schemaMigration) Done() {
+	if m.hooksTs == nil {
+		return
+	}
+
+	chg := m.st.NewChange(migrateConfdbChangeKind, fmt.Sprintf("Migrate confdb %s/%s to revision %d", m.account, m.schemaName, m.revision))
+	chg.AddAll(m.hooksTs)
+	ensureNow(m.st)
+}
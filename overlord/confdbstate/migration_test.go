@@ -0,0 +1,292 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/confdbstate"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+// signSchemaUpdate signs revision 1 of the "network" confdb-schema, with the
+// same views and storage as the one added in SetUpTest and the given
+// migrations.
+func (s *confdbTestSuite) signSchemaUpdate(c *C, migrations []any) *asserts.ConfdbSchema {
+	headers := map[string]any{
+		"authority-id": s.devAccID,
+		"account-id":   s.devAccID,
+		"name":         "network",
+		"revision":     "1",
+		"views": map[string]any{
+			"setup-wifi": map[string]any{
+				"rules": []any{
+					map[string]any{"request": "ssid", "storage": "wifi.ssid", "access": "read-write"},
+					map[string]any{"request": "password", "storage": "wifi.psk", "access": "write"},
+					map[string]any{"request": "status", "storage": "wifi.status", "access": "read"},
+					map[string]any{"request": "private.{placeholder}", "storage": "private.{placeholder}"},
+				},
+			},
+		},
+		"timestamp": "2030-11-07T09:16:26Z",
+	}
+	body := map[string]any{
+		"storage": map[string]any{
+			"schema": map[string]any{
+				"private": map[string]any{"values": "any"},
+				"wifi": map[string]any{
+					"schema": map[string]any{
+						"psk":    "string",
+						"ssid":   "string",
+						"status": "string",
+					},
+				},
+			},
+		},
+	}
+	if migrations != nil {
+		body["migrations"] = migrations
+		headers["format"] = "1"
+	}
+	rawBody, err := json.MarshalIndent(body, "", "  ")
+	c.Assert(err, IsNil)
+
+	as, err := s.devSigning.Sign(asserts.ConfdbSchemaType, headers, rawBody, "")
+	c.Assert(err, IsNil)
+	return as.(*asserts.ConfdbSchema)
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateMigratesData(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+	s.state.Set("confdb-history", map[string][]*confdbstate.HistoryEntry{
+		s.devAccID + "/network": {{ID: 1}},
+	})
+
+	update := s.signSchemaUpdate(c, []any{
+		map[string]any{
+			"revision": 1,
+			"rules": []any{
+				map[string]any{"from": "wifi.psk", "to": "private.psk"},
+			},
+		},
+	})
+	err := assertstate.Add(s.state, update)
+	c.Assert(err, IsNil)
+
+	as, err := assertstate.ConfdbSchema(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(as.Revision(), Equals, 1)
+
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	data, err := bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"private":{"psk":"secret"},"wifi":{"ssid":"foo"}}`)
+
+	// the migration is recorded in the history
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Check(history[0].ID, Equals, 1)
+	c.Check(history[1].ID, Equals, 2)
+	c.Check(history[1].Migration, Equals, 1)
+	c.Check(history[1].Diff, DeepEquals, []confdbstate.HistoryDelta{
+		{Path: "wifi.psk", Old: "secret"},
+		{Path: "private.psk", New: "secret"},
+	})
+
+	// transactions before the migration can't be rolled back
+	_, err = confdbstate.Rollback(s.state, s.devAccID, "network", 1, "")
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot rollback confdb %s/network: data was migrated to revision 1 of the confdb-schema by transaction 2`, s.devAccID))
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateMigrationReverted(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+	s.state.Set("confdb-history", map[string][]*confdbstate.HistoryEntry{
+		s.devAccID + "/network": {{ID: 1}},
+	})
+
+	current, err := assertstate.ConfdbSchema(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	update := s.signSchemaUpdate(c, []any{
+		map[string]any{
+			"revision": 1,
+			"rules": []any{
+				map[string]any{"from": "wifi.psk", "to": "private.psk"},
+			},
+		},
+	})
+
+	m, err := confdbstate.PrepareSchemaUpdate(s.state, current, update)
+	c.Assert(err, IsNil)
+	c.Assert(m, NotNil)
+	c.Assert(m.Apply(), IsNil)
+
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	data, err := bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"private":{"psk":"secret"}}`)
+
+	// the schema update couldn't be added so the migration is undone
+	m.Revert()
+
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	data, err = bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"wifi":{"psk":"secret"}}`)
+
+	history, err := confdbstate.History(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Check(history[0].ID, Equals, 1)
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateRefusedInvalidData(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "12"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	update := s.signSchemaUpdate(c, []any{
+		map[string]any{
+			"revision": 1,
+			"rules": []any{
+				map[string]any{"from": "wifi.ssid", "to": "wifi.status", "type": "int"},
+			},
+		},
+	})
+	err := assertstate.Add(s.state, update)
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot update confdb-schema %s/network to revision 1: migrated data is invalid: .*`, s.devAccID))
+
+	as, err := assertstate.ConfdbSchema(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(as.Revision(), Equals, 0)
+
+	// the data is untouched
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	data, err := bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"wifi":{"ssid":"12"}}`)
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateRefusedOngoingTransaction(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := confdbstate.AddReadTransaction(s.state, s.devAccID, "network", "1")
+	c.Assert(err, IsNil)
+
+	err = assertstate.Add(s.state, s.signSchemaUpdate(c, nil))
+	c.Assert(err, ErrorMatches, fmt.Sprintf(`cannot update confdb-schema %s/network to revision 1: cannot migrate confdb while it is being accessed`, s.devAccID))
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateWithoutMigrations(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	err := assertstate.Add(s.state, s.signSchemaUpdate(c, nil))
+	c.Assert(err, IsNil)
+
+	as, err := assertstate.ConfdbSchema(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Check(as.Revision(), Equals, 1)
+
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	data, err := bag.Data()
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"wifi":{"ssid":"foo"}}`)
+}
+
+func (s *confdbTestSuite) TestSchemaUpdateRunsCustodianMigrateHooks(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	custodians := map[string]confdbHooks{"custodian-snap": noHooks}
+	s.setupConfdbScenario(c, custodians, nil)
+	plug := s.repo.Plug("custodian-snap", "setup")
+	c.Assert(plug, NotNil)
+	plug.Snap.Hooks["migrate-view-setup"] = &snap.HookInfo{Name: "migrate-view-setup", Snap: plug.Snap}
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	err := assertstate.Add(s.state, s.signSchemaUpdate(c, nil))
+	c.Assert(err, IsNil)
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	chg := chgs[0]
+	c.Check(chg.Kind(), Equals, "migrate-confdb")
+	c.Check(chg.Summary(), Equals, fmt.Sprintf("Migrate confdb %s/network to revision 1", s.devAccID))
+
+	tasks := chg.Tasks()
+	kinds := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		kinds = append(kinds, t.Kind())
+	}
+	c.Check(kinds, DeepEquals, []string{"clear-confdb-tx-on-error", "run-hook", "commit-confdb-tx", "clear-confdb-tx"})
+
+	var hooksup hookstate.HookSetup
+	c.Assert(tasks[1].Get("hook-setup", &hooksup), IsNil)
+	c.Check(hooksup.Snap, Equals, "custodian-snap")
+	c.Check(hooksup.Hook, Equals, "migrate-view-setup")
+	c.Check(hooksup.IgnoreError, Equals, false)
+
+	// the hooks work on the migrated data in a transaction that keeps others
+	// from accessing the confdb until they're done
+	var ongoingTxs map[string]*confdbstate.ConfdbTransactions
+	c.Assert(s.state.Get("confdb-ongoing-txs", &ongoingTxs), IsNil)
+	c.Check(ongoingTxs[s.devAccID+"/network"].WriteTxID, Equals, tasks[2].ID())
+
+	tx, _, _, err := confdbstate.GetStoredTransaction(tasks[1])
+	c.Assert(err, IsNil)
+	val, err := tx.Get(parsePath(c, "wifi.ssid"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "foo")
+}
@@ -0,0 +1,327 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// Values with secret visibility are encrypted when the confdb data is saved
+// in the state (databags and history) with a key that is generated on, and
+// never leaves, the device. The data of ongoing transactions of confdbs with
+// secrets is encrypted as a whole when saved in their tasks.
+
+// sealedPrefix marks encrypted values. Values at secret paths without it were
+// saved before the path was secret and are read as is.
+const sealedPrefix = "sealed:v1:"
+
+const secretsKeySize = 32
+
+type secretsKeyKey struct{}
+
+func secretsKeyPath() string {
+	return filepath.Join(dirs.SnapDeviceDir, "confdb-secrets.key")
+}
+
+// secretsKey returns the device's key for encrypting confdb secrets,
+// generating it if it doesn't exist yet.
+func secretsKey(st *state.State) ([]byte, error) {
+	if key, ok := st.Cached(secretsKeyKey{}).([]byte); ok {
+		return key, nil
+	}
+
+	path := secretsKeyPath()
+	key, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("cannot read confdb secrets key: %v", err)
+		}
+
+		key = make([]byte, secretsKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("cannot generate confdb secrets key: %v", err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("cannot save confdb secrets key: %v", err)
+		}
+		if err := osutil.AtomicWriteFile(path, key, 0600, 0); err != nil {
+			return nil, fmt.Errorf("cannot save confdb secrets key: %v", err)
+		}
+	}
+
+	if len(key) != secretsKeySize {
+		return nil, fmt.Errorf("invalid confdb secrets key: expected %d bytes but got %d", secretsKeySize, len(key))
+	}
+
+	st.Cache(secretsKeyKey{}, key)
+	return key, nil
+}
+
+func secretsCipher(st *state.State) (cipher.AEAD, error) {
+	key, err := secretsKey(st)
+	if err != nil {
+		return nil, err
+	}
+	return newSecretsCipher(key)
+}
+
+// existingSecretsCipher returns a cipher using the device's key for confdb
+// secrets, which must already exist. Unlike secretsCipher, it doesn't require
+// the state.
+func existingSecretsCipher() (cipher.AEAD, error) {
+	key, err := os.ReadFile(secretsKeyPath())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read confdb secrets key: %v", err)
+	}
+
+	if len(key) != secretsKeySize {
+		return nil, fmt.Errorf("invalid confdb secrets key: expected %d bytes but got %d", secretsKeySize, len(key))
+	}
+	return newSecretsCipher(key)
+}
+
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealValue(aead cipher.AEAD, value any) (any, error) {
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return sealBytes(aead, plain)
+}
+
+func sealBytes(aead cipher.AEAD, plain []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plain, nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func unsealValue(aead cipher.AEAD, value any) (any, error) {
+	str, ok := value.(string)
+	if !ok || !strings.HasPrefix(str, sealedPrefix) {
+		return value, nil
+	}
+
+	plain, err := unsealBytes(aead, str)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsealed any
+	if err := json.Unmarshal(plain, &unsealed); err != nil {
+		return nil, fmt.Errorf("cannot decrypt confdb secret: %v", err)
+	}
+	return unsealed, nil
+}
+
+func unsealBytes(aead cipher.AEAD, str string) ([]byte, error) {
+	if !strings.HasPrefix(str, sealedPrefix) {
+		return nil, errors.New("cannot decrypt confdb secret: invalid data")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(str, sealedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt confdb secret: %v", err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("cannot decrypt confdb secret: invalid data")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt confdb secret: %v", err)
+	}
+	return plain, nil
+}
+
+// secretsSchema returns the storage schema of the confdb if it defines secret
+// values or nil, otherwise.
+func secretsSchema(st *state.State, account, schemaName string) (confdb.DatabagSchema, error) {
+	confdbSchemaAs, err := assertstateConfdbSchema(st, account, schemaName)
+	if err != nil {
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	schema := confdbSchemaAs.Schema().DatabagSchema
+	if !schema.NestedVisibility(confdb.SecretVisibility) {
+		return nil, nil
+	}
+	return schema, nil
+}
+
+// transformSecretsInDatabag returns a copy of the databag with its secret
+// values transformed by the cipher function.
+func transformSecretsInDatabag(st *state.State, schema confdb.DatabagSchema, bag confdb.JSONDatabag, transform func(cipher.AEAD, any) (any, error)) (confdb.JSONDatabag, error) {
+	aead, err := secretsCipher(st)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := bag.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	transformed, err := confdb.TransformSecrets(schema, nil, data, func(value any) (any, error) {
+		return transform(aead, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = json.Marshal(transformed)
+	if err != nil {
+		return nil, err
+	}
+
+	newBag := confdb.NewJSONDatabag()
+	if err := json.Unmarshal(raw, &newBag); err != nil {
+		return nil, err
+	}
+	return newBag, nil
+}
+
+// transformSecretsInData returns a copy of the data of a databag with its
+// secret values transformed by the cipher function.
+func transformSecretsInData(st *state.State, schema confdb.DatabagSchema, data map[string]any, transform func(cipher.AEAD, any) (any, error)) (map[string]any, error) {
+	aead, err := secretsCipher(st)
+	if err != nil {
+		return nil, err
+	}
+
+	transformed, err := confdb.TransformSecrets(schema, nil, data, func(value any) (any, error) {
+		return transform(aead, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transformedData, ok := transformed.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("internal error: expected databag data to be a map but got %T", transformed)
+	}
+	return transformedData, nil
+}
+
+// transformSecretsInHistory returns a copy of the history deltas with their
+// secret values transformed by the cipher function.
+func transformSecretsInHistory(st *state.State, schema confdb.DatabagSchema, diff []HistoryDelta, transform func(cipher.AEAD, any) (any, error)) ([]HistoryDelta, error) {
+	aead, err := secretsCipher(st)
+	if err != nil {
+		return nil, err
+	}
+
+	transformValue := func(value any) (any, error) {
+		return transform(aead, value)
+	}
+
+	transformed := make([]HistoryDelta, 0, len(diff))
+	for _, delta := range diff {
+		path, err := confdb.ParsePathIntoAccessors(delta.Path, confdb.ParseOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("internal error: cannot parse path %q: %v", delta.Path, err)
+		}
+
+		if _, err := schema.SchemaAt(path); err != nil {
+			// the path was migrated away, so its values are kept as they
+			// were saved
+			transformed = append(transformed, delta)
+			continue
+		}
+
+		delta.Old, err = confdb.TransformSecrets(schema, path, delta.Old, transformValue)
+		if err != nil {
+			return nil, err
+		}
+
+		delta.New, err = confdb.TransformSecrets(schema, path, delta.New, transformValue)
+		if err != nil {
+			return nil, err
+		}
+		transformed = append(transformed, delta)
+	}
+	return transformed, nil
+}
+
+// RedactSecretsFor returns a databag through which the snap (or, if snapName
+// is empty, a privileged user) can read through the view. Secret values are
+// redacted unless the view explicitly allows reading them and the snap is one
+// of the view's custodians.
+func RedactSecretsFor(st *state.State, bag confdb.Databag, view *confdb.View, snapName string) (confdb.Databag, error) {
+	schema := view.Schema().DatabagSchema
+	if !schema.NestedVisibility(confdb.SecretVisibility) {
+		return bag, nil
+	}
+
+	if view.SecretsVisible() {
+		if snapName == "" {
+			return bag, nil
+		}
+
+		custodians, _, err := getCustodianPlugsForView(st, view)
+		if err != nil {
+			return nil, err
+		}
+
+		if strutil.ListContains(custodians, snapName) {
+			return bag, nil
+		}
+	}
+
+	return confdb.NewRedactingDatabag(bag, schema), nil
+}
@@ -0,0 +1,340 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package confdbstate_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/confdbstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+// addSecretsSchemaUpdate adds revision 1 of the "network" confdb-schema in
+// which the wifi password is secret and an "admin" view can read it.
+func (s *confdbTestSuite) addSecretsSchemaUpdate(c *C) {
+	headers := map[string]any{
+		"authority-id": s.devAccID,
+		"account-id":   s.devAccID,
+		"name":         "network",
+		"revision":     "1",
+		"views": map[string]any{
+			"setup-wifi": map[string]any{
+				"rules": []any{
+					map[string]any{"request": "ssid", "storage": "wifi.ssid", "access": "read-write"},
+					map[string]any{"request": "password", "storage": "wifi.psk", "access": "write"},
+					map[string]any{"request": "status", "storage": "wifi.status", "access": "read"},
+					map[string]any{"request": "private.{placeholder}", "storage": "private.{placeholder}"},
+				},
+			},
+			"admin": map[string]any{
+				"rules": []any{
+					map[string]any{"request": "wifi", "storage": "wifi"},
+				},
+				"secrets": "visible",
+			},
+		},
+		"timestamp": "2030-11-07T09:16:26Z",
+	}
+	body := map[string]any{
+		"storage": map[string]any{
+			"schema": map[string]any{
+				"private": map[string]any{"values": "any"},
+				"wifi": map[string]any{
+					"schema": map[string]any{
+						"psk":    map[string]any{"type": "string", "pattern": "^[a-z0-9-]+$", "visibility": "secret"},
+						"ssid":   "string",
+						"status": "string",
+					},
+				},
+			},
+		},
+	}
+	rawBody, err := json.MarshalIndent(body, "", "  ")
+	c.Assert(err, IsNil)
+
+	as, err := s.devSigning.Sign(asserts.ConfdbSchemaType, headers, rawBody, "")
+	c.Assert(err, IsNil)
+	c.Assert(assertstate.Add(s.state, as), IsNil)
+}
+
+func (s *confdbTestSuite) rawDatabag(c *C) map[string]any {
+	var databags map[string]map[string]map[string]any
+	c.Assert(s.state.Get("confdb-databags", &databags), IsNil)
+	return databags[s.devAccID]["network"]
+}
+
+func (s *confdbTestSuite) TestSecretsEncryptedAtRest(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	wifi := s.rawDatabag(c)["wifi"].(map[string]any)
+	c.Check(wifi["ssid"], Equals, "foo")
+	psk, ok := wifi["psk"].(string)
+	c.Assert(ok, Equals, true)
+	c.Check(strings.HasPrefix(psk, "sealed:v1:"), Equals, true)
+	c.Check(psk, Not(testutil.Contains), "secret")
+
+	// the databag passed in isn't modified
+	val, err := bag.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "secret")
+
+	bag, err = confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	val, err = bag.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "secret")
+
+	st, err := os.Stat(filepath.Join(dirs.SnapDeviceDir, "confdb-secrets.key"))
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+	c.Check(st.Size(), Equals, int64(32))
+}
+
+func (s *confdbTestSuite) TestSecretsReadPlainTextValue(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	// the value was saved before the path became secret
+	s.addSecretsSchemaUpdate(c)
+
+	bag, err := confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	val, err := bag.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "secret")
+}
+
+func (s *confdbTestSuite) TestSecretsCannotDecryptWithOtherKey(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(confdbstate.WriteDatabag(s.state, bag, s.devAccID, "network"), IsNil)
+
+	// simulate the data being moved to another device
+	s.state.Cache(confdbstate.SecretsKeyKey{}, []byte(strings.Repeat("k", 32)))
+
+	_, err := confdbstate.ReadDatabag(s.state, s.devAccID, "network")
+	c.Assert(err, ErrorMatches, "cannot decrypt confdb secret: .*")
+}
+
+func (s *confdbTestSuite) TestRedactSecretsFor(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+	s.setupConfdbScenario(c, map[string]confdbHooks{"custodian-snap": noHooks}, []string{"test-snap"})
+
+	bag := confdb.NewJSONDatabag()
+	c.Assert(bag.Set(parsePath(c, "wifi.psk"), "secret"), IsNil)
+	c.Assert(bag.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+
+	type testcase struct {
+		view     string
+		snap     string
+		redacted bool
+	}
+	for _, tc := range []testcase{
+		// the view doesn't allow reading secrets
+		{view: "setup-wifi", snap: "", redacted: true},
+		{view: "setup-wifi", snap: "custodian-snap", redacted: true},
+		{view: "admin", snap: "", redacted: false},
+		// only custodians can read secrets
+		{view: "admin", snap: "test-snap", redacted: true},
+	} {
+		cmt := Commentf("view %q snap %q", tc.view, tc.snap)
+		view, err := confdbstate.GetView(s.state, s.devAccID, "network", tc.view)
+		c.Assert(err, IsNil, cmt)
+
+		readBag, err := confdbstate.RedactSecretsFor(s.state, bag, view, tc.snap)
+		c.Assert(err, IsNil, cmt)
+
+		val, err := readBag.Get(parsePath(c, "wifi"), nil)
+		c.Assert(err, IsNil, cmt)
+		psk := "secret"
+		if tc.redacted {
+			psk = confdb.RedactedValue
+		}
+		c.Check(val, DeepEquals, map[string]any{"ssid": "foo", "psk": psk}, cmt)
+	}
+}
+
+func (s *confdbTestSuite) TestRedactSecretsForNoSecrets(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	view, err := confdbstate.GetView(s.state, s.devAccID, "network", "setup-wifi")
+	c.Assert(err, IsNil)
+
+	bag := confdb.NewJSONDatabag()
+	readBag, err := confdbstate.RedactSecretsFor(s.state, bag, view, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(readBag, DeepEquals, confdb.Databag(bag))
+}
+
+func (s *confdbTestSuite) TestSecretsInHistory(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+	s.commitTx(c, "", map[string]any{"wifi.psk": "secret", "wifi.ssid": "foo"})
+
+	var histories map[string][]*confdbstate.HistoryEntry
+	c.Assert(s.state.Get("confdb-history", &histories), IsNil)
+	history := histories[s.devAccID+"/network"]
+	c.Assert(history, HasLen, 1)
+	for _, delta := range history[0].Diff {
+		if delta.Path == "wifi.psk" {
+			c.Check(strings.HasPrefix(delta.New.(string), "sealed:v1:"), Equals, true)
+		} else {
+			c.Check(delta.New, Equals, "foo")
+		}
+	}
+
+	view, err := confdbstate.GetView(s.state, s.devAccID, "network", "admin")
+	c.Assert(err, IsNil)
+
+	// secrets are redacted even if the view allows reading them
+	entries, err := confdbstate.ViewHistory(s.state, view)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Check(entries[0].Diff, DeepEquals, []confdbstate.HistoryDelta{
+		{Path: "wifi.psk", New: confdb.RedactedValue},
+		{Path: "wifi.ssid", New: "foo"},
+	})
+}
+
+func (s *confdbTestSuite) TestSecretsEncryptedInTransaction(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+
+	tx, err := confdbstate.NewTransaction(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(tx.Set(parsePath(c, "wifi.psk"), "top-secret-value"), IsNil)
+	c.Assert(tx.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+
+	t := s.state.NewTask("commit-confdb-tx", "")
+	setTransaction(t, tx)
+
+	raw, err := json.Marshal(s.state)
+	c.Assert(err, IsNil)
+	c.Check(string(raw), Not(testutil.Contains), "top-secret-value")
+
+	var data map[string]any
+	c.Assert(t.Get("confdb-transaction", &data), IsNil)
+	c.Check(data["confdb-account"], Equals, s.devAccID)
+	c.Check(data["confdb-name"], Equals, "network")
+	c.Check(strings.HasPrefix(data["sealed"].(string), "sealed:v1:"), Equals, true)
+	c.Check(data["deltas"], IsNil)
+
+	storedTx, _, saveTxChanges, err := confdbstate.GetStoredTransaction(t)
+	c.Assert(err, IsNil)
+	val, err := storedTx.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "top-secret-value")
+
+	// changes are still encrypted when saved again
+	c.Assert(storedTx.Set(parsePath(c, "wifi.psk"), "other-secret-value"), IsNil)
+	saveTxChanges()
+
+	raw, err = json.Marshal(s.state)
+	c.Assert(err, IsNil)
+	c.Check(string(raw), Not(testutil.Contains), "other-secret-value")
+
+	storedTx, _, _, err = confdbstate.GetStoredTransaction(t)
+	c.Assert(err, IsNil)
+	val, err = storedTx.Get(parsePath(c, "wifi.psk"), nil)
+	c.Assert(err, IsNil)
+	c.Check(val, Equals, "other-secret-value")
+}
+
+func (s *confdbTestSuite) TestTransactionWithoutSecretsNotEncrypted(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tx, err := confdbstate.NewTransaction(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	c.Assert(tx.Set(parsePath(c, "wifi.ssid"), "foo"), IsNil)
+
+	t := s.state.NewTask("commit-confdb-tx", "")
+	setTransaction(t, tx)
+
+	var data map[string]any
+	c.Assert(t.Get("confdb-transaction", &data), IsNil)
+	c.Check(data["sealed"], IsNil)
+	c.Check(data["deltas"], DeepEquals, []any{map[string]any{"wifi.ssid": "foo"}})
+}
+
+func (s *confdbTestSuite) TestSecretsRedactedInTaskLog(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.addSecretsSchemaUpdate(c)
+
+	tx, err := confdbstate.NewTransaction(s.state, s.devAccID, "network")
+	c.Assert(err, IsNil)
+	// doesn't match the secret's pattern so the commit fails
+	c.Assert(tx.Set(parsePath(c, "wifi.psk"), "Top-Secret!"), IsNil)
+
+	chg := s.state.NewChange("test", "")
+	t := s.state.NewTask("commit-confdb-tx", "")
+	chg.AddTask(t)
+	setTransaction(t, tx)
+
+	s.state.Unlock()
+	err = s.o.Settle(testutil.HostScaledTimeout(5 * time.Second))
+	s.state.Lock()
+	c.Assert(err, IsNil)
+
+	c.Assert(t.Status(), Equals, state.ErrorStatus)
+	log := strings.Join(t.Log(), "\n")
+	c.Check(log, Matches, `(?s).*cannot accept element in "wifi.psk": invalid secret value.*`)
+	c.Check(log, Not(testutil.Contains), "Top-Secret!")
+	c.Check(chg.Err(), ErrorMatches, `(?s).*invalid secret value.*`)
+	c.Check(chg.Err().Error(), Not(testutil.Contains), "Top-Secret!")
+}
@@ -19,6 +19,7 @@
 package confdbstate
 
 import (
+	"crypto/cipher"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -40,6 +41,10 @@ type Transaction struct {
 	ConfdbAccount string
 	ConfdbName    string
 
+	// Author identifies who made the changes (e.g., a snap or a user) and is
+	// recorded in the confdb history once the changes are committed.
+	Author string
+
 	modified      confdb.JSONDatabag
 	deltas        []pathValuePair
 	appliedDeltas int
@@ -47,6 +52,10 @@ type Transaction struct {
 	abortingSnap string
 	abortReason  string
 
+	// secrets is used to encrypt the transaction's data when it's saved in the
+	// state, if the confdb has secret values
+	secrets cipher.AEAD
+
 	mu sync.RWMutex
 }
 
@@ -64,12 +73,25 @@ func NewTransaction(st *state.State, account, confdbName string) (*Transaction,
 		return nil, err
 	}
 
-	return &Transaction{
+	tx := &Transaction{
 		pristine:      databag,
 		previous:      databag,
 		ConfdbAccount: account,
 		ConfdbName:    confdbName,
-	}, nil
+	}
+
+	schema, err := secretsSchema(st, account, confdbName)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		tx.secrets, err = secretsCipher(st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tx, nil
 }
 
 type marshalledTransaction struct {
@@ -78,6 +100,7 @@ type marshalledTransaction struct {
 
 	ConfdbAccount string `json:"confdb-account,omitempty"`
 	ConfdbName    string `json:"confdb-name,omitempty"`
+	Author        string `json:"author,omitempty"`
 
 	Modified      confdb.JSONDatabag `json:"modified,omitempty"`
 	Deltas        []map[string]any   `json:"deltas,omitempty"`
@@ -85,6 +108,20 @@ type marshalledTransaction struct {
 
 	AbortingSnap string `json:"aborting-snap,omitempty"`
 	AbortReason  string `json:"abort-reason,omitempty"`
+
+	// Sealed holds the encrypted data (databags and deltas) of transactions
+	// on confdbs with secret values
+	Sealed string `json:"sealed,omitempty"`
+}
+
+// sealedTransactionData is the part of the transaction that is encrypted if
+// the confdb has secret values.
+type sealedTransactionData struct {
+	Pristine      confdb.JSONDatabag `json:"pristine,omitempty"`
+	Previous      confdb.JSONDatabag `json:"previous,omitempty"`
+	Modified      confdb.JSONDatabag `json:"modified,omitempty"`
+	Deltas        []map[string]any   `json:"deltas,omitempty"`
+	AppliedDeltas int                `json:"applied-deltas,omitempty"`
 }
 
 func (t *Transaction) MarshalJSON() ([]byte, error) {
@@ -95,17 +132,39 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 		})
 	}
 
-	return json.Marshal(marshalledTransaction{
-		Pristine:      t.pristine,
-		Previous:      t.previous,
+	mt := marshalledTransaction{
 		ConfdbAccount: t.ConfdbAccount,
 		ConfdbName:    t.ConfdbName,
+		Author:        t.Author,
+		AbortingSnap:  t.abortingSnap,
+		AbortReason:   t.abortReason,
+	}
+
+	if t.secrets == nil {
+		mt.Pristine = t.pristine
+		mt.Previous = t.previous
+		mt.Modified = t.modified
+		mt.Deltas = deltas
+		mt.AppliedDeltas = t.appliedDeltas
+		return json.Marshal(mt)
+	}
+
+	plain, err := json.Marshal(sealedTransactionData{
+		Pristine:      t.pristine,
+		Previous:      t.previous,
 		Modified:      t.modified,
 		Deltas:        deltas,
 		AppliedDeltas: t.appliedDeltas,
-		AbortingSnap:  t.abortingSnap,
-		AbortReason:   t.abortReason,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	mt.Sealed, err = sealBytes(t.secrets, plain)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt confdb transaction: %v", err)
+	}
+	return json.Marshal(mt)
 }
 
 func (t *Transaction) UnmarshalJSON(data []byte) error {
@@ -114,6 +173,30 @@ func (t *Transaction) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if mt.Sealed != "" {
+		aead, err := existingSecretsCipher()
+		if err != nil {
+			return err
+		}
+
+		plain, err := unsealBytes(aead, mt.Sealed)
+		if err != nil {
+			return fmt.Errorf("cannot decrypt confdb transaction: %v", err)
+		}
+
+		var data sealedTransactionData
+		if err := json.Unmarshal(plain, &data); err != nil {
+			return err
+		}
+
+		mt.Pristine = data.Pristine
+		mt.Previous = data.Previous
+		mt.Modified = data.Modified
+		mt.Deltas = data.Deltas
+		mt.AppliedDeltas = data.AppliedDeltas
+		t.secrets = aead
+	}
+
 	var deltas []pathValuePair
 	for _, delta := range mt.Deltas {
 		for path, value := range delta {
@@ -130,6 +213,7 @@ func (t *Transaction) UnmarshalJSON(data []byte) error {
 	t.previous = mt.Previous
 	t.ConfdbAccount = mt.ConfdbAccount
 	t.ConfdbName = mt.ConfdbName
+	t.Author = mt.Author
 	t.modified = mt.Modified
 	t.deltas = deltas
 	t.appliedDeltas = mt.AppliedDeltas
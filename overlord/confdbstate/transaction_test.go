@@ -24,8 +24,10 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/confdb"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/testutil"
@@ -47,6 +49,11 @@ func (s *transactionTestSuite) SetUpTest(c *C) {
 	s.state.Lock()
 	s.AddCleanup(func() { s.state.Unlock() })
 
+	// no confdb-schemas, so no secrets to encrypt
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{Backstore: asserts.NewMemoryBackstore()})
+	c.Assert(err, IsNil)
+	assertstate.ReplaceDB(s.state, db)
+
 	s.readCalled = 0
 	s.writeCalled = 0
 
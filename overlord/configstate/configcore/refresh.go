@@ -23,9 +23,12 @@ package configcore
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
 )
@@ -43,6 +46,8 @@ func init() {
 	supportedConfigurations["core.refresh.retain"] = true
 	supportedConfigurations["core.refresh.rate-limit"] = true
 	supportedConfigurations["core.refresh.max-inhibition-days"] = true
+	// refresh.snap-timer.<snap> are accepted in applyHandlers
+	supportedConfigurations["core.refresh.snap-timer"] = true
 }
 
 func reportOrIgnoreInvalidManageRefreshes(tr RunTransaction, optName string) error {
@@ -149,6 +154,53 @@ func validateRefreshSchedule(tr RunTransaction) error {
 	return err
 }
 
+// validateSnapRefreshTimers validates the refresh.snap-timer.<snap> options
+// that were changed.
+func validateSnapRefreshTimers(tr RunTransaction) error {
+	changed := make(map[string]any)
+	for _, k := range tr.Changes() {
+		if k == "core.refresh.snap-timer" {
+			// the whole map was set, check all of it
+			var timers map[string]any
+			if err := tr.Get("core", "refresh.snap-timer", &timers); err != nil && !config.IsNoOption(err) {
+				return err
+			}
+			for name, v := range timers {
+				changed[name] = v
+			}
+			continue
+		}
+		if name := strings.TrimPrefix(k, "core.refresh.snap-timer."); name != k {
+			var v any
+			if err := tr.Get("core", "refresh.snap-timer."+name, &v); err != nil && !config.IsNoOption(err) {
+				return err
+			}
+			changed[name] = v
+		}
+	}
+
+	for name, v := range changed {
+		if err := snap.ValidateInstanceName(name); err != nil {
+			return fmt.Errorf("cannot set refresh timer for snap %q: %v", name, err)
+		}
+		if v == nil {
+			// unset
+			continue
+		}
+		timer, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("cannot set refresh timer for snap %q: timer must be a string", name)
+		}
+		if timer == "managed" {
+			return fmt.Errorf("cannot set refresh timer for snap %q: only the refresh of all snaps can be managed", name)
+		}
+		if _, err := timeutil.ParseSchedule(timer); err != nil {
+			return fmt.Errorf("cannot set refresh timer for snap %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
 func validateRefreshRateLimit(tr RunTransaction) error {
 	refreshRateLimit, err := coreCfg(tr, "refresh.rate-limit")
 	if err != nil {
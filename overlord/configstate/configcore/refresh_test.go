@@ -94,6 +94,54 @@ func (s *refreshSuite) TestConfigureRefreshTimerManagedChangeError(c *C) {
 	}
 }
 
+func (s *refreshSuite) TestConfigureSnapRefreshTimerHappy(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"refresh.snap-timer.foo":     "mon,8:00~12:00",
+			"refresh.snap-timer.bar_baz": "fri5,23:00-01:00",
+		},
+	})
+	c.Assert(err, IsNil)
+
+	// unsetting is fine
+	err = configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"refresh.snap-timer.foo": nil,
+		},
+	})
+	c.Assert(err, IsNil)
+
+	// as is setting the whole map
+	err = configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"refresh.snap-timer": map[string]any{"foo": "8:00-9:00"},
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *refreshSuite) TestConfigureSnapRefreshTimerRejected(c *C) {
+	for _, t := range []struct {
+		changes map[string]any
+		err     string
+	}{
+		{map[string]any{"refresh.snap-timer.foo": "invalid"}, `cannot set refresh timer for snap "foo": cannot parse "invalid": "invalid" is not a valid weekday`},
+		{map[string]any{"refresh.snap-timer.foo": 42}, `cannot set refresh timer for snap "foo": timer must be a string`},
+		{map[string]any{"refresh.snap-timer.foo": "managed"}, `cannot set refresh timer for snap "foo": only the refresh of all snaps can be managed`},
+		{map[string]any{"refresh.snap-timer.Foo": "8:00-9:00"}, `cannot set refresh timer for snap "Foo": invalid snap name: "Foo"`},
+		{map[string]any{"refresh.snap-timer": map[string]any{"foo": "invalid"}}, `cannot set refresh timer for snap "foo": cannot parse "invalid": .*`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state:   s.state,
+			changes: t.changes,
+		})
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
 func (s *refreshSuite) TestConfigureLegacyRefreshScheduleHappy(c *C) {
 	err := configcore.Run(classicDev, &mockConf{
 		state: s.state,
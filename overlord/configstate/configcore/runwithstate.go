@@ -45,6 +45,12 @@ func init() {
 	// proxy.store
 	addWithStateHandler(validateProxyStore, handleProxyStore, nil)
 
+	// store.local-path
+	addWithStateHandler(validateStoreLocalPath, handleStoreLocalPath, nil)
+
+	// store.peer-cache.{listen,peers}
+	addWithStateHandler(validateStorePeerCache, handleStorePeerCache, nil)
+
 	// resilience.vitality-hint
 	addWithStateHandler(validateVitalitySettings, handleVitalityConfiguration, nil)
 
@@ -58,6 +64,7 @@ func init() {
 
 	validateOnly := &flags{validatedOnlyStateConfig: true}
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
+	addWithStateHandler(validateSnapRefreshTimers, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
 
@@ -168,6 +175,8 @@ func applyHandlers(dev sysconfig.Device, cfg RunTransaction, handlers []configHa
 			if !validCertOption(k) {
 				return fmt.Errorf("cannot set store ssl certificate under name %q: name must only contain word characters or a dash", k)
 			}
+		case strings.HasPrefix(k, "core.refresh.snap-timer."):
+			// validated by validateSnapRefreshTimers
 		case isNetplanChange(k):
 			if release.OnClassic {
 				return fmt.Errorf("cannot set netplan configuration on classic")
@@ -0,0 +1,75 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+func init() {
+	supportedConfigurations["core.store.local-path"] = true
+}
+
+func validateStoreLocalPath(tr RunTransaction) error {
+	localPath, err := coreCfg(tr, "store.local-path")
+	if err != nil {
+		return err
+	}
+	if localPath == "" {
+		return nil
+	}
+
+	// the directory is not required to exist yet, it can be a mount point
+	// for removable media
+	if !filepath.IsAbs(localPath) || filepath.Clean(localPath) != localPath {
+		return fmt.Errorf("store.local-path must be a clean absolute path: %q", localPath)
+	}
+	return nil
+}
+
+func handleStoreLocalPath(tr RunTransaction, opts *fsOnlyContext) error {
+	changed := false
+	for _, name := range tr.Changes() {
+		if name == "core.store.local-path" {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	localPath, err := coreCfg(tr, "store.local-path")
+	if err != nil {
+		return err
+	}
+
+	// XXX like for proxy.store, the store is replaced before the
+	// configuration is committed
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+	snapstate.UseLocalStore(st, localPath)
+	return nil
+}
@@ -0,0 +1,115 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/store/localstore"
+	"github.com/snapcore/snapd/store/storetest"
+)
+
+type storeLocalPathSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&storeLocalPathSuite{})
+
+type networkStore struct {
+	storetest.Store
+}
+
+func (s *storeLocalPathSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	err := os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/etc/"), 0755)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(dirs.GlobalRootDir, "/etc/environment"), nil, 0644)
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	snapstate.ReplaceStore(s.state, &networkStore{})
+	s.state.Unlock()
+}
+
+func (s *storeLocalPathSuite) currentStore() snapstate.StoreService {
+	s.state.Lock()
+	defer s.state.Unlock()
+	return snapstate.Store(s.state, nil)
+}
+
+func (s *storeLocalPathSuite) TestStoreLocalPath(c *C) {
+	network := s.currentStore()
+
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"store.local-path": "/media/usb/snaps",
+		},
+	})
+	c.Assert(err, IsNil)
+
+	local, ok := s.currentStore().(*localstore.Store)
+	c.Assert(ok, Equals, true)
+	c.Check(local.Dir(), Equals, "/media/usb/snaps")
+
+	// switching to another directory
+	err = configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"store.local-path": "/media/other",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.currentStore().(*localstore.Store).Dir(), Equals, "/media/other")
+
+	// the network store is restored once unset
+	err = configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"store.local-path": "",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.currentStore(), Equals, network)
+}
+
+func (s *storeLocalPathSuite) TestStoreLocalPathInvalid(c *C) {
+	for _, p := range []string{"relative/path", "/media/../usb", "/media/usb/"} {
+		err := configcore.Run(coreDev, &mockConf{
+			state: s.state,
+			changes: map[string]any{
+				"store.local-path": p,
+			},
+		})
+		c.Check(err, ErrorMatches, `store.local-path must be a clean absolute path: ".*"`)
+	}
+
+	_, ok := s.currentStore().(*networkStore)
+	c.Check(ok, Equals, true)
+}
@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+func init() {
+	supportedConfigurations["core.store.peer-cache.listen"] = true
+	supportedConfigurations["core.store.peer-cache.peers"] = true
+}
+
+func splitPeers(peers string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(peers, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+func validatePeerCacheAddress(option, addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("cannot use %q for %s: %v", addr, option, err)
+	}
+	if port == "" || (option == "store.peer-cache.peers" && host == "") {
+		return fmt.Errorf("cannot use %q for %s: host and port are required", addr, option)
+	}
+	return nil
+}
+
+func validateStorePeerCache(tr RunTransaction) error {
+	listen, err := coreCfg(tr, "store.peer-cache.listen")
+	if err != nil {
+		return err
+	}
+	if listen != "" {
+		if err := validatePeerCacheAddress("store.peer-cache.listen", listen); err != nil {
+			return err
+		}
+	}
+
+	peers, err := coreCfg(tr, "store.peer-cache.peers")
+	if err != nil {
+		return err
+	}
+	for _, addr := range splitPeers(peers) {
+		if err := validatePeerCacheAddress("store.peer-cache.peers", addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleStorePeerCache(tr RunTransaction, opts *fsOnlyContext) error {
+	changed := false
+	for _, name := range tr.Changes() {
+		if name == "core.store.peer-cache.listen" || name == "core.store.peer-cache.peers" {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	// the peer cache is set up when snapd starts
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+	restartRequest(st, restart.RestartDaemon, nil)
+	return nil
+}
+
+// StorePeerCache returns the address to serve the download cache to peers on
+// and the addresses of the peers to share it with, as set with
+// store.peer-cache.listen and store.peer-cache.peers. Callers must hold the
+// state lock.
+func StorePeerCache(st *state.State) (listen string, peers []string, err error) {
+	tr := config.NewTransaction(st)
+	if err := tr.Get("core", "store.peer-cache.listen", &listen); err != nil && !config.IsNoOption(err) {
+		return "", nil, err
+	}
+	var peersOpt string
+	if err := tr.Get("core", "store.peer-cache.peers", &peersOpt); err != nil && !config.IsNoOption(err) {
+		return "", nil, err
+	}
+	return listen, splitPeers(peersOpt), nil
+}
@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type storePeerCacheSuite struct {
+	configcoreSuite
+
+	restartRequests []restart.RestartType
+}
+
+var _ = Suite(&storePeerCacheSuite{})
+
+func (s *storePeerCacheSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	err := os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/etc/"), 0755)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(dirs.GlobalRootDir, "/etc/environment"), nil, 0644)
+	c.Assert(err, IsNil)
+
+	s.restartRequests = nil
+	s.AddCleanup(configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		s.restartRequests = append(s.restartRequests, t)
+	}))
+}
+
+func (s *storePeerCacheSuite) TestStorePeerCacheRestartsDaemon(c *C) {
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]any{
+			"store.peer-cache.listen": ":7410",
+			"store.peer-cache.peers":  "192.168.1.10:7410, gateway.lan:7410",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.restartRequests, DeepEquals, []restart.RestartType{restart.RestartDaemon})
+
+	// unrelated changes do not restart snapd
+	s.restartRequests = nil
+	err = configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]any{
+			"store.peer-cache.listen": ":7410",
+		},
+		changes: map[string]any{
+			"refresh.timer": "4:00-6:00",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.restartRequests, HasLen, 0)
+}
+
+func (s *storePeerCacheSuite) TestStorePeerCacheInvalid(c *C) {
+	for _, t := range []struct {
+		changes map[string]any
+		err     string
+	}{
+		{map[string]any{"store.peer-cache.listen": "7410"}, `cannot use "7410" for store.peer-cache.listen: .*missing port in address`},
+		{map[string]any{"store.peer-cache.listen": "host:"}, `cannot use "host:" for store.peer-cache.listen: host and port are required`},
+		{map[string]any{"store.peer-cache.peers": "192.168.1.10"}, `cannot use "192.168.1.10" for store.peer-cache.peers: .*missing port in address`},
+		{map[string]any{"store.peer-cache.peers": "192.168.1.10:7410,:7410"}, `cannot use ":7410" for store.peer-cache.peers: host and port are required`},
+	} {
+		err := configcore.Run(coreDev, &mockConf{
+			state:   s.state,
+			changes: t.changes,
+		})
+		c.Check(err, ErrorMatches, t.err)
+	}
+	c.Check(s.restartRequests, HasLen, 0)
+}
+
+func (s *storePeerCacheSuite) TestStorePeerCache(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	listen, peers, err := configcore.StorePeerCache(s.state)
+	c.Assert(err, IsNil)
+	c.Check(listen, Equals, "")
+	c.Check(peers, HasLen, 0)
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "store.peer-cache.listen", ":7410"), IsNil)
+	c.Assert(tr.Set("core", "store.peer-cache.peers", "192.168.1.10:7410, gateway.lan:7410,"), IsNil)
+	tr.Commit()
+
+	listen, peers, err = configcore.StorePeerCache(s.state)
+	c.Assert(err, IsNil)
+	c.Check(listen, Equals, ":7410")
+	c.Check(peers, DeepEquals, []string{"192.168.1.10:7410", "gateway.lan:7410"})
+}
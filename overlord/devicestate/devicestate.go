@@ -143,6 +143,17 @@ func Serial(st *state.State) (*asserts.Serial, error) {
 	return findSerial(st, nil)
 }
 
+// SignSerialProof signs the given data with the device key, proving to the
+// peers of the device taking part in a cluster assembly session that it holds
+// the key of its serial. Callers must hold the state lock.
+func SignSerialProof(st *state.State, data []byte) ([]byte, error) {
+	privKey, err := deviceMgr(st).keyPair()
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign serial proof without device key: %v", err)
+	}
+	return asserts.RawSignWithKey(data, privKey)
+}
+
 // findKnownRevisionOfModel returns the model assertion revision if any in the
 // assertion database for the given model, otherwise it returns -1.
 func findKnownRevisionOfModel(st *state.State, mod *asserts.Model) (modRevision int, err error) {
@@ -2887,6 +2887,23 @@ func (s *deviceMgrSuite) TestSignConfdbControlOK(c *C) {
 	assertstatetest.AddMany(s.state, cc)
 }
 
+func (s *deviceMgrSuite) TestSignSerialProof(c *C) {
+	s.setPCModelInState(c)
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeSerialAssertionInState(c, "canonical", "pc", "serialserialserial")
+
+	_, err := devicestate.SignSerialProof(s.state, []byte("hmac"))
+	c.Assert(err, ErrorMatches, "cannot sign serial proof without device key: .*")
+
+	s.addKeyToManagerInState(c)
+
+	proof, err := devicestate.SignSerialProof(s.state, []byte("hmac"))
+	c.Assert(err, IsNil)
+	c.Check(asserts.RawVerifyWithKey([]byte("hmac"), proof, devKey.PublicKey()), IsNil)
+}
+
 func (s *deviceMgrSuite) TestConfdbControlNoSerial(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -441,6 +441,11 @@ func (c *getCommand) getConfdbValues(ctx *hookstate.Context, plugName string, re
 		bag = tx.Previous()
 	}
 
+	bag, err = confdbstate.RedactSecretsFor(ctx.State(), bag, view, ctx.InstanceName())
+	if err != nil {
+		return err
+	}
+
 	// TODO: support constraints in snapctl
 	res, err := confdbstate.GetViaView(bag, view, requests, nil)
 	if err != nil {
@@ -0,0 +1,191 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+// PolicyExplanation explains the declaration based policy decisions
+// about connecting a plug to a slot.
+type PolicyExplanation struct {
+	Plug      interfaces.PlugRef
+	Slot      interfaces.SlotRef
+	Interface string
+	// Connect explains the decision about manual connections.
+	Connect *policy.Explanation
+	// ConnectUnchecked is set when the plug or slot snap has no
+	// snap-declaration (it was installed with --dangerous), manual
+	// connections are not checked against the policy then.
+	ConnectUnchecked bool
+	// AutoConnect explains the decision about auto-connections.
+	AutoConnect *policy.Explanation
+	// AutoConnectCandidate is set if the slot is among the resulting
+	// auto-connection candidates of the plug.
+	AutoConnectCandidate bool
+	// Notes carries further details about the decisions, e.g. why
+	// an allowed auto-connection does not make the slot a candidate.
+	Notes []string
+}
+
+// ExplainPolicy explains the declaration based policy decisions about
+// connecting and auto-connecting the plugs and slots of the given snap,
+// or of all snaps if snapName is empty, optionally restricted to the
+// given interface. Every plug is paired with each slot of the same
+// interface.
+//
+// The state must be locked by the caller.
+func (m *InterfaceManager) ExplainPolicy(snapName, ifaceName string) ([]*PolicyExplanation, error) {
+	deviceCtx, err := snapstate.DeviceCtx(m.state, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	autochecker, err := newAutoConnectChecker(m.state, m.repo, deviceCtx)
+	if err != nil {
+		return nil, err
+	}
+	var storeAs *asserts.Store
+	if storeName := deviceCtx.Model().Store(); storeName != "" {
+		storeAs, err = assertstate.Store(m.state, storeName)
+		if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, err
+		}
+	}
+
+	var expls []*PolicyExplanation
+	for _, plug := range m.repo.AllPlugs(ifaceName) {
+		plugSnapName := plug.Snap.InstanceName()
+		bySlot := make(map[interfaces.SlotRef]*PolicyExplanation)
+		var plugExpls []*PolicyExplanation
+		policyCheck := func(cplug *interfaces.ConnectedPlug, cslot *interfaces.ConnectedSlot) (bool, interfaces.SideArity, error) {
+			ok, arity, err := autochecker.check(cplug, cslot)
+			slotSnapName := cslot.Snap().InstanceName()
+			if snapName == "" || plugSnapName == snapName || slotSnapName == snapName {
+				expl := autochecker.explain(cplug, cslot, storeAs)
+				bySlot[expl.Slot] = expl
+				plugExpls = append(plugExpls, expl)
+			}
+			return ok, arity, err
+		}
+		candSlots, arities := m.repo.AutoConnectCandidateSlots(plugSnapName, plug.Name, policyCheck)
+		if len(plugExpls) == 0 {
+			continue
+		}
+		markAutoConnectCandidates(bySlot, plugExpls, candSlots, arities)
+
+		sort.Slice(plugExpls, func(i, j int) bool {
+			return plugExpls[i].Slot.SortsBefore(plugExpls[j].Slot)
+		})
+		expls = append(expls, plugExpls...)
+	}
+	return expls, nil
+}
+
+// markAutoConnectCandidates mirrors the candidate selection of
+// addAutoConnections.
+func markAutoConnectCandidates(bySlot map[interfaces.SlotRef]*PolicyExplanation, expls []*PolicyExplanation, candSlots []*snap.SlotInfo, arities []interfaces.SideArity) {
+	candidates := make(map[interfaces.SlotRef]bool, len(candSlots))
+	for _, slot := range candSlots {
+		candidates[interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}] = true
+	}
+	for _, expl := range expls {
+		if expl.AutoConnect != nil && expl.AutoConnect.Allowed() && !candidates[expl.Slot] {
+			expl.Notes = append(expl.Notes, fmt.Sprintf("auto-connection rejected by the %q interface", expl.Interface))
+		}
+	}
+
+	candSlots, arities = filterUbuntuCoreSlots(candSlots, arities)
+	kept := make(map[interfaces.SlotRef]bool, len(candSlots))
+	for _, slot := range candSlots {
+		kept[interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}] = true
+	}
+	applicable := true
+	for _, arity := range arities {
+		if !arity.SlotsPerPlugAny() {
+			applicable = len(candSlots) == 1
+			break
+		}
+	}
+	for slotRef := range candidates {
+		expl := bySlot[slotRef]
+		if expl == nil {
+			continue
+		}
+		switch {
+		case !kept[slotRef]:
+			expl.Notes = append(expl.Notes, `slot of "ubuntu-core" ignored in favour of "core"`)
+		case !applicable:
+			expl.Notes = append(expl.Notes, fmt.Sprintf("%d candidate slots but only one slot per plug allowed", len(candSlots)))
+		default:
+			expl.AutoConnectCandidate = true
+		}
+	}
+}
+
+// explain traces the policy checks about connecting plug to slot.
+func (c *autoConnectChecker) explain(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot, storeAs *asserts.Store) *PolicyExplanation {
+	expl := &PolicyExplanation{
+		Plug:      *plug.Ref(),
+		Slot:      *slot.Ref(),
+		Interface: plug.Interface(),
+	}
+
+	var plugDecl, slotDecl *asserts.SnapDeclaration
+	if plug.Snap().SnapID != "" {
+		var err error
+		plugDecl, err = c.snapDeclaration(plug.Snap().SnapID)
+		if err != nil {
+			expl.Notes = append(expl.Notes, fmt.Sprintf("cannot find snap declaration for %q: %v", plug.Snap().InstanceName(), err))
+			return expl
+		}
+	}
+	if slot.Snap().SnapID != "" {
+		var err error
+		slotDecl, err = c.snapDeclaration(slot.Snap().SnapID)
+		if err != nil {
+			expl.Notes = append(expl.Notes, fmt.Sprintf("cannot find snap declaration for %q: %v", slot.Snap().InstanceName(), err))
+			return expl
+		}
+	}
+
+	ic := policy.ConnectCandidate{
+		Plug:                plug,
+		PlugSnapDeclaration: plugDecl,
+		Slot:                slot,
+		SlotSnapDeclaration: slotDecl,
+		BaseDeclaration:     c.baseDecl,
+		Model:               c.deviceCtx.Model(),
+		Store:               storeAs,
+		CompatEnabled:       allowCompatLabel(c.contentCompatEnabled, plug.Interface()),
+	}
+	expl.Connect = ic.ExplainConnect()
+	expl.ConnectUnchecked = plugDecl == nil || slotDecl == nil
+	expl.AutoConnect = ic.ExplainAutoConnect()
+	return expl
+}
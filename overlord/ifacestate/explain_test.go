@@ -0,0 +1,125 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/policy"
+)
+
+func (s *interfaceManagerSuite) TestExplainPolicy(c *C) {
+	s.MockModel(c, nil)
+
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+`))
+	defer restore()
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	s.MockSnapDecl(c, "consumer", "other-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.MockSnapDecl(c, "consumer2", "one-publisher", nil)
+	s.mockSnap(c, consumer2Yaml)
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	expls, err := mgr.ExplainPolicy("consumer", "")
+	c.Assert(err, IsNil)
+	c.Assert(expls, HasLen, 1)
+	expl := expls[0]
+	c.Check(expl.Plug, Equals, interfaces.PlugRef{Snap: "consumer", Name: "plug"})
+	c.Check(expl.Slot, Equals, interfaces.SlotRef{Snap: "producer", Name: "slot"})
+	c.Check(expl.Interface, Equals, "test")
+	c.Check(expl.Connect.Allowed(), Equals, true)
+	c.Check(expl.Connect.Origin, Equals, policy.OriginBaseDeclaration)
+	c.Check(expl.ConnectUnchecked, Equals, false)
+	c.Check(expl.AutoConnect.Allowed(), Equals, false)
+	c.Check(expl.AutoConnect.Err, ErrorMatches, `auto-connection not allowed by slot rule of interface "test"`)
+	c.Check(expl.AutoConnect.Side, Equals, "slot")
+	c.Check(expl.AutoConnect.Allow, DeepEquals, []policy.ConstraintsExplanation{
+		{Mismatch: "publisher id does not match"},
+	})
+	c.Check(expl.AutoConnectCandidate, Equals, false)
+	c.Check(expl.Notes, HasLen, 0)
+
+	expls, err = mgr.ExplainPolicy("producer", "test")
+	c.Assert(err, IsNil)
+	c.Assert(expls, HasLen, 2)
+	c.Check(expls[0].Plug.Snap, Equals, "consumer")
+	c.Check(expls[0].AutoConnectCandidate, Equals, false)
+	c.Check(expls[1].Plug.Snap, Equals, "consumer2")
+	c.Check(expls[1].AutoConnect.Allowed(), Equals, true)
+	c.Check(expls[1].AutoConnectCandidate, Equals, true)
+
+	expls, err = mgr.ExplainPolicy("consumer", "test2")
+	c.Assert(err, IsNil)
+	c.Check(expls, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestExplainPolicyOneSlotPerPlug(c *C) {
+	s.MockModel(c, nil)
+
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection: true
+`))
+	defer restore()
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	s.MockSnapDecl(c, "producer2", "one-publisher", nil)
+	s.mockSnap(c, producer2Yaml)
+	s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	expls, err := mgr.ExplainPolicy("consumer", "test")
+	c.Assert(err, IsNil)
+	c.Assert(expls, HasLen, 2)
+	for i, slotSnap := range []string{"producer", "producer2"} {
+		c.Check(expls[i].Slot.Snap, Equals, slotSnap)
+		c.Check(expls[i].AutoConnect.Allowed(), Equals, true)
+		c.Check(expls[i].AutoConnectCandidate, Equals, false)
+		c.Check(expls[i].Notes, DeepEquals, []string{"2 candidate slots but only one slot per plug allowed"})
+	}
+}
@@ -114,6 +114,10 @@ func MockContentLinkRetryTimeout(d time.Duration) (restore func()) {
 	return func() { contentLinkRetryTimeout = old }
 }
 
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
 func MockHotplugRetryTimeout(d time.Duration) (restore func()) {
 	old := hotplugRetryTimeout
 	hotplugRetryTimeout = d
@@ -602,6 +602,10 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 	if err := task.Get("delayed-setup-profiles", &delayedSetupProfiles); err != nil && !errors.Is(err, state.ErrNoState) {
 		return err
 	}
+	var expiry *time.Time
+	if err := task.Get("expiry", &expiry); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
 
 	deviceCtx, err := snapstate.DeviceCtx(st, task, nil)
 	if err != nil {
@@ -742,8 +746,14 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 		Auto:             autoConnect,
 		ByGadget:         byGadget,
 		HotplugKey:       slot.HotplugKey,
+		Expiry:           expiry,
 	}
 	setConns(st, conns)
+	if expiry != nil {
+		// make sure the expiry is noticed even if it's sooner than
+		// the next regular ensure
+		st.EnsureBefore(expiry.Sub(timeNow()))
+	}
 
 	// the dynamic attributes might have been updated by the interface's BeforeConnectPlug/Slot code,
 	// so we need to update the task for connect-plug- and connect-slot- hooks to see new values.
@@ -846,14 +856,23 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 		return fmt.Errorf("internal error: cannot read 'by-hotplug' flag: %s", err)
 	}
 
+	// "undesired" flag indicates the connection must not be auto-connected
+	// again even if it was not auto-connected in the first place.
+	var undesired bool
+	if err := task.Get("undesired", &undesired); err != nil && !errors.Is(err, state.ErrNoState) {
+		return fmt.Errorf("internal error: cannot read 'undesired' flag: %s", err)
+	}
+
 	switch {
 	case forget:
 		delete(conns, cref.ID())
 	case byHotplug:
 		conn.HotplugGone = true
 		conns[cref.ID()] = conn
-	case conn.Auto && !autoDisconnect:
+	case (conn.Auto && !autoDisconnect) || undesired:
 		conn.Undesired = true
+		// undesired connections are kept until connected again
+		conn.Expiry = nil
 		conn.DynamicPlugAttrs = nil
 		conn.DynamicSlotAttrs = nil
 		conn.StaticPlugAttrs = nil
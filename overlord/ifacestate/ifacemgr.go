@@ -20,10 +20,13 @@
 package ifacestate
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/backends"
 	"github.com/snapcore/snapd/logger"
@@ -279,6 +282,10 @@ func (m *InterfaceManager) Ensure() error {
 		return nil
 	}
 
+	if err := m.ensureExpiredConnections(); err != nil {
+		logger.Noticef("cannot remove expired connections: %v", err)
+	}
+
 	if m.udevMonitorDisabled {
 		return nil
 	}
@@ -307,6 +314,143 @@ func (m *InterfaceManager) Ensure() error {
 	return nil
 }
 
+var (
+	timeNow = time.Now
+
+	// expiredConnectionRetryTimeout is how long to wait before retrying to
+	// remove an expired connection whose snaps have other changes in
+	// progress.
+	expiredConnectionRetryTimeout = time.Minute
+
+	disconnectExpiredChangeKind = swfeats.RegisterChangeKind("disconnect-expired")
+)
+
+// ensureExpiredConnections removes time-limited connections whose expiry has
+// passed. Each removal is done in its own change and is recorded with an
+// interface-connection-expired notice keyed by the connection ID. The state
+// engine is asked to come back in time for the next expiry.
+func (m *InterfaceManager) ensureExpiredConnections() error {
+	logger.Trace("ensure", "manager", "InterfaceManager", "func", "ensureExpiredConnections")
+	st := m.state
+	st.Lock()
+	defer st.Unlock()
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	var next time.Time
+	scheduleAt := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+
+	ids := make([]string, 0, len(conns))
+	for id, cstate := range conns {
+		if cstate.Expiry != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		cstate := conns[id]
+		if now.Before(*cstate.Expiry) {
+			scheduleAt(*cstate.Expiry)
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		chg, err := disconnectExpired(st, m.repo, connRef)
+		if err != nil {
+			var conflictErr *snapstate.ChangeConflictError
+			if !errors.As(err, &conflictErr) {
+				return err
+			}
+			// either the connection is already being removed or
+			// the snaps are busy, check again later
+			logger.Debugf("cannot remove expired connection %s yet: %v", id, err)
+			scheduleAt(now.Add(expiredConnectionRetryTimeout))
+			continue
+		}
+		data := map[string]string{
+			"change-id": chg.ID(),
+			"interface": cstate.Interface,
+		}
+		if _, err := st.AddNotice(nil, state.InterfaceConnectionExpiredNotice, id, &state.AddNoticeOptions{Data: data}); err != nil {
+			return err
+		}
+		logger.Noticef("connection %s expired at %s, removing it", id, cstate.Expiry.Format(time.RFC3339))
+		scheduleAt(now)
+	}
+
+	if !next.IsZero() {
+		st.EnsureBefore(next.Sub(now))
+	}
+	return nil
+}
+
+func disconnectExpired(st *state.State, repo *interfaces.Repository, connRef *interfaces.ConnRef) (*state.Change, error) {
+	if err := snapstate.CheckChangeConflictMany(st, []string{connRef.PlugRef.Snap, connRef.SlotRef.Snap}, ""); err != nil {
+		return nil, err
+	}
+
+	var ts *state.TaskSet
+	autoConnectable := false
+	conn, err := repo.Connection(connRef)
+	if err == nil {
+		autoConnectable, err = isAutoConnectable(st, repo, conn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if autoConnectable {
+		// the connection would be established again on the next
+		// auto-connect, so it's marked as undesired as if the user
+		// disconnected it; this also restores the state of connections
+		// that were undesired before being connected for a limited time
+		ts, err = disconnectTasks(st, conn, disconnectOpts{Undesired: true})
+	} else {
+		// the connection was established manually, so it's removed
+		// from the state altogether
+		ts, err = Forget(st, repo, connRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf(i18n.G("Disconnect expired connection %s:%s from %s:%s"),
+		connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	chg := st.NewChange(disconnectExpiredChangeKind, summary)
+	chg.AddAll(ts)
+	snapNames := []string{connRef.PlugRef.Snap}
+	if connRef.SlotRef.Snap != connRef.PlugRef.Snap {
+		snapNames = append(snapNames, connRef.SlotRef.Snap)
+	}
+	sort.Strings(snapNames)
+	chg.Set("snap-names", snapNames)
+	return chg, nil
+}
+
+// isAutoConnectable returns whether the policy allows auto-connecting the
+// plug and slot of the given connection.
+func isAutoConnectable(st *state.State, repo *interfaces.Repository, conn *interfaces.Connection) (bool, error) {
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	checker, err := newAutoConnectChecker(st, repo, deviceCtx)
+	if err != nil {
+		return false, err
+	}
+	ok, _, err := checker.check(conn.Plug, conn.Slot)
+	return ok, err
+}
+
 // Stop implements StateStopper. It stops the udev monitor and prompting,
 // if running.
 func (m *InterfaceManager) Stop() {
@@ -382,6 +526,9 @@ type ConnectionState struct {
 	StaticSlotAttrs  map[string]any
 	DynamicSlotAttrs map[string]any
 	HotplugGone      bool
+	// Expiry is the time after which a time-limited connection is
+	// automatically removed, it's zero for connections without a limit
+	Expiry time.Time
 }
 
 // Active returns true if connection is not undesired and not removed by
@@ -403,7 +550,7 @@ func ConnectionStates(st *state.State) (connStateByRef map[string]ConnectionStat
 
 	connStateByRef = make(map[string]ConnectionState, len(states))
 	for cref, cstate := range states {
-		connStateByRef[cref] = ConnectionState{
+		connState := ConnectionState{
 			Auto:             cstate.Auto,
 			ByGadget:         cstate.ByGadget,
 			Interface:        cstate.Interface,
@@ -414,6 +561,10 @@ func ConnectionStates(st *state.State) (connStateByRef map[string]ConnectionStat
 			DynamicSlotAttrs: cstate.DynamicSlotAttrs,
 			HotplugGone:      cstate.HotplugGone,
 		}
+		if cstate.Expiry != nil {
+			connState.Expiry = *cstate.Expiry
+		}
+		connStateByRef[cref] = connState
 	}
 	return connStateByRef, nil
 }
@@ -89,15 +89,30 @@ type connectOpts struct {
 	AutoConnect bool
 
 	DelayedSetupProfiles bool
+
+	Expiry time.Time
+}
+
+// ConnectOptions holds optional parameters for ConnectWithOptions.
+type ConnectOptions struct {
+	// Expiry, if set, is the time after which the interface manager
+	// automatically removes the connection.
+	Expiry time.Time
 }
 
 // Connect returns a set of tasks for connecting an interface.
 func Connect(st *state.State, plugSnap, plugName, slotSnap, slotName string) (*state.TaskSet, error) {
+	return ConnectWithOptions(st, plugSnap, plugName, slotSnap, slotName, ConnectOptions{})
+}
+
+// ConnectWithOptions returns a set of tasks for connecting an interface
+// using the given options.
+func ConnectWithOptions(st *state.State, plugSnap, plugName, slotSnap, slotName string, opts ConnectOptions) (*state.TaskSet, error) {
 	if err := snapstate.CheckChangeConflictMany(st, []string{plugSnap, slotSnap}, ""); err != nil {
 		return nil, err
 	}
 
-	return connect(st, plugSnap, plugName, slotSnap, slotName, connectOpts{})
+	return connect(st, plugSnap, plugName, slotSnap, slotName, connectOpts{Expiry: opts.Expiry})
 }
 
 func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, flags connectOpts) (*state.TaskSet, error) {
@@ -212,6 +227,9 @@ func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, fla
 	if flags.DelayedSetupProfiles {
 		connectInterface.Set("delayed-setup-profiles", true)
 	}
+	if !flags.Expiry.IsZero() {
+		connectInterface.Set("expiry", flags.Expiry)
+	}
 
 	// Expose a copy of all plug and slot attributes coming from yaml to interface hooks. The hooks will be able
 	// to modify them but all attributes will be checked against assertions after the hooks are run.
@@ -356,6 +374,9 @@ type disconnectOpts struct {
 	AutoDisconnect bool
 	ByHotplug      bool
 	Forget         bool
+	// Undesired marks the connection as undesired even if it was not
+	// auto-connected.
+	Undesired bool
 }
 
 // forgetTasks creates a set of tasks for forgetting an inactive connection
@@ -393,6 +414,9 @@ func disconnectTasks(st *state.State, conn *interfaces.Connection, flags disconn
 	if flags.Forget {
 		disconnectTask.Set("forget", true)
 	}
+	if flags.Undesired {
+		disconnectTask.Set("undesired", true)
+	}
 
 	disconnectTask.Set("slot-static", conn.Slot.StaticAttrs())
 	disconnectTask.Set("slot-dynamic", conn.Slot.DynamicAttrs())
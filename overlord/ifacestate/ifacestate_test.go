@@ -11632,7 +11632,7 @@ func (s *interfaceManagerSuite) TestSystemKeyMismatchCompat(c *C) {
 }
 
 func (s *interfaceManagerSuite) TestEnsureLoopLogging(c *C) {
-	testutil.CheckEnsureLoopLogging("ifacemgr.go", c, false)
+	testutil.CheckEnsureLoopLogging("ifacemgr.go", c, true)
 }
 
 func (s *interfaceManagerSuite) setCompatEnabledFeature(c *C) {
@@ -11982,3 +11982,252 @@ func (s *interfaceManagerSuite) TestAutoConnectSupportsConfigurableAutoConnectSe
 	})
 	c.Assert(logs, HasLen, 0)
 }
+
+func (s *interfaceManagerSuite) TestConnectWithExpiryTracksExpiryInState(c *C) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	restore := ifacestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	expiry := now.Add(time.Hour)
+	s.state.Lock()
+	ts, err := ifacestate.ConnectWithOptions(s.state, "consumer", "plug", "producer", "slot", ifacestate.ConnectOptions{Expiry: expiry})
+	c.Assert(err, IsNil)
+	change := s.state.NewChange("connect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Err(), IsNil)
+	c.Check(change.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface":   "test",
+			"plug-static": map[string]any{"attr1": "value1"},
+			"slot-static": map[string]any{"attr2": "value2"},
+			"expiry":      "2026-10-19T13:00:00Z",
+		},
+	})
+
+	connStates, err := ifacestate.ConnectionStates(s.state)
+	c.Assert(err, IsNil)
+	c.Check(connStates["consumer:plug producer:slot"].Expiry.Equal(expiry), Equals, true)
+
+	// not expired yet, so still connected
+	c.Check(s.state.Changes(), HasLen, 1)
+	c.Check(s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.InterfaceConnectionExpiredNotice}}), HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestEnsureRemovesExpiredConnections(c *C) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	restore := ifacestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	restore = assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection: false
+`))
+	defer restore()
+
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test",
+			"expiry":    "2026-10-19T11:59:00Z",
+		},
+		"consumer:otherplug producer:otherslot": map[string]any{
+			"interface": "test2",
+			"expiry":    "2026-10-19T13:00:00Z",
+		},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	changes := s.state.Changes()
+	c.Assert(changes, HasLen, 1)
+	chg := changes[0]
+	c.Check(chg.Kind(), Equals, "disconnect-expired")
+	c.Check(chg.Summary(), Equals, "Disconnect expired connection consumer:plug from producer:slot")
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), IsNil)
+	c.Check(snapNames, DeepEquals, []string{"consumer", "producer"})
+
+	notices := s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.InterfaceConnectionExpiredNotice}})
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0].Key(), Equals, "consumer:plug producer:slot")
+	c.Check(notices[0].LastData(), DeepEquals, map[string]string{
+		"change-id": chg.ID(),
+		"interface": "test",
+	})
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]any{
+		"consumer:otherplug producer:otherslot": map[string]any{
+			"interface": "test2",
+			"expiry":    "2026-10-19T13:00:00Z",
+		},
+	})
+	ifaces := mgr.Repository().Interfaces()
+	c.Check(ifaces.Connections, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestEnsureExpiredConnectionsWaitsForConflicts(c *C) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	restore := ifacestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test",
+			"expiry":    "2026-10-19T11:00:00Z",
+		},
+	})
+	// the consumer snap is busy
+	chg := s.state.NewChange("other-change", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "consumer"},
+	})
+	chg.AddTask(t)
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(s.state.Changes(), HasLen, 1)
+	c.Check(s.state.Changes()[0].Kind(), Equals, "other-change")
+	c.Check(s.state.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.InterfaceConnectionExpiredNotice}}), HasLen, 0)
+
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 1)
+}
+
+func (s *interfaceManagerSuite) TestEnsureExpiredAutoConnectableConnectionIsUndesired(c *C) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	restore := ifacestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	restore = assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection: true
+`))
+	defer restore()
+
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	// the user disconnected the auto-connected plug
+	s.state.Lock()
+	s.state.Set("conns", map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test",
+			"auto":      true,
+			"undesired": true,
+		},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+
+	// and then connected it for an hour
+	s.state.Lock()
+	ts, err := ifacestate.ConnectWithOptions(s.state, "consumer", "plug", "producer", "slot", ifacestate.ConnectOptions{Expiry: now.Add(time.Hour)})
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("connect", "")
+	chg.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 1)
+	s.state.Unlock()
+
+	now = now.Add(2 * time.Hour)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	changes := s.state.Changes()
+	c.Assert(changes, HasLen, 2)
+	for _, chg = range changes {
+		if chg.Kind() == "disconnect-expired" {
+			break
+		}
+	}
+	c.Assert(chg.Kind(), Equals, "disconnect-expired")
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 0)
+
+	// the connection is undesired again, so it's not auto-connected on
+	// the next refresh, and it's not expired again
+	var conns map[string]any
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]any{
+		"consumer:plug producer:slot": map[string]any{
+			"interface": "test",
+			"undesired": true,
+		},
+	})
+	s.state.Unlock()
+
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.Changes(), HasLen, 2)
+}
@@ -20,7 +20,11 @@
 // Package schema holds structs for reading and writing interface-related state data.
 package schema
 
-import "github.com/snapcore/snapd/snap"
+import (
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
 
 // ConnState holds properties of an interface connection.
 type ConnState struct {
@@ -41,4 +45,7 @@ type ConnState struct {
 	// slots.
 	HotplugGone bool            `json:"hotplug-gone,omitempty" yaml:"hotplug-gone,omitempty"`
 	HotplugKey  snap.HotplugKey `json:"hotplug-key,omitempty" yaml:"hotplug-key,omitempty"`
+	// Expiry is the time after which a time-limited connection is
+	// automatically removed; it's nil for connections without a limit.
+	Expiry *time.Time `json:"expiry,omitempty" yaml:"expiry,omitempty"`
 }
@@ -22,8 +22,10 @@
 package overlord
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -42,6 +44,7 @@ import (
 	"github.com/snapcore/snapd/overlord/cmdstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/configstate"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
 	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/fdestate"
@@ -62,6 +65,7 @@ import (
 	"github.com/snapcore/snapd/overlord/storecontext"
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/localstore"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/timings"
 )
@@ -78,6 +82,8 @@ var (
 	pruneMaxChanges = 500
 
 	defaultCachedDownloads = 5
+	// large snaps are downloaded in chunks over this many connections
+	defaultDownloadConnections = 4
 
 	configstateInit = configstate.Init
 	systemdSdNotify = systemd.SdNotify
@@ -121,6 +127,12 @@ type Overlord struct {
 	noticeMgr  *notices.NoticeManager
 	// proxyConf mediates the http proxy config
 	proxyConf func(req *http.Request) (*url.URL, error)
+
+	// peer cache sharing downloaded snaps on the local network
+	peerCache       *store.PeerCache
+	peerCacheListen string
+	peerCacheStop   context.CancelFunc
+	peerCacheDone   chan struct{}
 }
 
 var storeNew = store.New
@@ -194,7 +206,7 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	deviceMgr.AddOnInit(fdeMgr)
 	o.addManager(deviceMgr)
 
-	o.addManager(clusterstate.Manager(s))
+	o.addManager(clusterstate.Manager(s, o.runner))
 
 	o.addManager(cmdstate.Manager(s, o.runner))
 	o.addManager(snapshotstate.Manager(s, o.runner))
@@ -212,10 +224,16 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	defer s.Unlock()
 	// setting up the store
 	o.proxyConf = proxyconf.New(s).Conf
+	if err := o.setupPeerCache(s); err != nil {
+		return nil, err
+	}
 	storeCtx := storecontext.New(s, o.deviceMgr.StoreContextBackend())
 	sto := o.newStoreWithContext(storeCtx)
 
 	snapstate.ReplaceStore(s, sto)
+	if err := snapstate.InitLocalStore(s); err != nil {
+		return nil, err
+	}
 
 	return o, nil
 }
@@ -361,16 +379,63 @@ func initRestart(s *state.State, curBootID string, restartHandler restart.Handle
 func (o *Overlord) newStoreWithContext(storeCtx store.DeviceAndAuthContext) snapstate.StoreService {
 	cfg := store.DefaultConfig()
 	cfg.Proxy = o.proxyConf
+	cfg.DownloadConnections = defaultDownloadConnections
 	sto := storeNew(cfg, storeCtx)
 	sto.SetCacheDownloads(defaultCachedDownloads)
+	if o.peerCache != nil {
+		sto.SetPeerCache(o.peerCache)
+	}
 	return sto
 }
 
+// setupPeerCache sets up sharing the download cache with peers if
+// store.peer-cache.listen is set, the cache is served from StartUp.
+func (o *Overlord) setupPeerCache(st *state.State) error {
+	listen, peers, err := configcore.StorePeerCache(st)
+	if err != nil {
+		return err
+	}
+	if listen == "" {
+		return nil
+	}
+	cache := store.NewCacheManager(dirs.SnapDownloadCacheDir, defaultCachedDownloads)
+	o.peerCache = store.NewPeerCache(cache, store.PeerCacheConfig{Peers: peers})
+	o.peerCacheListen = listen
+	return nil
+}
+
+func (o *Overlord) startPeerCache() {
+	ln, err := net.Listen("tcp", o.peerCacheListen)
+	if err != nil {
+		logger.Noticef("Cannot share the download cache with peers: %v", err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o.peerCacheStop = cancel
+	o.peerCacheDone = make(chan struct{})
+	go func() {
+		defer close(o.peerCacheDone)
+		if err := o.peerCache.Serve(ctx, ln); err != nil {
+			logger.Noticef("Cannot share the download cache with peers: %v", err)
+		}
+	}()
+}
+
 // newStore can make new stores for use during remodeling.
 // The device backend will tie them to the remodeling device state.
+// Callers must hold the state lock.
 func (o *Overlord) newStore(devBE storecontext.DeviceBackend) snapstate.StoreService {
 	scb := o.deviceMgr.StoreContextBackend()
 	stoCtx := storecontext.NewComposed(o.State(), devBE, scb, scb)
+	// like the device store, the store used for remodeling serves
+	// from the directory set with store.local-path if any
+	localPath, err := snapstate.LocalStorePath(o.State())
+	if err != nil {
+		logger.Noticef("cannot read store.local-path: %v", err)
+	}
+	if localPath != "" {
+		return localstore.New(localPath)
+	}
 	return o.newStoreWithContext(stoCtx)
 }
 
@@ -394,6 +459,10 @@ func (o *Overlord) StartUp() error {
 		}
 	}
 
+	if o.peerCache != nil && !snapdenv.Preseeding() {
+		o.startPeerCache()
+	}
+
 	// slow down for tests
 	if s := os.Getenv("SNAPD_SLOW_STARTUP"); s != "" {
 		if d, err := time.ParseDuration(s); err == nil {
@@ -533,6 +602,10 @@ func (o *Overlord) Stop() error {
 		err = o.loopTomb.Wait()
 	}
 	o.stateEng.Stop()
+	if o.peerCacheStop != nil {
+		o.peerCacheStop()
+		<-o.peerCacheDone
+	}
 	if o.stateFLock != nil {
 		// This will also unlock the file
 		o.stateFLock.Close()
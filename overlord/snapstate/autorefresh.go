@@ -142,6 +142,10 @@ type autoRefresh struct {
 	nextRefresh         time.Time
 	lastRefreshAttempt  time.Time
 
+	// nextSnapRefresh holds the next refresh of the snaps with their
+	// own refresh timer, keyed by the timer.
+	nextSnapRefresh map[string]time.Time
+
 	restoredMonitoring bool
 }
 
@@ -336,6 +340,7 @@ func (m *autoRefresh) Ensure() (err error) {
 	}
 	if len(refreshSchedule) == 0 {
 		m.nextRefresh = time.Time{}
+		m.nextSnapRefresh = nil
 		return nil
 	}
 	// we already have a refresh time, check if we got a new config
@@ -378,6 +383,11 @@ func (m *autoRefresh) Ensure() (err error) {
 		return err
 	}
 
+	timers, err := snapRefreshTimers(m.state)
+	if err != nil {
+		return err
+	}
+
 	// do refresh attempt (if needed)
 	if !held {
 		if !holdTime.IsZero() {
@@ -389,6 +399,12 @@ func (m *autoRefresh) Ensure() (err error) {
 				now = time.Now()
 				m.nextRefresh = now.Add(delta)
 			}
+			// as are the ones of the snaps with their own timer
+			for timer, next := range m.nextSnapRefresh {
+				if next.Before(holdTime) {
+					delete(m.nextSnapRefresh, timer)
+				}
+			}
 		}
 
 		// refresh is also "held" if the next time is in the future
@@ -408,7 +424,7 @@ func (m *autoRefresh) Ensure() (err error) {
 				return nil
 			}
 
-			err = m.launchAutoRefresh()
+			err = m.launchAutoRefresh(nil, timers)
 			if _, ok := err.(*httputil.PersistentNetworkError); ok {
 				// refresh will be retried after refreshRetryDelay
 				return err
@@ -419,10 +435,90 @@ func (m *autoRefresh) Ensure() (err error) {
 
 			// refreshed or hit an non-persistent network error, so reset nextRefresh
 			m.nextRefresh = time.Time{}
+			return err
+		}
+
+		return m.refreshSnapTimers(timers, now)
+	}
+
+	return nil
+}
+
+// refreshSnapTimers refreshes the snaps with their own refresh timer whose
+// next refresh is due. Snaps sharing a timer are refreshed together, at most
+// one group is refreshed at a time.
+func (m *autoRefresh) refreshSnapTimers(timers map[string]*snapRefreshTimer, now time.Time) error {
+	if len(timers) == 0 {
+		m.nextSnapRefresh = nil
+		return nil
+	}
+
+	lastRefresh, err := m.LastRefresh()
+	if err != nil {
+		return err
+	}
+	lastSnapRefreshes, err := getSnapTimerLastRefreshes(m.state)
+	if err != nil {
+		return err
+	}
+
+	// forget about the timers that are gone
+	nextSnapRefresh := make(map[string]time.Time, len(timers))
+	for timer := range timers {
+		if next, ok := m.nextSnapRefresh[timer]; ok {
+			nextSnapRefresh[timer] = next
+		}
+	}
+	m.nextSnapRefresh = nextSnapRefresh
+
+	names := make([]string, 0, len(timers))
+	for timer := range timers {
+		names = append(names, timer)
+	}
+	sort.Strings(names)
+
+	for _, timer := range names {
+		t := timers[timer]
+		last := t.lastRefresh(lastSnapRefreshes, lastRefresh)
+		next, ok := m.nextSnapRefresh[timer]
+		if !ok {
+			next = now
+			if !last.IsZero() {
+				next = now.Add(timeutil.Next(t.schedule, last, maxPostponement))
+			}
+			m.nextSnapRefresh[timer] = next
+			logger.Debugf("Next refresh of snaps %s scheduled for %s.", strutil.Quoted(t.snaps), next.Format(time.RFC3339))
+		}
+		if next.After(now) {
+			continue
+		}
+
+		can, err := m.canRefreshRespectingMetered(now, last)
+		if err != nil {
+			return err
+		}
+		if !can {
+			// clear the next refresh so that another one is calculated
+			delete(m.nextSnapRefresh, timer)
+			continue
 		}
+
+		err = m.launchAutoRefresh(t, timers)
+		if _, ok := err.(*httputil.PersistentNetworkError); ok {
+			// refresh will be retried after refreshRetryDelay
+			return err
+		} else if errors.Is(err, tooSoonError{}) {
+			// ignore error, retry the auto-refresh later
+			return nil
+		}
+
+		// refreshed or hit an non-persistent network error, so reset the
+		// next refresh of the group
+		delete(m.nextSnapRefresh, timer)
+		return err
 	}
 
-	return err
+	return nil
 }
 
 func (m *autoRefresh) restoreMonitoring() error {
@@ -567,6 +663,154 @@ func (m *autoRefresh) refreshScheduleWithDefaultsFallback() (sched []*timeutil.S
 	return sched, scheduleConf, legacy, nil
 }
 
+// snapRefreshTimer is a refresh timer set for some installed snaps with the
+// refresh.snap-timer.<snap> configuration. Those snaps are refreshed together
+// according to it instead of refresh.timer.
+type snapRefreshTimer struct {
+	timer    string
+	schedule []*timeutil.Schedule
+	snaps    []string
+}
+
+// lastRefresh returns the time the least recently refreshed snap of the
+// timer was refreshed, falling back to the given global last refresh.
+func (t *snapRefreshTimer) lastRefresh(lastSnapRefreshes map[string]time.Time, globalLastRefresh time.Time) time.Time {
+	var last time.Time
+	for _, name := range t.snaps {
+		snapLast, ok := lastSnapRefreshes[name]
+		if !ok {
+			snapLast = globalLastRefresh
+		}
+		if last.IsZero() || snapLast.Before(last) {
+			last = snapLast
+		}
+	}
+	return last
+}
+
+// snapRefreshTimers returns the refresh timers set for installed snaps, keyed
+// by the timer.
+func snapRefreshTimers(st *state.State) (map[string]*snapRefreshTimer, error) {
+	var conf map[string]string
+	tr := config.NewTransaction(st)
+	if err := tr.Get("core", "refresh.snap-timer", &conf); err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	if len(conf) == 0 {
+		return nil, nil
+	}
+
+	snapStates, err := All(st)
+	if err != nil {
+		return nil, err
+	}
+
+	timers := make(map[string]*snapRefreshTimer)
+	for name, timer := range conf {
+		if _, ok := snapStates[name]; !ok {
+			continue
+		}
+		t := timers[timer]
+		if t == nil {
+			sched, err := timeutil.ParseSchedule(timer)
+			if err != nil {
+				// log instead of fail, the snap is refreshed
+				// with the others
+				logger.Noticef("cannot use refresh.snap-timer.%s configuration: %v", name, err)
+				continue
+			}
+			t = &snapRefreshTimer{timer: timer, schedule: sched}
+			timers[timer] = t
+		}
+		t.snaps = append(t.snaps, name)
+	}
+	for _, t := range timers {
+		sort.Strings(t.snaps)
+	}
+	return timers, nil
+}
+
+// autoRefreshFilter returns the filter of the snaps to consider when
+// auto-refreshing the snaps of the given timer, or the snaps without their
+// own timer if it is nil.
+func autoRefreshFilter(timer *snapRefreshTimer, timers map[string]*snapRefreshTimer) updateFilter {
+	if timer != nil {
+		return func(info *snap.Info, _ *SnapState) bool {
+			return strutil.ListContains(timer.snaps, info.InstanceName())
+		}
+	}
+	if len(timers) == 0 {
+		return nil
+	}
+	return func(info *snap.Info, _ *SnapState) bool {
+		for _, t := range timers {
+			if strutil.ListContains(t.snaps, info.InstanceName()) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// getSnapTimerLastRefreshes returns the time the snaps with their own refresh
+// timer were last refreshed according to it, keyed by snap name.
+func getSnapTimerLastRefreshes(st *state.State) (map[string]time.Time, error) {
+	var last map[string]time.Time
+	if err := st.Get("last-snap-timer-refresh", &last); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return last, nil
+}
+
+func setSnapTimerLastRefresh(st *state.State, snaps []string, t time.Time) error {
+	last, err := getSnapTimerLastRefreshes(st)
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		last = make(map[string]time.Time, len(snaps))
+	}
+	for _, name := range snaps {
+		last[name] = t
+	}
+	st.Set("last-snap-timer-refresh", last)
+	return nil
+}
+
+// SnapRefreshTimer describes the refresh timer set for a snap.
+type SnapRefreshTimer struct {
+	Timer string
+	// Last is the time the snap was last refreshed according to the timer.
+	Last time.Time
+	// Next is the time the next refresh of the snap will be attempted, it
+	// is unset if it was not computed yet.
+	Next time.Time
+}
+
+// SnapRefreshTimers returns the refresh timers set for installed snaps,
+// keyed by snap name.
+func (m *autoRefresh) SnapRefreshTimers() (map[string]SnapRefreshTimer, error) {
+	timers, err := snapRefreshTimers(m.state)
+	if err != nil || len(timers) == 0 {
+		return nil, err
+	}
+	lastSnapRefreshes, err := getSnapTimerLastRefreshes(m.state)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]SnapRefreshTimer)
+	for timer, t := range timers {
+		for _, name := range t.snaps {
+			res[name] = SnapRefreshTimer{
+				Timer: timer,
+				Last:  lastSnapRefreshes[name],
+				Next:  m.nextSnapRefresh[timer],
+			}
+		}
+	}
+	return res, nil
+}
+
 func autoRefreshSummary(updated []string) string {
 	var msg string
 	switch len(updated) {
@@ -596,7 +840,9 @@ func (tooSoonError) Is(err error) bool {
 }
 
 // launchAutoRefresh creates the auto-refresh taskset and a change for it.
-func (m *autoRefresh) launchAutoRefresh() error {
+// Only the snaps of the given timer are considered if it is set, otherwise
+// the snaps without their own timer.
+func (m *autoRefresh) launchAutoRefresh(timer *snapRefreshTimer, timers map[string]*snapRefreshTimer) error {
 	// Check that we have reasonable delays between attempts.
 	// If the store is under stress we need to make sure we do not
 	// hammer it too often
@@ -615,7 +861,7 @@ func (m *autoRefresh) launchAutoRefresh() error {
 	}()
 
 	// NOTE: this will unlock and re-lock state for network ops
-	updated, updateTss, err := AutoRefresh(auth.EnsureContextTODO(), m.state)
+	updated, updateTss, err := autoRefreshFiltered(auth.EnsureContextTODO(), m.state, autoRefreshFilter(timer, timers))
 
 	// TODO: we should have some way to lock just creating and starting changes,
 	//       as that would alleviate this race condition we are guarding against
@@ -640,7 +886,13 @@ func (m *autoRefresh) launchAutoRefresh() error {
 		logger.Noticef("Cannot prepare auto-refresh change due to a permanent network error: %s", err)
 		return err
 	}
-	m.state.Set("last-refresh", timeNow())
+	if timer != nil {
+		if err := setSnapTimerLastRefresh(m.state, timer.snaps, timeNow()); err != nil {
+			return err
+		}
+	} else {
+		m.state.Set("last-refresh", timeNow())
+	}
 	if err != nil {
 		logger.Noticef("Cannot prepare auto-refresh change: %s", err)
 		return err
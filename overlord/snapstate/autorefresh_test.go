@@ -27,6 +27,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -273,6 +274,123 @@ func (s *autoRefreshTestSuite) TestLastRefreshNoRefreshNeeded(c *C) {
 	c.Check(s.store.ops, HasLen, 0)
 }
 
+func autoRefreshChangeSnapNames(c *C, st *state.State) [][]string {
+	chgs := st.Changes()
+	// changes come from a map, order them by creation
+	sort.Slice(chgs, func(i, j int) bool {
+		idi, _ := strconv.Atoi(chgs[i].ID())
+		idj, _ := strconv.Atoi(chgs[j].ID())
+		return idi < idj
+	})
+	var res [][]string
+	for _, chg := range chgs {
+		if chg.Kind() != "auto-refresh" {
+			continue
+		}
+		var names []string
+		c.Assert(chg.Get("snap-names", &names), IsNil)
+		res = append(res, names)
+	}
+	return res
+}
+
+func (s *autoRefreshTestSuite) TestSnapRefreshTimers(c *C) {
+	restore := snapstate.MockRefreshRetryDelay(0)
+	defer restore()
+
+	s.addRefreshableSnap("foo", "bar")
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.snap-timer", map[string]any{
+		"foo": "00:00~24:00/2",
+		// not installed
+		"missing-snap": "00:00~24:00/2",
+	})
+	tr.Commit()
+	s.state.Unlock()
+
+	// the first refresh is immediate and only refreshes the snaps
+	// without their own timer
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, HasLen, 1)
+
+	s.state.Lock()
+	c.Check(autoRefreshChangeSnapNames(c, s.state), DeepEquals, [][]string{{"bar"}})
+	// pretend it is done
+	for _, chg := range s.state.Changes() {
+		chg.SetStatus(state.DoneStatus)
+	}
+	timers, err := af.SnapRefreshTimers()
+	c.Assert(err, IsNil)
+	c.Check(timers, HasLen, 1)
+	c.Check(timers["foo"].Timer, Equals, "00:00~24:00/2")
+	c.Check(timers["foo"].Last.IsZero(), Equals, true)
+	// the snaps with their own timer were last refreshed long ago
+	s.state.Set("last-snap-timer-refresh", map[string]time.Time{"foo": time.Now().Add(-30 * 24 * time.Hour)})
+	s.state.Unlock()
+
+	// so their next refresh is due
+	err = af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, HasLen, 2)
+
+	var lastRefresh time.Time
+	var lastSnapRefresh map[string]time.Time
+	s.state.Lock()
+	c.Check(autoRefreshChangeSnapNames(c, s.state), DeepEquals, [][]string{{"bar"}, {"foo"}})
+	for _, chg := range s.state.Changes() {
+		chg.SetStatus(state.DoneStatus)
+	}
+	c.Assert(s.state.Get("last-refresh", &lastRefresh), IsNil)
+	c.Assert(s.state.Get("last-snap-timer-refresh", &lastSnapRefresh), IsNil)
+	timers, err = af.SnapRefreshTimers()
+	s.state.Unlock()
+	c.Assert(err, IsNil)
+	c.Check(lastSnapRefresh, HasLen, 1)
+	c.Check(lastSnapRefresh["foo"].After(lastRefresh), Equals, true)
+	c.Check(timers["foo"].Last.Equal(lastSnapRefresh["foo"]), Equals, true)
+
+	// nothing is due anymore
+	err = af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, HasLen, 2)
+
+	s.state.Lock()
+	timers, err = af.SnapRefreshTimers()
+	s.state.Unlock()
+	c.Assert(err, IsNil)
+	c.Check(timers["foo"].Next.After(time.Now()), Equals, true)
+}
+
+func (s *autoRefreshTestSuite) TestSnapRefreshTimersInvalidIgnored(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	s.addRefreshableSnap("foo", "bar")
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.snap-timer", map[string]any{"foo": "invalid"})
+	tr.Commit()
+	s.state.Unlock()
+
+	// the snap is refreshed with the others
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(logbuf.String(), testutil.Contains, `cannot use refresh.snap-timer.foo configuration: cannot parse "invalid"`)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(autoRefreshChangeSnapNames(c, s.state), DeepEquals, [][]string{{"bar", "foo"}})
+	timers, err := af.SnapRefreshTimers()
+	c.Assert(err, IsNil)
+	c.Check(timers, HasLen, 0)
+}
+
 func (s *autoRefreshTestSuite) TestRefreshBackoff(c *C) {
 	s.store.err = fmt.Errorf("random store error")
 	af := snapstate.NewAutoRefresh(s.state)
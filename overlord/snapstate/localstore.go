@@ -0,0 +1,80 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/store/localstore"
+)
+
+// networkStoreKey is the state cache key of the store that was replaced by a
+// local store, to be restored when store.local-path is unset.
+type networkStoreKey struct{}
+
+// LocalStorePath returns the directory set with store.local-path to serve
+// snaps and assertions from, or an empty string if none is set. Callers must
+// hold the state lock.
+func LocalStorePath(st *state.State) (string, error) {
+	var localPath string
+	tr := config.NewTransaction(st)
+	if err := tr.Get("core", "store.local-path", &localPath); err != nil && !config.IsNoOption(err) {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// UseLocalStore makes snapd use a store that serves snaps and assertions from
+// the given directory instead of the store it was using. With an empty path,
+// the store that was replaced is restored. Callers must hold the state lock.
+func UseLocalStore(st *state.State, localPath string) {
+	cur := Store(st, nil)
+	network := cur
+	if _, ok := cur.(*localstore.Store); ok {
+		network, _ = st.Cached(networkStoreKey{}).(StoreService)
+	}
+
+	if localPath == "" {
+		if network != nil && network != cur {
+			ReplaceStore(st, network)
+		}
+		return
+	}
+
+	if local, ok := cur.(*localstore.Store); ok && local.Dir() == localPath {
+		return
+	}
+	st.Cache(networkStoreKey{}, network)
+	ReplaceStore(st, localstore.New(localPath))
+}
+
+// InitLocalStore uses a local store if store.local-path is set, it is meant
+// to be called once the store is set up when snapd starts. Callers must hold
+// the state lock.
+func InitLocalStore(st *state.State) error {
+	localPath, err := LocalStorePath(st)
+	if err != nil {
+		return err
+	}
+	if localPath != "" {
+		UseLocalStore(st, localPath)
+	}
+	return nil
+}
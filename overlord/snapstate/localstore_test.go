@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/store/localstore"
+	"github.com/snapcore/snapd/store/storetest"
+)
+
+type localStoreSuite struct {
+	state *state.State
+}
+
+var _ = Suite(&localStoreSuite{})
+
+type networkStore struct {
+	storetest.Store
+}
+
+func (s *localStoreSuite) SetUpTest(c *C) {
+	s.state = state.New(nil)
+}
+
+func (s *localStoreSuite) TestInitLocalStore(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	network := &networkStore{}
+	snapstate.ReplaceStore(s.state, network)
+
+	localPath, err := snapstate.LocalStorePath(s.state)
+	c.Assert(err, IsNil)
+	c.Check(localPath, Equals, "")
+	c.Assert(snapstate.InitLocalStore(s.state), IsNil)
+	c.Check(snapstate.Store(s.state, nil), Equals, network)
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "store.local-path", "/media/usb"), IsNil)
+	tr.Commit()
+
+	localPath, err = snapstate.LocalStorePath(s.state)
+	c.Assert(err, IsNil)
+	c.Check(localPath, Equals, "/media/usb")
+	c.Assert(snapstate.InitLocalStore(s.state), IsNil)
+	local, ok := snapstate.Store(s.state, nil).(*localstore.Store)
+	c.Assert(ok, Equals, true)
+	c.Check(local.Dir(), Equals, "/media/usb")
+
+	// the replaced store is restored
+	snapstate.UseLocalStore(s.state, "")
+	c.Check(snapstate.Store(s.state, nil), Equals, network)
+}
@@ -1093,6 +1093,13 @@ func (m *SnapManager) RefreshSchedule() (string, bool, error) {
 	return m.autoRefresh.RefreshSchedule()
 }
 
+// SnapRefreshTimers returns the refresh timers set for installed snaps with
+// the refresh.snap-timer.<snap> configuration, keyed by snap name.
+// The caller should be holding the state lock.
+func (m *SnapManager) SnapRefreshTimers() (map[string]SnapRefreshTimer, error) {
+	return m.autoRefresh.SnapRefreshTimers()
+}
+
 // EnsureAutoRefreshesAreDelayed will delay refreshes for the specified amount
 // of time, as well as return any active auto-refresh changes that are currently
 // not ready so that the client can wait for those.
@@ -1905,89 +1905,22 @@ func currentEssentialSnapNames(st *state.State) ([]string, error) {
 // ResolveValidationSetsEnforcementError installs and updates snaps in order to
 // meet the validation set constraints reported in the ValidationSetsValidationError..
 func ResolveValidationSetsEnforcementError(ctx context.Context, st *state.State, valErr *snapasserts.ValidationSetsValidationError, pinnedSeqs map[string]int, userID int) ([]*state.TaskSet, []string, error) {
-	if len(valErr.InvalidSnaps) != 0 {
-		invSnaps := make([]string, 0, len(valErr.InvalidSnaps))
-		for invSnap := range valErr.InvalidSnaps {
-			invSnaps = append(invSnaps, invSnap)
-		}
-		return nil, nil, fmt.Errorf("cannot auto-resolve validation set constraints that require removing snaps: %s", strutil.Quoted(invSnaps))
-	}
-
-	var invComps []string
-	for snapName, cerr := range valErr.ComponentErrors {
-		for compName := range cerr.InvalidComponents {
-			invComps = append(invComps, naming.NewComponentRef(snapName, compName).String())
-		}
-	}
-	if len(invComps) != 0 {
-		return nil, nil, fmt.Errorf("cannot auto-resolve validation set constraints that require removing components: %s", strutil.Quoted(invComps))
+	if err := checkAutoResolvable(valErr); err != nil {
+		return nil, nil, err
 	}
 
-	vsets := snapasserts.NewValidationSets()
-	for _, vs := range valErr.Sets {
-		if err := vsets.Add(vs); err != nil {
-			return nil, nil, err
-		}
+	res, err := resolveValidationSets(st, valErr)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	affected := make([]string, 0, len(valErr.MissingSnaps)+len(valErr.WrongRevisionSnaps))
 	// use the same lane for installing and refreshing so everything is reversed
 	lane := st.NewLane()
 
-	// keep track of snaps that are being having their validation issues
-	// resolved. we won't need to resolve any of their component errors
-	// explicitly.
-	resolved := make(map[string]bool)
-
-	essential, err := currentEssentialSnapNames(st)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var wrongRevs, wrongRevsEssential []StoreUpdate
-	for name := range valErr.WrongRevisionSnaps {
-		resolved[name] = true
-
-		var additionalComps []string
-		if cerr, ok := valErr.ComponentErrors[name]; ok {
-			additionalComps = keys(cerr.MissingComponents)
-		}
-
-		update := StoreUpdate{
-			InstanceName: name,
-			RevOpts: RevisionOptions{
-				ValidationSets: vsets,
-			},
-			AdditionalComponents: additionalComps,
-		}
-
-		if strutil.ListContains(essential, name) {
-			wrongRevsEssential = append(wrongRevsEssential, update)
-		} else {
-			wrongRevs = append(wrongRevs, update)
-		}
-	}
-
-	var missing []StoreSnap
-	for name := range valErr.MissingSnaps {
-		var comps []string
-		if cerr, ok := valErr.ComponentErrors[name]; ok {
-			comps = keys(cerr.MissingComponents)
-		}
-
-		resolved[name] = true
-		missing = append(missing, StoreSnap{
-			InstanceName: name,
-			RevOpts: RevisionOptions{
-				ValidationSets: vsets,
-			},
-			Components: comps,
-		})
-	}
-
 	var essentialTss []*state.TaskSet
-	if len(wrongRevsEssential) > 0 {
-		updated, uts, err := UpdateWithGoal(ctx, st, StoreUpdateGoal(wrongRevsEssential...), nil, Options{
+	if len(res.essentialRefreshes) > 0 {
+		updated, uts, err := UpdateWithGoal(ctx, st, StoreUpdateGoal(res.essentialRefreshes...), nil, Options{
 			Flags: Flags{Transaction: client.TransactionAllSnaps, Lane: lane, NoReRefresh: true},
 		})
 		if err != nil {
@@ -1999,8 +1932,8 @@ func ResolveValidationSetsEnforcementError(ctx context.Context, st *state.State,
 	}
 
 	var updateTss []*state.TaskSet
-	if len(wrongRevs) > 0 {
-		updated, uts, err := UpdateWithGoal(ctx, st, StoreUpdateGoal(wrongRevs...), nil, Options{
+	if len(res.refreshes) > 0 {
+		updated, uts, err := UpdateWithGoal(ctx, st, StoreUpdateGoal(res.refreshes...), nil, Options{
 			Flags: Flags{Transaction: client.TransactionAllSnaps, Lane: lane, NoReRefresh: true},
 		})
 		if err != nil {
@@ -2013,9 +1946,9 @@ func ResolveValidationSetsEnforcementError(ctx context.Context, st *state.State,
 
 	var installed []*snap.Info
 	var installTss []*state.TaskSet
-	if len(missing) > 0 {
+	if len(res.installs) > 0 {
 		var err error
-		installed, installTss, err = InstallWithGoal(ctx, st, StoreInstallGoal(missing...), Options{
+		installed, installTss, err = InstallWithGoal(ctx, st, StoreInstallGoal(res.installs...), Options{
 			Flags: Flags{Transaction: client.TransactionAllSnaps, Lane: lane},
 		})
 		if err != nil {
@@ -2023,21 +1956,13 @@ func ResolveValidationSetsEnforcementError(ctx context.Context, st *state.State,
 		}
 	}
 
-	for snapName, cerr := range valErr.ComponentErrors {
-		if resolved[snapName] {
-			continue
-		}
-
-		comps := make([]string, 0, len(cerr.MissingComponents)+len(cerr.WrongRevisionComponents))
-		comps = append(comps, keys(cerr.MissingComponents)...)
-		comps = append(comps, keys(cerr.WrongRevisionComponents)...)
-
+	for snapName, comps := range res.components {
 		info, err := CurrentInfo(st, snapName)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		compTasks, err := InstallComponents(ctx, st, comps, info, vsets, Options{})
+		compTasks, err := InstallComponents(ctx, st, comps, info, res.vsets, Options{})
 		if err != nil {
 			return nil, nil, err
 		}
@@ -2096,6 +2021,281 @@ func ResolveValidationSetsEnforcementError(ctx context.Context, st *state.State,
 	return tasksets, affected, nil
 }
 
+// checkAutoResolvable returns an error if the validation set constraints
+// reported in valErr cannot be met by ResolveValidationSetsEnforcementError.
+func checkAutoResolvable(valErr *snapasserts.ValidationSetsValidationError) error {
+	if len(valErr.InvalidSnaps) != 0 {
+		invSnaps := make([]string, 0, len(valErr.InvalidSnaps))
+		for invSnap := range valErr.InvalidSnaps {
+			invSnaps = append(invSnaps, invSnap)
+		}
+		return fmt.Errorf("cannot auto-resolve validation set constraints that require removing snaps: %s", strutil.Quoted(invSnaps))
+	}
+
+	var invComps []string
+	for snapName, cerr := range valErr.ComponentErrors {
+		for compName := range cerr.InvalidComponents {
+			invComps = append(invComps, naming.NewComponentRef(snapName, compName).String())
+		}
+	}
+	if len(invComps) != 0 {
+		return fmt.Errorf("cannot auto-resolve validation set constraints that require removing components: %s", strutil.Quoted(invComps))
+	}
+	return nil
+}
+
+// validationSetsResolution describes the snaps and components to install
+// or refresh to meet the validation set constraints reported in a
+// ValidationSetsValidationError.
+type validationSetsResolution struct {
+	vsets *snapasserts.ValidationSets
+	// refreshes of snaps at the wrong revision, together with their
+	// missing components; essential snaps are refreshed separately
+	refreshes, essentialRefreshes []StoreUpdate
+	// installs of missing snaps, together with their missing components
+	installs []StoreSnap
+	// components to install or refresh, by the name of their snap, for
+	// snaps that are neither refreshed nor installed
+	components map[string][]string
+}
+
+// resolveValidationSets works out how to meet the validation set
+// constraints reported in valErr. Removals are not considered, see
+// checkAutoResolvable.
+func resolveValidationSets(st *state.State, valErr *snapasserts.ValidationSetsValidationError) (*validationSetsResolution, error) {
+	res := &validationSetsResolution{
+		vsets:      snapasserts.NewValidationSets(),
+		components: make(map[string][]string),
+	}
+	for _, vs := range valErr.Sets {
+		if err := res.vsets.Add(vs); err != nil {
+			return nil, err
+		}
+	}
+
+	// keep track of snaps that are being having their validation issues
+	// resolved. we won't need to resolve any of their component errors
+	// explicitly.
+	resolved := make(map[string]bool)
+
+	essential, err := currentEssentialSnapNames(st)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range valErr.WrongRevisionSnaps {
+		resolved[name] = true
+
+		var additionalComps []string
+		if cerr, ok := valErr.ComponentErrors[name]; ok {
+			additionalComps = keys(cerr.MissingComponents)
+		}
+
+		update := StoreUpdate{
+			InstanceName: name,
+			RevOpts: RevisionOptions{
+				ValidationSets: res.vsets,
+			},
+			AdditionalComponents: additionalComps,
+		}
+
+		if strutil.ListContains(essential, name) {
+			res.essentialRefreshes = append(res.essentialRefreshes, update)
+		} else {
+			res.refreshes = append(res.refreshes, update)
+		}
+	}
+
+	for name := range valErr.MissingSnaps {
+		var comps []string
+		if cerr, ok := valErr.ComponentErrors[name]; ok {
+			comps = keys(cerr.MissingComponents)
+		}
+
+		resolved[name] = true
+		res.installs = append(res.installs, StoreSnap{
+			InstanceName: name,
+			RevOpts: RevisionOptions{
+				ValidationSets: res.vsets,
+			},
+			Components: comps,
+		})
+	}
+
+	for snapName, cerr := range valErr.ComponentErrors {
+		if resolved[snapName] {
+			continue
+		}
+
+		comps := make([]string, 0, len(cerr.MissingComponents)+len(cerr.WrongRevisionComponents))
+		comps = append(comps, keys(cerr.MissingComponents)...)
+		comps = append(comps, keys(cerr.WrongRevisionComponents)...)
+		if len(comps) != 0 {
+			res.components[snapName] = comps
+		}
+	}
+
+	return res, nil
+}
+
+// ValidationSetsEnforcementAction is a change to an installed snap or
+// component needed to meet validation set constraints.
+type ValidationSetsEnforcementAction struct {
+	InstanceName string
+	// Component is set for actions on components.
+	Component string
+	// Revision is the required revision, it is unset if any revision
+	// allowed by the validation sets will do.
+	Revision snap.Revision
+	// Current is the installed revision, for refreshes.
+	Current snap.Revision
+	// ValidationSets are the keys of the validation sets requiring the
+	// action.
+	ValidationSets []string
+}
+
+// ValidationSetsEnforcementPlan describes the changes needed to meet the
+// validation set constraints reported in a ValidationSetsValidationError.
+type ValidationSetsEnforcementPlan struct {
+	Install []ValidationSetsEnforcementAction
+	Refresh []ValidationSetsEnforcementAction
+	Remove  []ValidationSetsEnforcementAction
+	// Unresolvable is set if ResolveValidationSetsEnforcementError
+	// cannot carry out the plan, it says why.
+	Unresolvable error
+}
+
+// PlanValidationSetsEnforcement returns the snaps and components that need
+// installing, refreshing or removing to meet the validation set constraints
+// reported in valErr, without creating any task.
+func PlanValidationSetsEnforcement(st *state.State, valErr *snapasserts.ValidationSetsValidationError) (*ValidationSetsEnforcementPlan, error) {
+	res, err := resolveValidationSets(st, valErr)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ValidationSetsEnforcementPlan{
+		Unresolvable: checkAutoResolvable(valErr),
+	}
+
+	// componentAction adds the installation or refresh of a component
+	// of an installed snap to the plan
+	componentAction := func(snapst *SnapState, name, compName string) {
+		cerr := valErr.ComponentErrors[name]
+		if revs, ok := cerr.MissingComponents[compName]; ok {
+			rev, sets := requiredRevision(revs)
+			plan.Install = append(plan.Install, ValidationSetsEnforcementAction{
+				InstanceName:   name,
+				Component:      compName,
+				Revision:       rev,
+				ValidationSets: sets,
+			})
+			return
+		}
+		var currentRev snap.Revision
+		if csi := snapst.CurrentComponentSideInfo(naming.NewComponentRef(name, compName)); csi != nil {
+			currentRev = csi.Revision
+		}
+		rev, sets := requiredRevision(cerr.WrongRevisionComponents[compName])
+		plan.Refresh = append(plan.Refresh, ValidationSetsEnforcementAction{
+			InstanceName:   name,
+			Component:      compName,
+			Revision:       rev,
+			Current:        currentRev,
+			ValidationSets: sets,
+		})
+	}
+
+	for _, sn := range res.installs {
+		rev, sets := requiredRevision(valErr.MissingSnaps[sn.InstanceName])
+		plan.Install = append(plan.Install, ValidationSetsEnforcementAction{
+			InstanceName:   sn.InstanceName,
+			Revision:       rev,
+			ValidationSets: sets,
+		})
+		for _, compName := range sn.Components {
+			componentAction(nil, sn.InstanceName, compName)
+		}
+	}
+	for _, up := range append(res.essentialRefreshes, res.refreshes...) {
+		var snapst SnapState
+		if err := Get(st, up.InstanceName, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+			return nil, err
+		}
+		rev, sets := requiredRevision(valErr.WrongRevisionSnaps[up.InstanceName])
+		plan.Refresh = append(plan.Refresh, ValidationSetsEnforcementAction{
+			InstanceName:   up.InstanceName,
+			Revision:       rev,
+			Current:        snapst.Current,
+			ValidationSets: sets,
+		})
+		// components at the wrong revision are refreshed along
+		// with the snap
+		comps := up.AdditionalComponents
+		if cerr, ok := valErr.ComponentErrors[up.InstanceName]; ok {
+			comps = append(comps, keys(cerr.WrongRevisionComponents)...)
+		}
+		for _, compName := range comps {
+			componentAction(&snapst, up.InstanceName, compName)
+		}
+	}
+	for name, comps := range res.components {
+		var snapst SnapState
+		if err := Get(st, name, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+			return nil, err
+		}
+		for _, compName := range comps {
+			componentAction(&snapst, name, compName)
+		}
+	}
+
+	for name, sets := range valErr.InvalidSnaps {
+		sets = strutil.Deduplicate(sets)
+		sort.Strings(sets)
+		plan.Remove = append(plan.Remove, ValidationSetsEnforcementAction{
+			InstanceName:   name,
+			ValidationSets: sets,
+		})
+	}
+	for name, cerr := range valErr.ComponentErrors {
+		for compName, sets := range cerr.InvalidComponents {
+			sets = strutil.Deduplicate(sets)
+			sort.Strings(sets)
+			plan.Remove = append(plan.Remove, ValidationSetsEnforcementAction{
+				InstanceName:   name,
+				Component:      compName,
+				ValidationSets: sets,
+			})
+		}
+	}
+
+	for _, actions := range [][]ValidationSetsEnforcementAction{plan.Install, plan.Refresh, plan.Remove} {
+		sort.Slice(actions, func(i, j int) bool {
+			if actions[i].InstanceName != actions[j].InstanceName {
+				return actions[i].InstanceName < actions[j].InstanceName
+			}
+			return actions[i].Component < actions[j].Component
+		})
+	}
+	return plan, nil
+}
+
+// requiredRevision returns the revision required by the validation sets
+// in revs, unset if they require no specific revision, and the keys of
+// the validation sets.
+func requiredRevision(revs map[snap.Revision][]string) (snap.Revision, []string) {
+	var rev snap.Revision
+	var sets []string
+	for r, vss := range revs {
+		if !r.Unset() {
+			rev = r
+		}
+		sets = append(sets, vss...)
+	}
+	sets = strutil.Deduplicate(sets)
+	sort.Strings(sets)
+	return rev, sets
+}
+
 // flattenAndWaitTaskSets merges a slice of [state.TaskSet] slices into one flat
 // slice. It also enforces ordering so that every [state.Task] in chain[i] waits
 // for each [state.Task] in chain[i-1].
@@ -3092,6 +3292,12 @@ type AutoRefreshOptions struct {
 // snaps on the system. In addition to that it will also refresh important
 // assertions.
 func AutoRefresh(ctx context.Context, st *state.State) ([]string, *UpdateTaskSets, error) {
+	return autoRefreshFiltered(ctx, st, nil)
+}
+
+// autoRefreshFiltered is like AutoRefresh but only the installed snaps for
+// which filter returns true are considered, if it is set.
+func autoRefreshFiltered(ctx context.Context, st *state.State, filter updateFilter) ([]string, *UpdateTaskSets, error) {
 	userID := 0
 
 	if AutoRefreshAssertions != nil {
@@ -3109,14 +3315,14 @@ func AutoRefresh(ctx context.Context, st *state.State) ([]string, *UpdateTaskSet
 	}
 	if !gateAutoRefreshHook {
 		// old-style refresh (gate-auto-refresh-hook feature disabled)
-		return updateManyFiltered(ctx, st, nil, nil, userID, nil, &Flags{IsAutoRefresh: true}, "")
+		return updateManyFiltered(ctx, st, nil, nil, userID, filter, &Flags{IsAutoRefresh: true}, "")
 	}
 
 	// TODO: rename to autoRefreshTasks when old auto refresh logic gets removed.
 	// TODO2: pass "IsContinuedAutoRefresh" so that the SnapSetup of
 	//        gate-auto-refresh contains this field (required so that
 	//        the update-finished notifications work)
-	updated, tss, err := autoRefreshPhase1(ctx, st, "")
+	updated, tss, err := autoRefreshPhase1Filtered(ctx, st, "", filter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -3124,11 +3330,34 @@ func AutoRefresh(ctx context.Context, st *state.State) ([]string, *UpdateTaskSet
 	return updated, &UpdateTaskSets{Refresh: tss}, nil
 }
 
+// filteredSnapNames returns the sorted names of the given snaps for which
+// filter returns true.
+func filteredSnapNames(snaps map[string]*SnapState, filter updateFilter) ([]string, error) {
+	var names []string
+	for name, snapst := range snaps {
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			return nil, err
+		}
+		if filter(info, snapst) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // autoRefreshPhase1 creates gate-auto-refresh hooks and conditional-auto-refresh
 // task that initiates actual refresh. forGatingSnap is optional and limits auto-refresh
 // to the snaps affecting the given snap only; it defaults to all snaps if nil.
 // The state needs to be locked by the caller.
 func autoRefreshPhase1(ctx context.Context, st *state.State, forGatingSnap string) ([]string, []*state.TaskSet, error) {
+	return autoRefreshPhase1Filtered(ctx, st, forGatingSnap, nil)
+}
+
+// autoRefreshPhase1Filtered is like autoRefreshPhase1 but only the installed
+// snaps for which filter returns true are considered, if it is set.
+func autoRefreshPhase1Filtered(ctx context.Context, st *state.State, forGatingSnap string, filter updateFilter) ([]string, []*state.TaskSet, error) {
 	user, err := userFromUserID(st, 0)
 	if err != nil {
 		return nil, nil, err
@@ -3147,6 +3376,11 @@ func autoRefreshPhase1(ctx context.Context, st *state.State, forGatingSnap strin
 		// of errors?
 		return nil, nil, err
 	}
+	if filter != nil {
+		plan.filter(func(t target) (bool, error) {
+			return filter(t.info, &t.snapst), nil
+		})
+	}
 	deviceCtx, err := DeviceCtxFromState(st, nil)
 	if err != nil {
 		return nil, nil, err
@@ -3156,11 +3390,23 @@ func autoRefreshPhase1(ctx context.Context, st *state.State, forGatingSnap strin
 	if err != nil {
 		return nil, nil, err
 	}
-	updateRefreshCandidates(st, hints, nil)
+	if filter != nil {
+		// only the snaps let through by the filter were considered,
+		// keep the candidates of the other snaps and their gating
+		filtered, err := filteredSnapNames(allSnaps, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(filtered) > 0 {
+			updateRefreshCandidates(st, hints, filtered)
+		}
+	} else {
+		updateRefreshCandidates(st, hints, nil)
 
-	// prune affecting snaps that are not in refresh candidates from hold state.
-	if err := pruneGating(st, hints); err != nil {
-		return nil, nil, err
+		// prune affecting snaps that are not in refresh candidates from hold state.
+		if err := pruneGating(st, hints); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	updates := make([]string, 0, len(hints))
@@ -9980,6 +9980,77 @@ func (s *snapmgrTestSuite) TestResolveValidationSetsEnforcementErrorWithInvalidS
 	c.Assert(err, ErrorMatches, "cannot auto-resolve validation set constraints that require removing snaps: \"snap-a\"")
 }
 
+func (s *snapmgrTestSuite) TestPlanValidationSetsEnforcement(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "snap-c", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromRevisionSideInfos([]*sequence.RevisionSideState{
+			sequence.NewRevisionSideState(&snap.SideInfo{RealName: "snap-c", SnapID: "snap-c-id", Revision: snap.R(1)},
+				[]*sequence.ComponentState{
+					sequence.NewComponentState(&snap.ComponentSideInfo{
+						Component: naming.NewComponentRef("snap-c", "comp"),
+						Revision:  snap.R(4),
+					}, snap.StandardComponent),
+				}),
+		}),
+		Current: snap.R(1),
+		Active:  true,
+	})
+
+	valErr := &snapasserts.ValidationSetsValidationError{
+		MissingSnaps: map[string]map[snap.Revision][]string{
+			"snap-b": {snap.R(0): []string{"foo/baz", "foo/bar"}},
+			"snap-a": {snap.R(3): []string{"foo/bar"}},
+		},
+		WrongRevisionSnaps: map[string]map[snap.Revision][]string{"snap-c": {snap.R(2): []string{"foo/bar"}}},
+		ComponentErrors: map[string]*snapasserts.ValidationSetsComponentValidationError{
+			"snap-c": {
+				MissingComponents:       map[string]map[snap.Revision][]string{"other": {snap.R(6): []string{"foo/bar"}}},
+				WrongRevisionComponents: map[string]map[snap.Revision][]string{"comp": {snap.R(5): []string{"foo/bar"}}},
+			},
+		},
+	}
+
+	plan, err := snapstate.PlanValidationSetsEnforcement(s.state, valErr)
+	c.Assert(err, IsNil)
+	c.Check(plan, DeepEquals, &snapstate.ValidationSetsEnforcementPlan{
+		Install: []snapstate.ValidationSetsEnforcementAction{
+			{InstanceName: "snap-a", Revision: snap.R(3), ValidationSets: []string{"foo/bar"}},
+			{InstanceName: "snap-b", ValidationSets: []string{"foo/bar", "foo/baz"}},
+			{InstanceName: "snap-c", Component: "other", Revision: snap.R(6), ValidationSets: []string{"foo/bar"}},
+		},
+		Refresh: []snapstate.ValidationSetsEnforcementAction{
+			{InstanceName: "snap-c", Revision: snap.R(2), Current: snap.R(1), ValidationSets: []string{"foo/bar"}},
+			{InstanceName: "snap-c", Component: "comp", Revision: snap.R(5), Current: snap.R(4), ValidationSets: []string{"foo/bar"}},
+		},
+	})
+}
+
+func (s *snapmgrTestSuite) TestPlanValidationSetsEnforcementUnresolvable(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	valErr := &snapasserts.ValidationSetsValidationError{
+		InvalidSnaps: map[string][]string{"snap-a": {"foo/bar", "foo/bar"}},
+		ComponentErrors: map[string]*snapasserts.ValidationSetsComponentValidationError{
+			"snap-b": {
+				InvalidComponents: map[string][]string{"comp": {"foo/baz"}},
+			},
+		},
+	}
+
+	plan, err := snapstate.PlanValidationSetsEnforcement(s.state, valErr)
+	c.Assert(err, IsNil)
+	c.Check(plan.Install, HasLen, 0)
+	c.Check(plan.Refresh, HasLen, 0)
+	c.Check(plan.Remove, DeepEquals, []snapstate.ValidationSetsEnforcementAction{
+		{InstanceName: "snap-a", ValidationSets: []string{"foo/bar"}},
+		{InstanceName: "snap-b", Component: "comp", ValidationSets: []string{"foo/baz"}},
+	})
+	c.Check(plan.Unresolvable, ErrorMatches, `cannot auto-resolve validation set constraints that require removing snaps: "snap-a"`)
+}
+
 func (s *snapmgrTestSuite) TestEnsureSnapStateRewriteMounts(c *C) {
 	s.testEnsureSnapStateRewriteMounts(c, "app")
 }
@@ -1130,8 +1130,25 @@ func UpdateWithGoal(ctx context.Context, st *state.State, goal UpdateGoal, filte
 			return nil, nil, err
 		}
 
-		// TODO: why not check this error?
-		updateRefreshCandidates(st, hints, plan.requested)
+		if filter != nil && plan.refreshAll() {
+			// only the snaps let through by the filter were
+			// considered, keep the candidates of the other snaps
+			allSnaps, err := All(st)
+			if err != nil {
+				return nil, nil, err
+			}
+			filtered, err := filteredSnapNames(allSnaps, filter)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(filtered) > 0 {
+				// TODO: why not check this error?
+				updateRefreshCandidates(st, hints, filtered)
+			}
+		} else {
+			// TODO: why not check this error?
+			updateRefreshCandidates(st, hints, plan.requested)
+		}
 	}
 
 	// filter out snaps that have been gated by refresh control
@@ -307,11 +307,21 @@ const (
 	// expired. The key for interfaces-requests-rule-update notices is the
 	// rule ID.
 	InterfacesRequestsRuleUpdateNotice NoticeType = "interfaces-requests-rule-update"
+
+	// Recorded whenever a committed confdb transaction changes data visible
+	// through a view. The key for confdb-change notices is the view ID, in
+	// the format <account>/<confdb-schema>/<view>.
+	ConfdbChangeNotice NoticeType = "confdb-change"
+
+	// Recorded whenever a time-limited interface connection expires and
+	// is removed. The key for interface-connection-expired notices is the
+	// connection ID, in the format "<plug-snap>:<plug> <slot-snap>:<slot>".
+	InterfaceConnectionExpiredNotice NoticeType = "interface-connection-expired"
 )
 
 func (t NoticeType) Valid() bool {
 	switch t {
-	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice:
+	case ChangeUpdateNotice, WarningNotice, RefreshInhibitNotice, SnapRunInhibitNotice, InterfacesRequestsPromptNotice, InterfacesRequestsRuleUpdateNotice, ConfdbChangeNotice, InterfaceConnectionExpiredNotice:
 		return true
 	}
 	return false
@@ -453,6 +463,10 @@ type NoticeFilter struct {
 	// Keys, if not empty, includes only notices whose key is one of these.
 	Keys []string
 
+	// KeysByType, if not nil, includes notices of the types it has entries
+	// for only if their key is one of the keys of their type.
+	KeysByType map[NoticeType][]string
+
 	// After, if set, includes only notices that were last repeated after this time.
 	After time.Time
 
@@ -476,6 +490,9 @@ func (f *NoticeFilter) matches(n *Notice) bool {
 	if len(f.Keys) > 0 && !sliceContains(f.Keys, n.key) {
 		return false
 	}
+	if keys, ok := f.KeysByType[n.noticeType]; ok && !sliceContains(keys, n.key) {
+		return false
+	}
 	if !f.After.IsZero() && !n.lastRepeated.After(f.After) {
 		return false
 	}
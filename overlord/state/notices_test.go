@@ -535,6 +535,56 @@ func (s *noticesSuite) TestNoticesFilterKey(c *C) {
 	c.Check(n["key"], Equals, "foo.com/baz")
 }
 
+func (s *noticesSuite) TestNoticesFilterKeysByType(c *C) {
+	st := state.New(nil)
+
+	st.Lock()
+	addNotice(c, st, nil, state.WarningNotice, "foo.com/bar", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/setup-wifi", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/observe-wifi", nil)
+	st.Unlock()
+
+	// keys of other types are not restricted
+	notices := st.Notices(&state.NoticeFilter{KeysByType: map[state.NoticeType][]string{
+		state.ConfdbChangeNotice: {"acc/network/observe-wifi"},
+	}})
+	c.Assert(notices, HasLen, 2)
+	c.Check(noticeToMap(c, notices[0])["key"], Equals, "foo.com/bar")
+	c.Check(noticeToMap(c, notices[1])["key"], Equals, "acc/network/observe-wifi")
+
+	// no keys exclude all notices of the type
+	notices = st.Notices(&state.NoticeFilter{KeysByType: map[state.NoticeType][]string{
+		state.ConfdbChangeNotice: nil,
+	}})
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["key"], Equals, "foo.com/bar")
+
+	// waiting clients keep waiting for notices with the keys
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		st.Lock()
+		defer st.Unlock()
+		addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/observe-wifi", nil)
+		time.Sleep(10 * time.Millisecond)
+		addNotice(c, st, nil, state.ConfdbChangeNotice, "acc/network/control-wifi", nil)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	notices, err := st.WaitNotices(ctx, &state.NoticeFilter{
+		Types: []state.NoticeType{state.ConfdbChangeNotice},
+		After: notices[0].LastRepeated(),
+		KeysByType: map[state.NoticeType][]string{
+			state.ConfdbChangeNotice: {"acc/network/control-wifi"},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["key"], Equals, "acc/network/control-wifi")
+}
+
 func (s *noticesSuite) TestNoticesFilterAfter(c *C) {
 	st := state.New(nil)
 
@@ -234,3 +234,18 @@ func (c *Compiler) Compile(in, out string) error {
 	}
 	return nil
 }
+
+// SyscallName returns the name of the system call with the given number on
+// the given architecture. The architecture is named as in libseccomp, eg.
+// amd64, x86 or arm64.
+func (c *Compiler) SyscallName(arch string, nr int) (string, error) {
+	cmd := exec.Command(c.snapSeccomp, "syscall-name", arch, strconv.Itoa(nr))
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			output = exitErr.Stderr
+		}
+		return "", osutil.OutputErr(output, err)
+	}
+	return string(bytes.TrimSpace(output)), nil
+}
@@ -297,3 +297,30 @@ exit 1`)
 	_, err := seccomp.CompilerVersionInfo(fromCmd(c, cmd))
 	c.Assert(err, ErrorMatches, "this goes to stderr")
 }
+
+func (s *compilerSuite) TestSyscallName(c *C) {
+	cmd := testutil.MockCommand(c, "snap-seccomp", `echo mount`)
+	defer cmd.Restore()
+
+	compiler, err := seccomp.NewCompiler(fromCmd(c, cmd))
+	c.Assert(err, IsNil)
+
+	name, err := compiler.SyscallName("amd64", 165)
+	c.Assert(err, IsNil)
+	c.Check(name, Equals, "mount")
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"snap-seccomp", "syscall-name", "amd64", "165"},
+	})
+}
+
+func (s *compilerSuite) TestSyscallNameError(c *C) {
+	cmd := testutil.MockCommand(c, "snap-seccomp", `echo "error: cannot resolve system call 9999 on amd64" >&2; exit 1`)
+	defer cmd.Restore()
+
+	compiler, err := seccomp.NewCompiler(fromCmd(c, cmd))
+	c.Assert(err, IsNil)
+
+	name, err := compiler.SyscallName("amd64", 9999)
+	c.Assert(err, ErrorMatches, "error: cannot resolve system call 9999 on amd64")
+	c.Check(name, Equals, "")
+}
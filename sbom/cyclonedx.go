@@ -0,0 +1,204 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom
+
+import "time"
+
+// CycloneDXDocument is a CycloneDX 1.5 bill of materials in its JSON form.
+type CycloneDXDocument struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     CycloneDXMetadata     `json:"metadata"`
+	Components   []*CycloneDXComponent `json:"components"`
+	Dependencies []CycloneDXDependency `json:"dependencies"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+type CycloneDXTools struct {
+	Components []*CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	Type               string                       `json:"type"`
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	Publisher          string                       `json:"publisher,omitempty"`
+	Licenses           []CycloneDXLicense           `json:"licenses,omitempty"`
+	Hashes             []CycloneDXHash              `json:"hashes,omitempty"`
+	Properties         []CycloneDXProperty          `json:"properties,omitempty"`
+	ExternalReferences []CycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Components         []*CycloneDXComponent        `json:"components,omitempty"`
+}
+
+type CycloneDXLicense struct {
+	Expression string `json:"expression"`
+}
+
+type CycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CycloneDXExternalReference struct {
+	Type   string          `json:"type"`
+	URL    string          `json:"url"`
+	Hashes []CycloneDXHash `json:"hashes,omitempty"`
+}
+
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func cycloneDXRef(p *Package) string {
+	if p.IsComponent() {
+		return "snap-component:" + p.Name
+	}
+	return "snap:" + p.Name
+}
+
+// CycloneDX returns the bill of materials for doc as a CycloneDX 1.5
+// document. Components of snaps are nested in the ones of their snaps.
+func CycloneDX(doc *Document) *CycloneDXDocument {
+	cdoc := &CycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + doc.ID,
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []*CycloneDXComponent{{
+					Type:    "application",
+					Name:    "snapd",
+					Version: doc.ToolVersion,
+				}},
+			},
+		},
+		Components:   []*CycloneDXComponent{},
+		Dependencies: []CycloneDXDependency{},
+	}
+	if doc.Name != "" {
+		cdoc.Metadata.Component = &CycloneDXComponent{
+			Type: "device",
+			Name: doc.Name,
+		}
+	}
+
+	refs := make(map[string]string, len(doc.Packages))
+	for _, p := range doc.Packages {
+		refs[p.Name] = cycloneDXRef(p)
+	}
+
+	snaps := make(map[string]*CycloneDXComponent)
+	var components []*Package
+	for _, p := range doc.Packages {
+		if p.IsComponent() {
+			// add them once all snaps are known
+			components = append(components, p)
+			continue
+		}
+		c := cycloneDXComponent(p)
+		snaps[p.Name] = c
+		cdoc.Components = append(cdoc.Components, c)
+	}
+	for _, p := range components {
+		c := cycloneDXComponent(p)
+		if parent, ok := snaps[p.Snap]; ok {
+			parent.Components = append(parent.Components, c)
+		} else {
+			cdoc.Components = append(cdoc.Components, c)
+		}
+	}
+
+	for _, p := range doc.Packages {
+		dep := CycloneDXDependency{Ref: refs[p.Name]}
+		for _, name := range p.DependsOn {
+			if ref, ok := refs[name]; ok {
+				dep.DependsOn = append(dep.DependsOn, ref)
+			}
+		}
+		cdoc.Dependencies = append(cdoc.Dependencies, dep)
+	}
+	return cdoc
+}
+
+func cycloneDXComponent(p *Package) *CycloneDXComponent {
+	c := &CycloneDXComponent{
+		Type:      cycloneDXType(p),
+		BOMRef:    cycloneDXRef(p),
+		Name:      p.Name,
+		Version:   p.Version,
+		Publisher: p.Publisher,
+	}
+	if license, ok := declaredLicense(p); ok {
+		c.Licenses = []CycloneDXLicense{{Expression: license}}
+	}
+	if p.SHA3_384 != "" {
+		c.Hashes = []CycloneDXHash{{Alg: "SHA3-384", Content: p.SHA3_384}}
+	}
+	for _, prop := range []CycloneDXProperty{
+		{Name: "snap:type", Value: p.Type},
+		{Name: "snap:id", Value: p.SnapID},
+		{Name: "snap:revision", Value: p.Revision},
+	} {
+		if prop.Value != "" {
+			c.Properties = append(c.Properties, prop)
+		}
+	}
+	for _, f := range p.EmbeddedSBOMs {
+		ref := CycloneDXExternalReference{
+			Type: "bom",
+			URL:  "file://" + f.Path,
+		}
+		if f.SHA256 != "" {
+			ref.Hashes = []CycloneDXHash{{Alg: "SHA-256", Content: f.SHA256}}
+		}
+		c.ExternalReferences = append(c.ExternalReferences, ref)
+	}
+	return c
+}
+
+func cycloneDXType(p *Package) string {
+	switch p.Type {
+	case "app", "snapd":
+		return "application"
+	case "base", "os", "kernel":
+		return "operating-system"
+	case "gadget":
+		return "firmware"
+	case "kernel-modules":
+		return "device-driver"
+	}
+	return "library"
+}
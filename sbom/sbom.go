@@ -0,0 +1,138 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package sbom produces software bills of materials of the snaps and
+// components installed on a device, in the SPDX and CycloneDX JSON formats.
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/randutil"
+	"github.com/snapcore/snapd/spdx"
+)
+
+const (
+	// FormatSPDX is the SPDX 2.3 JSON format.
+	FormatSPDX = "spdx"
+	// FormatCycloneDX is the CycloneDX 1.5 JSON format.
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Document describes what goes into a bill of materials.
+type Document struct {
+	// ID uniquely identifies the document, a random UUID is used when
+	// it is empty.
+	ID string
+	// Name names the described system.
+	Name string
+	// Created is the creation time of the document.
+	Created time.Time
+	// ToolVersion is the version of snapd producing the document.
+	ToolVersion string
+	// Packages are the snaps and components in the bill of materials.
+	Packages []*Package
+}
+
+// Package describes a snap or a component.
+type Package struct {
+	// Name is the instance name of a snap or the full
+	// <snap instance>+<component> name of a component.
+	Name string
+	// Snap is the instance name of the snap of a component, it is
+	// empty for snaps.
+	Snap string
+	// Type is the snap or component type.
+	Type     string
+	Version  string
+	Revision string
+	SnapID   string
+	// Publisher is the name of the publisher of the snap, if known.
+	Publisher string
+	// License is the license declared in snap.yaml.
+	License string
+	// SHA3_384 is the hex encoded sha3-384 digest of the snap or
+	// component file, as found in its assertions.
+	SHA3_384 string
+	// DependsOn lists the names of the other packages this one
+	// depends on, like its base or content providers.
+	DependsOn []string
+	// EmbeddedSBOMs are the bills of materials shipped in the package.
+	EmbeddedSBOMs []File
+}
+
+// IsComponent returns whether the package is a component.
+func (p *Package) IsComponent() bool {
+	return p.Snap != ""
+}
+
+// File is a file shipped in a package.
+type File struct {
+	Path string
+	// SHA256 is the hex encoded sha256 digest of the file.
+	SHA256 string
+}
+
+// Generate returns the bill of materials for doc in the given format,
+// ready to be marshalled to JSON.
+func Generate(doc *Document, format string) (any, error) {
+	if doc.ID == "" {
+		id, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		doc.ID = id
+	}
+	switch format {
+	case FormatSPDX:
+		return SPDX(doc), nil
+	case FormatCycloneDX:
+		return CycloneDX(doc), nil
+	}
+	return nil, fmt.Errorf("unsupported SBOM format %q", format)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	b, err := randutil.CryptoTokenBytes(16)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate document id: %v", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// declaredLicense returns the license of the package if it is a valid
+// SPDX license expression.
+func declaredLicense(p *Package) (string, bool) {
+	if p.License == "" || spdx.ValidateLicense(p.License) != nil {
+		return "", false
+	}
+	return p.License, true
+}
+
+func toolName(doc *Document) string {
+	if doc.ToolVersion == "" {
+		return "snapd"
+	}
+	return "snapd-" + strings.ReplaceAll(doc.ToolVersion, " ", "-")
+}
@@ -0,0 +1,182 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/sbom"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type sbomSuite struct{}
+
+var _ = Suite(&sbomSuite{})
+
+func testDocument() *sbom.Document {
+	return &sbom.Document{
+		ID:          "5f1b3c7e-2a44-4d1e-9d8a-7b4f0e6c1a22",
+		Name:        "pc",
+		Created:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		ToolVersion: "2.70",
+		Packages: []*sbom.Package{{
+			Name:      "core22",
+			Type:      "base",
+			Version:   "20260901",
+			Revision:  "2000",
+			SnapID:    "core22-id",
+			Publisher: "Canonical",
+			SHA3_384:  "abcd",
+		}, {
+			Name:      "foo_inst",
+			Type:      "app",
+			Version:   "1.0",
+			Revision:  "x1",
+			License:   "GPL-3.0 OR MIT",
+			DependsOn: []string{"core22", "gtk-common-themes"},
+			EmbeddedSBOMs: []sbom.File{{
+				Path:   "/snap/foo_inst/x1/meta/sbom/foo.spdx.json",
+				SHA256: "1234",
+			}},
+		}, {
+			Name:     "foo_inst+comp",
+			Snap:     "foo_inst",
+			Type:     "standard",
+			Version:  "1.0",
+			Revision: "x2",
+			License:  "not a license",
+		}},
+	}
+}
+
+func (s *sbomSuite) TestSPDX(c *C) {
+	doc := sbom.SPDX(testDocument())
+
+	c.Check(doc.SPDXVersion, Equals, "SPDX-2.3")
+	c.Check(doc.DocumentNamespace, Equals, "https://snapcraft.io/spdxdocs/pc-5f1b3c7e-2a44-4d1e-9d8a-7b4f0e6c1a22")
+	c.Check(doc.CreationInfo, DeepEquals, sbom.SPDXCreationInfo{
+		Created:  "2026-10-19T12:00:00Z",
+		Creators: []string{"Tool: snapd-2.70"},
+	})
+	c.Assert(doc.Packages, HasLen, 3)
+
+	core := doc.Packages[0]
+	c.Check(core.SPDXID, Equals, "SPDXRef-Snap-core22")
+	c.Check(core.Supplier, Equals, "Organization: Canonical")
+	c.Check(core.LicenseDeclared, Equals, "NOASSERTION")
+	c.Check(core.PrimaryPurpose, Equals, "OPERATING-SYSTEM")
+	c.Check(core.Checksums, DeepEquals, []sbom.SPDXChecksum{{Algorithm: "SHA3-384", ChecksumValue: "abcd"}})
+	c.Check(core.ExternalRefs, DeepEquals, []sbom.SPDXExternalRef{
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "snap-id", ReferenceLocator: "core22-id"},
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "snap-revision", ReferenceLocator: "2000"},
+	})
+
+	foo := doc.Packages[1]
+	c.Check(foo.SPDXID, Equals, "SPDXRef-Snap-foo.inst")
+	c.Check(foo.Supplier, Equals, "NOASSERTION")
+	c.Check(foo.LicenseDeclared, Equals, "GPL-3.0 OR MIT")
+	c.Check(foo.Checksums, HasLen, 0)
+	c.Check(foo.ExternalRefs, DeepEquals, []sbom.SPDXExternalRef{
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "snap-revision", ReferenceLocator: "x1"},
+		{ReferenceCategory: "OTHER", ReferenceType: "sbom", ReferenceLocator: "/snap/foo_inst/x1/meta/sbom/foo.spdx.json"},
+	})
+
+	comp := doc.Packages[2]
+	c.Check(comp.SPDXID, Equals, "SPDXRef-Component-foo.inst.comp")
+	c.Check(comp.LicenseDeclared, Equals, "NOASSERTION")
+
+	// the dependency that is not installed is left out
+	c.Check(doc.Relationships, DeepEquals, []sbom.SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Snap-core22"},
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Snap-foo.inst"},
+		{SPDXElementID: "SPDXRef-Snap-foo.inst", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Snap-core22"},
+		{SPDXElementID: "SPDXRef-Snap-foo.inst", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Component-foo.inst.comp"},
+	})
+}
+
+func (s *sbomSuite) TestCycloneDX(c *C) {
+	doc := sbom.CycloneDX(testDocument())
+
+	c.Check(doc.BOMFormat, Equals, "CycloneDX")
+	c.Check(doc.SpecVersion, Equals, "1.5")
+	c.Check(doc.SerialNumber, Equals, "urn:uuid:5f1b3c7e-2a44-4d1e-9d8a-7b4f0e6c1a22")
+	c.Check(doc.Metadata.Timestamp, Equals, "2026-10-19T12:00:00Z")
+	c.Check(doc.Metadata.Tools.Components[0].Version, Equals, "2.70")
+	c.Check(doc.Metadata.Component.Name, Equals, "pc")
+
+	// the component is nested in its snap
+	c.Assert(doc.Components, HasLen, 2)
+	c.Check(doc.Components[0], DeepEquals, &sbom.CycloneDXComponent{
+		Type:      "operating-system",
+		BOMRef:    "snap:core22",
+		Name:      "core22",
+		Version:   "20260901",
+		Publisher: "Canonical",
+		Hashes:    []sbom.CycloneDXHash{{Alg: "SHA3-384", Content: "abcd"}},
+		Properties: []sbom.CycloneDXProperty{
+			{Name: "snap:type", Value: "base"},
+			{Name: "snap:id", Value: "core22-id"},
+			{Name: "snap:revision", Value: "2000"},
+		},
+	})
+	foo := doc.Components[1]
+	c.Check(foo.Type, Equals, "application")
+	c.Check(foo.Licenses, DeepEquals, []sbom.CycloneDXLicense{{Expression: "GPL-3.0 OR MIT"}})
+	c.Check(foo.ExternalReferences, DeepEquals, []sbom.CycloneDXExternalReference{{
+		Type:   "bom",
+		URL:    "file:///snap/foo_inst/x1/meta/sbom/foo.spdx.json",
+		Hashes: []sbom.CycloneDXHash{{Alg: "SHA-256", Content: "1234"}},
+	}})
+	c.Assert(foo.Components, HasLen, 1)
+	c.Check(foo.Components[0].BOMRef, Equals, "snap-component:foo_inst+comp")
+	c.Check(foo.Components[0].Licenses, HasLen, 0)
+
+	c.Check(doc.Dependencies, DeepEquals, []sbom.CycloneDXDependency{
+		{Ref: "snap:core22"},
+		{Ref: "snap:foo_inst", DependsOn: []string{"snap:core22"}},
+		{Ref: "snap-component:foo_inst+comp"},
+	})
+}
+
+func (s *sbomSuite) TestGenerate(c *C) {
+	doc := testDocument()
+	doc.ID = ""
+
+	res, err := sbom.Generate(doc, sbom.FormatSPDX)
+	c.Assert(err, IsNil)
+	c.Check(res, FitsTypeOf, &sbom.SPDXDocument{})
+	c.Check(doc.ID, Matches, "[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}")
+
+	res, err = sbom.Generate(doc, sbom.FormatCycloneDX)
+	c.Assert(err, IsNil)
+	c.Check(res, FitsTypeOf, &sbom.CycloneDXDocument{})
+	b, err := json.Marshal(res)
+	c.Assert(err, IsNil)
+	c.Check(string(b), testutil.Contains, `"bomFormat":"CycloneDX"`)
+
+	_, err = sbom.Generate(doc, "swid")
+	c.Check(err, ErrorMatches, `unsupported SBOM format "swid"`)
+}
@@ -0,0 +1,197 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sbom
+
+import (
+	"strings"
+	"time"
+)
+
+// SPDXDocument is an SPDX 2.3 document in its JSON form.
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Supplier         string            `json:"supplier"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	PrimaryPurpose   string            `json:"primaryPurpose,omitempty"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const (
+	spdxNoAssertion = "NOASSERTION"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+)
+
+// spdxID returns the SPDX identifier of the package, the characters
+// allowed in them are letters, numbers, "." and "-".
+func spdxID(p *Package) string {
+	kind := "Snap"
+	if p.IsComponent() {
+		kind = "Component"
+	}
+	return "SPDXRef-" + kind + "-" + strings.NewReplacer("_", ".", "+", ".").Replace(p.Name)
+}
+
+// SPDX returns the bill of materials for doc as an SPDX 2.3 document.
+func SPDX(doc *Document) *SPDXDocument {
+	sdoc := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              doc.Name,
+		DocumentNamespace: "https://snapcraft.io/spdxdocs/" + doc.Name + "-" + doc.ID,
+		CreationInfo: SPDXCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName(doc)},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	ids := make(map[string]string, len(doc.Packages))
+	for _, p := range doc.Packages {
+		ids[p.Name] = spdxID(p)
+	}
+
+	for _, p := range doc.Packages {
+		id := ids[p.Name]
+		spkg := SPDXPackage{
+			SPDXID:           id,
+			Name:             p.Name,
+			VersionInfo:      p.Version,
+			Supplier:         spdxNoAssertion,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			PrimaryPurpose:   spdxPurpose(p),
+		}
+		if p.Publisher != "" {
+			spkg.Supplier = "Organization: " + p.Publisher
+		}
+		if license, ok := declaredLicense(p); ok {
+			spkg.LicenseDeclared = license
+		}
+		if p.SHA3_384 != "" {
+			spkg.Checksums = []SPDXChecksum{{Algorithm: "SHA3-384", ChecksumValue: p.SHA3_384}}
+		}
+		if p.SnapID != "" {
+			spkg.ExternalRefs = append(spkg.ExternalRefs, SPDXExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "snap-id",
+				ReferenceLocator:  p.SnapID,
+			})
+		}
+		if p.Revision != "" {
+			spkg.ExternalRefs = append(spkg.ExternalRefs, SPDXExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "snap-revision",
+				ReferenceLocator:  p.Revision,
+			})
+		}
+		for _, f := range p.EmbeddedSBOMs {
+			spkg.ExternalRefs = append(spkg.ExternalRefs, SPDXExternalRef{
+				ReferenceCategory: "OTHER",
+				ReferenceType:     "sbom",
+				ReferenceLocator:  f.Path,
+			})
+		}
+		sdoc.Packages = append(sdoc.Packages, spkg)
+
+		if p.IsComponent() {
+			if snapID, ok := ids[p.Snap]; ok {
+				sdoc.Relationships = append(sdoc.Relationships, SPDXRelationship{
+					SPDXElementID:      snapID,
+					RelationshipType:   "CONTAINS",
+					RelatedSPDXElement: id,
+				})
+			}
+		} else {
+			sdoc.Relationships = append(sdoc.Relationships, SPDXRelationship{
+				SPDXElementID:      spdxDocumentID,
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: id,
+			})
+		}
+		for _, dep := range p.DependsOn {
+			depID, ok := ids[dep]
+			if !ok {
+				continue
+			}
+			sdoc.Relationships = append(sdoc.Relationships, SPDXRelationship{
+				SPDXElementID:      id,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: depID,
+			})
+		}
+	}
+	return sdoc
+}
+
+func spdxPurpose(p *Package) string {
+	switch p.Type {
+	case "app", "snapd":
+		return "APPLICATION"
+	case "base", "os", "kernel":
+		return "OPERATING-SYSTEM"
+	case "gadget":
+		return "FIRMWARE"
+	}
+	return "LIBRARY"
+}
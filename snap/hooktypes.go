@@ -44,6 +44,7 @@ var supportedHooks = []*HookType{
 	NewHookType(regexp.MustCompile("^query-view-.+$")),
 	NewHookType(regexp.MustCompile("^load-view-.+$")),
 	NewHookType(regexp.MustCompile("^observe-view-.+$")),
+	NewHookType(regexp.MustCompile("^migrate-view-.+$")),
 }
 
 var supportedComponentHooks = []*HookType{
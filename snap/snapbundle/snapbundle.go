@@ -0,0 +1,279 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package snapbundle reads and writes offline bundles: tar archives holding
+// snaps, their components and the assertions for them, described by a
+// manifest. The bundle itself is not signed, its content is verified against
+// the assertions it carries when it is installed.
+package snapbundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+// ManifestName is the name of the manifest, which is the first entry of a
+// bundle.
+const ManifestName = "manifest.json"
+
+// maxManifestSize is the maximum size of a manifest.
+const maxManifestSize = 1024 * 1024
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Snaps []Snap `json:"snaps"`
+}
+
+// Snap describes a snap in a bundle.
+type Snap struct {
+	Name     string        `json:"name"`
+	Revision snap.Revision `json:"revision"`
+	// File is the name of the snap file in the bundle.
+	File string `json:"file"`
+	// Assertions is the name of the file in the bundle with the
+	// assertions for the snap and its components.
+	Assertions string      `json:"assertions"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// Component describes a component of a snap in a bundle.
+type Component struct {
+	Name string `json:"name"`
+	// File is the name of the component file in the bundle.
+	File string `json:"file"`
+}
+
+// Files returns the names of all the files in the bundle besides the
+// manifest, in the order they are stored.
+func (m *Manifest) Files() []string {
+	var files []string
+	for _, sn := range m.Snaps {
+		files = append(files, sn.Assertions, sn.File)
+		for _, comp := range sn.Components {
+			files = append(files, comp.File)
+		}
+	}
+	return files
+}
+
+func validateFileName(name string) error {
+	if name == "" {
+		return errors.New("file name cannot be empty")
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." || name == ManifestName {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
+
+// Validate checks that the manifest describes a bundle with at least one
+// snap, without duplicated snaps or files.
+func (m *Manifest) Validate() error {
+	if len(m.Snaps) == 0 {
+		return errors.New("bundle must contain at least one snap")
+	}
+	seenSnaps := make(map[string]bool, len(m.Snaps))
+	for _, sn := range m.Snaps {
+		if err := naming.ValidateSnap(sn.Name); err != nil {
+			return err
+		}
+		if seenSnaps[sn.Name] {
+			return fmt.Errorf("snap %q is listed more than once", sn.Name)
+		}
+		seenSnaps[sn.Name] = true
+		if sn.Revision.Unset() {
+			return fmt.Errorf("revision of snap %q must be set", sn.Name)
+		}
+		seenComps := make(map[string]bool, len(sn.Components))
+		for _, comp := range sn.Components {
+			if err := naming.ValidateSnap(comp.Name); err != nil {
+				return fmt.Errorf("invalid component name for snap %q: %v", sn.Name, err)
+			}
+			if seenComps[comp.Name] {
+				return fmt.Errorf("component %q of snap %q is listed more than once", comp.Name, sn.Name)
+			}
+			seenComps[comp.Name] = true
+		}
+	}
+	seenFiles := make(map[string]bool)
+	for _, name := range m.Files() {
+		if err := validateFileName(name); err != nil {
+			return err
+		}
+		if seenFiles[name] {
+			return fmt.Errorf("file %q is listed more than once", name)
+		}
+		seenFiles[name] = true
+	}
+	return nil
+}
+
+// Write writes to w a bundle with the given manifest and the files it lists,
+// which are read from dir.
+func Write(w io.Writer, m *Manifest, dir string) error {
+	if err := m.Validate(); err != nil {
+		return fmt.Errorf("invalid bundle manifest: %v", err)
+	}
+	manifest, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     ManifestName,
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(manifest)),
+	}); err != nil {
+		return fmt.Errorf("cannot write bundle: %v", err)
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return fmt.Errorf("cannot write bundle: %v", err)
+	}
+	for _, name := range m.Files() {
+		if err := writeFile(tw, name, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("cannot write bundle: %v", err)
+	}
+	return nil
+}
+
+func writeFile(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot add %q to bundle: %v", name, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot add %q to bundle: %v", name, err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     fi.Size(),
+	}); err != nil {
+		return fmt.Errorf("cannot add %q to bundle: %v", name, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("cannot add %q to bundle: %v", name, err)
+	}
+	return nil
+}
+
+// Extract reads a bundle from r. Each file listed in its manifest is written
+// to the file returned by create for its name. It returns the manifest and
+// the paths of the files the content was written to, keyed by their name in
+// the bundle. On error the files that were written are removed.
+func Extract(r io.Reader, create func(name string) (*os.File, error)) (m *Manifest, paths map[string]string, err error) {
+	tr := tar.NewReader(r)
+	hdr, err := tr.Next()
+	if err == io.EOF {
+		return nil, nil, errors.New("invalid bundle: bundle is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read bundle: %v", err)
+	}
+	if hdr.Name != ManifestName {
+		return nil, nil, fmt.Errorf("invalid bundle: expected %q as first entry, got %q", ManifestName, hdr.Name)
+	}
+	if hdr.Size > maxManifestSize {
+		return nil, nil, errors.New("invalid bundle: manifest is too big")
+	}
+	dec := json.NewDecoder(tr)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle: cannot decode manifest: %v", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle: %v", err)
+	}
+
+	expected := make(map[string]bool)
+	for _, name := range m.Files() {
+		expected[name] = true
+	}
+	extracted := make(map[string]string, len(expected))
+	defer func() {
+		if err != nil {
+			for _, path := range extracted {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read bundle: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !expected[hdr.Name] {
+			return nil, nil, fmt.Errorf("invalid bundle: unexpected entry %q", hdr.Name)
+		}
+		if _, ok := extracted[hdr.Name]; ok {
+			return nil, nil, fmt.Errorf("invalid bundle: duplicated entry %q", hdr.Name)
+		}
+		path, err := extractFile(tr, hdr.Name, create)
+		if path != "" {
+			extracted[hdr.Name] = path
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, name := range m.Files() {
+		if _, ok := extracted[name]; !ok {
+			return nil, nil, fmt.Errorf("invalid bundle: missing %q", name)
+		}
+	}
+	return m, extracted, nil
+}
+
+func extractFile(r io.Reader, name string, create func(name string) (*os.File, error)) (path string, err error) {
+	f, err := create(name)
+	if err != nil {
+		return "", fmt.Errorf("cannot extract %q from bundle: %v", name, err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("cannot extract %q from bundle: %v", name, cerr)
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		return f.Name(), fmt.Errorf("cannot extract %q from bundle: %v", name, err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,190 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapbundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapbundle"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type snapbundleSuite struct {
+	dir string
+}
+
+var _ = Suite(&snapbundleSuite{})
+
+func (s *snapbundleSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+}
+
+func (s *snapbundleSuite) manifest(c *C) *snapbundle.Manifest {
+	m := &snapbundle.Manifest{
+		Snaps: []snapbundle.Snap{{
+			Name:       "foo",
+			Revision:   snap.R(3),
+			File:       "foo_3.snap",
+			Assertions: "foo_3.assert",
+			Components: []snapbundle.Component{{
+				Name: "comp",
+				File: "foo+comp_7.comp",
+			}},
+		}, {
+			Name:       "bar",
+			Revision:   snap.R(5),
+			File:       "bar_5.snap",
+			Assertions: "bar_5.assert",
+		}},
+	}
+	for _, name := range m.Files() {
+		c.Assert(os.WriteFile(filepath.Join(s.dir, name), []byte("content of "+name), 0644), IsNil)
+	}
+	return m
+}
+
+func (s *snapbundleSuite) extractTo(dir string) func(name string) (*os.File, error) {
+	return func(name string) (*os.File, error) {
+		return os.Create(filepath.Join(dir, "extracted-"+name))
+	}
+}
+
+func (s *snapbundleSuite) TestWriteExtract(c *C) {
+	m := s.manifest(c)
+	c.Check(m.Files(), DeepEquals, []string{"foo_3.assert", "foo_3.snap", "foo+comp_7.comp", "bar_5.assert", "bar_5.snap"})
+
+	var buf bytes.Buffer
+	c.Assert(snapbundle.Write(&buf, m, s.dir), IsNil)
+
+	target := c.MkDir()
+	extracted, paths, err := snapbundle.Extract(&buf, s.extractTo(target))
+	c.Assert(err, IsNil)
+	c.Check(extracted, DeepEquals, m)
+	c.Assert(paths, HasLen, 5)
+	for _, name := range m.Files() {
+		c.Check(paths[name], Equals, filepath.Join(target, "extracted-"+name))
+		c.Check(paths[name], testutil.FileEquals, "content of "+name)
+	}
+}
+
+func (s *snapbundleSuite) TestWriteMissingFile(c *C) {
+	m := s.manifest(c)
+	c.Assert(os.Remove(filepath.Join(s.dir, "bar_5.snap")), IsNil)
+
+	var buf bytes.Buffer
+	err := snapbundle.Write(&buf, m, s.dir)
+	c.Check(err, ErrorMatches, `cannot add "bar_5.snap" to bundle: open .*: no such file or directory`)
+}
+
+func (s *snapbundleSuite) TestValidate(c *C) {
+	for _, t := range []struct {
+		mod func(m *snapbundle.Manifest)
+		err string
+	}{
+		{func(m *snapbundle.Manifest) { m.Snaps = nil }, "bundle must contain at least one snap"},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].Name = "Bar" }, `invalid snap name: "Bar"`},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].Name = "foo" }, `snap "foo" is listed more than once`},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].Revision = snap.Revision{} }, `revision of snap "bar" must be set`},
+		{func(m *snapbundle.Manifest) { m.Snaps[0].Components[0].Name = "comp_1" }, `invalid component name for snap "foo": invalid snap name: "comp_1"`},
+		{func(m *snapbundle.Manifest) {
+			m.Snaps[0].Components = append(m.Snaps[0].Components, m.Snaps[0].Components[0])
+		}, `component "comp" of snap "foo" is listed more than once`},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].File = "" }, "file name cannot be empty"},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].File = "../bar_5.snap" }, `invalid file name "../bar_5.snap"`},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].File = "manifest.json" }, `invalid file name "manifest.json"`},
+		{func(m *snapbundle.Manifest) { m.Snaps[1].Assertions = "foo_3.assert" }, `file "foo_3.assert" is listed more than once`},
+	} {
+		m := s.manifest(c)
+		t.mod(m)
+		c.Check(m.Validate(), ErrorMatches, t.err)
+	}
+}
+
+type tarEntry struct {
+	name    string
+	content string
+	typ     byte
+}
+
+func makeTar(c *C, entries ...tarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		typ := e.typ
+		if typ == 0 {
+			typ = tar.TypeReg
+		}
+		c.Assert(tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: typ, Mode: 0644, Size: int64(len(e.content))}), IsNil)
+		_, err := tw.Write([]byte(e.content))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	return &buf
+}
+
+const testManifest = `{"snaps":[{"name":"foo","revision":"3","file":"foo_3.snap","assertions":"foo_3.assert"}]}`
+
+func (s *snapbundleSuite) TestExtractErrors(c *C) {
+	for _, t := range []struct {
+		entries []tarEntry
+		err     string
+	}{
+		{nil, "invalid bundle: bundle is empty"},
+		{[]tarEntry{{name: "foo_3.snap"}}, `invalid bundle: expected "manifest.json" as first entry, got "foo_3.snap"`},
+		{[]tarEntry{{name: "manifest.json", content: "{"}}, "invalid bundle: cannot decode manifest: unexpected EOF"},
+		{[]tarEntry{{name: "manifest.json", content: `{"snaps":[],"extra":1}`}}, `invalid bundle: cannot decode manifest: json: unknown field "extra"`},
+		{[]tarEntry{{name: "manifest.json", content: `{"snaps":[]}`}}, "invalid bundle: bundle must contain at least one snap"},
+		{[]tarEntry{
+			{name: "manifest.json", content: testManifest},
+			{name: "foo_3.snap", content: "snap"},
+		}, `invalid bundle: missing "foo_3.assert"`},
+		{[]tarEntry{
+			{name: "manifest.json", content: testManifest},
+			{name: "foo_3.snap", content: "snap"},
+			{name: "other", content: "other"},
+		}, `invalid bundle: unexpected entry "other"`},
+		{[]tarEntry{
+			{name: "manifest.json", content: testManifest},
+			{name: "foo_3.snap", content: "snap"},
+			{name: "foo_3.snap", content: "snap"},
+		}, `invalid bundle: duplicated entry "foo_3.snap"`},
+		{[]tarEntry{
+			{name: "manifest.json", content: testManifest},
+			{name: "foo_3.assert", typ: tar.TypeSymlink},
+		}, `invalid bundle: unexpected entry "foo_3.assert"`},
+	} {
+		target := c.MkDir()
+		_, _, err := snapbundle.Extract(makeTar(c, t.entries...), s.extractTo(target))
+		c.Check(err, ErrorMatches, t.err)
+		// nothing is left behind
+		files, err := os.ReadDir(target)
+		c.Assert(err, IsNil)
+		c.Check(files, HasLen, 0)
+	}
+}
@@ -0,0 +1,81 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package snapdelta generates and applies xdelta3 deltas between snap files,
+// for updates of sideloaded snaps that are shipped as deltas.
+package snapdelta
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+// Format is the format of the deltas, as for the ones from the store.
+const Format = "xdelta3"
+
+var commandFromSystemSnap = snapdtool.CommandFromSystemSnap
+
+// xdelta3Cmd returns the command running xdelta3 with the given arguments,
+// the one from the snapd or core snap is preferred to the one of the host.
+func xdelta3Cmd(args ...string) (*exec.Cmd, error) {
+	if cmd, err := commandFromSystemSnap("/usr/bin/xdelta3", args...); err == nil {
+		return cmd, nil
+	}
+	loc, err := exec.LookPath("xdelta3")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find xdelta3: %v", err)
+	}
+	return exec.Command(loc, args...), nil
+}
+
+func runXdelta3(args ...string) error {
+	cmd, err := xdelta3Cmd(args...)
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return osutil.OutputErr(output, err)
+	}
+	return nil
+}
+
+// Generate writes to deltaPath the delta that turns the snap at sourcePath
+// into the one at targetPath.
+func Generate(sourcePath, targetPath, deltaPath string) error {
+	if err := runXdelta3("-e", "-f", "-s", sourcePath, targetPath, deltaPath); err != nil {
+		os.Remove(deltaPath)
+		return fmt.Errorf("cannot generate delta from %q: %v", sourcePath, err)
+	}
+	return nil
+}
+
+// Apply writes to targetPath the snap obtained by applying the delta at
+// deltaPath to the snap at sourcePath. The result must be verified by the
+// caller.
+func Apply(sourcePath, deltaPath, targetPath string) error {
+	if err := runXdelta3("-d", "-f", "-s", sourcePath, deltaPath, targetPath); err != nil {
+		os.Remove(targetPath)
+		return fmt.Errorf("cannot apply delta to %q: %v", sourcePath, err)
+	}
+	return nil
+}
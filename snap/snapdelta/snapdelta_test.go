@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdelta_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap/snapdelta"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type snapdeltaSuite struct {
+	testutil.BaseTest
+
+	dir string
+}
+
+var _ = Suite(&snapdeltaSuite{})
+
+func (s *snapdeltaSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.AddCleanup(snapdelta.MockCommandFromSystemSnap(func(name string, cmdArgs ...string) (*exec.Cmd, error) {
+		return nil, errors.New("no system snap")
+	}))
+}
+
+func (s *snapdeltaSuite) TestGenerate(c *C) {
+	// the last argument is the output
+	xdelta3 := testutil.MockCommand(c, "xdelta3", `echo delta > "${@: -1}"`)
+	defer xdelta3.Restore()
+
+	src := filepath.Join(s.dir, "foo_1.snap")
+	target := filepath.Join(s.dir, "foo_2.snap")
+	delta := filepath.Join(s.dir, "foo_2.xdelta3")
+	c.Assert(snapdelta.Generate(src, target, delta), IsNil)
+
+	c.Check(xdelta3.Calls(), DeepEquals, [][]string{
+		{"xdelta3", "-e", "-f", "-s", src, target, delta},
+	})
+	c.Check(delta, testutil.FileEquals, "delta\n")
+}
+
+func (s *snapdeltaSuite) TestApply(c *C) {
+	xdelta3 := testutil.MockCommand(c, "xdelta3", `echo snap > "${@: -1}"`)
+	defer xdelta3.Restore()
+
+	src := filepath.Join(s.dir, "foo_1.snap")
+	delta := filepath.Join(s.dir, "foo_2.xdelta3")
+	target := filepath.Join(s.dir, "foo_2.snap")
+	c.Assert(snapdelta.Apply(src, delta, target), IsNil)
+
+	c.Check(xdelta3.Calls(), DeepEquals, [][]string{
+		{"xdelta3", "-d", "-f", "-s", src, delta, target},
+	})
+	c.Check(target, testutil.FileEquals, "snap\n")
+}
+
+func (s *snapdeltaSuite) TestApplyFails(c *C) {
+	xdelta3 := testutil.MockCommand(c, "xdelta3", `echo partial > "${@: -1}"; echo "checksum mismatch" >&2; exit 1`)
+	defer xdelta3.Restore()
+
+	src := filepath.Join(s.dir, "foo_1.snap")
+	target := filepath.Join(s.dir, "foo_2.snap")
+	err := snapdelta.Apply(src, filepath.Join(s.dir, "foo_2.xdelta3"), target)
+	c.Assert(err, ErrorMatches, `cannot apply delta to ".*/foo_1.snap": checksum mismatch`)
+	c.Check(target, testutil.FileAbsent)
+}
+
+func (s *snapdeltaSuite) TestFromSystemSnap(c *C) {
+	var calls [][]string
+	restore := snapdelta.MockCommandFromSystemSnap(func(name string, cmdArgs ...string) (*exec.Cmd, error) {
+		calls = append(calls, append([]string{name}, cmdArgs...))
+		return exec.Command("true"), nil
+	})
+	defer restore()
+
+	err := snapdelta.Generate("src", "target", "delta")
+	c.Assert(err, IsNil)
+	c.Check(calls, DeepEquals, [][]string{
+		{"/usr/bin/xdelta3", "-e", "-f", "-s", "src", "target", "delta"},
+	})
+}
+
+func (s *snapdeltaSuite) TestNoXdelta3(c *C) {
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", s.dir)
+	defer os.Setenv("PATH", oldPath)
+
+	err := snapdelta.Generate("src", "target", "delta")
+	c.Assert(err, ErrorMatches, `cannot generate delta from "src": cannot find xdelta3: .*`)
+}
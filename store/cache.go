@@ -166,6 +166,28 @@ func (cm *CacheManager) Put(cacheKey, sourcePath string) error {
 	return cm.opportunisticCleanup()
 }
 
+// Keys returns the keys of the content in the cache.
+func (cm *CacheManager) Keys() ([]string, error) {
+	cm.cleanupLock.RLock()
+	defer cm.cleanupLock.RUnlock()
+
+	entries, err := os.ReadDir(cm.cacheDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			keys = append(keys, entry.Name())
+		}
+	}
+	return keys, nil
+}
+
 // count returns the number of items in the cache
 func (cm *CacheManager) count() int {
 	// TODO: Use something more effective than a list of all entries
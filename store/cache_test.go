@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
@@ -114,6 +115,18 @@ func (s *cacheSuite) TestGet(c *C) {
 	c.Assert(targetPath, testutil.FileEquals, canary)
 }
 
+func (s *cacheSuite) TestKeys(c *C) {
+	keys, err := store.NewCacheManager(filepath.Join(s.tmp, "missing"), 1).Keys()
+	c.Assert(err, IsNil)
+	c.Check(keys, HasLen, 0)
+
+	cacheKeys, _ := s.makeTestFiles(c, 3)
+	keys, err = s.cm.Keys()
+	c.Assert(err, IsNil)
+	sort.Strings(keys)
+	c.Check(keys, DeepEquals, cacheKeys)
+}
+
 func (s *cacheSuite) makeTestFiles(c *C, n int) (cacheKeys []string, testFiles []string) {
 	cacheKeys = make([]string, n)
 	testFiles = make([]string, n)
@@ -142,6 +142,15 @@ func MockOsRemove(f func(name string) error) func() {
 	}
 }
 
+func MockDownloadChunkSize(chunkSize, checkpointSize int64) (restore func()) {
+	restoreChunk := testutil.Mock(&downloadChunkSize, chunkSize)
+	restoreCheckpoint := testutil.Mock(&downloadCheckpointSize, checkpointSize)
+	return func() {
+		restoreCheckpoint()
+		restoreChunk()
+	}
+}
+
 func MockDownload(f func(ctx context.Context, name, sha3_384, downloadURL string, user *auth.UserState, s *Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *DownloadOptions) error) (restore func()) {
 	origDownload := download
 	download = f
@@ -275,3 +284,11 @@ type (
 )
 
 var ReportFetchAssertionsError = reportFetchAssertionsError
+
+func (pc *PeerCache) HoldersOf(digest string) []string {
+	return pc.holdersOf(digest)
+}
+
+func MockLookupHost(f func(host string) ([]string, error)) (restore func()) {
+	return testutil.Mock(&lookupHost, f)
+}
@@ -0,0 +1,469 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package localstore implements a store that serves snaps and assertions from
+// a local directory, for devices that cannot reach any store.
+//
+// The directory, typically a mounted USB stick, contains snap files, named
+// *.snap, and assertions, in files named *.assert that can each hold a stream
+// of several of them. Snaps are only served if the snap-revision assertion for
+// their file and the snap-declaration for their snap ID are present. The
+// account and account-key assertions of the publishers and validation-set
+// assertions can be added as well.
+//
+// Optionally, a channels.yaml file maps snap names to the revisions released
+// in each of their channels, for example:
+//
+//	hello:
+//	  latest/stable: 12
+//	  latest/edge: 14
+//	  2.0/stable: 20
+//
+// Without any entry in channels.yaml, the highest revision of a snap is
+// released to latest/stable. The directory is scanned again when its
+// modification time changes, so snaps and assertions can be added, removed
+// or replaced while it's in use.
+package localstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/arch"
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/snap/snapfile"
+)
+
+var snapfileOpen = snapfile.Open
+
+// ErrNotSupported is returned for store operations that require an online
+// store, such as logging in or buying snaps.
+var ErrNotSupported = errors.New("operation not supported by the local store")
+
+// Store is a store that serves snaps and assertions from a local directory.
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+	// digests caches the digests of the snap files, which are expensive to
+	// compute.
+	digests map[string]fileDigest
+	// idx caches the last scan of the directory, it is used as long as
+	// the modification time of the directory is idxModTime.
+	idx        *index
+	idxModTime time.Time
+}
+
+// modTimeGranularity is the coarsest granularity of the modification time
+// of the file systems the directory can be on, that of FAT.
+const modTimeGranularity = 2 * time.Second
+
+var timeNow = time.Now
+
+type fileDigest struct {
+	size    int64
+	modTime time.Time
+	sha3    string
+}
+
+// New creates a new Store serving the content of the given directory.
+func New(dir string) *Store {
+	return &Store{
+		dir:     dir,
+		digests: make(map[string]fileDigest),
+	}
+}
+
+// Dir returns the directory the store serves its content from.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// entry is a snap revision available in the directory.
+type entry struct {
+	path string
+	info *snap.Info
+}
+
+// index is the content of the directory at the time it was scanned.
+type index struct {
+	assertions asserts.Backstore
+	// snaps holds the available revisions of each snap by name, sorted by
+	// descending revision.
+	snaps map[string][]*entry
+	// channels maps snap names to the revisions released in each of their
+	// channels.
+	channels map[string]map[string]snap.Revision
+}
+
+func (s *Store) scan(ctx context.Context) (*index, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	fi, err := os.Stat(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use local store: %v", err)
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("cannot use local store: %q is not a directory", s.dir)
+	}
+
+	s.mu.Lock()
+	idx := s.idx
+	fresh := idx != nil && s.idxModTime.Equal(fi.ModTime())
+	s.mu.Unlock()
+	if fresh {
+		return idx, nil
+	}
+
+	idx = &index{
+		assertions: asserts.NewMemoryBackstore(),
+		snaps:      make(map[string][]*entry),
+	}
+
+	assertFiles, err := filepath.Glob(filepath.Join(s.dir, "*.assert"))
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range assertFiles {
+		if err := idx.addAssertions(fn); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := idx.readChannels(filepath.Join(s.dir, "channels.yaml")); err != nil {
+		return nil, err
+	}
+
+	snapFiles, err := filepath.Glob(filepath.Join(s.dir, "*.snap"))
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range snapFiles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e, err := s.readSnap(idx, fn)
+		if err != nil {
+			// unasserted or broken snaps don't prevent using the others
+			logger.Noticef("local store: ignoring %s: %v", fn, err)
+			continue
+		}
+		idx.snaps[e.info.SnapName()] = append(idx.snaps[e.info.SnapName()], e)
+	}
+
+	for _, entries := range idx.snaps {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].info.Revision.N > entries[j].info.Revision.N
+		})
+	}
+
+	// changes made right after the directory was last modified might not
+	// be reflected by its modification time, so don't rely on it then
+	if timeNow().Sub(fi.ModTime()) > modTimeGranularity {
+		s.mu.Lock()
+		s.idx = idx
+		s.idxModTime = fi.ModTime()
+		s.mu.Unlock()
+	}
+
+	return idx, nil
+}
+
+func (idx *index) addAssertions(fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := asserts.NewDecoder(f)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read assertions from %q: %v", fn, err)
+		}
+		// only the most recent revision of each assertion is kept
+		err = idx.assertions.Put(a.Type(), a)
+		if err != nil && !isRevisionError(err) {
+			return err
+		}
+	}
+}
+
+func isRevisionError(err error) bool {
+	var revErr *asserts.RevisionError
+	return errors.As(err, &revErr)
+}
+
+func (idx *index) readChannels(fn string) error {
+	data, err := os.ReadFile(fn)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]map[string]int
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("cannot parse %q: %v", fn, err)
+	}
+
+	idx.channels = make(map[string]map[string]snap.Revision, len(raw))
+	for name, releases := range raw {
+		idx.channels[name] = make(map[string]snap.Revision, len(releases))
+		for ch, rev := range releases {
+			full, err := channel.Full(ch)
+			if err != nil {
+				return fmt.Errorf("cannot parse %q: invalid channel %q for snap %q: %v", fn, ch, name, err)
+			}
+			if rev <= 0 {
+				return fmt.Errorf("cannot parse %q: invalid revision %d for snap %q in channel %q", fn, rev, name, ch)
+			}
+			idx.channels[name][full] = snap.R(rev)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) digest(fn string) (digest string, size int64, err error) {
+	fi, err := os.Stat(fn)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	d, ok := s.digests[fn]
+	s.mu.Unlock()
+	if ok && d.size == fi.Size() && d.modTime.Equal(fi.ModTime()) {
+		return d.sha3, d.size, nil
+	}
+
+	sha3, _, err := asserts.SnapFileSHA3_384(fn)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	s.digests[fn] = fileDigest{size: fi.Size(), modTime: fi.ModTime(), sha3: sha3}
+	s.mu.Unlock()
+
+	return sha3, fi.Size(), nil
+}
+
+// readSnap reads the snap at the given path, identified by its snap-revision
+// and snap-declaration assertions.
+func (s *Store) readSnap(idx *index, fn string) (*entry, error) {
+	sha3, size, err := s.digest(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := idx.assertions.Get(asserts.SnapRevisionType, []string{sha3}, asserts.SnapRevisionType.MaxSupportedFormat())
+	if err != nil {
+		return nil, fmt.Errorf("cannot find snap-revision assertion: %v", err)
+	}
+	snapRev := a.(*asserts.SnapRevision)
+
+	a, err = idx.assertions.Get(asserts.SnapDeclarationType, []string{release.Series, snapRev.SnapID()}, asserts.SnapDeclarationType.MaxSupportedFormat())
+	if err != nil {
+		return nil, fmt.Errorf("cannot find snap-declaration assertion: %v", err)
+	}
+	snapDecl := a.(*asserts.SnapDeclaration)
+
+	container, err := snapfileOpen(fn)
+	if err != nil {
+		return nil, err
+	}
+	info, err := snap.ReadInfoFromSnapFile(container, &snap.SideInfo{
+		RealName: snapDecl.SnapName(),
+		SnapID:   snapRev.SnapID(),
+		Revision: snap.R(snapRev.SnapRevision()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info.Publisher = snap.StoreAccount{ID: snapDecl.PublisherID()}
+	a, err = idx.assertions.Get(asserts.AccountType, []string{snapDecl.PublisherID()}, asserts.AccountType.MaxSupportedFormat())
+	if err == nil {
+		acct := a.(*asserts.Account)
+		info.Publisher.Username = acct.Username()
+		info.Publisher.DisplayName = acct.DisplayName()
+		info.Publisher.Validation = acct.Validation()
+	}
+
+	info.DownloadInfo = snap.DownloadInfo{
+		DownloadURL: fn,
+		Size:        size,
+		Sha3_384:    sha3,
+	}
+
+	return &entry{path: fn, info: info}, nil
+}
+
+// releases returns the revisions of the given snap released in each channel.
+func (idx *index) releases(name string) map[string]snap.Revision {
+	if releases, ok := idx.channels[name]; ok {
+		return releases
+	}
+	entries := idx.snaps[name]
+	if len(entries) == 0 {
+		return nil
+	}
+	return map[string]snap.Revision{"latest/stable": entries[0].info.Revision}
+}
+
+func (idx *index) revision(name string, rev snap.Revision) *entry {
+	for _, e := range idx.snaps[name] {
+		if e.info.Revision == rev {
+			return e
+		}
+	}
+	return nil
+}
+
+// resolve returns the revision of the given snap released in the given
+// channel. Like with the store, a closed risk level follows the next, more
+// stable, one, and branches fall back to their track and risk.
+func (idx *index) resolve(name, ch string) (*entry, error) {
+	if ch == "" {
+		ch = "latest/stable"
+	}
+	c, err := channel.Parse(ch, arch.DpkgArchitecture())
+	if err != nil {
+		return nil, err
+	}
+	track := c.Track
+	if track == "" {
+		track = "latest"
+	}
+
+	releases := idx.releases(name)
+
+	var candidates []string
+	if c.Branch != "" {
+		candidates = append(candidates, fmt.Sprintf("%s/%s/%s", track, c.Risk, c.Branch))
+	}
+	risks := []string{"edge", "beta", "candidate", "stable"}
+	for i, risk := range risks {
+		if risk == c.Risk {
+			for _, r := range risks[i:] {
+				candidates = append(candidates, fmt.Sprintf("%s/%s", track, r))
+			}
+			break
+		}
+	}
+
+	for _, cand := range candidates {
+		if rev, ok := releases[cand]; ok {
+			if e := idx.revision(name, rev); e != nil {
+				return e, nil
+			}
+		}
+	}
+
+	return nil, &notAvailableError{releases: releases}
+}
+
+// notAvailableError is converted to a store.RevisionNotAvailableError for
+// the action that failed.
+type notAvailableError struct {
+	releases map[string]snap.Revision
+}
+
+func (e *notAvailableError) Error() string {
+	return "no snap revision available as specified"
+}
+
+func (e *notAvailableError) channels() []channel.Channel {
+	names := make([]string, 0, len(e.releases))
+	for name := range e.releases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	chans := make([]channel.Channel, 0, len(names))
+	for _, name := range names {
+		c, err := channel.Parse(name, arch.DpkgArchitecture())
+		if err != nil {
+			continue
+		}
+		chans = append(chans, c)
+	}
+	return chans
+}
+
+// withChannels returns a copy of the given info, released in the given
+// channel, and carrying the information about all the channels of the snap.
+func (idx *index) withChannels(e *entry, ch string) *snap.Info {
+	info := *e.info
+	info.Channel = ch
+
+	name := e.info.SnapName()
+	info.Channels = make(map[string]*snap.ChannelSnapInfo)
+	tracks := make(map[string]bool)
+	for full, rev := range idx.releases(name) {
+		released := idx.revision(name, rev)
+		if released == nil {
+			continue
+		}
+		fi, err := os.Stat(released.path)
+		if err != nil {
+			continue
+		}
+		info.Channels[full] = &snap.ChannelSnapInfo{
+			Revision:    released.info.Revision,
+			Confinement: released.info.Confinement,
+			Version:     released.info.Version,
+			Channel:     full,
+			Epoch:       released.info.Epoch,
+			Size:        released.info.DownloadInfo.Size,
+			ReleasedAt:  fi.ModTime().UTC(),
+		}
+		tracks[strings.SplitN(full, "/", 2)[0]] = true
+	}
+	info.Tracks = make([]string, 0, len(tracks))
+	for track := range tracks {
+		info.Tracks = append(info.Tracks, track)
+	}
+	sort.Strings(info.Tracks)
+
+	return &info
+}
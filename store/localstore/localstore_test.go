@@ -0,0 +1,512 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package localstore_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapdir"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/localstore"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type localstoreSuite struct {
+	testutil.BaseTest
+
+	dir          string
+	storeSigning *assertstest.StoreStack
+	dev1Acct     *asserts.Account
+
+	// yamls holds the snap.yaml of the snaps by path
+	yamls map[string]string
+	// opened counts the snap files that were opened
+	opened int
+}
+
+var _ = Suite(&localstoreSuite{})
+
+// ensure we conform
+var _ snapstate.StoreService = (*localstore.Store)(nil)
+
+func (s *localstoreSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+
+	s.dir = c.MkDir()
+	s.storeSigning = assertstest.NewStoreStack("can0nical", nil)
+	s.dev1Acct = assertstest.NewAccount(s.storeSigning, "developer1", nil, "")
+	s.yamls = make(map[string]string)
+	s.opened = 0
+
+	s.AddCleanup(localstore.MockSnapfileOpen(func(path string) (snap.Container, error) {
+		s.opened++
+		yaml, ok := s.yamls[path]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a snap", path)
+		}
+		d := c.MkDir()
+		c.Assert(os.MkdirAll(filepath.Join(d, "meta"), 0755), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(d, "meta", "snap.yaml"), []byte(yaml), 0644), IsNil)
+		return snapdir.New(d), nil
+	}))
+
+	s.writeAssertions(c, "accounts.assert", s.dev1Acct, s.storeSigning.StoreAccountKey(""))
+}
+
+func (s *localstoreSuite) writeAssertions(c *C, name string, as ...asserts.Assertion) {
+	buf := bytes.NewBuffer(nil)
+	enc := asserts.NewEncoder(buf)
+	for _, a := range as {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+	c.Assert(os.WriteFile(filepath.Join(s.dir, name), buf.Bytes(), 0644), IsNil)
+}
+
+// addSnap adds a revision of a snap to the directory, along with its
+// assertions unless unasserted is set.
+func (s *localstoreSuite) addSnap(c *C, name string, rev int, unasserted bool) string {
+	fn := filepath.Join(s.dir, fmt.Sprintf("%s_%d.snap", name, rev))
+	c.Assert(os.WriteFile(fn, []byte(fmt.Sprintf("%s-%d-content", name, rev)), 0644), IsNil)
+	s.yamls[fn] = fmt.Sprintf("name: %s\nversion: %d.0\nsummary: the %s snap\napps:\n  cmd:\n    command: bin/cmd\n", name, rev, name)
+	if unasserted {
+		return fn
+	}
+
+	digest, size, err := asserts.SnapFileSHA3_384(fn)
+	c.Assert(err, IsNil)
+
+	decl, err := s.storeSigning.Sign(asserts.SnapDeclarationType, map[string]any{
+		"series":       "16",
+		"snap-id":      name + "-id",
+		"snap-name":    name,
+		"publisher-id": s.dev1Acct.AccountID(),
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	snapRev, err := s.storeSigning.Sign(asserts.SnapRevisionType, map[string]any{
+		"snap-id":       name + "-id",
+		"snap-sha3-384": digest,
+		"snap-size":     fmt.Sprintf("%d", size),
+		"snap-revision": fmt.Sprintf("%d", rev),
+		"developer-id":  s.dev1Acct.AccountID(),
+		"timestamp":     time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	s.writeAssertions(c, fmt.Sprintf("%s_%d.assert", name, rev), decl, snapRev)
+
+	return fn
+}
+
+func (s *localstoreSuite) validationSet(c *C, sequence, revision int) asserts.Assertion {
+	a, err := s.storeSigning.Sign(asserts.ValidationSetType, map[string]any{
+		"series":     "16",
+		"account-id": "can0nical",
+		"name":       "base-set",
+		"sequence":   fmt.Sprintf("%d", sequence),
+		"revision":   fmt.Sprintf("%d", revision),
+		"snaps": []any{map[string]any{
+			"id":       "qOqKhntON3vR7kwEbVPsILm7bUViPDzz",
+			"name":     "hello",
+			"presence": "required",
+		}},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return a
+}
+
+func (s *localstoreSuite) TestSnapActionInstall(c *C) {
+	s.addSnap(c, "hello", 1, false)
+	s.addSnap(c, "hello", 2, false)
+	s.addSnap(c, "other", 5, true)
+
+	sto := localstore.New(s.dir)
+
+	sars, _, err := sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "install",
+		InstanceName: "hello_foo",
+	}}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(sars, HasLen, 1)
+
+	info := sars[0].Info
+	c.Check(info.InstanceName(), Equals, "hello_foo")
+	c.Check(info.SnapID, Equals, "hello-id")
+	c.Check(info.Revision, Equals, snap.R(2))
+	c.Check(info.Version, Equals, "2.0")
+	c.Check(info.Channel, Equals, "latest/stable")
+	c.Check(info.Publisher.Username, Equals, "developer1")
+	c.Check(info.DownloadInfo.Size, Equals, int64(len("hello-2-content")))
+	c.Check(info.DownloadInfo.Sha3_384, Not(Equals), "")
+	c.Check(info.Channels["latest/stable"].Revision, Equals, snap.R(2))
+
+	// unasserted snaps aren't served
+	_, _, err = sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "install",
+		InstanceName: "other",
+	}}, nil, nil, nil)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	c.Check(err.(*store.SnapActionError).Install["other"], Equals, store.ErrSnapNotFound)
+
+	// a specific revision
+	sars, _, err = sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "download",
+		InstanceName: "hello",
+		Revision:     snap.R(1),
+	}}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(sars, HasLen, 1)
+	c.Check(sars[0].Revision, Equals, snap.R(1))
+}
+
+func (s *localstoreSuite) TestSnapActionChannels(c *C) {
+	for rev := 1; rev <= 3; rev++ {
+		s.addSnap(c, "hello", rev, false)
+	}
+	c.Assert(os.WriteFile(filepath.Join(s.dir, "channels.yaml"), []byte(`
+hello:
+  stable: 1
+  latest/beta: 2
+  2.0/edge: 3
+`), 0644), IsNil)
+
+	sto := localstore.New(s.dir)
+
+	for _, t := range []struct {
+		channel string
+		rev     int
+	}{
+		{"", 1},
+		{"stable", 1},
+		{"candidate", 1},
+		{"edge", 2},
+		{"latest/beta/fix", 2},
+		{"2.0/edge", 3},
+	} {
+		sars, _, err := sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+			Action:       "install",
+			InstanceName: "hello",
+			Channel:      t.channel,
+		}}, nil, nil, nil)
+		c.Assert(err, IsNil, Commentf("channel %q", t.channel))
+		c.Check(sars[0].Revision, Equals, snap.R(t.rev), Commentf("channel %q", t.channel))
+	}
+
+	_, _, err := sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "install",
+		InstanceName: "hello",
+		Channel:      "2.0/stable",
+	}}, nil, nil, nil)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	naErr, ok := err.(*store.SnapActionError).Install["hello"].(*store.RevisionNotAvailableError)
+	c.Assert(ok, Equals, true)
+	c.Check(naErr.Action, Equals, "install")
+	c.Check(naErr.Channel, Equals, "2.0/stable")
+	c.Assert(naErr.Releases, HasLen, 3)
+	c.Check(naErr.Releases[0].String(), Equals, "2.0/edge")
+
+	info, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "hello"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(info.Revision, Equals, snap.R(1))
+	c.Check(info.Channels, HasLen, 3)
+	c.Check(info.Channels["2.0/edge"].Version, Equals, "3.0")
+	c.Check(info.Tracks, DeepEquals, []string{"2.0", "latest"})
+}
+
+func (s *localstoreSuite) TestSnapActionRefresh(c *C) {
+	s.addSnap(c, "hello", 1, false)
+	s.addSnap(c, "hello", 2, false)
+
+	sto := localstore.New(s.dir)
+
+	current := []*store.CurrentSnap{{
+		InstanceName:    "hello",
+		SnapID:          "hello-id",
+		Revision:        snap.R(1),
+		TrackingChannel: "latest/stable",
+	}}
+	refresh := []*store.SnapAction{{
+		Action:       "refresh",
+		InstanceName: "hello",
+		SnapID:       "hello-id",
+	}}
+
+	sars, _, err := sto.SnapAction(context.Background(), current, refresh, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(sars, HasLen, 1)
+	c.Check(sars[0].Revision, Equals, snap.R(2))
+
+	current[0].Revision = snap.R(2)
+	_, _, err = sto.SnapAction(context.Background(), current, refresh, nil, nil, nil)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	c.Check(err.(*store.SnapActionError).Refresh["hello"], Equals, store.ErrNoUpdateAvailable)
+	c.Check(err.(*store.SnapActionError).NoResults, Equals, false)
+
+	current[0].Revision = snap.R(1)
+	current[0].Block = []snap.Revision{snap.R(2)}
+	_, _, err = sto.SnapAction(context.Background(), current, refresh, nil, nil, nil)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	c.Check(err.(*store.SnapActionError).Refresh["hello"], Equals, store.ErrNoUpdateAvailable)
+}
+
+func (s *localstoreSuite) TestFindAndCatalogs(c *C) {
+	s.addSnap(c, "hello", 1, false)
+	s.addSnap(c, "hello-world", 1, false)
+	s.addSnap(c, "other", 1, false)
+
+	sto := localstore.New(s.dir)
+
+	infos, err := sto.Find(context.Background(), &store.Search{Query: "hello"}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 2)
+	c.Check(infos[0].SnapName(), Equals, "hello")
+	c.Check(infos[1].SnapName(), Equals, "hello-world")
+
+	infos, err = sto.Find(context.Background(), &store.Search{Query: "the other snap"}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 1)
+	c.Check(infos[0].SnapName(), Equals, "other")
+
+	infos, err = sto.Find(context.Background(), &store.Search{Query: "missing"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(infos, HasLen, 0)
+
+	_, err = sto.Find(context.Background(), &store.Search{Query: "hello", Private: true}, nil)
+	c.Check(err, Equals, localstore.ErrNotSupported)
+
+	names := bytes.NewBuffer(nil)
+	adder := &testSnapAdder{}
+	c.Assert(sto.WriteCatalogs(context.Background(), names, adder), IsNil)
+	c.Check(names.String(), Equals, "hello\nhello-world\nother\n")
+	c.Check(adder.commands, DeepEquals, map[string][]string{
+		"hello":       {"hello.cmd"},
+		"hello-world": {"hello-world.cmd"},
+		"other":       {"other.cmd"},
+	})
+}
+
+func (s *localstoreSuite) TestScanCachedByDirModTime(c *C) {
+	s.addSnap(c, "hello", 1, false)
+	past := time.Now().Add(-time.Hour)
+	c.Assert(os.Chtimes(s.dir, past, past), IsNil)
+
+	sto := localstore.New(s.dir)
+	find := func() []string {
+		infos, err := sto.Find(context.Background(), &store.Search{Query: "hello"}, nil)
+		c.Assert(err, IsNil)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.SnapName())
+		}
+		return names
+	}
+
+	c.Check(find(), DeepEquals, []string{"hello"})
+	c.Check(s.opened, Equals, 1)
+
+	// the directory is not scanned again while it's unchanged
+	c.Check(find(), DeepEquals, []string{"hello"})
+	c.Check(s.opened, Equals, 1)
+
+	// but it is once it changes
+	s.addSnap(c, "hello-world", 1, false)
+	past = past.Add(time.Minute)
+	c.Assert(os.Chtimes(s.dir, past, past), IsNil)
+	c.Check(find(), DeepEquals, []string{"hello", "hello-world"})
+	c.Check(s.opened, Equals, 3)
+
+	// recent changes might not be reflected by the modification time, so
+	// the directory is scanned each time until it settles
+	c.Assert(os.Chtimes(s.dir, time.Now(), time.Now()), IsNil)
+	c.Check(find(), DeepEquals, []string{"hello", "hello-world"})
+	c.Check(find(), DeepEquals, []string{"hello", "hello-world"})
+	c.Check(s.opened, Equals, 7)
+}
+
+type testSnapAdder struct {
+	commands map[string][]string
+}
+
+func (a *testSnapAdder) AddSnap(snapName, version, summary string, commands []string) error {
+	if a.commands == nil {
+		a.commands = make(map[string][]string)
+	}
+	a.commands[snapName] = commands
+	return nil
+}
+
+func (s *localstoreSuite) TestDownload(c *C) {
+	s.addSnap(c, "hello", 1, false)
+
+	sto := localstore.New(s.dir)
+
+	info, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "hello"}, nil)
+	c.Assert(err, IsNil)
+
+	target := filepath.Join(c.MkDir(), "downloads", "hello_1.snap")
+	err = sto.Download(context.Background(), "hello", target, &info.DownloadInfo, progress.Null, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(target, testutil.FileEquals, "hello-1-content")
+
+	r, status, err := sto.DownloadStream(context.Background(), "hello", &info.DownloadInfo, 6, nil)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	c.Check(status, Equals, 206)
+	data, err := io.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "1-content")
+
+	err = sto.Download(context.Background(), "hello", target, &snap.DownloadInfo{Sha3_384: "unknown"}, progress.Null, nil, nil)
+	c.Check(err, ErrorMatches, `cannot find snap "hello" with digest unknown in .*`)
+}
+
+type testAssertionQuery struct {
+	toResolve    map[asserts.Grouping][]*asserts.AtRevision
+	toResolveSeq map[asserts.Grouping][]*asserts.AtSequence
+	errors       map[string]error
+}
+
+func (q *testAssertionQuery) ToResolve() (map[asserts.Grouping][]*asserts.AtRevision, map[asserts.Grouping][]*asserts.AtSequence, error) {
+	return q.toResolve, q.toResolveSeq, nil
+}
+
+func (q *testAssertionQuery) AddError(e error, ref *asserts.Ref) error {
+	q.errors[ref.Unique()] = e
+	return nil
+}
+
+func (q *testAssertionQuery) AddSequenceError(e error, atSeq *asserts.AtSequence) error {
+	q.errors[atSeq.Unique()] = e
+	return nil
+}
+
+func (q *testAssertionQuery) AddGroupingError(e error, grouping asserts.Grouping) error {
+	return nil
+}
+
+func (s *localstoreSuite) TestAssertions(c *C) {
+	s.addSnap(c, "hello", 1, false)
+	vs1 := s.validationSet(c, 1, 0)
+	vs2 := s.validationSet(c, 2, 0)
+	vs2r1 := s.validationSet(c, 2, 1)
+	s.writeAssertions(c, "validation-sets.assert", vs1, vs2r1, vs2)
+
+	sto := localstore.New(s.dir)
+
+	a, err := sto.Assertion(asserts.AccountType, []string{s.dev1Acct.AccountID()}, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.Account).Username(), Equals, "developer1")
+
+	_, err = sto.Assertion(asserts.AccountType, []string{"unknown"}, nil)
+	c.Check(err, ErrorMatches, `account \(unknown\) not found`)
+
+	// the latest sequence, with its most recent revision
+	a, err = sto.SeqFormingAssertion(asserts.ValidationSetType, []string{"16", "can0nical", "base-set"}, 0, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.ValidationSet).Sequence(), Equals, 2)
+	c.Check(a.Revision(), Equals, 1)
+
+	a, err = sto.SeqFormingAssertion(asserts.ValidationSetType, []string{"16", "can0nical", "base-set"}, 1, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.ValidationSet).Sequence(), Equals, 1)
+
+	_, err = sto.SeqFormingAssertion(asserts.ValidationSetType, []string{"16", "can0nical", "base-set"}, 3, nil)
+	c.Check(err, ErrorMatches, `validation-set \(3; series:16 account-id:can0nical name:base-set\) not found`)
+
+	q := &testAssertionQuery{
+		toResolve: map[asserts.Grouping][]*asserts.AtRevision{
+			"g1": {{
+				Ref:      asserts.Ref{Type: asserts.AccountType, PrimaryKey: []string{s.dev1Acct.AccountID()}},
+				Revision: asserts.RevisionNotKnown,
+			}, {
+				Ref:      asserts.Ref{Type: asserts.AccountType, PrimaryKey: []string{"unknown"}},
+				Revision: asserts.RevisionNotKnown,
+			}},
+		},
+		toResolveSeq: map[asserts.Grouping][]*asserts.AtSequence{
+			"g2": {{
+				Type:        asserts.ValidationSetType,
+				SequenceKey: []string{"16", "can0nical", "base-set"},
+				Sequence:    1,
+				Revision:    0,
+			}},
+		},
+		errors: make(map[string]error),
+	}
+	_, ars, err := sto.SnapAction(context.Background(), nil, nil, q, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(q.errors, HasLen, 1)
+	c.Check(q.errors["account/unknown"], ErrorMatches, `account \(unknown\) not found`)
+	c.Assert(ars, HasLen, 2)
+	c.Check(ars[0].Grouping, Equals, asserts.Grouping("g1"))
+	c.Check(ars[1].Grouping, Equals, asserts.Grouping("g2"))
+
+	b := asserts.NewBatch(nil)
+	err = sto.DownloadAssertions(append(ars[0].StreamURLs, ars[1].StreamURLs...), b, nil)
+	c.Assert(err, IsNil)
+
+	var got []string
+	b.Backstore().Search(asserts.ValidationSetType, nil, func(a asserts.Assertion) {
+		got = append(got, fmt.Sprintf("%d/%d", a.(*asserts.ValidationSet).Sequence(), a.Revision()))
+	}, asserts.ValidationSetType.MaxSupportedFormat())
+	c.Check(got, DeepEquals, []string{"2/1"})
+
+	// nothing newer
+	q.toResolve = nil
+	q.toResolveSeq["g2"][0].Sequence = 2
+	q.toResolveSeq["g2"][0].Revision = 1
+	_, _, err = sto.SnapAction(context.Background(), nil, nil, q, nil, nil)
+	c.Check(err, DeepEquals, &store.SnapActionError{NoResults: true})
+}
+
+func (s *localstoreSuite) TestErrors(c *C) {
+	sto := localstore.New(filepath.Join(s.dir, "missing"))
+	_, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "hello"}, nil)
+	c.Check(err, ErrorMatches, "cannot use local store: stat .*/missing: no such file or directory")
+
+	c.Assert(os.WriteFile(filepath.Join(s.dir, "channels.yaml"), []byte("hello:\n  foo/bar/baz/quux: 1\n"), 0644), IsNil)
+	sto = localstore.New(s.dir)
+	_, err = sto.SnapInfo(context.Background(), store.SnapSpec{Name: "hello"}, nil)
+	c.Check(err, ErrorMatches, `cannot parse ".*/channels.yaml": invalid channel "foo/bar/baz/quux" for snap "hello": .*`)
+
+	c.Assert(os.WriteFile(filepath.Join(s.dir, "channels.yaml"), nil, 0644), IsNil)
+	_, err = sto.SnapInfo(context.Background(), store.SnapSpec{Name: "hello"}, nil)
+	c.Check(err, Equals, store.ErrSnapNotFound)
+
+	_, _, err = sto.LoginUser("user", "pass", "")
+	c.Check(err, Equals, localstore.ErrNotSupported)
+}
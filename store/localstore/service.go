@@ -0,0 +1,622 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/store"
+)
+
+// assertionURLPrefix prefixes the stream URLs returned by SnapAction for the
+// assertions to fetch, followed by the unique reference of the assertion.
+const assertionURLPrefix = "local-assertion:"
+
+func (s *Store) EnsureDeviceSession() error {
+	return nil
+}
+
+// SnapInfo returns the revision of the snap released in latest/stable, along
+// with the information about all its channels.
+func (s *Store) SnapInfo(ctx context.Context, spec store.SnapSpec, user *auth.UserState) (*snap.Info, error) {
+	idx, err := s.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := snap.InstanceSnap(spec.Name)
+	e, err := idx.pick(name, "", snap.R(0))
+	if err != nil {
+		if len(idx.snaps[name]) == 0 {
+			return nil, store.ErrSnapNotFound
+		}
+		// the snap is only released to other channels
+		e = idx.snaps[name][0]
+	}
+
+	info := idx.withChannels(e, "latest/stable")
+	_, info.InstanceKey = snap.SplitInstanceName(spec.Name)
+	return info, nil
+}
+
+func (s *Store) SnapExists(ctx context.Context, spec store.SnapSpec, user *auth.UserState) (naming.SnapRef, *channel.Channel, error) {
+	info, err := s.SnapInfo(ctx, spec, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(info.Channels))
+	for name := range info.Channels {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, nil, store.ErrSnapNotFound
+	}
+	sort.Strings(names)
+
+	ch, err := channel.Parse(names[0], "")
+	if err != nil {
+		return nil, nil, err
+	}
+	ch = ch.Clean()
+	return naming.NewSnapRef(info.SnapName(), info.SnapID), &ch, nil
+}
+
+// Find returns the snaps whose name, title or summary contain the query, or
+// whose name starts with it for prefix searches. Only the revisions released
+// to latest/stable are returned.
+func (s *Store) Find(ctx context.Context, search *store.Search, user *auth.UserState) ([]*snap.Info, error) {
+	if search.Private {
+		return nil, ErrNotSupported
+	}
+	if search.Scope != "" && search.Scope != "wide" {
+		return nil, store.ErrInvalidScope
+	}
+
+	idx, err := s.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(strings.TrimSpace(search.Query))
+	matches := func(info *snap.Info) bool {
+		if search.CommonID != "" {
+			for _, app := range info.Apps {
+				if app.CommonID == search.CommonID {
+					return true
+				}
+			}
+			return false
+		}
+		name := info.SnapName()
+		if search.Prefix {
+			return strings.HasPrefix(name, query)
+		}
+		return strings.Contains(name, query) ||
+			strings.Contains(strings.ToLower(info.Title()), query) ||
+			strings.Contains(strings.ToLower(info.Summary()), query)
+	}
+
+	names := make([]string, 0, len(idx.snaps))
+	for name := range idx.snaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var infos []*snap.Info
+	for _, name := range names {
+		e, err := idx.pick(name, "", snap.R(0))
+		if err != nil {
+			continue
+		}
+		if matches(e.info) {
+			infos = append(infos, idx.withChannels(e, "latest/stable"))
+		}
+	}
+
+	return infos, nil
+}
+
+// SnapAction resolves the install, download and refresh actions against the
+// snaps of the directory, and the assertions of the query against its
+// assertions. Resources are not supported.
+func (s *Store) SnapAction(ctx context.Context, currentSnaps []*store.CurrentSnap, actions []*store.SnapAction, assertQuery store.AssertionQuery, user *auth.UserState, opts *store.RefreshOptions) ([]store.SnapActionResult, []store.AssertionResult, error) {
+	var toResolve map[asserts.Grouping][]*asserts.AtRevision
+	var toResolveSeq map[asserts.Grouping][]*asserts.AtSequence
+	if assertQuery != nil {
+		var err error
+		toResolve, toResolveSeq, err = assertQuery.ToResolve()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(currentSnaps) == 0 && len(actions) == 0 && len(toResolve) == 0 && len(toResolveSeq) == 0 {
+		// nothing to do
+		return nil, nil, &store.SnapActionError{NoResults: true}
+	}
+
+	idx, err := s.scan(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := make(map[string]*store.CurrentSnap, len(currentSnaps))
+	for _, cur := range currentSnaps {
+		current[cur.InstanceName] = cur
+	}
+
+	saErr := &store.SnapActionError{}
+	var sars []store.SnapActionResult
+	for _, a := range actions {
+		switch a.Action {
+		case "install", "download":
+			name := snap.InstanceSnap(a.InstanceName)
+			e, err := idx.pick(name, a.Channel, a.Revision)
+			if err != nil {
+				err = actionError(a.Action, a.Channel, err)
+				if a.Action == "install" {
+					addError(&saErr.Install, a.InstanceName, err)
+				} else {
+					addError(&saErr.Download, a.InstanceName, err)
+				}
+				continue
+			}
+			info := idx.withChannels(e, effectiveChannel(a.Channel))
+			if a.Action == "install" {
+				_, info.InstanceKey = snap.SplitInstanceName(a.InstanceName)
+			}
+			sars = append(sars, store.SnapActionResult{Info: info})
+		case "refresh":
+			cur := current[a.InstanceName]
+			if cur == nil {
+				saErr.Other = append(saErr.Other, fmt.Errorf("internal error: refresh of snap %q that is not installed", a.InstanceName))
+				continue
+			}
+			ch := a.Channel
+			if ch == "" && a.Revision.Unset() {
+				ch = cur.TrackingChannel
+			}
+			e, err := idx.pick(idx.nameOf(cur), ch, a.Revision)
+			if err != nil {
+				addError(&saErr.Refresh, cur.InstanceName, actionError("refresh", ch, err))
+				continue
+			}
+			if !a.ResourceInstall && (e.info.Revision == cur.Revision || isBlocked(e.info.Revision, cur.Block) || !e.info.Epoch.CanRead(cur.Epoch)) {
+				addError(&saErr.Refresh, cur.InstanceName, store.ErrNoUpdateAvailable)
+				continue
+			}
+			info := idx.withChannels(e, effectiveChannel(ch))
+			_, info.InstanceKey = snap.SplitInstanceName(cur.InstanceName)
+			sars = append(sars, store.SnapActionResult{Info: info})
+		default:
+			saErr.Other = append(saErr.Other, fmt.Errorf("internal error: unsupported action %q", a.Action))
+		}
+	}
+
+	ars, err := idx.resolveAssertions(assertQuery, toResolve, toResolveSeq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(saErr.Refresh)+len(saErr.Install)+len(saErr.Download)+len(saErr.Other) != 0 || len(sars)+len(ars) == 0 {
+		saErr.NoResults = len(actions) == 0 && len(ars) == 0
+		return sars, ars, saErr
+	}
+
+	return sars, ars, nil
+}
+
+func addError(errs *map[string]error, name string, err error) {
+	if *errs == nil {
+		*errs = make(map[string]error)
+	}
+	(*errs)[name] = err
+}
+
+func actionError(action, ch string, err error) error {
+	var naErr *notAvailableError
+	if errors.As(err, &naErr) {
+		return &store.RevisionNotAvailableError{
+			Action:   action,
+			Channel:  ch,
+			Releases: naErr.channels(),
+		}
+	}
+	return err
+}
+
+func effectiveChannel(ch string) string {
+	if ch == "" {
+		return "latest/stable"
+	}
+	if full, err := channel.Full(ch); err == nil {
+		return full
+	}
+	return ch
+}
+
+func isBlocked(rev snap.Revision, block []snap.Revision) bool {
+	for _, b := range block {
+		if b == rev {
+			return true
+		}
+	}
+	return false
+}
+
+// nameOf returns the name under which the directory provides the snap with
+// the snap ID of the given current snap, as it might have been renamed.
+func (idx *index) nameOf(cur *store.CurrentSnap) string {
+	for name, entries := range idx.snaps {
+		if entries[0].info.SnapID == cur.SnapID {
+			return name
+		}
+	}
+	return snap.InstanceSnap(cur.InstanceName)
+}
+
+// pick returns the given revision of the snap if it is set, otherwise the
+// revision released in the given channel.
+func (idx *index) pick(name, ch string, rev snap.Revision) (*entry, error) {
+	if len(idx.snaps[name]) == 0 {
+		return nil, store.ErrSnapNotFound
+	}
+	if !rev.Unset() {
+		if e := idx.revision(name, rev); e != nil {
+			return e, nil
+		}
+		return nil, &notAvailableError{releases: idx.releases(name)}
+	}
+	return idx.resolve(name, ch)
+}
+
+func (idx *index) resolveAssertions(assertQuery store.AssertionQuery, toResolve map[asserts.Grouping][]*asserts.AtRevision, toResolveSeq map[asserts.Grouping][]*asserts.AtSequence) ([]store.AssertionResult, error) {
+	groupings := make(map[asserts.Grouping][]string)
+
+	for grouping, ats := range toResolve {
+		for _, at := range ats {
+			a, err := idx.assertions.Get(at.Type, at.PrimaryKey, at.Type.MaxSupportedFormat())
+			if errors.Is(err, &asserts.NotFoundError{}) {
+				headers, _ := asserts.HeadersFromPrimaryKey(at.Type, at.PrimaryKey)
+				err = &asserts.NotFoundError{Type: at.Type, Headers: headers}
+				if err := assertQuery.AddError(err, &at.Ref); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if a.Revision() > at.Revision {
+				groupings[grouping] = append(groupings[grouping], assertionURLPrefix+a.Ref().Unique())
+			}
+		}
+	}
+
+	for grouping, ats := range toResolveSeq {
+		for _, at := range ats {
+			var a asserts.Assertion
+			var err error
+			if at.Pinned {
+				key := append(append([]string(nil), at.SequenceKey...), strconv.Itoa(at.Sequence))
+				a, err = idx.assertions.Get(at.Type, key, at.Type.MaxSupportedFormat())
+			} else {
+				a, err = idx.assertions.SequenceMemberAfter(at.Type, at.SequenceKey, -1, at.Type.MaxSupportedFormat())
+			}
+			if errors.Is(err, &asserts.NotFoundError{}) {
+				headers := make(map[string]string)
+				for i, keyVal := range at.SequenceKey {
+					headers[at.Type.PrimaryKey[i]] = keyVal
+				}
+				if at.Pinned {
+					headers[at.Type.PrimaryKey[len(at.Type.PrimaryKey)-1]] = strconv.Itoa(at.Sequence)
+				}
+				err = &asserts.NotFoundError{Type: at.Type, Headers: headers}
+				if err := assertQuery.AddSequenceError(err, at); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			seq := a.(asserts.SequenceMember).Sequence()
+			if seq > at.Sequence || (seq == at.Sequence && a.Revision() > at.Revision) {
+				groupings[grouping] = append(groupings[grouping], assertionURLPrefix+a.Ref().Unique())
+			}
+		}
+	}
+
+	ars := make([]store.AssertionResult, 0, len(groupings))
+	for grouping, urls := range groupings {
+		ars = append(ars, store.AssertionResult{
+			Grouping:   grouping,
+			StreamURLs: urls,
+		})
+	}
+	sort.Slice(ars, func(i, j int) bool { return ars[i].Grouping < ars[j].Grouping })
+
+	return ars, nil
+}
+
+func (s *Store) Sections(ctx context.Context, user *auth.UserState) ([]string, error) {
+	return nil, nil
+}
+
+func (s *Store) Categories(ctx context.Context, user *auth.UserState) ([]store.CategoryDetails, error) {
+	return nil, nil
+}
+
+// WriteCatalogs writes the names of the snaps of the directory and adds the
+// commands of their revisions released to latest/stable.
+func (s *Store) WriteCatalogs(ctx context.Context, names io.Writer, adder store.SnapAdder) error {
+	idx, err := s.scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapNames := make([]string, 0, len(idx.snaps))
+	for name := range idx.snaps {
+		snapNames = append(snapNames, name)
+	}
+	sort.Strings(snapNames)
+
+	for _, name := range snapNames {
+		if _, err := fmt.Fprintln(names, name); err != nil {
+			return err
+		}
+		e, err := idx.pick(name, "", snap.R(0))
+		if err != nil {
+			continue
+		}
+		var commands []string
+		for _, app := range e.info.Apps {
+			if !app.IsService() {
+				commands = append(commands, snap.JoinSnapApp(name, app.Name))
+			}
+		}
+		sort.Strings(commands)
+		if err := adder.AddSnap(name, e.info.Version, e.info.Summary(), commands); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// find returns the snap file of the directory with the given digest.
+func (s *Store) find(ctx context.Context, name string, downloadInfo *snap.DownloadInfo) (*entry, error) {
+	idx, err := s.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range idx.snaps[snap.InstanceSnap(name)] {
+		if e.info.DownloadInfo.Sha3_384 == downloadInfo.Sha3_384 {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find snap %q with digest %s in %s", name, downloadInfo.Sha3_384, s.dir)
+}
+
+// Download copies the snap file matching the given download information to
+// the target path.
+func (s *Store) Download(ctx context.Context, name string, targetPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *store.DownloadOptions) error {
+	e, err := s.find(ctx, name, downloadInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+
+	src, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := osutil.NewAtomicFile(targetPath, 0600, 0, osutil.NoChown, osutil.NoChown)
+	if err != nil {
+		return err
+	}
+	defer dst.Cancel()
+
+	if pbar == nil {
+		pbar = progress.Null
+	}
+	pbar.Start(name, float64(e.info.DownloadInfo.Size))
+	defer pbar.Finished()
+
+	if _, err := io.Copy(io.MultiWriter(dst, pbar), &contextReader{ctx: ctx, r: src}); err != nil {
+		return err
+	}
+
+	return dst.Commit()
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func (s *Store) DownloadStream(ctx context.Context, name string, downloadInfo *snap.DownloadInfo, resume int64, user *auth.UserState) (io.ReadCloser, int, error) {
+	e, err := s.find(ctx, name, downloadInfo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(e.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resume == 0 {
+		return f, 200, nil
+	}
+	if _, err := f.Seek(resume, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, 206, nil
+}
+
+func (s *Store) DownloadIcon(ctx context.Context, name string, targetPath string, downloadURL string) error {
+	return ErrNotSupported
+}
+
+// Assertion returns the assertion with the given primary key from the
+// directory.
+func (s *Store) Assertion(assertType *asserts.AssertionType, primaryKey []string, user *auth.UserState) (asserts.Assertion, error) {
+	idx, err := s.scan(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := idx.assertions.Get(assertType, primaryKey, assertType.MaxSupportedFormat())
+	if errors.Is(err, &asserts.NotFoundError{}) {
+		headers, _ := asserts.HeadersFromPrimaryKey(assertType, primaryKey)
+		return nil, &asserts.NotFoundError{Type: assertType, Headers: headers}
+	}
+	return a, err
+}
+
+// SeqFormingAssertion returns the sequence-forming assertion with the given
+// sequence key and sequence from the directory, or the one with the latest
+// sequence if sequence <= 0.
+func (s *Store) SeqFormingAssertion(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
+	if !assertType.SequenceForming() {
+		return nil, fmt.Errorf("internal error: requested non sequence-forming assertion type %q", assertType.Name)
+	}
+	if len(sequenceKey) != len(assertType.PrimaryKey)-1 {
+		return nil, fmt.Errorf("sequence key has wrong length for %q assertion", assertType.Name)
+	}
+
+	idx, err := s.scan(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var a asserts.Assertion
+	if sequence > 0 {
+		key := append(append([]string(nil), sequenceKey...), strconv.Itoa(sequence))
+		a, err = idx.assertions.Get(assertType, key, assertType.MaxSupportedFormat())
+	} else {
+		a, err = idx.assertions.SequenceMemberAfter(assertType, sequenceKey, -1, assertType.MaxSupportedFormat())
+	}
+	if errors.Is(err, &asserts.NotFoundError{}) {
+		headers := make(map[string]string)
+		for i, keyVal := range sequenceKey {
+			headers[assertType.PrimaryKey[i]] = keyVal
+		}
+		if sequence > 0 {
+			headers[assertType.PrimaryKey[len(assertType.PrimaryKey)-1]] = strconv.Itoa(sequence)
+		}
+		return nil, &asserts.NotFoundError{Type: assertType, Headers: headers}
+	}
+	return a, err
+}
+
+// DownloadAssertions adds the assertions referred to by the stream URLs
+// returned by SnapAction to the given batch.
+func (s *Store) DownloadAssertions(streamURLs []string, b *asserts.Batch, user *auth.UserState) error {
+	idx, err := s.scan(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	for _, u := range streamURLs {
+		if !strings.HasPrefix(u, assertionURLPrefix) {
+			return fmt.Errorf("invalid assertions stream URL: %q", u)
+		}
+		parts := strings.Split(strings.TrimPrefix(u, assertionURLPrefix), "/")
+		assertType := asserts.Type(parts[0])
+		if assertType == nil {
+			return fmt.Errorf("invalid assertions stream URL: %q", u)
+		}
+		a, err := idx.assertions.Get(assertType, parts[1:], assertType.MaxSupportedFormat())
+		if err != nil {
+			return err
+		}
+		if err := b.Add(a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) SuggestedCurrency() string {
+	return ""
+}
+
+func (s *Store) Buy(options *client.BuyOptions, user *auth.UserState) (*client.BuyResult, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *Store) ReadyToBuy(*auth.UserState) error {
+	return ErrNotSupported
+}
+
+// ConnectivityCheck reports whether the directory is available.
+func (s *Store) ConnectivityCheck() (map[string]bool, error) {
+	fi, err := os.Stat(s.dir)
+	return map[string]bool{s.dir: err == nil && fi.IsDir()}, nil
+}
+
+func (s *Store) CreateCohorts(context.Context, []string) (map[string]string, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *Store) LoginUser(username, password, otp string) (string, string, error) {
+	return "", "", ErrNotSupported
+}
+
+func (s *Store) UserInfo(email string) (userinfo *store.User, err error) {
+	return nil, ErrNotSupported
+}
+
+// CleanDownloadsCache does nothing, the local store doesn't cache downloads.
+func (s *Store) CleanDownloadsCache() error {
+	return nil
+}
@@ -0,0 +1,486 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+)
+
+const (
+	peerCacheAnnouncePath = "/v1/peer-cache/announce"
+	peerCacheBlobsPath    = "/v1/peer-cache/blobs/"
+
+	defaultPeerCacheTimeout          = 5 * time.Second
+	defaultPeerCacheAnnounceInterval = 10 * time.Minute
+)
+
+// blobs are named by the hex encoding of their sha3-384 digest
+var validBlobDigest = regexp.MustCompile("^[0-9a-f]{96}$")
+
+var errNoPeerHolder = errors.New("no peer announced the snap")
+
+var lookupHost = net.LookupHost
+
+// PeerCacheConfig holds the configuration of a PeerCache.
+type PeerCacheConfig struct {
+	// Peers are the addresses of the peers this device announces the content
+	// of its cache to.
+	Peers []string
+	// Timeout bounds the time waited for a peer to start sending a snap, or
+	// to send more of it, before falling back to the store.
+	Timeout time.Duration
+	// AnnounceInterval is the interval at which the content of the cache is
+	// announced to the peers. Announcements expire after three intervals.
+	AnnounceInterval time.Duration
+}
+
+// PeerCache shares the snaps of a download cache with peers on the local
+// network, so that devices of the same site download each snap revision from
+// the store only once.
+//
+// Devices announce the digests of the snaps they hold in their cache to their
+// peers, announcements from devices other than the configured peers are
+// ignored, and the store first tries to fetch a snap from the peers that
+// announced it. Peers are not trusted: the sha3-384 digest of a snap fetched
+// from a peer must match the one the store gave for the revision, which is the
+// one from its snap-revision assertion, otherwise the snap is downloaded from
+// the store.
+//
+// As peers can fetch any snap they know the digest of, private snaps should
+// not be shared with untrusted networks.
+type PeerCache struct {
+	cache  *CacheManager
+	cfg    PeerCacheConfig
+	client *http.Client
+
+	mu sync.Mutex
+	// port is the port this device serves its cache on, peers learn the
+	// address from the announcements.
+	port int
+	// holders maps blob digests to the addresses of the peers that announced
+	// them, along with the expiry of the announcements.
+	holders map[string]map[string]time.Time
+
+	changed chan struct{}
+}
+
+// NewPeerCache returns a PeerCache sharing the snaps of the given cache.
+func NewPeerCache(cache *CacheManager, cfg PeerCacheConfig) *PeerCache {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultPeerCacheTimeout
+	}
+	if cfg.AnnounceInterval == 0 {
+		cfg.AnnounceInterval = defaultPeerCacheAnnounceInterval
+	}
+
+	return &PeerCache{
+		cache: cache,
+		cfg:   cfg,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:           (&net.Dialer{Timeout: cfg.Timeout}).DialContext,
+				ResponseHeaderTimeout: cfg.Timeout,
+			},
+		},
+		holders: make(map[string]map[string]time.Time),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+type peerAnnouncement struct {
+	// Port is the port the announcing peer serves its cache on, at the
+	// address the announcement comes from.
+	Port    int      `json:"port"`
+	Digests []string `json:"digests"`
+}
+
+// Serve serves the cache to the peers on the given listener and announces its
+// content to them, until the context is cancelled.
+func (pc *PeerCache) Serve(ctx context.Context, ln net.Listener) error {
+	addr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("cannot serve peer cache on non-TCP address %s", ln.Addr())
+	}
+	pc.mu.Lock()
+	pc.port = addr.Port
+	pc.mu.Unlock()
+
+	srv := &http.Server{
+		Handler:           pc,
+		ReadHeaderTimeout: pc.cfg.Timeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(ln)
+	}()
+
+	ticker := time.NewTicker(pc.cfg.AnnounceInterval)
+	defer ticker.Stop()
+
+	pc.announce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			srv.Close()
+			<-errs
+			return nil
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+			pc.announce(ctx)
+		case <-pc.changed:
+			pc.announce(ctx)
+		}
+	}
+}
+
+// cacheChanged notes that a snap was added to the cache, to announce it.
+func (pc *PeerCache) cacheChanged() {
+	select {
+	case pc.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (pc *PeerCache) announce(ctx context.Context) {
+	if err := pc.Announce(ctx); err != nil {
+		logger.Debugf("cannot announce peer cache content: %v", err)
+	}
+}
+
+// Announce announces the content of the cache to the peers. It returns the
+// last error encountered, if any.
+func (pc *PeerCache) Announce(ctx context.Context) error {
+	pc.mu.Lock()
+	port := pc.port
+	pc.mu.Unlock()
+	if port == 0 {
+		return errors.New("cannot announce peer cache content before serving it")
+	}
+
+	digests, err := pc.cache.Keys()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(peerAnnouncement{Port: port, Digests: digests})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, peer := range pc.cfg.Peers {
+		if err := pc.announceTo(ctx, peer, body); err != nil {
+			lastErr = fmt.Errorf("cannot announce to peer %s: %v", peer, err)
+		}
+	}
+	return lastErr
+}
+
+func (pc *PeerCache) announceTo(ctx context.Context, peer string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, pc.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "http://"+peer+peerCacheAnnouncePath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", jsonContentType)
+
+	resp, err := pc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ServeHTTP handles the announcements of the peers and their requests for
+// the snaps of the cache.
+func (pc *PeerCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == peerCacheAnnouncePath && r.Method == "POST":
+		pc.serveAnnounce(w, r)
+	case strings.HasPrefix(r.URL.Path, peerCacheBlobsPath) && r.Method == "GET":
+		pc.serveBlob(w, r, strings.TrimPrefix(r.URL.Path, peerCacheBlobsPath))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (pc *PeerCache) serveAnnounce(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		http.Error(w, "cannot identify peer", http.StatusBadRequest)
+		return
+	}
+
+	var ann peerAnnouncement
+	// the list of digests of a large cache still fits
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1024*1024)).Decode(&ann); err != nil {
+		http.Error(w, "cannot decode announcement", http.StatusBadRequest)
+		return
+	}
+	if ann.Port <= 0 || ann.Port > 65535 {
+		http.Error(w, "invalid port in announcement", http.StatusBadRequest)
+		return
+	}
+	peer, ok := pc.configuredPeer(host, ann.Port)
+	if !ok {
+		logger.Debugf("Ignoring peer cache announcement from unknown peer %s.", r.RemoteAddr)
+		http.Error(w, "unknown peer", http.StatusForbidden)
+		return
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	expiry := time.Now().Add(3 * pc.cfg.AnnounceInterval)
+	// the announcement replaces the previous one of the same peer
+	for digest, holders := range pc.holders {
+		delete(holders, peer)
+		if len(holders) == 0 {
+			delete(pc.holders, digest)
+		}
+	}
+	for _, digest := range ann.Digests {
+		if !validBlobDigest.MatchString(digest) {
+			continue
+		}
+		if pc.holders[digest] == nil {
+			pc.holders[digest] = make(map[string]time.Time)
+		}
+		pc.holders[digest][peer] = expiry
+	}
+}
+
+// configuredPeer returns the configured peer serving its cache on the given
+// port of the given host, if any. A port of 0 matches any configured port.
+func (pc *PeerCache) configuredPeer(host string, port int) (string, bool) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", false
+	}
+	for _, peer := range pc.cfg.Peers {
+		peerHost, peerPort, err := net.SplitHostPort(peer)
+		if err != nil || (port != 0 && peerPort != strconv.Itoa(port)) {
+			continue
+		}
+		if peerIP := net.ParseIP(peerHost); peerIP != nil {
+			if peerIP.Equal(ip) {
+				return peer, true
+			}
+			continue
+		}
+		addrs, err := lookupHost(peerHost)
+		if err != nil {
+			logger.Debugf("cannot resolve peer %s: %v", peer, err)
+			continue
+		}
+		for _, addr := range addrs {
+			if addrIP := net.ParseIP(addr); addrIP != nil && addrIP.Equal(ip) {
+				return peer, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (pc *PeerCache) serveBlob(w http.ResponseWriter, r *http.Request, digest string) {
+	// blobs can belong to private snaps, only share them with the peers
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		http.Error(w, "cannot identify peer", http.StatusBadRequest)
+		return
+	}
+	// requests come from an arbitrary port of the peer
+	if _, ok := pc.configuredPeer(host, 0); !ok {
+		logger.Debugf("Refusing peer cache blob request from unknown peer %s.", r.RemoteAddr)
+		http.Error(w, "unknown peer", http.StatusForbidden)
+		return
+	}
+
+	if !validBlobDigest.MatchString(digest) {
+		http.NotFound(w, r)
+		return
+	}
+
+	path := pc.cache.GetPath(digest)
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		// the blob can be removed by a concurrent cleanup
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	io.Copy(w, f)
+}
+
+// holdersOf returns the peers that announced the given blob.
+func (pc *PeerCache) holdersOf(digest string) []string {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	now := time.Now()
+	var peers []string
+	for peer, expiry := range pc.holders[digest] {
+		if now.Before(expiry) {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+func (pc *PeerCache) forget(peer, digest string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	delete(pc.holders[digest], peer)
+}
+
+// fetch fetches the snap with the given download information from the first
+// peer that has it, into the target path.
+func (pc *PeerCache) fetch(ctx context.Context, name string, downloadInfo *snap.DownloadInfo, targetPath string, pbar progress.Meter) error {
+	if !validBlobDigest.MatchString(downloadInfo.Sha3_384) {
+		return errNoPeerHolder
+	}
+	// a peer could otherwise send an unbounded amount of data
+	if downloadInfo.Size <= 0 {
+		return errNoPeerHolder
+	}
+	peers := pc.holdersOf(downloadInfo.Sha3_384)
+	if len(peers) == 0 {
+		return errNoPeerHolder
+	}
+
+	var err error
+	for _, peer := range peers {
+		err = pc.fetchFrom(ctx, peer, name, downloadInfo, targetPath, pbar)
+		if err == nil {
+			logger.Debugf("Fetched %s from peer %s.", name, peer)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Noticef("Cannot fetch %s from peer %s: %v", name, peer, err)
+		pc.forget(peer, downloadInfo.Sha3_384)
+	}
+	return err
+}
+
+// idleReader cancels a request when no data could be read from its body for
+// the given timeout.
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (ir *idleReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	if n > 0 {
+		ir.timer.Reset(ir.timeout)
+	}
+	return n, err
+}
+
+func (pc *PeerCache) fetchFrom(ctx context.Context, peer, name string, downloadInfo *snap.DownloadInfo, targetPath string, pbar progress.Meter) (err error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// a peer can't stall the download by trickling the snap
+	idle := time.AfterFunc(pc.cfg.Timeout, cancel)
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", "http://"+peer+peerCacheBlobsPath+downloadInfo.Sha3_384, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := pc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	partialPath := targetPath + ".peer"
+	w, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(partialPath)
+		}
+	}()
+
+	// a peer can't make us read more than the snap size
+	body := io.LimitReader(&idleReader{r: resp.Body, timer: idle, timeout: pc.cfg.Timeout}, downloadInfo.Size+1)
+	pbar.Start(name, float64(downloadInfo.Size))
+	defer pbar.Finished()
+
+	h := crypto.SHA3_384.New()
+	if _, err := io.Copy(io.MultiWriter(w, h, pbar), body); err != nil {
+		if reqCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("peer sent no data for %v", pc.cfg.Timeout)
+		}
+		return err
+	}
+	actualSha3 := fmt.Sprintf("%x", h.Sum(nil))
+	if actualSha3 != downloadInfo.Sha3_384 {
+		return HashError{name, actualSha3, downloadInfo.Sha3_384}
+	}
+
+	if err := w.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, targetPath)
+}
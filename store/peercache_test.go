@@ -0,0 +1,375 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type peerCacheSuite struct {
+	baseStoreSuite
+}
+
+var _ = Suite(&peerCacheSuite{})
+
+// listenPeer returns a listener on a local address, to serve a peer cache on.
+func listenPeer(c *C) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	return ln
+}
+
+// servePeer serves the given peer cache on the given listener, and returns
+// the address along with a function that stops it.
+func servePeer(c *C, pc *store.PeerCache, ln net.Listener) (string, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.Check(pc.Serve(ctx, ln), IsNil)
+	}()
+
+	return ln.Addr().String(), func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// newCache returns a download cache holding the given blobs.
+func newCache(c *C, blobs map[string][]byte) *store.CacheManager {
+	dir := c.MkDir()
+	for digest, content := range blobs {
+		c.Assert(os.WriteFile(filepath.Join(dir, digest), content, 0644), IsNil)
+	}
+	return store.NewCacheManager(dir, 5)
+}
+
+func waitForHolders(c *C, pc *store.PeerCache, digest string, n int) {
+	for i := 0; i < 500; i++ {
+		if len(pc.HoldersOf(digest)) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("peers did not announce %s", digest)
+}
+
+func digestOf(content []byte) string {
+	return fmt.Sprintf("%x", sha3.Sum384(content))
+}
+
+func (s *peerCacheSuite) mockStoreDownload(c *C, content []byte, called *bool) {
+	s.AddCleanup(store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		*called = true
+		_, err := w.Write(content)
+		return err
+	}))
+}
+
+func (s *peerCacheSuite) TestDownloadFromPeer(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	localLn, remoteLn := listenPeer(c), listenPeer(c)
+
+	local := store.NewPeerCache(newCache(c, nil), store.PeerCacheConfig{
+		Peers: []string{remoteLn.Addr().String()},
+	})
+	localAddr, stop := servePeer(c, local, localLn)
+	defer stop()
+
+	remote := store.NewPeerCache(newCache(c, map[string][]byte{digest: content}), store.PeerCacheConfig{
+		Peers: []string{localAddr},
+	})
+	remoteAddr, stop := servePeer(c, remote, remoteLn)
+	defer stop()
+
+	waitForHolders(c, local, digest, 1)
+	c.Check(local.HoldersOf(digest), DeepEquals, []string{remoteAddr})
+
+	sto := store.New(nil, nil)
+	sto.SetPeerCache(local)
+
+	var storeDownload bool
+	s.mockStoreDownload(c, content, &storeDownload)
+
+	target := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", target, &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Size:        int64(len(content)),
+		Sha3_384:    digest,
+	}, progress.Null, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownload, Equals, false)
+	c.Check(target, testutil.FileEquals, content)
+	c.Check(target+".peer", testutil.FileAbsent)
+}
+
+func (s *peerCacheSuite) TestDownloadFromPeerDigestMismatch(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	localLn, remoteLn := listenPeer(c), listenPeer(c)
+
+	local := store.NewPeerCache(newCache(c, nil), store.PeerCacheConfig{
+		Peers: []string{remoteLn.Addr().String()},
+	})
+	localAddr, stop := servePeer(c, local, localLn)
+	defer stop()
+
+	// the peer pretends to have the blob
+	remote := store.NewPeerCache(newCache(c, map[string][]byte{digest: []byte("other content")}), store.PeerCacheConfig{
+		Peers: []string{localAddr},
+	})
+	_, stop = servePeer(c, remote, remoteLn)
+	defer stop()
+
+	waitForHolders(c, local, digest, 1)
+
+	sto := store.New(nil, nil)
+	sto.SetPeerCache(local)
+
+	var storeDownload bool
+	s.mockStoreDownload(c, content, &storeDownload)
+
+	target := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", target, &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Size:        int64(len(content)),
+		Sha3_384:    digest,
+	}, progress.Null, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownload, Equals, true)
+	c.Check(target, testutil.FileEquals, content)
+	c.Check(s.logbuf.String(), testutil.Contains, "Cannot fetch foo from peer")
+
+	// the peer is not asked again
+	c.Check(local.HoldersOf(digest), HasLen, 0)
+}
+
+// announce announces the given digests to the peer cache served on addr, as
+// the peer serving its cache on the given port.
+func announce(c *C, addr, port string, digests ...string) int {
+	body := fmt.Sprintf(`{"port": %s, "digests": ["%s"]}`, port, strings.Join(digests, `", "`))
+	resp, err := http.Post("http://"+addr+"/v1/peer-cache/announce", "application/json", bytes.NewBufferString(body))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// downloadFromSlowPeer downloads a snap announced by a peer serving it with
+// the given handler, and checks it's downloaded from the store.
+func (s *peerCacheSuite) downloadFromSlowPeer(c *C, downloadInfo *snap.DownloadInfo, content []byte, handler http.HandlerFunc) {
+	slow := httptest.NewServer(handler)
+	defer slow.Close()
+	slowAddr := strings.TrimPrefix(slow.URL, "http://")
+	_, port, err := net.SplitHostPort(slowAddr)
+	c.Assert(err, IsNil)
+
+	local := store.NewPeerCache(newCache(c, nil), store.PeerCacheConfig{
+		Peers:   []string{slowAddr},
+		Timeout: 100 * time.Millisecond,
+	})
+	localAddr, stop := servePeer(c, local, listenPeer(c))
+	defer stop()
+
+	c.Assert(announce(c, localAddr, port, downloadInfo.Sha3_384, "invalid"), Equals, 200)
+	c.Check(local.HoldersOf(downloadInfo.Sha3_384), HasLen, 1)
+
+	sto := store.New(nil, nil)
+	sto.SetPeerCache(local)
+
+	var storeDownload bool
+	s.mockStoreDownload(c, content, &storeDownload)
+
+	target := filepath.Join(c.MkDir(), "foo_1.snap")
+	err = sto.Download(s.ctx, "foo", target, downloadInfo, progress.Null, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownload, Equals, true)
+	c.Check(target, testutil.FileEquals, content)
+}
+
+func (s *peerCacheSuite) TestDownloadFromPeerTimeout(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	// a peer that doesn't respond in time
+	s.downloadFromSlowPeer(c, &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Size:        int64(len(content)),
+		Sha3_384:    digest,
+	}, content, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			<-r.Context().Done()
+		}
+	})
+}
+
+func (s *peerCacheSuite) TestDownloadFromPeerStalled(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	// a peer that starts sending the snap and then stalls
+	s.downloadFromSlowPeer(c, &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Size:        int64(len(content)),
+		Sha3_384:    digest,
+	}, content, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write(content[:4])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+	c.Check(s.logbuf.String(), testutil.Contains, "peer sent no data for 100ms")
+}
+
+func (s *peerCacheSuite) TestDownloadFromPeerUnknownSize(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	var peerRequested bool
+	s.downloadFromSlowPeer(c, &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Sha3_384:    digest,
+	}, content, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			peerRequested = true
+		}
+	})
+	// the size of the snap bounds what's read from peers
+	c.Check(peerRequested, Equals, false)
+}
+
+func (s *peerCacheSuite) TestAnnounceFromUnknownPeer(c *C) {
+	digest := digestOf([]byte("snap blob content"))
+
+	known := listenPeer(c)
+	defer known.Close()
+	_, knownPort, err := net.SplitHostPort(known.Addr().String())
+	c.Assert(err, IsNil)
+
+	restore := store.MockLookupHost(func(host string) ([]string, error) {
+		c.Check(host, Equals, "peer.local")
+		return []string{"127.0.0.1"}, nil
+	})
+	defer restore()
+
+	pc := store.NewPeerCache(newCache(c, nil), store.PeerCacheConfig{
+		Peers: []string{"192.0.2.1:" + knownPort, "peer.local:" + knownPort},
+	})
+	addr, stop := servePeer(c, pc, listenPeer(c))
+	defer stop()
+
+	// the port doesn't match any of the peers
+	c.Check(announce(c, addr, "1", digest), Equals, 403)
+	c.Check(pc.HoldersOf(digest), HasLen, 0)
+
+	// the peer is known by name
+	c.Check(announce(c, addr, knownPort, digest), Equals, 200)
+	c.Check(pc.HoldersOf(digest), DeepEquals, []string{"peer.local:" + knownPort})
+
+	// the announcing host doesn't match any of the peers
+	restore = store.MockLookupHost(func(host string) ([]string, error) {
+		return []string{"192.0.2.2"}, nil
+	})
+	defer restore()
+	c.Check(announce(c, addr, knownPort, digest), Equals, 403)
+	c.Check(pc.HoldersOf(digest), HasLen, 1)
+}
+
+func (s *peerCacheSuite) TestServe(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	// requests come from an arbitrary port of the peer
+	pc := store.NewPeerCache(newCache(c, map[string][]byte{digest: content}), store.PeerCacheConfig{
+		Peers: []string{"127.0.0.1:1"},
+	})
+	addr, stop := servePeer(c, pc, listenPeer(c))
+	defer stop()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get("http://" + addr + path)
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		c.Assert(err, IsNil)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/v1/peer-cache/blobs/" + digest)
+	c.Check(status, Equals, 200)
+	c.Check(body, Equals, string(content))
+
+	status, _ = get("/v1/peer-cache/blobs/" + digestOf([]byte("missing")))
+	c.Check(status, Equals, 404)
+
+	status, _ = get("/v1/peer-cache/blobs/../../etc/passwd")
+	c.Check(status, Equals, 404)
+
+	status, _ = get("/v1/peer-cache/announce")
+	c.Check(status, Equals, 404)
+
+	resp, err := http.Post("http://"+addr+"/v1/peer-cache/announce", "application/json", bytes.NewBufferString(`{"port": 0}`))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 400)
+}
+
+func (s *peerCacheSuite) TestServeBlobToUnknownPeer(c *C) {
+	content := []byte("snap blob content")
+	digest := digestOf(content)
+
+	pc := store.NewPeerCache(newCache(c, map[string][]byte{digest: content}), store.PeerCacheConfig{
+		Peers: []string{"192.0.2.1:1"},
+	})
+	addr, stop := servePeer(c, pc, listenPeer(c))
+	defer stop()
+
+	resp, err := http.Get("http://" + addr + "/v1/peer-cache/blobs/" + digest)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Check(resp.StatusCode, Equals, 403)
+	c.Check(string(body), Equals, "unknown peer\n")
+}
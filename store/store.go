@@ -112,6 +112,11 @@ type Config struct {
 	// CacheDownloads is the number of downloads that should be cached
 	CacheDownloads int
 
+	// DownloadConnections is the number of connections used to download
+	// large snaps in chunks, they are downloaded over a single connection
+	// if it is lower than 2
+	DownloadConnections int
+
 	// Proxy returns the HTTP proxy to use when talking to the store
 	Proxy func(*http.Request) (*url.URL, error)
 
@@ -165,6 +170,7 @@ type Store struct {
 	suggestedCurrency string
 
 	cacher downloadCache
+	peers  *PeerCache
 
 	proxy              func(*http.Request) (*url.URL, error)
 	proxyConnectHeader http.Header
@@ -211,6 +211,16 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 		return nil
 	}
 
+	if s.peers != nil {
+		err := s.peers.fetch(ctx, name, downloadInfo, targetPath, pbar)
+		if err == nil {
+			return s.cacheDownloaded(downloadInfo.Sha3_384, targetPath)
+		}
+		if err != errNoPeerHolder {
+			logger.Noticef("Cannot fetch %s from peers, downloading it from the store: %v", name, err)
+		}
+	}
+
 	if s.useDeltas() {
 		logger.Debugf("Available deltas returned by store: %v", downloadInfo.Deltas)
 
@@ -232,6 +242,28 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	}
 
 	partialPath := targetPath + ".partial"
+	if s.useRangedDownload(partialPath, downloadInfo) {
+		err := s.downloadRanged(ctx, name, partialPath, downloadInfo, pbar, user, dlOpts)
+		if err == nil {
+			if err := os.Rename(partialPath, targetPath); err != nil {
+				return err
+			}
+			return s.cacheDownloaded(downloadInfo.Sha3_384, targetPath)
+		}
+		_, isHashErr := err.(HashError)
+		if err != errRangesNotSupported && !isHashErr {
+			if dlOpts == nil || !dlOpts.LeavePartialOnError {
+				os.Remove(partialPath)
+				os.Remove(rangedStatePath(partialPath))
+			}
+			return err
+		}
+		// download it again from scratch over a single connection
+		logger.Noticef("Cannot download %s in chunks, downloading it over a single connection: %v", name, err)
+		os.Remove(partialPath)
+		os.Remove(rangedStatePath(partialPath))
+	}
+
 	w, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return err
@@ -308,7 +340,18 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 		return err
 	}
 
-	return s.cacher.Put(downloadInfo.Sha3_384, targetPath)
+	return s.cacheDownloaded(downloadInfo.Sha3_384, targetPath)
+}
+
+// cacheDownloaded places the downloaded snap in the download cache.
+func (s *Store) cacheDownloaded(sha3_384, targetPath string) error {
+	if err := s.cacher.Put(sha3_384, targetPath); err != nil {
+		return err
+	}
+	if s.peers != nil {
+		s.peers.cacheChanged()
+	}
+	return nil
 }
 
 var errIconUnchanged = errors.New("existing icon unchanged")
@@ -572,18 +615,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
 		}
 		var resp *http.Response
-		cli := s.newHTTPClient(nil) // XXX: there's no timeout defined for this client, and the context is context.TODO(), so it won't be cancelled
-		oldCheckRedirect := cli.CheckRedirect
-		if oldCheckRedirect == nil {
-			panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
-		}
-		cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			// remove user/device auth headers from being sent in "CDN" redirects
-			// see also: https://bugs.launchpad.net/snapd/+bug/2027993
-			// TODO: do we need to remove other identifying headers?
-			dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
-			return oldCheckRedirect(req, via)
-		}
+		cli := s.newDownloadHTTPClient(reqOptions)
 		resp, finalErr = s.doRequest(downloadCtx, cli, reqOptions, user)
 		if cancelled(downloadCtx) {
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
@@ -687,6 +719,24 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 	return finalErr
 }
 
+// newDownloadHTTPClient returns the client for the given download request,
+// which does not send authorization headers when redirected.
+func (s *Store) newDownloadHTTPClient(reqOptions *requestOptions) *http.Client {
+	cli := s.newHTTPClient(nil) // XXX: there's no timeout defined for this client, and the context is context.TODO(), so it won't be cancelled
+	oldCheckRedirect := cli.CheckRedirect
+	if oldCheckRedirect == nil {
+		panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
+	}
+	cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// remove user/device auth headers from being sent in "CDN" redirects
+		// see also: https://bugs.launchpad.net/snapd/+bug/2027993
+		// TODO: do we need to remove other identifying headers?
+		dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
+		return oldCheckRedirect(req, via)
+	}
+	return cli
+}
+
 type ReadWriteSeekTruncater interface {
 	io.Reader
 	io.Writer
@@ -981,6 +1031,13 @@ func (s *Store) SetCacheDownloads(fileCount int) {
 	}
 }
 
+// SetPeerCache makes the store try to fetch snaps from the peers of the given
+// PeerCache before downloading them. The peer cache is expected to share the
+// download cache of the store.
+func (s *Store) SetPeerCache(pc *PeerCache) {
+	s.peers = pc
+}
+
 // CleanDownloadsCache attempts cleanup of snap downloads cache.
 //
 // Returns ErrCleanupBusy if the cache was locked for other operations.
@@ -0,0 +1,442 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"gopkg.in/retry.v1"
+
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	// downloadChunkSize is the size of the chunks of ranged downloads, snaps
+	// smaller than two chunks are downloaded over a single connection.
+	downloadChunkSize int64 = 16 * 1024 * 1024
+	// downloadCheckpointSize is how much of a chunk is downloaded between
+	// two saves of the resume state.
+	downloadCheckpointSize int64 = 4 * 1024 * 1024
+)
+
+var errRangesNotSupported = errors.New("server does not support ranged downloads")
+
+// chunkState is the resume state of a chunk of a ranged download.
+type chunkState struct {
+	Offset int64 `json:"offset"`
+	Size   int64 `json:"size"`
+	// Done is how much of the chunk is synced to the partial file.
+	Done int64 `json:"done"`
+	// Sha3_384 is the digest of the first Done bytes of the chunk, they
+	// are fetched again if the partial file does not match it on resume.
+	Sha3_384 string `json:"sha3-384,omitempty"`
+
+	// written is how much of the chunk is written to the partial file, h
+	// is its digest
+	written int64
+	h       hash.Hash
+}
+
+func (c *chunkState) complete() bool {
+	return c.written == c.Size
+}
+
+// rangedState is the resume state of a ranged download, it is kept next to
+// the partial file.
+type rangedState struct {
+	Sha3_384 string        `json:"sha3-384"`
+	Size     int64         `json:"size"`
+	Chunks   []*chunkState `json:"chunks"`
+}
+
+func rangedStatePath(partialPath string) string {
+	return partialPath + ".chunks"
+}
+
+func newRangedState(downloadInfo *snap.DownloadInfo) *rangedState {
+	st := &rangedState{
+		Sha3_384: downloadInfo.Sha3_384,
+		Size:     downloadInfo.Size,
+	}
+	for offset := int64(0); offset < downloadInfo.Size; offset += downloadChunkSize {
+		size := downloadChunkSize
+		if offset+size > downloadInfo.Size {
+			size = downloadInfo.Size - offset
+		}
+		st.Chunks = append(st.Chunks, &chunkState{Offset: offset, Size: size})
+	}
+	return st
+}
+
+// loadRangedState returns the resume state of the ranged download of the
+// given snap into the partial file, a fresh state is returned if there is
+// none or if it is for another snap.
+func loadRangedState(partialPath string, downloadInfo *snap.DownloadInfo) *rangedState {
+	data, err := os.ReadFile(rangedStatePath(partialPath))
+	if err != nil {
+		return newRangedState(downloadInfo)
+	}
+	var st rangedState
+	if err := json.Unmarshal(data, &st); err != nil {
+		logger.Noticef("Cannot read resume state of %q, downloading it from scratch: %v", partialPath, err)
+		return newRangedState(downloadInfo)
+	}
+	if st.Sha3_384 != downloadInfo.Sha3_384 || st.Size != downloadInfo.Size {
+		return newRangedState(downloadInfo)
+	}
+	// the chunks must cover the snap
+	var offset int64
+	for _, chunk := range st.Chunks {
+		if chunk.Offset != offset || chunk.Size <= 0 {
+			return newRangedState(downloadInfo)
+		}
+		offset += chunk.Size
+	}
+	if offset != st.Size {
+		return newRangedState(downloadInfo)
+	}
+	return &st
+}
+
+// useRangedDownload returns whether the snap should be downloaded into the
+// partial file in chunks over multiple connections.
+func (s *Store) useRangedDownload(partialPath string, downloadInfo *snap.DownloadInfo) bool {
+	if s.cfg.DownloadConnections < 2 || downloadInfo.Sha3_384 == "" || downloadInfo.Size < 2*downloadChunkSize {
+		return false
+	}
+	if osutil.FileExists(rangedStatePath(partialPath)) {
+		return true
+	}
+	// resume a download started over a single connection as such
+	fi, err := os.Stat(partialPath)
+	return err != nil || fi.Size() == 0
+}
+
+// rangedDownload downloads a snap in chunks over multiple connections.
+type rangedDownload struct {
+	s         *Store
+	name      string
+	storeURL  *url.URL
+	cdnHeader string
+	user      *auth.UserState
+	dlOpts    *DownloadOptions
+
+	f         *os.File
+	statePath string
+	bucket    *ratelimit.Bucket
+
+	// mu protects the state and the progress writers
+	mu       sync.Mutex
+	state    *rangedState
+	progress io.Writer
+}
+
+// downloadRanged downloads the snap into the partial file in chunks over
+// multiple connections. The state of each chunk is saved next to the
+// partial file, so that only the unfinished chunks are fetched again when
+// resuming. The sha3-384 of the whole snap is checked once all chunks are
+// downloaded.
+func (s *Store) downloadRanged(ctx context.Context, name, partialPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
+	if dlOpts == nil {
+		dlOpts = &DownloadOptions{}
+	}
+	if pbar == nil {
+		pbar = progress.Null
+	}
+
+	storeURL, err := url.Parse(downloadInfo.DownloadURL)
+	if err != nil {
+		return err
+	}
+	cdnHeader, err := s.cdnHeader()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	state := loadRangedState(partialPath, downloadInfo)
+	if err := f.Truncate(downloadInfo.Size); err != nil {
+		return err
+	}
+
+	var done int64
+	for _, chunk := range state.Chunks {
+		if err := verifyChunk(f, chunk); err != nil {
+			return err
+		}
+		done += chunk.written
+	}
+	if done > 0 {
+		logger.Debugf("Resuming ranged download of %q with %d bytes done.", partialPath, done)
+	} else {
+		logger.Debugf("Starting ranged download of %q.", partialPath)
+	}
+
+	tc, downloadCtx := NewTransferSpeedMonitoringWriterAndContext(ctx, downloadSpeedMeasureWindow, downloadSpeedMin)
+	downloadCtx, cancel := context.WithCancel(downloadCtx)
+	defer cancel()
+
+	rd := &rangedDownload{
+		s:         s,
+		name:      name,
+		storeURL:  storeURL,
+		cdnHeader: cdnHeader,
+		user:      user,
+		dlOpts:    dlOpts,
+		f:         f,
+		statePath: rangedStatePath(partialPath),
+		state:     state,
+		progress:  io.MultiWriter(pbar, tc),
+	}
+	if limit := dlOpts.RateLimit; limit > 0 {
+		rd.bucket = ratelimit.NewBucketWithRate(float64(limit), 2*limit)
+	}
+
+	pbar.Start(name, float64(downloadInfo.Size))
+	pbar.Set(float64(done))
+
+	chunks := make(chan *chunkState, len(state.Chunks))
+	for _, chunk := range state.Chunks {
+		if !chunk.complete() {
+			chunks <- chunk
+		}
+	}
+	close(chunks)
+
+	stopMonitorCh := tc.Monitor()
+	var wg sync.WaitGroup
+	errs := make(chan error, s.cfg.DownloadConnections)
+	for i := 0; i < s.cfg.DownloadConnections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				if err := rd.downloadChunk(downloadCtx, chunk); err != nil {
+					errs <- err
+					// stop the other connections
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stopMonitorCh)
+	pbar.Finished()
+	close(errs)
+
+	// keep what was downloaded for resuming
+	saveErr := rd.checkpoint(state.Chunks...)
+
+	if err := tc.Err(); err != nil {
+		return err
+	}
+	if cancelled(ctx) {
+		return fmt.Errorf("the download has been cancelled: %s", ctx.Err())
+	}
+	for err := range errs {
+		if err != context.Canceled {
+			return err
+		}
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	h := crypto.SHA3_384.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, downloadInfo.Size)); err != nil {
+		return err
+	}
+	actualSha3 := fmt.Sprintf("%x", h.Sum(nil))
+	if actualSha3 != downloadInfo.Sha3_384 {
+		return HashError{name, actualSha3, downloadInfo.Sha3_384}
+	}
+	return os.Remove(rd.statePath)
+}
+
+// verifyChunk checks the downloaded part of the chunk against its digest,
+// the chunk is downloaded again if it does not match.
+func verifyChunk(f *os.File, chunk *chunkState) error {
+	chunk.h = crypto.SHA3_384.New()
+	chunk.written = 0
+	if chunk.Done <= 0 || chunk.Done > chunk.Size {
+		chunk.Done = 0
+		chunk.Sha3_384 = ""
+		return nil
+	}
+	if _, err := io.Copy(chunk.h, io.NewSectionReader(f, chunk.Offset, chunk.Done)); err != nil {
+		return err
+	}
+	if fmt.Sprintf("%x", chunk.h.Sum(nil)) != chunk.Sha3_384 {
+		logger.Noticef("Downloaded data at offset %d does not match its resume state, downloading it again.", chunk.Offset)
+		chunk.h.Reset()
+		chunk.Done = 0
+		chunk.Sha3_384 = ""
+		return nil
+	}
+	chunk.written = chunk.Done
+	return nil
+}
+
+// checkpoint syncs the partial file and saves the resume state, with what
+// was written of the given chunks marked as done.
+func (rd *rangedDownload) checkpoint(chunks ...*chunkState) error {
+	if err := rd.f.Sync(); err != nil {
+		return err
+	}
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	for _, chunk := range chunks {
+		chunk.Done = chunk.written
+		chunk.Sha3_384 = fmt.Sprintf("%x", chunk.h.Sum(nil))
+	}
+	data, err := json.Marshal(rd.state)
+	if err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(rd.statePath, data, 0600, 0)
+}
+
+// downloadChunk downloads the rest of the chunk, retrying from where it
+// stopped on transient errors.
+func (rd *rangedDownload) downloadChunk(ctx context.Context, chunk *chunkState) error {
+	var finalErr error
+	startTime := time.Now()
+	for attempt := retry.Start(downloadRetryStrategy, nil); attempt.Next(); {
+		reqOptions := downloadReqOpts(rd.storeURL, rd.cdnHeader, rd.dlOpts)
+		httputil.MaybeLogRetryAttempt(reqOptions.URL.String(), attempt, startTime)
+
+		start := chunk.Offset + chunk.written
+		reqOptions.ExtraHeaders["Range"] = fmt.Sprintf("bytes=%d-%d", start, chunk.Offset+chunk.Size-1)
+
+		var resp *http.Response
+		resp, finalErr = rd.s.doRequest(ctx, rd.s.newDownloadHTTPClient(reqOptions), reqOptions, rd.user)
+		if cancelled(ctx) {
+			return ctx.Err()
+		}
+		if finalErr != nil {
+			if httputil.ShouldRetryAttempt(attempt, finalErr) {
+				continue
+			}
+			return finalErr
+		}
+		if httputil.ShouldRetryHttpResponse(attempt, resp) {
+			resp.Body.Close()
+			continue
+		}
+
+		switch resp.StatusCode {
+		case 206: // Partial Content
+		case 200:
+			resp.Body.Close()
+			return errRangesNotSupported
+		case 402: // Payment Required
+			resp.Body.Close()
+			return fmt.Errorf("please buy %s before installing it", rd.name)
+		default:
+			resp.Body.Close()
+			return &DownloadError{Code: resp.StatusCode, URL: resp.Request.URL}
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", start)) {
+			resp.Body.Close()
+			return errRangesNotSupported
+		}
+
+		finalErr = rd.copyChunk(chunk, resp.Body)
+		resp.Body.Close()
+		if cancelled(ctx) {
+			return ctx.Err()
+		}
+		if finalErr == nil && !chunk.complete() {
+			finalErr = io.ErrUnexpectedEOF
+		}
+		if finalErr == nil {
+			return nil
+		}
+		if httputil.ShouldRetryAttempt(attempt, finalErr) {
+			continue
+		}
+		return finalErr
+	}
+	return finalErr
+}
+
+// copyChunk writes the body to the chunk in the partial file, saving the
+// resume state regularly.
+func (rd *rangedDownload) copyChunk(chunk *chunkState, body io.Reader) error {
+	r := io.LimitReader(body, chunk.Size-chunk.written)
+	if rd.bucket != nil {
+		r = ratelimitReader(r, rd.bucket)
+	}
+
+	buf := make([]byte, 32*1024)
+	var sinceCheckpoint int64
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if _, err := rd.f.WriteAt(buf[:n], chunk.Offset+chunk.written); err != nil {
+				return err
+			}
+
+			rd.mu.Lock()
+			chunk.h.Write(buf[:n])
+			chunk.written += int64(n)
+			rd.progress.Write(buf[:n])
+			rd.mu.Unlock()
+
+			sinceCheckpoint += int64(n)
+			if sinceCheckpoint >= downloadCheckpointSize || chunk.complete() {
+				if err := rd.checkpoint(chunk); err != nil {
+					return err
+				}
+				sinceCheckpoint = 0
+			}
+		}
+		if rerr == io.EOF {
+			return nil
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
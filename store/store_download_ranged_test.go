@@ -0,0 +1,293 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/retry.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type storeDownloadRangedSuite struct {
+	baseStoreSuite
+
+	store *store.Store
+
+	content []byte
+	info    *snap.DownloadInfo
+
+	mu sync.Mutex
+	// ranges are the Range headers of the requests to the server
+	ranges []string
+}
+
+var _ = Suite(&storeDownloadRangedSuite{})
+
+const rangedChunkSize = 1024
+
+func (s *storeDownloadRangedSuite) SetUpTest(c *C) {
+	s.baseStoreSuite.SetUpTest(c)
+
+	c.Assert(os.MkdirAll(dirs.SnapMountDir, 0755), IsNil)
+
+	cfg := store.DefaultConfig()
+	cfg.DownloadConnections = 3
+	s.store = store.New(cfg, nil)
+
+	s.AddCleanup(store.MockDownloadChunkSize(rangedChunkSize, 256))
+	store.MockDownloadRetryStrategy(&s.BaseTest, retry.LimitCount(5, retry.LimitTime(1*time.Second,
+		retry.Exponential{
+			Initial: 1 * time.Millisecond,
+			Factor:  1,
+		},
+	)))
+
+	s.content = make([]byte, 10*rangedChunkSize+100)
+	for i := range s.content {
+		s.content[i] = byte(i % 251)
+	}
+	h := crypto.SHA3_384.New()
+	h.Write(s.content)
+	s.info = &snap.DownloadInfo{
+		Sha3_384: fmt.Sprintf("%x", h.Sum(nil)),
+		Size:     int64(len(s.content)),
+	}
+	s.ranges = nil
+}
+
+// serve serves the snap content, or what handle returns for the requested
+// range if it is set.
+func (s *storeDownloadRangedSuite) serve(c *C, handle func(w http.ResponseWriter, r *http.Request) bool) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+		if handle != nil && handle(w, r) {
+			return
+		}
+		http.ServeContent(w, r, "foo.snap", time.Time{}, bytes.NewReader(s.content))
+	}))
+	s.AddCleanup(mockServer.Close)
+	s.info.DownloadURL = mockServer.URL
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRanged(c *C) {
+	s.serve(c, nil)
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+	c.Check(osutil.FileExists(targetFn+".partial"), Equals, false)
+	c.Check(osutil.FileExists(targetFn+".partial.chunks"), Equals, false)
+
+	c.Check(s.ranges, HasLen, 11)
+	c.Check(s.ranges, testutil.Contains, "bytes=0-1023")
+	c.Check(s.ranges, testutil.Contains, "bytes=10240-10339")
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedSmallSnap(c *C) {
+	s.content = s.content[:2*rangedChunkSize-1]
+	h := crypto.SHA3_384.New()
+	h.Write(s.content)
+	s.info.Sha3_384 = fmt.Sprintf("%x", h.Sum(nil))
+	s.info.Size = int64(len(s.content))
+	s.serve(c, nil)
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+	c.Check(s.ranges, DeepEquals, []string{""})
+}
+
+type rangedState struct {
+	Chunks []struct {
+		Offset int64  `json:"offset"`
+		Size   int64  `json:"size"`
+		Done   int64  `json:"done"`
+		Sha3   string `json:"sha3-384"`
+	} `json:"chunks"`
+}
+
+func readRangedState(c *C, path string) *rangedState {
+	data, err := os.ReadFile(path)
+	c.Assert(err, IsNil)
+	var st rangedState
+	c.Assert(json.Unmarshal(data, &st), IsNil)
+	return &st
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedResume(c *C) {
+	// the server fails permanently for the chunk at 5120
+	failing := true
+	s.serve(c, func(w http.ResponseWriter, r *http.Request) bool {
+		if failing && r.Header.Get("Range") == "bytes=5120-6143" {
+			w.WriteHeader(404)
+			return true
+		}
+		return false
+	})
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	dlOpts := &store.DownloadOptions{LeavePartialOnError: true}
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, dlOpts)
+	c.Assert(err, ErrorMatches, `received an unexpected http response code \(404\) when trying to download .*`)
+	c.Check(osutil.FileExists(targetFn), Equals, false)
+
+	st := readRangedState(c, targetFn+".partial.chunks")
+	c.Assert(st.Chunks, HasLen, 11)
+	var done int64
+	for _, chunk := range st.Chunks {
+		done += chunk.Done
+	}
+	c.Check(st.Chunks[5].Done, Equals, int64(0))
+
+	// resuming only fetches what is missing
+	failing = false
+	s.ranges = nil
+	err = s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, dlOpts)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+	c.Check(osutil.FileExists(targetFn+".partial.chunks"), Equals, false)
+
+	var fetched int64
+	for _, r := range s.ranges {
+		var start, end int64
+		_, err := fmt.Sscanf(r, "bytes=%d-%d", &start, &end)
+		c.Assert(err, IsNil)
+		fetched += end - start + 1
+	}
+	c.Check(fetched, Equals, s.info.Size-done)
+	c.Check(s.ranges, testutil.Contains, "bytes=5120-6143")
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedResumeCorruptedChunk(c *C) {
+	s.serve(c, nil)
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	partialFn := targetFn + ".partial"
+
+	// a previous download completed the first two chunks, but the second
+	// one got corrupted
+	h := crypto.SHA3_384.New()
+	h.Write(s.content[:rangedChunkSize])
+	first := fmt.Sprintf("%x", h.Sum(nil))
+	h = crypto.SHA3_384.New()
+	h.Write(s.content[rangedChunkSize : 2*rangedChunkSize])
+	second := fmt.Sprintf("%x", h.Sum(nil))
+
+	partial := append([]byte(nil), s.content[:2*rangedChunkSize]...)
+	partial[rangedChunkSize+10] ^= 0xff
+	c.Assert(os.WriteFile(partialFn, partial, 0600), IsNil)
+
+	var chunks []string
+	for offset := 0; offset < len(s.content); offset += rangedChunkSize {
+		size := rangedChunkSize
+		if offset+size > len(s.content) {
+			size = len(s.content) - offset
+		}
+		switch offset {
+		case 0:
+			chunks = append(chunks, fmt.Sprintf(`{"offset":0,"size":%d,"done":%d,"sha3-384":%q}`, size, size, first))
+		case rangedChunkSize:
+			chunks = append(chunks, fmt.Sprintf(`{"offset":%d,"size":%d,"done":%d,"sha3-384":%q}`, offset, size, size, second))
+		default:
+			chunks = append(chunks, fmt.Sprintf(`{"offset":%d,"size":%d,"done":0}`, offset, size))
+		}
+	}
+	state := fmt.Sprintf(`{"sha3-384":%q,"size":%d,"chunks":[%s]}`, s.info.Sha3_384, s.info.Size, strings.Join(chunks, ","))
+	c.Assert(os.WriteFile(partialFn+".chunks", []byte(state), 0600), IsNil)
+
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+
+	c.Check(s.ranges, HasLen, 10)
+	c.Check(s.ranges, Not(testutil.Contains), "bytes=0-1023")
+	c.Check(s.ranges, testutil.Contains, "bytes=1024-2047")
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedNotSupported(c *C) {
+	// the server ignores ranges
+	s.serve(c, func(w http.ResponseWriter, r *http.Request) bool {
+		w.Write(s.content)
+		return true
+	})
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+	c.Check(osutil.FileExists(targetFn+".partial.chunks"), Equals, false)
+	// a single download over one connection follows
+	c.Check(s.ranges[len(s.ranges)-1], Equals, "")
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedHashMismatch(c *C) {
+	// chunks are served corrupted
+	s.serve(c, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Range") == "" {
+			return false
+		}
+		corrupted := append([]byte(nil), s.content...)
+		corrupted[4000] ^= 0xff
+		http.ServeContent(w, r, "foo.snap", time.Time{}, bytes.NewReader(corrupted))
+		return true
+	})
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, s.content)
+	c.Check(osutil.FileExists(targetFn+".partial.chunks"), Equals, false)
+	c.Check(s.ranges, HasLen, 12)
+	c.Check(s.ranges[11], Equals, "")
+}
+
+func (s *storeDownloadRangedSuite) TestDownloadRangedFailsRemovesPartial(c *C) {
+	s.serve(c, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(404)
+		return true
+	})
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, s.info, nil, nil, nil)
+	c.Assert(err, ErrorMatches, `received an unexpected http response code \(404\) when trying to download .*`)
+	c.Check(osutil.FileExists(targetFn+".partial"), Equals, false)
+	c.Check(osutil.FileExists(targetFn+".partial.chunks"), Equals, false)
+}